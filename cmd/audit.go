@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/audit"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// auditPreviewLength is how many characters of the text the list shows
+const auditPreviewLength = 40
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Review the requests recorded in the audit log",
+	Long:  `Review the requests recorded in the audit log: exactly what text was sent to which provider and model, and when. Turn recording on with: grammr config set audit_enabled true`,
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the requests in the audit log",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAuditList(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show ID",
+	Short: "Show exactly what a request sent",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAuditShow(os.Stdout, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var (
+	// auditPurgeOlderThan keeps requests of the last days, 0 to purge them all
+	auditPurgeOlderThan int
+	// auditPurgeYes skips the question before purging
+	auditPurgeYes bool
+)
+
+var auditPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Remove requests from the audit log",
+	Long:  `Remove every request from the audit log, or with --older-than only those recorded more than that many days ago. Asks first unless --yes is given.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		question := "Remove every request from the audit log?"
+		if auditPurgeOlderThan > 0 {
+			question = fmt.Sprintf("Remove the requests older than %d days from the audit log?", auditPurgeOlderThan)
+		}
+		if !auditPurgeYes && !confirm(os.Stdin, os.Stdout, question) {
+			fmt.Println("Cancelled")
+			return
+		}
+		if err := runAuditPurge(os.Stdout, auditPurgeOlderThan); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// openAuditLog returns the audit log of the config, and whether requests are being recorded
+func openAuditLog() (*audit.Log, bool, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load config: %w", err)
+	}
+	log, err := cfg.AuditLog()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return log, cfg.AuditEnabled, nil
+}
+
+func runAuditList(out io.Writer) error {
+	log, enabled, err := openAuditLog()
+	if err != nil {
+		return err
+	}
+	entries, err := log.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No requests in the audit log")
+		if !enabled {
+			fmt.Fprintln(out, "Auditing is off. Turn it on with: grammr config set audit_enabled true")
+		}
+		return nil
+	}
+
+	fmt.Fprintf(out, "%-12s  %-19s  %-34s  %6s  %s\n", "ID", "TIME", "PROVIDER/MODEL", "CHARS", "TEXT")
+	for _, entry := range entries {
+		fmt.Fprintf(out, "%-12s  %-19s  %-34s  %6d  %s\n", entry.ID,
+			entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Provider+"/"+entry.Model,
+			entry.Chars(), auditPreview(entry))
+	}
+	return nil
+}
+
+// auditPreview returns the start of the last message of a request, the text itself rather than
+// the instructions before it, on one line
+func auditPreview(entry audit.Entry) string {
+	if len(entry.Messages) == 0 {
+		return ""
+	}
+	text := strings.Join(strings.Fields(entry.Messages[len(entry.Messages)-1].Content), " ")
+	if runes := []rune(text); len(runes) > auditPreviewLength {
+		return string(runes[:auditPreviewLength-1]) + "…"
+	}
+	return text
+}
+
+func runAuditShow(out io.Writer, id string) error {
+	log, _, err := openAuditLog()
+	if err != nil {
+		return err
+	}
+	entry, err := log.Find(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "ID:       %s\n", entry.ID)
+	fmt.Fprintf(out, "Time:     %s\n", entry.Time.Local().Format(time.RFC3339))
+	fmt.Fprintf(out, "Provider: %s\n", entry.Provider)
+	fmt.Fprintf(out, "Model:    %s\n", entry.Model)
+	for _, message := range entry.Messages {
+		fmt.Fprintf(out, "\n[%s]\n%s\n", message.Role, message.Content)
+	}
+	return nil
+}
+
+func runAuditPurge(out io.Writer, olderThanDays int) error {
+	if olderThanDays < 0 {
+		return errors.New("--older-than must be a positive number of days")
+	}
+	log, _, err := openAuditLog()
+	if err != nil {
+		return err
+	}
+	var before time.Time
+	if olderThanDays > 0 {
+		before = time.Now().AddDate(0, 0, -olderThanDays)
+	}
+	removed, err := log.Purge(before)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Removed %d requests from the audit log\n", removed)
+	return nil
+}
+
+func init() {
+	auditPurgeCmd.Flags().IntVar(&auditPurgeOlderThan, "older-than", 0, "only remove requests older than this many days")
+	auditPurgeCmd.Flags().BoolVarP(&auditPurgeYes, "yes", "y", false, "purge without asking")
+	auditCmd.AddCommand(auditListCmd)
+	auditCmd.AddCommand(auditShowCmd)
+	auditCmd.AddCommand(auditPurgeCmd)
+	rootCmd.AddCommand(auditCmd)
+}
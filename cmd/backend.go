@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/ratelimit"
+	"github.com/maximbilan/grammr/internal/translator"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// backend creates the correctors and translators of the commands that serve other programs, set
+// up from config like the TUI's and sharing one provider, glossary and rate limits
+type backend struct {
+	cfg        *config.Config
+	provider   provider.Provider
+	rateLimits *ratelimit.Registry
+	glossary   *glossary.Glossary
+}
+
+func newBackend(cfg *config.Config) (*backend, error) {
+	apiKey := cfg.GetAPIKey()
+	// Offline, nothing is sent, so no key is needed
+	if err := validation.ValidateAPIKey(apiKey); err != nil && !cfg.Offline {
+		return nil, err
+	}
+	auditLog, err := cfg.OpenAudit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	prov, err := provider.New(cfg.Provider, apiKey, provider.Options{Deterministic: cfg.Deterministic, Audit: auditLog, Redact: cfg.RedactPII, Retries: cfg.RequestRetries, Offline: cfg.Offline})
+	if err != nil {
+		return nil, err
+	}
+	gloss, err := cfg.LoadGlossary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load glossary: %w", err)
+	}
+	return &backend{cfg: cfg, provider: prov, rateLimits: cfg.OpenRateLimits(), glossary: gloss}, nil
+}
+
+// corrector creates a corrector for the configured style, prompt template and glossary
+func (b *backend) corrector() (*corrector.Corrector, error) {
+	cfg := b.cfg
+	rateLimiter := b.rateLimits.Limiter(cfg.Provider, ratelimit.OperationCorrection)
+	cor, err := corrector.NewWithCustomStyles(b.provider, cfg.Model, cfg.Style, cfg.Language, cfg.CustomStylePrompts(), rateLimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	promptTemplate, err := cfg.LoadPromptTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if err := cor.SetPromptTemplate(promptTemplate); err != nil {
+		return nil, err
+	}
+	if err := cor.SetFormat(cfg.Format); err != nil {
+		return nil, err
+	}
+	if err := cor.SetDialect(cfg.Dialect); err != nil {
+		return nil, err
+	}
+	if err := cor.SetCategory(cfg.Category); err != nil {
+		return nil, err
+	}
+	cor.SetGlossary(b.glossary)
+	cor.SetChunkConcurrency(cfg.ChunkConcurrency)
+	return cor, nil
+}
+
+// translator creates a translator to language, with the formality configured for it
+func (b *backend) translator(language string) (*translator.Translator, error) {
+	rateLimiter := b.rateLimits.Limiter(b.cfg.Provider, ratelimit.OperationTranslation)
+	trans, err := translator.NewWithRateLimit(b.provider, b.cfg.Model, language, rateLimiter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create translator: %w", err)
+	}
+	trans.SetGlossary(b.glossary)
+	if err := trans.SetFormality(b.cfg.FormalityFor(language)); err != nil {
+		return nil, err
+	}
+	return trans, nil
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/maximbilan/grammr/internal/cache"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage cached corrections and translations",
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached corrections and translations",
+	Long:  `Remove all cached corrections and translations from every cache backend, including entries from older versions of grammr that are no longer used. Asks first unless --yes is given.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !cacheClearYes && !confirm(os.Stdin, os.Stdout, "Remove all cached corrections and translations?") {
+			fmt.Println("Cancelled")
+			return
+		}
+		if err := runCacheClear(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// cacheClearYes skips the question before clearing the cache
+var cacheClearYes bool
+
+// confirm asks question on out and reports whether the answer read from in is yes. No answer,
+// such as an empty stdin, counts as no.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// openCache opens the configured cache directory with backend
+func openCache(cfg *config.Config, backend string) (*cache.Cache, error) {
+	dir, err := cfg.CachePath()
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewInDir(dir, cfg.CacheTTLDays, backend)
+}
+
+func runCacheClear() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Clear every backend, so entries left behind after switching backends go too
+	total := 0
+	for _, backend := range cache.Backends {
+		c, err := openCache(cfg, backend)
+		if err != nil {
+			return err
+		}
+		removed, err := c.Clear()
+		if err != nil {
+			return err
+		}
+		total += removed
+	}
+	fmt.Printf("Removed %d cached entries\n", total)
+	return nil
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how much the cache has saved",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheStats(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var cacheSweepDryRun bool
+
+var cacheSweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Remove expired cache entries",
+	Long:  `Remove expired cache entries. grammr also does this in the background whenever it starts; use --dry-run to see how much space a sweep would reclaim.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheSweep(cacheSweepDryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runCacheSweep(dryRun bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	c, err := openCache(cfg, cfg.CacheBackend)
+	if err != nil {
+		return err
+	}
+	result, err := c.Sweep(dryRun)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("%d expired entries could be removed, reclaiming %s\n", result.Entries, cache.FormatSize(result.Bytes))
+		return nil
+	}
+	fmt.Printf("Removed %d expired entries, reclaiming %s\n", result.Entries, cache.FormatSize(result.Bytes))
+	return nil
+}
+
+var cacheExportCmd = &cobra.Command{
+	Use:   "export <file.tar.zst>",
+	Short: "Export cached corrections and translations to an archive",
+	Long:  `Export cached corrections and translations to a zstd-compressed tar archive, to take them to another machine with "grammr cache import". Entries are decrypted so the other machine can encrypt them with its own key; keep the archive private.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheExport(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var cacheImportCmd = &cobra.Command{
+	Use:   "import <file.tar.zst>",
+	Short: "Import cached corrections and translations from an archive",
+	Long:  `Import cached corrections and translations from an archive written by "grammr cache export", encrypting them with this machine's key. Entries that have expired since the export are skipped.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCacheImport(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runCacheExport(path string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	c, err := openCache(cfg, cfg.CacheBackend)
+	if err != nil {
+		return err
+	}
+
+	// The archive holds decrypted entries, so only the user may read it, even when it replaces a
+	// file others could read
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, cache.CacheFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := file.Chmod(cache.CacheFilePerm); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	exported, err := c.Export(file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	fmt.Printf("Exported %d cached entries to %s\n", exported, path)
+	return nil
+}
+
+func runCacheImport(path string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	c, err := openCache(cfg, cfg.CacheBackend)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+	imported, err := c.Import(file)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d cached entries from %s\n", imported, path)
+	return nil
+}
+
+func runCacheStats() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	c, err := openCache(cfg, cfg.CacheBackend)
+	if err != nil {
+		return err
+	}
+	stats, err := c.Lifetime()
+	if err != nil {
+		return err
+	}
+	entries, size, err := c.Usage()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Entries:   %d (%s)\n", entries, cache.FormatSize(size))
+	fmt.Printf("Hits:      %d\n", stats.Hits)
+	fmt.Printf("Misses:    %d\n", stats.Misses)
+	fmt.Printf("Hit rate:  %.0f%%\n", stats.HitRate()*100)
+	fmt.Printf("Saved:     %s of responses\n", cache.FormatSize(stats.BytesSaved))
+	if !cfg.CacheEnabled {
+		fmt.Println("The cache is disabled. Enable it with: grammr config set cache_enabled true")
+	}
+	return nil
+}
+
+func init() {
+	cacheClearCmd.Flags().BoolVarP(&cacheClearYes, "yes", "y", false, "clear without asking")
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheSweepCmd.Flags().BoolVar(&cacheSweepDryRun, "dry-run", false, "only report what would be removed")
+	cacheCmd.AddCommand(cacheSweepCmd)
+	cacheCmd.AddCommand(cacheExportCmd)
+	cacheCmd.AddCommand(cacheImportCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
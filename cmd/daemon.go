@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/daemon"
+	"github.com/maximbilan/grammr/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+// daemonSocket is the socket given with --socket
+var daemonSocket string
+
+// newDaemonCommand creates the command running grammrd, as grammr daemon or as grammrd itself
+func newDaemonCommand(use string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: "Keep a grammr running in the background for grammr fix and grammr translate",
+		Long: `Keep a grammr running in the background, listening on a unix socket (default ~/.grammr/grammrd.sock).
+grammr fix and grammr translate find it and send their texts to it, which saves loading the config,
+reading the API key and connecting to the provider on every run, and shares its cache and rate limits.
+
+Requests use the provider, model, style and cache of the config grammrd started with.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if err := runDaemon(ctx, daemonSocket); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&daemonSocket, "socket", "", "socket to listen on (default ~/.grammr/grammrd.sock)")
+	return cmd
+}
+
+// ExecuteDaemon runs grammrd
+func ExecuteDaemon() {
+	cmd := newDaemonCommand("grammrd")
+	cmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default ~/.grammr/config.yaml)")
+	cmd.PersistentFlags().BoolVar(&offline, "offline", false, "send no requests: corrections come from the cache only")
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// daemonSocketPath returns the socket of grammrd, the one given or the one in the config directory
+func daemonSocketPath(socket string) (string, error) {
+	if socket != "" {
+		return socket, nil
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, daemon.SocketFile), nil
+}
+
+func runDaemon(ctx context.Context, socket string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	// The clients of grammrd show its answers, but may not be looking, as when a launcher runs them
+	notifier := notify.New(cfg.Notifications)
+	srv, closeServer, err := newRPCServer(cfg, func(method string, err error) {
+		notifier.Error(apierror.Message(err))
+	})
+	if err != nil {
+		return err
+	}
+	defer closeServer()
+
+	path, err := daemonSocketPath(socket)
+	if err != nil {
+		return err
+	}
+	listener, err := daemon.Listen(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	fmt.Fprintf(os.Stderr, "grammrd is listening on %s\n", path)
+
+	if err := daemon.Serve(ctx, listener, srv); err != nil {
+		notifier.Error("grammrd stopped: " + err.Error())
+		return err
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(newDaemonCommand("daemon"))
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/maximbilan/grammr/internal/clipboard"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the setup of grammr",
+	Long:  `Check the config file, the API key of the provider and which clipboard grammr can use, with what to do about anything that doesn't work.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDoctor(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runDoctor(out io.Writer) error {
+	file, err := config.File()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if _, err := os.Stat(file); err == nil {
+		fmt.Fprintf(out, "Config:     %s\n", file)
+	} else {
+		fmt.Fprintf(out, "Config:     %s (not found, create it with: grammr init)\n", file)
+	}
+
+	if cfg.GetAPIKey() != "" {
+		fmt.Fprintf(out, "API key:    set for %s\n", cfg.Provider)
+	} else {
+		key := "api_key"
+		if cfg.Provider == "anthropic" {
+			key = "anthropic_api_key"
+		}
+		fmt.Fprintf(out, "API key:    missing for %s, set it with: grammr config set %s YOUR_KEY\n", cfg.Provider, key)
+	}
+
+	fmt.Fprintf(out, "Clipboard:  %s\n", clipboardStatus(clipboard.Detect(), cfg.Clipboard))
+	return nil
+}
+
+// clipboardStatus describes the clipboard for doctor: the one the clipboard setting names, or
+// the detected backend
+func clipboardStatus(backend clipboard.Backend, mode string) string {
+	switch {
+	case mode != "" && mode != clipboard.ModeAuto:
+		return fmt.Sprintf("%s (set by the clipboard setting, %s detected)", mode, backend.Name)
+	case !backend.CanPaste():
+		return fmt.Sprintf("copy only, through the terminal (OSC 52); %s", backend.Hint)
+	}
+	return backend.Name
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
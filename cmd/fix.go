@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/daemon"
+	"github.com/maximbilan/grammr/internal/docx"
+	"github.com/maximbilan/grammr/internal/langdetect"
+	"github.com/maximbilan/grammr/internal/rpc"
+	"github.com/maximbilan/grammr/pkg/grammr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// noDaemon does the work in the command even when grammrd is running
+	noDaemon bool
+	// translateTo is the language given with --to
+	translateTo string
+	// fixOutput is where --output writes the corrected copy of a .docx file
+	fixOutput string
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix [text | file.docx]",
+	Short: "Correct text and print the correction",
+	Long: `Correct text and print the correction, for scripts and launchers. The text is taken from the arguments or, if none are given, from stdin. When grammrd is running, the text is sent to it.
+
+Given a Word document (.docx), its text is corrected with the formatting kept, and a corrected copy
+is written next to it as NAME.corrected.docx, or to --output. The path of the copy is printed.
+
+With --format json the original, the correction and its changes are printed as one JSON object, and
+with --format raycast as script filter JSON for Raycast and Alfred, errors included.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if path, ok := docxArg(args); ok {
+			output := fixOutput
+			if output == "" {
+				output = correctedPath(path)
+			}
+			original, corrected, err := runFixDocx(ctx, path, output, !noDaemon)
+			printResult(os.Stdout, outputFormat, result{
+				Original: original,
+				Text:     corrected,
+				File:     output,
+				Changes:  grammr.Changes(original, corrected),
+			}, err)
+			return
+		}
+		if fixOutput != "" {
+			fmt.Fprintln(os.Stderr, "Error: --output is only for .docx files")
+			os.Exit(1)
+		}
+		text, err := readInputText(args, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		corrected, err := runFix(ctx, text, !noDaemon)
+		printResult(os.Stdout, outputFormat, result{
+			Original: text,
+			Text:     corrected,
+			Changes:  grammr.Changes(text, corrected),
+		}, err)
+	},
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateOutputFormat(outputFormat)
+	},
+}
+
+var translateCmd = &cobra.Command{
+	Use:   "translate [text]",
+	Short: "Translate text and print the translation",
+	Long:  `Translate text to the language given with --to, or translation_language, and print the translation, for scripts and launchers. The text is taken from the arguments or, if none are given, from stdin. When grammrd is running, the text is sent to it. --format works like for grammr fix.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		text, err := readInputText(args, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		translated, language, err := runTranslate(ctx, text, translateTo, !noDaemon)
+		printResult(os.Stdout, outputFormat, result{Original: text, Text: translated, Language: language}, err)
+	},
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateOutputFormat(outputFormat)
+	},
+}
+
+// dialDaemon connects to grammrd, or returns nil when it isn't running. With --offline it isn't
+// used, since a grammrd started without it would send the text.
+func dialDaemon() *rpc.Client {
+	if offline {
+		return nil
+	}
+	path, err := daemonSocketPath("")
+	if err != nil {
+		return nil
+	}
+	client, err := daemon.Dial(path)
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+// runFix returns the correction of text
+func runFix(ctx context.Context, text string, useDaemon bool) (string, error) {
+	if useDaemon {
+		if client := dialDaemon(); client != nil {
+			defer client.Close()
+			var result rpc.CorrectResult
+			if err := client.Call(ctx, "correct", rpc.CorrectParams{Text: text}, &result, nil); err != nil {
+				return "", err
+			}
+			return result.Corrected, nil
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	b, err := newBackend(cfg)
+	if err != nil {
+		return "", err
+	}
+	cor, err := b.corrector()
+	if err != nil {
+		return "", err
+	}
+	if cfg.DetectLanguage {
+		if language, ok := langdetect.Detect(text); ok {
+			cor = cor.WithLanguage(language)
+		}
+	}
+	if cfg.Offline {
+		// Offline, the cache is the only place a correction can come from
+		if corrected, ok := cachedCorrection(cfg, cor, text); ok {
+			return corrected, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.ScaledTimeout(cfg.CorrectionTimeout(), text))
+	defer cancel()
+	corrected, err := cor.Correct(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(corrected, " \t\r\n"), nil
+}
+
+// cachedCorrection returns the correction of text the TUI or grammrd cached, if there is one
+func cachedCorrection(cfg *config.Config, cor *corrector.Corrector, text string) (string, bool) {
+	if !cfg.CacheEnabled {
+		return "", false
+	}
+	c, err := openCache(cfg, cfg.CacheBackend)
+	if err != nil {
+		return "", false
+	}
+	corrected := c.Get(c.CorrectionHash(text, cor.Style(), cfg.Model, cor.Language(), cor.PromptVersion()))
+	return strings.TrimRight(corrected, " \t\r\n"), corrected != ""
+}
+
+// docxArg returns the Word document given as the only argument, if there is one
+func docxArg(args []string) (string, bool) {
+	if len(args) != 1 || !strings.EqualFold(filepath.Ext(args[0]), ".docx") {
+		return "", false
+	}
+	return args[0], true
+}
+
+// correctedPath returns where the corrected copy of the document at path is written by default
+func correctedPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".corrected" + ext
+}
+
+// runFixDocx corrects the Word document at path and writes the corrected copy to output. It
+// returns the text of the document and its correction.
+func runFixDocx(ctx context.Context, path, output string, useDaemon bool) (string, string, error) {
+	doc, err := docx.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	text := doc.Text()
+	if strings.TrimSpace(text) == "" {
+		return "", "", fmt.Errorf("%s has no text to correct", path)
+	}
+	corrected, err := runFix(ctx, text, useDaemon)
+	if err != nil {
+		return text, "", err
+	}
+	doc.Replace(corrected)
+
+	f, err := os.Create(output)
+	if err != nil {
+		return text, "", fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	if err := doc.Write(f); err != nil {
+		f.Close()
+		return text, "", fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	if err := f.Close(); err != nil {
+		return text, "", fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	return text, corrected, nil
+}
+
+// runTranslate returns the translation of text, and the language it was translated to
+func runTranslate(ctx context.Context, text, language string, useDaemon bool) (string, string, error) {
+	if useDaemon {
+		if client := dialDaemon(); client != nil {
+			defer client.Close()
+			var result rpc.TranslateResult
+			if err := client.Call(ctx, "translate", rpc.TranslateParams{Text: text, Language: language}, &result, nil); err != nil {
+				return "", "", err
+			}
+			return result.Translated, result.Language, nil
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if language == "" {
+		language = cfg.TranslationLanguage
+	}
+	if language == "" {
+		return "", "", fmt.Errorf("no language to translate to, pass --to or set translation_language")
+	}
+	b, err := newBackend(cfg)
+	if err != nil {
+		return "", "", err
+	}
+	trans, err := b.translator(language)
+	if err != nil {
+		return "", "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.ScaledTimeout(cfg.TranslationTimeout(), text))
+	defer cancel()
+	translated, err := trans.Translate(ctx, text)
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimRight(translated, " \t\r\n"), language, nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{fixCmd, translateCmd} {
+		cmd.Flags().BoolVar(&noDaemon, "no-daemon", false, "don't send the text to grammrd even when it is running")
+		cmd.Flags().StringVar(&outputFormat, "format", formatText, "output format: "+strings.Join(outputFormats, ", "))
+		rootCmd.AddCommand(cmd)
+	}
+	translateCmd.Flags().StringVar(&translateTo, "to", "", "language to translate to (default translation_language)")
+	fixCmd.Flags().StringVarP(&fixOutput, "output", "o", "", "where to write the corrected copy of a .docx file (default NAME.corrected.docx)")
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/maximbilan/grammr/cmd"
+
+func main() {
+	cmd.ExecuteDaemon()
+}
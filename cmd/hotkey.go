@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/clipboard"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/hotkey"
+	"github.com/maximbilan/grammr/internal/notify"
+	"github.com/maximbilan/grammr/internal/sanitize"
+	"github.com/maximbilan/grammr/pkg/grammr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// hotkeyShortcut is the shortcut given with --key
+	hotkeyShortcut string
+	// hotkeyOnce corrects the selection once instead of listening for the shortcut
+	hotkeyOnce bool
+)
+
+var hotkeyCmd = &cobra.Command{
+	Use:   "hotkey",
+	Short: "Correct the selection in any app with a keyboard shortcut",
+	Long: `Listen for a system-wide keyboard shortcut (default ctrl+alt+g). When it is pressed, the text
+selected in the app in the foreground is copied, corrected and pasted over the selection. Without a
+selection, the text on the clipboard is corrected and left there to paste.
+
+Shortcuts are registered on Windows and on Linux with X11. Elsewhere, such as on macOS or under
+Wayland, bind "grammr hotkey --once" to a shortcut with the Shortcuts app or your compositor.
+Sending the copy and paste keys takes xdotool on X11 and wtype on Wayland, and on macOS the
+terminal must be allowed to control the computer under Privacy & Security > Accessibility.
+
+Each correction is reported in a desktop notification, see the notifications setting.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := runHotkey(ctx, hotkeyShortcut, hotkeyOnce, !noDaemon); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runHotkey(ctx context.Context, key string, once, useDaemon bool) error {
+	shortcut, err := hotkey.Parse(key)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	clip := clipboard.New(cfg.Clipboard)
+	if hint := clipboard.PasteHint(clip); hint != "" {
+		return errors.New(hint)
+	}
+	keys, err := hotkey.NewKeyboard()
+	if err != nil {
+		return err
+	}
+	notifier := notify.New(cfg.Notifications)
+	// correctSelection corrects the selection and reports the outcome, since there is no window
+	// to show it in
+	correctSelection := func(ctx context.Context) error {
+		changes := 0
+		pasted, err := hotkey.CorrectSelection(ctx, clip, keys, func(ctx context.Context, text string) (string, error) {
+			text, err := sanitizeSelection(text)
+			if err != nil {
+				return "", err
+			}
+			corrected, err := runFix(ctx, text, useDaemon)
+			// The trailing whitespace the correction lacks is put back, so it is no change
+			changes = len(grammr.Changes(strings.TrimRight(text, " \t\r\n"), corrected))
+			return corrected, err
+		})
+		if err != nil {
+			notifier.Error(apierror.Message(err))
+			return errors.New(apierror.Message(err))
+		}
+		notifier.Changes(changes, pasted)
+		return nil
+	}
+
+	if once {
+		return correctSelection(ctx)
+	}
+
+	var busy atomic.Bool
+	err = hotkey.Listen(ctx, shortcut, func() {
+		// A press while a correction is running would copy its own paste
+		if !busy.CompareAndSwap(false, true) {
+			return
+		}
+		go func() {
+			defer busy.Store(false)
+			if err := correctSelection(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}()
+	})
+	if errors.Is(err, hotkey.ErrUnsupported) {
+		return fmt.Errorf("%w; bind \"grammr hotkey --once\" to a shortcut in your system settings instead", err)
+	}
+	return err
+}
+
+// sanitizeSelection removes escape sequences and invisible characters from a copied selection.
+// There is no one to ask whether a text that may instruct the model should be sent, so it isn't.
+func sanitizeSelection(text string) (string, error) {
+	text, report := sanitize.Clean(text)
+	if len(report.Injections) > 0 {
+		return "", fmt.Errorf("not sent, the text may instruct the model: %q", report.Injections[0])
+	}
+	return text, nil
+}
+
+func init() {
+	hotkeyCmd.Flags().StringVar(&hotkeyShortcut, "key", "ctrl+alt+g", "shortcut to listen for, such as ctrl+shift+f9")
+	hotkeyCmd.Flags().BoolVar(&hotkeyOnce, "once", false, "correct the selection once and exit, to bind to a shortcut of the system")
+	hotkeyCmd.Flags().BoolVar(&noDaemon, "no-daemon", false, "don't send the text to grammrd even when it is running")
+	rootCmd.AddCommand(hotkeyCmd)
+}
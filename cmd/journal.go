@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/journal"
+	"github.com/spf13/cobra"
+)
+
+// journalReportLimit is how many recurring mistakes the report lists
+const journalReportLimit = 10
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Review the corrections kept in the journal",
+}
+
+var journalReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Sum up the most frequent mistakes in the journal",
+	Long:  `Sum up the corrections appended to journal_file: how many changes of each category they made, and the mistakes that were corrected more than once.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runJournalReport(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runJournalReport(out io.Writer) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	path, err := cfg.JournalPath()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return errors.New("no journal, start one with: grammr config set journal_file ~/grammr-journal.md")
+	}
+	entries, err := journal.Read(path)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(out, "No corrections in %s yet\n", path)
+		return nil
+	}
+
+	report := journal.Summarize(entries)
+	fmt.Fprintf(out, "Corrections: %d, from %s to %s\n", report.Entries,
+		report.First.Format("2006-01-02"), report.Last.Format("2006-01-02"))
+	fmt.Fprintf(out, "Changes:     %d\n", report.Changes)
+	if len(report.Categories) > 0 {
+		fmt.Fprintln(out, "\nBy category:")
+		for _, category := range report.Categories {
+			fmt.Fprintf(out, "  %-12s %4d  %3.0f%%\n", category.Name, category.Count,
+				float64(category.Count)*100/float64(report.Changes))
+		}
+	}
+	if len(report.Recurring) > 0 {
+		fmt.Fprintln(out, "\nRecurring mistakes:")
+		for i, mistake := range report.Recurring {
+			if i == journalReportLimit {
+				break
+			}
+			fmt.Fprintf(out, "  %3d× %s\n", mistake.Count, mistake.Name)
+		}
+	}
+	return nil
+}
+
+func init() {
+	journalCmd.AddCommand(journalReportCmd)
+	rootCmd.AddCommand(journalCmd)
+}
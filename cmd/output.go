@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/pkg/grammr"
+)
+
+// Output formats of grammr fix and grammr translate
+const (
+	formatText    = "text"
+	formatJSON    = "json"
+	formatRaycast = "raycast"
+)
+
+// outputFormats lists the formats --format accepts
+var outputFormats = []string{formatText, formatJSON, formatRaycast}
+
+// outputFormat is the format given with --format
+var outputFormat string
+
+// result is what grammr fix and grammr translate print
+type result struct {
+	Original string          `json:"original"`
+	Text     string          `json:"text"`
+	Language string          `json:"language,omitempty"` // Of a translation
+	Changes  []grammr.Change `json:"changes,omitempty"`  // Of a correction
+	File     string          `json:"file,omitempty"`     // Corrected copy of a Word document
+}
+
+// scriptFilter is the script filter JSON of Raycast and Alfred
+type scriptFilter struct {
+	Items []scriptFilterItem `json:"items"`
+}
+
+type scriptFilterItem struct {
+	UID      string            `json:"uid,omitempty"`
+	Title    string            `json:"title"`
+	Subtitle string            `json:"subtitle,omitempty"`
+	Arg      string            `json:"arg,omitempty"`
+	Text     *scriptFilterText `json:"text,omitempty"`
+	Valid    bool              `json:"valid"`
+}
+
+// scriptFilterText is copied with Cmd-C and shown with Cmd-L
+type scriptFilterText struct {
+	Copy      string `json:"copy"`
+	LargeType string `json:"largetype"`
+}
+
+func validateOutputFormat(format string) error {
+	for _, known := range outputFormats {
+		if format == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q, use one of: %s", format, strings.Join(outputFormats, ", "))
+}
+
+// printResult prints res, or err when it isn't nil, in format. Errors are printed to stderr with
+// a failing exit status, except in the raycast format, where launchers show them as an item.
+func printResult(out io.Writer, format string, res result, err error) {
+	if err := writeResult(out, format, res, err); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", apierror.Message(err))
+		os.Exit(1)
+	}
+}
+
+// writeResult writes res in format, returning err when format has no place for it
+func writeResult(out io.Writer, format string, res result, err error) error {
+	switch format {
+	case formatRaycast:
+		item := scriptFilterItem{UID: "result", Title: oneLine(res.Text), Subtitle: resultSubtitle(res), Arg: res.Text, Valid: true}
+		if err != nil {
+			item = scriptFilterItem{UID: "error", Title: "grammr failed", Subtitle: apierror.Message(err)}
+		} else {
+			item.Text = &scriptFilterText{Copy: res.Text, LargeType: res.Text}
+		}
+		return json.NewEncoder(out).Encode(scriptFilter{Items: []scriptFilterItem{item}})
+	case formatJSON:
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(out).Encode(res)
+	}
+	if err != nil {
+		return err
+	}
+	if res.File != "" {
+		// The text of a document is in the copy
+		_, err = fmt.Fprintln(out, res.File)
+		return err
+	}
+	_, err = fmt.Fprintln(out, res.Text)
+	return err
+}
+
+// resultSubtitle sums up a result, like "3 changes: 2 spelling, 1 grammar" or "Translated to spanish"
+func resultSubtitle(res result) string {
+	if res.Language != "" {
+		return "Translated to " + res.Language
+	}
+	if len(res.Changes) == 0 {
+		return "No changes"
+	}
+	counts := make(map[string]int)
+	for _, change := range res.Changes {
+		counts[change.Category]++
+	}
+	var parts []string
+	for _, category := range corrector.ChangeCategories {
+		if counts[category] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[category], category))
+		}
+	}
+	noun := "changes"
+	if len(res.Changes) == 1 {
+		noun = "change"
+	}
+	return fmt.Sprintf("%d %s: %s", len(res.Changes), noun, strings.Join(parts, ", "))
+}
+
+// oneLine joins the lines of text, for titles that show a single line
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
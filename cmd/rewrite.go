@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/langdetect"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rewriteTone     string
+	rewriteShorten  int
+	rewriteExpand   bool
+	rewriteSimplify bool
+)
+
+var rewriteCmd = &cobra.Command{
+	Use:   "rewrite [text]",
+	Short: "Rewrite text in a different tone, or shorten, expand or simplify it",
+	Long: fmt.Sprintf(`Rewrite text in a different tone, or shorten, expand or simplify it. The text is taken from the arguments or, if none are given, from stdin.
+
+Supported tones: %s`, strings.Join(corrector.Tones, ", ")),
+	Run: func(cmd *cobra.Command, args []string) {
+		text, err := readInputText(args, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := runRewrite(text, rewriteTone, rewriteAction()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", apierror.Message(err))
+			os.Exit(1)
+		}
+	},
+}
+
+// readInputText returns the text passed as arguments, falling back to stdin
+func readInputText(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 0 {
+		text := strings.TrimSpace(strings.Join(args, " "))
+		if text == "" {
+			return "", fmt.Errorf("no text provided")
+		}
+		return text, nil
+	}
+
+	// Don't block waiting for input from an interactive terminal
+	if f, ok := stdin.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return "", fmt.Errorf("no text provided: pass it as an argument or pipe it via stdin")
+		}
+	}
+
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return "", fmt.Errorf("no text provided")
+	}
+	return text, nil
+}
+
+// rewriteAction returns the rewrite action selected by flags, or an empty string for a tone rewrite
+func rewriteAction() string {
+	switch {
+	case rewriteShorten != 0:
+		return corrector.ActionShorten
+	case rewriteExpand:
+		return corrector.ActionExpand
+	case rewriteSimplify:
+		return corrector.ActionSimplify
+	default:
+		return ""
+	}
+}
+
+func runRewrite(text, tone, action string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	apiKey := cfg.GetAPIKey()
+	// Offline, nothing is sent, so no key is needed
+	if err := validation.ValidateAPIKey(apiKey); err != nil && !cfg.Offline {
+		return err
+	}
+
+	auditLog, err := cfg.OpenAudit()
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	prov, err := provider.New(cfg.Provider, apiKey, provider.Options{Deterministic: cfg.Deterministic, Audit: auditLog, Redact: cfg.RedactPII, Retries: cfg.RequestRetries, Offline: cfg.Offline})
+	if err != nil {
+		return err
+	}
+
+	corr, err := corrector.NewWithCustomStyles(prov, cfg.Model, cfg.Style, cfg.Language, cfg.CustomStylePrompts(), nil)
+	if err != nil {
+		return err
+	}
+	if cfg.DetectLanguage {
+		if language, ok := langdetect.Detect(text); ok {
+			corr = corr.WithLanguage(language)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ScaledTimeout(cfg.CorrectionTimeout(), text))
+	defer cancel()
+
+	printChunk := func(chunk string) {
+		fmt.Print(chunk)
+	}
+	if action != "" {
+		err = corr.StreamTransform(ctx, text, action, rewriteShorten, printChunk)
+	} else {
+		err = corr.StreamRewrite(ctx, text, tone, printChunk)
+	}
+	fmt.Println()
+	return err
+}
+
+func init() {
+	rewriteCmd.Flags().StringVarP(&rewriteTone, "tone", "t", "concise", "tone to rewrite in ("+strings.Join(corrector.Tones, ", ")+")")
+	rewriteCmd.Flags().IntVar(&rewriteShorten, "shorten", 0, "shorten to about this percentage of the original length")
+	rewriteCmd.Flags().BoolVar(&rewriteExpand, "expand", false, "expand the text with more detail")
+	rewriteCmd.Flags().BoolVar(&rewriteSimplify, "simplify", false, "simplify the text to plain language")
+	rewriteCmd.MarkFlagsMutuallyExclusive("tone", "shorten", "expand", "simplify")
+	rootCmd.AddCommand(rewriteCmd)
+}
@@ -1,22 +1,33 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/tracing"
 	"github.com/maximbilan/grammr/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+// configFile is the config file given with --config
+var configFile string
+
+// offline sends no requests, like the offline setting
+var offline bool
+
+// noColor renders the TUI without colors, borders or symbols, like the accessible setting
+var noColor bool
+
 var rootCmd = &cobra.Command{
 	Use:   "grammr",
 	Short: "Lightning-fast AI grammar checker",
 	Long:  `grammr is a TUI grammar checker that uses OpenAI to fix your writing instantly.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := ui.Run(); err != nil {
+		if err := ui.Run(ui.Options{Accessible: noColor}); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -55,6 +66,9 @@ var setCmd = &cobra.Command{
 			displayValue = maskSecret(args[1])
 		}
 		fmt.Printf("Set %s = %s\n", args[0], displayValue)
+		if isSensitiveConfigKey(args[0]) && args[1] != "" && !config.InKeychain(args[0]) && config.Get("key_storage") != config.KeyStorageFile {
+			fmt.Fprintf(os.Stderr, "Warning: no keychain available, %s is stored in the config file\n", args[0])
+		}
 	},
 }
 
@@ -81,13 +95,12 @@ var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize configuration file",
 	Run: func(cmd *cobra.Command, args []string) {
-		home, err := os.UserHomeDir()
+		configPath, err := config.Dir()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		configPath := filepath.Join(home, ".grammr")
 		if err := os.MkdirAll(configPath, 0700); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -99,25 +112,81 @@ var initCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if err := config.Save(cfg); err != nil {
+		if err := config.Save(cfg); errors.Is(err, config.ErrNoKeychain) {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Configuration initialized at %s\n", filepath.Join(configPath, "config.yaml"))
+		file, err := config.File()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Configuration initialized at %s\n", file)
 		fmt.Println("Set your API key with: grammr config set api_key YOUR_KEY")
 	},
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default ~/.grammr/config.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "send no requests: corrections come from the cache only")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "render without colors, borders or symbols, for screen readers")
+	cobra.OnInitialize(func() {
+		config.SetConfigFile(configFile)
+		config.SetOffline(offline)
+	})
 	configCmd.AddCommand(setCmd)
 	configCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(initCmd)
 }
 
+// shutdownTracing sends the spans not sent yet, see startTracing
+var shutdownTracing = func(context.Context) error { return nil }
+
+// startTracing sends spans when the OpenTelemetry variables ask for it, unless grammr is offline.
+// It never stops grammr.
+func startTracing(cmd *cobra.Command, args []string) {
+	if !tracing.Enabled(os.Getenv) {
+		return
+	}
+	if cfg, err := config.Load(); err != nil || cfg.Offline {
+		return
+	}
+	shutdown, err := tracing.Setup(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tracing is off: %v\n", err)
+		return
+	}
+	shutdownTracing = shutdown
+}
+
+// warnConfig warns about settings of the config that are ignored, and an upgrade of the config
+// file that couldn't be saved
+func warnConfig(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	if warning := cfg.ShadowedStylesWarning(); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if warning := cfg.UnsavedUpgradeWarning(); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Tracing starts once the flags and the config say whether grammr is offline
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		warnConfig(cmd, args)
+		startTracing(cmd, args)
+	}
+	err := rootCmd.Execute()
+	_ = shutdownTracing(context.Background())
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/maximbilan/grammr/internal/cache"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/rpc"
+	"github.com/spf13/cobra"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Answer JSON-RPC requests on stdin, for editor plugins",
+	Long: `Answer newline-delimited JSON-RPC 2.0 requests on stdin with responses on stdout, one message
+a line, until stdin is closed. Editor plugins run it as a child process instead of managing a server.
+
+  correct    {"text": "...", "stream": false}            → {"corrected": "...", "cached": false}
+  translate  {"text": "...", "language": "spanish"}      → {"translated": "...", "language": "spanish"}
+  diff       {"original": "...", "corrected": "..."}     → {"changes": [{"original", "corrected", "category"}]}
+
+With "stream": true, parts of the result are sent as they arrive in "chunk" notifications,
+{"id": <request id>, "text": "..."}, before the response. "$/cancelRequest" {"id": <request id>}
+stops a request. Requests run concurrently and use the provider, model, style and cache of your
+config.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := runRPC(ctx, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runRPC(ctx context.Context, in io.Reader, out io.Writer) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	srv, closeServer, err := newRPCServer(cfg, nil)
+	if err != nil {
+		return err
+	}
+	defer closeServer()
+	return srv.Serve(ctx, in, out)
+}
+
+// newRPCServer creates the JSON-RPC server of grammr rpc and grammrd from config, calling onError,
+// unless it is nil, when a request fails. The returned function saves the statistics of its cache
+// when it is done.
+func newRPCServer(cfg *config.Config, onError func(method string, err error)) (*rpc.Server, func(), error) {
+	b, err := newBackend(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	cor, err := b.corrector()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var c *cache.Cache
+	closeServer := func() {}
+	if cfg.CacheEnabled {
+		if c, err = openCache(cfg, cfg.CacheBackend); err != nil {
+			return nil, nil, fmt.Errorf("failed to create cache: %w", err)
+		}
+		// Statistics are informational, so failing to save them isn't an error
+		closeServer = func() { _ = c.SaveStats() }
+	}
+
+	return rpc.New(cor, c, rpc.Options{
+		Model:               cfg.Model,
+		Translator:          b.translator,
+		TranslationLanguage: cfg.TranslationLanguage,
+		Timeout:             cfg.CorrectionTimeout(),
+		TranslationTimeout:  cfg.TranslationTimeout(),
+		ScaleTimeouts:       cfg.ScaleTimeouts,
+		DetectLanguage:      cfg.DetectLanguage,
+		OnError:             onError,
+	}), closeServer, nil
+}
+
+func init() {
+	rootCmd.AddCommand(rpcCmd)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/cache"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// serveShutdownTimeout is how long corrections in progress may take to finish on shutdown
+const serveShutdownTimeout = 30 * time.Second
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Correct texts over HTTP",
+	Long: `Correct texts over HTTP, for editors, bots and other tools on this machine or a team server.
+
+  POST /v1/correct  {"text": "..."} answers {"original": "...", "corrected": "...", "cached": false},
+                    or with Accept: text/event-stream, chunk events as the correction arrives and a
+                    done event with the response and its changes
+  GET  /healthz     reports that the server is up
+  GET  /metrics     serves Prometheus metrics: requests, latencies, cache hits and estimated tokens
+
+Corrections use the provider, model, style and cache of your config, and share the rate limits of
+other grammr processes.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := runServe(ctx, serveAddr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runServe(ctx context.Context, addr string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	b, err := newBackend(cfg)
+	if err != nil {
+		return err
+	}
+	cor, err := b.corrector()
+	if err != nil {
+		return err
+	}
+
+	var c *cache.Cache
+	if cfg.CacheEnabled {
+		cacheDir, err := cfg.CachePath()
+		if err != nil {
+			return fmt.Errorf("failed to create cache: %w", err)
+		}
+		if c, err = cache.NewInDir(cacheDir, cfg.CacheTTLDays, cfg.CacheBackend); err != nil {
+			return fmt.Errorf("failed to create cache: %w", err)
+		}
+		// Statistics are informational, so failing to save them isn't an error
+		defer func() { _ = c.SaveStats() }()
+	}
+
+	srv := &http.Server{
+		Addr: addr,
+		Handler: server.New(cor, c, server.Options{
+			Provider:       cfg.Provider,
+			Model:          cfg.Model,
+			Timeout:        cfg.CorrectionTimeout(),
+			ScaleTimeouts:  cfg.ScaleTimeouts,
+			DetectLanguage: cfg.DetectLanguage,
+		}).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "grammr is listening on http://%s\n", addr)
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8787", "address to listen on")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,190 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Kind is the category of a failed request
+type Kind int
+
+// Kinds of failed requests
+const (
+	KindUnknown       Kind = iota
+	KindInvalidKey         // The API key is wrong, revoked or not allowed to make the request
+	KindQuotaExceeded      // The account is out of quota or credit
+	KindRateLimited        // The provider refused the request for now because of its rate limit
+	KindModelNotFound      // The model doesn't exist or the key has no access to it
+	KindContextLength      // The text doesn't fit in the model's context window
+	KindNetwork            // The provider couldn't be reached or didn't answer in time
+)
+
+var kindNames = map[Kind]string{
+	KindUnknown:       "unknown",
+	KindInvalidKey:    "invalid key",
+	KindQuotaExceeded: "quota exceeded",
+	KindRateLimited:   "rate limited",
+	KindModelNotFound: "model not found",
+	KindContextLength: "context length",
+	KindNetwork:       "network",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Error is a failed provider request of a known kind, which the TUI and CLI explain with
+// Guidance instead of showing the raw SDK error. The SDK error stays available through errors.As.
+type Error struct {
+	Kind     Kind
+	Provider string // "openai" or "anthropic"
+	Model    string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Guidance tells the user what went wrong and what to do about it
+func (e *Error) Guidance() string {
+	name := providerName(e.Provider)
+	switch e.Kind {
+	case KindInvalidKey:
+		key := "api_key"
+		if e.Provider == "anthropic" {
+			key = "anthropic_api_key"
+		}
+		return fmt.Sprintf("%s rejected the API key. Run: grammr config set %s YOUR_KEY", name, key)
+	case KindQuotaExceeded:
+		return fmt.Sprintf("Your %s account is out of quota or credit. Check your plan and billing with %s.", name, name)
+	case KindRateLimited:
+		return fmt.Sprintf("%s is rate limiting your requests. Wait a moment, or lower rate_limit_requests.", name)
+	case KindModelNotFound:
+		return fmt.Sprintf("Model %s isn't available for your key. Press M to pick another, or run: grammr config set model NAME", e.Model)
+	case KindContextLength:
+		return fmt.Sprintf("The text is too long for %s. Correct it in smaller parts, or pick a model with a larger context.", e.Model)
+	case KindNetwork:
+		if errors.Is(e.Err, context.DeadlineExceeded) {
+			return fmt.Sprintf("%s didn't answer in time. Check your connection, or raise request_timeout_seconds (or correction_timeout_seconds and translation_timeout_seconds).", name)
+		}
+		return fmt.Sprintf("Couldn't reach %s. Check your internet connection and proxy settings.", name)
+	}
+	return e.Err.Error()
+}
+
+// Response describes an error response of a provider's API
+type Response struct {
+	Status  int    // HTTP status code
+	Code    string // Error code or type, such as "invalid_api_key" or "not_found_error"
+	Message string // Message of the error
+}
+
+// Classify wraps err in an Error when the response it came from or the way it failed is of a
+// known kind. Other errors are returned unchanged. resp is nil when there was no response.
+func Classify(provider, model string, resp *Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	kind := KindUnknown
+	if resp != nil {
+		kind = responseKind(*resp)
+	} else if isNetwork(err) {
+		kind = KindNetwork
+	}
+	if kind == KindUnknown {
+		return err
+	}
+	return &Error{Kind: kind, Provider: provider, Model: model, Err: err}
+}
+
+// KindOf returns the kind of err, KindUnknown when it wasn't classified
+func KindOf(err error) Kind {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Kind
+	}
+	return KindUnknown
+}
+
+// Transient reports whether err may not happen again when the request is sent again: the
+// provider rate limited it or couldn't be reached. Requests that ran out of time aren't.
+func Transient(err error) bool {
+	switch KindOf(err) {
+	case KindRateLimited:
+		return true
+	case KindNetwork:
+		return !errors.Is(err, context.DeadlineExceeded)
+	}
+	return false
+}
+
+// Message returns what to show the user for err: the guidance of a classified error, otherwise
+// the error itself
+func Message(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Guidance()
+	}
+	return err.Error()
+}
+
+// responseKind sorts an error response by its code first, since status codes are shared by
+// several kinds, then by its status
+func responseKind(resp Response) Kind {
+	code := strings.ToLower(resp.Code)
+	message := strings.ToLower(resp.Message)
+	switch {
+	case code == "insufficient_quota" || code == "billing_error" || strings.Contains(message, "credit balance"):
+		return KindQuotaExceeded
+	case code == "context_length_exceeded" || strings.Contains(message, "context length") ||
+		strings.Contains(message, "prompt is too long") || strings.Contains(message, "too many tokens"):
+		return KindContextLength
+	case code == "model_not_found":
+		return KindModelNotFound
+	case code == "invalid_api_key" || code == "authentication_error" || code == "permission_error":
+		return KindInvalidKey
+	}
+
+	switch resp.Status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return KindInvalidKey
+	case http.StatusTooManyRequests:
+		return KindRateLimited
+	case http.StatusNotFound:
+		// Completions are only ever not found for a model
+		return KindModelNotFound
+	}
+	return KindUnknown
+}
+
+// isNetwork reports whether err means the provider couldn't be reached or didn't answer in time
+func isNetwork(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// providerName returns the name of a provider as its users know it
+func providerName(provider string) string {
+	switch provider {
+	case "anthropic":
+		return "Anthropic"
+	case "", "openai":
+		return "OpenAI"
+	}
+	return provider
+}
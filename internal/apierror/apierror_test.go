@@ -0,0 +1,116 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *Response
+		err  error
+		want Kind
+	}{
+		{name: "invalid key", resp: &Response{Status: 401, Code: "invalid_api_key"}, want: KindInvalidKey},
+		{name: "unauthorized", resp: &Response{Status: 401}, want: KindInvalidKey},
+		{name: "anthropic permission", resp: &Response{Status: 403, Code: "permission_error"}, want: KindInvalidKey},
+		{name: "insufficient quota", resp: &Response{Status: 429, Code: "insufficient_quota"}, want: KindQuotaExceeded},
+		{name: "anthropic credit", resp: &Response{Status: 400, Code: "invalid_request_error", Message: "Your credit balance is too low"}, want: KindQuotaExceeded},
+		{name: "rate limited", resp: &Response{Status: 429, Code: "rate_limit_exceeded"}, want: KindRateLimited},
+		{name: "model not found", resp: &Response{Status: 404, Code: "model_not_found"}, want: KindModelNotFound},
+		{name: "anthropic model not found", resp: &Response{Status: 404, Code: "not_found_error", Message: "model: claude-9"}, want: KindModelNotFound},
+		{name: "context length", resp: &Response{Status: 400, Code: "context_length_exceeded"}, want: KindContextLength},
+		{name: "anthropic prompt too long", resp: &Response{Status: 400, Code: "invalid_request_error", Message: "prompt is too long: 210000 tokens"}, want: KindContextLength},
+		{name: "server error", resp: &Response{Status: 500, Code: "server_error"}, want: KindUnknown},
+		{name: "timeout", err: fmt.Errorf("stream error: %w", context.DeadlineExceeded), want: KindNetwork},
+		{name: "no connection", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: KindNetwork},
+		{name: "cancelled", err: context.Canceled, want: KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err
+			if err == nil {
+				err = errors.New("request failed")
+			}
+			got := Classify("openai", "gpt-4o", tt.resp, err)
+			if KindOf(got) != tt.want {
+				t.Errorf("Classify() kind = %v, want %v", KindOf(got), tt.want)
+			}
+			if !errors.Is(got, err) {
+				t.Errorf("Classify() = %v, want it to wrap %v", got, err)
+			}
+		})
+	}
+}
+
+func TestClassifyNil(t *testing.T) {
+	if err := Classify("openai", "gpt-4o", &Response{Status: 401}, nil); err != nil {
+		t.Errorf("Classify(nil) = %v, want nil", err)
+	}
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "rate limited", err: &Error{Kind: KindRateLimited, Err: errors.New("429")}, want: true},
+		{name: "no connection", err: fmt.Errorf("failed to correct: %w", &Error{Kind: KindNetwork, Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}), want: true},
+		{name: "timeout", err: &Error{Kind: KindNetwork, Err: context.DeadlineExceeded}, want: false},
+		{name: "invalid key", err: &Error{Kind: KindInvalidKey, Err: errors.New("401")}, want: false},
+		{name: "unclassified", err: errors.New("boom"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transient(tt.err); got != tt.want {
+				t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "model not found",
+			err:  fmt.Errorf("failed to correct: %w", &Error{Kind: KindModelNotFound, Provider: "openai", Model: "gpt-5", Err: errors.New("404")}),
+			want: "Model gpt-5 isn't available for your key",
+		},
+		{
+			name: "anthropic key",
+			err:  &Error{Kind: KindInvalidKey, Provider: "anthropic", Err: errors.New("401")},
+			want: "Anthropic rejected the API key. Run: grammr config set anthropic_api_key YOUR_KEY",
+		},
+		{
+			name: "timeout",
+			err:  &Error{Kind: KindNetwork, Provider: "openai", Err: context.DeadlineExceeded},
+			want: "raise request_timeout_seconds",
+		},
+		{
+			name: "unclassified",
+			err:  errors.New("no response from API"),
+			want: "no response from API",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Message(tt.err); !strings.Contains(got, tt.want) {
+				t.Errorf("Message() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/cache"
+)
+
+const (
+	// DefaultFile is the name of the audit log in ~/.grammr
+	DefaultFile = "audit.log"
+
+	// filePerm keeps the audit log readable by the user only, though its entries are encrypted
+	filePerm os.FileMode = 0600
+	dirPerm  os.FileMode = 0700
+	// idSize is the number of random bytes of an entry ID
+	idSize = 6
+)
+
+// Message is one message of a request, as sent to the provider
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Entry records one request sent to a provider
+type Entry struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+}
+
+// Chars returns how many characters of text the request sent
+func (e Entry) Chars() int {
+	chars := 0
+	for _, message := range e.Messages {
+		chars += len([]rune(message.Content))
+	}
+	return chars
+}
+
+// Log appends the requests sent to providers to a file, one entry encrypted with the cache key a
+// line, so what was sent where and when can be shown later. A nil Log records nothing.
+type Log struct {
+	mu   sync.Mutex
+	path string
+	key  []byte
+}
+
+// New returns the audit log in path, encrypting entries with key
+func New(path string, key []byte) *Log {
+	return &Log{path: path, key: key}
+}
+
+// Path returns the file of the log
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record appends a request of model to provider with messages, and returns its entry
+func (l *Log) Record(provider, model string, messages []Message) (Entry, error) {
+	if l == nil {
+		return Entry{}, nil
+	}
+	id, err := newID()
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := Entry{ID: id, Time: time.Now().UTC(), Provider: provider, Model: model, Messages: messages}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.append(entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// Entries returns every entry of the log, oldest first. A missing log has none.
+func (l *Log) Entries() ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.read()
+}
+
+// Find returns the entry with id
+func (l *Log) Find(id string) (Entry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("no audit entry %s", id)
+}
+
+// Purge removes the entries recorded before before, or all of them when before is zero, and
+// returns how many were removed
+func (l *Log) Purge(before time.Time) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries, err := l.read()
+	if err != nil {
+		return 0, err
+	}
+	var kept []Entry
+	if !before.IsZero() {
+		for _, entry := range entries {
+			if !entry.Time.Before(before) {
+				kept = append(kept, entry)
+			}
+		}
+	}
+	if len(kept) == len(entries) {
+		return 0, nil
+	}
+	if len(kept) == 0 {
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, fmt.Errorf("failed to remove audit log: %w", err)
+		}
+		return len(entries), nil
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range kept {
+		line, err := l.encode(entry)
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(line)
+	}
+	// Replace the log at once, so a failed purge leaves it whole
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), filePerm); err != nil {
+		return 0, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return len(entries) - len(kept), nil
+}
+
+// append writes entry at the end of the log; l.mu must be held
+func (l *Log) append(entry Entry) error {
+	line, err := l.encode(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), dirPerm); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	// Appends of a line are atomic, so other grammr processes can record at the same time
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// encode encrypts entry into a line of the log
+func (l *Log) encode(entry Entry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	line, err := cache.Encrypt(l.key, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt audit entry: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+// read decrypts the entries of the log; l.mu must be held
+func (l *Log) read() ([]Entry, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	// Entries hold whole texts, far longer than the default line limit
+	scanner.Buffer(nil, 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		data, err := cache.Decrypt(l.key, line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt line %d of %s: %w", n, l.path, err)
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse line %d of %s: %w", n, l.path, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+func newID() (string, error) {
+	id := make([]byte, idSize)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate audit entry ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testKey = bytes.Repeat([]byte{7}, 32)
+
+func TestRecordAndFind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", DefaultFile)
+	log := New(path, testKey)
+
+	messages := []Message{
+		{Role: "system", Content: "Fix the grammar"},
+		{Role: "user", Content: "Secret plans for teh launch"},
+	}
+	recorded, err := log.Record("openai", "gpt-4o", messages)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if _, err := log.Record("anthropic", "claude-3-haiku-20240307", []Message{{Role: "user", Content: "Another"}}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	if bytes.Contains(data, []byte("Secret plans")) || bytes.Contains(data, []byte("gpt-4o")) {
+		t.Error("audit log stores requests in plain text")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != filePerm {
+		t.Errorf("audit log permissions = %o, want %o", perm, filePerm)
+	}
+
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Entries() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Provider != "openai" || entries[1].Provider != "anthropic" {
+		t.Errorf("Entries() = %+v, want the openai request first", entries)
+	}
+
+	found, err := log.Find(recorded.ID)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if found.Model != "gpt-4o" || len(found.Messages) != 2 || found.Messages[1].Content != messages[1].Content {
+		t.Errorf("Find() = %+v, want the recorded request", found)
+	}
+	if found.Chars() != len("Fix the grammar")+len("Secret plans for teh launch") {
+		t.Errorf("Chars() = %d", found.Chars())
+	}
+	if _, err := log.Find("missing"); err == nil {
+		t.Error("Find() of an unknown ID should fail")
+	}
+
+	if _, err := New(path, bytes.Repeat([]byte{8}, 32)).Entries(); err == nil {
+		t.Error("Entries() with another key should fail")
+	}
+}
+
+func TestEntriesMissingLog(t *testing.T) {
+	entries, err := New(filepath.Join(t.TempDir(), DefaultFile), testKey).Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Entries() = %v, want none", entries)
+	}
+}
+
+func TestNilLogRecordsNothing(t *testing.T) {
+	var log *Log
+	if _, err := log.Record("openai", "gpt-4o", []Message{{Role: "user", Content: "text"}}); err != nil {
+		t.Errorf("Record() error = %v", err)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	tests := []struct {
+		name      string
+		before    time.Duration // Purge entries older than this, 0 for all
+		removed   int
+		remaining int
+	}{
+		{name: "all", before: 0, removed: 3, remaining: 0},
+		{name: "older than a day", before: 24 * time.Hour, removed: 2, remaining: 1},
+		{name: "none old enough", before: 30 * 24 * time.Hour, removed: 0, remaining: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), DefaultFile)
+			log := New(path, testKey)
+			for _, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, 0} {
+				entry, err := log.Record("openai", "gpt-4o", []Message{{Role: "user", Content: "text"}})
+				if err != nil {
+					t.Fatalf("Record() error = %v", err)
+				}
+				ageEntry(t, log, entry.ID, age)
+			}
+
+			var before time.Time
+			if tt.before > 0 {
+				before = time.Now().Add(-tt.before)
+			}
+			removed, err := log.Purge(before)
+			if err != nil {
+				t.Fatalf("Purge() error = %v", err)
+			}
+			if removed != tt.removed {
+				t.Errorf("Purge() removed %d entries, want %d", removed, tt.removed)
+			}
+			entries, err := log.Entries()
+			if err != nil {
+				t.Fatalf("Entries() error = %v", err)
+			}
+			if len(entries) != tt.remaining {
+				t.Errorf("%d entries remain, want %d", len(entries), tt.remaining)
+			}
+			if tt.remaining == 0 {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Error("Purge() of every entry should remove the audit log")
+				}
+			}
+		})
+	}
+}
+
+// ageEntry rewrites the time of the entry with id to age ago
+func ageEntry(t *testing.T, log *Log, id string, age time.Duration) {
+	t.Helper()
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(log.path); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			entry.Time = time.Now().Add(-age)
+		}
+		if err := log.append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// boltFile is the database of the bolt backend, inside the cache directory
+	boltFile = "cache.db"
+	// boltTimeout is how long to wait for another grammr process to release the database
+	boltTimeout = 2 * time.Second
+)
+
+var entriesBucket = []byte("entries")
+
+// boltStore keeps all entries in a single bbolt database. The database is only opened for the
+// duration of each operation, so several grammr processes can share it.
+type boltStore struct {
+	path string
+}
+
+func (s boltStore) open(readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(s.path, CacheFilePerm, &bolt.Options{Timeout: boltTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database: %w", err)
+	}
+	return db, nil
+}
+
+// update runs fn on the entries bucket in a read-write transaction
+func (s boltStore) update(fn func(*bolt.Bucket) error) error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(entriesBucket)
+		if err != nil {
+			return fmt.Errorf("failed to create cache bucket: %w", err)
+		}
+		return fn(bucket)
+	})
+}
+
+func (s boltStore) read(hash string) ([]byte, error) {
+	// Opening a database read-only doesn't create it
+	if _, err := os.Stat(s.path); err != nil {
+		return nil, err
+	}
+	db, err := s.open(true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var data []byte
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(entriesBucket)
+		if bucket == nil {
+			return nil
+		}
+		// Values are only valid during the transaction
+		if value := bucket.Get([]byte(hash)); value != nil {
+			data = append([]byte(nil), value...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("cache entry %s: %w", hash, os.ErrNotExist)
+	}
+	return data, nil
+}
+
+func (s boltStore) write(hash string, data []byte) error {
+	if !isValidHash(hash) {
+		return fmt.Errorf("invalid hash format")
+	}
+	return s.update(func(bucket *bolt.Bucket) error {
+		return bucket.Put([]byte(hash), data)
+	})
+}
+
+func (s boltStore) remove(hashes ...string) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+	return s.update(func(bucket *bolt.Bucket) error {
+		for _, hash := range hashes {
+			if err := bucket.Delete([]byte(hash)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s boltStore) each(fn func(hash string, data []byte) error) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+	db, err := s.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(entriesBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			return fn(string(key), value)
+		})
+	})
+}
+
+func (s boltStore) clear() (int, error) {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return 0, nil
+	}
+	db, err := s.open(false)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	removed := 0
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(entriesBucket)
+		if bucket == nil {
+			return nil
+		}
+		removed = bucket.Stats().KeyN
+		if err := tx.DeleteBucket(entriesBucket); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+func (s boltStore) usage() (int, int64, error) {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read cache database: %w", err)
+	}
+	db, err := s.open(true)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer db.Close()
+
+	entries := 0
+	err = db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(entriesBucket); bucket != nil {
+			entries = bucket.Stats().KeyN
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return entries, info.Size(), nil
+}
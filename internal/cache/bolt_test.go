@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBoltBackend(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cache, err := NewWithBackend(1, BackendBolt)
+	if err != nil {
+		t.Fatalf("NewWithBackend() error = %v", err)
+	}
+
+	hash := cache.Hash("Hello world")
+	if got := cache.Get(hash); got != "" {
+		t.Fatalf("Get() before Set() = %q, want empty string", got)
+	}
+	if err := cache.Set(hash, "Hello world", "Hello, world"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := cache.Get(hash); got != "Hello, world" {
+		t.Fatalf("Get() = %q, want %q", got, "Hello, world")
+	}
+
+	// Everything lives in a single file
+	files, err := filepath.Glob(filepath.Join(cache.dir, "*"))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != boltFile {
+		t.Fatalf("cache directory = %v, want only %s", files, boltFile)
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		t.Errorf("cache database mode = %o, want no access for group and others", info.Mode().Perm())
+	}
+
+	removed, err := cache.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Clear() removed %d entries, want 1", removed)
+	}
+	if got := cache.Get(hash); got != "" {
+		t.Errorf("Get() after Clear() = %q, want empty string", got)
+	}
+}
+
+func TestBoltBackendExpires(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cache, err := NewWithBackend(1, BackendBolt)
+	if err != nil {
+		t.Fatalf("NewWithBackend() error = %v", err)
+	}
+	hash := cache.Hash("Hello world")
+	if err := cache.Set(hash, "Hello world", "Hello, world"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	cache.ttl = -time.Second
+	if got := cache.Get(hash); got != "" {
+		t.Fatalf("Get() expired entry = %q, want empty string", got)
+	}
+	cache.ttl = time.Hour
+	if got := cache.Get(hash); got != "" {
+		t.Fatalf("Get() should have removed the expired entry, got %q", got)
+	}
+}
+
+func TestBoltBackendConcurrentAccess(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Two caches on the same database, like the TUI and a daemon running at once
+	first, err := NewWithBackend(1, BackendBolt)
+	if err != nil {
+		t.Fatalf("NewWithBackend() error = %v", err)
+	}
+	second, err := NewWithBackend(1, BackendBolt)
+	if err != nil {
+		t.Fatalf("NewWithBackend() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 5; i++ {
+		for _, c := range []*Cache{first, second} {
+			wg.Add(1)
+			go func(c *Cache, i int) {
+				defer wg.Done()
+				text := fmt.Sprintf("text %d", i)
+				if err := c.Set(c.Hash(text), text, text+"!"); err != nil {
+					errs <- err
+				}
+			}(c, i)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		text := fmt.Sprintf("text %d", i)
+		if got := second.Get(first.Hash(text)); got != text+"!" {
+			t.Errorf("Get(%q) = %q, want %q", text, got, text+"!")
+		}
+	}
+}
+
+func TestNewWithBackendRejectsUnknownBackend(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := NewWithBackend(1, "redis"); err == nil {
+		t.Fatal("NewWithBackend() expected an error for an unknown backend")
+	}
+	if _, err := NewWithBackend(1, ""); err != nil {
+		t.Fatalf("NewWithBackend() with no backend should use files, got %v", err)
+	}
+}
@@ -1,17 +1,13 @@
 package cache
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,7 +23,14 @@ const (
 type Cache struct {
 	dir     string
 	ttl     time.Duration
-	encKey  []byte // Encryption key derived from user's home directory
+	encKey    []byte // Encryption key kept in the OS keychain, see loadKey
+	legacyKey []byte // Key older versions encrypted entries with
+	entries   store  // Where entries are kept, one file per entry when nil
+	hot       *hotCache
+
+	mu      sync.Mutex // Guards the statistics, lookups run in several goroutines
+	session Stats      // Lookups since the cache was created
+	unsaved Stats      // Lookups not yet added to the lifetime statistics
 }
 
 type CacheEntry struct {
@@ -38,6 +41,16 @@ type CacheEntry struct {
 }
 
 func New(ttlDays int) (*Cache, error) {
+	return NewWithBackend(ttlDays, BackendFiles)
+}
+
+// NewWithBackend creates a cache that keeps its entries in backend, see Backends
+func NewWithBackend(ttlDays int, backend string) (*Cache, error) {
+	return NewInDir("", ttlDays, backend)
+}
+
+// NewInDir creates a cache in dir, or in DefaultDir when dir is empty
+func NewInDir(dir string, ttlDays int, backend string) (*Cache, error) {
 	if ttlDays < 0 {
 		return nil, fmt.Errorf("cache TTL days must be non-negative, got %d", ttlDays)
 	}
@@ -47,27 +60,83 @@ func New(ttlDays int) (*Cache, error) {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	cacheDir := filepath.Join(home, ".grammr", "cache")
+	cacheDir := dir
+	if cacheDir == "" {
+		if cacheDir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
 	if err := os.MkdirAll(cacheDir, CacheDirPerm); err != nil {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	// Derive encryption key from user's home directory
-	// This ensures cache is encrypted per-user
-	keyHash := sha256.Sum256([]byte(home + ".grammr.cache.key"))
-	encKey := keyHash[:] // Use first 32 bytes for AES-256
+	encKey, err := LoadKey(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := newStore(backend, cacheDir)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Cache{
-		dir:    cacheDir,
-		ttl:    time.Duration(ttlDays) * 24 * time.Hour,
-		encKey: encKey,
+		dir:     cacheDir,
+		ttl:     time.Duration(ttlDays) * 24 * time.Hour,
+		encKey:    encKey,
+		legacyKey: legacyKey(home),
+		entries:   entries,
+		hot:       newHotCache(hotSize),
 	}, nil
 }
 
+// DefaultDir returns where the cache is kept unless configured otherwise: ~/.grammr/cache, or
+// $XDG_CACHE_HOME/grammr when XDG_CACHE_HOME is set and ~/.grammr/cache doesn't exist yet
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	legacy := filepath.Join(home, ".grammr", "cache")
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	if xdg := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "grammr"), nil
+	}
+	return legacy, nil
+}
+
+// backend returns the store entries are kept in
+func (c *Cache) backend() store {
+	if c.entries == nil {
+		return fileStore{dir: c.dir}
+	}
+	return c.entries
+}
+
+// Hash returns the SHA256 of text. Use CorrectionHash or TranslationHash to key entries, since
+// the same text gives a different result with other settings.
 func (c *Cache) Hash(text string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(text)))
 }
 
+// CorrectionHash returns the key of a correction of text. It includes everything that changes
+// the correction, so switching style, model or language doesn't return an old correction.
+// promptVersion identifies the rest of the prompt, see corrector.PromptVersion.
+func (c *Cache) CorrectionHash(text, style, model, language, promptVersion string) string {
+	key := strings.Join([]string{"correction", style, model, strings.ToLower(language), promptVersion, text}, "\x00")
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+}
+
+// TranslationHash returns the key of a translation of text. Translations live next to
+// corrections, so the key is namespaced to never collide with Hash, and includes everything
+// that changes the translation.
+func (c *Cache) TranslationHash(text, language, model, formality string) string {
+	key := strings.Join([]string{"translation", strings.ToLower(language), model, formality, text}, "\x00")
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+}
+
 func (c *Cache) Get(hash string) string {
 	if hash == "" {
 		return ""
@@ -78,38 +147,66 @@ func (c *Cache) Get(hash string) string {
 		return ""
 	}
 
-	path := filepath.Join(c.dir, hash+".json")
-	// Additional safety check: ensure the resolved path is within cache directory
-	// Use filepath.Clean to resolve any path traversal attempts
-	cleanPath := filepath.Clean(path)
-	if !strings.HasPrefix(cleanPath, c.dir+string(filepath.Separator)) && cleanPath != c.dir {
+	if entry, ok := c.hot.get(hash); ok && !c.expired(entry) {
+		c.record(true, len(entry.Corrected))
+		return entry.Corrected
+	}
+
+	data, err := c.backend().read(hash)
+	if err != nil {
+		c.record(false, 0)
 		return ""
 	}
 
-	data, err := os.ReadFile(path)
+	entry, current, err := c.decode(data)
 	if err != nil {
+		c.record(false, 0)
 		return ""
 	}
 
-	// Try to decrypt the data (backward compatible with unencrypted entries)
-	decryptedData, err := c.decrypt(data)
+	// Check if expired
+	if c.expired(entry) {
+		c.hot.remove(hash)
+		_ = c.backend().remove(hash) // Ignore error on removal
+		c.record(false, 0)
+		return ""
+	}
+
+	// Entries written by older versions are encrypted with the current key once read
+	if !current {
+		_ = c.put(entry) // Ignore error, the entry is still readable as it is
+	} else {
+		c.hot.add(entry)
+	}
+
+	c.record(true, len(entry.Corrected))
+	return entry.Corrected
+}
+
+// decode decrypts and parses a stored entry, and reports whether it is encrypted with the
+// current key
+func (c *Cache) decode(data []byte) (CacheEntry, bool, error) {
+	current := true
+	decryptedData, err := c.decryptWith(c.encKey, data)
+	if err != nil && c.legacyKey != nil {
+		current = false
+		decryptedData, err = c.decryptWith(c.legacyKey, data)
+	}
 	if err != nil {
 		// If decryption fails, try parsing as plain JSON (backward compatibility)
+		current = false
 		decryptedData = data
 	}
 
 	var entry CacheEntry
 	if err := json.Unmarshal(decryptedData, &entry); err != nil {
-		return ""
-	}
-
-	// Check if expired
-	if time.Since(time.Unix(entry.Timestamp, 0)) > c.ttl {
-		_ = os.Remove(path) // Ignore error on removal
-		return ""
+		return CacheEntry{}, false, fmt.Errorf("failed to parse cache entry: %w", err)
 	}
+	return entry, current, nil
+}
 
-	return entry.Corrected
+func (c *Cache) expired(entry CacheEntry) bool {
+	return time.Since(time.Unix(entry.Timestamp, 0)) > c.ttl
 }
 
 // isValidHash validates that the hash is a valid SHA256 hex string (64 characters)
@@ -135,13 +232,16 @@ func (c *Cache) Set(hash, original, corrected string) error {
 		return fmt.Errorf("invalid hash format")
 	}
 
-	entry := CacheEntry{
+	return c.put(CacheEntry{
 		Hash:      hash,
 		Original:  original,
 		Corrected: corrected,
 		Timestamp: time.Now().Unix(),
-	}
+	})
+}
 
+// put encrypts and stores entry
+func (c *Cache) put(entry CacheEntry) error {
 	data, err := json.Marshal(entry)
 	if err != nil {
 		return fmt.Errorf("failed to marshal cache entry: %w", err)
@@ -153,78 +253,31 @@ func (c *Cache) Set(hash, original, corrected string) error {
 		return fmt.Errorf("failed to encrypt cache entry: %w", err)
 	}
 
-	path := filepath.Join(c.dir, hash+".json")
-	// Additional safety check: ensure the resolved path is within cache directory
-	// Use filepath.Clean to resolve any path traversal attempts
-	cleanPath := filepath.Clean(path)
-	if !strings.HasPrefix(cleanPath, c.dir+string(filepath.Separator)) && cleanPath != c.dir {
-		return fmt.Errorf("invalid cache path")
+	if err := c.backend().write(entry.Hash, encryptedData); err != nil {
+		return err
 	}
-
-	if err := os.WriteFile(path, encryptedData, CacheFilePerm); err != nil {
-		return fmt.Errorf("failed to write cache file: %w", err)
-	}
-
+	c.hot.add(entry)
 	return nil
 }
 
+// Clear removes every cached entry, including ones written by older versions that are no longer
+// looked up, and returns how many were removed
+func (c *Cache) Clear() (int, error) {
+	c.hot.clear()
+	return c.backend().clear()
+}
+
 // encrypt encrypts data using AES-GCM
 func (c *Cache) encrypt(plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.encKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, fmt.Errorf("failed to generate nonce: %w", err)
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
-	
-	// Encode as base64 for safe storage
-	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(ciphertext)))
-	base64.StdEncoding.Encode(encoded, ciphertext)
-	
-	return encoded, nil
+	return Encrypt(c.encKey, plaintext)
 }
 
 // decrypt decrypts data using AES-GCM
 func (c *Cache) decrypt(encryptedData []byte) ([]byte, error) {
-	// Try to decode from base64 first
-	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(encryptedData)))
-	n, err := base64.StdEncoding.Decode(decoded, encryptedData)
-	if err != nil {
-		// If base64 decode fails, assume it's not encrypted (backward compatibility)
-		return encryptedData, fmt.Errorf("not base64 encoded (likely unencrypted)")
-	}
-	encryptedData = decoded[:n]
-
-	block, err := aes.NewCipher(c.encKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(encryptedData) < nonceSize {
-		return nil, fmt.Errorf("ciphertext too short")
-	}
-
-	nonce, ciphertext := encryptedData[:nonceSize], encryptedData[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt: %w", err)
-	}
+	return c.decryptWith(c.encKey, encryptedData)
+}
 
-	return plaintext, nil
+// decryptWith decrypts data using AES-GCM with key
+func (c *Cache) decryptWith(key, encryptedData []byte) ([]byte, error) {
+	return Decrypt(key, encryptedData)
 }
@@ -7,8 +7,19 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/zalando/go-keyring"
 )
 
+func TestMain(m *testing.M) {
+	// Tests point HOME at a temporary directory; keep XDG locations from taking over
+	os.Unsetenv("XDG_CONFIG_HOME")
+	os.Unsetenv("XDG_CACHE_HOME")
+	// Keep the encryption key out of the real keychain
+	keyring.MockInit()
+	os.Exit(m.Run())
+}
+
 func TestNew(t *testing.T) {
 	// Create a temporary directory for testing
 	tmpDir := t.TempDir()
@@ -453,3 +464,127 @@ func TestCachePermissions(t *testing.T) {
 		t.Fatalf("cache file should not be accessible by group/others, got mode %o", fileInfo.Mode().Perm())
 	}
 }
+
+func TestTranslationHash(t *testing.T) {
+	cache := &Cache{dir: "/tmp", ttl: 24 * time.Hour}
+
+	base := cache.TranslationHash("Hello world", "french", "gpt-4o", "auto")
+	if !isValidHash(base) {
+		t.Fatalf("TranslationHash() = %q, want a SHA256 hex string", base)
+	}
+	if got := cache.TranslationHash("Hello world", "French", "gpt-4o", "auto"); got != base {
+		t.Errorf("TranslationHash() should ignore the case of the language")
+	}
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "correction of the same text", hash: cache.Hash("Hello world")},
+		{name: "other text", hash: cache.TranslationHash("Hello there", "french", "gpt-4o", "auto")},
+		{name: "other language", hash: cache.TranslationHash("Hello world", "german", "gpt-4o", "auto")},
+		{name: "other model", hash: cache.TranslationHash("Hello world", "french", "gpt-4o-mini", "auto")},
+		{name: "other formality", hash: cache.TranslationHash("Hello world", "french", "gpt-4o", "formal")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.hash == base {
+				t.Errorf("TranslationHash() collides with %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestCorrectionHash(t *testing.T) {
+	cache := &Cache{dir: "/tmp", ttl: 24 * time.Hour}
+
+	base := cache.CorrectionHash("Hello world", "casual", "gpt-4o", "english", "1-abc")
+	if !isValidHash(base) {
+		t.Fatalf("CorrectionHash() = %q, want a SHA256 hex string", base)
+	}
+	if got := cache.CorrectionHash("Hello world", "casual", "gpt-4o", "English", "1-abc"); got != base {
+		t.Errorf("CorrectionHash() should ignore the case of the language")
+	}
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "old text-only key", hash: cache.Hash("Hello world")},
+		{name: "translation", hash: cache.TranslationHash("Hello world", "english", "gpt-4o", "auto")},
+		{name: "other text", hash: cache.CorrectionHash("Hello there", "casual", "gpt-4o", "english", "1-abc")},
+		{name: "other style", hash: cache.CorrectionHash("Hello world", "formal", "gpt-4o", "english", "1-abc")},
+		{name: "other model", hash: cache.CorrectionHash("Hello world", "casual", "gpt-4o-mini", "english", "1-abc")},
+		{name: "other language", hash: cache.CorrectionHash("Hello world", "casual", "gpt-4o", "spanish", "1-abc")},
+		{name: "other prompt", hash: cache.CorrectionHash("Hello world", "casual", "gpt-4o", "english", "2-abc")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.hash == base {
+				t.Errorf("CorrectionHash() collides with %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	tmpDir := t.TempDir()
+	keyHash := sha256.Sum256([]byte(tmpDir + ".grammr.cache.key"))
+	cache := &Cache{dir: tmpDir, ttl: 24 * time.Hour, encKey: keyHash[:]}
+
+	for _, text := range []string{"one", "two", "three"} {
+		if err := cache.Set(cache.Hash(text), text, text); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	// Files that aren't cache entries are left alone
+	other := filepath.Join(tmpDir, "notes.json")
+	if err := os.WriteFile(other, []byte("{}"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	removed, err := cache.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("Clear() removed %d entries, want 3", removed)
+	}
+	if got := cache.Get(cache.Hash("one")); got != "" {
+		t.Errorf("Get() after Clear() = %q, want empty string", got)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Clear() should keep files that aren't cache entries: %v", err)
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "xdg"))
+
+	got, err := DefaultDir()
+	if err != nil {
+		t.Fatalf("DefaultDir() error = %v", err)
+	}
+	if want := filepath.Join(home, "xdg", "grammr"); got != want {
+		t.Errorf("DefaultDir() = %q, want %q", got, want)
+	}
+
+	// An existing ~/.grammr/cache keeps being used
+	legacy := filepath.Join(home, ".grammr", "cache")
+	if err := os.MkdirAll(legacy, CacheDirPerm); err != nil {
+		t.Fatalf("failed to create cache directory: %v", err)
+	}
+	if got, err := DefaultDir(); err != nil || got != legacy {
+		t.Errorf("DefaultDir() = %q, %v, want %q", got, err, legacy)
+	}
+
+	cache, err := NewInDir(filepath.Join(home, "custom"), 1, BackendFiles)
+	if err != nil {
+		t.Fatalf("NewInDir() error = %v", err)
+	}
+	if cache.dir != filepath.Join(home, "custom") {
+		t.Errorf("NewInDir() dir = %q, want the given directory", cache.dir)
+	}
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxImportEntrySize is the largest entry Import accepts, so a corrupt archive can't exhaust memory
+const maxImportEntrySize = 16 << 20
+
+// Export writes every entry that hasn't expired to w as a zstd-compressed tar archive, and returns
+// how many were written. Entries are decrypted, so Import can encrypt them with the key of another
+// machine; keep the archive as private as the cache itself.
+func (c *Cache) Export(w io.Writer) (int, error) {
+	var entries []CacheEntry
+	err := c.backend().each(func(hash string, data []byte) error {
+		if entry, _, err := c.decode(data); err == nil && !c.expired(entry) {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	tw := tar.NewWriter(zw)
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal cache entry: %w", err)
+		}
+		header := &tar.Header{
+			Name:    entry.Hash + ".json",
+			Mode:    int64(CacheFilePerm),
+			Size:    int64(len(data)),
+			ModTime: time.Unix(entry.Timestamp, 0),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return 0, fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return 0, fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return len(entries), nil
+}
+
+// Import adds the entries of an archive written by Export, encrypted with this cache's key, and
+// returns how many were added. Entries keep their age, so ones that have expired since the export
+// are skipped.
+func (c *Cache) Import(r io.Reader) (int, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer zr.Close()
+
+	imported := 0
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return imported, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxImportEntrySize {
+			return imported, fmt.Errorf("archive entry %s is too large", header.Name)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxImportEntrySize))
+		if err != nil {
+			return imported, fmt.Errorf("failed to read archive: %w", err)
+		}
+		var entry CacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return imported, fmt.Errorf("invalid archive entry %s: %w", header.Name, err)
+		}
+		// The hash names the entry on disk, so it must be valid and match the file name
+		if !isValidHash(entry.Hash) || header.Name != entry.Hash+".json" {
+			return imported, fmt.Errorf("invalid archive entry %s", header.Name)
+		}
+		if c.expired(entry) {
+			continue
+		}
+
+		if err := c.put(entry); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+	return imported, nil
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/zalando/go-keyring"
+)
+
+func TestExportImport(t *testing.T) {
+	for _, backend := range Backends {
+		t.Run(backend, func(t *testing.T) {
+			keyring.MockInit()
+			t.Setenv("HOME", t.TempDir())
+			source, err := NewWithBackend(1, backend)
+			if err != nil {
+				t.Fatalf("NewWithBackend() error = %v", err)
+			}
+			hash := source.Hash("I has a apple.")
+			if err := source.Set(hash, "I has a apple.", "I have an apple."); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			// Expired entries are left behind
+			data, _ := json.Marshal(CacheEntry{Hash: source.Hash("old"), Original: "old", Corrected: "Old.", Timestamp: time.Now().Add(-48 * time.Hour).Unix()})
+			encrypted, _ := source.encrypt(data)
+			if err := source.backend().write(source.Hash("old"), encrypted); err != nil {
+				t.Fatalf("write() error = %v", err)
+			}
+
+			var archive bytes.Buffer
+			exported, err := source.Export(&archive)
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if exported != 1 {
+				t.Fatalf("Export() = %d entries, want 1", exported)
+			}
+
+			// Another machine, with another key
+			keyring.MockInit()
+			t.Setenv("HOME", t.TempDir())
+			dest, err := NewWithBackend(1, backend)
+			if err != nil {
+				t.Fatalf("NewWithBackend() error = %v", err)
+			}
+			if bytes.Equal(source.encKey, dest.encKey) {
+				t.Fatal("destination cache uses the same key")
+			}
+			imported, err := dest.Import(&archive)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if imported != 1 {
+				t.Fatalf("Import() = %d entries, want 1", imported)
+			}
+			if got := dest.Get(hash); got != "I have an apple." {
+				t.Errorf("Get() after import = %q, want %q", got, "I have an apple.")
+			}
+			stored, err := dest.backend().read(hash)
+			if err != nil {
+				t.Fatalf("read() error = %v", err)
+			}
+			if _, err := dest.decrypt(stored); err != nil {
+				t.Errorf("imported entry not encrypted with the destination key: %v", err)
+			}
+		})
+	}
+}
+
+func TestImportRejectsInvalidEntries(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("HOME", t.TempDir())
+	cache, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	valid := cache.Hash("text")
+	tests := []struct {
+		name  string
+		file  string
+		entry CacheEntry
+	}{
+		{name: "path traversal", file: "../../etc/passwd", entry: CacheEntry{Hash: "../../etc/passwd"}},
+		{name: "name doesn't match hash", file: cache.Hash("other") + ".json", entry: CacheEntry{Hash: valid}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.entry.Timestamp = time.Now().Unix()
+			data, _ := json.Marshal(tt.entry)
+
+			var archive bytes.Buffer
+			zw, _ := zstd.NewWriter(&archive)
+			tw := tar.NewWriter(zw)
+			if err := tw.WriteHeader(&tar.Header{Name: tt.file, Mode: 0600, Size: int64(len(data))}); err != nil {
+				t.Fatal(err)
+			}
+			tw.Write(data)
+			tw.Close()
+			zw.Close()
+
+			if _, err := cache.Import(&archive); err == nil {
+				t.Error("Import() should reject the entry")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// hotSize is how many recently used entries are kept in memory
+const hotSize = 256
+
+// hotCache keeps the most recently used entries in memory, so a text pasted again in the same
+// session skips reading and decrypting its entry. A nil hotCache keeps nothing.
+type hotCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Entries, most recently used first
+	entries map[string]*list.Element
+}
+
+func newHotCache(size int) *hotCache {
+	return &hotCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (h *hotCache) get(hash string) (CacheEntry, bool) {
+	if h == nil {
+		return CacheEntry{}, false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	elem, ok := h.entries[hash]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	h.order.MoveToFront(elem)
+	return elem.Value.(CacheEntry), true
+}
+
+// add keeps entry, evicting the least recently used entry when full
+func (h *hotCache) add(entry CacheEntry) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if elem, ok := h.entries[entry.Hash]; ok {
+		elem.Value = entry
+		h.order.MoveToFront(elem)
+		return
+	}
+	h.entries[entry.Hash] = h.order.PushFront(entry)
+	if h.order.Len() > h.size {
+		oldest := h.order.Back()
+		h.order.Remove(oldest)
+		delete(h.entries, oldest.Value.(CacheEntry).Hash)
+	}
+}
+
+func (h *hotCache) remove(hashes ...string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, hash := range hashes {
+		if elem, ok := h.entries[hash]; ok {
+			h.order.Remove(elem)
+			delete(h.entries, hash)
+		}
+	}
+}
+
+func (h *hotCache) clear() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.order.Init()
+	clear(h.entries)
+}
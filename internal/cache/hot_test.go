@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHotCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	h := newHotCache(2)
+	h.add(CacheEntry{Hash: "a", Corrected: "A"})
+	h.add(CacheEntry{Hash: "b", Corrected: "B"})
+	h.get("a") // b is now the least recently used
+	h.add(CacheEntry{Hash: "c", Corrected: "C"})
+
+	if _, ok := h.get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, hash := range []string{"a", "c"} {
+		if _, ok := h.get(hash); !ok {
+			t.Errorf("%s should still be cached", hash)
+		}
+	}
+
+	h.remove("a")
+	if _, ok := h.get("a"); ok {
+		t.Error("a should have been removed")
+	}
+	h.clear()
+	if _, ok := h.get("c"); ok {
+		t.Error("clear() should remove every entry")
+	}
+}
+
+func TestGetServesFromMemory(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	cache, err := New(1)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	hash := cache.Hash("I has a apple.")
+	if err := cache.Set(hash, "I has a apple.", "I have an apple."); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// Remove the entry behind the cache's back; a repeated lookup doesn't touch the disk
+	if err := os.Remove(filepath.Join(cache.dir, hash+".json")); err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.Get(hash); got != "I have an apple." {
+		t.Errorf("Get() = %q, want the entry from memory", got)
+	}
+	if got := cache.Session(); got.Hits != 1 {
+		t.Errorf("Session().Hits = %d, want 1", got.Hits)
+	}
+
+	if _, err := cache.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if got := cache.Get(hash); got != "" {
+		t.Errorf("Get() after Clear() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// keyringService and keyringUser name the encryption key in the OS keychain
+	keyringService = "grammr"
+	keyringUser    = "cache-encryption-key"
+	// keyFile keeps the encryption key in the cache directory when there is no keychain
+	keyFile = "key"
+	// keySize is the size of the AES-256 encryption key
+	keySize = 32
+	// keyReadAttempts and keyReadDelay bound the wait for a key file that is still empty
+	keyReadAttempts = 10
+	keyReadDelay    = 50 * time.Millisecond
+)
+
+// LoadKey returns the cache encryption key, a random key created on first use. It is kept in
+// the OS keychain (macOS Keychain, Secret Service on Linux, Windows Credential Manager), or,
+// where there is none such as on a headless server, in a file in dir only the user can read.
+func LoadKey(dir string) ([]byte, error) {
+	path := filepath.Join(dir, keyFile)
+
+	encoded, err := keyring.Get(keyringService, keyringUser)
+	if err == nil {
+		if key, err := decodeKey(encoded); err == nil {
+			return key, nil
+		}
+	}
+	// A key file from a machine without a keychain keeps being used
+	if _, statErr := os.Stat(path); statErr == nil || !errors.Is(err, keyring.ErrNotFound) {
+		return loadKeyFile(path)
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := keyring.Set(keyringService, keyringUser, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return loadKeyFile(path)
+	}
+	return key, nil
+}
+
+// loadKeyFile reads the key in path, creating it when it doesn't exist
+func loadKeyFile(path string) ([]byte, error) {
+	key, err := readKeyFile(path)
+	if err == nil || !os.IsNotExist(err) {
+		return key, err
+	}
+
+	key, err = newKey()
+	if err != nil {
+		return nil, err
+	}
+	err = createKeyFile(path, key)
+	if os.IsExist(err) {
+		// Another grammr process created it in the meantime
+		return readKeyFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache key: %w", err)
+	}
+	return key, nil
+}
+
+// readKeyFile reads the key in path. An empty file, which an older version may still be
+// writing, is read again for a little while before it is given up on.
+func readKeyFile(path string) ([]byte, error) {
+	var data []byte
+	for attempt := 0; attempt < keyReadAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(keyReadDelay)
+		}
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to read cache key: %w", err)
+		}
+		if len(data) > 0 {
+			break
+		}
+	}
+	key, err := decodeKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache key in %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// createKeyFile writes key to path, failing with an error for which os.IsExist is true when the
+// file exists. The key is written to a temporary file first and linked into place once it is
+// on disk, so other processes never read a partial key.
+func createKeyFile(path string, key []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), keyFile+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(CacheFilePerm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.WriteString(base64.StdEncoding.EncodeToString(key)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Link(tmp.Name(), path)
+}
+
+func newKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate cache key: %w", err)
+	}
+	return key, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key is %d bytes, want %d", len(key), keySize)
+	}
+	return key, nil
+}
+
+// legacyKey returns the key older versions derived from the home directory. Entries encrypted
+// with it are still read, and encrypted with the new key when they are.
+func legacyKey(home string) []byte {
+	keyHash := sha256.Sum256([]byte(home + ".grammr.cache.key"))
+	return keyHash[:]
+}
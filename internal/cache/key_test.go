@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestLoadKey(t *testing.T) {
+	keyring.MockInit()
+	dir := t.TempDir()
+
+	key, err := LoadKey(dir)
+	if err != nil {
+		t.Fatalf("LoadKey() error = %v", err)
+	}
+	if len(key) != keySize {
+		t.Fatalf("key is %d bytes, want %d", len(key), keySize)
+	}
+	again, err := LoadKey(dir)
+	if err != nil {
+		t.Fatalf("LoadKey() error = %v", err)
+	}
+	if !bytes.Equal(key, again) {
+		t.Error("LoadKey() created a new key instead of reusing the one in the keychain")
+	}
+	if _, err := os.Stat(filepath.Join(dir, keyFile)); !os.IsNotExist(err) {
+		t.Error("key file written although the keychain is available")
+	}
+}
+
+func TestLoadKeyWithoutKeychain(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keychain"))
+	defer keyring.MockInit()
+	dir := t.TempDir()
+
+	key, err := LoadKey(dir)
+	if err != nil {
+		t.Fatalf("LoadKey() error = %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, keyFile))
+	if err != nil {
+		t.Fatalf("key file not written: %v", err)
+	}
+	if info.Mode().Perm() != CacheFilePerm {
+		t.Errorf("key file permissions = %o, want %o", info.Mode().Perm(), CacheFilePerm)
+	}
+
+	// The key file keeps being used once the keychain becomes available
+	keyring.MockInit()
+	again, err := LoadKey(dir)
+	if err != nil {
+		t.Fatalf("LoadKey() error = %v", err)
+	}
+	if !bytes.Equal(key, again) {
+		t.Error("LoadKey() did not reuse the key file")
+	}
+}
+
+func TestLoadKeyConcurrently(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keychain"))
+	defer keyring.MockInit()
+	dir := t.TempDir()
+
+	const processes = 8
+	keys := make([][]byte, processes)
+	errs := make([]error, processes)
+	var wg sync.WaitGroup
+	for i := range processes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys[i], errs[i] = LoadKey(dir)
+		}()
+	}
+	wg.Wait()
+
+	for i := range processes {
+		if errs[i] != nil {
+			t.Fatalf("LoadKey() error = %v", errs[i])
+		}
+		if !bytes.Equal(keys[i], keys[0]) {
+			t.Fatal("LoadKey() returned different keys")
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("cache directory has %d files, want only the key file", len(entries))
+	}
+}
+
+func TestLoadKeyWaitsForKeyFile(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keychain"))
+	defer keyring.MockInit()
+	dir := t.TempDir()
+	path := filepath.Join(dir, keyFile)
+
+	// An empty key file that another process is about to write
+	if err := os.WriteFile(path, nil, CacheFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	want := bytes.Repeat([]byte{7}, keySize)
+	go func() {
+		time.Sleep(2 * keyReadDelay)
+		os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(want)), CacheFilePerm)
+	}()
+
+	key, err := LoadKey(dir)
+	if err != nil {
+		t.Fatalf("LoadKey() error = %v", err)
+	}
+	if !bytes.Equal(key, want) {
+		t.Error("LoadKey() did not wait for the key being written")
+	}
+}
+
+func TestGetReencryptsLegacyEntries(t *testing.T) {
+	keyring.MockInit()
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	// An entry written by a version that derived the key from the home directory
+	old := &Cache{dir: filepath.Join(tmpDir, "cache"), ttl: 24 * time.Hour, encKey: legacyKey(tmpDir)}
+	if err := os.MkdirAll(old.dir, CacheDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	hash := old.Hash("I has a apple.")
+	if err := old.Set(hash, "I has a apple.", "I have an apple."); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	before, err := os.ReadFile(filepath.Join(old.dir, hash+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewInDir(old.dir, 1, BackendFiles)
+	if err != nil {
+		t.Fatalf("NewInDir() error = %v", err)
+	}
+	if got := cache.Get(hash); got != "I have an apple." {
+		t.Fatalf("Get() = %q, want the legacy entry", got)
+	}
+
+	after, err := os.ReadFile(filepath.Join(old.dir, hash+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.decrypt(after); err != nil {
+		t.Errorf("entry not re-encrypted with the new key: %v", err)
+	}
+	if _, err := old.decrypt(after); err == nil {
+		t.Error("entry still readable with the legacy key")
+	}
+	var entry CacheEntry
+	plain, _ := cache.decrypt(after)
+	if err := json.Unmarshal(plain, &entry); err != nil {
+		t.Fatal(err)
+	}
+	oldPlain, _ := old.decrypt(before)
+	var oldEntry CacheEntry
+	if err := json.Unmarshal(oldPlain, &oldEntry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Timestamp != oldEntry.Timestamp {
+		t.Errorf("re-encryption changed the timestamp from %d to %d", oldEntry.Timestamp, entry.Timestamp)
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// statsFile keeps the lifetime statistics, inside the cache directory
+const statsFile = "stats.json"
+
+// Stats counts how often the cache answered instead of the API
+type Stats struct {
+	Hits       int   `json:"hits"`
+	Misses     int   `json:"misses"`
+	BytesSaved int64 `json:"bytes_saved"` // Size of the responses served from the cache
+}
+
+// HitRate returns the share of lookups that were hits, from 0 to 1
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// String summarizes the statistics, e.g. "3 hits, 1 miss (75%), 2.1 KB saved"
+func (s Stats) String() string {
+	return fmt.Sprintf("%d %s, %d %s (%.0f%%), %s saved",
+		s.Hits, plural(s.Hits, "hit", "hits"), s.Misses, plural(s.Misses, "miss", "misses"), s.HitRate()*100, FormatSize(s.BytesSaved))
+}
+
+func (s Stats) add(other Stats) Stats {
+	return Stats{
+		Hits:       s.Hits + other.Hits,
+		Misses:     s.Misses + other.Misses,
+		BytesSaved: s.BytesSaved + other.BytesSaved,
+	}
+}
+
+// record counts a lookup, with the size of the response it served on a hit
+func (c *Cache) record(hit bool, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lookup := Stats{Misses: 1}
+	if hit {
+		lookup = Stats{Hits: 1, BytesSaved: int64(size)}
+	}
+	c.session = c.session.add(lookup)
+	c.unsaved = c.unsaved.add(lookup)
+}
+
+// Session returns the statistics since the cache was created
+func (c *Cache) Session() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// Lifetime returns the statistics of all sessions, including the lookups of this one that
+// haven't been saved yet
+func (c *Cache) Lifetime() (Stats, error) {
+	saved, err := c.loadStats()
+	if err != nil {
+		return Stats{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return saved.add(c.unsaved), nil
+}
+
+// SaveStats adds the lookups made since the last save to the lifetime statistics
+func (c *Cache) SaveStats() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unsaved == (Stats{}) {
+		return nil
+	}
+
+	saved, err := c.loadStats()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(saved.add(c.unsaved))
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache stats: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, statsFile), data, CacheFilePerm); err != nil {
+		return fmt.Errorf("failed to write cache stats: %w", err)
+	}
+	c.unsaved = Stats{}
+	return nil
+}
+
+func (c *Cache) loadStats() (Stats, error) {
+	var stats Stats
+	data, err := os.ReadFile(filepath.Join(c.dir, statsFile))
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to read cache stats: %w", err)
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return Stats{}, fmt.Errorf("failed to parse cache stats: %w", err)
+	}
+	return stats, nil
+}
+
+// Usage returns how many entries the cache holds and how much space they take
+func (c *Cache) Usage() (entries int, size int64, err error) {
+	return c.backend().usage()
+}
+
+// FormatSize formats a number of bytes for people, e.g. "2.1 KB"
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, suffix := float64(bytes)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
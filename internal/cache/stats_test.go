@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	keyHash := sha256.Sum256([]byte(tmpDir + ".grammr.cache.key"))
+	cache := &Cache{dir: tmpDir, ttl: 24 * time.Hour, encKey: keyHash[:]}
+
+	hash := cache.Hash("Hello world")
+	cache.Get(hash)
+	if err := cache.Set(hash, "Hello world", "Hello, world"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	cache.Get(hash)
+	cache.Get(hash)
+
+	want := Stats{Hits: 2, Misses: 1, BytesSaved: 24}
+	if got := cache.Session(); got != want {
+		t.Fatalf("Session() = %+v, want %+v", got, want)
+	}
+	if got := cache.Session().String(); got != "2 hits, 1 miss (67%), 24 B saved" {
+		t.Errorf("Stats.String() = %q", got)
+	}
+
+	if err := cache.SaveStats(); err != nil {
+		t.Fatalf("SaveStats() error = %v", err)
+	}
+	// Saving again doesn't count the same lookups twice
+	if err := cache.SaveStats(); err != nil {
+		t.Fatalf("SaveStats() error = %v", err)
+	}
+
+	// A later session adds to the lifetime statistics
+	next := &Cache{dir: tmpDir, ttl: 24 * time.Hour, encKey: keyHash[:]}
+	next.Get(hash)
+	lifetime, err := next.Lifetime()
+	if err != nil {
+		t.Fatalf("Lifetime() error = %v", err)
+	}
+	if want := (Stats{Hits: 3, Misses: 1, BytesSaved: 36}); lifetime != want {
+		t.Fatalf("Lifetime() = %+v, want %+v", lifetime, want)
+	}
+
+	entries, size, err := next.Usage()
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	if entries != 1 || size == 0 {
+		t.Errorf("Usage() = %d entries, %d bytes, want 1 entry", entries, size)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{bytes: 0, want: "0 B"},
+		{bytes: 1023, want: "1023 B"},
+		{bytes: 2150, want: "2.1 KB"},
+		{bytes: 5 * 1024 * 1024, want: "5.0 MB"},
+		{bytes: 3 * 1024 * 1024 * 1024, want: "3.0 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatSize(tt.bytes); got != tt.want {
+				t.Errorf("FormatSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// BackendFiles stores each entry in its own file in the cache directory
+	BackendFiles = "files"
+	// BackendBolt stores all entries in a single bbolt database in the cache directory
+	BackendBolt = "bolt"
+)
+
+// Backends lists the supported cache backends
+var Backends = []string{BackendFiles, BackendBolt}
+
+// store persists encrypted cache entries by hash
+type store interface {
+	// read returns the entry for hash, or an error wrapping os.ErrNotExist when there is none
+	read(hash string) ([]byte, error)
+	write(hash string, data []byte) error
+	remove(hashes ...string) error
+	// each calls fn with every entry, stopping at the first error
+	each(fn func(hash string, data []byte) error) error
+	// clear removes every entry and returns how many were removed
+	clear() (int, error)
+	// usage returns the number of entries and the space they take
+	usage() (int, int64, error)
+}
+
+// newStore creates the store for backend in dir
+func newStore(backend, dir string) (store, error) {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case "", BackendFiles:
+		return fileStore{dir: dir}, nil
+	case BackendBolt:
+		return boltStore{path: filepath.Join(dir, boltFile)}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %s (supported: %s)", backend, strings.Join(Backends, ", "))
+	}
+}
+
+// fileStore keeps one file per entry, named after its hash
+type fileStore struct {
+	dir string
+}
+
+// path returns the file of hash, making sure it stays inside the cache directory
+func (s fileStore) path(hash string) (string, error) {
+	// Validate hash to prevent path traversal attacks
+	if !isValidHash(hash) {
+		return "", fmt.Errorf("invalid hash format")
+	}
+	path := filepath.Join(s.dir, hash+".json")
+	// Additional safety check: ensure the resolved path is within cache directory
+	// Use filepath.Clean to resolve any path traversal attempts
+	cleanPath := filepath.Clean(path)
+	if !strings.HasPrefix(cleanPath, s.dir+string(filepath.Separator)) && cleanPath != s.dir {
+		return "", fmt.Errorf("invalid cache path")
+	}
+	return path, nil
+}
+
+func (s fileStore) read(hash string) ([]byte, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (s fileStore) write(hash string, data []byte) error {
+	path, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, CacheFilePerm); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+func (s fileStore) remove(hashes ...string) error {
+	for _, hash := range hashes {
+		path, err := s.path(hash)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cache file: %w", err)
+		}
+	}
+	return nil
+}
+
+// hashes returns the hashes of all entries
+func (s fileStore) hashes() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache files: %w", err)
+	}
+	var hashes []string
+	for _, path := range paths {
+		if hash := strings.TrimSuffix(filepath.Base(path), ".json"); isValidHash(hash) {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes, nil
+}
+
+func (s fileStore) each(fn func(hash string, data []byte) error) error {
+	hashes, err := s.hashes()
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		data, err := s.read(hash)
+		if os.IsNotExist(err) {
+			// Removed in the meantime
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read cache file: %w", err)
+		}
+		if err := fn(hash, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s fileStore) clear() (int, error) {
+	hashes, err := s.hashes()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, hash := range hashes {
+		if err := s.remove(hash); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (s fileStore) usage() (int, int64, error) {
+	hashes, err := s.hashes()
+	if err != nil {
+		return 0, 0, err
+	}
+	entries, size := 0, int64(0)
+	for _, hash := range hashes {
+		info, err := os.Stat(filepath.Join(s.dir, hash+".json"))
+		if err != nil {
+			// Removed in the meantime
+			continue
+		}
+		entries++
+		size += info.Size()
+	}
+	return entries, size, nil
+}
@@ -0,0 +1,37 @@
+package cache
+
+// SweepResult describes the entries a sweep removed, or would remove on a dry run
+type SweepResult struct {
+	Entries int
+	Bytes   int64
+}
+
+// Sweep removes every expired entry at once, rather than only when its hash is looked up again.
+// Entries that can't be read anymore are removed too, since they can never be served. With
+// dryRun nothing is removed, to report how much space a sweep would reclaim.
+func (c *Cache) Sweep(dryRun bool) (SweepResult, error) {
+	var result SweepResult
+	var stale []string
+	err := c.backend().each(func(hash string, data []byte) error {
+		if entry, _, err := c.decode(data); err == nil && !c.expired(entry) {
+			return nil
+		}
+		stale = append(stale, hash)
+		result.Entries++
+		result.Bytes += int64(len(data))
+		return nil
+	})
+	if err != nil {
+		return SweepResult{}, err
+	}
+
+	if dryRun || len(stale) == 0 {
+		return result, nil
+	}
+	// Remove after iterating, since a store can't be changed while it's being read
+	c.hot.remove(stale...)
+	if err := c.backend().remove(stale...); err != nil {
+		return SweepResult{}, err
+	}
+	return result, nil
+}
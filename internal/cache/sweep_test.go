@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSweep(t *testing.T) {
+	for _, backend := range Backends {
+		t.Run(backend, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			cache, err := NewWithBackend(1, backend)
+			if err != nil {
+				t.Fatalf("NewWithBackend() error = %v", err)
+			}
+
+			fresh := cache.Hash("fresh")
+			if err := cache.Set(fresh, "fresh", "Fresh."); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			// An entry written two days ago and one that can't be read
+			data, err := json.Marshal(CacheEntry{Original: "old", Corrected: "Old.", Timestamp: time.Now().Add(-48 * time.Hour).Unix()})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			encrypted, err := cache.encrypt(data)
+			if err != nil {
+				t.Fatalf("encrypt() error = %v", err)
+			}
+			if err := cache.backend().write(cache.Hash("old"), encrypted); err != nil {
+				t.Fatalf("write() error = %v", err)
+			}
+			if err := cache.backend().write(cache.Hash("corrupt"), []byte("not json")); err != nil {
+				t.Fatalf("write() error = %v", err)
+			}
+
+			want := SweepResult{Entries: 2, Bytes: int64(len(encrypted) + len("not json"))}
+			result, err := cache.Sweep(true)
+			if err != nil {
+				t.Fatalf("Sweep(dry run) error = %v", err)
+			}
+			if result != want {
+				t.Fatalf("Sweep(dry run) = %+v, want %+v", result, want)
+			}
+			if entries, _, _ := cache.Usage(); entries != 3 {
+				t.Fatalf("a dry run should not remove anything, %d entries left", entries)
+			}
+
+			result, err = cache.Sweep(false)
+			if err != nil {
+				t.Fatalf("Sweep() error = %v", err)
+			}
+			if result != want {
+				t.Fatalf("Sweep() = %+v, want %+v", result, want)
+			}
+			if entries, _, _ := cache.Usage(); entries != 1 {
+				t.Fatalf("Sweep() left %d entries, want 1", entries)
+			}
+			if got := cache.Get(fresh); got != "Fresh." {
+				t.Fatalf("Get() after Sweep() = %q, want the fresh entry kept", got)
+			}
+		})
+	}
+}
@@ -1,15 +1,78 @@
 package clipboard
 
-import (
-	"github.com/atotto/clipboard"
+// Modes of the clipboard setting, see New
+const (
+	// ModeAuto picks the clipboard Detect finds
+	ModeAuto = "auto"
+	// ModeSystem uses the system clipboard through pbcopy, xclip, xsel or the Windows API
+	ModeSystem = "system"
+	// ModeWayland runs wl-copy and wl-paste
+	ModeWayland = "wl-clipboard"
+	// ModeTmux uses the tmux paste buffer
+	ModeTmux = "tmux"
+	// ModeOSC52 only copies, through the terminal
+	ModeOSC52 = "osc52"
 )
 
-// Paste reads text from the system clipboard
-func Paste() (string, error) {
-	return clipboard.ReadAll()
+// Clipboard reads and writes a clipboard
+type Clipboard interface {
+	// Copy puts text on the clipboard
+	Copy(text string) error
+	// Paste returns the text on the clipboard
+	Paste() (string, error)
 }
 
-// Copy writes text to the system clipboard
-func Copy(text string) error {
-	return clipboard.WriteAll(text)
+// New returns the clipboard of mode, one of the Mode constants. Unknown modes are treated as
+// ModeAuto.
+func New(mode string) Clipboard {
+	switch mode {
+	case ModeSystem:
+		return System{}
+	case ModeWayland:
+		return WLClipboard{}
+	case ModeTmux:
+		return Tmux{}
+	case ModeOSC52:
+		return OSC52{Hint: "the clipboard setting is osc52, which can only copy; paste with your terminal instead"}
+	}
+	return forBackend(Detect())
+}
+
+// forBackend returns the clipboard that uses backend. Where a clipboard tool is found but fails,
+// as without a display over SSH, copying falls back to the terminal.
+func forBackend(backend Backend) Clipboard {
+	switch backend.Name {
+	case BackendOSC52:
+		return OSC52{Hint: backend.Hint}
+	case BackendTmux:
+		return Tmux{}
+	case "wl-copy":
+		return withOSC52{WLClipboard{}}
+	}
+	return withOSC52{System{}}
+}
+
+// PasteHint returns why text can't be pasted from c, or "" when it can
+func PasteHint(c Clipboard) string {
+	if terminal, ok := c.(OSC52); ok {
+		_, err := terminal.Paste()
+		return err.Error()
+	}
+	return ""
+}
+
+// withOSC52 copies through the terminal when its clipboard can't copy
+type withOSC52 struct {
+	Clipboard
+}
+
+func (c withOSC52) Copy(text string) error {
+	err := c.Clipboard.Copy(text)
+	if err == nil {
+		return nil
+	}
+	if (OSC52{}).Copy(text) == nil {
+		return nil
+	}
+	return err
 }
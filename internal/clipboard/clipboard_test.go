@@ -0,0 +1,122 @@
+package clipboard
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestOSC52Sequence(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		tmux bool
+		want string
+	}{
+		{
+			name: "plain terminal",
+			text: "Hello, world!",
+			want: "\x1b]52;c;SGVsbG8sIHdvcmxkIQ==\a",
+		},
+		{
+			name: "inside tmux",
+			text: "Hello, world!",
+			tmux: true,
+			want: "\x1bPtmux;\x1b\x1b]52;c;SGVsbG8sIHdvcmxkIQ==\a\x1b\\",
+		},
+		{
+			name: "non-ASCII text",
+			text: "café",
+			want: "\x1b]52;c;Y2Fmw6k=\a",
+		},
+		{
+			name: "empty text",
+			want: "\x1b]52;c;\a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := osc52Sequence(tt.text, tt.tmux); got != tt.want {
+				t.Errorf("osc52Sequence() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOSC52Copy(t *testing.T) {
+	var out bytes.Buffer
+	previous := openTerminal
+	openTerminal = func() (io.WriteCloser, error) { return nopCloser{&out}, nil }
+	t.Cleanup(func() { openTerminal = previous })
+	t.Setenv("TMUX", "")
+
+	if err := (OSC52{}).Copy("Hello, world!"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if want := "\x1b]52;c;SGVsbG8sIHdvcmxkIQ==\a"; out.String() != want {
+		t.Errorf("Copy() wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		mode string
+		want Clipboard
+	}{
+		{mode: ModeSystem, want: System{}},
+		{mode: ModeWayland, want: WLClipboard{}},
+		{mode: ModeTmux, want: Tmux{}},
+	}
+
+	for _, tt := range tests {
+		if got := New(tt.mode); got != tt.want {
+			t.Errorf("New(%q) = %#v, want %#v", tt.mode, got, tt.want)
+		}
+	}
+	if hint := PasteHint(New(ModeOSC52)); hint == "" {
+		t.Error("PasteHint(New(osc52)) is empty, want why pasting doesn't work")
+	}
+}
+
+func TestForBackend(t *testing.T) {
+	tests := []struct {
+		backend  Backend
+		want     Clipboard
+		wantHint string
+	}{
+		{backend: Backend{Name: "pbcopy"}, want: withOSC52{System{}}},
+		{backend: Backend{Name: "xclip"}, want: withOSC52{System{}}},
+		{backend: Backend{Name: "wl-copy"}, want: withOSC52{WLClipboard{}}},
+		{backend: Backend{Name: BackendTmux}, want: Tmux{}},
+		{
+			backend:  Backend{Name: BackendOSC52, Hint: "install xclip"},
+			want:     OSC52{Hint: "install xclip"},
+			wantHint: "install xclip",
+		},
+	}
+
+	for _, tt := range tests {
+		got := forBackend(tt.backend)
+		if got != tt.want {
+			t.Errorf("forBackend(%q) = %#v, want %#v", tt.backend.Name, got, tt.want)
+		}
+		if hint := PasteHint(got); hint != tt.wantHint {
+			t.Errorf("PasteHint(forBackend(%q)) = %q, want %q", tt.backend.Name, hint, tt.wantHint)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	var c Clipboard = &Memory{}
+	if err := c.Copy("copied"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if got, err := c.Paste(); err != nil || got != "copied" {
+		t.Errorf("Paste() = %q, %v, want %q", got, err, "copied")
+	}
+}
@@ -0,0 +1,73 @@
+package clipboard
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+const (
+	// BackendTmux is the backend inside tmux when no clipboard tool can be used, as over SSH
+	BackendTmux = "tmux"
+	// BackendOSC52 is the backend when no clipboard can be used: text can still be copied
+	// through the terminal, see OSC52, but not pasted
+	BackendOSC52 = "osc52"
+)
+
+// Backend is the way the clipboard is reached
+type Backend struct {
+	Name string // Tool used, such as "wl-copy", "xclip" or "pbcopy", or BackendOSC52
+	Hint string // What to do to be able to paste, empty when pasting works
+}
+
+// CanPaste reports whether text can be read from the clipboard
+func (b Backend) CanPaste() bool {
+	return b.Name != BackendOSC52
+}
+
+// Detect finds the clipboard backend of this machine, in the order the system clipboard tries
+// them, see System
+func Detect() Backend {
+	return detect(runtime.GOOS, os.Getenv, func(name string) bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	})
+}
+
+func detect(goos string, getenv func(string) string, installed func(string) bool) Backend {
+	switch goos {
+	case "darwin":
+		return Backend{Name: "pbcopy"}
+	case "windows":
+		return Backend{Name: "windows"}
+	case "plan9":
+		return Backend{Name: "/dev/snarf"}
+	}
+
+	wayland := getenv("WAYLAND_DISPLAY") != ""
+	x11 := getenv("DISPLAY") != ""
+	if wayland && installed("wl-copy") && installed("wl-paste") {
+		return Backend{Name: "wl-copy"}
+	}
+	if x11 {
+		for _, tool := range []string{"xclip", "xsel"} {
+			if installed(tool) {
+				return Backend{Name: tool}
+			}
+		}
+	}
+	if installed("termux-clipboard-set") {
+		return Backend{Name: "termux-clipboard-set"}
+	}
+	if getenv("TMUX") != "" && installed("tmux") {
+		return Backend{Name: BackendTmux}
+	}
+
+	switch {
+	case wayland:
+		return Backend{Name: BackendOSC52, Hint: "install wl-clipboard to paste from the clipboard"}
+	case x11:
+		return Backend{Name: BackendOSC52, Hint: "install xclip or xsel to paste from the clipboard"}
+	}
+	return Backend{Name: BackendOSC52, Hint: "no display to read a clipboard from, as over SSH; paste with your terminal instead"}
+}
@@ -0,0 +1,109 @@
+package clipboard
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		env       map[string]string
+		installed []string
+		want      string
+		wantPaste bool
+	}{
+		{
+			name:      "macOS",
+			goos:      "darwin",
+			want:      "pbcopy",
+			wantPaste: true,
+		},
+		{
+			name:      "Wayland with wl-clipboard",
+			goos:      "linux",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			installed: []string{"wl-copy", "wl-paste", "xclip"},
+			want:      "wl-copy",
+			wantPaste: true,
+		},
+		{
+			name:      "Wayland falls back to xclip through XWayland",
+			goos:      "linux",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: []string{"xclip"},
+			want:      "xclip",
+			wantPaste: true,
+		},
+		{
+			name:      "X11 with xsel",
+			goos:      "linux",
+			env:       map[string]string{"DISPLAY": ":0"},
+			installed: []string{"xsel"},
+			want:      "xsel",
+			wantPaste: true,
+		},
+		{
+			name: "Wayland without tools",
+			goos: "linux",
+			env:  map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			want: BackendOSC52,
+		},
+		{
+			name: "X11 without tools",
+			goos: "linux",
+			env:  map[string]string{"DISPLAY": ":0"},
+			want: BackendOSC52,
+		},
+		{
+			name:      "headless ignores xclip",
+			goos:      "linux",
+			installed: []string{"xclip"},
+			want:      BackendOSC52,
+		},
+		{
+			name:      "tmux over SSH",
+			goos:      "linux",
+			env:       map[string]string{"TMUX": "/tmp/tmux-1000/default,1234,0"},
+			installed: []string{"tmux"},
+			want:      BackendTmux,
+			wantPaste: true,
+		},
+		{
+			name:      "tmux prefers the display's clipboard",
+			goos:      "linux",
+			env:       map[string]string{"TMUX": "/tmp/tmux-1000/default,1234,0", "DISPLAY": ":0"},
+			installed: []string{"tmux", "xclip"},
+			want:      "xclip",
+			wantPaste: true,
+		},
+		{
+			name:      "Termux",
+			goos:      "android",
+			installed: []string{"termux-clipboard-set"},
+			want:      "termux-clipboard-set",
+			wantPaste: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installed := func(name string) bool {
+				for _, tool := range tt.installed {
+					if tool == name {
+						return true
+					}
+				}
+				return false
+			}
+			got := detect(tt.goos, func(key string) string { return tt.env[key] }, installed)
+			if got.Name != tt.want {
+				t.Errorf("detect() = %q, want %q", got.Name, tt.want)
+			}
+			if got.CanPaste() != tt.wantPaste {
+				t.Errorf("CanPaste() = %v, want %v", got.CanPaste(), tt.wantPaste)
+			}
+			if (got.Hint == "") != tt.wantPaste {
+				t.Errorf("Hint = %q, want one only when pasting doesn't work", got.Hint)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package clipboard
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// System uses the system clipboard: pbcopy on macOS, the Windows API, and xclip, xsel, wl-copy
+// or the Termux API on other systems, whichever is installed
+type System struct{}
+
+func (System) Copy(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+func (System) Paste() (string, error) {
+	return clipboard.ReadAll()
+}
+
+// WLClipboard runs wl-copy and wl-paste, the clipboard tools of Wayland
+type WLClipboard struct{}
+
+func (WLClipboard) Copy(text string) error {
+	return runCopy(text, "wl-copy")
+}
+
+func (WLClipboard) Paste() (string, error) {
+	return runPaste("wl-paste", "--no-newline")
+}
+
+// Tmux uses the tmux paste buffer. tmux 3.2 and later also pass copied text on to the clipboard
+// of the terminal, depending on its set-clipboard option.
+type Tmux struct{}
+
+func (Tmux) Copy(text string) error {
+	return runCopy(text, "tmux", "load-buffer", "-w", "-")
+}
+
+func (Tmux) Paste() (string, error) {
+	return runPaste("tmux", "save-buffer", "-")
+}
+
+// runCopy runs a copy command with text as its input. Its output isn't read: wl-copy leaves a
+// process behind that holds it open.
+func runCopy(text, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
+
+// runPaste runs a paste command and returns its output
+func runPaste(name string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s failed: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,15 @@
+package clipboard
+
+// Memory is a clipboard that only keeps its text in memory, for tests
+type Memory struct {
+	Text string
+}
+
+func (c *Memory) Copy(text string) error {
+	c.Text = text
+	return nil
+}
+
+func (c *Memory) Paste() (string, error) {
+	return c.Text, nil
+}
@@ -0,0 +1,49 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+)
+
+// openTerminal opens the terminal OSC 52 sequences are written to
+var openTerminal = func() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
+
+// OSC52 asks the terminal to copy text with an OSC 52 escape sequence, which works over SSH and
+// inside tmux. Terminals don't answer it, so whether the text was copied can't be known, and
+// nothing can be pasted.
+type OSC52 struct {
+	Hint string // Why there is no clipboard to paste from, returned by Paste
+}
+
+func (OSC52) Copy(text string) error {
+	tty, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(tty, osc52Sequence(text, os.Getenv("TMUX") != ""))
+	if closeErr := tty.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (c OSC52) Paste() (string, error) {
+	if c.Hint == "" {
+		return "", errors.New("the terminal's clipboard can't be read; paste with your terminal instead")
+	}
+	return "", errors.New(c.Hint)
+}
+
+// osc52Sequence returns the escape sequence that puts text on the terminal's clipboard. Inside
+// tmux it's wrapped to pass through to the terminal tmux runs in.
+func osc52Sequence(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if tmux {
+		return "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	return seq
+}
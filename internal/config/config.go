@@ -4,9 +4,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-
-	"github.com/spf13/viper"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/audit"
+	"github.com/maximbilan/grammr/internal/cache"
+	"github.com/maximbilan/grammr/internal/estimate"
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/maximbilan/grammr/internal/history"
+	"github.com/maximbilan/grammr/internal/ratelimit"
+	"github.com/maximbilan/grammr/internal/session"
 )
 
 const (
@@ -16,6 +24,19 @@ const (
 	// ConfigFilePerm is the permission for the config file (0600 = rw-------)
 	// Restrictive permissions protect the API key from being read by other users
 	ConfigFilePerm os.FileMode = 0600
+	// CorrectionTemplateFile is the optional correction prompt template in the prompts directory
+	// of the config directory
+	CorrectionTemplateFile = "correction.tmpl"
+
+	// DiffWords compares texts a word at a time
+	DiffWords = "word"
+	// DiffChars compares texts a character at a time
+	DiffChars = "char"
+
+	// KeybindingsDefault is the keymap shown in the help screen
+	KeybindingsDefault = "default"
+	// KeybindingsVim adds vim motions, yank, put and search on top of the default keymap
+	KeybindingsVim = "vim"
 )
 
 type Config struct {
@@ -33,193 +54,423 @@ type Config struct {
 	RateLimitEnabled  bool   `mapstructure:"rate_limit_enabled"`
 	RateLimitRequests int    `mapstructure:"rate_limit_requests"`
 	RateLimitWindow   int    `mapstructure:"rate_limit_window_seconds"`
+	RateLimitBurst    int    `mapstructure:"rate_limit_burst"` // Requests allowed at once, defaults to rate_limit_requests
+	RateLimits        map[string]RateLimit `mapstructure:"rate_limits"` // Budgets by operation, provider or "provider/operation"
 	RequestTimeoutSeconds int `mapstructure:"request_timeout_seconds"`
+	CorrectionTimeoutSeconds int `mapstructure:"correction_timeout_seconds"` // Timeout of corrections, rewrites and explanations, 0 for request_timeout_seconds
+	TranslationTimeoutSeconds int `mapstructure:"translation_timeout_seconds"` // Timeout of translations, 0 for request_timeout_seconds
+	ScaleTimeouts     bool   `mapstructure:"scale_timeouts"` // Lengthen timeouts by the time a long text takes to generate
+	RequestRetries    int    `mapstructure:"request_retries"` // Times a rate limited or unreachable request is sent again
+	ChunkConcurrency  int    `mapstructure:"chunk_concurrency"` // Chunks of a long text corrected at once, within the rate limit
+	CustomStyles      []CustomStyle `mapstructure:"custom_styles"`
+	PromptTemplate    string `mapstructure:"prompt_template"` // Optional text/template overriding the correction prompt
+	ShortenPercent    int    `mapstructure:"shorten_percent"` // Target length for the shorten action, as a percentage
+	Format            string `mapstructure:"format"` // Input format: "auto", "markdown", "html" or "plain"
+	Dialect           string `mapstructure:"dialect"` // English variant: "us", "uk", "au" or empty for none
+	Category          string `mapstructure:"category"` // Mistakes to fix: "all", "spelling", "punctuation" or "grammar"
+	GlossaryFile      string `mapstructure:"glossary_file"` // Optional glossary path, defaults to ~/.grammr/glossary.yaml
+	Deterministic     bool   `mapstructure:"deterministic"` // Use temperature 0 and a fixed seed for reproducible output
+	ConfirmTokens     int    `mapstructure:"confirm_tokens"` // Ask before sending more tokens than this, 0 to never ask
+	DetectLanguage    bool   `mapstructure:"detect_language"` // Detect the language of the text, falling back to language
+	TranslateOriginal bool   `mapstructure:"translate_original"` // Translate the original text instead of the corrected one
+	TranslationFormality string `mapstructure:"translation_formality"` // Form of address in translations: "auto", "formal" or "informal"
+	FormalityByLanguage map[string]string `mapstructure:"translation_formality_by_language"` // Per-language overrides of translation_formality
+	Romanize          bool   `mapstructure:"romanize"` // Show a romanized line (pinyin, romaji, ...) beneath translations
+	CacheBackend      string `mapstructure:"cache_backend"` // Where the cache keeps entries: "files" or "bolt"
+	CacheDir          string `mapstructure:"cache_dir"` // Optional cache directory, defaults to ~/.grammr/cache
+	KeyStorage        string `mapstructure:"key_storage"` // Where API keys are kept: "keyring" or "file"
+	Theme             string `mapstructure:"theme"` // Color theme: "dark", "light", "high-contrast" or "colorblind"
+	ThemeColors       map[string]string `mapstructure:"theme_colors"` // Optional colors replacing roles of the theme
+	HistoryEnabled    bool   `mapstructure:"history_enabled"` // Keep past corrections in ~/.grammr/history.json
+	HistorySize       int    `mapstructure:"history_size"` // How many corrections the history keeps
+	SessionEnabled    bool   `mapstructure:"session_enabled"` // Keep the open texts in ~/.grammr/session.json to restore on the next launch
+	JournalFile       string `mapstructure:"journal_file"` // Optional Markdown file every correction is appended to
+	AuditEnabled      bool   `mapstructure:"audit_enabled"` // Record every request sent to providers in ~/.grammr/audit.log, encrypted
+	RedactPII         bool   `mapstructure:"redact_pii"` // Replace emails, phone and card numbers and names with placeholders before sending texts
+	Offline           bool   `mapstructure:"offline"` // Never send requests: corrections come from the cache only
+	DiffGranularity   string `mapstructure:"diff_granularity"` // Compare texts by "word" or "char"
+	DiffIgnoreWhitespace bool `mapstructure:"diff_ignore_whitespace"` // Hide changes that only touch whitespace
+	Keybindings       string `mapstructure:"keybindings"` // Keymap of the TUI: "default" or "vim"
+	Accessible        bool   `mapstructure:"accessible"` // Render without colors, borders or symbols, for screen readers and basic terminals
+	Clipboard         string `mapstructure:"clipboard"` // Clipboard to use: "auto", "system", "wl-clipboard", "tmux" or "osc52"
+	Notifications     string `mapstructure:"notifications"` // Desktop notifications of grammr hotkey and grammrd: "all", "errors" or "off"
+
+	project   string              // Project config applied over this config, see ProjectFile
+	overrides map[string]override // Settings the project config changed
+	unsaved   error               // Why the upgrade of the config file couldn't be saved, see UnsavedUpgradeWarning
 }
 
-// GetAPIKey returns the appropriate API key based on the provider
-func (c *Config) GetAPIKey() string {
-	if c.Provider == "anthropic" {
-		if c.AnthropicAPIKey != "" {
-			return c.AnthropicAPIKey
+// RateLimit is a budget of requests of its own, for an operation ("correction" or "translation"),
+// a provider, or an operation with a provider ("anthropic/translation"). Unset fields are taken
+// from the rate_limit_ settings.
+type RateLimit struct {
+	Requests      int `mapstructure:"requests" yaml:"requests"`
+	WindowSeconds int `mapstructure:"window_seconds" yaml:"window_seconds"`
+	Burst         int `mapstructure:"burst" yaml:"burst"`
+}
+
+// CustomStyle is a user-defined correction style with its own prompt instructions
+type CustomStyle struct {
+	Name   string `mapstructure:"name" yaml:"name"`
+	Prompt string `mapstructure:"prompt" yaml:"prompt"`
+}
+
+// CustomStylePrompts returns the custom styles as a map of normalized style name to prompt instructions.
+// Entries without a name or prompt are ignored, and so are the ones named after a built-in style,
+// see ShadowedStyles.
+func (c *Config) CustomStylePrompts() map[string]string {
+	prompts := make(map[string]string, len(c.CustomStyles))
+	for _, style := range c.CustomStyles {
+		name := NormalizeStyleName(style.Name)
+		if name == "" || strings.TrimSpace(style.Prompt) == "" || slices.Contains(choices["style"], name) {
+			continue
 		}
-		// Fallback to api_key for backward compatibility
-		return c.APIKey
+		prompts[name] = style.Prompt
 	}
-	// Default to OpenAI
-	return c.APIKey
+	return prompts
 }
 
-func Load() (*Config, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	configPath := filepath.Join(home, ".grammr")
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(configPath)
-
-	// Set defaults (but don't set default for style to allow backward compatibility check)
-	viper.SetDefault("provider", "openai") // Default to OpenAI for backward compatibility
-	viper.SetDefault("model", "gpt-4o")
-	viper.SetDefault("show_diff", true)
-	// Note: We don't set default for "style" here to allow backward compatibility check
-	// We'll set it after checking for "mode"
-	viper.SetDefault("language", "english")
-	viper.SetDefault("translation_language", "")
-	viper.SetDefault("cache_enabled", true)
-	viper.SetDefault("cache_ttl_days", 7)
-	viper.SetDefault("rate_limit_enabled", true)
-	viper.SetDefault("rate_limit_requests", 60)      // 60 requests
-	viper.SetDefault("rate_limit_window_seconds", 60) // per minute
-	viper.SetDefault("request_timeout_seconds", 30)   // 30 seconds default timeout
-
-	// Try to read config
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file not found; create directory
-			if err := os.MkdirAll(configPath, ConfigDirPerm); err != nil {
-				return nil, fmt.Errorf("failed to create config directory: %w", err)
-			}
-			// Set default for style now (no config file, so no backward compatibility needed)
-			viper.SetDefault("style", "casual")
-			// Return config with defaults
-			config := &Config{}
-			if err := viper.Unmarshal(config); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal default config: %w", err)
-			}
-			return config, nil
+// ShadowedStyles returns the names of the custom styles named after a built-in style, which
+// are ignored so the built-in style always means the same thing
+func (c *Config) ShadowedStyles() []string {
+	var names []string
+	for _, style := range c.CustomStyles {
+		name := NormalizeStyleName(style.Name)
+		if slices.Contains(choices["style"], name) && !slices.Contains(names, name) {
+			names = append(names, name)
 		}
-		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
+	return names
+}
 
-	// Backward compatibility: if "mode" exists but "style" doesn't, copy mode to style
-	if viper.IsSet("mode") && !viper.IsSet("style") {
-		if oldMode := viper.GetString("mode"); oldMode != "" {
-			viper.Set("style", oldMode)
-		}
+// ShadowedStylesWarning describes the custom styles ShadowedStyles ignores, or is empty when
+// there are none
+func (c *Config) ShadowedStylesWarning() string {
+	names := c.ShadowedStyles()
+	if len(names) == 0 {
+		return ""
 	}
-	
-	// Set default for style if it still doesn't exist
-	if !viper.IsSet("style") {
-		viper.SetDefault("style", "casual")
+	return fmt.Sprintf("custom styles named after a built-in style are ignored (%s), rename them in custom_styles", strings.Join(names, ", "))
+}
+
+// UnsavedUpgradeWarning explains that the config file was upgraded in memory only, or is empty
+// when it was saved or needed no upgrade
+func (c *Config) UnsavedUpgradeWarning() string {
+	if c.unsaved == nil {
+		return ""
 	}
+	return fmt.Sprintf("%v; the config is upgraded in memory each time it is read", c.unsaved)
+}
 
-	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+// RequestTimeout returns the API request timeout, falling back to 30 seconds when unset
+func (c *Config) RequestTimeout() time.Duration {
+	timeoutSeconds := c.RequestTimeoutSeconds
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 30 // Default fallback
 	}
+	return time.Duration(timeoutSeconds) * time.Second
+}
 
-	return &config, nil
+// CorrectionTimeout returns the timeout of corrections and the requests made like them, such as
+// rewrites and explanations, falling back to RequestTimeout when unset
+func (c *Config) CorrectionTimeout() time.Duration {
+	return c.operationTimeout(c.CorrectionTimeoutSeconds)
 }
 
-func Save(cfg *Config) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+// TranslationTimeout returns the timeout of translations, falling back to RequestTimeout when
+// unset
+func (c *Config) TranslationTimeout() time.Duration {
+	return c.operationTimeout(c.TranslationTimeoutSeconds)
+}
+
+func (c *Config) operationTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return c.RequestTimeout()
 	}
+	return time.Duration(seconds) * time.Second
+}
 
-	configPath := filepath.Join(home, ".grammr")
-	if err := os.MkdirAll(configPath, ConfigDirPerm); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// ScaledTimeout returns timeout for a request on text, lengthened for long texts when
+// scale_timeouts is on
+func (c *Config) ScaledTimeout(timeout time.Duration, text string) time.Duration {
+	if !c.ScaleTimeouts {
+		return timeout
 	}
+	return estimate.Timeout(timeout, text)
+}
 
-	// Set values
-	viper.Set("provider", cfg.Provider)
-	viper.Set("api_key", cfg.APIKey)
-	viper.Set("anthropic_api_key", cfg.AnthropicAPIKey)
-	viper.Set("model", cfg.Model)
-	viper.Set("show_diff", cfg.ShowDiff)
-	viper.Set("auto_copy", cfg.AutoCopy)
-	viper.Set("style", cfg.Style)
-	viper.Set("language", cfg.Language)
-	viper.Set("translation_language", cfg.TranslationLanguage)
-	viper.Set("cache_enabled", cfg.CacheEnabled)
-	viper.Set("cache_ttl_days", cfg.CacheTTLDays)
-	viper.Set("rate_limit_enabled", cfg.RateLimitEnabled)
-	viper.Set("rate_limit_requests", cfg.RateLimitRequests)
-	viper.Set("rate_limit_window_seconds", cfg.RateLimitWindow)
-	viper.Set("request_timeout_seconds", cfg.RequestTimeoutSeconds)
+// ShortenTargetPercent returns the target length for the shorten action, falling back to 50% when
+// unset or out of range
+func (c *Config) ShortenTargetPercent() int {
+	if c.ShortenPercent < 1 || c.ShortenPercent > 99 {
+		return 50 // Default fallback
+	}
+	return c.ShortenPercent
+}
 
-	configFile := filepath.Join(configPath, "config.yaml")
-	if err := viper.WriteConfigAs(configFile); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+// FormalityFor returns the form of address for translations into language, preferring a
+// per-language override over translation_formality
+func (c *Config) FormalityFor(language string) string {
+	for lang, formality := range c.FormalityByLanguage {
+		if strings.EqualFold(strings.TrimSpace(lang), strings.TrimSpace(language)) && strings.TrimSpace(formality) != "" {
+			return formality
+		}
 	}
+	if strings.TrimSpace(c.TranslationFormality) == "" {
+		return "auto"
+	}
+	return c.TranslationFormality
+}
 
-	// Set restrictive permissions on the config file to protect API key
-	if err := os.Chmod(configFile, ConfigFilePerm); err != nil {
-		return fmt.Errorf("failed to set config file permissions: %w", err)
+// LoadPromptTemplate returns the correction prompt template override: the prompt_template
+// entry if set, otherwise the contents of prompts/correction.tmpl in the config directory.
+// An empty string means no override is configured.
+func (c *Config) LoadPromptTemplate() (string, error) {
+	if strings.TrimSpace(c.PromptTemplate) != "" {
+		return c.PromptTemplate, nil
 	}
 
-	return nil
-}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
 
-func Set(key, value string) error {
-	if key == "" {
-		return fmt.Errorf("config key cannot be empty")
+	data, err := os.ReadFile(filepath.Join(dir, "prompts", CorrectionTemplateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read prompt template: %w", err)
 	}
+	return string(data), nil
+}
 
-	// Sanitize key to prevent injection
-	key = strings.TrimSpace(key)
-	if strings.ContainsAny(key, " \t\n\r") {
-		return fmt.Errorf("config key contains invalid characters")
+// LoadGlossary loads the terminology glossary from glossary_file, or from glossary.yaml in the
+// config directory when unset. A missing file yields an empty glossary.
+func (c *Config) LoadGlossary() (*glossary.Glossary, error) {
+	path, err := expandHome(strings.TrimSpace(c.GlossaryFile))
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		dir, err := Dir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, glossary.DefaultFile)
 	}
+	return glossary.Load(path)
+}
 
-	home, err := os.UserHomeDir()
+// OpenHistory opens the history of past corrections in the config directory, or returns nil when
+// the history is turned off
+func (c *Config) OpenHistory() (*history.History, error) {
+	if !c.HistoryEnabled {
+		return nil, nil
+	}
+	dir, err := Dir()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
+	return history.Open(filepath.Join(dir, history.DefaultFile), c.HistorySize)
+}
 
-	configPath := filepath.Join(home, ".grammr")
-	if err := os.MkdirAll(configPath, ConfigDirPerm); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// OpenAudit returns the audit log to record requests in, or nil when auditing is turned off
+func (c *Config) OpenAudit() (*audit.Log, error) {
+	if !c.AuditEnabled {
+		return nil, nil
 	}
+	return c.AuditLog()
+}
 
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(configPath)
+// AuditLog returns the audit log in the config directory, encrypted with the cache key, whether
+// or not auditing is turned on
+func (c *Config) AuditLog() (*audit.Log, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := c.CachePath()
+	if err != nil {
+		return nil, err
+	}
+	if cacheDir == "" {
+		if cacheDir, err = cache.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	// The key is kept in the cache directory where there is no keychain
+	if err := os.MkdirAll(cacheDir, cache.CacheDirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	key, err := cache.LoadKey(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return audit.New(filepath.Join(dir, audit.DefaultFile), key), nil
+}
 
-	// Try to read existing config (ignore error if file doesn't exist)
-	_ = viper.ReadInConfig()
+// OpenRateLimits creates the rate limit budgets, shared with the other grammr processes using
+// the config directory, or returns nil when rate limiting is turned off
+func (c *Config) OpenRateLimits() *ratelimit.Registry {
+	if !c.RateLimitEnabled {
+		return nil
+	}
+	maxRequests := c.RateLimitRequests
+	if maxRequests <= 0 {
+		maxRequests = 60 // Default
+	}
+	windowSeconds := c.RateLimitWindow
+	if windowSeconds <= 0 {
+		windowSeconds = 60 // Default: per minute
+	}
+	defaults := ratelimit.Budget{
+		Requests: maxRequests,
+		Window:   time.Duration(windowSeconds) * time.Second,
+		Burst:    c.RateLimitBurst,
+	}
+	budgets := make(map[string]ratelimit.Budget, len(c.RateLimits))
+	for key, limit := range c.RateLimits {
+		budgets[key] = ratelimit.Budget{
+			Requests: limit.Requests,
+			Window:   time.Duration(limit.WindowSeconds) * time.Second,
+			Burst:    limit.Burst,
+		}
+	}
+	registry := ratelimit.NewRegistry(defaults, budgets, 100*time.Millisecond)
+	// Don't create the config directory just to share the budgets
+	if dir, err := Dir(); err == nil {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			registry.Share(filepath.Join(dir, ratelimit.DefaultFile))
+		}
+	}
+	return registry
+}
 
-	// Backward compatibility: map "mode" to "style"
-	if key == "mode" {
-		key = "style"
+// SessionFile returns where the open texts are saved for the next launch, or an empty string
+// when sessions are turned off
+func (c *Config) SessionFile() (string, error) {
+	if !c.SessionEnabled {
+		return "", nil
 	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, session.DefaultFile), nil
+}
 
-	viper.Set(key, value)
+// NormalizeStyleName lowercases and trims a style name so it can be used as a lookup key
+func NormalizeStyleName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
 
-	configFile := filepath.Join(configPath, "config.yaml")
-	if err := viper.WriteConfigAs(configFile); err != nil {
-		// If file doesn't exist, try SafeWriteConfigAs
-		if err := viper.SafeWriteConfigAs(configFile); err != nil {
-			return fmt.Errorf("failed to write config file: %w", err)
-		}
+// GetAPIKey returns the appropriate API key based on the provider
+func (c *Config) GetAPIKey() string {
+	return c.APIKeyFor(c.Provider)
+}
+
+// APIKeyFor returns the API key of a provider, which may not be the one in use
+func (c *Config) APIKeyFor(provider string) string {
+	if provider == "anthropic" {
+		return c.AnthropicAPIKey
 	}
+	// Default to OpenAI
+	return c.APIKey
+}
+
+// Load reads the config file, see Store.Load
+func Load() (*Config, error) {
+	store, err := DefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Load()
+}
 
-	// Set restrictive permissions on the config file to protect API key
-	if err := os.Chmod(configFile, ConfigFilePerm); err != nil {
-		return fmt.Errorf("failed to set config file permissions: %w", err)
+// Save writes cfg to the config file, see Store.Save
+func Save(cfg *Config) error {
+	store, err := DefaultStore()
+	if err != nil {
+		return err
 	}
+	return store.Save(cfg)
+}
 
-	return nil
+// settings returns the value of every setting by its key in the config file
+func (c *Config) settings() map[string]interface{} {
+	return map[string]interface{}{
+		"provider":                          c.Provider,
+		"api_key":                           c.APIKey,
+		"anthropic_api_key":                 c.AnthropicAPIKey,
+		"model":                             c.Model,
+		"show_diff":                         c.ShowDiff,
+		"auto_copy":                         c.AutoCopy,
+		"style":                             c.Style,
+		"language":                          c.Language,
+		"translation_language":              c.TranslationLanguage,
+		"cache_enabled":                     c.CacheEnabled,
+		"cache_ttl_days":                    c.CacheTTLDays,
+		"rate_limit_enabled":                c.RateLimitEnabled,
+		"rate_limit_requests":               c.RateLimitRequests,
+		"rate_limit_window_seconds":         c.RateLimitWindow,
+		"rate_limit_burst":                  c.RateLimitBurst,
+		"rate_limits":                       c.RateLimits,
+		"request_timeout_seconds":           c.RequestTimeoutSeconds,
+		"correction_timeout_seconds":        c.CorrectionTimeoutSeconds,
+		"translation_timeout_seconds":       c.TranslationTimeoutSeconds,
+		"scale_timeouts":                    c.ScaleTimeouts,
+		"request_retries":                   c.RequestRetries,
+		"chunk_concurrency":                 c.ChunkConcurrency,
+		"custom_styles":                     c.CustomStyles,
+		"prompt_template":                   c.PromptTemplate,
+		"shorten_percent":                   c.ShortenPercent,
+		"format":                            c.Format,
+		"dialect":                           c.Dialect,
+		"category":                          c.Category,
+		"glossary_file":                     c.GlossaryFile,
+		"deterministic":                     c.Deterministic,
+		"confirm_tokens":                    c.ConfirmTokens,
+		"detect_language":                   c.DetectLanguage,
+		"translate_original":                c.TranslateOriginal,
+		"translation_formality":             c.TranslationFormality,
+		"translation_formality_by_language": c.FormalityByLanguage,
+		"romanize":                          c.Romanize,
+		"cache_backend":                     c.CacheBackend,
+		"cache_dir":                         c.CacheDir,
+		"key_storage":                       c.KeyStorage,
+		"theme":                             c.Theme,
+		"theme_colors":                      c.ThemeColors,
+		"history_enabled":                   c.HistoryEnabled,
+		"history_size":                      c.HistorySize,
+		"session_enabled":                   c.SessionEnabled,
+		"journal_file":                      c.JournalFile,
+		"audit_enabled":                     c.AuditEnabled,
+		"redact_pii":                        c.RedactPII,
+		"offline":                           c.Offline,
+		"diff_granularity":                  c.DiffGranularity,
+		"diff_ignore_whitespace":            c.DiffIgnoreWhitespace,
+		"keybindings":                       c.Keybindings,
+		"accessible":                        c.Accessible,
+		"clipboard":                         c.Clipboard,
+		"notifications":                     c.Notifications,
+	}
 }
 
-func Get(key string) interface{} {
-	if key == "" {
-		return nil
+// Set changes one setting in the config file, see Store.Set
+func Set(key, value string) error {
+	store, err := DefaultStore()
+	if err != nil {
+		return err
 	}
+	return store.Set(key, value)
+}
 
-	home, err := os.UserHomeDir()
+// Get returns one setting from the config file, see Store.Get
+func Get(key string) interface{} {
+	store, err := DefaultStore()
 	if err != nil {
 		return nil
 	}
-
-	configPath := filepath.Join(home, ".grammr")
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(configPath)
-	_ = viper.ReadInConfig() // Ignore error if config doesn't exist
-	return viper.Get(key)
+	return store.Get(key)
 }
@@ -1,20 +1,34 @@
 package config
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/spf13/viper"
+	"github.com/maximbilan/grammr/internal/ratelimit"
+	"github.com/zalando/go-keyring"
 )
 
+func TestMain(m *testing.M) {
+	// Tests point HOME at a temporary directory; keep XDG locations from taking over
+	os.Unsetenv("XDG_CONFIG_HOME")
+	os.Unsetenv("XDG_CACHE_HOME")
+	// Keep API keys out of the real keychain
+	keyring.MockInit()
+	os.Exit(m.Run())
+}
+
 func TestLoad(t *testing.T) {
 	// Create a temporary directory for testing
 	tmpDir := t.TempDir()
 	originalHome := os.Getenv("HOME")
 	defer func() {
 		os.Setenv("HOME", originalHome)
-		viper.Reset()
 	}()
 
 	// Set HOME to temp directory
@@ -60,7 +74,6 @@ func TestLoad(t *testing.T) {
 	})
 
 	t.Run("load existing config file", func(t *testing.T) {
-		viper.Reset()
 		configPath := filepath.Join(tmpDir, ".grammr")
 		if err := os.MkdirAll(configPath, 0755); err != nil {
 			t.Fatalf("Failed to create config directory: %v", err)
@@ -118,7 +131,6 @@ func TestSave(t *testing.T) {
 	originalHome := os.Getenv("HOME")
 	defer func() {
 		os.Setenv("HOME", originalHome)
-		viper.Reset()
 	}()
 
 	os.Setenv("HOME", tmpDir)
@@ -130,13 +142,14 @@ func TestSave(t *testing.T) {
 		{
 			name: "save complete config",
 			cfg: &Config{
-				APIKey:       "test-api-key",
-				Model:        "gpt-4",
-				ShowDiff:     false,
-				AutoCopy:     true,
-				Style:        "academic",
-				CacheEnabled: true,
-				CacheTTLDays: 10,
+				APIKey:        "test-api-key",
+				Model:         "gpt-4",
+				ShowDiff:      false,
+				AutoCopy:      true,
+				Style:         "academic",
+				CacheEnabled:  true,
+				CacheTTLDays:  10,
+				Deterministic: true,
 			},
 		},
 		{
@@ -149,7 +162,6 @@ func TestSave(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			viper.Reset()
 			err := Save(tt.cfg)
 			if err != nil {
 				t.Fatalf("Save() error = %v, want nil", err)
@@ -164,7 +176,6 @@ func TestSave(t *testing.T) {
 			}
 
 			// Reload and verify
-			viper.Reset()
 			loaded, err := Load()
 			if err != nil {
 				t.Fatalf("Load() after Save() error = %v", err)
@@ -191,6 +202,9 @@ func TestSave(t *testing.T) {
 			if loaded.CacheTTLDays != tt.cfg.CacheTTLDays {
 				t.Errorf("Save() CacheTTLDays = %v, want %v", loaded.CacheTTLDays, tt.cfg.CacheTTLDays)
 			}
+			if loaded.Deterministic != tt.cfg.Deterministic {
+				t.Errorf("Save() Deterministic = %v, want %v", loaded.Deterministic, tt.cfg.Deterministic)
+			}
 			if tt.cfg.Language != "" && loaded.Language != tt.cfg.Language {
 				t.Errorf("Save() Language = %v, want %v", loaded.Language, tt.cfg.Language)
 			}
@@ -206,7 +220,6 @@ func TestSet(t *testing.T) {
 	originalHome := os.Getenv("HOME")
 	defer func() {
 		os.Setenv("HOME", originalHome)
-		viper.Reset()
 	}()
 
 	os.Setenv("HOME", tmpDir)
@@ -247,14 +260,12 @@ func TestSet(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			viper.Reset()
 			err := Set(tt.key, tt.value)
 			if err != nil {
 				t.Fatalf("Set() error = %v, want nil", err)
 			}
 
 			// Verify value was set
-			viper.Reset()
 			// If key was "mode", it was mapped to "style", so check "style" instead
 			checkKey := tt.key
 			if tt.key == "mode" {
@@ -273,13 +284,11 @@ func TestGet(t *testing.T) {
 	originalHome := os.Getenv("HOME")
 	defer func() {
 		os.Setenv("HOME", originalHome)
-		viper.Reset()
 	}()
 
 	os.Setenv("HOME", tmpDir)
 
 	t.Run("get default value", func(t *testing.T) {
-		viper.Reset()
 		// Load config to set defaults
 		_, err := Load()
 		if err != nil {
@@ -293,7 +302,6 @@ func TestGet(t *testing.T) {
 	})
 
 	t.Run("get non-existent key", func(t *testing.T) {
-		viper.Reset()
 		_, err := Load()
 		if err != nil {
 			t.Fatalf("Load() error = %v", err)
@@ -306,7 +314,6 @@ func TestGet(t *testing.T) {
 	})
 
 	t.Run("get set value", func(t *testing.T) {
-		viper.Reset()
 		err := Set("test_key", "test_value")
 		if err != nil {
 			t.Fatalf("Set() error = %v", err)
@@ -324,7 +331,6 @@ func TestLoadWithInvalidConfigFile(t *testing.T) {
 	originalHome := os.Getenv("HOME")
 	defer func() {
 		os.Setenv("HOME", originalHome)
-		viper.Reset()
 	}()
 
 	os.Setenv("HOME", tmpDir)
@@ -372,12 +378,12 @@ func TestGetAPIKey(t *testing.T) {
 			want: "sk-ant-123",
 		},
 		{
-			name: "anthropic provider falls back to api_key",
+			name: "anthropic provider ignores api_key",
 			cfg: Config{
 				Provider: "anthropic",
-				APIKey:   "sk-openai-fallback",
+				APIKey:   "sk-openai-123",
 			},
-			want: "sk-openai-fallback",
+			want: "",
 		},
 	}
 
@@ -396,7 +402,6 @@ func TestSaveAndSetPermissions(t *testing.T) {
 	originalHome := os.Getenv("HOME")
 	defer func() {
 		os.Setenv("HOME", originalHome)
-		viper.Reset()
 	}()
 
 	os.Setenv("HOME", tmpDir)
@@ -448,7 +453,6 @@ func TestSetRejectsInvalidKeys(t *testing.T) {
 	originalHome := os.Getenv("HOME")
 	defer func() {
 		os.Setenv("HOME", originalHome)
-		viper.Reset()
 	}()
 
 	os.Setenv("HOME", tmpDir)
@@ -473,3 +477,329 @@ func TestSetRejectsInvalidKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadCustomStyles(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		os.Setenv("HOME", originalHome)
+	}()
+	os.Setenv("HOME", tmpDir)
+
+	configPath := filepath.Join(tmpDir, ".grammr")
+	if err := os.MkdirAll(configPath, 0700); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	configContent := `style: slack
+custom_styles:
+  - name: Slack
+    prompt: Keep it short and friendly.
+  - name: legal
+    prompt: Use precise legal language.
+  - name: broken
+`
+	if err := os.WriteFile(filepath.Join(configPath, "config.yaml"), []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.CustomStyles) != 3 {
+		t.Fatalf("Load() CustomStyles length = %d, want 3", len(cfg.CustomStyles))
+	}
+
+	prompts := cfg.CustomStylePrompts()
+	if len(prompts) != 2 {
+		t.Fatalf("CustomStylePrompts() = %v, want 2 entries", prompts)
+	}
+	if prompts["slack"] != "Keep it short and friendly." {
+		t.Errorf("CustomStylePrompts()[slack] = %q", prompts["slack"])
+	}
+
+	// Custom styles survive a save/load round trip
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	reloaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() after Save() error = %v", err)
+	}
+	if len(reloaded.CustomStylePrompts()) != 2 {
+		t.Fatalf("reloaded CustomStylePrompts() = %v, want 2 entries", reloaded.CustomStylePrompts())
+	}
+}
+
+func TestShadowedStyles(t *testing.T) {
+	cfg := &Config{CustomStyles: []CustomStyle{
+		{Name: "Formal", Prompt: "Talk like a pirate."},
+		{Name: "slack", Prompt: "Keep it short."},
+		{Name: "formal", Prompt: "Talk like Yoda."},
+	}}
+
+	if got := cfg.ShadowedStyles(); !slices.Equal(got, []string{"formal"}) {
+		t.Errorf("ShadowedStyles() = %v, want [formal]", got)
+	}
+	prompts := cfg.CustomStylePrompts()
+	if _, ok := prompts["formal"]; ok || len(prompts) != 1 {
+		t.Errorf("CustomStylePrompts() = %v, want only slack", prompts)
+	}
+	if warning := cfg.ShadowedStylesWarning(); !strings.Contains(warning, "formal") {
+		t.Errorf("ShadowedStylesWarning() = %q, want it to name formal", warning)
+	}
+
+	cfg.CustomStyles = cfg.CustomStyles[1:2]
+	if warning := cfg.ShadowedStylesWarning(); warning != "" {
+		t.Errorf("ShadowedStylesWarning() = %q, want none", warning)
+	}
+}
+
+func TestLoadPromptTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	cfg := &Config{}
+	got, err := cfg.LoadPromptTemplate()
+	if err != nil || got != "" {
+		t.Fatalf("LoadPromptTemplate() = %q, %v; want no override", got, err)
+	}
+
+	promptsDir := filepath.Join(tmpDir, ".grammr", "prompts")
+	if err := os.MkdirAll(promptsDir, 0700); err != nil {
+		t.Fatalf("Failed to create prompts directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(promptsDir, CorrectionTemplateFile), []byte("From file: {{.Text}}"), 0600); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	got, err = cfg.LoadPromptTemplate()
+	if err != nil || got != "From file: {{.Text}}" {
+		t.Fatalf("LoadPromptTemplate() = %q, %v; want file template", got, err)
+	}
+
+	// The config entry takes precedence over the file
+	cfg.PromptTemplate = "From config: {{.Text}}"
+	got, err = cfg.LoadPromptTemplate()
+	if err != nil || got != "From config: {{.Text}}" {
+		t.Fatalf("LoadPromptTemplate() = %q, %v; want config template", got, err)
+	}
+}
+
+func TestFormalityFor(t *testing.T) {
+	cfg := &Config{
+		TranslationFormality: "informal",
+		FormalityByLanguage:  map[string]string{"German": "formal", "french": ""},
+	}
+
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{language: "german", want: "formal"},
+		{language: "french", want: "informal"},
+		{language: "spanish", want: "informal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			if got := cfg.FormalityFor(tt.language); got != tt.want {
+				t.Errorf("FormalityFor(%q) = %q, want %q", tt.language, got, tt.want)
+			}
+		})
+	}
+
+	if got := (&Config{}).FormalityFor("german"); got != "auto" {
+		t.Errorf("FormalityFor() without a setting = %q, want %q", got, "auto")
+	}
+}
+
+func TestOperationTimeouts(t *testing.T) {
+	cfg := &Config{RequestTimeoutSeconds: 20, TranslationTimeoutSeconds: 90}
+	if got := cfg.CorrectionTimeout(); got != 20*time.Second {
+		t.Errorf("CorrectionTimeout() = %v, want request_timeout_seconds", got)
+	}
+	if got := cfg.TranslationTimeout(); got != 90*time.Second {
+		t.Errorf("TranslationTimeout() = %v, want translation_timeout_seconds", got)
+	}
+	if got := (&Config{}).CorrectionTimeout(); got != 30*time.Second {
+		t.Errorf("CorrectionTimeout() without settings = %v, want 30s", got)
+	}
+
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 200)
+	if got := cfg.ScaledTimeout(20*time.Second, long); got != 20*time.Second {
+		t.Errorf("ScaledTimeout() = %v with scale_timeouts off, want it unchanged", got)
+	}
+	cfg.ScaleTimeouts = true
+	if got := cfg.ScaledTimeout(20*time.Second, long); got <= 20*time.Second {
+		t.Errorf("ScaledTimeout() = %v, want more time for a long text", got)
+	}
+	if got := cfg.ScaledTimeout(20*time.Second, "Short."); got != 20*time.Second {
+		t.Errorf("ScaledTimeout() = %v, want a short text unchanged", got)
+	}
+}
+
+func TestLoadRateLimits(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	configContent := `rate_limits:
+  translation:
+    requests: 30
+  anthropic/translation:
+    requests: 10
+    window_seconds: 30
+    burst: 2
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(configContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]RateLimit{
+		"translation":           {Requests: 30},
+		"anthropic/translation": {Requests: 10, WindowSeconds: 30, Burst: 2},
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg.RateLimits, want) {
+		t.Fatalf("Load() RateLimits = %+v, want %+v", cfg.RateLimits, want)
+	}
+
+	// Budgets survive a save/load round trip
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	reloaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(reloaded.RateLimits, want) {
+		t.Errorf("reloaded RateLimits = %+v, want %+v", reloaded.RateLimits, want)
+	}
+}
+
+func TestOpenRateLimits(t *testing.T) {
+	// Keep the shared budgets away from the real config directory
+	t.Setenv("HOME", t.TempDir())
+
+	t.Run("disabled returns nil", func(t *testing.T) {
+		cfg := &Config{Provider: "openai"}
+		cfg.RateLimitEnabled = false
+		if rl := cfg.OpenRateLimits(); rl != nil {
+			t.Fatalf("OpenRateLimits() = %#v, want nil", rl)
+		}
+	})
+
+	t.Run("enabled returns limiter and defaults", func(t *testing.T) {
+		cfg := &Config{Provider: "openai"}
+		cfg.RateLimitEnabled = true
+		cfg.RateLimitRequests = 0
+		cfg.RateLimitWindow = 0
+
+		rl := cfg.OpenRateLimits().Limiter(cfg.Provider, ratelimit.OperationCorrection)
+		if rl == nil {
+			t.Fatal("OpenRateLimits() returned no limiter")
+		}
+		// First call should pass immediately with initial token bucket.
+		if err := rl.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	})
+
+	t.Run("burst limits requests at once", func(t *testing.T) {
+		cfg := &Config{Provider: "openai"}
+		cfg.RateLimitEnabled = true
+		cfg.RateLimitRequests = 60
+		cfg.RateLimitWindow = 60
+		cfg.RateLimitBurst = 1
+
+		rl := cfg.OpenRateLimits().Limiter(cfg.Provider, ratelimit.OperationCorrection)
+		if _, ok := rl.TryWait(); !ok {
+			t.Fatal("first TryWait() was refused")
+		}
+		if delay, ok := rl.TryWait(); ok || delay > time.Second {
+			t.Fatalf("TryWait() = %v, %v, want to wait up to a second", delay, ok)
+		}
+	})
+
+	t.Run("translations have a budget of their own", func(t *testing.T) {
+		cfg := &Config{Provider: "openai"}
+		cfg.RateLimitEnabled = true
+		cfg.RateLimitRequests = 60
+		cfg.RateLimitWindow = 60
+		cfg.RateLimits = map[string]RateLimit{"translation": {Requests: 1}}
+
+		limits := cfg.OpenRateLimits()
+		translations := limits.Limiter(cfg.Provider, ratelimit.OperationTranslation)
+		if _, ok := translations.TryWait(); !ok {
+			t.Fatal("first translation was refused")
+		}
+		if _, ok := translations.TryWait(); ok {
+			t.Fatal("second translation within a minute was allowed")
+		}
+		if _, ok := limits.Limiter(cfg.Provider, ratelimit.OperationCorrection).TryWait(); !ok {
+			t.Fatal("correction was refused after using up the translation budget")
+		}
+	})
+
+	t.Run("processes share budgets", func(t *testing.T) {
+		dir, err := Dir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		cfg := &Config{Provider: "openai"}
+		cfg.RateLimitEnabled = true
+		cfg.RateLimitRequests = 1
+		cfg.RateLimitWindow = 60
+
+		// Each call stands in for another grammr process
+		if _, ok := cfg.OpenRateLimits().Limiter(cfg.Provider, ratelimit.OperationCorrection).TryWait(); !ok {
+			t.Fatal("first correction was refused")
+		}
+		if _, ok := cfg.OpenRateLimits().Limiter(cfg.Provider, ratelimit.OperationCorrection).TryWait(); ok {
+			t.Fatal("another process went over the shared budget")
+		}
+		if _, err := os.Stat(filepath.Join(dir, ratelimit.DefaultFile)); err != nil {
+			t.Errorf("shared budgets weren't stored: %v", err)
+		}
+	})
+}
+
+func TestOpenAudit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	if log, err := (&Config{}).OpenAudit(); err != nil || log != nil {
+		t.Fatalf("OpenAudit() = %v, %v, want nil when auditing is off", log, err)
+	}
+
+	cfg := &Config{AuditEnabled: true}
+	log, err := cfg.OpenAudit()
+	if err != nil {
+		t.Fatalf("OpenAudit() error = %v", err)
+	}
+	if want := filepath.Join(home, ".grammr", "audit.log"); log.Path() != want {
+		t.Errorf("audit log path = %s, want %s", log.Path(), want)
+	}
+	if _, err := log.Record("openai", "gpt-4o", nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	// The log is read back with the same key
+	again, err := cfg.OpenAudit()
+	if err != nil {
+		t.Fatalf("OpenAudit() error = %v", err)
+	}
+	if entries, err := again.Entries(); err != nil || len(entries) != 1 {
+		t.Errorf("Entries() = %v, %v, want the recorded request", entries, err)
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config_version of config files written by this version of grammr. Files
+// without one are version 0.
+const CurrentVersion = 3
+
+// migrations upgrade the settings of a config file: migrations[n] upgrades version n to n+1.
+// Add a migration, and bump CurrentVersion, instead of handling old settings where they are used.
+var migrations = []func(settings map[string]interface{}){
+	migrateModeToStyle,
+	migrateAnthropicAPIKey,
+	migrateClipboardOSC52,
+}
+
+// migrateModeToStyle renames mode, the old name of style
+func migrateModeToStyle(settings map[string]interface{}) {
+	mode, ok := settings["mode"]
+	if !ok {
+		return
+	}
+	delete(settings, "mode")
+	if _, ok := settings["style"]; !ok && fmt.Sprint(mode) != "" {
+		settings["style"] = mode
+	}
+}
+
+// migrateAnthropicAPIKey copies api_key to anthropic_api_key for Anthropic users, since older
+// versions used api_key for Anthropic when anthropic_api_key was empty
+func migrateAnthropicAPIKey(settings map[string]interface{}) {
+	provider, _ := settings["provider"].(string)
+	apiKey, _ := settings["api_key"].(string)
+	anthropicKey, _ := settings["anthropic_api_key"].(string)
+	if strings.EqualFold(strings.TrimSpace(provider), "anthropic") && anthropicKey == "" && apiKey != "" {
+		settings["anthropic_api_key"] = apiKey
+	}
+}
+
+// migrateClipboardOSC52 replaces clipboard_osc52, which forced OSC 52 before the clipboard
+// setting could name any clipboard
+func migrateClipboardOSC52(settings map[string]interface{}) {
+	force, ok := settings["clipboard_osc52"]
+	if !ok {
+		return
+	}
+	delete(settings, "clipboard_osc52")
+	if _, ok := settings["clipboard"]; !ok && force == true {
+		settings["clipboard"] = "osc52"
+	}
+}
+
+// migrate upgrades the config file to CurrentVersion and rewrites it, once, keeping its comments
+// and the order of its settings. It returns the upgraded file, or nil when there is nothing to
+// upgrade. When the file can't be rewritten, the upgraded file is returned with the reason as
+// unsaved, so it can be used in memory. A missing file, or one from a newer version of grammr,
+// is left alone.
+func (s *Store) migrate() (migrated []byte, unsaved, err error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// Reported by the caller reading the file
+		return nil, nil, nil
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	var settings map[string]interface{}
+	if root.Kind != yaml.MappingNode || root.Decode(&settings) != nil {
+		return nil, nil, nil
+	}
+	if settings == nil {
+		settings = make(map[string]interface{})
+	}
+	version, _ := settings["config_version"].(int)
+	if version >= CurrentVersion {
+		return nil, nil, nil
+	}
+
+	original := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		original[key] = value
+	}
+	for _, migration := range migrations[version:] {
+		migration(settings)
+	}
+	settings["config_version"] = CurrentVersion
+	if err := updateMapping(root, original, settings); err != nil {
+		return nil, nil, fmt.Errorf("failed to migrate config: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to migrate config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to migrate config: %w", err)
+	}
+	migrated = buf.Bytes()
+	if err := writeFile(s.path, migrated); err != nil {
+		return migrated, fmt.Errorf("failed to save the upgraded config: %w", err), nil
+	}
+	return migrated, nil, nil
+}
+
+// updateMapping changes the mapping node from the settings in before to those in after. Settings
+// that didn't change keep their nodes, and so their comments; new ones are added at the end.
+func updateMapping(mapping *yaml.Node, before, after map[string]interface{}) error {
+	var content []*yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		updated, ok := after[key.Value]
+		if !ok {
+			continue
+		}
+		if !reflect.DeepEqual(before[key.Value], updated) {
+			var node yaml.Node
+			if err := node.Encode(updated); err != nil {
+				return err
+			}
+			node.LineComment = value.LineComment
+			value = &node
+		}
+		content = append(content, key, value)
+	}
+
+	var added []string
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		var value yaml.Node
+		if err := value.Encode(after[key]); err != nil {
+			return err
+		}
+		content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+	mapping.Content = content
+	return nil
+}
+
+// writeFile writes data to a temporary file next to path and renames it over path, so a failed
+// write never leaves half a config behind
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(ConfigFilePerm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	legacy := "provider: anthropic\napi_key: keyring:api_key\nmode: formal\n"
+	if err := os.WriteFile(path, []byte(legacy), ConfigFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(path)
+
+	if _, err := store.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated := string(data)
+	for _, want := range []string{"config_version: 3", "style: formal", "anthropic_api_key: keyring:api_key"} {
+		if !strings.Contains(migrated, want) {
+			t.Errorf("migrated config is missing %q:\n%s", want, migrated)
+		}
+	}
+	if strings.Contains(migrated, "mode:") {
+		t.Errorf("migrated config still has mode:\n%s", migrated)
+	}
+
+	// The file is only rewritten once
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	again, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.ModTime().Equal(info.ModTime()) {
+		t.Error("a migrated config was rewritten again")
+	}
+}
+
+func TestMigrateKeepsComments(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	legacy := "# grammr settings\nprovider: anthropic # the one at work\n\n# Old name of style\nmode: formal\napi_key: keyring:api_key\ncustom_styles:\n  - name: pirate\n    prompt: Talk like a pirate.\n"
+	if err := os.WriteFile(path, []byte(legacy), ConfigFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewStore(path).Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated := string(data)
+	want := "# grammr settings\nprovider: anthropic # the one at work\napi_key: keyring:api_key\ncustom_styles:\n  - name: pirate\n    prompt: Talk like a pirate.\nanthropic_api_key: keyring:api_key\nconfig_version: 3\nstyle: formal\n"
+	if migrated != want {
+		t.Errorf("migrated config =\n%s\nwant\n%s", migrated, want)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != ConfigFilePerm {
+		t.Errorf("migrated config permissions = %o, want %o", info.Mode().Perm(), ConfigFilePerm)
+	}
+}
+
+func TestMigrateUnwritableConfig(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to a read-only directory")
+	}
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	legacy := "mode: formal\n"
+	if err := os.WriteFile(path, []byte(legacy), ConfigFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0o700) })
+
+	cfg, err := NewStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want the config upgraded in memory", err)
+	}
+	if cfg.Style != "formal" {
+		t.Errorf("Load() Style = %q, want the migrated formal", cfg.Style)
+	}
+	if cfg.UnsavedUpgradeWarning() == "" {
+		t.Error("UnsavedUpgradeWarning() is empty, want the reason the upgrade wasn't saved")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != legacy {
+		t.Errorf("config = %q, %v, want it left as it was", data, err)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     map[string]interface{}
+	}{
+		{
+			name:     "mode becomes style",
+			settings: map[string]interface{}{"mode": "formal"},
+			want:     map[string]interface{}{"style": "formal"},
+		},
+		{
+			name:     "style wins over mode",
+			settings: map[string]interface{}{"mode": "formal", "style": "academic"},
+			want:     map[string]interface{}{"style": "academic"},
+		},
+		{
+			name:     "api_key is kept for anthropic",
+			settings: map[string]interface{}{"provider": "anthropic", "api_key": "sk-1"},
+			want:     map[string]interface{}{"provider": "anthropic", "api_key": "sk-1", "anthropic_api_key": "sk-1"},
+		},
+		{
+			name:     "anthropic_api_key isn't replaced",
+			settings: map[string]interface{}{"provider": "anthropic", "api_key": "sk-1", "anthropic_api_key": "sk-ant-1"},
+			want:     map[string]interface{}{"provider": "anthropic", "api_key": "sk-1", "anthropic_api_key": "sk-ant-1"},
+		},
+		{
+			name:     "clipboard_osc52 becomes clipboard",
+			settings: map[string]interface{}{"clipboard_osc52": true},
+			want:     map[string]interface{}{"clipboard": "osc52"},
+		},
+		{
+			name:     "clipboard_osc52 off is dropped",
+			settings: map[string]interface{}{"clipboard_osc52": false},
+			want:     map[string]interface{}{},
+		},
+		{
+			name:     "openai is left alone",
+			settings: map[string]interface{}{"provider": "openai", "api_key": "sk-1"},
+			want:     map[string]interface{}{"provider": "openai", "api_key": "sk-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, migration := range migrations {
+				migration(tt.settings)
+			}
+			if len(tt.settings) != len(tt.want) {
+				t.Fatalf("settings = %v, want %v", tt.settings, tt.want)
+			}
+			for key, want := range tt.want {
+				if tt.settings[key] != want {
+					t.Errorf("%s = %v, want %v", key, tt.settings[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrationsMatchVersion(t *testing.T) {
+	if len(migrations) != CurrentVersion {
+		t.Fatalf("%d migrations for config version %d", len(migrations), CurrentVersion)
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+// offlineFlag is set with --offline, which turns offline mode on whatever the config says
+var offlineFlag bool
+
+// SetOffline makes Load turn the offline setting on, without changing it in the config file. False
+// leaves the setting to the config.
+func SetOffline(offline bool) {
+	offlineFlag = offline
+}
+
+// applyOffline turns offline mode on for --offline. Like a setting of the project config, it
+// isn't saved.
+func (c *Config) applyOffline() {
+	if !offlineFlag || c.Offline {
+		return
+	}
+	if c.overrides == nil {
+		c.overrides = make(map[string]override)
+	}
+	c.overrides["offline"] = override{global: false, project: true}
+	c.Offline = true
+}
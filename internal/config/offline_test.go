@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestOfflineFlag(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	chdir(t, home)
+	SetOffline(true)
+	t.Cleanup(func() { SetOffline(false) })
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Offline {
+		t.Fatal("Load() should turn offline on for --offline")
+	}
+	// Like a project setting, the flag isn't saved
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	SetOffline(false)
+	saved, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.Offline {
+		t.Error("Save() should not keep the offline mode of --offline")
+	}
+}
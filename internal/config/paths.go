@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configFileOverride is the config file given with --config, empty for the default location
+var configFileOverride string
+
+// SetConfigFile makes Load, Save, Set and Get use path instead of the default config file. An
+// empty path restores the default.
+func SetConfigFile(path string) {
+	configFileOverride = strings.TrimSpace(path)
+}
+
+// Dir returns the directory grammr keeps its configuration, prompts and glossary in: the
+// directory of the --config file if one was given, otherwise ~/.grammr. When ~/.grammr doesn't
+// exist yet and XDG_CONFIG_HOME is set, $XDG_CONFIG_HOME/grammr is used instead.
+func Dir() (string, error) {
+	if configFileOverride != "" {
+		file, err := expandHome(configFileOverride)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Dir(file), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	legacy := filepath.Join(home, ".grammr")
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "grammr"), nil
+	}
+	return legacy, nil
+}
+
+// File returns the path of the config file
+func File() (string, error) {
+	if configFileOverride != "" {
+		return expandHome(configFileOverride)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// CachePath returns the cache directory set with cache_dir, or an empty string to use the
+// default location
+func (c *Config) CachePath() (string, error) {
+	if strings.TrimSpace(c.CacheDir) == "" {
+		return "", nil
+	}
+	return expandHome(strings.TrimSpace(c.CacheDir))
+}
+
+// JournalPath returns the journal set with journal_file, or an empty string when there is none
+func (c *Config) JournalPath() (string, error) {
+	if strings.TrimSpace(c.JournalFile) == "" {
+		return "", nil
+	}
+	return expandHome(strings.TrimSpace(c.JournalFile))
+}
+
+// expandHome replaces a leading ~/ in path with the home directory
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, path[2:]), nil
+}
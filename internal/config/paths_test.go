@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	tests := []struct {
+		name       string
+		legacy     bool   // ~/.grammr exists
+		xdg        string // XDG_CONFIG_HOME, relative to the temporary home
+		configFile string
+		want       string // Relative to the temporary home
+	}{
+		{name: "default", want: ".grammr"},
+		{name: "xdg", xdg: "xdg", want: filepath.Join("xdg", "grammr")},
+		{name: "existing ~/.grammr wins over xdg", legacy: true, xdg: "xdg", want: ".grammr"},
+		{name: "config flag", xdg: "xdg", configFile: "~/dotfiles/grammr.yaml", want: "dotfiles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", "")
+			if tt.xdg != "" {
+				t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, tt.xdg))
+			}
+			if tt.legacy {
+				if err := os.MkdirAll(filepath.Join(home, ".grammr"), ConfigDirPerm); err != nil {
+					t.Fatalf("failed to create ~/.grammr: %v", err)
+				}
+			}
+			SetConfigFile(tt.configFile)
+			defer SetConfigFile("")
+
+			got, err := Dir()
+			if err != nil {
+				t.Fatalf("Dir() error = %v", err)
+			}
+			if want := filepath.Join(home, tt.want); got != want {
+				t.Errorf("Dir() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	got, err := File()
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if want := filepath.Join(home, ".grammr", "config.yaml"); got != want {
+		t.Errorf("File() = %q, want %q", got, want)
+	}
+
+	SetConfigFile("/etc/grammr/work.yaml")
+	defer SetConfigFile("")
+	if got, err := File(); err != nil || got != "/etc/grammr/work.yaml" {
+		t.Errorf("File() = %q, %v, want the --config file", got, err)
+	}
+}
+
+func TestCachePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		cacheDir string
+		want     string
+	}{
+		{cacheDir: "", want: ""},
+		{cacheDir: "/var/cache/grammr", want: "/var/cache/grammr"},
+		{cacheDir: "~/cache", want: filepath.Join(home, "cache")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cacheDir, func(t *testing.T) {
+			got, err := (&Config{CacheDir: tt.cacheDir}).CachePath()
+			if err != nil {
+				t.Fatalf("CachePath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CachePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadWithConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	file := filepath.Join(home, "work", "grammr.yaml")
+	if err := os.MkdirAll(filepath.Dir(file), ConfigDirPerm); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(file, []byte("model: gpt-4o-mini\n"), ConfigFilePerm); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	SetConfigFile(file)
+	defer SetConfigFile("")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Model != "gpt-4o-mini" {
+		t.Errorf("Load() model = %q, want the one from the --config file", cfg.Model)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".grammr")); !os.IsNotExist(err) {
+		t.Errorf("Load() with --config should not create ~/.grammr")
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile is the name of a project config, looked up in the current directory and its
+// parents
+const ProjectConfigFile = ".grammr.yaml"
+
+// projectKeys are the settings a project config may change. Credentials, the provider and local
+// settings such as the cache stay with each user, and so do prompt_template and custom_styles,
+// which would let any repository grammr runs in replace the instructions given to the model.
+var projectKeys = map[string]bool{
+	"style":                             true,
+	"language":                          true,
+	"detect_language":                   true,
+	"dialect":                           true,
+	"category":                          true,
+	"format":                            true,
+	"glossary_file":                     true,
+	"shorten_percent":                   true,
+	"translation_language":              true,
+	"translation_formality":             true,
+	"translation_formality_by_language": true,
+	"offline":                           true,
+}
+
+// override is the value of a setting before and after a project config changed it
+type override struct {
+	global  interface{}
+	project interface{}
+}
+
+// ProjectFile returns the project config applied over the global config, or an empty string
+// when there is none
+func (c *Config) ProjectFile() string {
+	return c.project
+}
+
+// applyProject merges the nearest project config over c, so a repository can share its style,
+// language and glossary with everyone working on it. Save keeps writing the global values.
+func (c *Config) applyProject() error {
+	path, err := findProjectFile()
+	if err != nil || path == "" {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read project config: %w", err)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse project config %s: %w", path, err)
+	}
+
+	var rejected []string
+	for key := range values {
+		if !projectKeys[key] {
+			rejected = append(rejected, key)
+		}
+	}
+	if len(rejected) > 0 {
+		sort.Strings(rejected)
+		return fmt.Errorf("%s: %s can't be set in a project config", path, strings.Join(rejected, ", "))
+	}
+	// The glossary is in the project, so a project config can't send a file from elsewhere to the model
+	if glossaryFile, ok := values["glossary_file"].(string); ok && glossaryFile != "" {
+		if !filepath.IsLocal(glossaryFile) || strings.HasPrefix(glossaryFile, "~") {
+			return fmt.Errorf("%s: glossary_file must be a relative path inside the project, got %q", path, glossaryFile)
+		}
+		values["glossary_file"] = filepath.Join(filepath.Dir(path), glossaryFile)
+	}
+
+	global := c.settings()
+	v := viper.New()
+	if err := v.MergeConfigMap(values); err != nil {
+		return fmt.Errorf("failed to merge project config: %w", err)
+	}
+	// Replace lists and maps rather than merging them into the global ones
+	zeroFields := func(dc *mapstructure.DecoderConfig) { dc.ZeroFields = true }
+	if err := v.Unmarshal(c, zeroFields); err != nil {
+		return fmt.Errorf("failed to parse project config %s: %w", path, err)
+	}
+
+	project := c.settings()
+	c.project = path
+	c.overrides = make(map[string]override, len(values))
+	for key := range values {
+		c.overrides[key] = override{global: global[key], project: project[key]}
+	}
+	return nil
+}
+
+// findProjectFile returns the project config in the current directory or its nearest parent
+// that has one, or an empty string when there is none
+func findProjectFile() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() {
+			return path, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read project config: %w", err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// KeyStorageKeyring keeps API keys in the OS keychain, with only a reference in the config file
+	KeyStorageKeyring = "keyring"
+	// KeyStorageFile keeps API keys in the config file
+	KeyStorageFile = "file"
+
+	// keyringService names grammr's API keys in the OS keychain
+	keyringService = "grammr"
+	// keyringRef is the prefix of a config value that refers to a keychain entry
+	keyringRef = "keyring:"
+)
+
+// ErrNoKeychain is returned, wrapped, by Save when it had to write an API key to the config file
+// because there is no keychain. The config is saved all the same.
+var ErrNoKeychain = errors.New("no keychain available")
+
+// secretKeys are the settings kept in the keychain
+var secretKeys = []string{"api_key", "anthropic_api_key"}
+
+func isSecretKey(key string) bool {
+	for _, secret := range secretKeys {
+		if key == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// storeSecret keeps value in the keychain and returns the reference to write to the config file
+// instead. It returns value itself when it is empty or there is no keychain.
+func storeSecret(key, value string) (string, bool) {
+	if value == "" || strings.HasPrefix(value, keyringRef) {
+		return value, false
+	}
+	if stored, err := keyring.Get(keyringService, key); err != nil || stored != value {
+		if err := keyring.Set(keyringService, key, value); err != nil {
+			return value, false
+		}
+	}
+	return keyringRef + key, true
+}
+
+// resolveSecret returns the API key a config value refers to, or the value itself when it isn't
+// a reference
+func resolveSecret(key, value string) (string, error) {
+	name, ok := strings.CutPrefix(value, keyringRef)
+	if !ok {
+		return value, nil
+	}
+	secret, err := keyring.Get(keyringService, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from the keychain: %w", key, err)
+	}
+	return secret, nil
+}
+
+// resolveSecrets replaces keychain references in c with the API keys they refer to
+func (c *Config) resolveSecrets() error {
+	var err error
+	if c.APIKey, err = resolveSecret("api_key", c.APIKey); err != nil {
+		return err
+	}
+	if c.AnthropicAPIKey, err = resolveSecret("anthropic_api_key", c.AnthropicAPIKey); err != nil {
+		return err
+	}
+	return nil
+}
+
+// InKeychain reports whether the setting key is kept in the keychain rather than the config file
+func InKeychain(key string) bool {
+	store, err := DefaultStore()
+	if err != nil {
+		return false
+	}
+	return store.InKeychain(key)
+}
+
+// InKeychain reports whether the setting key is kept in the keychain rather than the config file
+func (s *Store) InKeychain(key string) bool {
+	v, _, _, err := s.viper()
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(v.GetString(key), keyringRef)
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestSetAPIKeyInKeychain(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	store := NewStore(path)
+
+	if err := store.Set("api_key", "sk-secret-123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-secret-123") {
+		t.Errorf("config file contains the API key:\n%s", data)
+	}
+	if !store.InKeychain("api_key") {
+		t.Error("InKeychain() = false, want true")
+	}
+	if got := store.Get("api_key"); got != "sk-secret-123" {
+		t.Errorf("Get() = %v, want the key from the keychain", got)
+	}
+
+	cfg, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.APIKey != "sk-secret-123" {
+		t.Errorf("Load() APIKey = %q, want the key from the keychain", cfg.APIKey)
+	}
+
+	// Saving the loaded config keeps the key out of the file
+	cfg.Model = "gpt-4o-mini"
+	if err := store.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "sk-secret-123") {
+		t.Errorf("Save() wrote the API key to the config file:\n%s", data)
+	}
+
+	// Clearing the key removes it from the keychain
+	if err := store.Set("api_key", ""); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, err := keyring.Get(keyringService, "api_key"); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("keychain entry still exists: %v", err)
+	}
+}
+
+func TestSetAPIKeyWithoutKeychain(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	t.Run("no keychain", func(t *testing.T) {
+		keyring.MockInitWithError(errors.New("no keychain"))
+		defer keyring.MockInit()
+		store := NewStore(filepath.Join(t.TempDir(), "config.yaml"))
+
+		if err := store.Set("api_key", "sk-secret-123"); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if store.InKeychain("api_key") {
+			t.Error("InKeychain() = true without a keychain")
+		}
+		if got := store.Get("api_key"); got != "sk-secret-123" {
+			t.Errorf("Get() = %v, want the key from the file", got)
+		}
+	})
+
+	t.Run("key_storage file", func(t *testing.T) {
+		keyring.MockInit()
+		store := NewStore(filepath.Join(t.TempDir(), "config.yaml"))
+		if err := store.Set("key_storage", "file"); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if err := store.Set("anthropic_api_key", "sk-ant-123"); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if store.InKeychain("anthropic_api_key") {
+			t.Error("InKeychain() = true with key_storage file")
+		}
+	})
+}
+
+func TestSaveWithoutKeychain(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keychain"))
+	defer keyring.MockInit()
+	t.Setenv("HOME", t.TempDir())
+	store := NewStore(filepath.Join(t.TempDir(), "config.yaml"))
+	cfg := &Config{Provider: "openai", Model: "gpt-4o", APIKey: "sk-secret-123"}
+
+	err := store.Save(cfg)
+	if !errors.Is(err, ErrNoKeychain) || !strings.Contains(err.Error(), "api_key is stored in the config file") {
+		t.Fatalf("Save() error = %v, want the key reported as stored in the config file", err)
+	}
+	if got := store.Get("api_key"); got != "sk-secret-123" {
+		t.Errorf("Get() = %v, want the config saved all the same", got)
+	}
+
+	// A key already in the file isn't reported again
+	cfg.Model = "gpt-4o-mini"
+	if err := store.Save(cfg); err != nil {
+		t.Errorf("Save() error = %v, want none for a key already in the file", err)
+	}
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/maximbilan/grammr/internal/history"
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+)
+
+// Store reads and writes one config file. It shares no state with other stores: every operation
+// uses its own viper instance, so a daemon and the TUI can use the config at once.
+type Store struct {
+	path string
+}
+
+// NewStore creates a Store for the config file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultStore returns the Store for the config file in use, see File
+func DefaultStore() (*Store, error) {
+	path, err := File()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(path), nil
+}
+
+// viper returns a viper instance with the defaults, reading the config file if it exists. An
+// upgrade of the file that couldn't be saved is applied in memory, and returned as unsaved.
+func (s *Store) viper() (v *viper.Viper, found bool, unsaved, err error) {
+	v = viper.New()
+	v.SetConfigFile(s.path)
+	v.SetConfigType("yaml")
+	setDefaults(v)
+
+	found, unsaved, err = s.read(v)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return v, found, unsaved, nil
+}
+
+// read reads the config file into v, upgraded to CurrentVersion, and reports whether there is
+// one. An upgrade that couldn't be saved is returned as unsaved.
+func (s *Store) read(v *viper.Viper) (found bool, unsaved, err error) {
+	migrated, unsaved, err := s.migrate()
+	if err != nil {
+		return false, nil, err
+	}
+	if migrated != nil {
+		if err := v.ReadConfig(bytes.NewReader(migrated)); err != nil {
+			return false, nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		return true, unsaved, nil
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok || errors.Is(err, fs.ErrNotExist) {
+			return false, nil, nil
+		}
+		return false, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return true, nil, nil
+}
+
+// setDefaults sets the default of every setting that has one
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("provider", "openai") // Default to OpenAI for backward compatibility
+	v.SetDefault("model", "gpt-4o")
+	v.SetDefault("show_diff", true)
+	v.SetDefault("style", "casual")
+	v.SetDefault("language", "english")
+	v.SetDefault("translation_language", "")
+	v.SetDefault("cache_enabled", true)
+	v.SetDefault("cache_ttl_days", 7)
+	v.SetDefault("cache_backend", "files")
+	v.SetDefault("rate_limit_enabled", true)
+	v.SetDefault("rate_limit_requests", 60)       // 60 requests
+	v.SetDefault("rate_limit_window_seconds", 60) // per minute
+	v.SetDefault("rate_limit_burst", 0)           // all of them at once
+	v.SetDefault("request_timeout_seconds", 30)   // 30 seconds default timeout
+	v.SetDefault("correction_timeout_seconds", 0) // 0 uses request_timeout_seconds
+	v.SetDefault("translation_timeout_seconds", 0)
+	v.SetDefault("scale_timeouts", true)
+	v.SetDefault("request_retries", 2)
+	v.SetDefault("chunk_concurrency", 4)
+	v.SetDefault("shorten_percent", 50)
+	v.SetDefault("format", "auto")
+	v.SetDefault("category", "all")
+	v.SetDefault("confirm_tokens", 20000)
+	v.SetDefault("detect_language", true)
+	v.SetDefault("translation_formality", "auto")
+	v.SetDefault("key_storage", KeyStorageKeyring)
+	v.SetDefault("theme", "dark")
+	v.SetDefault("history_enabled", true)
+	v.SetDefault("history_size", history.DefaultSize)
+	v.SetDefault("session_enabled", true)
+	v.SetDefault("audit_enabled", false)
+	v.SetDefault("redact_pii", false)
+	v.SetDefault("offline", false)
+	v.SetDefault("diff_granularity", DiffWords)
+	v.SetDefault("keybindings", KeybindingsDefault)
+	v.SetDefault("accessible", false)
+	v.SetDefault("clipboard", "auto")
+	v.SetDefault("notifications", "all")
+}
+
+// Load reads the config file, with defaults for missing settings, and merges the project config
+// over it. A missing file yields the defaults and creates the config directory.
+func (s *Store) Load() (*Config, error) {
+	v, found, unsaved, err := s.viper()
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		if err := os.MkdirAll(filepath.Dir(s.path), ConfigDirPerm); err != nil {
+			return nil, fmt.Errorf("failed to create config directory: %w", err)
+		}
+	}
+
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	config.unsaved = unsaved
+	if err := config.resolveSecrets(); err != nil {
+		return nil, err
+	}
+	if err := config.applyProject(); err != nil {
+		return nil, err
+	}
+	config.applyOffline()
+	return &config, nil
+}
+
+// Save writes cfg to the config file. Only grammr's settings are written, and settings a project
+// config overrides keep their global values unless they were changed. An API key that has to go
+// into the file because there is no keychain, and wasn't there yet, is reported with
+// ErrNoKeychain once the file is written.
+func (s *Store) Save(cfg *Config) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), ConfigDirPerm); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	previous, _, _, err := s.viper()
+	if err != nil {
+		previous = viper.New()
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.Set("config_version", CurrentVersion)
+	var inFile []string
+	for key, value := range cfg.settings() {
+		if override, ok := cfg.overrides[key]; ok && reflect.DeepEqual(value, override.project) {
+			value = override.global
+		}
+		if isSecretKey(key) && cfg.KeyStorage != KeyStorageFile {
+			stored, ok := storeSecret(key, value.(string))
+			if !ok && stored != "" && !strings.HasPrefix(stored, keyringRef) && previous.GetString(key) != stored {
+				inFile = append(inFile, key)
+			}
+			value = stored
+		}
+		v.Set(key, value)
+	}
+	if err := s.write(v); err != nil {
+		return err
+	}
+	if len(inFile) > 0 {
+		slices.Sort(inFile)
+		verb := "is"
+		if len(inFile) > 1 {
+			verb = "are"
+		}
+		return fmt.Errorf("%w, %s %s stored in the config file", ErrNoKeychain, strings.Join(inFile, " and "), verb)
+	}
+	return nil
+}
+
+// Set changes one setting in the config file, keeping the others as they are. The value is
+// converted to the type of the setting, and rejected when the setting doesn't allow it.
+func (s *Store) Set(key, value string) error {
+	if key == "" {
+		return fmt.Errorf("config key cannot be empty")
+	}
+
+	// Sanitize key to prevent injection
+	key = strings.TrimSpace(key)
+	if strings.ContainsAny(key, " \t\n\r") {
+		return fmt.Errorf("config key contains invalid characters")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), ConfigDirPerm); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// Only the file is rewritten, defaults are not added to it
+	v := viper.New()
+	v.SetConfigFile(s.path)
+	v.SetConfigType("yaml")
+	if _, _, err := s.read(v); err != nil {
+		return err
+	}
+
+	// Backward compatibility: map "mode" to "style"
+	if key == "mode" {
+		key = "style"
+	}
+
+	var customStyles []CustomStyle
+	_ = v.UnmarshalKey("custom_styles", &customStyles) // Only needed to accept custom style names
+	parsed, err := parseValue(key, value, customStyles)
+	if err != nil {
+		return err
+	}
+
+	// API keys go to the keychain unless key_storage is file, or there is no keychain
+	if isSecretKey(key) && v.GetString("key_storage") != KeyStorageFile {
+		if parsed == "" {
+			_ = keyring.Delete(keyringService, key) // Ignore error, there may be nothing to delete
+		}
+		parsed, _ = storeSecret(key, parsed.(string))
+	}
+
+	v.Set(key, parsed)
+	if !v.IsSet("config_version") {
+		v.Set("config_version", CurrentVersion)
+	}
+	return s.write(v)
+}
+
+// Get returns one setting from the config file, or its default when it isn't set
+func (s *Store) Get(key string) interface{} {
+	if key == "" {
+		return nil
+	}
+	v, _, _, err := s.viper()
+	if err != nil {
+		return nil
+	}
+	if isSecretKey(key) {
+		if secret, err := resolveSecret(key, v.GetString(key)); err == nil {
+			return secret
+		}
+	}
+	return v.Get(key)
+}
+
+// write writes the settings in v to the config file, readable only by the user
+func (s *Store) write(v *viper.Viper) error {
+	if err := v.WriteConfigAs(s.path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	// Set restrictive permissions on the config file to protect API key
+	if err := os.Chmod(s.path, ConfigFilePerm); err != nil {
+		return fmt.Errorf("failed to set config file permissions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStoresAreIndependent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	first := NewStore(filepath.Join(dir, "first.yaml"))
+	second := NewStore(filepath.Join(dir, "second.yaml"))
+
+	if err := first.Set("model", "gpt-4o-mini"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := first.Set("unrelated_key", "value"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := second.Get("model"); got != "gpt-4o" {
+		t.Errorf("second Get(model) = %v, want the default", got)
+	}
+
+	// Saving writes grammr's settings only, not keys another store has seen
+	cfg, err := first.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := second.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "second.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "unrelated_key") {
+		t.Errorf("saved config absorbed an unrelated key:\n%s", data)
+	}
+	if got := second.Get("model"); got != "gpt-4o-mini" {
+		t.Errorf("second Get(model) after Save() = %v, want gpt-4o-mini", got)
+	}
+}
+
+func TestStoreConcurrentUse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store := NewStore(filepath.Join(dir, string(rune('a'+i))+".yaml"))
+			if err := store.Set("style", "formal"); err != nil {
+				t.Errorf("Set() error = %v", err)
+				return
+			}
+			cfg, err := store.Load()
+			if err != nil {
+				t.Errorf("Load() error = %v", err)
+				return
+			}
+			if cfg.Style != "formal" {
+				t.Errorf("Load() Style = %q, want formal", cfg.Style)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestSetTypedValues(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("custom_styles:\n  - name: Pirate\n    prompt: Talk like a pirate.\n"), ConfigFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(path)
+
+	tests := []struct {
+		key     string
+		value   string
+		want    interface{}
+		wantErr string
+	}{
+		{key: "cache_enabled", value: "false", want: false},
+		{key: "cache_ttl_days", value: "14", want: 14},
+		{key: "provider", value: "Anthropic", want: "anthropic"},
+		{key: "style", value: "technical", want: "technical"},
+		{key: "style", value: "pirate", want: "pirate"},
+		{key: "mode", value: "formal", want: "formal"},
+		{key: "dialect", value: "", want: ""},
+		{key: "model", value: "gpt-4o-mini", want: "gpt-4o-mini"},
+		{key: "unknown_key", value: "42", want: "42"},
+		{key: "cache_enabled", value: "maybe", wantErr: "cache_enabled must be true or false"},
+		{key: "cache_ttl_days", value: "a week", wantErr: "cache_ttl_days must be a whole number"},
+		{key: "confirm_tokens", value: "-1", wantErr: "confirm_tokens must be a whole number"},
+		{key: "provider", value: "gemini", wantErr: "unknown provider: gemini (supported: openai, anthropic)"},
+		{key: "style", value: "shouty", wantErr: "unknown style: shouty (supported: casual, formal, academic, technical, pirate)"},
+		{key: "cache_backend", value: "redis", wantErr: "unknown cache backend: redis (supported: files, bolt)"},
+		{key: "custom_styles", value: "pirate", wantErr: "can't be set from the command line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			err := store.Set(tt.key, tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Set() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			key := tt.key
+			if key == "mode" {
+				key = "style"
+			}
+			if got := store.Get(key); got != tt.want {
+				t.Errorf("Get() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// choices lists the values of settings that accept a fixed set. Style also accepts the names of
+// custom styles.
+var choices = map[string][]string{
+	"provider":              {"openai", "anthropic"},
+	"style":                 {"casual", "formal", "academic", "technical"},
+	"format":                {"auto", "markdown", "html", "plain"},
+	"dialect":               {"", "us", "uk", "au"},
+	"category":              {"all", "spelling", "punctuation", "grammar"},
+	"translation_formality": {"auto", "formal", "informal"},
+	"cache_backend":         {"files", "bolt"},
+	"key_storage":           {KeyStorageKeyring, KeyStorageFile},
+	"theme":                 {"dark", "light", "high-contrast", "colorblind"},
+	"diff_granularity":      {DiffWords, DiffChars},
+	"keybindings":           {KeybindingsDefault, KeybindingsVim},
+	"clipboard":             {"auto", "system", "wl-clipboard", "tmux", "osc52"},
+	"notifications":         {"all", "errors", "off"},
+}
+
+// parseValue converts value, as given to config set, to the type of the setting key and checks
+// that it is allowed. Keys grammr doesn't know are kept as strings.
+func parseValue(key, value string, customStyles []CustomStyle) (interface{}, error) {
+	current, ok := (&Config{}).settings()[key]
+	if !ok {
+		return value, nil
+	}
+	value = strings.TrimSpace(value)
+
+	switch reflect.TypeOf(current).Kind() {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		return parsed, nil
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("%s must be a whole number of 0 or more, got %q", key, value)
+		}
+		return parsed, nil
+	case reflect.String:
+		allowed, ok := choices[key]
+		if !ok {
+			return value, nil
+		}
+		if key == "style" {
+			allowed = append([]string{}, allowed...)
+			for _, style := range customStyles {
+				allowed = append(allowed, NormalizeStyleName(style.Name))
+			}
+		}
+		for _, choice := range allowed {
+			if strings.EqualFold(value, choice) {
+				return choice, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown %s: %s (supported: %s)", strings.ReplaceAll(key, "_", " "), value, strings.Join(nonEmpty(allowed), ", "))
+	default:
+		return nil, fmt.Errorf("%s can't be set from the command line, edit the config file instead", key)
+	}
+}
+
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
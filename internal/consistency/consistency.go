@@ -0,0 +1,275 @@
+package consistency
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Occurrence is a single appearance of a term in the text
+type Occurrence struct {
+	Start         int // Byte offset of the term
+	End           int
+	Text          string
+	SentenceStart bool // Capitalization at the start of a sentence doesn't count as a variant
+}
+
+// Variant is one way a term is written, with every place it appears
+type Variant struct {
+	Text        string
+	Occurrences []Occurrence
+}
+
+// Issue is a term that is written in more than one way
+type Issue struct {
+	Variants  []Variant // Most frequent first
+	Preferred string    // The variant normalization rewrites the others to
+}
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+(?:[-'’][\p{L}\p{N}]+)*`)
+
+// ambiguousWords are common words whose capitalized form is usually a different word
+// (a name, month or country), so casing differences alone aren't reported
+var ambiguousWords = map[string]bool{
+	"may": true, "will": true, "march": true, "august": true, "june": true, "april": true,
+	"mark": true, "bill": true, "bob": true, "rose": true, "china": true, "turkey": true,
+	"polish": true, "us": true, "it": true, "apple": true, "amazon": true, "windows": true,
+}
+
+func (v Variant) midSentence() int {
+	count := 0
+	for _, occ := range v.Occurrences {
+		if !occ.SentenceStart {
+			count++
+		}
+	}
+	return count
+}
+
+type group struct {
+	variants []*Variant
+	byText   map[string]*Variant
+}
+
+func (g *group) add(text string, occ Occurrence) {
+	v, ok := g.byText[text]
+	if !ok {
+		v = &Variant{Text: text}
+		g.byText[text] = v
+		g.variants = append(g.variants, v)
+	}
+	v.Occurrences = append(v.Occurrences, occ)
+}
+
+func (g *group) hasHyphenated() bool {
+	for _, v := range g.variants {
+		if strings.Contains(v.Text, "-") {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reports terms that are spelled, hyphenated or capitalized inconsistently in text
+func Check(text string) []Issue {
+	tokens := tokenize(text)
+
+	groups := make(map[string]*group)
+	var keys []string
+	groupFor := func(key string) *group {
+		g, ok := groups[key]
+		if !ok {
+			g = &group{byText: make(map[string]*Variant)}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		return g
+	}
+
+	// Mid-sentence occurrences define the variants; sentence starts are matched against them afterwards
+	var initial []Occurrence
+	for _, tok := range tokens {
+		if skipToken(tok.Text) {
+			continue
+		}
+		if tok.SentenceStart {
+			initial = append(initial, tok)
+			continue
+		}
+		groupFor(termKey(tok.Text)).add(tok.Text, tok)
+	}
+	for _, tok := range initial {
+		g := groupFor(termKey(tok.Text))
+		text := tok.Text
+		for _, v := range g.variants {
+			if sameExceptFirstLetterCase(v.Text, tok.Text) {
+				text = v.Text
+				break
+			}
+		}
+		g.add(text, tok)
+	}
+
+	// Open compounds ("sign in") are variants of hyphenated ones ("sign-in"). Closed compounds are
+	// left alone since pairs like "into" and "in to" are both correct.
+	for i := 0; i+1 < len(tokens); i++ {
+		a, b := tokens[i], tokens[i+1]
+		if text[a.End:b.Start] != " " {
+			continue
+		}
+		g, ok := groups[termKey(a.Text+b.Text)]
+		if !ok || !g.hasHyphenated() {
+			continue
+		}
+		occ := Occurrence{Start: a.Start, End: b.End, Text: text[a.Start:b.End], SentenceStart: a.SentenceStart}
+		g.add(occ.Text, occ)
+	}
+
+	var issues []Issue
+	for _, key := range keys {
+		g := groups[key]
+		if len(g.variants) < 2 || (ambiguousWords[key] && caseOnly(g.variants)) {
+			continue
+		}
+
+		issue := Issue{}
+		for _, v := range g.variants {
+			issue.Variants = append(issue.Variants, *v)
+		}
+		// Prefer the variant used most mid-sentence, where its capitalization is deliberate
+		sort.SliceStable(issue.Variants, func(i, j int) bool {
+			a, b := issue.Variants[i], issue.Variants[j]
+			if a.midSentence() != b.midSentence() {
+				return a.midSentence() > b.midSentence()
+			}
+			return len(a.Occurrences) > len(b.Occurrences)
+		})
+		issue.Preferred = issue.Variants[0].Text
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// Normalize rewrites every variant in issues to its preferred form
+func Normalize(text string, issues []Issue) string {
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var replacements []replacement
+	for _, issue := range issues {
+		for _, v := range issue.Variants {
+			for _, occ := range v.Occurrences {
+				preferred := issue.Preferred
+				if occ.SentenceStart && startsUpper(occ.Text) {
+					preferred = upperFirst(preferred)
+				}
+				if preferred != occ.Text {
+					replacements = append(replacements, replacement{start: occ.Start, end: occ.End, text: preferred})
+				}
+			}
+		}
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start > replacements[j].start
+	})
+	lastStart := len(text) + 1
+	for _, r := range replacements {
+		if r.end > lastStart {
+			continue // Overlaps a replacement already applied
+		}
+		text = text[:r.start] + r.text + text[r.end:]
+		lastStart = r.start
+	}
+	return text
+}
+
+func tokenize(text string) []Occurrence {
+	var tokens []Occurrence
+	for _, loc := range wordPattern.FindAllStringIndex(text, -1) {
+		// Headings are often in title case, so their capitalization says nothing about the term
+		if isHeading(text, loc[0]) {
+			continue
+		}
+		tokens = append(tokens, Occurrence{
+			Start:         loc[0],
+			End:           loc[1],
+			Text:          text[loc[0]:loc[1]],
+			SentenceStart: isSentenceStart(text, loc[0]),
+		})
+	}
+	return tokens
+}
+
+// isSentenceStart reports whether the word at pos starts a sentence, line or list item
+func isSentenceStart(text string, pos int) bool {
+	for pos > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:pos])
+		switch {
+		case r == '\n' || r == '.' || r == '!' || r == '?' || r == ':':
+			return true
+		case unicode.IsSpace(r) || strings.ContainsRune(`"'“‘([*-#>`, r):
+			pos -= size
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isHeading reports whether pos is on a Markdown heading line
+func isHeading(text string, pos int) bool {
+	lineStart := strings.LastIndexByte(text[:pos], '\n') + 1
+	return strings.HasPrefix(strings.TrimLeft(text[lineStart:pos], " \t"), "#")
+}
+
+// termKey identifies a term regardless of case and hyphens
+func termKey(text string) string {
+	return strings.ReplaceAll(strings.ToLower(text), "-", "")
+}
+
+// skipToken ignores single letters, numbers and all-caps words, which are usually acronyms or emphasis
+func skipToken(text string) bool {
+	if utf8.RuneCountInString(text) < 2 {
+		return true
+	}
+	hasLetter, hasLower := false, false
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			hasLetter = true
+			if unicode.IsLower(r) {
+				hasLower = true
+			}
+		}
+	}
+	return !hasLetter || !hasLower
+}
+
+func caseOnly(variants []*Variant) bool {
+	for _, v := range variants[1:] {
+		if !strings.EqualFold(v.Text, variants[0].Text) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameExceptFirstLetterCase(a, b string) bool {
+	ra, sizeA := utf8.DecodeRuneInString(a)
+	rb, sizeB := utf8.DecodeRuneInString(b)
+	return unicode.ToLower(ra) == unicode.ToLower(rb) && a[sizeA:] == b[sizeB:]
+}
+
+func startsUpper(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsUpper(r)
+}
+
+func upperFirst(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(r)) + text[size:]
+}
@@ -0,0 +1,108 @@
+package consistency
+
+import (
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		preferred []string
+	}{
+		{
+			name:      "consistent text",
+			text:      "Send an email. The email arrived. Email is fast.",
+			preferred: nil,
+		},
+		{
+			name:      "hyphenation",
+			text:      "Send an email and another email, then an e-mail.",
+			preferred: []string{"email"},
+		},
+		{
+			name:      "casing",
+			text:      "I use grammr daily. Grammr is great, and grammr is fast. Try Grammr too.",
+			preferred: []string{"grammr"},
+		},
+		{
+			name:      "open compound",
+			text:      "Use the sign-in page. The sign-in button is blue. Then sign in again.",
+			preferred: []string{"sign-in"},
+		},
+		{
+			name:      "sentence start capitalization is not a variant",
+			text:      "the cat sat. The dog ran. the end.",
+			preferred: nil,
+		},
+		{
+			name:      "ambiguous words and acronyms are ignored",
+			text:      "We may go in May. Tell us about the US and the API.",
+			preferred: nil,
+		},
+		{
+			name:      "closed compounds are left alone",
+			text:      "Log in to the app and look into it.",
+			preferred: nil,
+		},
+		{
+			name:      "title case headings are ignored",
+			text:      "# Getting Started\n\nWe started yesterday.",
+			preferred: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Check(tt.text)
+			if len(issues) != len(tt.preferred) {
+				t.Fatalf("Check() found %d issues (%+v), want %d", len(issues), issues, len(tt.preferred))
+			}
+			for i, issue := range issues {
+				if issue.Preferred != tt.preferred[i] {
+					t.Errorf("issue %d preferred = %q, want %q", i, issue.Preferred, tt.preferred[i])
+				}
+				if len(issue.Variants) < 2 {
+					t.Errorf("issue %d has %d variants, want at least 2", i, len(issue.Variants))
+				}
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "hyphenation",
+			text: "Send an email and another email, then an e-mail. E-mail is old.",
+			want: "Send an email and another email, then an email. Email is old.",
+		},
+		{
+			name: "casing",
+			text: "I use grammr daily, and grammr is fast. Try Grammr too. Grammr rocks.",
+			want: "I use grammr daily, and grammr is fast. Try grammr too. Grammr rocks.",
+		},
+		{
+			name: "open compound",
+			text: "The sign-in page and sign-in button. Then sign in again.",
+			want: "The sign-in page and sign-in button. Then sign-in again.",
+		},
+		{
+			name: "nothing to do",
+			text: "All consistent here.",
+			want: "All consistent here.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text, Check(tt.text)); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package corrector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CategoryAll is the default category, which fixes every kind of mistake and applies the style
+const CategoryAll = "all"
+
+// Categories lists the supported correction categories in display order
+var Categories = []string{CategoryAll, "spelling", "punctuation", "grammar"}
+
+// categoryPrompts replace the style prompt when corrections are restricted to one category
+var categoryPrompts = map[string]string{
+	"spelling": `Fix spelling mistakes only. Do not change grammar, punctuation, word choice or style.
+Only output the corrected text, nothing else.`,
+
+	"punctuation": `Fix punctuation mistakes only. Do not change spelling, grammar, word choice or style.
+Only output the corrected text, nothing else.`,
+
+	"grammar": `Fix grammar mistakes only. Do not change spelling, punctuation, word choice or style unless the grammar fix requires it.
+Only output the corrected text, nothing else.`,
+}
+
+// SetCategory restricts corrections to one category of mistakes ("spelling", "punctuation"
+// or "grammar"). "all" or an empty category fixes everything.
+func (c *Corrector) SetCategory(category string) error {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		category = CategoryAll
+	}
+	if _, ok := categoryPrompts[category]; !ok && category != CategoryAll {
+		return fmt.Errorf("unknown category: %s (supported: %s)", category, strings.Join(Categories, ", "))
+	}
+	c.category = category
+	return nil
+}
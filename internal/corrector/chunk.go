@@ -0,0 +1,239 @@
+package corrector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// MaxChunkLength is the maximum length of a chunk when a long text is split for correction
+const MaxChunkLength = 10000
+
+// defaultChunkConcurrency is the number of chunks corrected at once unless SetChunkConcurrency
+// says otherwise
+const defaultChunkConcurrency = 4
+
+// Chunk is a piece of a longer text. Separator holds the whitespace that followed it in the
+// original text, so the corrected chunks can be reassembled with the same blank-line structure.
+type Chunk struct {
+	Text      string
+	Separator string
+}
+
+// chunkSeparators are tried in order when a piece of text is too long for a single chunk.
+// The first capture group of each pattern is the separator between pieces.
+var chunkSeparators = []*regexp.Regexp{
+	regexp.MustCompile(`(\n[ \t]*\n\s*)`), // Blank lines between paragraphs
+	regexp.MustCompile(`(\n)`),            // Line breaks
+	regexp.MustCompile(`[.!?]+(\s+)`),     // Sentence ends
+	regexp.MustCompile(`(\s+)`),           // Words
+}
+
+// NeedsChunking reports whether text is too long to be corrected in a single request
+func NeedsChunking(text string) bool {
+	return validation.ExceedsMaxLength(text)
+}
+
+// SplitChunks splits text into chunks of at most maxLen bytes, preferring paragraph boundaries
+// and falling back to lines, sentences and words for oversized paragraphs
+func SplitChunks(text string, maxLen int) []Chunk {
+	if maxLen <= 0 || len(text) <= maxLen {
+		return []Chunk{{Text: text}}
+	}
+	return splitChunks(text, maxLen, 0)
+}
+
+// SplitParagraphs splits text at its blank lines, however long its paragraphs are. Joining them
+// back with JoinChunks gives text again.
+func SplitParagraphs(text string) []Chunk {
+	return splitPieces(text, chunkSeparators[0])
+}
+
+// JoinChunks reassembles texts, one per chunk, using the separators of the original chunks
+func JoinChunks(chunks []Chunk, texts []string) string {
+	var result strings.Builder
+	for i, chunk := range chunks {
+		if i < len(texts) {
+			result.WriteString(texts[i])
+		}
+		result.WriteString(chunk.Separator)
+	}
+	return result.String()
+}
+
+// SetChunkConcurrency sets how many chunks of a text too long for one request Correct and
+// StreamCorrect correct at once. Zero or less restores the default.
+func (c *Corrector) SetChunkConcurrency(concurrency int) {
+	c.chunkConcurrency = concurrency
+}
+
+// correctLong corrects a text too long for a single request by splitting it on paragraph
+// boundaries and correcting the chunks, chunkConcurrency at a time
+func (c *Corrector) correctLong(ctx context.Context, text string) (string, error) {
+	concurrency := c.chunkConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultChunkConcurrency
+	}
+	chunks := SplitChunks(text, MaxChunkLength)
+	corrected, err := c.CorrectChunks(ctx, chunks, concurrency, 0, nil)
+	if err != nil {
+		return "", err
+	}
+	return JoinChunks(chunks, corrected), nil
+}
+
+// CorrectChunks corrects chunks, up to concurrency of them at a time, and returns their
+// corrections in the order of the chunks. The requests share the rate limiter of the corrector,
+// and each gets timeout unless it is 0. onDone is called with the number of chunks done after
+// each one. The first failure stops the other requests.
+func (c *Corrector) CorrectChunks(ctx context.Context, chunks []Chunk, concurrency int, timeout time.Duration, onDone func(done int)) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	corrected := make([]string, len(chunks))
+	slots := make(chan struct{}, max(concurrency, 1))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+		firstErr error
+	)
+	for i, chunk := range chunks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			text, err := c.correctChunk(ctx, chunk.Text, timeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to correct chunk %d/%d: %w", i+1, len(chunks), err)
+					cancel()
+				}
+				return
+			}
+			corrected[i] = text
+			done++
+			if onDone != nil && firstErr == nil {
+				onDone(done)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return corrected, nil
+}
+
+// correctChunk corrects the text of one chunk. Blank chunks are kept as they are, and trailing
+// whitespace is left to the separator.
+func (c *Corrector) correctChunk(ctx context.Context, text string, timeout time.Duration) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var result strings.Builder
+	if err := c.StreamCorrect(ctx, text, func(chunk string) {
+		result.WriteString(chunk)
+	}); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(result.String(), " \t\n\r"), nil
+}
+
+func splitChunks(text string, maxLen, level int) []Chunk {
+	if len(text) <= maxLen {
+		return []Chunk{{Text: text}}
+	}
+	if level == len(chunkSeparators) {
+		return splitHard(text, maxLen)
+	}
+
+	var chunks []Chunk
+	var current *Chunk
+	flush := func() {
+		if current != nil {
+			chunks = append(chunks, *current)
+			current = nil
+		}
+	}
+
+	for _, piece := range splitPieces(text, chunkSeparators[level]) {
+		if len(piece.Text) > maxLen {
+			flush()
+			sub := splitChunks(piece.Text, maxLen, level+1)
+			sub[len(sub)-1].Separator = piece.Separator
+			chunks = append(chunks, sub...)
+			continue
+		}
+
+		// Merge pieces greedily while they fit in one chunk
+		if current != nil && len(current.Text)+len(current.Separator)+len(piece.Text) <= maxLen {
+			current.Text += current.Separator + piece.Text
+			current.Separator = piece.Separator
+			continue
+		}
+
+		flush()
+		p := piece
+		current = &p
+	}
+	flush()
+
+	return chunks
+}
+
+// splitPieces splits text at each match of re, keeping the separator with the preceding piece
+func splitPieces(text string, re *regexp.Regexp) []Chunk {
+	var pieces []Chunk
+	start := 0
+	for _, match := range re.FindAllStringSubmatchIndex(text, -1) {
+		pieces = append(pieces, Chunk{Text: text[start:match[2]], Separator: text[match[2]:match[3]]})
+		start = match[3]
+	}
+	if start < len(text) || len(pieces) == 0 {
+		pieces = append(pieces, Chunk{Text: text[start:]})
+	}
+	return pieces
+}
+
+// splitHard splits text into maxLen-sized chunks without breaking UTF-8 characters
+func splitHard(text string, maxLen int) []Chunk {
+	var chunks []Chunk
+	for len(text) > maxLen {
+		cut := maxLen
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			cut = maxLen
+		}
+		chunks = append(chunks, Chunk{Text: text[:cut]})
+		text = text[cut:]
+	}
+	return append(chunks, Chunk{Text: text})
+}
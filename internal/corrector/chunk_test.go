@@ -0,0 +1,254 @@
+package corrector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   []Chunk
+	}{
+		{
+			name:   "short text is a single chunk",
+			text:   "Hello world.",
+			maxLen: 100,
+			want:   []Chunk{{Text: "Hello world."}},
+		},
+		{
+			name:   "paragraphs are grouped",
+			text:   "aaaa\n\nbbbb\n\n\ncccc",
+			maxLen: 10,
+			want: []Chunk{
+				{Text: "aaaa\n\nbbbb", Separator: "\n\n\n"},
+				{Text: "cccc"},
+			},
+		},
+		{
+			name:   "long paragraph is split on sentences",
+			text:   "One two. Three four. Five.\n\nSix.",
+			maxLen: 12,
+			want: []Chunk{
+				{Text: "One two.", Separator: " "},
+				{Text: "Three four.", Separator: " "},
+				{Text: "Five.", Separator: "\n\n"},
+				{Text: "Six."},
+			},
+		},
+		{
+			name:   "unbroken text is split without breaking characters",
+			text:   "ééééé",
+			maxLen: 3,
+			want:   []Chunk{{Text: "é"}, {Text: "é"}, {Text: "é"}, {Text: "é"}, {Text: "é"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitChunks(tt.text, tt.maxLen)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitChunks() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("chunk %d = %q, want %q", i, got[i], tt.want[i])
+				}
+				if len(got[i].Text) > tt.maxLen {
+					t.Fatalf("chunk %d is %d bytes, want at most %d", i, len(got[i].Text), tt.maxLen)
+				}
+			}
+		})
+	}
+}
+
+func TestJoinChunksPreservesStructure(t *testing.T) {
+	var paragraphs []string
+	for i := 0; i < 50; i++ {
+		paragraphs = append(paragraphs, strings.Repeat("word ", 40)+"end.")
+	}
+	text := strings.Join(paragraphs, "\n\n")
+
+	chunks := SplitChunks(text, 1000)
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	if got := JoinChunks(chunks, texts); got != text {
+		t.Fatal("JoinChunks() should reassemble the original text")
+	}
+
+	for i := range texts {
+		texts[i] = strings.ToUpper(texts[i])
+	}
+	if got := JoinChunks(chunks, texts); got != strings.ToUpper(text) {
+		t.Fatal("JoinChunks() should keep the blank lines between corrected chunks")
+	}
+}
+
+func TestSplitParagraphs(t *testing.T) {
+	text := "First line.\nSecond line.\n\nSecond paragraph.\n \n\nThird."
+	want := []Chunk{
+		{Text: "First line.\nSecond line.", Separator: "\n\n"},
+		{Text: "Second paragraph.", Separator: "\n \n\n"},
+		{Text: "Third."},
+	}
+	got := SplitParagraphs(text)
+	if len(got) != len(want) {
+		t.Fatalf("SplitParagraphs() = %q, want %q", got, want)
+	}
+	texts := make([]string, len(got))
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("paragraph %d = %q, want %q", i, got[i], want[i])
+		}
+		texts[i] = got[i].Text
+	}
+	if joined := JoinChunks(got, texts); joined != text {
+		t.Errorf("JoinChunks() = %q, want the text back", joined)
+	}
+}
+
+// slowProvider echoes the prompt after a pause, counting the requests running at once. It fails
+// requests whose prompt contains fail.
+type slowProvider struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+	fail    string
+}
+
+func (p *slowProvider) StreamChat(ctx context.Context, model string, messages []provider.Message, onChunk func(string)) error {
+	response, err := p.Chat(ctx, model, messages)
+	if err != nil {
+		return err
+	}
+	onChunk(response)
+	return nil
+}
+
+func (p *slowProvider) Chat(ctx context.Context, model string, messages []provider.Message) (string, error) {
+	p.mu.Lock()
+	p.running++
+	p.peak = max(p.peak, p.running)
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+	}()
+
+	prompt := messages[len(messages)-1].Content
+	if p.fail != "" && strings.Contains(prompt, p.fail) {
+		return "", errors.New("provider failed")
+	}
+	select {
+	case <-time.After(20 * time.Millisecond):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return "Mock response for: " + prompt, nil
+}
+
+func TestCorrectChunks(t *testing.T) {
+	chunks := []Chunk{
+		{Text: "first", Separator: "\n\n"},
+		{Text: "second", Separator: "\n\n"},
+		{Text: "  ", Separator: "\n\n"},
+		{Text: "third", Separator: "\n\n"},
+		{Text: "fourth", Separator: "\n\n"},
+		{Text: "fifth"},
+	}
+
+	prov := &slowProvider{}
+	cor, err := New(prov, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress []int
+	got, err := cor.CorrectChunks(context.Background(), chunks, 2, time.Second, func(done int) {
+		progress = append(progress, done)
+	})
+	if err != nil {
+		t.Fatalf("CorrectChunks() error = %v", err)
+	}
+	for i, chunk := range chunks {
+		if !strings.Contains(got[i], chunk.Text) {
+			t.Errorf("correction %d = %q, want the correction of %q", i, got[i], chunk.Text)
+		}
+	}
+	if got[2] != "  " {
+		t.Errorf("blank chunk corrected to %q, want it kept", got[2])
+	}
+	if prov.peak != 2 {
+		t.Errorf("%d requests ran at once, want 2", prov.peak)
+	}
+	if len(progress) != len(chunks) || progress[len(progress)-1] != len(chunks) {
+		t.Errorf("progress = %v, want one call a chunk up to %d", progress, len(chunks))
+	}
+
+	prov = &slowProvider{fail: "third"}
+	if cor, err = New(prov, "gpt-4o", "casual", "english"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = cor.CorrectChunks(context.Background(), chunks, 3, 0, nil)
+	if err == nil || !strings.Contains(err.Error(), "chunk 4/6") {
+		t.Errorf("CorrectChunks() error = %v, want the failed chunk", err)
+	}
+}
+
+func TestCorrectLongText(t *testing.T) {
+	var paragraphs []string
+	for i := 0; len(strings.Join(paragraphs, "\n\n")) <= validation.MaxInputLength; i++ {
+		paragraphs = append(paragraphs, fmt.Sprintf("Paragraph %d has a error.", i))
+	}
+	text := strings.Join(paragraphs, "\n\n")
+
+	prov := &slowProvider{}
+	cor, err := New(prov, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The provider echoes the prompt, so each chunk comes back prefixed
+	if err := cor.SetPromptTemplate("{{.Text}}"); err != nil {
+		t.Fatal(err)
+	}
+	cor.SetChunkConcurrency(3)
+
+	got, err := cor.Correct(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Correct() error = %v, want the text corrected in chunks", err)
+	}
+	chunks := SplitChunks(text, MaxChunkLength)
+	if count := strings.Count(got, "Mock response for: "); count != len(chunks) {
+		t.Errorf("Correct() made %d requests, want one for each of the %d chunks", count, len(chunks))
+	}
+	if !strings.HasPrefix(got, "Mock response for: Paragraph 0 has a error.\n\n") || !strings.HasSuffix(got, paragraphs[len(paragraphs)-1]) {
+		t.Errorf("Correct() = %q..., want the chunks joined in order", got[:60])
+	}
+	if prov.peak != 3 {
+		t.Errorf("%d requests ran at once, want 3", prov.peak)
+	}
+
+	var streamed strings.Builder
+	if err := cor.StreamCorrect(context.Background(), text, func(chunk string) { streamed.WriteString(chunk) }); err != nil {
+		t.Fatalf("StreamCorrect() error = %v", err)
+	}
+	if streamed.String() != got {
+		t.Error("StreamCorrect() should give the same correction as Correct()")
+	}
+}
@@ -0,0 +1,178 @@
+package corrector
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ChangeCategories lists the kinds of changes ClassifyChange tells apart, in display order
+var ChangeCategories = []string{"spelling", "punctuation", "grammar", "rewording"}
+
+// grammarWords are short function words that grammar fixes typically add, remove or swap
+var grammarWords = map[string]bool{
+	"a": true, "an": true, "the": true,
+	"am": true, "is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "being": true,
+	"has": true, "have": true, "had": true, "do": true, "does": true, "did": true,
+	"will": true, "would": true, "shall": true, "should": true, "can": true, "could": true, "may": true, "might": true, "must": true,
+	"i": true, "me": true, "my": true, "you": true, "your": true, "he": true, "him": true, "his": true, "she": true, "her": true,
+	"it": true, "its": true, "we": true, "us": true, "our": true, "they": true, "them": true, "their": true,
+	"this": true, "that": true, "these": true, "those": true, "who": true, "whom": true, "which": true,
+	"to": true, "of": true, "in": true, "on": true, "at": true, "for": true, "with": true, "by": true, "from": true,
+	"into": true, "onto": true, "about": true, "as": true, "than": true, "then": true,
+	"and": true, "or": true, "but": true, "nor": true, "not": true,
+}
+
+// inflectionSuffixes are endings that change the form of a word rather than the word
+var inflectionSuffixes = map[string]bool{
+	"": true, "s": true, "es": true, "d": true, "ed": true, "ing": true, "er": true, "est": true,
+	"y": true, "ies": true, "ied": true, "'s": true, "’s": true,
+}
+
+// ClassifyChange guesses whether replacing before with after fixes spelling, punctuation or
+// grammar, or rewords the text. It looks at the words only, so it is a heuristic: before and
+// after should be whole words, not parts of them.
+func ClassifyChange(before, after string) string {
+	if stripPunctuation(before) == stripPunctuation(after) {
+		return "punctuation"
+	}
+	if strings.EqualFold(before, after) {
+		// Capitalization
+		return "spelling"
+	}
+
+	removed, added := wordDifference(words(before), words(after))
+	if len(removed) == 0 && len(added) == 0 {
+		// Only the case of some words changed
+		return "spelling"
+	}
+	if len(removed) == len(added) {
+		spelling := true
+		for i := range removed {
+			if !isMisspelling(removed[i], added[i]) {
+				spelling = false
+				break
+			}
+		}
+		if spelling {
+			return "spelling"
+		}
+	}
+
+	// Grammar fixes swap, add or drop function words and change the form of words
+	grammar := true
+	for _, word := range removed {
+		if !grammarWords[word] && !hasInflection(word, added) {
+			grammar = false
+		}
+	}
+	for _, word := range added {
+		if !grammarWords[word] && !hasInflection(word, removed) {
+			grammar = false
+		}
+	}
+	if grammar {
+		return "grammar"
+	}
+	return "rewording"
+}
+
+// stripPunctuation keeps only the letters and digits of text
+func stripPunctuation(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, text)
+}
+
+// words splits text into lowercase words, dropping punctuation
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+}
+
+// wordDifference returns the words only before has and the words only after has, in order
+func wordDifference(before, after []string) (removed, added []string) {
+	counts := make(map[string]int)
+	for _, word := range after {
+		counts[word]++
+	}
+	for _, word := range before {
+		if counts[word] > 0 {
+			counts[word]--
+		} else {
+			removed = append(removed, word)
+		}
+	}
+	for _, word := range after {
+		if counts[word] > 0 {
+			counts[word]--
+			added = append(added, word)
+		}
+	}
+	return removed, added
+}
+
+// isMisspelling reports whether after looks like the same word as before, spelled correctly
+func isMisspelling(before, after string) bool {
+	if grammarWords[before] && grammarWords[after] {
+		return false
+	}
+	if isInflection(before, after) {
+		return false
+	}
+	longest := max(len([]rune(before)), len([]rune(after)))
+	return editDistance(before, after) <= max(1, longest/3)
+}
+
+// hasInflection reports whether any of candidates is another form of word
+func hasInflection(word string, candidates []string) bool {
+	for _, candidate := range candidates {
+		if isInflection(word, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInflection reports whether a and b are forms of the same word, like "apple" and "apples"
+func isInflection(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	prefix := 0
+	for prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	if prefix < 3 || a == b {
+		return false
+	}
+	return inflectionSuffixes[string(ra[prefix:])] && inflectionSuffixes[string(rb[prefix:])]
+}
+
+// editDistance is the number of single letter edits that turn a into b, counting a swap of two
+// neighbouring letters as one edit like a typo
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
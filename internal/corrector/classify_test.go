@@ -0,0 +1,52 @@
+package corrector
+
+import "testing"
+
+func TestClassifyChange(t *testing.T) {
+	tests := []struct {
+		before string
+		after  string
+		want   string
+	}{
+		{before: "Hello world", after: "Hello, world", want: "punctuation"},
+		{before: "its", after: "it's", want: "punctuation"},
+		{before: "done", after: "done.", want: "punctuation"},
+		{before: "i", after: "I", want: "spelling"},
+		{before: "recieve", after: "receive", want: "spelling"},
+		{before: "teh", after: "the", want: "spelling"},
+		{before: "I has a apple", after: "I have an apple", want: "grammar"},
+		{before: "are", after: "am", want: "grammar"},
+		{before: "two apple", after: "two apples", want: "grammar"},
+		{before: "I went store", after: "I went to the store", want: "grammar"},
+		{before: "walk", after: "walked", want: "grammar"},
+		{before: "big", after: "large", want: "rewording"},
+		{before: "Hello world", after: "Hello there", want: "rewording"},
+		{before: "in order to improve", after: "to improve", want: "rewording"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.before+" → "+tt.after, func(t *testing.T) {
+			if got := ClassifyChange(tt.before, tt.after); got != tt.want {
+				t.Errorf("ClassifyChange(%q, %q) = %q, want %q", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"naïve", "naive", 1},
+		{"teh", "the", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -2,8 +2,15 @@ package corrector
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
 
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/maximbilan/grammr/internal/postprocess"
 	"github.com/maximbilan/grammr/internal/provider"
 	"github.com/maximbilan/grammr/internal/ratelimit"
 	"github.com/maximbilan/grammr/internal/validation"
@@ -11,11 +18,28 @@ import (
 
 
 type Corrector struct {
-	provider    provider.Provider
-	model       string
-	style       string
-	language    string
-	rateLimiter *ratelimit.RateLimiter
+	provider         provider.Provider
+	model            string
+	style            string
+	language         string
+	customStyles     map[string]string  // User-defined style name -> prompt instructions
+	promptTmpl       *template.Template // Optional user template replacing the built-in prompt
+	format           string             // Input format: auto, markdown or plain
+	dialect          string             // English variant to normalize to, empty for none
+	category         string             // Kind of mistakes to fix, see Categories
+	glossary         *glossary.Glossary // Terminology to enforce, may be nil
+	rateLimiter      *ratelimit.RateLimiter
+	chunkConcurrency int // Chunks of a long text corrected at once, see SetChunkConcurrency
+}
+
+// PromptData holds the values available to a custom prompt template
+type PromptData struct {
+	Text     string
+	Style    string
+	Language string
+	Dialect  string // "us", "uk", "au" or empty
+	Category string // "all", "spelling", "punctuation" or "grammar"
+	Glossary string // Glossary instructions, empty if there is no glossary
 }
 
 // New creates a new Corrector with a provider
@@ -25,6 +49,12 @@ func New(prov provider.Provider, model, style, language string) (*Corrector, err
 
 // NewWithRateLimit creates a new Corrector with an optional rate limiter
 func NewWithRateLimit(prov provider.Provider, model, style, language string, rateLimiter *ratelimit.RateLimiter) (*Corrector, error) {
+	return NewWithCustomStyles(prov, model, style, language, nil, rateLimiter)
+}
+
+// NewWithCustomStyles creates a new Corrector that also accepts user-defined styles,
+// given as a map of style name to prompt instructions
+func NewWithCustomStyles(prov provider.Provider, model, style, language string, customStyles map[string]string, rateLimiter *ratelimit.RateLimiter) (*Corrector, error) {
 	if prov == nil {
 		return nil, fmt.Errorf("provider is required")
 	}
@@ -45,21 +75,88 @@ func NewWithRateLimit(prov provider.Provider, model, style, language string, rat
 		"academic":  true,
 		"technical": true,
 	}
+	for name := range customStyles {
+		validStyles[name] = true
+	}
 	if style != "" && !validStyles[style] {
 		// Default to casual for invalid styles
 		style = "casual"
 	}
 
 	return &Corrector{
-		provider:    prov,
-		model:       model,
-		style:       style,
-		language:    language,
-		rateLimiter: rateLimiter,
+		provider:     prov,
+		model:        model,
+		style:        style,
+		language:     language,
+		customStyles: customStyles,
+		format:       FormatAuto,
+		category:     CategoryAll,
+		rateLimiter:  rateLimiter,
 	}, nil
 }
 
+// SetPromptTemplate overrides the correction prompt with a text/template that can
+// reference {{.Text}}, {{.Style}} and {{.Language}}. An empty template restores the default prompt.
+func (c *Corrector) SetPromptTemplate(text string) error {
+	if strings.TrimSpace(text) == "" {
+		c.promptTmpl = nil
+		return nil
+	}
+
+	tmpl, err := template.New("correction").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	// Execute once with sample data so unknown fields are reported up front
+	if err := tmpl.Execute(io.Discard, PromptData{Text: "text", Style: "casual", Language: "english", Category: CategoryAll}); err != nil {
+		return fmt.Errorf("failed to execute prompt template: %w", err)
+	}
+	c.promptTmpl = tmpl
+	return nil
+}
+
+// WithLanguage returns a copy of the corrector for text written in language. The original is left
+// untouched, so requests already in flight keep their language.
+func (c *Corrector) WithLanguage(language string) *Corrector {
+	if language == "" {
+		language = "english"
+	}
+	copied := *c
+	copied.language = language
+	return &copied
+}
+
+// Language returns the language the corrector expects text to be written in
+func (c *Corrector) Language() string {
+	return c.language
+}
+
+// SetGlossary sets the terminology that corrections and rewrites must follow. A nil or empty
+// glossary removes it.
+func (c *Corrector) SetGlossary(g *glossary.Glossary) {
+	c.glossary = g
+}
+
+func (c *Corrector) glossaryInstruction() string {
+	if c.glossary.Empty() {
+		return ""
+	}
+	return "\n" + c.glossary.Instruction()
+}
+
 func (c *Corrector) buildPrompt(text string) string {
+	if c.promptTmpl != nil {
+		var prompt strings.Builder
+		style := c.style
+		if style == "" {
+			style = "casual"
+		}
+		err := c.promptTmpl.Execute(&prompt, PromptData{Text: text, Style: style, Language: c.language, Dialect: c.dialect, Category: c.category, Glossary: c.glossary.Instruction()})
+		if err == nil {
+			return prompt.String()
+		}
+		// Fall back to the built-in prompt if the template fails at runtime
+	}
 	prompts := map[string]string{
 		"casual": `Fix grammar, spelling, and punctuation. Keep it casual and natural.
 Only output the corrected text, nothing else.`,
@@ -73,11 +170,22 @@ Only output the corrected text, nothing else.`,
 		"technical": `Fix grammar, spelling, and punctuation. Maintain technical accuracy.
 Only output the corrected text, nothing else.`,
 	}
+	for name, instructions := range c.customStyles {
+		if _, ok := prompts[name]; ok {
+			// A custom style can't replace a built-in one
+			continue
+		}
+		prompts[name] = fmt.Sprintf("Fix grammar, spelling, and punctuation. %s\nOnly output the corrected text, nothing else.", strings.TrimSpace(instructions))
+	}
 
 	prompt, ok := prompts[c.style]
 	if !ok {
 		prompt = prompts["casual"]
 	}
+	// A category restriction overrides the style, which would otherwise change the wording
+	if categoryPrompt, ok := categoryPrompts[c.category]; ok {
+		prompt = categoryPrompt
+	}
 
 	// Add language instruction if not English
 	languageInstruction := ""
@@ -85,10 +193,35 @@ Only output the corrected text, nothing else.`,
 		languageInstruction = fmt.Sprintf(" The text is in %s. Correct it in %s.\n", c.language, c.language)
 	}
 
-	return fmt.Sprintf("%s%s\nText to correct:\n%s", prompt, languageInstruction, text)
+	return fmt.Sprintf("%s%s%s%s\nText to correct:\n%s", prompt, languageInstruction, c.dialectInstruction(), c.glossaryInstruction(), text)
+}
+
+// promptRevision is bumped whenever the built-in prompts change, so corrections cached with the
+// old wording aren't reused
+const promptRevision = 1
+
+// PromptVersion identifies the prompt corrections are made with: the built-in wording and the
+// settings that change it, such as the dialect, category, glossary or a custom template
+func (c *Corrector) PromptVersion() string {
+	sum := sha256.Sum256([]byte(c.buildPrompt("")))
+	return fmt.Sprintf("%d-%x", promptRevision, sum[:8])
+}
+
+// Style returns the correction style
+func (c *Corrector) Style() string {
+	return c.style
 }
 
 func (c *Corrector) StreamCorrect(ctx context.Context, text string, onChunk func(string)) error {
+	if NeedsChunking(text) && onChunk != nil {
+		// The chunks finish in any order, so the correction comes in one piece
+		corrected, err := c.correctLong(ctx, text)
+		if err != nil {
+			return err
+		}
+		onChunk(corrected)
+		return nil
+	}
 	if err := validation.ValidateTextInput(text, onChunk); err != nil {
 		return err
 	}
@@ -100,24 +233,180 @@ func (c *Corrector) StreamCorrect(ctx context.Context, text string, onChunk func
 		}
 	}
 
+	if masked, protected := mask(c.formatOf(text), text); len(protected) > 0 {
+		// Placeholders can be split across chunks, so restore the complete response
+		corrected, err := c.provider.Chat(ctx, c.model, c.correctionMessages(masked, true))
+		if err != nil {
+			return err
+		}
+		restored, err := restoreProtected(postprocess.Clean(corrected, masked), protected)
+		if err != nil {
+			return err
+		}
+		onChunk(restored)
+		return nil
+	}
+
+	return c.streamCleaned(ctx, c.correctionMessages(text, false), text, onChunk)
+}
+
+// correctionMessages builds the messages for a correction request. When masked is true the text
+// contains placeholders for protected Markdown or HTML content, which the model is told to keep.
+func (c *Corrector) correctionMessages(text string, masked bool) []provider.Message {
+	var messages []provider.Message
+	if masked {
+		messages = append(messages, provider.Message{
+			Role:    provider.RoleSystem,
+			Content: placeholderInstruction,
+		})
+	}
+	return append(messages, provider.Message{
+		Role:    provider.RoleUser,
+		Content: c.buildPrompt(text),
+	})
+}
+
+// Correct performs a non-streaming correction (fallback)
+func (c *Corrector) Correct(ctx context.Context, text string) (string, error) {
+	if NeedsChunking(text) {
+		return c.correctLong(ctx, text)
+	}
+	if err := validation.ValidateText(text); err != nil {
+		return "", err
+	}
+
+	// Apply rate limiting if enabled
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return "", fmt.Errorf("rate limit error: %w", err)
+		}
+	}
+
+	if masked, protected := mask(c.formatOf(text), text); len(protected) > 0 {
+		corrected, err := c.provider.Chat(ctx, c.model, c.correctionMessages(masked, true))
+		if err != nil {
+			return "", err
+		}
+		return restoreProtected(postprocess.Clean(corrected, masked), protected)
+	}
+
+	return c.chatCleaned(ctx, c.correctionMessages(text, false), text)
+}
+
+// streamCleaned streams a response with model preambles, wrapping quotes and sign-offs removed
+func (c *Corrector) streamCleaned(ctx context.Context, messages []provider.Message, original string, onChunk func(string)) error {
+	cleaner := postprocess.NewStream(original, onChunk)
+	if err := c.provider.StreamChat(ctx, c.model, messages, cleaner.Write); err != nil {
+		return err
+	}
+	cleaner.Close()
+	return nil
+}
+
+// chatCleaned returns a response with model preambles, wrapping quotes and sign-offs removed
+func (c *Corrector) chatCleaned(ctx context.Context, messages []provider.Message, original string) (string, error) {
+	resp, err := c.provider.Chat(ctx, c.model, messages)
+	if err != nil {
+		return "", err
+	}
+	return postprocess.Clean(resp, original), nil
+}
+
+// MaxAlternatives is the maximum number of alternative phrasings returned by SuggestAlternatives
+const MaxAlternatives = 3
+
+func (c *Corrector) buildAlternativesPrompt(sentence, span string) string {
+	return fmt.Sprintf(`Suggest %d alternative phrasings for the part "%s" in the sentence below. Keep the meaning and make each alternative fit the sentence.
+Output one alternative per line, with no numbering, quotes or explanations.
+
+Sentence:
+%s`, MaxAlternatives, span, sentence)
+}
+
+// SuggestAlternatives asks the provider for alternative phrasings of span within sentence
+func (c *Corrector) SuggestAlternatives(ctx context.Context, sentence, span string) ([]string, error) {
+	if strings.TrimSpace(span) == "" {
+		return nil, fmt.Errorf("span cannot be empty")
+	}
+	if sentence == "" {
+		sentence = span
+	}
+	if err := validation.ValidateText(sentence); err != nil {
+		return nil, err
+	}
+
+	// Apply rate limiting if enabled
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit error: %w", err)
+		}
+	}
+
 	messages := []provider.Message{
 		{
 			Role:    provider.RoleUser,
-			Content: c.buildPrompt(text),
+			Content: c.buildAlternativesPrompt(sentence, span),
 		},
 	}
+	response, err := c.provider.Chat(ctx, c.model, messages)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.provider.StreamChat(ctx, c.model, messages, onChunk)
+	alternatives := parseAlternatives(response, span)
+	if len(alternatives) == 0 {
+		return nil, fmt.Errorf("no alternatives suggested")
+	}
+	return alternatives, nil
 }
 
-// Correct performs a non-streaming correction (fallback)
-func (c *Corrector) Correct(ctx context.Context, text string) (string, error) {
-	if text == "" {
-		return "", fmt.Errorf("text cannot be empty")
+// listMarkerPattern matches bullet or numbered list prefixes such as "- ", "1. " or "2) "
+var listMarkerPattern = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+
+// parseAlternatives extracts one alternative per line, dropping list markers,
+// surrounding quotes, duplicates and the original span
+func parseAlternatives(response, span string) []string {
+	seen := map[string]bool{strings.TrimSpace(span): true}
+	alternatives := make([]string, 0, MaxAlternatives)
+	for _, line := range strings.Split(response, "\n") {
+		line = listMarkerPattern.ReplaceAllString(strings.TrimSpace(line), "")
+		line = strings.Trim(line, "\"'`“”")
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		alternatives = append(alternatives, line)
+		if len(alternatives) == MaxAlternatives {
+			break
+		}
 	}
+	return alternatives
+}
 
-	if len(text) > validation.MaxInputLength {
-		return "", fmt.Errorf("text exceeds maximum length of %d characters (got %d)", validation.MaxInputLength, len(text))
+func (c *Corrector) buildExplanationPrompt(sentence, before, after string) string {
+	change := fmt.Sprintf(`"%s" was changed to "%s"`, before, after)
+	switch {
+	case after == "":
+		change = fmt.Sprintf(`"%s" was removed`, before)
+	case before == "":
+		change = fmt.Sprintf(`"%s" was added`, after)
+	}
+	return fmt.Sprintf(`In the sentence below, %s. Explain the grammatical reason for this change in one short sentence that a language learner can follow.
+Output only the explanation.
+
+Sentence:
+%s`, change, sentence)
+}
+
+// ExplainChange asks the provider for the grammatical reason before was changed to after in
+// sentence. Either side may be empty for text that was added or removed.
+func (c *Corrector) ExplainChange(ctx context.Context, sentence, before, after string) (string, error) {
+	if strings.TrimSpace(before) == "" && strings.TrimSpace(after) == "" {
+		return "", fmt.Errorf("change cannot be empty")
+	}
+	if err := validation.ValidateText(sentence); err != nil {
+		return "", err
 	}
 
 	// Apply rate limiting if enabled
@@ -130,9 +419,162 @@ func (c *Corrector) Correct(ctx context.Context, text string) (string, error) {
 	messages := []provider.Message{
 		{
 			Role:    provider.RoleUser,
-			Content: c.buildPrompt(text),
+			Content: c.buildExplanationPrompt(sentence, before, after),
+		},
+	}
+	response, err := c.provider.Chat(ctx, c.model, messages)
+	if err != nil {
+		return "", err
+	}
+
+	explanation := strings.Join(strings.Fields(response), " ")
+	if explanation == "" {
+		return "", fmt.Errorf("no explanation given")
+	}
+	return explanation, nil
+}
+
+// Tones lists the supported rewrite tones in display order
+var Tones = []string{"friendly", "assertive", "apologetic", "concise"}
+
+var toneInstructions = map[string]string{
+	"friendly":   "Make it warm, approachable and positive.",
+	"assertive":  "Make it confident and direct, without hedging or being rude.",
+	"apologetic": "Make it sincerely apologetic and understanding, taking responsibility where appropriate.",
+	"concise":    "Make it as short and clear as possible, removing filler and redundancy.",
+}
+
+// Rewrite actions offered as quick follow-ups to a correction
+const (
+	ActionShorten  = "shorten"
+	ActionExpand   = "expand"
+	ActionSimplify = "simplify"
+)
+
+// Actions lists the supported rewrite actions in display order
+var Actions = []string{ActionShorten, ActionExpand, ActionSimplify}
+
+// DefaultShortenPercent is the target length used by ActionShorten when none is given
+const DefaultShortenPercent = 50
+
+func (c *Corrector) buildRewritePrompt(text, tone string) string {
+	return c.buildRewriteInstructionPrompt(text, fmt.Sprintf("Rewrite the text in a %s tone. %s", tone, toneInstructions[tone]))
+}
+
+func (c *Corrector) buildRewriteInstructionPrompt(text, instruction string) string {
+	// Add language instruction if not English
+	languageInstruction := ""
+	if c.language != "" && c.language != "english" {
+		languageInstruction = fmt.Sprintf(" The text is in %s. Keep it in %s.\n", c.language, c.language)
+	}
+	return fmt.Sprintf(`%s
+Keep the original meaning and fix any grammar, spelling, and punctuation mistakes.
+Only output the rewritten text, nothing else.%s%s%s
+Text to rewrite:
+%s`, instruction, languageInstruction, c.dialectInstruction(), c.glossaryInstruction(), text)
+}
+
+// actionInstruction returns the prompt instruction for a rewrite action. percent is only
+// used by ActionShorten, where zero means DefaultShortenPercent.
+func actionInstruction(action string, percent int) (string, error) {
+	switch action {
+	case ActionShorten:
+		if percent == 0 {
+			percent = DefaultShortenPercent
+		}
+		if percent < 1 || percent > 99 {
+			return "", fmt.Errorf("shorten percent must be between 1 and 99 (got %d)", percent)
+		}
+		return fmt.Sprintf("Shorten the text to about %d%% of its current length, keeping the key points.", percent), nil
+	case ActionExpand:
+		return "Expand the text with more detail and explanation, keeping the same tone.", nil
+	case ActionSimplify:
+		return "Simplify the text into plain language: short sentences, common words and no jargon.", nil
+	default:
+		return "", fmt.Errorf("unknown action: %s (supported: %s)", action, strings.Join(Actions, ", "))
+	}
+}
+
+func validateTone(tone string) error {
+	if _, ok := toneInstructions[tone]; !ok {
+		return fmt.Errorf("unknown tone: %s (supported: %s)", tone, strings.Join(Tones, ", "))
+	}
+	return nil
+}
+
+// StreamRewrite rewrites text in the given tone, streaming the result
+func (c *Corrector) StreamRewrite(ctx context.Context, text, tone string, onChunk func(string)) error {
+	if err := validateTone(tone); err != nil {
+		return err
+	}
+	return c.streamRewritePrompt(ctx, text, c.buildRewritePrompt(text, tone), onChunk)
+}
+
+// Rewrite performs a non-streaming tone rewrite
+func (c *Corrector) Rewrite(ctx context.Context, text, tone string) (string, error) {
+	if err := validateTone(tone); err != nil {
+		return "", err
+	}
+	return c.chatRewritePrompt(ctx, text, c.buildRewritePrompt(text, tone))
+}
+
+// StreamTransform applies a rewrite action (shorten, expand, simplify) to text, streaming the
+// result. percent is the target length for ActionShorten and is ignored otherwise.
+func (c *Corrector) StreamTransform(ctx context.Context, text, action string, percent int, onChunk func(string)) error {
+	instruction, err := actionInstruction(action, percent)
+	if err != nil {
+		return err
+	}
+	return c.streamRewritePrompt(ctx, text, c.buildRewriteInstructionPrompt(text, instruction), onChunk)
+}
+
+// Transform performs a non-streaming rewrite action
+func (c *Corrector) Transform(ctx context.Context, text, action string, percent int) (string, error) {
+	instruction, err := actionInstruction(action, percent)
+	if err != nil {
+		return "", err
+	}
+	return c.chatRewritePrompt(ctx, text, c.buildRewriteInstructionPrompt(text, instruction))
+}
+
+func (c *Corrector) streamRewritePrompt(ctx context.Context, text, prompt string, onChunk func(string)) error {
+	if err := validation.ValidateTextInput(text, onChunk); err != nil {
+		return err
+	}
+
+	// Apply rate limiting if enabled
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit error: %w", err)
+		}
+	}
+
+	messages := []provider.Message{
+		{
+			Role:    provider.RoleUser,
+			Content: prompt,
 		},
 	}
+	return c.streamCleaned(ctx, messages, text, onChunk)
+}
+
+func (c *Corrector) chatRewritePrompt(ctx context.Context, text, prompt string) (string, error) {
+	if err := validation.ValidateText(text); err != nil {
+		return "", err
+	}
 
-	return c.provider.Chat(ctx, c.model, messages)
+	// Apply rate limiting if enabled
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return "", fmt.Errorf("rate limit error: %w", err)
+		}
+	}
+
+	messages := []provider.Message{
+		{
+			Role:    provider.RoleUser,
+			Content: prompt,
+		},
+	}
+	return c.chatCleaned(ctx, messages, text)
 }
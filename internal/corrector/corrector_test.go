@@ -7,9 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/maximbilan/grammr/internal/glossary"
 	"github.com/maximbilan/grammr/internal/provider"
 	"github.com/maximbilan/grammr/internal/ratelimit"
-	"github.com/maximbilan/grammr/internal/validation"
 )
 
 func TestNew(t *testing.T) {
@@ -350,6 +350,33 @@ func TestStreamCorrect(t *testing.T) {
 	}
 }
 
+func TestCorrectStripsResponseWrappers(t *testing.T) {
+	mockProv := provider.NewMockProvider()
+	c, err := New(mockProv, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	text := "i are happy"
+	mockProv.SetResponse(c.buildPrompt(text), "Here is the corrected text:\n\n\"I am happy.\"\n\nLet me know if you need anything else!")
+
+	got, err := c.Correct(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Correct() error = %v", err)
+	}
+	if got != "I am happy." {
+		t.Fatalf("Correct() = %q, want %q", got, "I am happy.")
+	}
+
+	var streamed strings.Builder
+	if err := c.StreamCorrect(context.Background(), text, func(chunk string) { streamed.WriteString(chunk) }); err != nil {
+		t.Fatalf("StreamCorrect() error = %v", err)
+	}
+	if streamed.String() != "I am happy." {
+		t.Fatalf("StreamCorrect() = %q, want %q", streamed.String(), "I am happy.")
+	}
+}
+
 func TestCorrectValidationAndRateLimit(t *testing.T) {
 	mockProv := provider.NewMockProvider()
 	rl := ratelimit.New(1, time.Minute, time.Second)
@@ -366,14 +393,6 @@ func TestCorrectValidationAndRateLimit(t *testing.T) {
 		}
 	})
 
-	t.Run("text too long", func(t *testing.T) {
-		longText := strings.Repeat("x", validation.MaxInputLength+1)
-		_, err := c.Correct(context.Background(), longText)
-		if err == nil {
-			t.Fatal("Correct() should fail for oversized text")
-		}
-	})
-
 	t.Run("rate limit cancellation", func(t *testing.T) {
 		// Consume first token so second call blocks on limiter.
 		if err := rl.Wait(context.Background()); err != nil {
@@ -413,3 +432,357 @@ func TestStreamCorrectValidationErrors(t *testing.T) {
 		t.Fatal("StreamCorrect() should fail for nil callback")
 	}
 }
+
+func TestSuggestAlternatives(t *testing.T) {
+	mockProv := provider.NewMockProvider()
+	c, err := New(mockProv, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	sentence := "I am very happy today."
+	span := "very happy"
+	mockProv.SetResponse(c.buildAlternativesPrompt(sentence, span), "1. delighted\n- \"thrilled\"\nvery happy\n\n2) overjoyed\n3. glad")
+
+	got, err := c.SuggestAlternatives(context.Background(), sentence, span)
+	if err != nil {
+		t.Fatalf("SuggestAlternatives() error = %v", err)
+	}
+	want := []string{"delighted", "thrilled", "overjoyed"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("SuggestAlternatives() = %q, want %q", got, want)
+	}
+
+	t.Run("empty span", func(t *testing.T) {
+		if _, err := c.SuggestAlternatives(context.Background(), sentence, " "); err == nil {
+			t.Fatal("SuggestAlternatives() should fail for empty span")
+		}
+	})
+
+	t.Run("no usable alternatives", func(t *testing.T) {
+		mockProv.SetResponse(c.buildAlternativesPrompt(sentence, "happy"), "happy\n")
+		if _, err := c.SuggestAlternatives(context.Background(), sentence, "happy"); err == nil {
+			t.Fatal("SuggestAlternatives() should fail when the provider only echoes the span")
+		}
+	})
+}
+
+func TestExplainChange(t *testing.T) {
+	mockProv := provider.NewMockProvider()
+	c, err := New(mockProv, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	sentence := "She has two apples."
+	mockProv.SetResponse(c.buildExplanationPrompt(sentence, "have", "has"),
+		"  A singular subject like \"she\" takes \"has\".\n")
+	got, err := c.ExplainChange(context.Background(), sentence, "have", "has")
+	if err != nil {
+		t.Fatalf("ExplainChange() error = %v", err)
+	}
+	if want := `A singular subject like "she" takes "has".`; got != want {
+		t.Fatalf("ExplainChange() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{name: "replaced", before: "have", after: "has", want: `"have" was changed to "has"`},
+		{name: "removed", before: "very", after: "", want: `"very" was removed`},
+		{name: "added", before: "", after: "the", want: `"the" was added`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if prompt := c.buildExplanationPrompt(sentence, tt.before, tt.after); !strings.Contains(prompt, tt.want) {
+				t.Errorf("buildExplanationPrompt() = %q, want it to say %q", prompt, tt.want)
+			}
+		})
+	}
+
+	t.Run("empty change", func(t *testing.T) {
+		if _, err := c.ExplainChange(context.Background(), sentence, " ", ""); err == nil {
+			t.Fatal("ExplainChange() should fail for an empty change")
+		}
+	})
+}
+
+func TestParseAlternativesKeepsLeadingNumbers(t *testing.T) {
+	got := parseAlternatives("3 apples\n1. 4 pears", "two apples")
+	if len(got) != 2 || got[0] != "3 apples" || got[1] != "4 pears" {
+		t.Fatalf("parseAlternatives() = %q", got)
+	}
+}
+
+func TestBuildPromptCustomStyle(t *testing.T) {
+	customStyles := map[string]string{
+		"slack": "Keep it short and friendly, suitable for a Slack message.",
+	}
+	c, err := NewWithCustomStyles(provider.NewMockProvider(), "gpt-4o", "slack", "english", customStyles, nil)
+	if err != nil {
+		t.Fatalf("NewWithCustomStyles() error = %v", err)
+	}
+	if c.style != "slack" {
+		t.Fatalf("style = %q, want slack", c.style)
+	}
+
+	prompt := c.buildPrompt("hey team")
+	if !strings.Contains(prompt, "suitable for a Slack message") {
+		t.Errorf("buildPrompt() should contain the custom instructions. Got: %q", prompt)
+	}
+	if !strings.Contains(prompt, "Only output the corrected text") {
+		t.Errorf("buildPrompt() should keep the output instruction. Got: %q", prompt)
+	}
+
+	shadowing, err := NewWithCustomStyles(provider.NewMockProvider(), "gpt-4o", "formal", "english", map[string]string{"formal": "Talk like a pirate."}, nil)
+	if err != nil {
+		t.Fatalf("NewWithCustomStyles() error = %v", err)
+	}
+	if prompt := shadowing.buildPrompt("hey team"); strings.Contains(prompt, "pirate") || !strings.Contains(prompt, "more formal") {
+		t.Errorf("a custom style should not replace a built-in one. Got: %q", prompt)
+	}
+
+	unknown, err := NewWithCustomStyles(provider.NewMockProvider(), "gpt-4o", "legal", "english", customStyles, nil)
+	if err != nil {
+		t.Fatalf("NewWithCustomStyles() error = %v", err)
+	}
+	if unknown.style != "casual" {
+		t.Fatalf("unknown custom style should default to casual, got %q", unknown.style)
+	}
+}
+
+func TestSetPromptTemplate(t *testing.T) {
+	c, err := New(provider.NewMockProvider(), "gpt-4o", "formal", "german")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := c.SetPromptTemplate("Proofread this {{.Language}} text ({{.Style}}):\n{{.Text}}"); err != nil {
+		t.Fatalf("SetPromptTemplate() error = %v", err)
+	}
+	if got := c.buildPrompt("Hallo Welt"); got != "Proofread this german text (formal):\nHallo Welt" {
+		t.Fatalf("buildPrompt() = %q", got)
+	}
+
+	t.Run("invalid syntax", func(t *testing.T) {
+		if err := c.SetPromptTemplate("{{.Text"); err == nil {
+			t.Fatal("SetPromptTemplate() should fail for invalid syntax")
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		if err := c.SetPromptTemplate("{{.Tone}}"); err == nil {
+			t.Fatal("SetPromptTemplate() should fail for unknown fields")
+		}
+	})
+
+	t.Run("empty template restores default prompt", func(t *testing.T) {
+		if err := c.SetPromptTemplate("  "); err != nil {
+			t.Fatalf("SetPromptTemplate() error = %v", err)
+		}
+		if got := c.buildPrompt("Hallo"); !strings.Contains(got, "Text to correct:") {
+			t.Fatalf("buildPrompt() = %q, want default prompt", got)
+		}
+	})
+}
+
+func TestRewrite(t *testing.T) {
+	mockProv := provider.NewMockProvider()
+	c, err := New(mockProv, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	text := "I think maybe we could possibly ship this on Friday."
+	prompt := c.buildRewritePrompt(text, "concise")
+	if !strings.Contains(prompt, "concise tone") || !strings.Contains(prompt, toneInstructions["concise"]) {
+		t.Fatalf("buildRewritePrompt() should describe the tone. Got: %q", prompt)
+	}
+	mockProv.SetResponse(prompt, "We can ship this on Friday.")
+
+	got, err := c.Rewrite(context.Background(), text, "concise")
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if got != "We can ship this on Friday." {
+		t.Fatalf("Rewrite() = %q", got)
+	}
+
+	if _, err := c.Rewrite(context.Background(), text, "sarcastic"); err == nil || !strings.Contains(err.Error(), "unknown tone") {
+		t.Fatalf("Rewrite() with unknown tone error = %v, want unknown tone", err)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	mockProv := provider.NewMockProvider()
+	c, err := New(mockProv, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	text := "The meeting, which was scheduled for Monday, has been moved to Tuesday."
+	instruction, err := actionInstruction(ActionShorten, 0)
+	if err != nil {
+		t.Fatalf("actionInstruction() error = %v", err)
+	}
+	if !strings.Contains(instruction, "50%") {
+		t.Fatalf("shorten should default to %d%%, got %q", DefaultShortenPercent, instruction)
+	}
+	mockProv.SetResponse(c.buildRewriteInstructionPrompt(text, instruction), "The Monday meeting moved to Tuesday.")
+
+	got, err := c.Transform(context.Background(), text, ActionShorten, 0)
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	if got != "The Monday meeting moved to Tuesday." {
+		t.Fatalf("Transform() = %q", got)
+	}
+
+	tests := []struct {
+		name    string
+		action  string
+		percent int
+		wantErr string
+	}{
+		{name: "unknown action", action: "translate", wantErr: "unknown action"},
+		{name: "percent too large", action: ActionShorten, percent: 120, wantErr: "between 1 and 99"},
+		{name: "negative percent", action: ActionShorten, percent: -5, wantErr: "between 1 and 99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.Transform(context.Background(), text, tt.action, tt.percent); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Transform() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithLanguage(t *testing.T) {
+	c, err := New(provider.NewMockProvider(), "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	german := c.WithLanguage("german")
+	if prompt := german.buildPrompt("Ich habe ein Apfel."); !strings.Contains(prompt, "The text is in german.") {
+		t.Errorf("buildPrompt() should name the language. Got: %q", prompt)
+	}
+	if c.Language() != "english" {
+		t.Errorf("WithLanguage() changed the original corrector to %q", c.Language())
+	}
+	if got := c.WithLanguage("").Language(); got != "english" {
+		t.Errorf("WithLanguage(\"\").Language() = %q, want english", got)
+	}
+}
+
+func TestPromptVersion(t *testing.T) {
+	c, err := New(provider.NewMockProvider(), "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	version := c.PromptVersion()
+	if version != c.PromptVersion() {
+		t.Fatal("PromptVersion() should be stable")
+	}
+	if c.WithLanguage("german").PromptVersion() == version {
+		t.Error("PromptVersion() should change with the language")
+	}
+	if err := c.SetDialect("uk"); err != nil {
+		t.Fatalf("SetDialect() error = %v", err)
+	}
+	if c.PromptVersion() == version {
+		t.Error("PromptVersion() should change with the dialect")
+	}
+}
+
+func TestSetDialect(t *testing.T) {
+	c, err := New(provider.NewMockProvider(), "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := c.SetDialect(" UK "); err != nil {
+		t.Fatalf("SetDialect() error = %v", err)
+	}
+	if prompt := c.buildPrompt("The color is nice."); !strings.Contains(prompt, "British English") {
+		t.Errorf("buildPrompt() should ask for British English. Got: %q", prompt)
+	}
+	if prompt := c.buildRewritePrompt("The color is nice.", "friendly"); !strings.Contains(prompt, "British English") {
+		t.Errorf("buildRewritePrompt() should ask for British English. Got: %q", prompt)
+	}
+
+	spanish, err := New(provider.NewMockProvider(), "gpt-4o", "casual", "spanish")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := spanish.SetDialect("us"); err != nil {
+		t.Fatalf("SetDialect() error = %v", err)
+	}
+	if prompt := spanish.buildPrompt("Hola"); strings.Contains(prompt, "American English") {
+		t.Errorf("dialect should only apply to English text. Got: %q", prompt)
+	}
+
+	if err := c.SetDialect("nz"); err == nil {
+		t.Fatal("SetDialect() should reject unknown dialects")
+	}
+	if err := c.SetDialect(""); err != nil {
+		t.Fatalf("SetDialect(\"\") error = %v", err)
+	}
+	if prompt := c.buildPrompt("text"); strings.Contains(prompt, "English spelling") {
+		t.Errorf("clearing the dialect should remove the instruction. Got: %q", prompt)
+	}
+}
+
+func TestSetCategory(t *testing.T) {
+	c, err := New(provider.NewMockProvider(), "gpt-4o", "formal", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{category: "spelling", want: "Fix spelling mistakes only"},
+		{category: "Punctuation", want: "Fix punctuation mistakes only"},
+		{category: "grammar", want: "Fix grammar mistakes only"},
+		{category: "all", want: "Make it more formal and professional"},
+		{category: "", want: "Make it more formal and professional"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			if err := c.SetCategory(tt.category); err != nil {
+				t.Fatalf("SetCategory() error = %v", err)
+			}
+			if prompt := c.buildPrompt("text"); !strings.Contains(prompt, tt.want) {
+				t.Errorf("buildPrompt() should contain %q. Got: %q", tt.want, prompt)
+			}
+		})
+	}
+
+	if err := c.SetCategory("style"); err == nil {
+		t.Fatal("SetCategory() should reject unknown categories")
+	}
+}
+
+func TestSetGlossary(t *testing.T) {
+	c, err := New(provider.NewMockProvider(), "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if prompt := c.buildPrompt("text"); strings.Contains(prompt, "glossary") {
+		t.Errorf("buildPrompt() without a glossary should not mention it. Got: %q", prompt)
+	}
+
+	c.SetGlossary(&glossary.Glossary{Terms: []glossary.Term{{Preferred: "sign in", Avoid: []string{"login"}}}})
+	if prompt := c.buildPrompt("Please login."); !strings.Contains(prompt, `Use "sign in", not "login".`) {
+		t.Errorf("buildPrompt() should include the glossary. Got: %q", prompt)
+	}
+	if prompt := c.buildRewritePrompt("Please login.", "friendly"); !strings.Contains(prompt, `Use "sign in"`) {
+		t.Errorf("buildRewritePrompt() should include the glossary. Got: %q", prompt)
+	}
+}
@@ -0,0 +1,37 @@
+package corrector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialects lists the supported English variants in display order
+var Dialects = []string{"us", "uk", "au"}
+
+var dialectInstructions = map[string]string{
+	"us": "Use American English spelling and punctuation (e.g. color, organize), with periods and commas inside quotation marks.",
+	"uk": "Use British English spelling and punctuation (e.g. colour, organise), with single quotation marks and punctuation outside them unless it belongs to the quote.",
+	"au": "Use Australian English spelling and punctuation (e.g. colour, organise), with single quotation marks and punctuation outside them unless it belongs to the quote.",
+}
+
+// SetDialect sets the English variant ("us", "uk" or "au") that spelling and punctuation are
+// normalized to. An empty dialect leaves the variant of the input unchanged.
+func (c *Corrector) SetDialect(dialect string) error {
+	dialect = strings.ToLower(strings.TrimSpace(dialect))
+	if dialect != "" {
+		if _, ok := dialectInstructions[dialect]; !ok {
+			return fmt.Errorf("unknown dialect: %s (supported: %s)", dialect, strings.Join(Dialects, ", "))
+		}
+	}
+	c.dialect = dialect
+	return nil
+}
+
+// dialectInstruction returns the prompt line for the configured dialect. Dialects only apply
+// to English text.
+func (c *Corrector) dialectInstruction() string {
+	if c.dialect == "" || (c.language != "" && c.language != "english") {
+		return ""
+	}
+	return "\n" + dialectInstructions[c.dialect]
+}
@@ -0,0 +1,59 @@
+package corrector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maximbilan/grammr/internal/postprocess"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// differentInstruction follows up a correction whose phrasing the user wants to replace
+const differentInstruction = `Correct the text again. Fix the same mistakes, but phrase the correction differently from your previous answer.
+Only output the corrected text, nothing else.`
+
+// differentMessages continues a correction request with the previous answer and asks for
+// another one
+func (c *Corrector) differentMessages(text, previous string, masked bool) []provider.Message {
+	return append(c.correctionMessages(text, masked),
+		provider.Message{Role: provider.RoleAssistant, Content: previous},
+		provider.Message{Role: provider.RoleUser, Content: differentInstruction},
+	)
+}
+
+// StreamCorrectDifferently corrects text again, asking for a different phrasing than previous,
+// the correction returned before. Unlike StreamCorrect, the same text gives a new result.
+func (c *Corrector) StreamCorrectDifferently(ctx context.Context, text, previous string, onChunk func(string)) error {
+	if err := validation.ValidateTextInput(text, onChunk); err != nil {
+		return err
+	}
+	if previous == "" {
+		return fmt.Errorf("previous correction cannot be empty")
+	}
+
+	// Apply rate limiting if enabled
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit error: %w", err)
+		}
+	}
+
+	format := c.formatOf(text)
+	if masked, protected := mask(format, text); len(protected) > 0 {
+		// The previous correction kept the protected content, so it masks the same way
+		maskedPrevious, _ := mask(format, previous)
+		corrected, err := c.provider.Chat(ctx, c.model, c.differentMessages(masked, maskedPrevious, true))
+		if err != nil {
+			return err
+		}
+		restored, err := restoreProtected(postprocess.Clean(corrected, masked), protected)
+		if err != nil {
+			return err
+		}
+		onChunk(restored)
+		return nil
+	}
+
+	return c.streamCleaned(ctx, c.differentMessages(text, previous, false), text, onChunk)
+}
@@ -0,0 +1,49 @@
+package corrector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maximbilan/grammr/internal/provider"
+)
+
+func TestStreamCorrectDifferently(t *testing.T) {
+	mockProv := provider.NewMockProvider()
+	c, err := New(mockProv, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	mockProv.SetResponse(differentInstruction, "I own an apple.")
+
+	var got string
+	err = c.StreamCorrectDifferently(context.Background(), "I has a apple.", "I have an apple.", func(chunk string) {
+		got += chunk
+	})
+	if err != nil {
+		t.Fatalf("StreamCorrectDifferently() error = %v", err)
+	}
+	if got != "I own an apple." {
+		t.Fatalf("StreamCorrectDifferently() = %q, want the new phrasing", got)
+	}
+
+	messages := c.differentMessages("I has a apple.", "I have an apple.", false)
+	roles := []string{provider.RoleUser, provider.RoleAssistant, provider.RoleUser}
+	if len(messages) != len(roles) {
+		t.Fatalf("differentMessages() = %d messages, want %d", len(messages), len(roles))
+	}
+	for i, role := range roles {
+		if messages[i].Role != role {
+			t.Errorf("message %d role = %q, want %q", i, messages[i].Role, role)
+		}
+	}
+	if messages[1].Content != "I have an apple." {
+		t.Errorf("assistant message = %q, want the previous correction", messages[1].Content)
+	}
+
+	t.Run("no previous correction", func(t *testing.T) {
+		err := c.StreamCorrectDifferently(context.Background(), "I has a apple.", "", func(string) {})
+		if err == nil {
+			t.Fatal("StreamCorrectDifferently() should fail without a previous correction")
+		}
+	})
+}
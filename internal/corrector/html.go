@@ -0,0 +1,31 @@
+package corrector
+
+import (
+	"regexp"
+	"slices"
+)
+
+// htmlPatterns match the markup of an HTML text, which the model must not touch, in the order
+// they are masked: comments, elements whose content isn't prose, template syntax such as the
+// merge tags of email templates, runs of tags with their attributes, and character references
+// such as &nbsp;
+var htmlPatterns = slices.Concat([]*regexp.Regexp{
+	regexp.MustCompile(`(?s)<!--.*?-->`),
+	regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>|<pre\b.*?</pre\s*>|<code\b.*?</code\s*>`),
+}, templatePatterns, []*regexp.Regexp{
+	regexp.MustCompile(`(?:<[a-zA-Z/!?](?:[^>"']|"[^"]*"|'[^']*')*>)+`),
+	regexp.MustCompile(`&(?:[a-zA-Z][a-zA-Z0-9]*|#[0-9]+|#[xX][0-9a-fA-F]+);`),
+})
+
+// htmlStart matches a text that starts with a tag, a comment or a doctype
+var htmlStart = regexp.MustCompile(`\A\s*<(?:!DOCTYPE|!doctype|!--|[a-zA-Z][a-zA-Z0-9-]*[\s/>])`)
+
+// htmlEnd matches a closing tag
+var htmlEnd = regexp.MustCompile(`</[a-zA-Z][a-zA-Z0-9-]*\s*>`)
+
+// LooksLikeHTML reports whether text appears to be HTML, such as an email draft or CMS content
+// copied as HTML: it starts with markup and closes a tag. Markdown with a few inline tags
+// doesn't.
+func LooksLikeHTML(text string) bool {
+	return htmlStart.MatchString(text) && htmlEnd.MatchString(text)
+}
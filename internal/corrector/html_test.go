@@ -0,0 +1,106 @@
+package corrector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/maximbilan/grammr/internal/provider"
+)
+
+const htmlSample = `<div class="greeting" data-note="a > b">Hi Anna,</div>
+<!-- draft: its not final -->
+<p>Thank you for you're <a href="https://example.com/offer?a=1&amp;b=2" title='The "offer"'>offer</a>&nbsp;&mdash; its great.</p>
+<pre>teh code</pre><style>p { color: red; }</style>`
+
+func TestLooksLikeHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "email draft", text: htmlSample, want: true},
+		{name: "doctype", text: "<!DOCTYPE html>\n<html><body>Hi</body></html>", want: true},
+		{name: "paragraphs", text: "  <p>One</p>\n<p>Two</p>", want: true},
+		{name: "plain text", text: "I has a apple.", want: false},
+		{name: "comparison", text: "If a < b and c > d, it </works>.", want: false},
+		{name: "markdown with a tag", text: "# Title\n\nPress <kbd>Ctrl</kbd> to copy.", want: false},
+		{name: "unclosed tag", text: "<br> and nothing else", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LooksLikeHTML(tt.text); got != tt.want {
+				t.Errorf("LooksLikeHTML(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskHTML(t *testing.T) {
+	masked, protected := mask(FormatHTML, htmlSample)
+
+	for _, hidden := range []string{"<div", "greeting", "a > b", "its not final", "href", "&amp;", "&nbsp;", "&mdash;", "teh code", "color: red", "</p>"} {
+		if strings.Contains(masked, hidden) {
+			t.Errorf("masked text should not contain %q: %q", hidden, masked)
+		}
+	}
+	for _, visible := range []string{"Hi Anna,", "Thank you for you're ", "offer", " its great."} {
+		if !strings.Contains(masked, visible) {
+			t.Errorf("masked text should still contain %q: %q", visible, masked)
+		}
+	}
+	// Adjacent tags share a placeholder
+	if got, tags := mask(FormatHTML, "<p><b>Hi</b></p>"); got != "⟦0⟧Hi⟦1⟧" || len(tags) != 2 {
+		t.Errorf("mask() = %q, %q, want a placeholder for each run of tags", got, tags)
+	}
+
+	restored, err := restoreProtected(masked, protected)
+	if err != nil {
+		t.Fatalf("restoreProtected() error = %v", err)
+	}
+	if restored != htmlSample {
+		t.Fatalf("restoreProtected() = %q, want the original text", restored)
+	}
+}
+
+func TestCorrectProtectsHTML(t *testing.T) {
+	mockProv := provider.NewMockProvider()
+	c, err := New(mockProv, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	text := `<p>Thank you for <b class="x">you're</b> offer&nbsp;&mdash; its great.</p>`
+	masked, _ := mask(FormatHTML, text)
+	corrected := strings.NewReplacer("you're", "your", "its", "it's").Replace(masked)
+	mockProv.SetResponse(c.buildPrompt(masked), corrected)
+
+	// Auto mode detects the HTML
+	got, err := c.Correct(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Correct() error = %v", err)
+	}
+	if want := `<p>Thank you for <b class="x">your</b> offer&nbsp;&mdash; it's great.</p>`; got != want {
+		t.Fatalf("Correct() = %q, want %q", got, want)
+	}
+
+	// A text that doesn't look like HTML is protected in html mode too
+	if err := c.SetFormat(FormatHTML); err != nil {
+		t.Fatalf("SetFormat() error = %v", err)
+	}
+	text = "Its <b>bold</b>."
+	masked, _ = mask(FormatHTML, text)
+	mockProv.SetResponse(c.buildPrompt(masked), strings.Replace(masked, "Its", "It's", 1))
+	if got, err := c.Correct(context.Background(), text); err != nil || got != "It's <b>bold</b>." {
+		t.Fatalf("Correct() in html mode = %q, %v", got, err)
+	}
+
+	// In markdown mode the tags are sent as they are
+	if err := c.SetFormat(FormatMarkdown); err != nil {
+		t.Fatalf("SetFormat() error = %v", err)
+	}
+	if _, protected := mask(c.formatOf(text), text); len(protected) != 0 {
+		t.Errorf("markdown mode masked %q", protected)
+	}
+}
@@ -0,0 +1,145 @@
+package corrector
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Input formats accepted by SetFormat
+const (
+	FormatAuto     = "auto"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatPlain    = "plain"
+)
+
+// Formats lists the supported input formats
+var Formats = []string{FormatAuto, FormatMarkdown, FormatHTML, FormatPlain}
+
+// protectedPatterns match the parts of a Markdown document the model must not touch, in the
+// order they are masked
+var protectedPatterns = slices.Concat([]*regexp.Regexp{
+	regexp.MustCompile(`\A\s*(?:---\r?\n(?s:.*?\r?\n)?---|\+\+\+\r?\n(?s:.*?\r?\n)?\+\+\+)[ \t]*(?:\r?\n|\z)`), // Front matter
+	regexp.MustCompile("(?ms)^[ \t]*```.*?(?:^[ \t]*```[^\n]*$|\\z)"),                                          // Fenced code blocks
+	regexp.MustCompile("(?ms)^[ \t]*~~~.*?(?:^[ \t]*~~~[^\n]*$|\\z)"),
+}, templatePatterns, []*regexp.Regexp{
+	regexp.MustCompile("``[^\n]+?``|`[^`\n]+`"),        // Inline code
+	regexp.MustCompile(`\]\([^)\s]*(?:\s+"[^"]*")?\)`), // Link targets
+	regexp.MustCompile(`https?://[^\s<>()\[\]]+`),      // Bare URLs
+})
+
+// templatePatterns match the template syntax of static site generators and CMSs, which breaks
+// builds when the model rewrites it: blocks whose content isn't prose, such as highlighted code
+// and comments, then Hugo shortcodes, Go template actions and Liquid tags and outputs
+var templatePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?s)\{\{[<%]-?\s*highlight\b.*?\{\{[<%]-?\s*/highlight\s*-?[>%]\}\}`),
+	regexp.MustCompile(`(?s)\{%-?\s*highlight\b.*?\{%-?\s*endhighlight\s*-?%\}`),
+	regexp.MustCompile(`(?s)\{%-?\s*raw\s*-?%\}.*?\{%-?\s*endraw\s*-?%\}`),
+	regexp.MustCompile(`(?s)\{%-?\s*comment\s*-?%\}.*?\{%-?\s*endcomment\s*-?%\}`),
+	regexp.MustCompile(`(?s)\{\{.*?\}\}|\{%.*?%\}`),
+}
+
+// markdownHints match syntax that is common in Markdown and rare in plain prose
+var markdownHints = []*regexp.Regexp{
+	regexp.MustCompile("(?m)^[ \t]*(```|~~~)"),
+	regexp.MustCompile(`\A\s*(---|\+\+\+)\r?\n`),
+	regexp.MustCompile(`\{\{.*?\}\}|\{%.*?%\}`),
+	regexp.MustCompile("`[^`\n]+`"),
+	regexp.MustCompile(`\[[^\]\n]+\]\([^)\s]+\)`),
+	regexp.MustCompile(`(?m)^#{1,6} \S`),
+}
+
+const placeholderInstruction = `The text contains placeholders such as ⟦0⟧ that stand for code, links, markup or metadata.
+Keep every placeholder exactly as it is, in the same place.`
+
+// LooksLikeMarkdown reports whether text appears to be Markdown
+func LooksLikeMarkdown(text string) bool {
+	for _, hint := range markdownHints {
+		if hint.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateFormat(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format: %s (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// SetFormat sets how input is treated: "markdown" protects code, links and front matter from
+// the model, "html" protects tags, attributes and entities, "plain" sends text as is, and "auto"
+// (the default) protects text that looks like HTML or Markdown
+func (c *Corrector) SetFormat(format string) error {
+	if format == "" {
+		format = FormatAuto
+	}
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+	c.format = format
+	return nil
+}
+
+// formatOf returns the format text is treated as, detecting it in auto mode
+func (c *Corrector) formatOf(text string) string {
+	if c.format != FormatAuto && c.format != "" {
+		return c.format
+	}
+	switch {
+	case LooksLikeHTML(text):
+		return FormatHTML
+	case LooksLikeMarkdown(text):
+		return FormatMarkdown
+	}
+	return FormatPlain
+}
+
+// mask replaces the content of text in format that the model must not touch with numbered
+// placeholders, returning the masked text and the original content of each placeholder
+func mask(format, text string) (string, []string) {
+	switch format {
+	case FormatMarkdown:
+		return maskPatterns(text, protectedPatterns)
+	case FormatHTML:
+		return maskPatterns(text, htmlPatterns)
+	}
+	return text, nil
+}
+
+func placeholder(i int) string {
+	return fmt.Sprintf("⟦%d⟧", i)
+}
+
+// maskPatterns replaces the matches of patterns, one pattern after the other, with numbered
+// placeholders
+func maskPatterns(text string, patterns []*regexp.Regexp) (string, []string) {
+	var protected []string
+	for _, pattern := range patterns {
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			protected = append(protected, match)
+			return placeholder(len(protected) - 1)
+		})
+	}
+	return text, protected
+}
+
+// restoreProtected puts the protected content back in place of its placeholders. It fails if
+// the model dropped a placeholder, since the protected content would be lost otherwise.
+func restoreProtected(text string, protected []string) (string, error) {
+	// Restore in reverse so content masked by an earlier pattern is put back last
+	for i := len(protected) - 1; i >= 0; i-- {
+		p := placeholder(i)
+		if !strings.Contains(text, p) {
+			return "", fmt.Errorf("correction dropped protected content %q; try again or set format to plain", protected[i])
+		}
+		text = strings.Replace(text, p, protected[i], 1)
+	}
+	return text, nil
+}
@@ -0,0 +1,131 @@
+package corrector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/maximbilan/grammr/internal/provider"
+)
+
+const markdownSample = "---\ntitle: Notes\n---\n# Setup\n\nRun `go buid ./...` and see [the docs](https://example.com/docs).\n\n```go\nfunc main() { fmt.Println(\"helo\") }\n```\n\nMore at https://example.com/faq, its great."
+
+func TestLooksLikeMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "plain prose", text: "I has a apple. It's great.", want: false},
+		{name: "plain prose with url", text: "See https://example.com for more.", want: false},
+		{name: "heading", text: "# Title\n\nSome text", want: true},
+		{name: "inline code", text: "Call `Run()` first.", want: true},
+		{name: "code fence", text: "Example:\n```\ncode\n```", want: true},
+		{name: "link", text: "Read [this](https://example.com).", want: true},
+		{name: "front matter", text: "---\ntitle: x\n---\nBody", want: true},
+		{name: "toml front matter", text: "+++\r\ntitle = \"x\"\r\n+++\r\nBody", want: true},
+		{name: "shortcode", text: "Look at this.\n\n{{< figure src=\"a.png\" >}}", want: true},
+		{name: "liquid tag", text: "Hello {% if user %}{{ user.name }}{% endif %}.", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LooksLikeMarkdown(tt.text); got != tt.want {
+				t.Errorf("LooksLikeMarkdown(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskMarkdown(t *testing.T) {
+	masked, protected := mask(FormatMarkdown, markdownSample)
+
+	for _, keep := range []string{"title: Notes", "go buid", "https://example.com/docs", "helo", "https://example.com/faq"} {
+		if strings.Contains(masked, keep) {
+			t.Errorf("masked text should not contain %q: %q", keep, masked)
+		}
+	}
+	for _, visible := range []string{"# Setup", "the docs", "its great"} {
+		if !strings.Contains(masked, visible) {
+			t.Errorf("masked text should still contain %q: %q", visible, masked)
+		}
+	}
+
+	restored, err := restoreProtected(masked, protected)
+	if err != nil {
+		t.Fatalf("restoreProtected() error = %v", err)
+	}
+	if restored != markdownSample {
+		t.Fatalf("restoreProtected() = %q, want the original text", restored)
+	}
+
+	if _, err := restoreProtected(strings.Replace(masked, placeholder(0), "", 1), protected); err == nil {
+		t.Fatal("restoreProtected() should fail when a placeholder is missing")
+	}
+}
+
+const blogSample = "+++\ntitle = \"My frist post\"\ndraft = true\n+++\n\nThis is my frist post on {{ .Site.Title }}.\n\n{{< figure src=\"/img/cat.png\" caption=\"My cat\" >}}\n\n{{% notice info %}}\nThe notice text gets corected.\n{{% /notice %}}\n\n{% if page.comments %}Leave a coment below.{% endif %}\n\n{% raw %}\n{{ not a template }}\n{% endraw %}\n\n{{< highlight go >}}\nfmt.Println(\"helo\")\n{{< /highlight >}}\n"
+
+func TestMaskTemplates(t *testing.T) {
+	masked, protected := mask(FormatMarkdown, blogSample)
+
+	for _, keep := range []string{"My frist post", ".Site.Title", "figure src", "notice info", "page.comments", "endif", "not a template", "helo"} {
+		if strings.Contains(masked, keep) {
+			t.Errorf("masked text should not contain %q: %q", keep, masked)
+		}
+	}
+	for _, visible := range []string{"This is my frist post on", "The notice text gets corected.", "Leave a coment below."} {
+		if !strings.Contains(masked, visible) {
+			t.Errorf("masked text should still contain %q: %q", visible, masked)
+		}
+	}
+
+	restored, err := restoreProtected(masked, protected)
+	if err != nil {
+		t.Fatalf("restoreProtected() error = %v", err)
+	}
+	if restored != blogSample {
+		t.Fatalf("restoreProtected() = %q, want the original text", restored)
+	}
+}
+
+func TestCorrectProtectsMarkdown(t *testing.T) {
+	mockProv := provider.NewMockProvider()
+	c, err := New(mockProv, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	text := "Run `go buid` its fast."
+	masked, _ := mask(FormatMarkdown, text)
+	mockProv.SetResponse(c.buildPrompt(masked), "Run "+placeholder(0)+"; it's fast.")
+
+	got, err := c.Correct(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Correct() error = %v", err)
+	}
+	if got != "Run `go buid`; it's fast." {
+		t.Fatalf("Correct() = %q", got)
+	}
+
+	var streamed strings.Builder
+	if err := c.StreamCorrect(context.Background(), text, func(chunk string) { streamed.WriteString(chunk) }); err != nil {
+		t.Fatalf("StreamCorrect() error = %v", err)
+	}
+	if streamed.String() != got {
+		t.Fatalf("StreamCorrect() = %q, want %q", streamed.String(), got)
+	}
+
+	// In plain mode the text is sent unchanged
+	if err := c.SetFormat(FormatPlain); err != nil {
+		t.Fatalf("SetFormat() error = %v", err)
+	}
+	mockProv.SetResponse(c.buildPrompt(text), "plain")
+	if got, _ := c.Correct(context.Background(), text); got != "plain" {
+		t.Fatalf("Correct() in plain mode = %q, want %q", got, "plain")
+	}
+
+	if err := c.SetFormat("rtf"); err == nil {
+		t.Fatal("SetFormat() should reject unknown formats")
+	}
+}
@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/rpc"
+)
+
+const (
+	// SocketFile is the name of the socket of grammrd in ~/.grammr
+	SocketFile = "grammrd.sock"
+
+	// dialTimeout is how long commands wait for grammrd before doing the work themselves
+	dialTimeout = 200 * time.Millisecond
+	dirPerm     = 0700
+	// socketPerm lets only the user connect, since grammrd sends requests with their API key
+	socketPerm = 0600
+)
+
+// Listen listens on the socket in path, replacing the socket of a grammrd that is no longer
+// running. It fails when another grammrd is listening there.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, dialTimeout); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("grammrd is already running on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketPerm); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket: %w", err)
+	}
+	return listener, nil
+}
+
+// Serve answers the clients connecting to listener with srv until ctx is done, then closes the
+// listener and waits for the clients
+func Serve(ctx context.Context, listener net.Listener, srv *rpc.Server) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			// Ending the context closes the connection, which ends the loop reading it
+			stop := context.AfterFunc(ctx, func() { conn.Close() })
+			defer stop()
+			_ = srv.Serve(ctx, conn, conn)
+		}()
+	}
+}
+
+// Dial connects to the grammrd listening on path
+func Dial(path string) (*rpc.Client, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.NewClient(conn), nil
+}
@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/rpc"
+)
+
+// fakeProvider answers every request with the same response
+type fakeProvider struct {
+	response string
+}
+
+func (p fakeProvider) StreamChat(ctx context.Context, model string, messages []provider.Message, onChunk func(string)) error {
+	onChunk(p.response)
+	return nil
+}
+
+func (p fakeProvider) Chat(ctx context.Context, model string, messages []provider.Message) (string, error) {
+	return p.response, nil
+}
+
+func TestServeAndDial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SocketFile)
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != socketPerm {
+		t.Errorf("socket permissions = %o, want %o", perm, socketPerm)
+	}
+
+	cor, err := corrector.New(fakeProvider{response: "I have an apple."}, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, listener, rpc.New(cor, nil, rpc.Options{Timeout: time.Second}))
+	}()
+
+	// Two clients at once are both answered
+	for i := 0; i < 2; i++ {
+		client, err := Dial(path)
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		defer client.Close()
+		var result rpc.CorrectResult
+		if err := client.Call(context.Background(), "correct", rpc.CorrectParams{Text: "I has a apple."}, &result, nil); err != nil {
+			t.Fatalf("Call() error = %v", err)
+		}
+		if result.Corrected != "I have an apple." {
+			t.Errorf("correct answered %+v", result)
+		}
+	}
+
+	if _, err := Listen(path); err == nil {
+		t.Error("Listen() should fail while another daemon is listening")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SocketFile)
+	// A socket file left by a daemon that was killed
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	listener.Close()
+}
+
+func TestDialWithoutDaemon(t *testing.T) {
+	if _, err := Dial(filepath.Join(t.TempDir(), SocketFile)); err == nil {
+		t.Error("Dial() should fail when no daemon is running")
+	}
+}
@@ -0,0 +1,350 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/maximbilan/grammr/internal/textdiff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// documentPath is the part of a .docx file that holds the body of the document
+const documentPath = "word/document.xml"
+
+// wordNamespace is the namespace of the WordprocessingML elements
+const wordNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// ParagraphSeparator separates the paragraphs in Text
+const ParagraphSeparator = "\n\n"
+
+// lineBreakPattern matches the line breaks of added text, which can't go inside a run
+var lineBreakPattern = regexp.MustCompile(`\s*\n\s*`)
+
+// Document is a Word document whose text can be replaced without losing its formatting. Only
+// the text inside runs changes, so every run keeps its style, and every other part of the file
+// is copied as it is.
+type Document struct {
+	files      []*zip.File
+	body       []byte    // The XML of documentPath
+	elements   []element // The text elements of the body, in order
+	paragraphs [][]unit  // The characters of the paragraphs with text, in order
+}
+
+// element is a text element (w:t) of the body
+type element struct {
+	start, end int    // Byte range of the whole element in the body
+	tag        string // Start tag, as in the body
+	text       string
+	replaced   string // Text written in place of text
+}
+
+// unit is a character of a paragraph, from a text element or, when element is -1, from a tab or
+// a line break, which can't be changed
+type unit struct {
+	r       rune
+	element int
+}
+
+// Open reads the .docx file at path
+func Open(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Read(bytes.NewReader(data), int64(len(data)))
+}
+
+// Read reads a .docx file of size bytes from r, which has to stay readable until the document
+// is written
+func Read(r io.ReaderAt, size int64) (*Document, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not a Word document: %w", err)
+	}
+	d := &Document{files: archive.File}
+	for _, f := range archive.File {
+		if f.Name != documentPath {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", documentPath, err)
+		}
+		d.body, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", documentPath, err)
+		}
+		if err := d.parse(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", documentPath, err)
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("not a Word document: %s is missing", documentPath)
+}
+
+// parse finds the text elements of the body and the paragraphs they belong to. A paragraph
+// inside another one, such as in a text box, is a paragraph of its own.
+func (d *Document) parse() error {
+	decoder := xml.NewDecoder(bytes.NewReader(d.body))
+	var paragraphs [][]unit // Open paragraphs, innermost last
+	runs := 0               // Depth of runs, outside of which tabs are tab stops
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != wordNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				paragraphs = append(paragraphs, nil)
+			case "r":
+				runs++
+			case "t":
+				e, err := d.readText(decoder, offset)
+				if err != nil {
+					return err
+				}
+				if len(paragraphs) == 0 {
+					// Text outside of paragraphs is left alone
+					continue
+				}
+				index := len(d.elements)
+				d.elements = append(d.elements, e)
+				for _, r := range e.text {
+					paragraphs[len(paragraphs)-1] = append(paragraphs[len(paragraphs)-1], unit{r: r, element: index})
+				}
+			case "tab", "br", "cr":
+				if runs == 0 || len(paragraphs) == 0 {
+					continue
+				}
+				r := '\n'
+				if t.Name.Local == "tab" {
+					r = '\t'
+				}
+				paragraphs[len(paragraphs)-1] = append(paragraphs[len(paragraphs)-1], unit{r: r, element: -1})
+			}
+		case xml.EndElement:
+			if t.Name.Space != wordNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				if len(paragraphs) == 0 {
+					continue
+				}
+				paragraph := paragraphs[len(paragraphs)-1]
+				paragraphs = paragraphs[:len(paragraphs)-1]
+				if hasText(paragraph) {
+					d.paragraphs = append(d.paragraphs, paragraph)
+				}
+			case "r":
+				runs--
+			}
+		}
+	}
+}
+
+// readText reads the rest of a text element that started at offset
+func (d *Document) readText(decoder *xml.Decoder, offset int) (element, error) {
+	e := element{start: offset, tag: string(d.body[offset:decoder.InputOffset()])}
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return e, err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			e.end = int(decoder.InputOffset())
+			e.text = text.String()
+			e.replaced = e.text
+			return e, nil
+		}
+	}
+}
+
+// hasText reports whether a paragraph has any text other than whitespace
+func hasText(paragraph []unit) bool {
+	for _, u := range paragraph {
+		if u.element >= 0 && strings.TrimSpace(string(u.r)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Paragraphs returns the number of paragraphs with text
+func (d *Document) Paragraphs() int {
+	return len(d.paragraphs)
+}
+
+// Text returns the text of the document: its paragraphs, separated by ParagraphSeparator, with
+// tabs and line breaks. Empty paragraphs are left out.
+func (d *Document) Text() string {
+	var b strings.Builder
+	for _, u := range d.characters() {
+		b.WriteRune(u.r)
+	}
+	return b.String()
+}
+
+// characters returns the characters of Text with the elements they come from
+func (d *Document) characters() []unit {
+	var units []unit
+	for i, paragraph := range d.paragraphs {
+		if i > 0 {
+			for _, r := range ParagraphSeparator {
+				units = append(units, unit{r: r, element: -1})
+			}
+		}
+		units = append(units, paragraph...)
+	}
+	return units
+}
+
+// Replace sets the text of the document to text, a corrected version of Text. Each change goes
+// into the run of the text it replaces, or of the text before it when it only adds some, so the
+// runs keep their formatting. Tabs, line breaks and paragraphs stay where they are, even when
+// text leaves them out or puts spaces in their place.
+func (d *Document) Replace(text string) {
+	texts := make([]strings.Builder, len(d.elements))
+	if paragraphs := strings.Split(text, ParagraphSeparator); len(paragraphs) == len(d.paragraphs) {
+		// Each paragraph is compared on its own, which keeps the diffs of a long document small
+		for i, paragraph := range d.paragraphs {
+			replaceUnits(paragraph, paragraphs[i], texts)
+		}
+	} else {
+		replaceUnits(d.characters(), text, texts)
+	}
+	// The elements of the paragraphs left out of Text keep their text
+	for _, paragraph := range d.paragraphs {
+		for _, u := range paragraph {
+			if u.element >= 0 {
+				d.elements[u.element].replaced = texts[u.element].String()
+			}
+		}
+	}
+}
+
+// replaceUnits writes text, a corrected version of the characters units, into texts, by element
+func replaceUnits(units []unit, text string, texts []strings.Builder) {
+	var original strings.Builder
+	for _, u := range units {
+		original.WriteRune(u.r)
+	}
+	pos, previous, last := 0, -1, -1
+	for _, diff := range textdiff.Compute(original.String(), text, textdiff.Options{}) {
+		if diff.Type == diffmatchpatch.DiffInsert {
+			nextFixed := pos < len(units) && units[pos].element < 0
+			if strings.TrimSpace(diff.Text) == "" && ((pos > 0 && previous < 0) || nextFixed) {
+				// Whitespace next to a tab, a line break or a paragraph end, which is kept
+				continue
+			}
+			target := previous
+			for i := pos; target < 0 && i < len(units); i++ {
+				target = units[i].element
+			}
+			if target < 0 {
+				target = last
+			}
+			if target >= 0 {
+				texts[target].WriteString(lineBreakPattern.ReplaceAllString(diff.Text, " "))
+			}
+			continue
+		}
+		for _, r := range diff.Text {
+			if pos >= len(units) {
+				break
+			}
+			u := units[pos]
+			pos++
+			if u.element >= 0 {
+				if diff.Type == diffmatchpatch.DiffEqual {
+					texts[u.element].WriteRune(r)
+				}
+				last = u.element
+			}
+			previous = u.element
+		}
+	}
+}
+
+// Write writes the document, with the text given to Replace, as a .docx file to w
+func (d *Document) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+	for _, f := range d.files {
+		if f.Name != documentPath {
+			if err := archive.Copy(f); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", f.Name, err)
+			}
+			continue
+		}
+		fw, err := archive.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: f.Modified})
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", documentPath, err)
+		}
+		if _, err := fw.Write(d.render()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", documentPath, err)
+		}
+	}
+	return archive.Close()
+}
+
+// render returns the XML of the body with the replaced text elements
+func (d *Document) render() []byte {
+	var b bytes.Buffer
+	b.Grow(len(d.body))
+	pos := 0
+	for _, e := range d.elements {
+		if e.replaced == e.text {
+			continue
+		}
+		b.Write(d.body[pos:e.start])
+		e.write(&b)
+		pos = e.end
+	}
+	b.Write(d.body[pos:])
+	return b.Bytes()
+}
+
+// write writes the element with its replaced text. Spaces at either end of a text are only kept
+// when the element says to preserve them.
+func (e element) write(b *bytes.Buffer) {
+	tag := e.tag
+	if short, ok := strings.CutSuffix(tag, "/>"); ok {
+		tag = short + ">"
+	}
+	if strings.TrimSpace(e.replaced) != e.replaced && !strings.Contains(tag, "xml:space") {
+		tag = strings.TrimSuffix(tag, ">") + ` xml:space="preserve">`
+	}
+	b.WriteString(tag)
+	xml.EscapeText(b, []byte(e.replaced))
+	b.WriteString("</" + tagName(e.tag) + ">")
+}
+
+// tagName returns the qualified name of a start tag, such as "w:t"
+func tagName(tag string) string {
+	name := strings.TrimPrefix(tag, "<")
+	if i := strings.IndexAny(name, " \t\r\n/>"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
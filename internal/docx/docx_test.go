@@ -0,0 +1,190 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+const contentTypes = `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`
+
+// newDocx returns a .docx file with body as the content of its document body
+func newDocx(t *testing.T, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes},
+		{documentPath, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body + `</w:body></w:document>`},
+	} {
+		w, err := archive.Create(part.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readPart returns the content of the part name of a .docx file
+func readPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := archive.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func readDocx(t *testing.T, data []byte) *Document {
+	t.Helper()
+	d, err := Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return d
+}
+
+func TestText(t *testing.T) {
+	body := `<w:p><w:pPr><w:tabs><w:tab w:val="left" w:pos="720"/></w:tabs></w:pPr>` +
+		`<w:r><w:t xml:space="preserve">I has </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>a</w:t></w:r><w:r><w:t xml:space="preserve"> apple.</w:t></w:r></w:p>` +
+		`<w:p/>` +
+		`<w:p><w:r><w:t> </w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Name:</w:t><w:tab/><w:t>Tom &amp; Jerry</w:t><w:br/><w:t>Line two</w:t></w:r></w:p>` +
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>In a cell</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	d := readDocx(t, newDocx(t, body))
+
+	want := "I has a apple.\n\nName:\tTom & Jerry\nLine two\n\nIn a cell"
+	if got := d.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if got := d.Paragraphs(); got != 3 {
+		t.Errorf("Paragraphs() = %d, want 3", got)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		corrected string
+		want      []string // Parts of the written body
+		unwanted  []string
+	}{
+		{
+			name:      "keeps the runs",
+			body:      `<w:p><w:r><w:t xml:space="preserve">I has </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>a</w:t></w:r><w:r><w:t xml:space="preserve"> apple.</w:t></w:r></w:p>`,
+			corrected: "I have an apple.",
+			want:      []string{`<w:t xml:space="preserve">I have </w:t>`, `<w:rPr><w:b/></w:rPr><w:t>an</w:t>`, `<w:t xml:space="preserve"> apple.</w:t>`},
+		},
+		{
+			name:      "preserves added spaces",
+			body:      `<w:p><w:r><w:t>Hello</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>world</w:t></w:r></w:p>`,
+			corrected: "Hello world",
+			want:      []string{`<w:t xml:space="preserve">Hello </w:t>`, `<w:rPr><w:i/></w:rPr><w:t>world</w:t>`},
+		},
+		{
+			name:      "keeps tabs and paragraphs",
+			body:      `<w:p><w:r><w:t>Nmae:</w:t><w:tab/><w:t>Tom</w:t></w:r></w:p><w:p><w:r><w:t>Its fine.</w:t></w:r></w:p>`,
+			corrected: "Name: Tom It's fine.",
+			want:      []string{`<w:t>Name:</w:t><w:tab/><w:t>Tom</w:t>`, `<w:t>It&#39;s fine.</w:t>`},
+		},
+		{
+			name:      "escapes text",
+			body:      `<w:p><w:r><w:t>Tom and Jerry</w:t></w:r></w:p>`,
+			corrected: "Tom & Jerry",
+			want:      []string{`<w:t>Tom &amp; Jerry</w:t>`},
+		},
+		{
+			name:      "leaves empty paragraphs alone",
+			body:      `<w:p><w:r><w:t xml:space="preserve"> </w:t></w:r></w:p><w:p><w:r><w:t>Teh end</w:t></w:r></w:p>`,
+			corrected: "The end",
+			want:      []string{`<w:t xml:space="preserve"> </w:t>`, `<w:t>The end</w:t>`},
+		},
+		{
+			name:      "fills empty elements",
+			body:      `<w:p><w:r><w:t/></w:r><w:r><w:t>end</w:t></w:r></w:p>`,
+			corrected: "The end",
+			want:      []string{`<w:t>The end</w:t>`},
+			unwanted:  []string{`</w:t></w:t>`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := newDocx(t, tt.body)
+			d := readDocx(t, data)
+			d.Replace(tt.corrected)
+
+			var out bytes.Buffer
+			if err := d.Write(&out); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			body := readPart(t, out.Bytes(), documentPath)
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body = %s, want it to contain %s", body, want)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body = %s, want no %s", body, unwanted)
+				}
+			}
+			if got := readPart(t, out.Bytes(), "[Content_Types].xml"); got != contentTypes {
+				t.Errorf("[Content_Types].xml = %s, want it copied", got)
+			}
+			// The copy reads back with the replaced text, tabs and paragraphs kept
+			if got, want := readDocx(t, out.Bytes()).Text(), strings.ReplaceAll(tt.corrected, "Name: Tom It's", "Name:\tTom\n\nIt's"); got != want {
+				t.Errorf("Text() of the copy = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestReplaceUnchanged(t *testing.T) {
+	data := newDocx(t, `<w:p><w:r><w:t>I have an apple.</w:t></w:r></w:p>`)
+	d := readDocx(t, data)
+	d.Replace(d.Text())
+
+	var out bytes.Buffer
+	if err := d.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readPart(t, out.Bytes(), documentPath), readPart(t, data, documentPath); got != want {
+		t.Errorf("body = %s, want it unchanged: %s", got, want)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	if _, err := Read(strings.NewReader("not a zip"), 9); err == nil || !strings.Contains(err.Error(), "not a Word document") {
+		t.Errorf("Read() error = %v, want not a Word document", err)
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	if _, err := archive.Create("content.xml"); err != nil {
+		t.Fatal(err)
+	}
+	archive.Close()
+	if _, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil || !strings.Contains(err.Error(), documentPath) {
+		t.Errorf("Read() error = %v, want %s missing", err, documentPath)
+	}
+}
@@ -0,0 +1,156 @@
+package estimate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// PromptOverhead is the approximate number of tokens the instructions add to each request
+const PromptOverhead = 150
+
+// piecePattern splits text the way BPE tokenizers pre-tokenize it: contractions, words with
+// their leading space, short digit runs, punctuation runs and whitespace
+var piecePattern = regexp.MustCompile(`'(?:s|t|re|ve|m|ll|d)| ?\p{L}+| ?\p{N}{1,3}| ?[^\s\p{L}\p{N}]+|\s+`)
+
+// Price is the cost of a model in US dollars per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// prices maps model name prefixes to their list price. The longest matching prefix wins.
+var prices = map[string]Price{
+	"gpt-4o":            {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.60},
+	"gpt-4.1":           {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":      {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":      {Input: 0.10, Output: 0.40},
+	"gpt-4-turbo":       {Input: 10.00, Output: 30.00},
+	"gpt-4":             {Input: 30.00, Output: 60.00},
+	"gpt-3.5-turbo":     {Input: 0.50, Output: 1.50},
+	"claude-3-5-sonnet": {Input: 3.00, Output: 15.00},
+	"claude-3-7-sonnet": {Input: 3.00, Output: 15.00},
+	"claude-sonnet-4":   {Input: 3.00, Output: 15.00},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4.00},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+	"claude-3-opus":     {Input: 15.00, Output: 75.00},
+	"claude-opus-4":     {Input: 15.00, Output: 75.00},
+}
+
+// Estimate is the expected size and cost of a request
+type Estimate struct {
+	InputTokens  int
+	OutputTokens int
+	Cost         float64 // US dollars, zero if the model's price is unknown
+	Priced       bool
+}
+
+// Tokens approximates the number of tokens in text without calling the API. English prose
+// comes out within about 10% of OpenAI's and Anthropic's tokenizers.
+func Tokens(text string) int {
+	count := 0
+	for _, piece := range piecePattern.FindAllString(text, -1) {
+		count += pieceTokens(piece)
+	}
+	return count
+}
+
+func pieceTokens(piece string) int {
+	if strings.TrimSpace(piece) == "" {
+		return 1
+	}
+	if utf8.RuneCountInString(piece) != len(piece) {
+		// Non-Latin scripts take roughly one token per character or two
+		return (len(piece) + 2) / 3
+	}
+	// Common words are a single token; longer ones split into pieces of about six letters
+	return (len(piece) + 5) / 6
+}
+
+// LookupPrice returns the price of model, if known
+func LookupPrice(model string) (Price, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	prefixes := make([]string, 0, len(prices))
+	for prefix := range prices {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return prices[prefix], true
+		}
+	}
+	return Price{}, false
+}
+
+// ForCorrection estimates a request that sends text with instructions and gets back a text of
+// about the same length
+func ForCorrection(model, text string) Estimate {
+	tokens := Tokens(text)
+	e := Estimate{
+		InputTokens:  tokens + PromptOverhead,
+		OutputTokens: tokens,
+	}
+	if price, ok := LookupPrice(model); ok {
+		e.Cost = (float64(e.InputTokens)*price.Input + float64(e.OutputTokens)*price.Output) / 1e6
+		e.Priced = true
+	}
+	return e
+}
+
+// Responses are assumed to come at slowTokensPerSecond at least, and the first scaleAfterTokens of
+// a text to fit in any timeout
+const (
+	slowTokensPerSecond = 20
+	scaleAfterTokens    = 250
+)
+
+// Timeout lengthens timeout by the time a response as long as text may take to generate, so a
+// long document isn't held to the time of a sentence. Texts of up to about a thousand characters
+// keep timeout.
+func Timeout(timeout time.Duration, text string) time.Duration {
+	extra := Tokens(text) - scaleAfterTokens
+	if extra <= 0 {
+		return timeout
+	}
+	return timeout + time.Duration(extra)*time.Second/slowTokensPerSecond
+}
+
+// String formats the estimate for the status line, e.g. "≈1,240 tokens, est. $0.004"
+func (e Estimate) String() string {
+	s := fmt.Sprintf("≈%s tokens", groupThousands(e.InputTokens))
+	if e.Priced {
+		s += ", est. " + formatCost(e.Cost)
+	}
+	return s
+}
+
+func formatCost(cost float64) string {
+	switch {
+	case cost < 0.001:
+		return "<$0.001"
+	case cost < 1:
+		return fmt.Sprintf("$%.3f", cost)
+	default:
+		return fmt.Sprintf("$%.2f", cost)
+	}
+}
+
+func groupThousands(n int) string {
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
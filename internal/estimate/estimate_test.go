@@ -0,0 +1,88 @@
+package estimate
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: "empty", text: "", want: 0},
+		{name: "sentence", text: "The quick brown fox jumps over the lazy dog.", want: 10},
+		{name: "contraction", text: "It's", want: 2},
+		{name: "long word", text: "internationalization", want: 4},
+		{name: "numbers", text: "12345", want: 2},
+		{name: "non-latin", text: "你好", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokens(tt.text); got != tt.want {
+				t.Errorf("Tokens(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupPrice(t *testing.T) {
+	tests := []struct {
+		model  string
+		want   Price
+		wantOK bool
+	}{
+		{model: "gpt-4o", want: Price{Input: 2.50, Output: 10.00}, wantOK: true},
+		{model: "gpt-4o-mini", want: Price{Input: 0.15, Output: 0.60}, wantOK: true},
+		{model: "gpt-4o-2024-08-06", want: Price{Input: 2.50, Output: 10.00}, wantOK: true},
+		{model: "claude-3-5-sonnet-20241022", want: Price{Input: 3.00, Output: 15.00}, wantOK: true},
+		{model: "llama3", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := LookupPrice(tt.model)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("LookupPrice(%q) = %+v, %v, want %+v, %v", tt.model, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestForCorrection(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+
+	e := ForCorrection("gpt-4o", text)
+	if e.InputTokens != e.OutputTokens+PromptOverhead {
+		t.Errorf("InputTokens = %d, want output tokens plus overhead (%d)", e.InputTokens, e.OutputTokens+PromptOverhead)
+	}
+	if !e.Priced || e.Cost <= 0 {
+		t.Fatalf("ForCorrection() = %+v, want a priced estimate", e)
+	}
+	if got := e.String(); got != "≈1,151 tokens, est. $0.013" {
+		t.Errorf("String() = %q", got)
+	}
+
+	unknown := ForCorrection("llama3", "Hello")
+	if unknown.Priced {
+		t.Errorf("ForCorrection() with unknown model should not be priced")
+	}
+	if got := unknown.String(); got != "≈151 tokens" {
+		t.Errorf("String() = %q, want %q", got, "≈151 tokens")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	if got := Timeout(30*time.Second, "I has a apple."); got != 30*time.Second {
+		t.Errorf("Timeout() = %v for a sentence, want it unchanged", got)
+	}
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 1000)
+	got := Timeout(30*time.Second, long)
+	want := 30*time.Second + time.Duration(Tokens(long)-scaleAfterTokens)*time.Second/slowTokensPerSecond
+	if got != want || got < 8*time.Minute {
+		t.Errorf("Timeout() = %v for %d tokens, want %v", got, Tokens(long), want)
+	}
+}
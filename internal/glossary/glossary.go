@@ -0,0 +1,139 @@
+package glossary
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the name of the glossary file in ~/.grammr
+const DefaultFile = "glossary.yaml"
+
+// Term is a preferred term together with the variants that should be replaced by it.
+// A term without variants only enforces its spelling and capitalization.
+type Term struct {
+	Preferred string   `yaml:"preferred"`
+	Avoid     []string `yaml:"avoid"`
+}
+
+// Glossary is a list of terms to enforce in corrections and translations
+type Glossary struct {
+	Terms []Term `yaml:"terms"`
+}
+
+// Violation is a place in a text that doesn't follow the glossary
+type Violation struct {
+	Start     int // Byte offset of the offending text
+	End       int
+	Found     string
+	Preferred string
+}
+
+// Load reads a glossary from a YAML file. A missing file yields an empty glossary.
+func Load(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Glossary{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read glossary: %w", err)
+	}
+
+	var g Glossary
+	if err := yaml.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("failed to parse glossary %s: %w", path, err)
+	}
+
+	// Drop incomplete entries
+	terms := g.Terms[:0]
+	for _, term := range g.Terms {
+		term.Preferred = strings.TrimSpace(term.Preferred)
+		if term.Preferred != "" {
+			terms = append(terms, term)
+		}
+	}
+	g.Terms = terms
+	return &g, nil
+}
+
+// Empty reports whether the glossary has no terms
+func (g *Glossary) Empty() bool {
+	return g == nil || len(g.Terms) == 0
+}
+
+// Instruction describes the glossary for a prompt, or returns an empty string if there are no terms
+func (g *Glossary) Instruction() string {
+	if g.Empty() {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Follow this glossary:")
+	for _, term := range g.Terms {
+		if len(term.Avoid) == 0 {
+			fmt.Fprintf(&b, "\n- Write %q exactly like this.", term.Preferred)
+			continue
+		}
+		quoted := make([]string, len(term.Avoid))
+		for i, avoid := range term.Avoid {
+			quoted[i] = fmt.Sprintf("%q", avoid)
+		}
+		fmt.Fprintf(&b, "\n- Use %q, not %s.", term.Preferred, strings.Join(quoted, " or "))
+	}
+	return b.String()
+}
+
+// Check finds variants to avoid and miscapitalized preferred terms in text
+func (g *Glossary) Check(text string) []Violation {
+	if g.Empty() {
+		return nil
+	}
+
+	var violations []Violation
+	for _, term := range g.Terms {
+		for _, avoid := range term.Avoid {
+			for _, loc := range termPattern(avoid).FindAllStringIndex(text, -1) {
+				violations = append(violations, Violation{Start: loc[0], End: loc[1], Found: text[loc[0]:loc[1]], Preferred: term.Preferred})
+			}
+		}
+		for _, loc := range termPattern(term.Preferred).FindAllStringIndex(text, -1) {
+			found := text[loc[0]:loc[1]]
+			if !matchesPreferred(found, term.Preferred) {
+				violations = append(violations, Violation{Start: loc[0], End: loc[1], Found: found, Preferred: term.Preferred})
+			}
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Start < violations[j].Start
+	})
+	return violations
+}
+
+// termPattern matches a term as a whole word, ignoring case and the amount of whitespace between words
+func termPattern(term string) *regexp.Regexp {
+	words := strings.Fields(term)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return regexp.MustCompile(`(?i)\b` + strings.Join(words, `\s+`) + `\b`)
+}
+
+// matchesPreferred reports whether found is written like preferred. A lowercase term may still
+// be capitalized, e.g. at the start of a sentence.
+func matchesPreferred(found, preferred string) bool {
+	found = strings.Join(strings.Fields(found), " ")
+	preferred = strings.Join(strings.Fields(preferred), " ")
+	if found == preferred {
+		return true
+	}
+	r, size := utf8.DecodeRuneInString(preferred)
+	return unicode.IsLower(r) && found == string(unicode.ToUpper(r))+preferred[size:]
+}
@@ -0,0 +1,95 @@
+package glossary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	g, err := Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load() missing file error = %v", err)
+	}
+	if !g.Empty() {
+		t.Fatal("a missing glossary file should give an empty glossary")
+	}
+
+	path := filepath.Join(dir, DefaultFile)
+	content := `terms:
+  - preferred: sign in
+    avoid: [login, log-in]
+  - preferred: Kubernetes
+  - preferred: "  "
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	g, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(g.Terms) != 2 {
+		t.Fatalf("Load() terms = %+v, want 2 (blank entries dropped)", g.Terms)
+	}
+
+	if err := os.WriteFile(path, []byte("terms: [oops"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load() should fail on invalid YAML")
+	}
+}
+
+func TestInstruction(t *testing.T) {
+	var empty *Glossary
+	if empty.Instruction() != "" {
+		t.Fatal("a nil glossary should have no instruction")
+	}
+
+	g := &Glossary{Terms: []Term{
+		{Preferred: "sign in", Avoid: []string{"login", "log-in"}},
+		{Preferred: "Kubernetes"},
+	}}
+	got := g.Instruction()
+	for _, want := range []string{`Use "sign in", not "login" or "log-in".`, `Write "Kubernetes" exactly like this.`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Instruction() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	g := &Glossary{Terms: []Term{
+		{Preferred: "sign in", Avoid: []string{"login", "log-in"}},
+		{Preferred: "Kubernetes"},
+	}}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "clean", text: "Sign in to deploy on Kubernetes. Then sign in again.", want: nil},
+		{name: "avoided terms", text: "Use the login page or Log-in later.", want: []string{"login", "Log-in"}},
+		{name: "capitalization", text: "Deploy to kubernetes or KUBERNETES.", want: []string{"kubernetes", "KUBERNETES"}},
+		{name: "whole words only", text: "The loginservice and Kubernetesish things.", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := g.Check(tt.text)
+			if len(violations) != len(tt.want) {
+				t.Fatalf("Check() = %+v, want %q", violations, tt.want)
+			}
+			for i, v := range violations {
+				if v.Found != tt.want[i] || tt.text[v.Start:v.End] != v.Found {
+					t.Errorf("violation %d = %+v, want %q", i, v, tt.want[i])
+				}
+			}
+		})
+	}
+}
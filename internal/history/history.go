@@ -0,0 +1,161 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFile is the name of the history file in ~/.grammr
+	DefaultFile = "history.json"
+	// DefaultSize is how many corrections are kept when no size is configured
+	DefaultSize = 200
+
+	// dirPerm is used when the history is the first file in its directory. The file itself is
+	// created readable by the user only, like the config.
+	dirPerm os.FileMode = 0700
+)
+
+// Entry is one correction, with its translation if there was one
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Original    string    `json:"original"`
+	Corrected   string    `json:"corrected"`
+	Translation string    `json:"translation,omitempty"`
+	Style       string    `json:"style,omitempty"`
+}
+
+// History keeps the latest corrections in a file, so they survive restarts. A nil History keeps
+// nothing.
+type History struct {
+	mu      sync.Mutex
+	path    string
+	size    int
+	entries []Entry // Oldest first
+}
+
+// Open reads the history file at path, keeping at most size entries. A missing file yields an
+// empty history.
+func Open(path string, size int) (*History, error) {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	h := &History{path: path, size: size}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	h.trim()
+	return h, nil
+}
+
+// Add records a correction. Correcting the same text into the same result again moves the
+// existing entry to the top instead of adding another one.
+func (h *History) Add(entry Entry) error {
+	if h == nil || entry.Original == "" {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if i := h.find(entry.Original, entry.Corrected); i >= 0 {
+		if entry.Translation == "" {
+			entry.Translation = h.entries[i].Translation
+		}
+		h.entries = append(h.entries[:i], h.entries[i+1:]...)
+	}
+	h.entries = append(h.entries, entry)
+	h.trim()
+	return h.save()
+}
+
+// SetTranslation stores the translation of the latest correction of original into corrected
+func (h *History) SetTranslation(original, corrected, translation string) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := h.find(original, corrected)
+	if i < 0 || h.entries[i].Translation == translation {
+		return nil
+	}
+	h.entries[i].Translation = translation
+	return h.save()
+}
+
+// Entries returns the corrections, newest first
+func (h *History) Entries() []Entry {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := make([]Entry, len(h.entries))
+	for i, entry := range h.entries {
+		entries[len(entries)-1-i] = entry
+	}
+	return entries
+}
+
+// find returns the index of the newest entry correcting original into corrected, or -1
+func (h *History) find(original, corrected string) int {
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].Original == original && h.entries[i].Corrected == corrected {
+			return i
+		}
+	}
+	return -1
+}
+
+// trim drops the oldest entries beyond the size
+func (h *History) trim() {
+	if extra := len(h.entries) - h.size; extra > 0 {
+		h.entries = append([]Entry(nil), h.entries[extra:]...)
+	}
+}
+
+// save writes the entries to a temporary file and renames it over the history file, so a crash
+// never leaves half a history behind
+func (h *History) save() error {
+	data, err := json.Marshal(h.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), dirPerm); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), ".history-*")
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenMissingFile(t *testing.T) {
+	h, err := Open(filepath.Join(t.TempDir(), DefaultFile), 0)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if entries := h.Entries(); len(entries) != 0 {
+		t.Fatalf("Entries() = %v, want none", entries)
+	}
+}
+
+func TestAddAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grammr", DefaultFile)
+	h, err := Open(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Add(Entry{Original: "I has a apple.", Corrected: "I have an apple.", Style: "casual"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := h.Add(Entry{Original: "I has a pear.", Corrected: "I have a pear."}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := h.SetTranslation("I has a apple.", "I have an apple.", "Tengo una manzana."); err != nil {
+		t.Fatalf("SetTranslation() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		t.Errorf("history file mode = %o, want it private", perm)
+	}
+
+	reopened, err := Open(path, 0)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	entries := reopened.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries() = %d entries, want 2", len(entries))
+	}
+	if entries[0].Original != "I has a pear." {
+		t.Errorf("newest entry = %q, want the last correction", entries[0].Original)
+	}
+	if entries[1].Translation != "Tengo una manzana." || entries[1].Style != "casual" {
+		t.Errorf("oldest entry = %+v, want its translation and style", entries[1])
+	}
+	if entries[1].Time.IsZero() {
+		t.Error("entry has no time")
+	}
+}
+
+func TestAddMovesRepeatedCorrectionToTop(t *testing.T) {
+	h, err := Open(filepath.Join(t.TempDir(), DefaultFile), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Add(Entry{Original: "a", Corrected: "A", Translation: "Á"})
+	h.Add(Entry{Original: "b", Corrected: "B"})
+	h.Add(Entry{Original: "a", Corrected: "A"})
+
+	entries := h.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries() = %v, want the repeated correction once", entries)
+	}
+	if entries[0].Original != "a" || entries[0].Translation != "Á" {
+		t.Errorf("newest entry = %+v, want the repeated correction with its translation", entries[0])
+	}
+}
+
+func TestSizeLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	h, err := Open(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, text := range []string{"one", "two", "three"} {
+		h.Add(Entry{Time: base.Add(time.Duration(i) * time.Minute), Original: text, Corrected: text})
+	}
+
+	entries := h.Entries()
+	if len(entries) != 2 || entries[0].Original != "three" || entries[1].Original != "two" {
+		t.Fatalf("Entries() = %v, want the two newest", entries)
+	}
+
+	// A smaller size drops old entries on open
+	smaller, err := Open(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := smaller.Entries(); len(entries) != 1 || entries[0].Original != "three" {
+		t.Fatalf("Entries() = %v, want only the newest", entries)
+	}
+}
+
+func TestNilHistory(t *testing.T) {
+	var h *History
+	if err := h.Add(Entry{Original: "a", Corrected: "A"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := h.SetTranslation("a", "A", "Á"); err != nil {
+		t.Fatalf("SetTranslation() error = %v", err)
+	}
+	if entries := h.Entries(); entries != nil {
+		t.Fatalf("Entries() = %v, want nil", entries)
+	}
+}
+
+func TestOpenCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, 0); err == nil {
+		t.Fatal("Open() error = nil, want a parse error")
+	}
+}
@@ -0,0 +1,15 @@
+package hotkey
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnsupported is returned by Listen where grammr can't register shortcuts itself
+var ErrUnsupported = errors.New("system-wide shortcuts aren't supported here")
+
+// Listen registers shortcut system-wide and calls onPress each time it is pressed, until ctx is
+// done. It fails when another app has taken the shortcut.
+func Listen(ctx context.Context, shortcut Shortcut, onPress func()) error {
+	return listen(ctx, shortcut, onPress)
+}
@@ -0,0 +1,191 @@
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/clipboard"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Shortcut
+		wantErr bool
+	}{
+		{text: "ctrl+alt+g", want: Shortcut{Mods: ModCtrl | ModAlt, Key: "g"}},
+		{text: "Cmd + Shift + F9", want: Shortcut{Mods: ModSuper | ModShift, Key: "f9"}},
+		{text: "option+1", want: Shortcut{Mods: ModAlt, Key: "1"}},
+		{text: "g", wantErr: true},
+		{text: "ctrl+f13", wantErr: true},
+		{text: "ctrl+enter", wantErr: true},
+		{text: "hyper+g", wantErr: true},
+		{text: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortcutString(t *testing.T) {
+	s := Shortcut{Mods: ModSuper | ModCtrl | ModShift, Key: "f1"}
+	if got := s.String(); got != "ctrl+shift+super+f1" {
+		t.Errorf("String() = %q", got)
+	}
+	if parsed, err := Parse(s.String()); err != nil || parsed != s {
+		t.Errorf("Parse(String()) = %+v, %v", parsed, err)
+	}
+}
+
+func TestNewKeyboard(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		env       map[string]string
+		installed []string
+		want      string // Tool sending the shortcuts, empty for an error
+	}{
+		{name: "macOS", goos: "darwin", want: "osascript"},
+		{name: "Windows", goos: "windows", want: "powershell"},
+		{
+			name:      "Wayland with wtype",
+			goos:      "linux",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: []string{"wtype", "xdotool"},
+			want:      "wtype",
+		},
+		{
+			name:      "XWayland with xdotool",
+			goos:      "linux",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			installed: []string{"xdotool"},
+			want:      "xdotool",
+		},
+		{name: "X11 without xdotool", goos: "linux", env: map[string]string{"DISPLAY": ":0"}},
+		{name: "headless", goos: "linux", installed: []string{"xdotool"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := newKeyboard(tt.goos,
+				func(name string) string { return tt.env[name] },
+				func(name string) bool {
+					for _, tool := range tt.installed {
+						if tool == name {
+							return true
+						}
+					}
+					return false
+				})
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("newKeyboard() = %+v, want an error", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newKeyboard() error = %v", err)
+			}
+			if got := keys.(commandKeyboard).copy[0]; got != tt.want {
+				t.Errorf("newKeyboard() uses %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeKeyboard copies selection to clip, and records what was pasted
+type fakeKeyboard struct {
+	clip      *clipboard.Memory
+	selection string
+	pasted    []string
+}
+
+func (k *fakeKeyboard) Copy() error {
+	if k.selection != "" {
+		k.clip.Text = k.selection
+	}
+	return nil
+}
+
+func (k *fakeKeyboard) Paste() error {
+	k.pasted = append(k.pasted, k.clip.Text)
+	return nil
+}
+
+func TestCorrectSelection(t *testing.T) {
+	copyWait = 10 * time.Millisecond
+	upper := func(ctx context.Context, text string) (string, error) {
+		return strings.ToUpper(strings.TrimSpace(text)), nil
+	}
+
+	tests := []struct {
+		name       string
+		clipboard  string
+		selection  string
+		correct    func(context.Context, string) (string, error)
+		wantClip   string
+		wantPasted []string
+		wantErr    bool
+	}{
+		{
+			name:       "selection is replaced",
+			clipboard:  "old",
+			selection:  "hello there\n",
+			correct:    upper,
+			wantClip:   "HELLO THERE\n",
+			wantPasted: []string{"HELLO THERE\n"},
+		},
+		{
+			name:      "clipboard is corrected without a selection",
+			clipboard: "from the clipboard",
+			correct:   upper,
+			wantClip:  "FROM THE CLIPBOARD",
+		},
+		{
+			name:      "nothing to correct",
+			clipboard: " ",
+			correct:   upper,
+			wantClip:  " ",
+			wantErr:   true,
+		},
+		{
+			name:      "failed correction restores the clipboard",
+			clipboard: "old",
+			selection: "hello",
+			correct: func(ctx context.Context, text string) (string, error) {
+				return "", errors.New("offline")
+			},
+			wantClip: "old",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clip := &clipboard.Memory{Text: tt.clipboard}
+			keys := &fakeKeyboard{clip: clip, selection: tt.selection}
+			pasted, err := CorrectSelection(context.Background(), clip, keys, tt.correct)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CorrectSelection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if pasted != (len(tt.wantPasted) > 0) {
+				t.Errorf("CorrectSelection() pasted = %v", pasted)
+			}
+			if clip.Text != tt.wantClip {
+				t.Errorf("clipboard = %q, want %q", clip.Text, tt.wantClip)
+			}
+			if strings.Join(keys.pasted, "|") != strings.Join(tt.wantPasted, "|") {
+				t.Errorf("pasted %q, want %q", keys.pasted, tt.wantPasted)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package hotkey
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Keyboard sends the copy and paste shortcuts to the app in the foreground
+type Keyboard interface {
+	// Copy copies the selection of the foreground app to the clipboard
+	Copy() error
+	// Paste pastes the clipboard over the selection of the foreground app
+	Paste() error
+}
+
+// commandKeyboard sends the shortcuts by running a tool
+type commandKeyboard struct {
+	copy  []string
+	paste []string
+}
+
+func (k commandKeyboard) Copy() error {
+	return run(k.copy)
+}
+
+func (k commandKeyboard) Paste() error {
+	return run(k.paste)
+}
+
+func run(command []string) error {
+	out, err := exec.Command(command[0], command[1:]...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s failed: %s", command[0], msg)
+		}
+		return fmt.Errorf("%s failed: %w", command[0], err)
+	}
+	return nil
+}
+
+// NewKeyboard returns the keyboard of this machine, or an error saying what to install
+func NewKeyboard() (Keyboard, error) {
+	return newKeyboard(runtime.GOOS, os.Getenv, func(name string) bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	})
+}
+
+func newKeyboard(goos string, getenv func(string) string, installed func(string) bool) (Keyboard, error) {
+	switch goos {
+	case "darwin":
+		// Needs the terminal to be allowed in System Settings > Privacy & Security > Accessibility
+		return commandKeyboard{
+			copy:  []string{"osascript", "-e", `tell application "System Events" to keystroke "c" using command down`},
+			paste: []string{"osascript", "-e", `tell application "System Events" to keystroke "v" using command down`},
+		}, nil
+	case "windows":
+		return commandKeyboard{
+			copy:  []string{"powershell", "-NoProfile", "-Command", `(New-Object -ComObject WScript.Shell).SendKeys('^c')`},
+			paste: []string{"powershell", "-NoProfile", "-Command", `(New-Object -ComObject WScript.Shell).SendKeys('^v')`},
+		}, nil
+	}
+
+	if getenv("WAYLAND_DISPLAY") != "" && installed("wtype") {
+		return commandKeyboard{
+			copy:  []string{"wtype", "-M", "ctrl", "c", "-m", "ctrl"},
+			paste: []string{"wtype", "-M", "ctrl", "v", "-m", "ctrl"},
+		}, nil
+	}
+	if getenv("DISPLAY") != "" && installed("xdotool") {
+		return commandKeyboard{
+			copy:  []string{"xdotool", "key", "--clearmodifiers", "ctrl+c"},
+			paste: []string{"xdotool", "key", "--clearmodifiers", "ctrl+v"},
+		}, nil
+	}
+	switch {
+	case getenv("WAYLAND_DISPLAY") != "":
+		return nil, fmt.Errorf("install wtype to copy and paste the selection")
+	case getenv("DISPLAY") != "":
+		return nil, fmt.Errorf("install xdotool to copy and paste the selection")
+	}
+	return nil, fmt.Errorf("no display to copy the selection from")
+}
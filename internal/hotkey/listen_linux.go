@@ -0,0 +1,98 @@
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// listen grabs the shortcut on the X11 root window. Wayland compositors don't let apps grab keys,
+// so there it needs XWayland apps in focus or a shortcut set in the compositor.
+func listen(ctx context.Context, shortcut Shortcut, onPress func()) error {
+	if os.Getenv("DISPLAY") == "" {
+		return fmt.Errorf("%w without an X11 display", ErrUnsupported)
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the X server: %w", err)
+	}
+	defer conn.Close()
+
+	setup := xproto.Setup(conn)
+	root := setup.DefaultScreen(conn).Root
+	keycode, err := keycodeOf(conn, setup, keysym(shortcut.Key))
+	if err != nil {
+		return err
+	}
+	mods := x11Modifiers(shortcut.Mods)
+	// Grab the shortcut with Caps Lock and Num Lock on too, or it only works with them off
+	for _, locks := range []uint16{0, xproto.ModMaskLock, xproto.ModMask2, xproto.ModMaskLock | xproto.ModMask2} {
+		err := xproto.GrabKeyChecked(conn, true, root, mods|locks, keycode, xproto.GrabModeAsync, xproto.GrabModeAsync).Check()
+		if err != nil {
+			return fmt.Errorf("%s is taken by another app: %v", shortcut, err)
+		}
+	}
+
+	stop := context.AfterFunc(ctx, conn.Close)
+	defer stop()
+	for {
+		event, err := conn.WaitForEvent()
+		if event == nil && err == nil {
+			// The connection was closed
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.New("lost the connection to the X server")
+		}
+		if _, ok := event.(xproto.KeyPressEvent); ok {
+			onPress()
+		}
+	}
+}
+
+// keysym returns the X11 keysym of a key of a shortcut
+func keysym(key string) xproto.Keysym {
+	if n := functionKey(key); n > 0 {
+		return xproto.Keysym(0xffbe + n - 1) // XK_F1
+	}
+	return xproto.Keysym(key[0]) // Letters and digits are their ASCII codes
+}
+
+// keycodeOf returns the key of the keyboard that types sym
+func keycodeOf(conn *xgb.Conn, setup *xproto.SetupInfo, sym xproto.Keysym) (xproto.Keycode, error) {
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	mapping, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the keyboard mapping: %w", err)
+	}
+	perKey := int(mapping.KeysymsPerKeycode)
+	for i := 0; i < int(count); i++ {
+		for j := 0; j < perKey; j++ {
+			if mapping.Keysyms[i*perKey+j] == sym {
+				return setup.MinKeycode + xproto.Keycode(i), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no key on this keyboard types %q", rune(sym))
+}
+
+func x11Modifiers(mods Modifier) uint16 {
+	var mask uint16
+	if mods&ModCtrl != 0 {
+		mask |= xproto.ModMaskControl
+	}
+	if mods&ModAlt != 0 {
+		mask |= xproto.ModMask1
+	}
+	if mods&ModShift != 0 {
+		mask |= xproto.ModMaskShift
+	}
+	if mods&ModSuper != 0 {
+		mask |= xproto.ModMask4
+	}
+	return mask
+}
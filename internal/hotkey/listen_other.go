@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package hotkey
+
+import "context"
+
+// listen can't register shortcuts without cgo on macOS; bind grammr hotkey --once to a shortcut
+// with the Shortcuts app instead
+func listen(ctx context.Context, shortcut Shortcut, onPress func()) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,102 @@
+package hotkey
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32            = windows.NewLazySystemDLL("user32.dll")
+	registerHotKey    = user32.NewProc("RegisterHotKey")
+	unregisterHotKey  = user32.NewProc("UnregisterHotKey")
+	getMessage        = user32.NewProc("GetMessageW")
+	postThreadMessage = user32.NewProc("PostThreadMessageW")
+)
+
+const (
+	modAlt      = 0x0001
+	modControl  = 0x0002
+	modShift    = 0x0004
+	modWin      = 0x0008
+	modNoRepeat = 0x4000
+	wmHotkey    = 0x0312
+	wmQuit      = 0x0012
+	hotkeyID    = 1
+)
+
+// msg is the MSG structure of the Windows API
+type msg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+}
+
+// listen registers the shortcut with RegisterHotKey and reads WM_HOTKEY messages. Both must
+// happen on the same thread.
+func listen(ctx context.Context, shortcut Shortcut, onPress func()) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if ok, _, err := registerHotKey.Call(0, hotkeyID, uintptr(windowsModifiers(shortcut.Mods)|modNoRepeat), uintptr(virtualKey(shortcut.Key))); ok == 0 {
+		return fmt.Errorf("%s is taken by another app: %v", shortcut, err)
+	}
+	defer unregisterHotKey.Call(0, hotkeyID)
+
+	thread := windows.GetCurrentThreadId()
+	stop := context.AfterFunc(ctx, func() {
+		postThreadMessage.Call(uintptr(thread), wmQuit, 0, 0)
+	})
+	defer stop()
+
+	var m msg
+	for {
+		// GetMessage returns 0 for WM_QUIT and -1 on errors
+		ret, _, err := getMessage.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		switch int32(ret) {
+		case 0:
+			return nil
+		case -1:
+			return fmt.Errorf("failed to read messages: %v", err)
+		}
+		if m.message == wmHotkey {
+			onPress()
+		}
+	}
+}
+
+// virtualKey returns the virtual-key code of a key of a shortcut
+func virtualKey(key string) uint32 {
+	if n := functionKey(key); n > 0 {
+		return 0x70 + uint32(n) - 1 // VK_F1
+	}
+	// Letters are their uppercase ASCII codes, digits their ASCII codes
+	c := key[0]
+	if 'a' <= c && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	return uint32(c)
+}
+
+func windowsModifiers(mods Modifier) uint32 {
+	var flags uint32
+	if mods&ModCtrl != 0 {
+		flags |= modControl
+	}
+	if mods&ModAlt != 0 {
+		flags |= modAlt
+	}
+	if mods&ModShift != 0 {
+		flags |= modShift
+	}
+	if mods&ModSuper != 0 {
+		flags |= modWin
+	}
+	return flags
+}
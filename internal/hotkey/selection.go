@@ -0,0 +1,75 @@
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/clipboard"
+)
+
+var (
+	// copyWait is how long the copied selection may take to reach the clipboard
+	copyWait = 500 * time.Millisecond
+	// copyPoll is how often the clipboard is checked for it
+	copyPoll = 25 * time.Millisecond
+)
+
+// CorrectSelection corrects the selection of the foreground app and pastes the correction over
+// it. Without a selection, the text on the clipboard is corrected and left there to paste. It
+// reports whether the correction was pasted.
+func CorrectSelection(ctx context.Context, clip clipboard.Clipboard, keys Keyboard, correct func(context.Context, string) (string, error)) (bool, error) {
+	previous, _ := clip.Paste()
+	// Clear the clipboard so a selection equal to it is still noticed
+	_ = clip.Copy("")
+	if err := keys.Copy(); err != nil {
+		_ = clip.Copy(previous)
+		return false, fmt.Errorf("failed to copy the selection: %w", err)
+	}
+
+	text, selected := waitForCopy(ctx, clip)
+	if !selected {
+		_ = clip.Copy(previous)
+		text = previous
+	}
+	if strings.TrimSpace(text) == "" {
+		return false, errors.New("nothing to correct: select some text or copy it first")
+	}
+
+	corrected, err := correct(ctx, text)
+	if err != nil {
+		if selected {
+			_ = clip.Copy(previous)
+		}
+		return false, err
+	}
+	// Corrections lose trailing whitespace, which would join the next line to the pasted text
+	corrected += text[len(strings.TrimRight(text, " \t\r\n")):]
+	if err := clip.Copy(corrected); err != nil {
+		return false, fmt.Errorf("failed to copy the correction: %w", err)
+	}
+	if !selected {
+		return false, nil
+	}
+	if err := keys.Paste(); err != nil {
+		return false, fmt.Errorf("failed to paste the correction, it is on the clipboard: %w", err)
+	}
+	return true, nil
+}
+
+// waitForCopy returns the text the copy shortcut put on the clipboard, and false when nothing
+// arrived in time
+func waitForCopy(ctx context.Context, clip clipboard.Clipboard) (string, bool) {
+	deadline := time.Now().Add(copyWait)
+	for {
+		if text, err := clip.Paste(); err == nil && text != "" {
+			return text, true
+		}
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			return "", false
+		}
+		time.Sleep(copyPoll)
+	}
+}
@@ -0,0 +1,97 @@
+package hotkey
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Modifier is a set of modifier keys
+type Modifier uint8
+
+// Modifier keys of a shortcut
+const (
+	ModCtrl Modifier = 1 << iota
+	ModAlt
+	ModShift
+	ModSuper // Cmd on macOS, Windows key on Windows
+)
+
+// modifierNames are the names of modifiers in shortcuts, in the order they are written
+var modifierNames = []struct {
+	mod   Modifier
+	names []string
+}{
+	{ModCtrl, []string{"ctrl", "control"}},
+	{ModAlt, []string{"alt", "option", "opt"}},
+	{ModShift, []string{"shift"}},
+	{ModSuper, []string{"super", "cmd", "command", "win", "meta"}},
+}
+
+// Shortcut is a key pressed with modifiers, like ctrl+alt+g
+type Shortcut struct {
+	Mods Modifier
+	Key  string // A lowercase letter, a digit, or f1 to f12
+}
+
+// Parse reads a shortcut like "ctrl+alt+g". It needs at least one modifier, so typing the key
+// alone keeps working in other apps.
+func Parse(text string) (Shortcut, error) {
+	var s Shortcut
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(text, " ", "")), "+")
+	for i, part := range parts {
+		if i == len(parts)-1 {
+			if !validKey(part) {
+				return Shortcut{}, fmt.Errorf("invalid shortcut %q: the last key must be a letter, a digit or f1 to f12", text)
+			}
+			s.Key = part
+			break
+		}
+		mod, ok := modifier(part)
+		if !ok {
+			return Shortcut{}, fmt.Errorf("invalid shortcut %q: unknown modifier %q", text, part)
+		}
+		s.Mods |= mod
+	}
+	if s.Mods == 0 {
+		return Shortcut{}, fmt.Errorf("invalid shortcut %q: add a modifier such as ctrl or alt", text)
+	}
+	return s, nil
+}
+
+// String writes the shortcut the way Parse reads it
+func (s Shortcut) String() string {
+	var parts []string
+	for _, m := range modifierNames {
+		if s.Mods&m.mod != 0 {
+			parts = append(parts, m.names[0])
+		}
+	}
+	return strings.Join(append(parts, s.Key), "+")
+}
+
+func modifier(name string) (Modifier, bool) {
+	for _, m := range modifierNames {
+		for _, n := range m.names {
+			if n == name {
+				return m.mod, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func validKey(key string) bool {
+	if len(key) == 1 {
+		return ('a' <= key[0] && key[0] <= 'z') || ('0' <= key[0] && key[0] <= '9')
+	}
+	return functionKey(key) > 0
+}
+
+// functionKey returns n for the key fn, from 1 to 12, or 0 for other keys
+func functionKey(key string) int {
+	var n int
+	if _, err := fmt.Sscanf(key, "f%d", &n); err != nil || fmt.Sprintf("f%d", n) != key || n < 1 || n > 12 {
+		return 0
+	}
+	return n
+}
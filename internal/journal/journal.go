@@ -0,0 +1,247 @@
+package journal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// timeFormat is the format of the heading of an entry, in local time
+	timeFormat = "2006-01-02 15:04:05"
+
+	// filePerm keeps the journal readable by the user only, like the history
+	filePerm os.FileMode = 0600
+	// dirPerm is used when the journal is the first file in its directory
+	dirPerm os.FileMode = 0700
+)
+
+// Entry is one correction in the journal
+type Entry struct {
+	Time      time.Time
+	Original  string
+	Corrected string
+	Changes   []Change
+}
+
+// Change is one change a correction made
+type Change struct {
+	Category string // See corrector.ChangeCategories
+	Before   string // Text the change removed, empty for an addition
+	After    string // Text the change added, empty for a removal
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Before == "":
+		return "added " + c.After
+	case c.After == "":
+		return "removed " + c.Before
+	}
+	return c.Before + " → " + c.After
+}
+
+// Append adds entry to the end of the Markdown journal at path, creating it if needed
+func Append(path string, entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	_, err = file.WriteString(format(entry))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// format writes entry as a Markdown section
+func format(entry Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", entry.Time.Local().Format(timeFormat))
+	fmt.Fprintf(&b, "**Original**\n\n%s\n\n", quote(entry.Original))
+	fmt.Fprintf(&b, "**Corrected**\n\n%s\n\n", quote(entry.Corrected))
+	var changes []string
+	for _, change := range entry.Changes {
+		change.Before = oneLine(change.Before)
+		change.After = oneLine(change.After)
+		if change.Before == "" && change.After == "" {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("- %s: %s\n", change.Category, change))
+	}
+	if len(changes) > 0 {
+		fmt.Fprintf(&b, "**Changes**\n\n%s\n", strings.Join(changes, ""))
+	}
+	return b.String()
+}
+
+// quote quotes text as a Markdown blockquote
+func quote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// oneLine collapses the whitespace of text, so a change fits on its list item
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Read returns the entries of the journal at path, oldest first. A missing journal has none.
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	var current *Entry
+	var section string
+	var quoted []string
+	// flush stores the quoted lines read so far in the section they belong to
+	flush := func() {
+		if current == nil || quoted == nil {
+			return
+		}
+		switch section {
+		case "Original":
+			current.Original = strings.Join(quoted, "\n")
+		case "Corrected":
+			current.Corrected = strings.Join(quoted, "\n")
+		}
+		quoted = nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			flush()
+			t, err := time.ParseInLocation(timeFormat, strings.TrimPrefix(line, "## "), time.Local)
+			if err != nil {
+				// A heading the user added, not an entry
+				current, section = nil, ""
+				continue
+			}
+			entries = append(entries, Entry{Time: t})
+			current, section = &entries[len(entries)-1], ""
+		case current == nil:
+		case line == "**Original**" || line == "**Corrected**" || line == "**Changes**":
+			flush()
+			section = strings.Trim(line, "*")
+		case line == ">" || strings.HasPrefix(line, "> "):
+			quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(line, ">"), " "))
+		case section == "Changes" && strings.HasPrefix(line, "- "):
+			if change, ok := parseChange(strings.TrimPrefix(line, "- ")); ok {
+				current.Changes = append(current.Changes, change)
+			}
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// parseChange reads a change as written by Change.String, after its category
+func parseChange(line string) (Change, bool) {
+	category, text, ok := strings.Cut(line, ": ")
+	if !ok {
+		return Change{}, false
+	}
+	change := Change{Category: category}
+	if before, after, ok := strings.Cut(text, " → "); ok {
+		change.Before, change.After = before, after
+	} else if after, ok := strings.CutPrefix(text, "added "); ok {
+		change.After = after
+	} else if before, ok := strings.CutPrefix(text, "removed "); ok {
+		change.Before = before
+	} else {
+		return Change{}, false
+	}
+	return change, true
+}
+
+// Report sums up the mistakes in a journal
+type Report struct {
+	Entries     int
+	First, Last time.Time
+	Changes     int
+	Categories  []Count // Changes of each category, most frequent first
+	Recurring   []Count // Changes made more than once, most frequent first
+}
+
+// Count is how often a category of change, or a change, was made
+type Count struct {
+	Name  string
+	Count int
+}
+
+// Summarize counts the changes in entries by category, and the changes that were made more
+// than once, ignoring case
+func Summarize(entries []Entry) Report {
+	report := Report{Entries: len(entries)}
+	categories := make(map[string]int)
+	changes := make(map[string]int)
+	names := make(map[string]string) // First spelling of each change, by its lowercase form
+	for _, entry := range entries {
+		if report.First.IsZero() || entry.Time.Before(report.First) {
+			report.First = entry.Time
+		}
+		if entry.Time.After(report.Last) {
+			report.Last = entry.Time
+		}
+		for _, change := range entry.Changes {
+			report.Changes++
+			categories[change.Category]++
+			name := fmt.Sprintf("%s (%s)", change, change.Category)
+			key := strings.ToLower(name)
+			if _, ok := names[key]; !ok {
+				names[key] = name
+			}
+			changes[key]++
+		}
+	}
+
+	for category, count := range categories {
+		report.Categories = append(report.Categories, Count{Name: category, Count: count})
+	}
+	for key, count := range changes {
+		if count > 1 {
+			report.Recurring = append(report.Recurring, Count{Name: names[key], Count: count})
+		}
+	}
+	sortCounts(report.Categories)
+	sortCounts(report.Recurring)
+	return report
+}
+
+// sortCounts orders counts from the most frequent, then by name
+func sortCounts(counts []Count) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+}
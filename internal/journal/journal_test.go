@@ -0,0 +1,118 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes", "journal.md")
+	first := Entry{
+		Time:      time.Date(2026, 10, 1, 9, 30, 0, 0, time.Local),
+		Original:  "I has a apple.\n\nIt taste good.",
+		Corrected: "I have an apple.\n\nIt tastes good.",
+		Changes: []Change{
+			{Category: "grammar", Before: "has", After: "have"},
+			{Category: "grammar", Before: "a", After: "an"},
+			{Category: "grammar", Before: "taste", After: "tastes"},
+		},
+	}
+	second := Entry{
+		Time:      time.Date(2026, 10, 2, 18, 5, 7, 0, time.Local),
+		Original:  "Its fine",
+		Corrected: "It's fine.",
+		Changes: []Change{
+			{Category: "spelling", Before: "Its", After: "It's"},
+			{Category: "punctuation", After: "."},
+			{Category: "punctuation", Before: " ", After: "  "},
+		},
+	}
+	for _, entry := range []Entry{first, second} {
+		if err := Append(path, entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## 2026-10-01 09:30:00\n", "> I has a apple.\n>\n> It taste good.\n", "- grammar: has → have\n", "- punctuation: added .\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("journal is missing %q:\n%s", want, data)
+		}
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	// Whitespace-only changes aren't worth keeping
+	second.Changes = second.Changes[:2]
+	if !reflect.DeepEqual(entries, []Entry{first, second}) {
+		t.Errorf("Read() = %+v, want %+v", entries, []Entry{first, second})
+	}
+}
+
+func TestReadMissing(t *testing.T) {
+	entries, err := Read(filepath.Join(t.TempDir(), "journal.md"))
+	if err != nil || entries != nil {
+		t.Errorf("Read() = %v, %v, want no entries", entries, err)
+	}
+}
+
+func TestReadSkipsOtherHeadings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.md")
+	text := "# My journal\n\n## Notes\n\n> a quote of mine\n\n## 2026-10-01 09:30:00\n\n**Original**\n\n> Helo\n\n**Corrected**\n\n> Hello\n\n**Changes**\n\n- spelling: Helo → Hello\n"
+	if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Original != "Helo" || len(entries[0].Changes) != 1 {
+		t.Errorf("Read() = %+v, want the one entry", entries)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	entries := []Entry{
+		{
+			Time: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+			Changes: []Change{
+				{Category: "spelling", Before: "recieve", After: "receive"},
+				{Category: "grammar", Before: "has", After: "have"},
+			},
+		},
+		{
+			Time: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			Changes: []Change{
+				{Category: "spelling", Before: "Recieve", After: "Receive"},
+				{Category: "spelling", Before: "teh", After: "the"},
+				{Category: "punctuation", After: ","},
+			},
+		},
+	}
+
+	report := Summarize(entries)
+	if report.Entries != 2 || report.Changes != 5 {
+		t.Errorf("Summarize() counted %d entries and %d changes", report.Entries, report.Changes)
+	}
+	if !report.First.Equal(entries[1].Time) || !report.Last.Equal(entries[0].Time) {
+		t.Errorf("Summarize() spans %v to %v", report.First, report.Last)
+	}
+	wantCategories := []Count{{"spelling", 3}, {"grammar", 1}, {"punctuation", 1}}
+	if !reflect.DeepEqual(report.Categories, wantCategories) {
+		t.Errorf("Categories = %v, want %v", report.Categories, wantCategories)
+	}
+	wantRecurring := []Count{{"recieve → receive (spelling)", 2}}
+	if !reflect.DeepEqual(report.Recurring, wantRecurring) {
+		t.Errorf("Recurring = %v, want %v", report.Recurring, wantRecurring)
+	}
+}
@@ -0,0 +1,184 @@
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Languages lists the languages Detect recognizes, named like the language setting
+var Languages = []string{
+	"english", "spanish", "french", "german", "italian", "portuguese", "dutch", "polish", "swedish",
+	"russian", "ukrainian", "greek", "arabic", "hebrew", "hindi", "chinese", "japanese", "korean", "thai",
+}
+
+// minStopwords is how many common words a Latin-script text needs before its language is trusted
+const minStopwords = 2
+
+// stopwords are frequent short words that tell Latin-script languages apart
+var stopwords = map[string][]string{
+	"english":    {"the", "and", "is", "are", "was", "were", "of", "to", "that", "it", "with", "for", "this", "have", "has", "you", "not", "be", "on", "at", "by", "from", "they", "we", "an", "i", "my", "your"},
+	"spanish":    {"el", "la", "los", "las", "de", "que", "y", "en", "un", "una", "es", "por", "con", "para", "no", "se", "del", "al", "lo", "como", "pero", "más", "está", "muy", "también", "yo"},
+	"french":     {"le", "la", "les", "de", "des", "et", "est", "un", "une", "que", "qui", "dans", "pour", "pas", "sur", "au", "avec", "ce", "il", "elle", "nous", "vous", "sont", "mais", "je", "du"},
+	"german":     {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "von", "mit", "sich", "des", "auf", "für", "im", "dem", "auch", "es", "ich", "wir", "sie", "sind", "aber"},
+	"italian":    {"il", "lo", "la", "gli", "le", "di", "che", "e", "è", "un", "una", "per", "non", "con", "del", "della", "sono", "nel", "alla", "anche", "ma", "più", "questo", "come", "io"},
+	"portuguese": {"o", "os", "a", "as", "de", "que", "e", "é", "um", "uma", "do", "da", "dos", "das", "em", "no", "na", "não", "para", "com", "por", "mais", "muito", "também", "você", "eu"},
+	"dutch":      {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "zijn", "met", "voor", "er", "maar", "ook", "als", "bij", "nog", "wat", "deze", "ik", "je", "we"},
+	"polish":     {"i", "w", "z", "na", "się", "nie", "to", "jest", "że", "do", "o", "jak", "ale", "co", "po", "tak", "od", "za", "już", "czy", "dla", "są"},
+	"swedish":    {"och", "att", "det", "som", "en", "är", "på", "för", "med", "inte", "jag", "har", "den", "av", "till", "om", "ett", "var", "men", "vi", "kan", "så"},
+}
+
+// letterHints are letters that only appear in a few Latin-script languages
+var letterHints = map[rune][]string{
+	'ñ': {"spanish"},
+	'ç': {"french", "portuguese"},
+	'œ': {"french"},
+	'ê': {"french", "portuguese"},
+	'ã': {"portuguese"},
+	'õ': {"portuguese"},
+	'ß': {"german"},
+	'ü': {"german"},
+	'ä': {"german", "swedish"},
+	'ö': {"german", "swedish"},
+	'å': {"swedish"},
+	'ą': {"polish"},
+	'ę': {"polish"},
+	'ł': {"polish"},
+	'ś': {"polish"},
+	'ż': {"polish"},
+	'ź': {"polish"},
+	'ń': {"polish"},
+	'ì': {"italian"},
+	'ò': {"italian"},
+}
+
+// stopwordIndex maps each stopword to the languages it belongs to
+var stopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// Detect guesses the language of text without calling the API. It reports false when the text
+// is too short or too mixed to tell.
+func Detect(text string) (string, bool) {
+	scripts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		scripts[scriptOf(r)]++
+	}
+	if letters == 0 {
+		return "", false
+	}
+
+	// Japanese mixes kana with Chinese characters, so any kana decides it
+	if scripts["kana"] > 0 && scripts["kana"]+scripts["han"] > letters/2 {
+		return "japanese", true
+	}
+
+	script, count := "", 0
+	for s, n := range scripts {
+		if n > count || (n == count && s < script) {
+			script, count = s, n
+		}
+	}
+	if count <= letters/2 {
+		return "", false
+	}
+
+	switch script {
+	case "latin":
+		return detectLatin(text)
+	case "cyrillic":
+		// Letters that Ukrainian has and Russian doesn't
+		if strings.ContainsAny(strings.ToLower(text), "іїєґ") {
+			return "ukrainian", true
+		}
+		return "russian", true
+	case "han":
+		return "chinese", true
+	case "hangul":
+		return "korean", true
+	case "greek", "arabic", "hebrew", "thai":
+		return script, true
+	case "devanagari":
+		return "hindi", true
+	}
+	return "", false
+}
+
+func scriptOf(r rune) string {
+	switch {
+	case unicode.Is(unicode.Latin, r):
+		return "latin"
+	case unicode.Is(unicode.Cyrillic, r):
+		return "cyrillic"
+	case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+		return "kana"
+	case unicode.Is(unicode.Han, r):
+		return "han"
+	case unicode.Is(unicode.Hangul, r):
+		return "hangul"
+	case unicode.Is(unicode.Greek, r):
+		return "greek"
+	case unicode.Is(unicode.Arabic, r):
+		return "arabic"
+	case unicode.Is(unicode.Hebrew, r):
+		return "hebrew"
+	case unicode.Is(unicode.Devanagari, r):
+		return "devanagari"
+	case unicode.Is(unicode.Thai, r):
+		return "thai"
+	default:
+		return "other"
+	}
+}
+
+// detectLatin scores Latin-script text by its common words and distinctive letters
+func detectLatin(text string) (string, bool) {
+	scores := make(map[string]int)
+	hits := 0
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if langs, ok := stopwordIndex[word]; ok {
+			hits++
+			for _, lang := range langs {
+				scores[lang] += 2
+			}
+		}
+		for _, r := range word {
+			for _, lang := range letterHints[r] {
+				scores[lang]++
+			}
+		}
+	}
+	if hits < minStopwords {
+		return "", false
+	}
+
+	best, second := "", 0
+	for _, lang := range Languages {
+		score := scores[lang]
+		if score > scores[best] {
+			if best != "" {
+				second = scores[best]
+			}
+			best = lang
+		} else if score > second {
+			second = score
+		}
+	}
+	if best == "" || scores[best] == second {
+		return "", false
+	}
+	return best, true
+}
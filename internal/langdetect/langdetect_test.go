@@ -0,0 +1,40 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{name: "english", text: "I has a apple and it are red.", want: "english", wantOK: true},
+		{name: "spanish", text: "Yo tengo una manzana y es muy roja.", want: "spanish", wantOK: true},
+		{name: "french", text: "Je suis allé au marché avec ma sœur et nous sommes rentrés.", want: "french", wantOK: true},
+		{name: "german", text: "Ich habe einen Apfel und er ist nicht rot.", want: "german", wantOK: true},
+		{name: "italian", text: "Io ho una mela e non è rossa, ma è buona.", want: "italian", wantOK: true},
+		{name: "portuguese", text: "Eu tenho uma maçã e não é vermelha, mas é muito boa.", want: "portuguese", wantOK: true},
+		{name: "dutch", text: "Ik heb een appel en het is niet rood.", want: "dutch", wantOK: true},
+		{name: "polish", text: "Mam jabłko i nie jest czerwone, ale jest dobre.", want: "polish", wantOK: true},
+		{name: "swedish", text: "Jag har ett äpple och det är inte rött.", want: "swedish", wantOK: true},
+		{name: "russian", text: "У меня есть яблоко, и оно красное.", want: "russian", wantOK: true},
+		{name: "ukrainian", text: "У мене є яблуко, і воно червоне.", want: "ukrainian", wantOK: true},
+		{name: "chinese", text: "我有一个苹果。", want: "chinese", wantOK: true},
+		{name: "japanese", text: "私はりんごを持っています。", want: "japanese", wantOK: true},
+		{name: "korean", text: "나는 사과가 있어요.", want: "korean", wantOK: true},
+		{name: "greek", text: "Έχω ένα μήλο.", want: "greek", wantOK: true},
+		{name: "too short", text: "Hello world", wantOK: false},
+		{name: "no letters", text: "1234 !!", wantOK: false},
+		{name: "empty", text: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Detect(tt.text)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Detect(%q) = %q, %v, want %q, %v", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package merge
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Conflict is a place where both versions changed the same words of the base differently. The
+// merged text keeps ours there.
+type Conflict struct {
+	Start, End int    // Byte offsets of ours in the merged text
+	Ours       string // Text of ours, as merged
+	Theirs     string // What theirs has instead
+}
+
+// hunk replaces the base tokens [start, end) with text
+type hunk struct {
+	start, end int
+	text       []string
+}
+
+// Merge combines the changes ours and theirs each made to base, a word at a time. Where both
+// changed the same words differently, ours is kept and a Conflict reports what theirs had.
+func Merge(base, ours, theirs string) (string, []Conflict) {
+	var enc encoder
+	baseTokens := enc.split(base)
+	ourHunks := enc.hunks(baseTokens, enc.split(ours))
+	theirHunks := enc.hunks(baseTokens, enc.split(theirs))
+
+	var merged strings.Builder
+	var conflicts []Conflict
+	pos := 0 // Next base token to copy
+	for len(ourHunks) > 0 || len(theirHunks) > 0 {
+		// Group the hunks of both sides that touch the same base tokens
+		var ourGroup, theirGroup []hunk
+		first := len(theirHunks) == 0 || (len(ourHunks) > 0 && ourHunks[0].start <= theirHunks[0].start)
+		var start, end int
+		if first {
+			start, end = ourHunks[0].start, ourHunks[0].end
+		} else {
+			start, end = theirHunks[0].start, theirHunks[0].end
+		}
+		for {
+			if len(ourHunks) > 0 && overlaps(ourHunks[0], start, end) {
+				ourGroup = append(ourGroup, ourHunks[0])
+				end = max(end, ourHunks[0].end)
+				ourHunks = ourHunks[1:]
+				continue
+			}
+			if len(theirHunks) > 0 && overlaps(theirHunks[0], start, end) {
+				theirGroup = append(theirGroup, theirHunks[0])
+				end = max(end, theirHunks[0].end)
+				theirHunks = theirHunks[1:]
+				continue
+			}
+			break
+		}
+
+		merged.WriteString(strings.Join(baseTokens[pos:start], ""))
+		pos = end
+		ourText := apply(baseTokens, start, end, ourGroup)
+		theirText := apply(baseTokens, start, end, theirGroup)
+		switch {
+		case len(theirGroup) == 0 || ourText == theirText:
+			merged.WriteString(ourText)
+		case len(ourGroup) == 0:
+			merged.WriteString(theirText)
+		default:
+			conflicts = append(conflicts, Conflict{
+				Start:  merged.Len(),
+				End:    merged.Len() + len(ourText),
+				Ours:   ourText,
+				Theirs: theirText,
+			})
+			merged.WriteString(ourText)
+		}
+	}
+	merged.WriteString(strings.Join(baseTokens[pos:], ""))
+	return merged.String(), conflicts
+}
+
+// overlaps reports whether h changes any of the base tokens [start, end). Two insertions at the
+// same place overlap as well, since their order can't be decided.
+func overlaps(h hunk, start, end int) bool {
+	return h.start < end || (h.start == start && h.start == end)
+}
+
+// apply returns the base tokens [start, end) with the hunks in it applied
+func apply(base []string, start, end int, hunks []hunk) string {
+	var b strings.Builder
+	pos := start
+	for _, h := range hunks {
+		b.WriteString(strings.Join(base[pos:h.start], ""))
+		b.WriteString(strings.Join(h.text, ""))
+		pos = h.end
+	}
+	b.WriteString(strings.Join(base[pos:end], ""))
+	return b.String()
+}
+
+// encoder maps each distinct token to a rune, so go-diff can compare tokens like characters
+type encoder struct {
+	index  map[string]rune
+	tokens []string
+}
+
+func (e *encoder) encode(tokens []string) string {
+	if e.index == nil {
+		e.index = make(map[string]rune)
+	}
+	var b strings.Builder
+	for _, token := range tokens {
+		r, ok := e.index[token]
+		if !ok {
+			// Skip the surrogate range, which isn't valid in a string
+			r = rune(len(e.tokens))
+			if r >= 0xD800 {
+				r += 0x800
+			}
+			e.index[token] = r
+			e.tokens = append(e.tokens, token)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (e *encoder) decode(r rune) string {
+	if r >= 0xD800 {
+		r -= 0x800
+	}
+	return e.tokens[r]
+}
+
+// hunks returns the changes that turn the base tokens into the other tokens, in order
+func (e *encoder) hunks(base, other []string) []hunk {
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffMain(e.encode(base), e.encode(other), false)
+
+	var hunks []hunk
+	pos := 0
+	var current *hunk
+	for _, diff := range diffs {
+		n := len([]rune(diff.Text))
+		if diff.Type == diffmatchpatch.DiffEqual {
+			current = nil
+			pos += n
+			continue
+		}
+		if current == nil {
+			hunks = append(hunks, hunk{start: pos, end: pos})
+			current = &hunks[len(hunks)-1]
+		}
+		if diff.Type == diffmatchpatch.DiffDelete {
+			pos += n
+			current.end = pos
+		} else {
+			for _, r := range diff.Text {
+				current.text = append(current.text, e.decode(r))
+			}
+		}
+	}
+	return hunks
+}
+
+// split splits text into words, runs of whitespace and single other characters
+func (e *encoder) split(text string) []string {
+	kind := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+	var tokens []string
+	start, prev := 0, -1
+	for i, r := range text {
+		k := kind(r)
+		if i > start && (k == 0 || k != prev) {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		prev = k
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
@@ -0,0 +1,90 @@
+package merge
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts []Conflict
+	}{
+		{
+			name:   "nothing changed",
+			base:   "I have an apple.",
+			ours:   "I have an apple.",
+			theirs: "I have an apple.",
+			want:   "I have an apple.",
+		},
+		{
+			name:   "only ours changed",
+			base:   "I have an apple.",
+			ours:   "I have a red apple.",
+			theirs: "I have an apple.",
+			want:   "I have a red apple.",
+		},
+		{
+			name:   "only theirs changed",
+			base:   "I have an apple.",
+			ours:   "I have an apple.",
+			theirs: "I have an apple!",
+			want:   "I have an apple!",
+		},
+		{
+			name:   "separate changes",
+			base:   "We meet on Monday. Bring the report.",
+			ours:   "We meet on Tuesday. Bring the report.",
+			theirs: "We meet on Monday. Please bring the report.",
+			want:   "We meet on Tuesday. Please bring the report.",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "Send it tomorow.",
+			ours:   "Send it tomorrow.",
+			theirs: "Send it tomorrow.",
+			want:   "Send it tomorrow.",
+		},
+		{
+			name:   "conflicting changes keep ours",
+			base:   "The results was good.",
+			ours:   "The results were great.",
+			theirs: "The results were excellent.",
+			want:   "The results were great.",
+			wantConflicts: []Conflict{
+				{Start: 17, End: 22, Ours: "great", Theirs: "excellent"},
+			},
+		},
+		{
+			name:   "insertions at the same place conflict",
+			base:   "Thanks for help.",
+			ours:   "Thanks for your help.",
+			theirs: "Thanks for the help.",
+			want:   "Thanks for your help.",
+			wantConflicts: []Conflict{
+				{Start: 11, End: 16, Ours: "your ", Theirs: "the "},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge(tt.base, tt.ours, tt.theirs)
+			if got != tt.want {
+				t.Errorf("Merge() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Errorf("Merge() conflicts = %+v, want %+v", conflicts, tt.wantConflicts)
+			}
+			for _, c := range conflicts {
+				if got[c.Start:c.End] != c.Ours {
+					t.Errorf("conflict at %d-%d = %q, want %q", c.Start, c.End, got[c.Start:c.End], c.Ours)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Levels of the notifications setting
+const (
+	// LevelAll notifies of every correction and every error
+	LevelAll = "all"
+	// LevelErrors only notifies of errors
+	LevelErrors = "errors"
+	// LevelOff never notifies
+	LevelOff = "off"
+)
+
+// title is the title of every notification
+const title = "grammr"
+
+// Notifier shows desktop notifications for the modes that run without a window, grammr hotkey
+// and grammrd. Notifications are best effort: when they can't be shown, nothing is.
+type Notifier struct {
+	level string
+	send  func(title, body string) error // Nil when the desktop has no notifications
+}
+
+// New returns a notifier showing the notifications of level, one of the Level constants
+func New(level string) *Notifier {
+	return &Notifier{level: level, send: detect(runtime.GOOS, os.Getenv, func(name string) bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	})}
+}
+
+// Changes reports a correction that made count changes
+func (n *Notifier) Changes(count int, pasted bool) {
+	if n == nil || n.level != LevelAll {
+		return
+	}
+	var body string
+	switch {
+	case count == 0:
+		body = "No changes needed"
+	case count == 1:
+		body = "1 change"
+	default:
+		body = fmt.Sprintf("%d changes", count)
+	}
+	if count > 0 && pasted {
+		body += " applied"
+	} else if count > 0 {
+		body += ", the correction is on the clipboard"
+	}
+	n.show(body)
+}
+
+// Error reports an error
+func (n *Notifier) Error(message string) {
+	if n == nil || n.level == LevelOff {
+		return
+	}
+	n.show("Error: " + message)
+}
+
+func (n *Notifier) show(body string) {
+	if n.send != nil {
+		_ = n.send(title, body)
+	}
+}
+
+// detect returns the way notifications are shown on this machine, or nil when there is none
+func detect(goos string, getenv func(string) string, installed func(string) bool) func(title, body string) error {
+	switch goos {
+	case "darwin":
+		return osascript
+	case "windows":
+		return toast
+	}
+	if (getenv("DISPLAY") != "" || getenv("WAYLAND_DISPLAY") != "") && installed("notify-send") {
+		return notifySend
+	}
+	return nil
+}
+
+// osascript shows a notification through Notification Center. The texts are passed as
+// arguments, so they needn't be quoted for AppleScript.
+func osascript(title, body string) error {
+	return exec.Command("osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		title, body).Run()
+}
+
+// notifySend shows a notification through libnotify
+func notifySend(title, body string) error {
+	return exec.Command("notify-send", "--app-name="+title, title, body).Run()
+}
+
+// toastScript shows a Windows toast with the texts of the GRAMMR_TITLE and GRAMMR_BODY variables,
+// as PowerShell, since Go programs can't show toasts themselves without an app identity
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$title = [Security.SecurityElement]::Escape($env:GRAMMR_TITLE)
+$body = [Security.SecurityElement]::Escape($env:GRAMMR_BODY)
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml("<toast><visual><binding template=""ToastGeneric""><text>$title</text><text>$body</text></binding></visual></toast>")
+$app = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($app).Show([Windows.UI.Notifications.ToastNotification]::new($xml))
+`
+
+func toast(title, body string) error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Env = append(os.Environ(), "GRAMMR_TITLE="+title, "GRAMMR_BODY="+body)
+	return cmd.Run()
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNotifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		level string
+		show  func(n *Notifier)
+		want  []string
+	}{
+		{
+			name:  "applied changes",
+			level: LevelAll,
+			show:  func(n *Notifier) { n.Changes(3, true) },
+			want:  []string{"3 changes applied"},
+		},
+		{
+			name:  "changes on the clipboard",
+			level: LevelAll,
+			show:  func(n *Notifier) { n.Changes(1, false) },
+			want:  []string{"1 change, the correction is on the clipboard"},
+		},
+		{
+			name:  "no changes",
+			level: LevelAll,
+			show:  func(n *Notifier) { n.Changes(0, true) },
+			want:  []string{"No changes needed"},
+		},
+		{
+			name:  "errors only",
+			level: LevelErrors,
+			show: func(n *Notifier) {
+				n.Changes(3, true)
+				n.Error("offline")
+			},
+			want: []string{"Error: offline"},
+		},
+		{
+			name:  "off",
+			level: LevelOff,
+			show: func(n *Notifier) {
+				n.Changes(3, true)
+				n.Error("offline")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shown []string
+			n := &Notifier{level: tt.level, send: func(title, body string) error {
+				shown = append(shown, body)
+				return nil
+			}}
+			tt.show(n)
+			if !reflect.DeepEqual(shown, tt.want) {
+				t.Errorf("shown %q, want %q", shown, tt.want)
+			}
+		})
+	}
+
+	// A nil notifier and a desktop without notifications show nothing
+	var n *Notifier
+	n.Error("offline")
+	(&Notifier{level: LevelAll}).Changes(1, true)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		env       map[string]string
+		installed bool
+		want      bool
+	}{
+		{name: "macOS", goos: "darwin", want: true},
+		{name: "Windows", goos: "windows", want: true},
+		{name: "X11 with libnotify", goos: "linux", env: map[string]string{"DISPLAY": ":0"}, installed: true, want: true},
+		{name: "Wayland without libnotify", goos: "linux", env: map[string]string{"WAYLAND_DISPLAY": "wayland-0"}},
+		{name: "headless", goos: "linux", installed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send := detect(tt.goos,
+				func(name string) string { return tt.env[name] },
+				func(name string) bool { return tt.installed })
+			if (send != nil) != tt.want {
+				t.Errorf("detect() found notifications = %v, want %v", send != nil, tt.want)
+			}
+		})
+	}
+}
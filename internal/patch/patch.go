@@ -0,0 +1,142 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Context is how many unchanged lines surround the changes of a hunk
+const Context = 3
+
+// line is one line of a diff: kept (' '), removed ('-') or added ('+')
+type line struct {
+	op   byte
+	text string
+}
+
+// Unified returns a unified diff turning oldText into newText, as written by diff -u, or an
+// empty string when they are the same. The names are used in the --- and +++ headers. A text
+// without a final newline is treated as if it had one, so the patch applies to a file holding it.
+func Unified(oldName, newName, oldText, newText string) string {
+	oldText, newText = withNewline(oldText), withNewline(newText)
+	if oldText == newText {
+		return ""
+	}
+	lines := diffLines(oldText, newText)
+
+	// Line numbers in each text before every line of the diff
+	oldBefore := make([]int, len(lines)+1)
+	newBefore := make([]int, len(lines)+1)
+	var changes []int
+	for i, l := range lines {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+		if l.op != '+' {
+			oldBefore[i+1]++
+		}
+		if l.op != '-' {
+			newBefore[i+1]++
+		}
+		if l.op != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(changes); {
+		// Merge changes whose context would overlap into one hunk
+		start := max(changes[i]-Context, 0)
+		end := changes[i] + 1
+		for i+1 < len(changes) && changes[i+1]-end <= 2*Context {
+			i++
+			end = changes[i] + 1
+		}
+		end = min(end+Context, len(lines))
+		i++
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldBefore[start], oldBefore[end]-oldBefore[start]),
+			hunkRange(newBefore[start], newBefore[end]-newBefore[start]))
+		for _, l := range lines[start:end] {
+			b.WriteByte(l.op)
+			b.WriteString(l.text)
+		}
+	}
+	return b.String()
+}
+
+// hunkRange formats the lines of a hunk in one text, given the number of lines before it
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// diffLines compares the texts line by line. Each distinct line is turned into one character,
+// so the character diff of the results is a line diff.
+func diffLines(oldText, newText string) []line {
+	index := make(map[string]rune)
+	var texts []string
+	encode := func(text string) string {
+		var b strings.Builder
+		for _, l := range strings.SplitAfter(text, "\n") {
+			if l == "" {
+				continue
+			}
+			r, ok := index[l]
+			if !ok {
+				r = lineRune(len(texts))
+				index[l] = r
+				texts = append(texts, l)
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+	oldChars, newChars := encode(oldText), encode(newText)
+
+	dmp := diffmatchpatch.New()
+	var lines []line
+	for _, diff := range dmp.DiffMain(oldChars, newChars, false) {
+		op := byte(' ')
+		switch diff.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		for _, r := range diff.Text {
+			lines = append(lines, line{op: op, text: texts[runeLine(r)]})
+		}
+	}
+	return lines
+}
+
+// lineRune returns the character standing for the nth distinct line, skipping the surrogate
+// range, which isn't valid in a string
+func lineRune(n int) rune {
+	if r := rune(n); r < 0xD800 {
+		return r
+	}
+	return rune(n) + 0x800
+}
+
+func runeLine(r rune) int {
+	if r < 0xD800 {
+		return int(r)
+	}
+	return int(r - 0x800)
+}
+
+func withNewline(text string) string {
+	if text == "" || strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
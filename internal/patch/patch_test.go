@@ -0,0 +1,119 @@
+package patch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    string
+	}{
+		{
+			name:    "same text",
+			oldText: "Hello world",
+			newText: "Hello world\n",
+			want:    "",
+		},
+		{
+			name:    "one line",
+			oldText: "I has a apple.",
+			newText: "I have an apple.",
+			want: `--- original
++++ corrected
+@@ -1 +1 @@
+-I has a apple.
++I have an apple.
+`,
+		},
+		{
+			name:    "context around a change",
+			oldText: "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine",
+			newText: "one\ntwo\nthree\nfour\nFIVE\nsix\nseven\neight\nnine",
+			want: `--- original
++++ corrected
+@@ -2,7 +2,7 @@
+ two
+ three
+ four
+-five
++FIVE
+ six
+ seven
+ eight
+`,
+		},
+		{
+			name:    "separate hunks",
+			oldText: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk",
+			newText: "A\nb\nc\nd\ne\nf\ng\nh\ni\nj\nK\nl",
+			want: `--- original
++++ corrected
+@@ -1,4 +1,4 @@
+-a
++A
+ b
+ c
+ d
+@@ -8,4 +8,5 @@
+ h
+ i
+ j
+-k
++K
++l
+`,
+		},
+		{
+			name:    "from nothing",
+			oldText: "",
+			newText: "new",
+			want: `--- original
++++ corrected
+@@ -0,0 +1 @@
++new
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("original", "corrected", tt.oldText, tt.newText); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedAppliesWithPatch(t *testing.T) {
+	patchCmd, err := exec.LookPath("patch")
+	if err != nil {
+		t.Skip("patch is not installed")
+	}
+	oldText := "Dear team,\n\nI has a apple.\nIt are red.\n\nThanks\n"
+	newText := "Dear team,\n\nI have an apple.\nIt is red.\n\nThanks,\nMe\n"
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "letter.txt")
+	if err := os.WriteFile(file, []byte(oldText), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(patchCmd, file)
+	cmd.Stdin = strings.NewReader(Unified("a/letter.txt", "b/letter.txt", oldText, newText))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("patch failed: %v\n%s", err, out)
+	}
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != newText {
+		t.Fatalf("patched file = %q, want %q", got, newText)
+	}
+}
@@ -0,0 +1,192 @@
+package postprocess
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxHeadLength is how much of a response is buffered at most before deciding whether it starts
+// with a preamble
+const maxHeadLength = 500
+
+var (
+	// preamblePattern matches an introduction on its own line, such as "Here is the corrected text:"
+	preamblePattern = regexp.MustCompile(`(?i)^(?:(?:sure|certainly|of course|okay|ok)[!,.]?\s*)?(?:(?:here(?:'s|’s| is| are)|below is|i(?:'ve|’ve| have) (?:corrected|fixed|rewritten|revised|translated|edited))\b.*|(?:the )?(?:corrected|revised|rewritten|improved|edited|translated|fixed)(?: text| version| sentence| paragraph| translation)?\s*):$`)
+
+	// labelPattern matches a label in front of the text on the same line, such as "Corrected text: "
+	labelPattern = regexp.MustCompile(`(?i)^(?:corrected|revised|rewritten|edited|translated|translation)(?: text| version)?:[ \t]+`)
+
+	// signOffPattern matches a closing remark, such as "Let me know if you need anything else."
+	signOffPattern = regexp.MustCompile(`(?i)^(?:let me know|please let me know|i hope|hope this|feel free|if you (?:need|have|want|would|'d|’d)|is there anything|(?:please )?note:|i(?:'ve|’ve| have) (?:corrected|fixed|made|changed|kept))`)
+
+	// fencePattern matches the opening line of a code fence around the whole text
+	fencePattern = regexp.MustCompile("^(```|~~~)[\\w-]*[ \t]*\r?\n")
+)
+
+var paragraphBreak = []byte("\n\n")
+
+// quotePairs maps opening quotes to their closing counterparts
+var quotePairs = map[rune]string{
+	'"':  `"`,
+	'\'': `'`,
+	'`':  "`",
+	'“':  "”",
+	'‘':  "’",
+	'„':  "“",
+	'«':  "»",
+}
+
+// Clean removes what models wrap around their answer: an introduction like "Here is the
+// corrected text:", quotes or a code fence around the text, and a closing remark like "Let me
+// know if…". Artifacts that also appear in original are kept, since they are part of the text.
+func Clean(text, original string) string {
+	var b strings.Builder
+	s := NewStream(original, func(chunk string) { b.WriteString(chunk) })
+	s.Write(text)
+	s.Close()
+	return b.String()
+}
+
+// Stream cleans a response while it is being streamed. It holds back the start until it can tell
+// whether it is an introduction, and the last paragraphs until the end, so only clean text is
+// passed on.
+type Stream struct {
+	original string
+	emit     func(string)
+	pending  []byte
+	scanned  int    // Bytes of pending already searched for paragraph breaks
+	breaks   []int  // Offsets of the last paragraph breaks in pending
+	started  bool   // The start of the response has been cleaned
+	closer   string // Closing quote or fence of a wrapper the model added
+}
+
+// NewStream creates a Stream that passes the cleaned text to emit
+func NewStream(original string, emit func(string)) *Stream {
+	return &Stream{original: original, emit: emit}
+}
+
+// Write adds a chunk of the response
+func (s *Stream) Write(chunk string) {
+	s.pending = append(s.pending, chunk...)
+	if !s.started {
+		if !s.headComplete() {
+			return
+		}
+		s.cleanHead()
+	}
+
+	// Only search the new text for paragraph breaks; a break can straddle two chunks
+	for {
+		i := bytes.Index(s.pending[s.scanned:], paragraphBreak)
+		if i < 0 {
+			break
+		}
+		s.breaks = append(s.breaks, s.scanned+i)
+		s.scanned += i + 1
+	}
+	s.scanned = max(s.scanned, len(s.pending)-1)
+	if len(s.breaks) > 2 {
+		s.breaks = s.breaks[len(s.breaks)-2:]
+	}
+
+	// Hold back the last two paragraphs: they may be a closing remark and the end of a wrapper
+	if len(s.breaks) == 2 && s.breaks[0] > 0 {
+		i := s.breaks[0]
+		s.emit(string(s.pending[:i]))
+		s.pending = s.pending[i:]
+		s.scanned -= i
+		s.breaks = []int{0, s.breaks[1] - i}
+	}
+}
+
+// Close passes on the rest of the response
+func (s *Stream) Close() {
+	if !s.started {
+		s.cleanHead()
+	}
+	if text := s.cleanTail(string(s.pending)); text != "" {
+		s.emit(text)
+	}
+	s.pending = nil
+}
+
+// headComplete reports whether the first line and the start of the next one have arrived
+func (s *Stream) headComplete() bool {
+	if len(s.pending) > maxHeadLength {
+		return true
+	}
+	text := bytes.TrimLeft(s.pending, " \t\r\n")
+	_, rest, ok := bytes.Cut(text, []byte("\n"))
+	return ok && len(bytes.TrimSpace(rest)) > 0
+}
+
+func (s *Stream) cleanHead() {
+	s.started = true
+	text := string(s.pending)
+	trimmed := strings.TrimLeft(text, " \t\r\n")
+
+	if line, rest, ok := strings.Cut(trimmed, "\n"); ok && isPreamble(line) && !isPreamble(firstLine(s.original)) {
+		trimmed = strings.TrimLeft(rest, " \t\r\n")
+		text = trimmed
+	}
+	if label := labelPattern.FindString(trimmed); label != "" && !labelPattern.MatchString(strings.TrimSpace(s.original)) {
+		trimmed = trimmed[len(label):]
+		text = trimmed
+	}
+
+	original := strings.TrimSpace(s.original)
+	if fence := fencePattern.FindStringSubmatch(trimmed); fence != nil && !fencePattern.MatchString(original+"\n") {
+		text = trimmed[len(fence[0]):]
+		s.closer = fence[1]
+	} else if r, size := utf8.DecodeRuneInString(trimmed); quotePairs[r] != "" {
+		if first, _ := utf8.DecodeRuneInString(original); first != r {
+			text = trimmed[size:]
+			s.closer = quotePairs[r]
+		}
+	}
+	s.pending = []byte(text)
+}
+
+func (s *Stream) cleanTail(text string) string {
+	body := strings.TrimRight(text, " \t\r\n")
+
+	if !isSignOff(lastLine(s.original)) {
+		for {
+			i := strings.LastIndex(body, "\n")
+			if i < 0 || !isSignOff(body[i+1:]) {
+				break
+			}
+			body = strings.TrimRight(body[:i], " \t\r\n")
+		}
+	}
+	if s.closer != "" && strings.HasSuffix(body, s.closer) {
+		body = strings.TrimRight(strings.TrimSuffix(body, s.closer), " \t\r\n")
+	}
+
+	if body == strings.TrimRight(text, " \t\r\n") {
+		// Nothing was removed, keep the text as it is
+		return text
+	}
+	return body
+}
+
+func isPreamble(line string) bool {
+	line = strings.TrimSpace(line)
+	return len(line) < 120 && preamblePattern.MatchString(line)
+}
+
+func isSignOff(line string) bool {
+	return signOffPattern.MatchString(strings.TrimSpace(line))
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
+
+func lastLine(text string) string {
+	text = strings.TrimSpace(text)
+	return text[strings.LastIndex(text, "\n")+1:]
+}
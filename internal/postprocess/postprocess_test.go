@@ -0,0 +1,123 @@
+package postprocess
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		original string
+		want     string
+	}{
+		{
+			name:     "clean response",
+			text:     "I have an apple.",
+			original: "I has a apple.",
+			want:     "I have an apple.",
+		},
+		{
+			name:     "preamble",
+			text:     "Here is the corrected text:\n\nI have an apple.",
+			original: "I has a apple.",
+			want:     "I have an apple.",
+		},
+		{
+			name:     "polite preamble",
+			text:     "Sure! Here's the revised version:\nI have an apple.",
+			original: "I has a apple.",
+			want:     "I have an apple.",
+		},
+		{
+			name:     "label on the same line",
+			text:     "Corrected text: I have an apple.",
+			original: "I has a apple.",
+			want:     "I have an apple.",
+		},
+		{
+			name:     "quotes",
+			text:     "\"I have an apple.\"",
+			original: "I has a apple.",
+			want:     "I have an apple.",
+		},
+		{
+			name:     "curly quotes",
+			text:     "“I have an apple.”",
+			original: "I has a apple.",
+			want:     "I have an apple.",
+		},
+		{
+			name:     "code fence",
+			text:     "```text\nI have an apple.\n\nAnd a pear.\n```",
+			original: "I has a apple.\n\nAnd a pear.",
+			want:     "I have an apple.\n\nAnd a pear.",
+		},
+		{
+			name:     "sign-off",
+			text:     "I have an apple.\n\nLet me know if you need anything else!",
+			original: "I has a apple.",
+			want:     "I have an apple.",
+		},
+		{
+			name:     "everything at once",
+			text:     "Here is the corrected text:\n\n\"I have an apple.\n\nAnd a pear.\"\n\nI hope this helps.",
+			original: "I has a apple.\n\nAnd a pear.",
+			want:     "I have an apple.\n\nAnd a pear.",
+		},
+		{
+			name:     "quotes that are part of the text",
+			text:     "\"Hello,\" she said.",
+			original: "\"Hello\" she said.",
+			want:     "\"Hello,\" she said.",
+		},
+		{
+			name:     "introduction that is part of the text",
+			text:     "Here are the steps:\n1. Open the app.",
+			original: "Here is the steps:\n1. Open the app.",
+			want:     "Here are the steps:\n1. Open the app.",
+		},
+		{
+			name:     "sign-off that is part of the text",
+			text:     "Thanks for the update.\nLet me know when you're free.",
+			original: "Thanks for the update.\nLet me know when your free.",
+			want:     "Thanks for the update.\nLet me know when you're free.",
+		},
+		{
+			name:     "a sign-off alone is kept",
+			text:     "Let me know.",
+			original: "let me know",
+			want:     "Let me know.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Clean(tt.text, tt.original); got != tt.want {
+				t.Errorf("Clean() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamMatchesClean(t *testing.T) {
+	original := "I has a apple.\n\nAnd a pear.\n\nAnd a plum."
+	response := "Here is the corrected text:\n\n\"I have an apple.\n\nAnd a pear.\n\nAnd a plum.\"\n\nLet me know if you need anything else."
+
+	// Feed the response a few bytes at a time, as a provider would
+	var got strings.Builder
+	s := NewStream(original, func(chunk string) { got.WriteString(chunk) })
+	for i := 0; i < len(response); i += 3 {
+		end := min(i+3, len(response))
+		s.Write(response[i:end])
+	}
+	s.Close()
+
+	if want := Clean(response, original); got.String() != want {
+		t.Fatalf("streamed = %q, want %q", got.String(), want)
+	}
+	if got.String() != "I have an apple.\n\nAnd a pear.\n\nAnd a plum." {
+		t.Fatalf("streamed = %q", got.String())
+	}
+}
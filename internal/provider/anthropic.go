@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
@@ -10,7 +11,8 @@ import (
 
 // AnthropicProvider implements Provider using Anthropic's API
 type AnthropicProvider struct {
-	client anthropic.Client
+	client        anthropic.Client
+	deterministic bool
 }
 
 func toAnthropicMessages(messages []Message) ([]anthropic.MessageParam, []anthropic.TextBlockParam) {
@@ -35,30 +37,52 @@ func toAnthropicMessages(messages []Message) ([]anthropic.MessageParam, []anthro
 
 // NewAnthropicProvider creates a new Anthropic provider
 func NewAnthropicProvider(apiKey string) (*AnthropicProvider, error) {
+	return newAnthropicProvider(apiKey, nil)
+}
+
+// newAnthropicProvider creates an Anthropic provider sending its requests through httpClient, or
+// http.DefaultClient when it is nil
+func newAnthropicProvider(apiKey string, httpClient *http.Client) (*AnthropicProvider, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is required")
 	}
-	client := anthropic.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
+	}
+	client := anthropic.NewClient(opts...)
 	return &AnthropicProvider{
 		client: client,
 	}, nil
 }
 
-// StreamChat streams a chat completion response
-func (p *AnthropicProvider) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
-	anthropicMessages, systemPrompt := toAnthropicMessages(messages)
+// SetDeterministic makes responses as reproducible as possible by using temperature 0.
+// Anthropic doesn't support a seed, so identical output isn't guaranteed.
+func (p *AnthropicProvider) SetDeterministic(enabled bool) {
+	p.deterministic = enabled
+}
 
+func (p *AnthropicProvider) newParams(model string, messages []anthropic.MessageParam, systemPrompt []anthropic.TextBlockParam) anthropic.MessageNewParams {
 	params := anthropic.MessageNewParams{
 		Model:     anthropic.Model(model),
 		MaxTokens: 4096,
-		Messages:  anthropicMessages,
+		Messages:  messages,
 	}
 
 	if len(systemPrompt) > 0 {
 		params.System = systemPrompt
 	}
+	if p.deterministic {
+		params.Temperature = anthropic.Float(0)
+	}
+	return params
+}
+
+// StreamChat streams a chat completion response
+func (p *AnthropicProvider) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	anthropicMessages, systemPrompt := toAnthropicMessages(messages)
+
+	params := p.newParams(model, anthropicMessages, systemPrompt)
 
 	stream := p.client.Messages.NewStreaming(ctx, params)
 	message := anthropic.Message{}
@@ -66,7 +90,7 @@ func (p *AnthropicProvider) StreamChat(ctx context.Context, model string, messag
 	for stream.Next() {
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("context cancelled: %w", ctx.Err())
+			return classifyAnthropicError(model, fmt.Errorf("context cancelled: %w", ctx.Err()))
 		default:
 		}
 
@@ -88,7 +112,7 @@ func (p *AnthropicProvider) StreamChat(ctx context.Context, model string, messag
 	}
 
 	if err := stream.Err(); err != nil {
-		return fmt.Errorf("stream error: %w", err)
+		return classifyAnthropicError(model, fmt.Errorf("stream error: %w", err))
 	}
 
 	return nil
@@ -98,19 +122,11 @@ func (p *AnthropicProvider) StreamChat(ctx context.Context, model string, messag
 func (p *AnthropicProvider) Chat(ctx context.Context, model string, messages []Message) (string, error) {
 	anthropicMessages, systemPrompt := toAnthropicMessages(messages)
 
-	params := anthropic.MessageNewParams{
-		Model:     anthropic.Model(model),
-		MaxTokens: 4096,
-		Messages:  anthropicMessages,
-	}
-
-	if len(systemPrompt) > 0 {
-		params.System = systemPrompt
-	}
+	params := p.newParams(model, anthropicMessages, systemPrompt)
 
 	resp, err := p.client.Messages.New(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to create completion: %w", err)
+		return "", classifyAnthropicError(model, fmt.Errorf("failed to create completion: %w", err))
 	}
 
 	if len(resp.Content) == 0 {
@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maximbilan/grammr/internal/audit"
+)
+
+// audited records every request of a provider in the audit log before sending it. A request that
+// can't be recorded isn't sent, so the log misses nothing.
+type audited struct {
+	Provider
+	name string
+	log  *audit.Log
+}
+
+// StreamChat streams a chat completion response
+func (p audited) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	if err := p.record(model, messages); err != nil {
+		return err
+	}
+	return p.Provider.StreamChat(ctx, model, messages, onChunk)
+}
+
+// Chat performs a non-streaming chat completion
+func (p audited) Chat(ctx context.Context, model string, messages []Message) (string, error) {
+	if err := p.record(model, messages); err != nil {
+		return "", err
+	}
+	return p.Provider.Chat(ctx, model, messages)
+}
+
+func (p audited) record(model string, messages []Message) error {
+	sent := make([]audit.Message, len(messages))
+	for i, message := range messages {
+		sent[i] = audit.Message{Role: message.Role, Content: message.Content}
+	}
+	if _, err := p.log.Record(p.name, model, sent); err != nil {
+		return fmt.Errorf("request not sent, failed to record it in the audit log: %w", err)
+	}
+	return nil
+}
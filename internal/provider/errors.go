@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/openai/openai-go"
+)
+
+// classifyOpenAIError sorts a failed OpenAI request into the kinds of apierror
+func classifyOpenAIError(model string, err error) error {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return apierror.Classify("openai", model, nil, err)
+	}
+	code := apiErr.Code
+	if code == "" {
+		code = apiErr.Type
+	}
+	return apierror.Classify("openai", model, &apierror.Response{
+		Status:  apiErr.StatusCode,
+		Code:    code,
+		Message: apiErr.Message,
+	}, err)
+}
+
+// classifyAnthropicError sorts a failed Anthropic request into the kinds of apierror
+func classifyAnthropicError(model string, err error) error {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return apierror.Classify("anthropic", model, nil, err)
+	}
+	// The SDK keeps the details of the error in the raw response only
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal([]byte(apiErr.RawJSON()), &body)
+	return apierror.Classify("anthropic", model, &apierror.Response{
+		Status:  apiErr.StatusCode,
+		Code:    body.Error.Type,
+		Message: body.Error.Message,
+	}, err)
+}
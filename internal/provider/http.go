@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"net/http"
+	"time"
+)
+
+// maxIdleConnsPerHost is how many connections to a provider are kept open between requests. The
+// default of 2 is fewer than the requests a chunked correction sends at once, see
+// chunk_concurrency, so most of their connections would be closed after each request.
+const maxIdleConnsPerHost = 16
+
+// NewHTTPClient returns a client for providers to share, keeping enough connections to each
+// provider alive that requests reuse them and their TLS sessions instead of dialing again.
+// Requests are bounded by their contexts, so the client has no timeout of its own, which would
+// also cut off long streams.
+func NewHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = 90 * time.Second
+	return &http.Client{Transport: transport}
+}
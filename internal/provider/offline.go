@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrOffline is returned for every request in offline mode, instead of sending it
+var ErrOffline = errors.New("offline mode is on")
+
+// offline stands in for a provider in offline mode. It has no client, so it can't connect
+// anywhere, and fails every request at once.
+type offline struct {
+	name string
+}
+
+// StreamChat fails with ErrOffline
+func (p offline) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	return p.err()
+}
+
+// Chat fails with ErrOffline
+func (p offline) Chat(ctx context.Context, model string, messages []Message) (string, error) {
+	return "", p.err()
+}
+
+func (p offline) err() error {
+	return fmt.Errorf("%w: this needs a request to %s, which isn't sent (turn off offline to send it)", ErrOffline, p.name)
+}
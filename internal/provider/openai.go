@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -10,7 +11,8 @@ import (
 
 // OpenAIProvider implements Provider using OpenAI's API
 type OpenAIProvider struct {
-	client openai.Client
+	client        openai.Client
+	deterministic bool
 }
 
 func toOpenAIMessages(messages []Message) []openai.ChatCompletionMessageParamUnion {
@@ -31,29 +33,53 @@ func toOpenAIMessages(messages []Message) []openai.ChatCompletionMessageParamUni
 
 // NewOpenAIProvider creates a new OpenAI provider
 func NewOpenAIProvider(apiKey string) (*OpenAIProvider, error) {
+	return newOpenAIProvider(apiKey, nil)
+}
+
+// newOpenAIProvider creates an OpenAI provider sending its requests through httpClient, or
+// http.DefaultClient when it is nil
+func newOpenAIProvider(apiKey string, httpClient *http.Client) (*OpenAIProvider, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is required")
 	}
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
+	}
 	return &OpenAIProvider{
-		client: openai.NewClient(option.WithAPIKey(apiKey)),
+		client: openai.NewClient(opts...),
 	}, nil
 }
 
-// StreamChat streams a chat completion response
-func (p *OpenAIProvider) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
-	openaiMessages := toOpenAIMessages(messages)
+// SetDeterministic makes responses reproducible by using temperature 0 and a fixed seed
+func (p *OpenAIProvider) SetDeterministic(enabled bool) {
+	p.deterministic = enabled
+}
 
+func (p *OpenAIProvider) newParams(model string, messages []openai.ChatCompletionMessageParamUnion) openai.ChatCompletionNewParams {
 	params := openai.ChatCompletionNewParams{
 		Model:    openai.ChatModel(model),
-		Messages: openaiMessages,
+		Messages: messages,
 	}
+	if p.deterministic {
+		params.Temperature = openai.Float(0)
+		params.Seed = openai.Int(DeterministicSeed)
+	}
+	return params
+}
+
+// StreamChat streams a chat completion response
+func (p *OpenAIProvider) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	openaiMessages := toOpenAIMessages(messages)
+
+	params := p.newParams(model, openaiMessages)
 
 	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
 
 	for stream.Next() {
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("context cancelled: %w", ctx.Err())
+			return classifyOpenAIError(model, fmt.Errorf("context cancelled: %w", ctx.Err()))
 		default:
 		}
 
@@ -66,7 +92,7 @@ func (p *OpenAIProvider) StreamChat(ctx context.Context, model string, messages
 	}
 
 	if err := stream.Err(); err != nil {
-		return fmt.Errorf("stream error: %w", err)
+		return classifyOpenAIError(model, fmt.Errorf("stream error: %w", err))
 	}
 
 	return nil
@@ -76,14 +102,11 @@ func (p *OpenAIProvider) StreamChat(ctx context.Context, model string, messages
 func (p *OpenAIProvider) Chat(ctx context.Context, model string, messages []Message) (string, error) {
 	openaiMessages := toOpenAIMessages(messages)
 
-	params := openai.ChatCompletionNewParams{
-		Model:    openai.ChatModel(model),
-		Messages: openaiMessages,
-	}
+	params := p.newParams(model, openaiMessages)
 
 	resp, err := p.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to create completion: %w", err)
+		return "", classifyOpenAIError(model, fmt.Errorf("failed to create completion: %w", err))
 	}
 
 	if len(resp.Choices) == 0 {
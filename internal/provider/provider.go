@@ -2,6 +2,11 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/maximbilan/grammr/internal/audit"
 )
 
 // Provider defines the interface for AI providers (OpenAI, Anthropic, etc.)
@@ -25,3 +30,86 @@ const (
 	RoleAssistant = "assistant"
 	RoleSystem    = "system"
 )
+
+// DeterministicSeed is the seed sent in deterministic mode to providers that support one
+const DeterministicSeed = 42
+
+// Options configures a provider created by New
+type Options struct {
+	// Deterministic requests temperature 0 and a fixed seed where supported, so the same
+	// prompt yields the same response
+	Deterministic bool
+	// Audit records every request before it is sent, when not nil
+	Audit *audit.Log
+	// HTTPClient sends the requests, so providers created one after another can share its
+	// connections; see NewHTTPClient. Nil uses http.DefaultClient.
+	HTTPClient *http.Client
+	// Redact replaces emails, phone numbers, card numbers and names with placeholders before
+	// a request is sent, and restores them in the response
+	Redact bool
+	// Retries is how many times a request that was rate limited or couldn't reach the provider
+	// is sent again, waiting longer before each attempt; see WithRetryNotify
+	Retries int
+	// Offline creates a provider that sends nothing and fails every request with ErrOffline.
+	// The API key isn't needed.
+	Offline bool
+}
+
+// Names lists the supported providers
+var Names = []string{"openai", "anthropic"}
+
+// Models lists the models offered for each provider in the model picker. Any other model the
+// provider serves can still be set with the model setting.
+var Models = map[string][]string{
+	"openai":    {"gpt-4o", "gpt-4o-mini"},
+	"anthropic": {"claude-3-5-sonnet-20241022", "claude-3-opus-20240229", "claude-3-haiku-20240307"},
+}
+
+// New creates a provider by name ("openai" or "anthropic"); an empty name defaults to OpenAI
+func New(name, apiKey string, opts Options) (Provider, error) {
+	if opts.Offline {
+		if name == "" {
+			name = "openai"
+		}
+		if !slices.Contains(Names, name) {
+			return nil, unknownProvider(name)
+		}
+		return offline{name: name}, nil
+	}
+	var p Provider
+	switch name {
+	case "", "openai":
+		openai, err := newOpenAIProvider(apiKey, opts.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		openai.SetDeterministic(opts.Deterministic)
+		name, p = "openai", openai
+	case "anthropic":
+		anthropic, err := newAnthropicProvider(apiKey, opts.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		anthropic.SetDeterministic(opts.Deterministic)
+		p = anthropic
+	default:
+		return nil, unknownProvider(name)
+	}
+	if opts.Audit != nil {
+		p = audited{Provider: p, name: name, log: opts.Audit}
+	}
+	if opts.Retries > 0 {
+		// Outside the audit log, so it records every attempt
+		p = retrying{Provider: p, retries: opts.Retries, backoff: retryBackoff}
+	}
+	if opts.Redact {
+		// Outside the audit log, so it records the request as it was sent, and outside the
+		// retries, so the text is redacted once however many attempts it takes
+		p = redacted{Provider: p}
+	}
+	return traced{Provider: p, name: name}, nil
+}
+
+func unknownProvider(name string) error {
+	return fmt.Errorf("unknown provider: %s (supported: openai, anthropic)", name)
+}
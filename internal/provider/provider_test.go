@@ -4,8 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/audit"
+	"github.com/maximbilan/grammr/internal/redact"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestNewOpenAIProvider(t *testing.T) {
@@ -229,6 +245,62 @@ func TestProviderInterface(t *testing.T) {
 	})
 }
 
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		wantErr  bool
+	}{
+		{name: "default", provider: "", wantErr: false},
+		{name: "openai", provider: "openai", wantErr: false},
+		{name: "anthropic", provider: "anthropic", wantErr: false},
+		{name: "unknown", provider: "gemini", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov, err := New(tt.provider, "test-key", Options{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New(%q) error = %v, wantErr %v", tt.provider, err, tt.wantErr)
+			}
+			if !tt.wantErr && prov == nil {
+				t.Fatalf("New(%q) returned nil provider", tt.provider)
+			}
+		})
+	}
+}
+
+func TestDeterministicParams(t *testing.T) {
+	openaiProv, err := NewOpenAIProvider("test-key")
+	if err != nil {
+		t.Fatalf("NewOpenAIProvider() error = %v", err)
+	}
+	params := openaiProv.newParams("gpt-4o", nil)
+	if params.Temperature.Valid() || params.Seed.Valid() {
+		t.Fatal("OpenAI params should not set temperature or seed by default")
+	}
+	openaiProv.SetDeterministic(true)
+	params = openaiProv.newParams("gpt-4o", nil)
+	if params.Temperature.Value != 0 || !params.Temperature.Valid() {
+		t.Errorf("OpenAI temperature = %v, want 0", params.Temperature)
+	}
+	if params.Seed.Value != DeterministicSeed {
+		t.Errorf("OpenAI seed = %d, want %d", params.Seed.Value, DeterministicSeed)
+	}
+
+	anthropicProv, err := NewAnthropicProvider("test-key")
+	if err != nil {
+		t.Fatalf("NewAnthropicProvider() error = %v", err)
+	}
+	if anthropicProv.newParams("claude", nil, nil).Temperature.Valid() {
+		t.Fatal("Anthropic params should not set temperature by default")
+	}
+	anthropicProv.SetDeterministic(true)
+	if temp := anthropicProv.newParams("claude", nil, nil).Temperature; !temp.Valid() || temp.Value != 0 {
+		t.Errorf("Anthropic temperature = %v, want 0", temp)
+	}
+}
+
 func TestToOpenAIMessages(t *testing.T) {
 	messages := []Message{
 		{Role: RoleSystem, Content: "system"},
@@ -281,3 +353,370 @@ func TestToAnthropicMessages(t *testing.T) {
 		t.Fatalf("anthropicMessages length = %d, want 2", len(anthropicMessages))
 	}
 }
+
+func TestProviderErrorsAreClassified(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		status   int
+		body     string
+		want     apierror.Kind
+	}{
+		{
+			name:     "openai invalid key",
+			provider: "openai",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`,
+			want:     apierror.KindInvalidKey,
+		},
+		{
+			name:     "openai model not found",
+			provider: "openai",
+			status:   http.StatusNotFound,
+			body:     `{"error":{"message":"The model gpt-5 does not exist","type":"invalid_request_error","code":"model_not_found"}}`,
+			want:     apierror.KindModelNotFound,
+		},
+		{
+			name:     "anthropic prompt too long",
+			provider: "anthropic",
+			status:   http.StatusBadRequest,
+			body:     `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 250000 tokens > 200000 maximum"}}`,
+			want:     apierror.KindContextLength,
+		},
+		{
+			name:     "anthropic quota",
+			provider: "anthropic",
+			status:   http.StatusBadRequest,
+			body:     `{"type":"error","error":{"type":"invalid_request_error","message":"Your credit balance is too low to access the Anthropic API."}}`,
+			want:     apierror.KindQuotaExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			var p Provider
+			if tt.provider == "anthropic" {
+				p = &AnthropicProvider{client: anthropic.NewClient(anthropicoption.WithAPIKey("test"), anthropicoption.WithBaseURL(server.URL), anthropicoption.WithMaxRetries(0))}
+			} else {
+				p = &OpenAIProvider{client: openai.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL), option.WithMaxRetries(0))}
+			}
+
+			_, err := p.Chat(context.Background(), "gpt-5", []Message{{Role: RoleUser, Content: "Helo"}})
+			if got := apierror.KindOf(err); got != tt.want {
+				t.Errorf("Chat() error kind = %v, want %v (error: %v)", got, tt.want, err)
+			}
+			err = p.StreamChat(context.Background(), "gpt-5", []Message{{Role: RoleUser, Content: "Helo"}}, func(string) {})
+			if got := apierror.KindOf(err); got != tt.want {
+				t.Errorf("StreamChat() error kind = %v, want %v (error: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestTracedProvider(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	p := traced{Provider: NewMockProvider(), name: "openai"}
+	messages := []Message{{Role: RoleUser, Content: "Helo"}}
+	if _, err := p.Chat(context.Background(), "gpt-4o", messages); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StreamChat(context.Background(), "gpt-4o", messages, func(string) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "provider.chat" || spans[1].Name() != "provider.stream_chat" {
+		t.Fatalf("ended spans = %v, want a chat and a stream", spans)
+	}
+	if events := spans[1].Events(); len(events) != 1 || events[0].Name != "first chunk" {
+		t.Errorf("stream events = %v, want the first chunk marked", events)
+	}
+}
+
+// countingProvider counts the requests that reach the provider
+type countingProvider struct {
+	Provider
+	requests int
+}
+
+func (p *countingProvider) Chat(ctx context.Context, model string, messages []Message) (string, error) {
+	p.requests++
+	return p.Provider.Chat(ctx, model, messages)
+}
+
+func (p *countingProvider) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	p.requests++
+	return p.Provider.StreamChat(ctx, model, messages, onChunk)
+}
+
+func TestAuditedProvider(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	dir := t.TempDir()
+	log := audit.New(filepath.Join(dir, audit.DefaultFile), key)
+	inner := &countingProvider{Provider: NewMockProvider()}
+	p := audited{Provider: inner, name: "anthropic", log: log}
+
+	messages := []Message{{Role: RoleSystem, Content: "Fix the grammar"}, {Role: RoleUser, Content: "Helo"}}
+	if _, err := p.Chat(context.Background(), "claude-3-haiku-20240307", messages); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StreamChat(context.Background(), "claude-3-haiku-20240307", messages, func(string) {}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 || inner.requests != 2 {
+		t.Fatalf("recorded %d requests and sent %d, want both 2", len(entries), inner.requests)
+	}
+	if entries[0].Provider != "anthropic" || entries[0].Model != "claude-3-haiku-20240307" || entries[0].Messages[1].Content != "Helo" {
+		t.Errorf("recorded %+v, want the request as sent", entries[0])
+	}
+
+	// A log that can't be written stops requests from being sent
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	p.log = audit.New(filepath.Join(blocker, audit.DefaultFile), key)
+	if _, err := p.Chat(context.Background(), "claude-3-haiku-20240307", messages); err == nil {
+		t.Error("Chat() should fail when the request can't be recorded")
+	}
+	if inner.requests != 2 {
+		t.Errorf("a request that couldn't be recorded was sent")
+	}
+}
+
+// failingTransport fails every request, recording that it was asked
+type failingTransport struct {
+	requests int
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return nil, errors.New("no network in tests")
+}
+
+func TestNewUsesHTTPClient(t *testing.T) {
+	for _, name := range Names {
+		t.Run(name, func(t *testing.T) {
+			transport := &failingTransport{}
+			p, err := New(name, "test-key", Options{HTTPClient: &http.Client{Transport: transport}})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := p.Chat(ctx, Models[name][0], []Message{{Role: RoleUser, Content: "Hi"}}); err == nil {
+				t.Fatal("Chat() should fail")
+			}
+			if transport.requests == 0 {
+				t.Error("Chat() should send its request through the HTTP client")
+			}
+		})
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	client := NewHTTPClient()
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport.MaxIdleConnsPerHost < 4 {
+		t.Errorf("NewHTTPClient() keeps too few connections: %+v", client.Transport)
+	}
+	if client.Timeout != 0 {
+		t.Error("NewHTTPClient() should leave timeouts to the contexts of requests")
+	}
+}
+
+// recordingProvider keeps the messages of the last request
+type recordingProvider struct {
+	Provider
+	messages []Message
+}
+
+func (p *recordingProvider) Chat(ctx context.Context, model string, messages []Message) (string, error) {
+	p.messages = messages
+	return p.Provider.Chat(ctx, model, messages)
+}
+
+func (p *recordingProvider) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	p.messages = messages
+	return p.Provider.StreamChat(ctx, model, messages, onChunk)
+}
+
+func TestRedactedProvider(t *testing.T) {
+	inner := &recordingProvider{Provider: NewMockProvider()}
+	p := redacted{Provider: inner}
+	messages := []Message{{Role: RoleSystem, Content: "Fix the grammar"}, {Role: RoleUser, Content: "Ask Anna Lee at anna@example.com"}}
+
+	response, err := p.Chat(context.Background(), "gpt-4o", messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := inner.messages
+	if len(sent) != 3 || sent[0].Content != redact.Instruction || sent[1].Content != "Fix the grammar" {
+		t.Fatalf("sent %+v, want the placeholders explained before the instructions", sent)
+	}
+	if sent[2].Content != "Ask [NAME_1] at [EMAIL_1]" {
+		t.Errorf("sent %q, want the personal data redacted", sent[2].Content)
+	}
+	if want := "Mock response for: Ask Anna Lee at anna@example.com"; response != want {
+		t.Errorf("Chat() = %q, want %q", response, want)
+	}
+	if messages[1].Content != "Ask Anna Lee at anna@example.com" {
+		t.Error("the caller's messages should not be changed")
+	}
+
+	// The mock streams a character at a time, splitting every placeholder
+	var streamed strings.Builder
+	if err := p.StreamChat(context.Background(), "gpt-4o", messages, func(chunk string) { streamed.WriteString(chunk) }); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Mock response for: Ask Anna Lee at anna@example.com"; streamed.String() != want {
+		t.Errorf("StreamChat() = %q, want %q", streamed.String(), want)
+	}
+
+	// Nothing to redact, nothing added
+	if _, err := p.Chat(context.Background(), "gpt-4o", []Message{{Role: RoleUser, Content: "Helo"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.messages) != 1 {
+		t.Errorf("sent %+v, want the request unchanged", inner.messages)
+	}
+}
+
+func TestNewOffline(t *testing.T) {
+	transport := &failingTransport{}
+	opts := Options{Offline: true, HTTPClient: &http.Client{Transport: transport}}
+	for _, name := range []string{"", "openai", "anthropic"} {
+		// No API key is needed, since nothing is sent
+		p, err := New(name, "", opts)
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", name, err)
+		}
+		if _, err := p.Chat(context.Background(), "gpt-4o", []Message{{Role: RoleUser, Content: "Helo"}}); !errors.Is(err, ErrOffline) {
+			t.Errorf("Chat() error = %v, want ErrOffline", err)
+		}
+		if err := p.StreamChat(context.Background(), "gpt-4o", []Message{{Role: RoleUser, Content: "Helo"}}, func(string) {}); !errors.Is(err, ErrOffline) {
+			t.Errorf("StreamChat() error = %v, want ErrOffline", err)
+		}
+	}
+	if transport.requests != 0 {
+		t.Errorf("%d requests were sent offline", transport.requests)
+	}
+	if _, err := New("gemini", "", opts); err == nil {
+		t.Error("New() should reject an unknown provider offline too")
+	}
+}
+
+// flakyProvider fails its first requests with err, streaming partial before failing when set
+type flakyProvider struct {
+	Provider
+	failures int
+	err      error
+	partial  string
+	requests int
+}
+
+func (p *flakyProvider) Chat(ctx context.Context, model string, messages []Message) (string, error) {
+	p.requests++
+	if p.requests <= p.failures {
+		return "", p.err
+	}
+	return p.Provider.Chat(ctx, model, messages)
+}
+
+func (p *flakyProvider) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	p.requests++
+	if p.requests <= p.failures {
+		if p.partial != "" {
+			onChunk(p.partial)
+		}
+		return p.err
+	}
+	return p.Provider.StreamChat(ctx, model, messages, onChunk)
+}
+
+func TestRetryingProvider(t *testing.T) {
+	rateLimited := &apierror.Error{Kind: apierror.KindRateLimited, Provider: "openai", Err: errors.New("429")}
+	invalidKey := &apierror.Error{Kind: apierror.KindInvalidKey, Provider: "openai", Err: errors.New("401")}
+	messages := []Message{{Role: RoleUser, Content: "Helo"}}
+
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+		partial  string
+		wantErr  bool
+		requests int
+	}{
+		{name: "succeeds after retries", failures: 2, err: rateLimited, requests: 3},
+		{name: "runs out of retries", failures: 5, err: rateLimited, wantErr: true, requests: 3},
+		{name: "not transient", failures: 1, err: invalidKey, wantErr: true, requests: 1},
+		{name: "streamed part of the response", failures: 1, err: rateLimited, partial: "Mo", wantErr: true, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, stream := range []bool{false, true} {
+				if tt.partial != "" && !stream {
+					continue
+				}
+				inner := &flakyProvider{Provider: NewMockProvider(), failures: tt.failures, err: tt.err, partial: tt.partial}
+				p := retrying{Provider: inner, retries: 2, backoff: time.Millisecond}
+				var delays []time.Duration
+				ctx := WithRetryNotify(context.Background(), func(attempt int, delay time.Duration, err error) {
+					if attempt != len(delays)+1 || !errors.Is(err, tt.err) {
+						t.Errorf("notify(%d, %v, %v), want attempt %d of %v", attempt, delay, err, len(delays)+1, tt.err)
+					}
+					delays = append(delays, delay)
+				})
+
+				var err error
+				if stream {
+					err = p.StreamChat(ctx, "gpt-4o", messages, func(string) {})
+				} else {
+					_, err = p.Chat(ctx, "gpt-4o", messages)
+				}
+				if (err != nil) != tt.wantErr {
+					t.Errorf("stream %v: error = %v, wantErr %v", stream, err, tt.wantErr)
+				}
+				if inner.requests != tt.requests {
+					t.Errorf("stream %v: %d requests, want %d", stream, inner.requests, tt.requests)
+				}
+				if len(delays) != tt.requests-1 {
+					t.Errorf("stream %v: notified of %d retries, want %d", stream, len(delays), tt.requests-1)
+				}
+				for i := 1; i < len(delays); i++ {
+					if delays[i] != 2*delays[i-1] {
+						t.Errorf("stream %v: delays %v, want each one doubled", stream, delays)
+					}
+				}
+			}
+		})
+	}
+
+	// A cancelled request isn't retried
+	inner := &flakyProvider{Provider: NewMockProvider(), failures: 5, err: rateLimited}
+	p := retrying{Provider: inner, retries: 2, backoff: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithRetryNotify(ctx, func(int, time.Duration, error) { cancel() })
+	if _, err := p.Chat(ctx, "gpt-4o", messages); !errors.Is(err, rateLimited) {
+		t.Errorf("Chat() error = %v, want the last failure", err)
+	}
+	if inner.requests != 1 {
+		t.Errorf("%d requests, want the cancelled retry not sent", inner.requests)
+	}
+}
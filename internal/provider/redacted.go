@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/maximbilan/grammr/internal/redact"
+)
+
+// redacted replaces the personal data in the messages of a provider with placeholders before
+// sending them, and puts it back in the response. Each request gets its own placeholders.
+type redacted struct {
+	Provider
+}
+
+// StreamChat streams a chat completion response
+func (p redacted) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	r, messages := redactMessages(messages)
+	stream := r.NewStream(onChunk)
+	if err := p.Provider.StreamChat(ctx, model, messages, stream.Write); err != nil {
+		return err
+	}
+	stream.Close()
+	return nil
+}
+
+// Chat performs a non-streaming chat completion
+func (p redacted) Chat(ctx context.Context, model string, messages []Message) (string, error) {
+	r, messages := redactMessages(messages)
+	response, err := p.Provider.Chat(ctx, model, messages)
+	if err != nil {
+		return "", err
+	}
+	return r.Restore(response), nil
+}
+
+// redactMessages redacts the user and assistant messages, leaving the instructions alone. When
+// anything was redacted, the model is told to keep the placeholders.
+func redactMessages(messages []Message) (*redact.Redactor, []Message) {
+	r := redact.New()
+	sent := make([]Message, 0, len(messages)+1)
+	for _, message := range messages {
+		if message.Role != RoleSystem {
+			message.Content = r.Redact(message.Content)
+		}
+		sent = append(sent, message)
+	}
+	if r.Len() > 0 {
+		sent = append([]Message{{Role: RoleSystem, Content: redact.Instruction}}, sent...)
+	}
+	return r, sent
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/apierror"
+)
+
+// retryBackoff is the wait before the first retry of a request, doubled for each one after it
+const retryBackoff = time.Second
+
+// retryNotifyKey is the context key of the function told about retries
+type retryNotifyKey struct{}
+
+// WithRetryNotify returns a context that makes a provider created with Options.Retries call
+// notify before it waits to send a failed request again, with the attempt about to be made
+// (1 for the first retry), how long it waits and the error the request failed with
+func WithRetryNotify(ctx context.Context, notify func(attempt int, delay time.Duration, err error)) context.Context {
+	return context.WithValue(ctx, retryNotifyKey{}, notify)
+}
+
+// retrying sends a request again when it failed in a way that may not happen again, such as a
+// rate limit or a dropped connection, waiting longer before each attempt
+type retrying struct {
+	Provider
+	retries int
+	backoff time.Duration // Wait before the first retry
+}
+
+// StreamChat streams a chat completion response. A request that streamed part of its response
+// isn't sent again, as the chunks can't be taken back.
+func (p retrying) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	return p.retry(ctx, func() (bool, error) {
+		streamed := false
+		err := p.Provider.StreamChat(ctx, model, messages, func(chunk string) {
+			streamed = true
+			onChunk(chunk)
+		})
+		return !streamed, err
+	})
+}
+
+// Chat performs a non-streaming chat completion
+func (p retrying) Chat(ctx context.Context, model string, messages []Message) (string, error) {
+	var response string
+	err := p.retry(ctx, func() (bool, error) {
+		var err error
+		response, err = p.Provider.Chat(ctx, model, messages)
+		return true, err
+	})
+	return response, err
+}
+
+// retry calls send until it succeeds, fails for good, says it can't be retried or runs out of
+// retries. A cancelled or expired ctx ends the wait with the error of the last attempt.
+func (p retrying) retry(ctx context.Context, send func() (retryable bool, err error)) error {
+	delay := p.backoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := send()
+		if err == nil || !retryable || attempt > p.retries || !apierror.Transient(err) || ctx.Err() != nil {
+			return err
+		}
+		if notify, ok := ctx.Value(retryNotifyKey{}).(func(int, time.Duration, error)); ok && notify != nil {
+			notify(attempt, delay, err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/maximbilan/grammr/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// traced records a span for every request of a provider, so a slow correction can be told apart
+// from a slow clipboard or cache when tracing is on
+type traced struct {
+	Provider
+	name string
+}
+
+// StreamChat streams a chat completion response, marking when the first chunk arrived
+func (p traced) StreamChat(ctx context.Context, model string, messages []Message, onChunk func(string)) error {
+	ctx, span := tracing.Start(ctx, "provider.stream_chat", p.attributes(model)...)
+	first := true
+	err := p.Provider.StreamChat(ctx, model, messages, func(chunk string) {
+		if first {
+			span.AddEvent("first chunk")
+			first = false
+		}
+		onChunk(chunk)
+	})
+	tracing.End(span, err)
+	return err
+}
+
+// Chat performs a non-streaming chat completion
+func (p traced) Chat(ctx context.Context, model string, messages []Message) (string, error) {
+	ctx, span := tracing.Start(ctx, "provider.chat", p.attributes(model)...)
+	resp, err := p.Provider.Chat(ctx, model, messages)
+	tracing.End(span, err)
+	return resp, err
+}
+
+func (p traced) attributes(model string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("gen_ai.system", p.name),
+		attribute.String("gen_ai.request.model", model),
+	}
+}
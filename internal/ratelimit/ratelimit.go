@@ -3,25 +3,24 @@ package ratelimit
 import (
 	"context"
 	"fmt"
-	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
-// RateLimiter implements a token bucket rate limiter to prevent excessive API calls
+// RateLimiter implements a token bucket rate limiter to prevent excessive API calls. The zero
+// value doesn't limit anything.
 type RateLimiter struct {
-	mu          sync.Mutex
-	tokens      int           // Current number of tokens available
-	maxTokens   int           // Maximum number of tokens
-	refillRate  time.Duration // Time between token refills
-	lastRefill  time.Time     // Last time tokens were refilled
-	minInterval time.Duration // Minimum time between requests
-	lastRequest time.Time     // Last request time
+	limiter  *rate.Limiter // Requests on average, with bursts
+	interval *rate.Limiter // Minimum time between requests
+	shared   *shared       // Budget shared with other processes, used instead of the limiters above
 }
 
 // New creates a new rate limiter
 // maxRequests: maximum number of requests allowed
 // perDuration: time window for maxRequests (e.g., 60 requests per minute)
 // minInterval: minimum time between requests (prevents burst requests)
+// All maxRequests may be used at once, see NewWithBurst for a smaller burst.
 func New(maxRequests int, perDuration time.Duration, minInterval time.Duration) *RateLimiter {
 	if maxRequests <= 0 {
 		maxRequests = 60 // Default: 60 requests
@@ -29,95 +28,112 @@ func New(maxRequests int, perDuration time.Duration, minInterval time.Duration)
 	if perDuration <= 0 {
 		perDuration = time.Minute // Default: per minute
 	}
+	return NewWithBurst(float64(maxRequests)/perDuration.Seconds(), maxRequests, minInterval)
+}
+
+// NewWithBurst creates a rate limiter that allows perSecond requests a second on average, which
+// may be a fraction such as 0.5 for one request every two seconds, and up to burst requests at
+// once after a quiet period. minInterval is the minimum time between requests, 100ms when not
+// positive.
+func NewWithBurst(perSecond float64, burst int, minInterval time.Duration) *RateLimiter {
+	if perSecond <= 0 {
+		perSecond = 1 // Default: 60 requests per minute
+	}
+	if burst <= 0 {
+		burst = 1
+	}
 	if minInterval <= 0 {
 		minInterval = 100 * time.Millisecond // Default: 100ms between requests
 	}
-
-	refillRate := perDuration / time.Duration(maxRequests)
-	if refillRate <= 0 {
-		// Defensive clamp for extreme config values (e.g. 1ms window, huge request count).
-		refillRate = time.Nanosecond
-	}
-
 	return &RateLimiter{
-		tokens:      maxRequests,
-		maxTokens:   maxRequests,
-		refillRate:  refillRate,
-		lastRefill:  time.Now(),
-		minInterval: minInterval,
-		lastRequest: time.Time{},
+		limiter:  rate.NewLimiter(rate.Limit(perSecond), burst),
+		interval: rate.NewLimiter(rate.Every(minInterval), 1),
 	}
 }
 
-// Wait blocks until a token is available, respecting rate limits
-func (rl *RateLimiter) Wait(ctx context.Context) error {
-	for {
-		rl.mu.Lock()
+// waitNotifyKey is the context key of the function told about waits
+type waitNotifyKey struct{}
 
-		// Defensive guard: prevent division-by-zero if a malformed limiter is constructed.
-		if rl.refillRate <= 0 {
-			rl.refillRate = time.Nanosecond
-		}
+// WithWaitNotify returns a context that makes Wait call notify with how long it is going to
+// block before it blocks, so a caller can tell the user why nothing is happening yet
+func WithWaitNotify(ctx context.Context, notify func(time.Duration)) context.Context {
+	return context.WithValue(ctx, waitNotifyKey{}, notify)
+}
 
-		now := time.Now()
+// Wait blocks until a request may be made, respecting rate limits
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("rate limit wait cancelled: %w", err)
+	}
+	delay, cancel := rl.reserve(time.Now())
+	if delay == 0 {
+		return nil
+	}
+	if notify, ok := ctx.Value(waitNotifyKey{}).(func(time.Duration)); ok && notify != nil {
+		notify(delay)
+	}
 
-		// Refill tokens based on elapsed time.
-		elapsed := now.Sub(rl.lastRefill)
-		if elapsed > 0 {
-			tokensToAdd := int(elapsed / rl.refillRate)
-			if tokensToAdd > 0 {
-				rl.tokens = min(rl.maxTokens, rl.tokens+tokensToAdd)
-				rl.lastRefill = now
-			}
-		}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// Give the tokens back, so a cancelled request doesn't slow down the next one
+		cancel()
+		return fmt.Errorf("rate limit wait cancelled: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
 
-		// Check minimum interval between requests.
-		if !rl.lastRequest.IsZero() {
-			timeSinceLastRequest := now.Sub(rl.lastRequest)
-			if timeSinceLastRequest < rl.minInterval {
-				waitTime := rl.minInterval - timeSinceLastRequest
-				rl.mu.Unlock()
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case <-time.After(waitTime):
-				}
-				continue
-			}
-		}
+// TryWait takes a request without blocking when one may be made right away. Otherwise it takes
+// nothing and returns how long Wait would block, for the UI to show.
+func (rl *RateLimiter) TryWait() (time.Duration, bool) {
+	delay, cancel := rl.reserve(time.Now())
+	if delay > 0 {
+		cancel()
+		return delay, false
+	}
+	return 0, true
+}
 
-		// Consume a token if available.
-		if rl.tokens > 0 {
-			rl.tokens--
-			rl.lastRequest = now
-			rl.mu.Unlock()
-			return nil
-		}
+// Share makes the limiter draw from the budget stored under key in the database at path, which
+// other grammr processes limiting the same key share. When the database can't be used, the
+// limiter falls back to a budget of this process only.
+func (rl *RateLimiter) Share(path, key string) {
+	if rl.limiter == nil {
+		return
+	}
+	rl.shared = &shared{
+		path:        path,
+		key:         key,
+		perSecond:   float64(rl.limiter.Limit()),
+		burst:       rl.limiter.Burst(),
+		minInterval: time.Duration(float64(time.Second) / float64(rl.interval.Limit())),
+	}
+}
 
-		// Wait for next token availability.
-		nextTokenTime := rl.lastRefill.Add(rl.refillRate)
-		waitTime := nextTokenTime.Sub(now)
-		if waitTime <= 0 {
-			waitTime = rl.refillRate
-			if waitTime <= 0 {
-				waitTime = time.Nanosecond
-			}
+// reserve takes a request at now from both limiters, returning how long to wait before making
+// it and a function that gives it back
+func (rl *RateLimiter) reserve(now time.Time) (time.Duration, func()) {
+	if rl.shared != nil {
+		if delay, cancel, err := rl.shared.reserve(now); err == nil {
+			return delay, cancel
 		}
+	}
 
-		rl.mu.Unlock()
-
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("rate limit wait cancelled: %w", ctx.Err())
-		case <-time.After(waitTime):
+	var delay time.Duration
+	var reservations []*rate.Reservation
+	for _, limiter := range []*rate.Limiter{rl.limiter, rl.interval} {
+		if limiter == nil {
+			continue
 		}
+		r := limiter.ReserveN(now, 1)
+		reservations = append(reservations, r)
+		delay = max(delay, r.DelayFrom(now))
 	}
-}
-
-// min returns the minimum of two integers
-func min(a, b int) int {
-	if a < b {
-		return a
+	return delay, func() {
+		for _, r := range reservations {
+			r.Cancel()
+		}
 	}
-	return b
 }
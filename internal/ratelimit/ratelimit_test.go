@@ -9,8 +9,8 @@ import (
 
 func TestNewClampsRefillRate(t *testing.T) {
 	rl := New(1_000_000, time.Millisecond, 0)
-	if rl.refillRate <= 0 {
-		t.Fatalf("refillRate must be > 0, got %v", rl.refillRate)
+	if rl.limiter.Limit() <= 0 {
+		t.Fatalf("rate must be > 0, got %v", rl.limiter.Limit())
 	}
 }
 
@@ -42,13 +42,7 @@ func TestWaitContextCancelDoesNotPanic(t *testing.T) {
 
 func TestWaitHandlesMalformedLimiter(t *testing.T) {
 	// Simulate a malformed limiter created outside New().
-	rl := &RateLimiter{
-		tokens:      0,
-		maxTokens:   0,
-		refillRate:  0,
-		lastRefill:  time.Now(),
-		minInterval: time.Millisecond,
-	}
+	rl := &RateLimiter{}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -67,3 +61,87 @@ func TestWaitHandlesMalformedLimiter(t *testing.T) {
 		t.Fatalf("expected context.Canceled, got %v", err)
 	}
 }
+
+func TestTryWait(t *testing.T) {
+	rl := NewWithBurst(1, 2, time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if delay, ok := rl.TryWait(); !ok {
+			t.Fatalf("TryWait() #%d = %v, false, want a request of the burst", i+1, delay)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	delay, ok := rl.TryWait()
+	if ok || delay <= 0 || delay > time.Second {
+		t.Fatalf("TryWait() after the burst = %v, %v, want to wait up to a second", delay, ok)
+	}
+	// A refused request takes nothing
+	if again, _ := rl.TryWait(); again > delay {
+		t.Errorf("TryWait() again = %v, want no more than %v", again, delay)
+	}
+}
+
+func TestTryWaitMinInterval(t *testing.T) {
+	rl := New(60, time.Minute, time.Hour)
+
+	if _, ok := rl.TryWait(); !ok {
+		t.Fatal("first TryWait() was refused")
+	}
+	if delay, ok := rl.TryWait(); ok || delay < 59*time.Minute {
+		t.Fatalf("TryWait() within the minimum interval = %v, %v, want to wait about an hour", delay, ok)
+	}
+}
+
+func TestFractionalRate(t *testing.T) {
+	// One request every two seconds
+	rl := NewWithBurst(0.5, 1, time.Millisecond)
+
+	if _, ok := rl.TryWait(); !ok {
+		t.Fatal("first TryWait() was refused")
+	}
+	delay, ok := rl.TryWait()
+	if ok || delay <= time.Second || delay > 2*time.Second {
+		t.Fatalf("TryWait() = %v, %v, want to wait about two seconds", delay, ok)
+	}
+}
+
+func TestWaitCancelGivesTokenBack(t *testing.T) {
+	rl := NewWithBurst(10, 1, time.Millisecond)
+	if err := rl.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := rl.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() = %v, want the deadline exceeded", err)
+	}
+	// Only the first request was made, so the next one waits at most one interval of the rate
+	if err := rl.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() after cancelling failed: %v", err)
+	}
+	if delay, ok := rl.TryWait(); ok || delay > 100*time.Millisecond {
+		t.Errorf("TryWait() = %v, %v, want to wait at most 100ms", delay, ok)
+	}
+}
+
+func TestWaitNotify(t *testing.T) {
+	rl := NewWithBurst(20, 1, time.Millisecond)
+	var waits []time.Duration
+	ctx := WithWaitNotify(context.Background(), func(delay time.Duration) {
+		waits = append(waits, delay)
+	})
+
+	if err := rl.Wait(ctx); err != nil {
+		t.Fatalf("first Wait() failed: %v", err)
+	}
+	if len(waits) != 0 {
+		t.Fatalf("Wait() notified %v without blocking", waits)
+	}
+	if err := rl.Wait(ctx); err != nil {
+		t.Fatalf("second Wait() failed: %v", err)
+	}
+	if len(waits) != 1 || waits[0] <= 0 || waits[0] > 50*time.Millisecond {
+		t.Errorf("Wait() notified %v, want one wait of up to 50ms", waits)
+	}
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operations that budgets can be set for
+const (
+	// OperationCorrection covers corrections and everything else the corrector asks for, such as
+	// rewrites and explanations
+	OperationCorrection = "correction"
+	// OperationTranslation covers translations
+	OperationTranslation = "translation"
+)
+
+// Budget is how many requests may be made in a window of time
+type Budget struct {
+	Requests int
+	Window   time.Duration
+	Burst    int // Requests allowed at once, Requests when not positive
+}
+
+// Registry hands out the limiters of budgets, so operations that share a budget share its limiter
+// however often correctors and translators are recreated. A nil Registry doesn't limit anything.
+type Registry struct {
+	mu          sync.Mutex
+	defaults    Budget
+	budgets     map[string]Budget // By operation, provider or "provider/operation"
+	minInterval time.Duration
+	limiters    map[string]*RateLimiter
+	path        string // Database of budgets shared with other processes, if any
+}
+
+// NewRegistry creates a registry of budgets keyed by operation ("translation"), provider
+// ("anthropic") or both ("anthropic/translation"). Operations and providers without a budget of
+// their own share defaults, one budget per provider. Unset fields of a budget are taken from
+// defaults.
+func NewRegistry(defaults Budget, budgets map[string]Budget, minInterval time.Duration) *Registry {
+	normalized := make(map[string]Budget, len(budgets))
+	for key, budget := range budgets {
+		normalized[strings.ToLower(strings.TrimSpace(key))] = budget
+	}
+	return &Registry{
+		defaults:    defaults,
+		budgets:     normalized,
+		minInterval: minInterval,
+		limiters:    make(map[string]*RateLimiter),
+	}
+}
+
+// Share makes the limiters of the registry draw from budgets stored in the database at path, so
+// every grammr process sharing it keeps to one budget. It must be called before Limiter.
+func (r *Registry) Share(path string) {
+	if r != nil {
+		r.path = path
+	}
+}
+
+// Limiter returns the limiter of operation with provider. The most specific budget applies: the
+// one for both, then the one for the operation, then the one for the provider.
+func (r *Registry) Limiter(provider, operation string) *RateLimiter {
+	if r == nil {
+		return nil
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+
+	// Quotas are per provider, so every provider gets its own limiter of a budget
+	key, budget := provider, r.defaults
+	if b, ok := r.budgets[provider+"/"+operation]; ok {
+		key, budget = provider+"/"+operation, r.withDefaults(b)
+	} else if b, ok := r.budgets[operation]; ok {
+		key, budget = provider+"/"+operation, r.withDefaults(b)
+	} else if b, ok := r.budgets[provider]; ok {
+		budget = r.withDefaults(b)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	limiter, ok := r.limiters[key]
+	if !ok {
+		limiter = budget.limiter(r.minInterval)
+		if r.path != "" {
+			limiter.Share(r.path, key)
+		}
+		r.limiters[key] = limiter
+	}
+	return limiter
+}
+
+// withDefaults fills the unset fields of b from the default budget
+func (r *Registry) withDefaults(b Budget) Budget {
+	if b.Requests <= 0 {
+		b.Requests = r.defaults.Requests
+		if b.Burst <= 0 {
+			b.Burst = r.defaults.Burst
+		}
+	}
+	if b.Window <= 0 {
+		b.Window = r.defaults.Window
+	}
+	return b
+}
+
+// limiter creates a limiter for the budget
+func (b Budget) limiter(minInterval time.Duration) *RateLimiter {
+	if b.Requests <= 0 || b.Window <= 0 {
+		return New(b.Requests, b.Window, minInterval)
+	}
+	burst := b.Burst
+	if burst <= 0 {
+		burst = b.Requests
+	}
+	return NewWithBurst(float64(b.Requests)/b.Window.Seconds(), burst, minInterval)
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistryLimiter(t *testing.T) {
+	defaults := Budget{Requests: 60, Window: time.Minute}
+	r := NewRegistry(defaults, map[string]Budget{
+		"translation":           {Requests: 30},
+		"Anthropic":             {Requests: 10, Window: time.Second},
+		"anthropic/translation": {Requests: 2, Window: time.Second, Burst: 1},
+	}, time.Millisecond)
+
+	tests := []struct {
+		provider  string
+		operation string
+		wantRate  float64
+		wantBurst int
+	}{
+		{provider: "openai", operation: OperationCorrection, wantRate: 1, wantBurst: 60},
+		{provider: "openai", operation: OperationTranslation, wantRate: 0.5, wantBurst: 30},
+		{provider: "anthropic", operation: OperationCorrection, wantRate: 10, wantBurst: 10},
+		{provider: "anthropic", operation: OperationTranslation, wantRate: 2, wantBurst: 1},
+	}
+
+	for _, tt := range tests {
+		limiter := r.Limiter(tt.provider, tt.operation)
+		if got := float64(limiter.limiter.Limit()); got != tt.wantRate {
+			t.Errorf("Limiter(%q, %q) rate = %v, want %v", tt.provider, tt.operation, got, tt.wantRate)
+		}
+		if got := limiter.limiter.Burst(); got != tt.wantBurst {
+			t.Errorf("Limiter(%q, %q) burst = %d, want %d", tt.provider, tt.operation, got, tt.wantBurst)
+		}
+	}
+}
+
+func TestRegistrySharesLimiters(t *testing.T) {
+	r := NewRegistry(Budget{Requests: 60, Window: time.Minute}, map[string]Budget{
+		"translation": {Requests: 30},
+	}, time.Millisecond)
+
+	if r.Limiter("openai", OperationCorrection) != r.Limiter("openai", OperationCorrection) {
+		t.Error("the same operation got two limiters")
+	}
+	if r.Limiter("openai", OperationCorrection) == r.Limiter("openai", OperationTranslation) {
+		t.Error("operations with their own budgets share a limiter")
+	}
+	if r.Limiter("openai", OperationTranslation) == r.Limiter("anthropic", OperationTranslation) {
+		t.Error("providers share a limiter")
+	}
+
+	shared := NewRegistry(Budget{Requests: 60, Window: time.Minute}, nil, time.Millisecond)
+	if shared.Limiter("openai", OperationCorrection) != shared.Limiter("openai", OperationTranslation) {
+		t.Error("operations without budgets of their own don't share the default one")
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	if limiter := r.Limiter("openai", OperationCorrection); limiter != nil {
+		t.Errorf("Limiter() = %v, want no limit", limiter)
+	}
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// DefaultFile is the name of the database of shared budgets in ~/.grammr
+	DefaultFile = "ratelimit.db"
+
+	// sharedTimeout is how long to wait for another grammr process to release the database
+	// before falling back to a budget of this process only
+	sharedTimeout = time.Second
+	// sharedFilePerm keeps the database readable by the user only
+	sharedFilePerm os.FileMode = 0600
+)
+
+var budgetsBucket = []byte("budgets")
+
+// shared keeps the token bucket of a limiter in a bbolt database, so every grammr process using
+// the same database draws from one budget. The database is only opened for the duration of each
+// reservation, like the bolt cache backend.
+type shared struct {
+	path        string
+	key         string
+	perSecond   float64
+	burst       int
+	minInterval time.Duration
+}
+
+// sharedState is the token bucket of a budget as stored in the database
+type sharedState struct {
+	Tokens  float64   `json:"tokens"`  // Tokens left at Updated, negative when requests are waiting
+	Updated time.Time `json:"updated"` // When Tokens was worked out
+	Next    time.Time `json:"next"`    // When the last request reserved may be made
+}
+
+// update runs fn on the state of the budget in a read-write transaction
+func (s *shared) update(fn func(*sharedState)) error {
+	db, err := bolt.Open(s.path, sharedFilePerm, &bolt.Options{Timeout: sharedTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to open rate limit database: %w", err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(budgetsBucket)
+		if err != nil {
+			return err
+		}
+		state := sharedState{Tokens: float64(s.burst)}
+		if data := bucket.Get([]byte(s.key)); data != nil {
+			// A damaged state starts the budget over
+			_ = json.Unmarshal(data, &state)
+		}
+		fn(&state)
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(s.key), data)
+	})
+}
+
+// reserve takes a request at now, returning how long to wait before making it and a function
+// that gives it back
+func (s *shared) reserve(now time.Time) (time.Duration, func(), error) {
+	var delay time.Duration
+	var previous, at time.Time
+	err := s.update(func(state *sharedState) {
+		tokens := state.Tokens
+		if !state.Updated.IsZero() && now.After(state.Updated) {
+			tokens += now.Sub(state.Updated).Seconds() * s.perSecond
+		}
+		tokens = math.Min(tokens, float64(s.burst)) - 1
+
+		previous, at = state.Next, now
+		if tokens < 0 {
+			at = now.Add(time.Duration(-tokens / s.perSecond * float64(time.Second)))
+		}
+		if !state.Next.IsZero() && at.Before(state.Next.Add(s.minInterval)) {
+			at = state.Next.Add(s.minInterval)
+		}
+		*state = sharedState{Tokens: tokens, Updated: now, Next: at}
+		delay = at.Sub(now)
+	})
+	return delay, func() {
+		_ = s.update(func(state *sharedState) {
+			state.Tokens = math.Min(state.Tokens+1, float64(s.burst))
+			// Free the slot unless another process has reserved the one after it
+			if state.Next.Equal(at) {
+				state.Next = previous
+			}
+		})
+	}, err
+}
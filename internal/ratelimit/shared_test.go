@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSharedBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	budget := Budget{Requests: 2, Window: time.Minute}
+
+	// Two registries stand in for two grammr processes
+	first := NewRegistry(budget, nil, time.Millisecond)
+	first.Share(path)
+	second := NewRegistry(budget, nil, time.Millisecond)
+	second.Share(path)
+
+	if _, ok := first.Limiter("openai", OperationCorrection).TryWait(); !ok {
+		t.Fatal("first request was limited")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := second.Limiter("openai", OperationCorrection).TryWait(); !ok {
+		t.Fatal("second request was limited")
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	delay, ok := first.Limiter("openai", OperationCorrection).TryWait()
+	if ok || delay < 25*time.Second {
+		t.Fatalf("TryWait() = %v, %v after the shared budget was used up", delay, ok)
+	}
+	// A refused request doesn't push the next one back
+	if again, _ := second.Limiter("openai", OperationCorrection).TryWait(); again > delay {
+		t.Errorf("TryWait() = %v after a refused request, want at most %v", again, delay)
+	}
+
+	// Other providers have budgets of their own
+	if _, ok := second.Limiter("anthropic", OperationCorrection).TryWait(); !ok {
+		t.Error("another provider's request was limited")
+	}
+}
+
+func TestSharedBudgetFallback(t *testing.T) {
+	r := NewRegistry(Budget{Requests: 1, Window: time.Minute}, nil, time.Millisecond)
+	r.Share(filepath.Join(t.TempDir(), "missing", DefaultFile))
+
+	limiter := r.Limiter("openai", OperationCorrection)
+	if _, ok := limiter.TryWait(); !ok {
+		t.Fatal("first request was limited")
+	}
+	if _, ok := limiter.TryWait(); ok {
+		t.Error("the local budget wasn't kept without the database")
+	}
+}
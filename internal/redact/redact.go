@@ -0,0 +1,248 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kinds of personal data, used in the placeholders that stand for them
+const (
+	KindEmail = "EMAIL"
+	KindCard  = "CARD"
+	KindPhone = "PHONE"
+	KindName  = "NAME"
+)
+
+// Instruction tells the model to keep the placeholders of a redacted text
+const Instruction = `The text contains placeholders such as [NAME_1] or [EMAIL_1] that stand for personal data.
+Keep every placeholder exactly as it is, in the same place.`
+
+// detector finds one kind of personal data. accept, when set, rejects matches that only look
+// like it.
+type detector struct {
+	kind    string
+	pattern *regexp.Regexp
+	accept  func(match string) bool
+}
+
+// detectors run in order, each on the text the previous ones left, so an email address isn't
+// also taken for a name
+var detectors = []detector{
+	{
+		kind:    KindEmail,
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	{
+		kind:    KindCard,
+		pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		accept:  luhn,
+	},
+	{
+		kind:    KindPhone,
+		pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?)?\d{2,4}(?:[ .-]\d{2,4}){1,4}\b`),
+		accept:  phone,
+	},
+	{
+		kind:    KindName,
+		pattern: regexp.MustCompile(`\b(?:(?:Mr|Mrs|Ms|Dr|Prof)\.? )?\p{Lu}\p{Ll}+(?: \p{Lu}\p{Ll}+)+\b|\b(?:Mr|Mrs|Ms|Dr|Prof)\.? \p{Lu}\p{Ll}+\b`),
+	},
+}
+
+// datePattern matches dates, which look like phone numbers
+var datePattern = regexp.MustCompile(`^(?:\d{4}[-./]\d{1,2}[-./]\d{1,2}|\d{1,2}[-./]\d{1,2}[-./]\d{2,4})$`)
+
+// notNames are capitalized words that start sentences or name days and months rather than
+// people, so they are left out at either end of a name
+var notNames = map[string]bool{
+	"The": true, "A": true, "An": true, "This": true, "That": true, "These": true, "Those": true,
+	"It": true, "He": true, "She": true, "We": true, "They": true, "You": true, "My": true,
+	"Our": true, "Your": true, "His": true, "Her": true, "Their": true, "In": true, "On": true,
+	"At": true, "For": true, "From": true, "To": true, "With": true, "But": true, "And": true,
+	"Or": true, "So": true, "If": true, "When": true, "Then": true, "Dear": true, "Hi": true,
+	"Hello": true, "Thanks": true, "Please": true, "Yes": true, "No": true, "Ask": true,
+	"Call": true, "Tell": true, "Meet": true, "Send": true, "Email": true, "Let": true,
+	"Monday": true, "Tuesday": true, "Wednesday": true, "Thursday": true, "Friday": true,
+	"Saturday": true, "Sunday": true, "January": true, "February": true, "March": true,
+	"April": true, "May": true, "June": true, "July": true, "August": true, "September": true,
+	"October": true, "November": true, "December": true,
+}
+
+// placeholderPattern matches the placeholders of Redact
+var placeholderPattern = regexp.MustCompile(`\[(?:EMAIL|CARD|PHONE|NAME)_\d+\]`)
+
+// Redactor replaces personal data in texts with placeholders and puts it back in the responses.
+// A value gets the same placeholder every time, in every text of the redactor, so the messages
+// of one request agree.
+type Redactor struct {
+	placeholders map[string]string // Value -> placeholder
+	values       map[string]string // Placeholder -> value
+	counts       map[string]int    // Placeholders of each kind
+}
+
+// New creates a redactor with no placeholders yet
+func New() *Redactor {
+	return &Redactor{
+		placeholders: make(map[string]string),
+		values:       make(map[string]string),
+		counts:       make(map[string]int),
+	}
+}
+
+// Redact replaces the email addresses, card numbers, phone numbers and names in text with
+// placeholders such as [EMAIL_1]. Names are found by their capitals: two or more capitalized
+// words in a row, or one after a title such as Dr.
+func (r *Redactor) Redact(text string) string {
+	for _, d := range detectors {
+		text = d.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			prefix, value, suffix := match, match, ""
+			if d.kind == KindName {
+				prefix, value, suffix = trimName(match)
+				if value == "" {
+					return match
+				}
+			} else {
+				prefix = ""
+			}
+			if d.accept != nil && !d.accept(value) {
+				return match
+			}
+			return prefix + r.placeholder(d.kind, value) + suffix
+		})
+	}
+	return text
+}
+
+// placeholder returns the placeholder of value, making one up the first time
+func (r *Redactor) placeholder(kind, value string) string {
+	if p, ok := r.placeholders[value]; ok {
+		return p
+	}
+	r.counts[kind]++
+	p := fmt.Sprintf("[%s_%d]", kind, r.counts[kind])
+	r.placeholders[value] = p
+	r.values[p] = value
+	return p
+}
+
+// Len returns how many different values were redacted
+func (r *Redactor) Len() int {
+	return len(r.values)
+}
+
+// Restore puts the redacted values back in place of their placeholders
+func (r *Redactor) Restore(text string) string {
+	if len(r.values) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(p string) string {
+		if value, ok := r.values[p]; ok {
+			return value
+		}
+		return p
+	})
+}
+
+// Count returns how many different values Redact would replace in text
+func Count(text string) int {
+	r := New()
+	r.Redact(text)
+	return r.Len()
+}
+
+// trimName drops the words at either end of a run of capitalized words that aren't part of a
+// name, returning what was dropped before and after it. value is empty when no name is left.
+func trimName(match string) (prefix, value, suffix string) {
+	words := strings.Split(match, " ")
+	start, end := 0, len(words)
+	for start < end && notNames[words[start]] {
+		start++
+	}
+	for end > start && notNames[words[end-1]] {
+		end--
+	}
+	// A single word is too likely to be something else, unless it follows a title
+	if end-start < 2 {
+		return "", "", ""
+	}
+	prefix = strings.Join(words[:start], " ")
+	if prefix != "" {
+		prefix += " "
+	}
+	suffix = strings.Join(words[end:], " ")
+	if suffix != "" {
+		suffix = " " + suffix
+	}
+	return prefix, strings.Join(words[start:end], " "), suffix
+}
+
+// luhn reports whether the digits of number pass the Luhn check of card numbers
+func luhn(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// phone reports whether number has as many digits as a phone number and isn't a date
+func phone(number string) bool {
+	digits := 0
+	for _, r := range number {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15 && !datePattern.MatchString(number)
+}
+
+// maxPlaceholderLength is the longest a placeholder gets, with room for large numbers
+const maxPlaceholderLength = len("[PHONE_]") + 6
+
+// Stream restores the placeholders of a streamed response. The end of a chunk that may be the
+// start of a placeholder is held back until the next chunk completes it.
+type Stream struct {
+	redactor *Redactor
+	onChunk  func(string)
+	pending  string
+}
+
+// NewStream returns a stream passing the chunks written to it on to onChunk, restored
+func (r *Redactor) NewStream(onChunk func(string)) *Stream {
+	return &Stream{redactor: r, onChunk: onChunk}
+}
+
+// Write restores a chunk of the response
+func (s *Stream) Write(chunk string) {
+	s.pending += chunk
+	cut := len(s.pending)
+	if i := strings.LastIndexByte(s.pending, '['); i >= 0 && !strings.Contains(s.pending[i:], "]") && len(s.pending)-i < maxPlaceholderLength {
+		cut = i
+	}
+	s.emit(cut)
+}
+
+// Close passes on what was held back
+func (s *Stream) Close() {
+	s.emit(len(s.pending))
+}
+
+func (s *Stream) emit(n int) {
+	if n == 0 {
+		return
+	}
+	s.onChunk(s.redactor.Restore(s.pending[:n]))
+	s.pending = s.pending[n:]
+}
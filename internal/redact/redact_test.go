@@ -0,0 +1,108 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "email",
+			text: "Write to jane.doe@example.com today.",
+			want: "Write to [EMAIL_1] today.",
+		},
+		{
+			name: "card number",
+			text: "My card is 4111 1111 1111 1111, thanks.",
+			want: "My card is [CARD_1], thanks.",
+		},
+		{
+			name: "number that fails the card check",
+			text: "Order 1234 5678 9012 3456 shipped.",
+			want: "Order 1234 5678 9012 3456 shipped.",
+		},
+		{
+			name: "phone numbers",
+			text: "Call +1 415-555-0132 or (020) 7946 0958.",
+			want: "Call [PHONE_1] or [PHONE_2].",
+		},
+		{
+			name: "dates and short numbers are kept",
+			text: "On 2024-01-15 we sold 3-4 of them.",
+			want: "On 2024-01-15 we sold 3-4 of them.",
+		},
+		{
+			name: "names",
+			text: "Dear John Smith, Dr. Watson says The Beatles met Anna Lee on Monday.",
+			want: "Dear [NAME_1], [NAME_2] says The Beatles met [NAME_3] on Monday.",
+		},
+		{
+			name: "same value same placeholder",
+			text: "Ask Anna Lee. Anna Lee knows anna@example.com and anna@example.com knows her.",
+			want: "Ask [NAME_1]. [NAME_1] knows [EMAIL_1] and [EMAIL_1] knows her.",
+		},
+		{
+			name: "nothing personal",
+			text: "The quick brown fox jumps over the lazy dog.",
+			want: "The quick brown fox jumps over the lazy dog.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			got := r.Redact(tt.text)
+			if got != tt.want {
+				t.Fatalf("Redact() = %q, want %q", got, tt.want)
+			}
+			if restored := r.Restore(got); restored != tt.text {
+				t.Errorf("Restore() = %q, want %q", restored, tt.text)
+			}
+			placeholders := make(map[string]bool)
+			for _, p := range placeholderPattern.FindAllString(tt.want, -1) {
+				placeholders[p] = true
+			}
+			if n := Count(tt.text); n != r.Len() || n != len(placeholders) {
+				t.Errorf("Count() = %d, Len() = %d, want %d", n, r.Len(), len(placeholders))
+			}
+		})
+	}
+}
+
+func TestRedactorSharesPlaceholders(t *testing.T) {
+	r := New()
+	first := r.Redact("Ask Anna Lee.")
+	second := r.Redact("Anna Lee and Tom Hardy.")
+	if first != "Ask [NAME_1]." || second != "[NAME_1] and [NAME_2]." {
+		t.Errorf("Redact() = %q, %q, want the same placeholder for the same name", first, second)
+	}
+	// Placeholders the redactor didn't make are left alone
+	if got := r.Restore("[NAME_1] met [NAME_9]."); got != "Anna Lee met [NAME_9]." {
+		t.Errorf("Restore() = %q", got)
+	}
+}
+
+func TestStream(t *testing.T) {
+	r := New()
+	r.Redact("Ask Anna Lee at anna@example.com.")
+
+	var got []string
+	s := r.NewStream(func(chunk string) { got = append(got, chunk) })
+	for _, chunk := range []string{"Ask [NA", "ME_1] at [", "EMAIL_1]", ". [Note"} {
+		s.Write(chunk)
+	}
+	s.Close()
+
+	if joined := strings.Join(got, ""); joined != "Ask Anna Lee at anna@example.com. [Note" {
+		t.Errorf("stream = %q", joined)
+	}
+	for _, chunk := range got {
+		if strings.Contains(chunk, "[NA") || strings.Contains(chunk, "EMAIL") {
+			t.Errorf("chunk %q has a placeholder split across chunks", chunk)
+		}
+	}
+}
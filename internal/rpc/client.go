@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Client calls the methods of a server over a connection, one call at a time
+type Client struct {
+	conn  io.ReadWriteCloser
+	lines *bufio.Scanner
+
+	mu     sync.Mutex // Held during a call
+	nextID int
+
+	writeMu sync.Mutex // Keeps a cancellation from interleaving with a request
+}
+
+// NewClient returns a client calling the server at the other end of conn
+func NewClient(conn io.ReadWriteCloser) *Client {
+	lines := bufio.NewScanner(conn)
+	lines.Buffer(nil, maxLineSize)
+	return &Client{conn: conn, lines: lines}
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Call calls method with params and decodes its result into result. With onChunk, the chunks of
+// the result are passed to it as they arrive. When ctx is done, the server is asked to stop.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}, onChunk func(string)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	if err := c.write(Message{JSONRPC: "2.0", ID: id, Method: method, Params: encoded}); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		cancel, _ := json.Marshal(CancelParams{ID: id})
+		_ = c.write(Message{JSONRPC: "2.0", Method: "$/cancelRequest", Params: cancel})
+	})
+	defer stop()
+
+	for c.lines.Scan() {
+		var msg struct {
+			Message
+			Result json.RawMessage `json:"result,omitempty"`
+		}
+		if err := json.Unmarshal(c.lines.Bytes(), &msg); err != nil {
+			return fmt.Errorf("invalid message from grammr: %w", err)
+		}
+		if msg.Method == "chunk" {
+			var chunk ChunkParams
+			if err := json.Unmarshal(msg.Params, &chunk); err == nil && string(chunk.ID) == string(id) && onChunk != nil {
+				onChunk(chunk.Text)
+			}
+			continue
+		}
+		if string(msg.ID) != string(id) {
+			continue
+		}
+		if msg.Error != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return msg.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	}
+	if err := c.lines.Err(); err != nil {
+		return err
+	}
+	return errors.New("grammr closed the connection")
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) write(msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.conn.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,457 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/cache"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/estimate"
+	"github.com/maximbilan/grammr/internal/langdetect"
+	"github.com/maximbilan/grammr/internal/tracing"
+	"github.com/maximbilan/grammr/internal/translator"
+	"github.com/maximbilan/grammr/internal/validation"
+	"github.com/maximbilan/grammr/pkg/grammr"
+)
+
+// Error codes of JSON-RPC 2.0, and codeFailed for corrections and translations that failed
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeFailed         = -32000
+)
+
+// maxLineSize limits a message to what a text of validation.MaxDocumentLength characters could
+// take in JSON, at up to 4 bytes a character
+const maxLineSize = 4*validation.MaxDocumentLength + 1024
+
+// Options configures a server
+type Options struct {
+	// Model is the model of the corrector, which cached corrections are kept by
+	Model string
+	// Translator creates a translator to a language; translate fails when it is nil
+	Translator func(language string) (*translator.Translator, error)
+	// TranslationLanguage is used when a translate request names no language
+	TranslationLanguage string
+	// Timeout is the time allowed for each correction
+	Timeout time.Duration
+	// TranslationTimeout is the time allowed for each translation, Timeout when zero
+	TranslationTimeout time.Duration
+	// ScaleTimeouts lengthens the timeouts of long texts by the time their response may take
+	ScaleTimeouts bool
+	// DetectLanguage corrects texts in the language they are written in, like detect_language
+	DetectLanguage bool
+	// OnError is called with the errors of corrections and translations, except for cancelled
+	// ones, for grammrd to report them where they would otherwise go unseen
+	OnError func(method string, err error)
+}
+
+// Server answers newline-delimited JSON-RPC 2.0 requests, for editor plugins that run grammr as
+// a child process and talk to it over stdio
+type Server struct {
+	corrector *corrector.Corrector
+	cache     *cache.Cache // Nil when the cache is turned off
+	opts      Options
+}
+
+// conn is the state of one client of a server
+type conn struct {
+	mu  sync.Mutex // Keeps messages written at the same time from interleaving
+	out io.Writer
+
+	requestsMu sync.Mutex
+	requests   map[string]context.CancelFunc // Requests in progress by ID, to cancel them
+}
+
+// Message is a request, response or notification
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error reports a request that failed
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// CorrectParams are the params of correct. With Stream, the correction is also sent in chunk
+// notifications as it arrives.
+type CorrectParams struct {
+	Text   string `json:"text"`
+	Stream bool   `json:"stream,omitempty"`
+}
+
+// CorrectResult answers correct
+type CorrectResult struct {
+	Corrected string `json:"corrected"`
+	Cached    bool   `json:"cached"`
+}
+
+// TranslateParams are the params of translate. Language defaults to translation_language.
+type TranslateParams struct {
+	Text     string `json:"text"`
+	Language string `json:"language,omitempty"`
+	Stream   bool   `json:"stream,omitempty"`
+}
+
+// TranslateResult answers translate
+type TranslateResult struct {
+	Translated string `json:"translated"`
+	Language   string `json:"language"`
+}
+
+// DiffParams are the params of diff
+type DiffParams struct {
+	Original  string `json:"original"`
+	Corrected string `json:"corrected"`
+}
+
+// DiffResult answers diff with the changes in the order they appear
+type DiffResult struct {
+	Changes []Change `json:"changes"`
+}
+
+// Change is one change between two texts, compared a word at a time
+type Change struct {
+	Original  string `json:"original"`
+	Corrected string `json:"corrected"`
+	Category  string `json:"category"`
+}
+
+// ChunkParams are the params of a chunk notification: part of the result of the request with ID
+type ChunkParams struct {
+	ID   json.RawMessage `json:"id"`
+	Text string          `json:"text"`
+}
+
+// CancelParams are the params of $/cancelRequest
+type CancelParams struct {
+	ID json.RawMessage `json:"id"`
+}
+
+// New creates a server that corrects with cor, looking corrections up in c first unless it is nil
+func New(cor *corrector.Corrector, c *cache.Cache, opts Options) *Server {
+	return &Server{
+		corrector: cor,
+		cache:     c,
+		opts:      opts,
+	}
+}
+
+// Serve answers the requests read from in on out until in ends or ctx is done, then waits for
+// the requests in progress. Requests are handled concurrently, so answers may come out of order.
+// A server can serve several clients at once.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	c := &conn{out: out, requests: make(map[string]context.CancelFunc)}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	errs := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(nil, maxLineSize)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		errs <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			if err != nil {
+				return fmt.Errorf("failed to read request: %w", err)
+			}
+			return nil
+		case line := <-lines:
+			if len(strings.TrimSpace(string(line))) == 0 {
+				continue
+			}
+			var req Message
+			if err := json.Unmarshal(line, &req); err != nil {
+				c.reply(nil, nil, &Error{Code: codeParseError, Message: "invalid JSON: " + err.Error()})
+				continue
+			}
+			if req.JSONRPC != "2.0" || req.Method == "" {
+				c.reply(req.ID, nil, &Error{Code: codeInvalidRequest, Message: "not a JSON-RPC 2.0 request"})
+				continue
+			}
+			if req.Method == "$/cancelRequest" {
+				c.cancel(req.Params)
+				continue
+			}
+
+			reqCtx, cancelReq := context.WithCancel(ctx)
+			c.track(req.ID, cancelReq)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer c.untrack(req.ID, cancelReq)
+				result, rpcErr := s.handle(reqCtx, c, req)
+				// Notifications, requests without an ID, get no answer
+				if req.ID != nil {
+					c.reply(req.ID, result, rpcErr)
+				}
+			}()
+		}
+	}
+}
+
+// handle runs the method of req
+func (s *Server) handle(ctx context.Context, c *conn, req Message) (interface{}, *Error) {
+	ctx, span := tracing.Start(ctx, "rpc."+req.Method)
+	var err error
+	defer func() {
+		tracing.End(span, err)
+		if err != nil && s.opts.OnError != nil && !errors.Is(err, context.Canceled) {
+			s.opts.OnError(req.Method, err)
+		}
+	}()
+
+	switch req.Method {
+	case "correct":
+		var params CorrectParams
+		if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+			return nil, rpcErr
+		}
+		var result CorrectResult
+		result, err = s.correct(ctx, params, c.chunks(req.ID))
+		return result, failed(err)
+	case "translate":
+		var params TranslateParams
+		if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+			return nil, rpcErr
+		}
+		var result TranslateResult
+		result, err = s.translate(ctx, params, c.chunks(req.ID))
+		return result, failed(err)
+	case "diff":
+		var params DiffParams
+		if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+			return nil, rpcErr
+		}
+		changes := []Change{}
+		for _, change := range grammr.Changes(params.Original, params.Corrected) {
+			changes = append(changes, Change(change))
+		}
+		return DiffResult{Changes: changes}, nil
+	}
+	return nil, &Error{Code: codeMethodNotFound, Message: "unknown method: " + req.Method}
+}
+
+// correct corrects the text of params, from the cache when it can, passing the correction to
+// onChunk as it arrives when params ask for a stream
+func (s *Server) correct(ctx context.Context, params CorrectParams, onChunk func(string)) (CorrectResult, error) {
+	// A text too long for one request is corrected in chunks
+	if err := validation.ValidateNotEmpty(params.Text); err != nil {
+		return CorrectResult{}, err
+	}
+	cor := s.corrector
+	if s.opts.DetectLanguage {
+		if language, ok := langdetect.Detect(params.Text); ok {
+			cor = cor.WithLanguage(language)
+		}
+	}
+	hash := ""
+	if s.cache != nil {
+		hash = s.cache.CorrectionHash(params.Text, cor.Style(), s.opts.Model, cor.Language(), cor.PromptVersion())
+		if cached := s.cache.Get(hash); cached != "" {
+			if params.Stream {
+				onChunk(cached)
+			}
+			return CorrectResult{Corrected: cached, Cached: true}, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout(s.opts.Timeout, params.Text))
+	defer cancel()
+	var corrected string
+	if params.Stream {
+		var b strings.Builder
+		err := cor.StreamCorrect(ctx, params.Text, func(chunk string) {
+			b.WriteString(chunk)
+			onChunk(chunk)
+		})
+		if err != nil {
+			return CorrectResult{}, err
+		}
+		corrected = b.String()
+	} else {
+		var err error
+		if corrected, err = cor.Correct(ctx, params.Text); err != nil {
+			return CorrectResult{}, err
+		}
+	}
+	corrected = strings.TrimRight(corrected, " \t\r\n")
+
+	if s.cache != nil {
+		// A correction that can't be cached is still a correction
+		_ = s.cache.Set(hash, params.Text, corrected)
+	}
+	return CorrectResult{Corrected: corrected}, nil
+}
+
+// translate translates the text of params, passing the translation to onChunk as it arrives
+// when params ask for a stream
+func (s *Server) translate(ctx context.Context, params TranslateParams, onChunk func(string)) (TranslateResult, error) {
+	language := strings.TrimSpace(params.Language)
+	if language == "" {
+		language = s.opts.TranslationLanguage
+	}
+	if language == "" {
+		return TranslateResult{}, errors.New("no language to translate to, pass one or set translation_language")
+	}
+	if s.opts.Translator == nil {
+		return TranslateResult{}, errors.New("translation isn't available")
+	}
+	trans, err := s.opts.Translator(language)
+	if err != nil {
+		return TranslateResult{}, err
+	}
+
+	timeout := s.opts.TranslationTimeout
+	if timeout == 0 {
+		timeout = s.opts.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout(timeout, params.Text))
+	defer cancel()
+	var translated string
+	if params.Stream {
+		var b strings.Builder
+		err = trans.StreamTranslate(ctx, params.Text, func(chunk string) {
+			b.WriteString(chunk)
+			onChunk(chunk)
+		})
+		translated = b.String()
+	} else {
+		translated, err = trans.Translate(ctx, params.Text)
+	}
+	if err != nil {
+		return TranslateResult{}, err
+	}
+	return TranslateResult{Translated: strings.TrimRight(translated, " \t\r\n"), Language: language}, nil
+}
+
+// timeout returns the time allowed for a request on text, lengthened for a long text when the
+// timeouts scale
+func (s *Server) timeout(timeout time.Duration, text string) time.Duration {
+	if !s.opts.ScaleTimeouts {
+		return timeout
+	}
+	return estimate.Timeout(timeout, text)
+}
+
+// cancel stops the request named by params, which then fails
+func (c *conn) cancel(params json.RawMessage) {
+	var p CancelParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return
+	}
+	c.requestsMu.Lock()
+	cancel := c.requests[string(p.ID)]
+	c.requestsMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (c *conn) track(id json.RawMessage, cancel context.CancelFunc) {
+	if id == nil {
+		return
+	}
+	c.requestsMu.Lock()
+	c.requests[string(id)] = cancel
+	c.requestsMu.Unlock()
+}
+
+func (c *conn) untrack(id json.RawMessage, cancel context.CancelFunc) {
+	cancel()
+	if id == nil {
+		return
+	}
+	c.requestsMu.Lock()
+	delete(c.requests, string(id))
+	c.requestsMu.Unlock()
+}
+
+// chunks returns a function sending parts of the result of the request with id in chunk
+// notifications
+func (c *conn) chunks(id json.RawMessage) func(string) {
+	return func(text string) {
+		if id == nil {
+			return
+		}
+		params, _ := json.Marshal(ChunkParams{ID: id, Text: text})
+		c.write(Message{JSONRPC: "2.0", Method: "chunk", Params: params})
+	}
+}
+
+// reply answers the request with id with result, or with rpcErr when it isn't nil
+func (c *conn) reply(id json.RawMessage, result interface{}, rpcErr *Error) {
+	if id == nil {
+		// Errors about requests whose ID couldn't be read have a null ID
+		id = json.RawMessage("null")
+	}
+	if rpcErr != nil {
+		c.write(Message{JSONRPC: "2.0", ID: id, Error: rpcErr})
+		return
+	}
+	c.write(Message{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (c *conn) write(msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// A client that went away can't be told anything
+	_, _ = c.out.Write(append(data, '\n'))
+}
+
+// decodeParams reads params into v
+func decodeParams(params json.RawMessage, v interface{}) *Error {
+	if len(params) == 0 {
+		return &Error{Code: codeInvalidParams, Message: "missing params"}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+// failed turns the error of a correction or translation into an answer, explaining provider
+// errors the way the TUI does
+func failed(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: codeFailed, Message: apierror.Message(err)}
+}
@@ -0,0 +1,207 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/translator"
+)
+
+// fakeProvider answers every request with the same response, a word at a time when streaming.
+// With block, it waits for the request to be cancelled instead.
+type fakeProvider struct {
+	response string
+	block    bool
+}
+
+func (p *fakeProvider) StreamChat(ctx context.Context, model string, messages []provider.Message, onChunk func(string)) error {
+	response, err := p.Chat(ctx, model, messages)
+	if err != nil {
+		return err
+	}
+	for _, word := range strings.SplitAfter(response, " ") {
+		onChunk(word)
+	}
+	return nil
+}
+
+func (p *fakeProvider) Chat(ctx context.Context, model string, messages []provider.Message) (string, error) {
+	if p.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return p.response, nil
+}
+
+// serve runs a server over pipes, returning a function that sends a line and one that reads a
+// message. onError may be nil.
+func serve(t *testing.T, prov provider.Provider, onError func(string, error)) (send func(string), receive func() Message) {
+	t.Helper()
+	cor, err := corrector.New(prov, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(cor, nil, Options{
+		Model: "gpt-4o",
+		Translator: func(language string) (*translator.Translator, error) {
+			return translator.NewWithRateLimit(prov, "gpt-4o", language, nil)
+		},
+		Timeout: 5 * time.Second,
+		OnError: onError,
+	})
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(context.Background(), inReader, outWriter)
+		outWriter.Close()
+	}()
+	t.Cleanup(func() {
+		inWriter.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+
+	lines := bufio.NewScanner(outReader)
+	send = func(line string) {
+		if _, err := io.WriteString(inWriter, line+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receive = func() Message {
+		t.Helper()
+		if !lines.Scan() {
+			t.Fatal("no message from the server")
+		}
+		var msg Message
+		if err := json.Unmarshal(lines.Bytes(), &msg); err != nil {
+			t.Fatalf("invalid message %q: %v", lines.Text(), err)
+		}
+		return msg
+	}
+	return send, receive
+}
+
+// result decodes the result of msg into v
+func result(t *testing.T, msg Message, v interface{}) {
+	t.Helper()
+	if msg.Error != nil {
+		t.Fatalf("request failed: %+v", msg.Error)
+	}
+	data, _ := json.Marshal(msg.Result)
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServeMethods(t *testing.T) {
+	send, receive := serve(t, &fakeProvider{response: "I have an apple.\n"}, nil)
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"correct","params":{"text":"I has a apple."}}`)
+	msg := receive()
+	var corrected CorrectResult
+	result(t, msg, &corrected)
+	if string(msg.ID) != "1" || corrected.Corrected != "I have an apple." {
+		t.Errorf("correct answered %s: %+v", msg.ID, corrected)
+	}
+
+	send(`{"jsonrpc":"2.0","id":"t","method":"translate","params":{"text":"I have an apple.","language":"spanish"}}`)
+	var translated TranslateResult
+	result(t, receive(), &translated)
+	if translated.Language != "spanish" || translated.Translated != "I have an apple." {
+		t.Errorf("translate answered %+v", translated)
+	}
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"diff","params":{"original":"I has a apple.","corrected":"I have an apple."}}`)
+	var diff DiffResult
+	result(t, receive(), &diff)
+	want := []Change{{Original: "has", Corrected: "have", Category: "grammar"}, {Original: "a", Corrected: "an", Category: "grammar"}}
+	if len(diff.Changes) != len(want) || diff.Changes[0] != want[0] || diff.Changes[1] != want[1] {
+		t.Errorf("diff answered %+v, want %+v", diff.Changes, want)
+	}
+}
+
+func TestServeStreaming(t *testing.T) {
+	send, receive := serve(t, &fakeProvider{response: "I have an apple."}, nil)
+
+	send(`{"jsonrpc":"2.0","id":7,"method":"correct","params":{"text":"I has a apple.","stream":true}}`)
+	var streamed strings.Builder
+	for {
+		msg := receive()
+		if msg.Method != "chunk" {
+			var corrected CorrectResult
+			result(t, msg, &corrected)
+			if corrected.Corrected != "I have an apple." {
+				t.Errorf("correct answered %+v", corrected)
+			}
+			break
+		}
+		var chunk ChunkParams
+		if err := json.Unmarshal(msg.Params, &chunk); err != nil {
+			t.Fatal(err)
+		}
+		if string(chunk.ID) != "7" {
+			t.Errorf("chunk for request %s, want 7", chunk.ID)
+		}
+		streamed.WriteString(chunk.Text)
+	}
+	if streamed.String() != "I have an apple." {
+		t.Errorf("streamed %q, want the whole correction", streamed.String())
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		code    int
+	}{
+		{name: "invalid JSON", request: `{"jsonrpc"`, code: codeParseError},
+		{name: "not JSON-RPC", request: `{"id":1,"method":"correct"}`, code: codeInvalidRequest},
+		{name: "unknown method", request: `{"jsonrpc":"2.0","id":1,"method":"summarize","params":{}}`, code: codeMethodNotFound},
+		{name: "missing params", request: `{"jsonrpc":"2.0","id":1,"method":"correct"}`, code: codeInvalidParams},
+		{name: "empty text", request: `{"jsonrpc":"2.0","id":1,"method":"correct","params":{"text":""}}`, code: codeFailed},
+		{name: "no language", request: `{"jsonrpc":"2.0","id":1,"method":"translate","params":{"text":"Hello"}}`, code: codeFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported []string
+			send, receive := serve(t, &fakeProvider{response: "Hello"}, func(method string, err error) {
+				reported = append(reported, method)
+			})
+			send(tt.request)
+			msg := receive()
+			if msg.Error == nil || msg.Error.Code != tt.code {
+				t.Errorf("answer = %+v, want error code %d", msg, tt.code)
+			}
+			// Only failed corrections and translations are reported
+			if wantReported := tt.code == codeFailed; (len(reported) > 0) != wantReported {
+				t.Errorf("reported errors of %q, want reported = %v", reported, wantReported)
+			}
+		})
+	}
+}
+
+func TestServeCancel(t *testing.T) {
+	send, receive := serve(t, &fakeProvider{block: true}, func(method string, err error) {
+		t.Errorf("cancelled request reported as %v", err)
+	})
+
+	send(`{"jsonrpc":"2.0","id":3,"method":"correct","params":{"text":"I has a apple."}}`)
+	// Lines are read in order, so the request is known by the time it is cancelled
+	send(`{"jsonrpc":"2.0","method":"$/cancelRequest","params":{"id":3}}`)
+	msg := receive()
+	if string(msg.ID) != "3" || msg.Error == nil || msg.Error.Code != codeFailed {
+		t.Errorf("answer = %+v, want request 3 to fail", msg)
+	}
+}
@@ -0,0 +1,135 @@
+package sanitize
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// escapePattern matches terminal escape sequences: CSI sequences such as colors, OSC sequences
+// such as titles and hyperlinks, and the two-character ones
+var escapePattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// injectionPatterns match phrases that address the model rather than belong to a text to correct
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\s+(?:all\s+|any\s+)?(?:of\s+)?(?:the\s+|your\s+)?(?:previous|prior|above|earlier|preceding|system)\s+(?:instructions?|prompts?|rules|messages?|directions)`),
+	regexp.MustCompile(`(?i)\b(?:reveal|print|show|repeat|output)\s+(?:me\s+)?(?:your|the)\s+(?:system\s+)?(?:prompt|instructions)`),
+	regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(?:a|an|in)\b[^.\n]*`),
+	regexp.MustCompile(`(?i)\bnew\s+instructions\s*:`),
+	regexp.MustCompile(`(?im)^\s*(?:system|assistant)\s*:`),
+	regexp.MustCompile(`<\|[a-z_]+\|>|\[/?INST\]`),
+}
+
+// Report says what Clean found in a text
+type Report struct {
+	Escapes    int      // Terminal escape sequences removed
+	Controls   int      // Control characters removed
+	Invisible  int      // Zero-width, direction and tag characters removed
+	Injections []string // Phrases that may try to instruct the model, kept in the text
+}
+
+// Removed returns how many escape sequences and characters were removed
+func (r Report) Removed() int {
+	return r.Escapes + r.Controls + r.Invisible
+}
+
+// String sums up the report, such as "removed 2 escape sequences and 1 invisible character",
+// or returns an empty string when nothing was found
+func (r Report) String() string {
+	var removed []string
+	for _, count := range []struct {
+		n    int
+		name string
+	}{
+		{r.Escapes, "escape sequence"},
+		{r.Controls, "control character"},
+		{r.Invisible, "invisible character"},
+	} {
+		if count.n == 1 {
+			removed = append(removed, "1 "+count.name)
+		} else if count.n > 1 {
+			removed = append(removed, fmt.Sprintf("%d %ss", count.n, count.name))
+		}
+	}
+	var parts []string
+	if len(removed) > 0 {
+		parts = append(parts, "removed "+joinList(removed))
+	}
+	if len(r.Injections) > 0 {
+		parts = append(parts, fmt.Sprintf("may instruct the model: %q", r.Injections[0]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Clean removes what a text copied from a terminal or a web page may hide: escape sequences,
+// control characters other than tabs and line breaks, and characters that are invisible or
+// reorder text. Phrases that may try to instruct the model are only reported, since they may
+// as well belong to the text.
+func Clean(text string) (string, Report) {
+	var report Report
+	// Line breaks are normalized, not reported
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = escapePattern.ReplaceAllStringFunc(text, func(string) string {
+		report.Escapes++
+		return ""
+	})
+
+	var b strings.Builder
+	b.Grow(len(text))
+	inFlag := false // Tag characters spell out the region of a flag emoji
+	for _, r := range text {
+		switch {
+		case r == '\t' || r == '\n':
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			report.Controls++
+			continue
+		case r >= 0xe0000 && r <= 0xe007f:
+			if inFlag {
+				inFlag = r != 0xe007f
+				break
+			}
+			report.Invisible++
+			continue
+		case invisible(r):
+			report.Invisible++
+			continue
+		}
+		if r == 0x1f3f4 {
+			inFlag = true
+		} else if r < 0xe0000 || r > 0xe007f {
+			inFlag = false
+		}
+		b.WriteRune(r)
+	}
+	text = b.String()
+
+	for _, pattern := range injectionPatterns {
+		for _, match := range pattern.FindAllString(text, -1) {
+			report.Injections = append(report.Injections, strings.TrimSpace(match))
+		}
+	}
+	return text, report
+}
+
+// invisible reports whether r takes no space or reorders the text around it. The zero-width
+// joiner and non-joiner and the direction marks are kept, as emoji and several scripts need them.
+func invisible(r rune) bool {
+	switch {
+	case r == 0x200b, r == 0x2060, r == 0xfeff, r == 0x180e, r == 0x034f:
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+		return true
+	case r >= 0x2061 && r <= 0x2064:
+		return true
+	}
+	return false
+}
+
+// joinList joins items as "a, b and c"
+func joinList(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// eventStream sends server-sent events, flushing each one so the client sees it at once
+type eventStream struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+// newEventStream starts an event stream answering with w
+func newEventStream(w http.ResponseWriter) *eventStream {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keep proxies such as nginx from holding events back
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	e := &eventStream{w: w, controller: http.NewResponseController(w)}
+	e.flush()
+	return e
+}
+
+// send sends an event named name with data encoded as JSON
+func (e *eventStream) send(name string, data interface{}) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	// A client that went away stops reading, which the request context reports
+	fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", name, encoded)
+	e.flush()
+}
+
+func (e *eventStream) flush() {
+	_ = e.controller.Flush()
+}
+
+// acceptsEventStream reports whether the client asked for server-sent events
+func acceptsEventStream(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && mediaType == "text/event-stream" {
+			return true
+		}
+	}
+	return false
+}
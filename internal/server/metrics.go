@@ -0,0 +1,61 @@
+package server
+
+import (
+	"github.com/maximbilan/grammr/internal/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// metrics are the Prometheus metrics served on /metrics
+type metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec   // By endpoint and status code
+	duration *prometheus.HistogramVec // By endpoint
+	tokens   *prometheus.CounterVec   // By direction, input or output
+}
+
+func newMetrics(c *cache.Cache) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grammr_requests_total",
+			Help: "Requests answered, by endpoint and status code.",
+		}, []string{"endpoint", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "grammr_request_duration_seconds",
+			Help: "Time taken to answer requests, by endpoint.",
+			// Corrections take from a few milliseconds from the cache to a minute for long texts
+			Buckets: []float64{0.01, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+		}, []string{"endpoint"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grammr_tokens_total",
+			Help: "Estimated tokens sent to and received from the provider, by direction.",
+		}, []string{"direction"}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.tokens,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	if c != nil {
+		// The cache counts its own lookups, so these read its statistics rather than count again
+		m.registry.MustRegister(
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name: "grammr_cache_hits_total",
+				Help: "Corrections served from the cache.",
+			}, func() float64 { return float64(c.Session().Hits) }),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name: "grammr_cache_misses_total",
+				Help: "Corrections not found in the cache.",
+			}, func() float64 { return float64(c.Session().Misses) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "grammr_cache_hit_ratio",
+				Help: "Share of cache lookups that were hits, from 0 to 1.",
+			}, func() float64 { return c.Session().HitRate() }),
+		)
+	}
+	return m
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/cache"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/estimate"
+	"github.com/maximbilan/grammr/internal/langdetect"
+	"github.com/maximbilan/grammr/internal/tracing"
+	"github.com/maximbilan/grammr/internal/validation"
+	"github.com/maximbilan/grammr/pkg/grammr"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxBodySize limits request bodies to what a text of validation.MaxDocumentLength characters
+// could take in JSON, at up to 4 bytes a character
+const maxBodySize = 4*validation.MaxDocumentLength + 1024
+
+// Options configures a server
+type Options struct {
+	Provider string
+	Model    string
+	Timeout  time.Duration // Time allowed for each correction
+	// ScaleTimeouts lengthens the timeout of a long text by the time its correction may take
+	ScaleTimeouts bool
+	// DetectLanguage corrects texts in the language they are written in, like detect_language
+	DetectLanguage bool
+}
+
+// Server corrects texts over HTTP for tools that embed grammr, and reports its health and
+// metrics for monitoring
+type Server struct {
+	corrector *corrector.Corrector
+	cache     *cache.Cache // Nil when the cache is turned off
+	opts      Options
+	metrics   *metrics
+}
+
+// CorrectRequest is the body of POST /v1/correct
+type CorrectRequest struct {
+	Text string `json:"text"`
+}
+
+// CorrectResponse answers POST /v1/correct, and is the final event of a stream
+type CorrectResponse struct {
+	Original  string `json:"original"`
+	Corrected string `json:"corrected"`
+	Cached    bool   `json:"cached"`
+	// Changes lists the changes of the correction, in the final event of a stream only
+	Changes []grammr.Change `json:"changes,omitempty"`
+}
+
+// ChunkEvent is the data of a chunk event: the next part of a streamed correction
+type ChunkEvent struct {
+	Text string `json:"text"`
+}
+
+// ErrorResponse answers a request that failed
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// New creates a server that corrects with cor, looking corrections up in c first unless it is nil
+func New(cor *corrector.Corrector, c *cache.Cache, opts Options) *Server {
+	return &Server{
+		corrector: cor,
+		cache:     c,
+		opts:      opts,
+		metrics:   newMetrics(c),
+	}
+}
+
+// Handler returns the routes of the server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST /v1/correct", s.instrument("correct", http.HandlerFunc(s.handleCorrect)))
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}))
+	return mux
+}
+
+// handleCorrect corrects the text of a CorrectRequest. Clients accepting text/event-stream get
+// the correction as it arrives, like the TUI shows it.
+func (s *Server) handleCorrect(w http.ResponseWriter, r *http.Request) {
+	var req CorrectRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, "invalid request body: "+err.Error())
+		return
+	}
+	// A text too long for one request is corrected in chunks
+	if err := validation.ValidateNotEmpty(req.Text); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var events *eventStream
+	if acceptsEventStream(r) {
+		events = newEventStream(w)
+	}
+
+	ctx, span := tracing.Start(r.Context(), "correct",
+		attribute.Int("text.length", len(req.Text)),
+		attribute.Bool("stream", events != nil))
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	cor := s.corrector
+	if s.opts.DetectLanguage {
+		if language, ok := langdetect.Detect(req.Text); ok {
+			cor = cor.WithLanguage(language)
+		}
+	}
+	hash := ""
+	if s.cache != nil {
+		hash = s.cache.CorrectionHash(req.Text, cor.Style(), s.opts.Model, cor.Language(), cor.PromptVersion())
+		_, lookup := tracing.Start(ctx, "cache.lookup")
+		cached := strings.TrimRight(s.cache.Get(hash), " \t\r\n")
+		lookup.SetAttributes(attribute.Bool("cache.hit", cached != ""))
+		lookup.End()
+		if cached != "" {
+			resp := CorrectResponse{Original: req.Text, Corrected: cached, Cached: true}
+			if events != nil {
+				events.send("chunk", ChunkEvent{Text: cached})
+				resp.Changes = grammr.Changes(req.Text, cached)
+				events.send("done", resp)
+				return
+			}
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+	}
+
+	timeout := s.opts.Timeout
+	if s.opts.ScaleTimeouts {
+		timeout = estimate.Timeout(timeout, req.Text)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var corrected string
+	if events != nil {
+		var b strings.Builder
+		err = cor.StreamCorrect(ctx, req.Text, func(chunk string) {
+			b.WriteString(chunk)
+			events.send("chunk", ChunkEvent{Text: chunk})
+		})
+		corrected = b.String()
+	} else {
+		corrected, err = cor.Correct(ctx, req.Text)
+	}
+	if err != nil {
+		if events != nil {
+			// The status was sent with the first event, so the error is an event too
+			events.send("error", ErrorResponse{Error: apierror.Message(err)})
+			return
+		}
+		writeError(w, errorStatus(err), apierror.Message(err))
+		return
+	}
+	corrected = strings.TrimRight(corrected, " \t\r\n")
+	s.metrics.tokens.WithLabelValues("input").Add(float64(estimate.Tokens(req.Text) + estimate.PromptOverhead))
+	s.metrics.tokens.WithLabelValues("output").Add(float64(estimate.Tokens(corrected)))
+
+	if s.cache != nil {
+		// A correction that can't be cached is still a correction
+		_ = s.cache.Set(hash, req.Text, corrected)
+	}
+	resp := CorrectResponse{Original: req.Text, Corrected: corrected}
+	if events != nil {
+		resp.Changes = grammr.Changes(req.Text, corrected)
+		events.send("done", resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleHealth reports that the server is up, for load balancers and supervisors
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":   "ok",
+		"provider": s.opts.Provider,
+		"model":    s.opts.Model,
+	})
+}
+
+// instrument counts the requests of an endpoint and times them
+func (s *Server) instrument(endpoint string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		s.metrics.duration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+		s.metrics.requests.WithLabelValues(endpoint, strconv.Itoa(recorder.status)).Inc()
+	})
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController flush the response of a stream
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// errorStatus picks the status code of a failed correction
+func errorStatus(err error) int {
+	switch apierror.KindOf(err) {
+	case apierror.KindRateLimited, apierror.KindQuotaExceeded:
+		return http.StatusTooManyRequests
+	case apierror.KindContextLength:
+		return http.StatusRequestEntityTooLarge
+	case apierror.KindNetwork:
+		if errors.Is(err, context.DeadlineExceeded) {
+			return http.StatusGatewayTimeout
+		}
+	}
+	return http.StatusBadGateway
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
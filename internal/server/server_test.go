@@ -0,0 +1,311 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/cache"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/zalando/go-keyring"
+)
+
+func TestMain(m *testing.M) {
+	// Keep the cache's encryption key out of the real keychain
+	keyring.MockInit()
+	os.Exit(m.Run())
+}
+
+// fakeProvider answers every request with the same response or error
+type fakeProvider struct {
+	response string
+	err      error
+	calls    int
+}
+
+func (p *fakeProvider) StreamChat(ctx context.Context, model string, messages []provider.Message, onChunk func(string)) error {
+	response, err := p.Chat(ctx, model, messages)
+	if err != nil {
+		return err
+	}
+	onChunk(response)
+	return nil
+}
+
+func (p *fakeProvider) Chat(ctx context.Context, model string, messages []provider.Message) (string, error) {
+	p.calls++
+	return p.response, p.err
+}
+
+func newTestServer(t *testing.T, prov provider.Provider, c *cache.Cache) *httptest.Server {
+	t.Helper()
+	cor, err := corrector.New(prov, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(New(cor, c, Options{Provider: "openai", Model: "gpt-4o", Timeout: time.Second}).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postCorrect(t *testing.T, srv *httptest.Server, body string) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := http.Post(srv.URL+"/v1/correct", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var decoded map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestCorrect(t *testing.T) {
+	tests := []struct {
+		name       string
+		prov       *fakeProvider
+		body       string
+		wantStatus int
+		wantField  string
+		want       string
+	}{
+		{
+			name:       "corrects the text",
+			prov:       &fakeProvider{response: "I have an apple.\n"},
+			body:       `{"text": "I has a apple."}`,
+			wantStatus: http.StatusOK,
+			wantField:  "corrected",
+			want:       "I have an apple.",
+		},
+		{
+			name:       "rejects a body that isn't JSON",
+			prov:       &fakeProvider{},
+			body:       `I has a apple.`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "error",
+			want:       "invalid request body",
+		},
+		{
+			name:       "corrects a long text in chunks",
+			prov:       &fakeProvider{response: "I have an apple.\n"},
+			body:       `{"text": "` + strings.Repeat("I has a apple. ", 8000) + `"}`,
+			wantStatus: http.StatusOK,
+			wantField:  "corrected",
+			want:       "I have an apple.",
+		},
+		{
+			name:       "rejects an empty text",
+			prov:       &fakeProvider{},
+			body:       `{"text": ""}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "error",
+			want:       "text cannot be empty",
+		},
+		{
+			name:       "explains a provider error",
+			prov:       &fakeProvider{err: &apierror.Error{Kind: apierror.KindRateLimited, Provider: "openai", Err: errors.New("429")}},
+			body:       `{"text": "I has a apple."}`,
+			wantStatus: http.StatusTooManyRequests,
+			wantField:  "error",
+			want:       "OpenAI is rate limiting your requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, tt.prov, nil)
+			status, body := postCorrect(t, srv, tt.body)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if got, _ := body[tt.wantField].(string); !strings.Contains(got, tt.want) {
+				t.Errorf("%s = %q, want %q", tt.wantField, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorrectUsesCache(t *testing.T) {
+	c, err := cache.NewInDir(t.TempDir(), 7, cache.BackendFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov := &fakeProvider{response: "I have an apple."}
+	srv := newTestServer(t, prov, c)
+
+	for i, wantCached := range []bool{false, true} {
+		status, body := postCorrect(t, srv, `{"text": "I has a apple."}`)
+		if status != http.StatusOK || body["corrected"] != "I have an apple." || body["cached"] != wantCached {
+			t.Errorf("request %d = %d %v, want cached %v", i+1, status, body, wantCached)
+		}
+	}
+	if prov.calls != 1 {
+		t.Errorf("provider was called %d times, want once", prov.calls)
+	}
+}
+
+func TestCorrectDetectsLanguage(t *testing.T) {
+	c, err := cache.NewInDir(t.TempDir(), 7, cache.BackendFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cor, err := corrector.New(&fakeProvider{response: "Ich habe einen Apfel.\n"}, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(New(cor, c, Options{Provider: "openai", Model: "gpt-4o", Timeout: time.Second, DetectLanguage: true}).Handler())
+	t.Cleanup(srv.Close)
+
+	german := cor.WithLanguage("german")
+	text := "Ich habe einen Apfel und er ist nicht rot."
+	if status, body := postCorrect(t, srv, `{"text": "`+text+`"}`); status != http.StatusOK || body["corrected"] != "Ich habe einen Apfel." {
+		t.Fatalf("POST /v1/correct = %d %v", status, body)
+	}
+	if c.Get(c.CorrectionHash(text, "casual", "gpt-4o", "german", german.PromptVersion())) == "" {
+		t.Error("the correction should be cached for the detected language")
+	}
+
+	// A cached correction is trimmed like a fresh one
+	other := "Ich habe keinen Apfel und er ist nicht rot."
+	if err := c.Set(c.CorrectionHash(other, "casual", "gpt-4o", "german", german.PromptVersion()), other, "Ich habe keinen Apfel.\n"); err != nil {
+		t.Fatal(err)
+	}
+	if status, body := postCorrect(t, srv, `{"text": "`+other+`"}`); status != http.StatusOK || body["corrected"] != "Ich habe keinen Apfel." || body["cached"] != true {
+		t.Errorf("POST /v1/correct = %d %v, want the trimmed cached correction", status, body)
+	}
+}
+
+// event is a server-sent event
+type event struct {
+	name string
+	data string
+}
+
+// streamCorrect posts body asking for server-sent events, and returns the events received
+func streamCorrect(t *testing.T, srv *httptest.Server, body string) []event {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/correct", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", got)
+	}
+
+	var events []event
+	var current event
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, current)
+			current = event{}
+		}
+	}
+	return events
+}
+
+func TestCorrectEventStream(t *testing.T) {
+	srv := newTestServer(t, &fakeProvider{response: "I have an apple."}, nil)
+	events := streamCorrect(t, srv, `{"text": "I has a apple."}`)
+	if len(events) < 2 {
+		t.Fatalf("received %v, want chunks and a final event", events)
+	}
+
+	var streamed strings.Builder
+	for _, e := range events[:len(events)-1] {
+		var chunk ChunkEvent
+		if e.name != "chunk" || json.Unmarshal([]byte(e.data), &chunk) != nil {
+			t.Fatalf("event %+v isn't a chunk", e)
+		}
+		streamed.WriteString(chunk.Text)
+	}
+	if streamed.String() != "I have an apple." {
+		t.Errorf("streamed %q, want the whole correction", streamed.String())
+	}
+
+	last := events[len(events)-1]
+	var done CorrectResponse
+	if last.name != "done" || json.Unmarshal([]byte(last.data), &done) != nil {
+		t.Fatalf("last event = %+v, want done", last)
+	}
+	if done.Corrected != "I have an apple." || len(done.Changes) != 2 || done.Changes[0].Original != "has" || done.Changes[0].Corrected != "have" {
+		t.Errorf("done = %+v, want the correction and its changes", done)
+	}
+}
+
+func TestCorrectEventStreamError(t *testing.T) {
+	srv := newTestServer(t, &fakeProvider{err: errors.New("connection reset")}, nil)
+	events := streamCorrect(t, srv, `{"text": "I has a apple."}`)
+	if len(events) != 1 || events[0].name != "error" || !strings.Contains(events[0].data, "connection reset") {
+		t.Errorf("received %+v, want an error event", events)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	srv := newTestServer(t, &fakeProvider{}, nil)
+	resp, err := http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"status":"ok"`) {
+		t.Errorf("GET /healthz = %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	c, err := cache.NewInDir(t.TempDir(), 7, cache.BackendFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, &fakeProvider{response: "I have an apple."}, c)
+	postCorrect(t, srv, `{"text": "I has a apple."}`)
+	postCorrect(t, srv, `{"text": "I has a apple."}`)
+	postCorrect(t, srv, `{"text": ""}`)
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		`grammr_requests_total{code="200",endpoint="correct"} 2`,
+		`grammr_requests_total{code="400",endpoint="correct"} 1`,
+		`grammr_request_duration_seconds_count{endpoint="correct"} 3`,
+		`grammr_cache_hits_total 1`,
+		`grammr_cache_misses_total 1`,
+		`grammr_cache_hit_ratio 0.5`,
+		`grammr_tokens_total{direction="input"}`,
+		`grammr_tokens_total{direction="output"} 5`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics are missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DefaultFile is the name of the session file in ~/.grammr
+	DefaultFile = "session.json"
+
+	// dirPerm is used when the session is the first file in its directory. The file itself is
+	// created readable by the user only, like the history.
+	dirPerm os.FileMode = 0700
+)
+
+// Session is what was open in the TUI: the texts of the panes and a review in progress
+type Session struct {
+	Saved         time.Time `json:"saved"` // Set by Save
+	Original      string    `json:"original"`
+	Corrected     string    `json:"corrected,omitempty"`
+	Result        string    `json:"result,omitempty"` // The corrected text as the correction returned it
+	Translation   string    `json:"translation,omitempty"`
+	RewriteSource string    `json:"rewrite_source,omitempty"`
+	RewriteLabel  string    `json:"rewrite_label,omitempty"`
+	Review        *Review   `json:"review,omitempty"`
+}
+
+// Review is a word-by-word review of the corrected text with the decisions made so far
+type Review struct {
+	Changes []Change `json:"changes"`
+	Current int      `json:"current"` // Index of the change being reviewed
+}
+
+// Change is one change of a review
+type Change struct {
+	Op          int    `json:"op"`                // -1 removed or replaced, 1 added
+	Removed     string `json:"removed,omitempty"` // Text of the original the change takes out
+	Added       string `json:"added,omitempty"`   // Text the correction puts in
+	Applied     bool   `json:"applied,omitempty"`
+	Skipped     bool   `json:"skipped,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+	Category    string `json:"category,omitempty"`
+}
+
+// Empty reports whether there is no text worth restoring
+func (s Session) Empty() bool {
+	return s.Original == "" && s.Corrected == "" && s.Translation == ""
+}
+
+// Load reads the session file at path. A missing file yields nil.
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes s to a temporary file and renames it over the session file, so a crash never
+// leaves half a session behind
+func Save(path string, s Session) error {
+	s.Saved = time.Now()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".session-*")
+	if err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the session file, if there is one
+func Clear(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove session: %w", err)
+	}
+	return nil
+}
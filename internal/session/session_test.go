@@ -0,0 +1,94 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), DefaultFile))
+	if err != nil || s != nil {
+		t.Fatalf("Load() = %v, %v, want nil", s, err)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grammr", DefaultFile)
+	want := Session{
+		Original:    "I has a apple.",
+		Corrected:   "I have an apple.",
+		Result:      "I have an apple.",
+		Translation: "Tengo una manzana.",
+		Review: &Review{
+			Changes: []Change{
+				{Op: -1, Removed: "has", Added: "have", Applied: true, Category: "grammar"},
+				{Op: -1, Removed: "a", Added: "an"},
+			},
+			Current: 1,
+		},
+	}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		t.Errorf("session file mode = %o, want it private", perm)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Saved.IsZero() {
+		t.Error("session has no time")
+	}
+	got.Saved = want.Saved
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("Load() = %+v, want %+v", *got, want)
+	}
+
+	if err := Clear(path); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if s, err := Load(path); err != nil || s != nil {
+		t.Fatalf("Load() after Clear() = %v, %v, want nil", s, err)
+	}
+	if err := Clear(path); err != nil {
+		t.Errorf("Clear() without a session error = %v", err)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load() of an invalid file should fail")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Session
+		want bool
+	}{
+		{name: "nothing", s: Session{}, want: true},
+		{name: "original", s: Session{Original: "Hi"}, want: false},
+		{name: "translation only", s: Session{Translation: "Hola"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Empty(); got != tt.want {
+				t.Errorf("Empty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package textdiff
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/maximbilan/grammr/internal/tracing"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// timeout bounds the search for the smallest diff of two texts. Past it, the diff found so far
+	// is refined no further, which can only make it coarser.
+	timeout = 500 * time.Millisecond
+	// lineModeLength is the combined length of two texts over which their changed lines are found
+	// first, and only those are compared a character at a time
+	lineModeLength = 20000
+)
+
+// Options control how texts are compared. The zero value compares characters and keeps every
+// change.
+type Options struct {
+	Words            bool // Compare whole words instead of characters
+	IgnoreWhitespace bool // Leave out changes that only touch whitespace
+}
+
+// Compute compares original with corrected
+func Compute(original, corrected string, opts Options) []diffmatchpatch.Diff {
+	diffs, _ := ComputeLengths(original, corrected, opts)
+	return diffs
+}
+
+// ComputeLengths is Compute that also returns the length of the original text each diff stands
+// for: none for an insertion, and the length of its text otherwise, except for unchanged text
+// that took the whitespace of the corrected text because of IgnoreWhitespace
+func ComputeLengths(original, corrected string, opts Options) ([]diffmatchpatch.Diff, []int) {
+	_, span := tracing.Start(context.Background(), "diff",
+		attribute.Int("diff.original_length", len(original)),
+		attribute.Int("diff.corrected_length", len(corrected)),
+		attribute.Bool("diff.words", opts.Words))
+	defer span.End()
+
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = timeout
+	var diffs []diffmatchpatch.Diff
+	if opts.Words {
+		// Word diffs need no cleanup, which would move changes back inside words
+		diffs = diffWords(dmp, original, corrected)
+	} else {
+		diffs = dmp.DiffMain(original, corrected, len(original)+len(corrected) > lineModeLength)
+		// Clean up the diff to make it more semantic (word-level rather than character-level)
+		diffs = dmp.DiffCleanupSemantic(diffs)
+	}
+	if opts.IgnoreWhitespace {
+		return dropWhitespaceChanges(diffs)
+	}
+	lengths := make([]int, len(diffs))
+	for i, diff := range diffs {
+		if diff.Type != diffmatchpatch.DiffInsert {
+			lengths[i] = len(diff.Text)
+		}
+	}
+	return diffs, lengths
+}
+
+// diffWords compares the texts a word at a time, so a change never starts or ends inside a word.
+// Each distinct word, run of spaces or punctuation mark becomes one character to diff.
+func diffWords(dmp *diffmatchpatch.DiffMatchPatch, original, corrected string) []diffmatchpatch.Diff {
+	index := make(map[string]rune)
+	var tokens []string
+	encode := func(text string) string {
+		var b strings.Builder
+		for _, token := range splitWords(text) {
+			r, ok := index[token]
+			if !ok {
+				// Skip the surrogate range, which isn't valid in a string
+				r = rune(len(tokens))
+				if r >= 0xD800 {
+					r += 0x800
+				}
+				index[token] = r
+				tokens = append(tokens, token)
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+
+	diffs := dmp.DiffMain(encode(original), encode(corrected), false)
+	for i, diff := range diffs {
+		var text strings.Builder
+		for _, r := range diff.Text {
+			if r >= 0xD800 {
+				r -= 0x800
+			}
+			text.WriteString(tokens[r])
+		}
+		diffs[i].Text = text.String()
+	}
+	return diffs
+}
+
+// splitWords splits text into words, runs of whitespace and single other characters
+func splitWords(text string) []string {
+	var tokens []string
+	kind := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+	start := 0
+	for i, r := range text {
+		if i > start {
+			prev := []rune(text[start:i])
+			if k := kind(r); k == 0 || k != kind(prev[len(prev)-1]) {
+				tokens = append(tokens, text[start:i])
+				start = i
+			}
+		}
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// dropWhitespaceChanges turns changes that only add, remove or replace whitespace into unchanged
+// text, taking the whitespace of the corrected text. It returns the length of the original text
+// each diff stands for, which differs from that of its text where whitespace was taken.
+func dropWhitespaceChanges(diffs []diffmatchpatch.Diff) ([]diffmatchpatch.Diff, []int) {
+	noSpace := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, s)
+	}
+
+	var result []diffmatchpatch.Diff
+	var lengths []int
+	add := func(op diffmatchpatch.Operation, text string, length int) {
+		if text == "" && length == 0 {
+			return
+		}
+		if n := len(result); n > 0 && result[n-1].Type == op {
+			result[n-1].Text += text
+			lengths[n-1] += length
+			return
+		}
+		result = append(result, diffmatchpatch.Diff{Type: op, Text: text})
+		lengths = append(lengths, length)
+	}
+	for i := 0; i < len(diffs); {
+		if diffs[i].Type == diffmatchpatch.DiffEqual {
+			add(diffmatchpatch.DiffEqual, diffs[i].Text, len(diffs[i].Text))
+			i++
+			continue
+		}
+		// Collect the whole change between two unchanged parts
+		var deleted, inserted strings.Builder
+		for ; i < len(diffs) && diffs[i].Type != diffmatchpatch.DiffEqual; i++ {
+			if diffs[i].Type == diffmatchpatch.DiffDelete {
+				deleted.WriteString(diffs[i].Text)
+			} else {
+				inserted.WriteString(diffs[i].Text)
+			}
+		}
+		if noSpace(deleted.String()) == noSpace(inserted.String()) {
+			add(diffmatchpatch.DiffEqual, inserted.String(), deleted.Len())
+			continue
+		}
+		add(diffmatchpatch.DiffDelete, deleted.String(), deleted.Len())
+		add(diffmatchpatch.DiffInsert, inserted.String(), 0)
+	}
+	return result, lengths
+}
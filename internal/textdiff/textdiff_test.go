@@ -0,0 +1,103 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name      string
+		original  string
+		corrected string
+		opts      Options
+		want      string // Changes marked as [-deleted-] and {+inserted+}
+	}{
+		{
+			name:      "words",
+			original:  "I has a apple.",
+			corrected: "I have an apple.",
+			opts:      Options{Words: true},
+			want:      "I [-has-]{+have+} [-a-]{+an+} apple.",
+		},
+		{
+			name:      "characters",
+			original:  "recieve",
+			corrected: "receive",
+			opts:      Options{},
+			want:      "rec[-i-]e{+i+}ve",
+		},
+		{
+			name:      "whitespace ignored",
+			original:  "Hello  world",
+			corrected: "Hello world",
+			opts:      Options{Words: true, IgnoreWhitespace: true},
+			want:      "Hello world",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got strings.Builder
+			for _, diff := range Compute(tt.original, tt.corrected, tt.opts) {
+				switch diff.Type {
+				case diffmatchpatch.DiffDelete:
+					got.WriteString("[-" + diff.Text + "-]")
+				case diffmatchpatch.DiffInsert:
+					got.WriteString("{+" + diff.Text + "+}")
+				default:
+					got.WriteString(diff.Text)
+				}
+			}
+			if got.String() != tt.want {
+				t.Errorf("Compute() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeLongTexts(t *testing.T) {
+	var original, corrected strings.Builder
+	for i := 0; i < 2000; i++ {
+		original.WriteString("Their going too the store tomorow, i think.\n")
+		corrected.WriteString("They're going to the store tomorrow, I think.\n")
+	}
+
+	for _, opts := range []Options{{}, {Words: true}} {
+		diffs := Compute(original.String(), corrected.String(), opts)
+		var from, to strings.Builder
+		for _, diff := range diffs {
+			if diff.Type != diffmatchpatch.DiffInsert {
+				from.WriteString(diff.Text)
+			}
+			if diff.Type != diffmatchpatch.DiffDelete {
+				to.WriteString(diff.Text)
+			}
+		}
+		if from.String() != original.String() || to.String() != corrected.String() {
+			t.Errorf("Compute(%+v) doesn't rebuild the texts", opts)
+		}
+	}
+}
+
+func TestComputeLengths(t *testing.T) {
+	original, corrected := "a b. Remove this.", "a          b."
+	for _, opts := range []Options{{}, {Words: true}, {Words: true, IgnoreWhitespace: true}, {IgnoreWhitespace: true}} {
+		diffs, lengths := ComputeLengths(original, corrected, opts)
+		if len(lengths) != len(diffs) {
+			t.Fatalf("ComputeLengths(%+v) returned %d lengths for %d diffs", opts, len(lengths), len(diffs))
+		}
+		total := 0
+		for i, diff := range diffs {
+			if diff.Type == diffmatchpatch.DiffDelete && lengths[i] != len(diff.Text) {
+				t.Errorf("ComputeLengths(%+v) length of %q = %d, want %d", opts, diff.Text, lengths[i], len(diff.Text))
+			}
+			total += lengths[i]
+		}
+		if total != len(original) {
+			t.Errorf("ComputeLengths(%+v) lengths add up to %d, want %d", opts, total, len(original))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormalityAuto is the default formality, which leaves the form of address to the model
+const FormalityAuto = "auto"
+
+// Formalities lists the supported translation formalities
+var Formalities = []string{FormalityAuto, "formal", "informal"}
+
+// formalityInstructions tell the model which form of address to use in languages that distinguish
+// them, such as tu/vous or du/Sie
+var formalityInstructions = map[string]string{
+	"formal":   "Use the formal form of address (for example vous, Sie, usted or Lei) where the language has one.",
+	"informal": "Use the informal form of address (for example tu, du or tú) where the language has one.",
+}
+
+// SetFormality sets the form of address for translations: "formal", "informal" or "auto" (the
+// default) to let the model choose
+func (t *Translator) SetFormality(formality string) error {
+	formality = strings.ToLower(strings.TrimSpace(formality))
+	if formality == "" {
+		formality = FormalityAuto
+	}
+	if _, ok := formalityInstructions[formality]; !ok && formality != FormalityAuto {
+		return fmt.Errorf("unknown formality: %s (supported: %s)", formality, strings.Join(Formalities, ", "))
+	}
+	t.formality = formality
+	return nil
+}
+
+func (t *Translator) formalityInstruction() string {
+	instruction, ok := formalityInstructions[t.formality]
+	if !ok {
+		return ""
+	}
+	return "\n" + instruction
+}
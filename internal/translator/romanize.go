@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/maximbilan/grammr/internal/postprocess"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// romanizationSystems maps languages written in a non-Latin script to the romanization learners
+// usually read them in
+var romanizationSystems = map[string]string{
+	"japanese":  "Hepburn romaji",
+	"chinese":   "Hanyu Pinyin with tone marks",
+	"korean":    "the Revised Romanization of Korean",
+	"russian":   "a scientific transliteration",
+	"ukrainian": "the official Ukrainian national transliteration",
+	"greek":     "the ISO 843 transliteration",
+	"arabic":    "a simple Latin transliteration with vowels",
+	"hebrew":    "a simple Latin transliteration with vowels",
+	"hindi":     "IAST",
+	"thai":      "the Royal Thai General System of Transcription",
+}
+
+// CanRomanize reports whether the translation language is written in a script Romanize can
+// spell out in Latin letters
+func (t *Translator) CanRomanize() bool {
+	_, ok := romanizationSystems[strings.ToLower(strings.TrimSpace(t.translationLanguage))]
+	return ok
+}
+
+// Romanize spells out a translation in Latin letters, such as pinyin or romaji, so learners can
+// read it
+func (t *Translator) Romanize(ctx context.Context, text string) (string, error) {
+	if err := validation.ValidateText(text); err != nil {
+		return "", err
+	}
+	system, ok := romanizationSystems[strings.ToLower(strings.TrimSpace(t.translationLanguage))]
+	if !ok {
+		return "", fmt.Errorf("romanization is not supported for %s", t.translationLanguage)
+	}
+
+	// Apply rate limiting if enabled
+	if t.rateLimiter != nil {
+		if err := t.rateLimiter.Wait(ctx); err != nil {
+			return "", fmt.Errorf("rate limit error: %w", err)
+		}
+	}
+
+	messages := []provider.Message{
+		{
+			Role:    provider.RoleUser,
+			Content: buildRomanizationPrompt(text, t.translationLanguage, system),
+		},
+	}
+
+	romanized, err := t.provider.Chat(ctx, t.model, messages)
+	if err != nil {
+		return "", err
+	}
+	return postprocess.Clean(romanized, text), nil
+}
+
+func buildRomanizationPrompt(text, language, system string) string {
+	return fmt.Sprintf("Write the following %s text in Latin letters using %s. Keep the line breaks and punctuation. Only output the romanized text, nothing else.\n\nText to romanize:\n%s", language, system, text)
+}
@@ -0,0 +1,61 @@
+package translator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/maximbilan/grammr/internal/provider"
+)
+
+func TestCanRomanize(t *testing.T) {
+	tests := []struct {
+		language string
+		want     bool
+	}{
+		{language: "japanese", want: true},
+		{language: "Chinese", want: true},
+		{language: "korean", want: true},
+		{language: "russian", want: true},
+		{language: "french", want: false},
+		{language: "english", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			tr := &Translator{translationLanguage: tt.language}
+			if got := tr.CanRomanize(); got != tt.want {
+				t.Errorf("CanRomanize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRomanize(t *testing.T) {
+	mock := provider.NewMockProvider()
+	prompt := buildRomanizationPrompt("你好", "chinese", romanizationSystems["chinese"])
+	mock.SetResponse(prompt, "Nǐ hǎo")
+
+	tr, err := NewWithRateLimit(mock, "gpt-4o", "chinese", nil)
+	if err != nil {
+		t.Fatalf("NewWithRateLimit() error = %v", err)
+	}
+	got, err := tr.Romanize(context.Background(), "你好")
+	if err != nil {
+		t.Fatalf("Romanize() error = %v", err)
+	}
+	if got != "Nǐ hǎo" {
+		t.Fatalf("Romanize() = %q, want %q", got, "Nǐ hǎo")
+	}
+	if !strings.Contains(prompt, "Pinyin") {
+		t.Fatalf("expected the prompt to name the romanization, got: %q", prompt)
+	}
+
+	tr, err = NewWithRateLimit(mock, "gpt-4o", "french", nil)
+	if err != nil {
+		t.Fatalf("NewWithRateLimit() error = %v", err)
+	}
+	if _, err := tr.Romanize(context.Background(), "Bonjour"); err == nil {
+		t.Fatal("Romanize() expected an error for a Latin-script language")
+	}
+}
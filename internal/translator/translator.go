@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/maximbilan/grammr/internal/postprocess"
 	"github.com/maximbilan/grammr/internal/provider"
 	"github.com/maximbilan/grammr/internal/ratelimit"
 	"github.com/maximbilan/grammr/internal/validation"
@@ -15,6 +17,9 @@ type Translator struct {
 	model             string
 	translationLanguage string
 	rateLimiter       *ratelimit.RateLimiter
+	glossary          *glossary.Glossary // Terminology to keep in translations, may be nil
+	sourceLanguage    string             // Language of the text to translate, empty if unknown
+	formality         string             // Form of address: "auto", "formal" or "informal"
 }
 
 // NewWithRateLimit creates a new Translator with an optional rate limiter
@@ -39,11 +44,37 @@ func NewWithRateLimit(prov provider.Provider, model, translationLanguage string,
 	}, nil
 }
 
+// WithSourceLanguage returns a copy of the translator for text written in language. The original
+// is left untouched, so requests already in flight keep their language.
+func (t *Translator) WithSourceLanguage(language string) *Translator {
+	copied := *t
+	copied.sourceLanguage = language
+	return &copied
+}
+
+// Language returns the language the translator translates to
+func (t *Translator) Language() string {
+	return t.translationLanguage
+}
+
+// SetGlossary sets the terminology that translations must follow. A nil or empty glossary removes it.
+func (t *Translator) SetGlossary(g *glossary.Glossary) {
+	t.glossary = g
+}
+
 func (t *Translator) buildPrompt(text string) string {
+	glossaryInstruction := t.formalityInstruction()
+	if !t.glossary.Empty() {
+		glossaryInstruction += "\n" + t.glossary.Instruction() + " Keep names and terms without an alternative untranslated."
+	}
+	source := ""
+	if t.sourceLanguage != "" {
+		source = t.sourceLanguage + " "
+	}
 	if t.translationLanguage == "" {
-		return fmt.Sprintf("Translate the following text to English. Only output the translated text, nothing else.\n\nText to translate:\n%s", text)
+		return fmt.Sprintf("Translate the following %stext to English. Only output the translated text, nothing else.%s\n\nText to translate:\n%s", source, glossaryInstruction, text)
 	}
-	return fmt.Sprintf("Translate the following text to %s. Only output the translated text, nothing else.\n\nText to translate:\n%s", t.translationLanguage, text)
+	return fmt.Sprintf("Translate the following %stext to %s. Only output the translated text, nothing else.%s\n\nText to translate:\n%s", source, t.translationLanguage, glossaryInstruction, text)
 }
 
 func (t *Translator) StreamTranslate(ctx context.Context, text string, onChunk func(string)) error {
@@ -65,17 +96,19 @@ func (t *Translator) StreamTranslate(ctx context.Context, text string, onChunk f
 		},
 	}
 
-	return t.provider.StreamChat(ctx, t.model, messages, onChunk)
+	// Drop preambles like "Here is the translation:" before they reach the UI
+	cleaner := postprocess.NewStream(text, onChunk)
+	if err := t.provider.StreamChat(ctx, t.model, messages, cleaner.Write); err != nil {
+		return err
+	}
+	cleaner.Close()
+	return nil
 }
 
 // Translate performs a non-streaming translation (fallback)
 func (t *Translator) Translate(ctx context.Context, text string) (string, error) {
-	if text == "" {
-		return "", fmt.Errorf("text cannot be empty")
-	}
-
-	if len(text) > validation.MaxInputLength {
-		return "", fmt.Errorf("text exceeds maximum length of %d characters (got %d)", validation.MaxInputLength, len(text))
+	if err := validation.ValidateText(text); err != nil {
+		return "", err
 	}
 
 	// Apply rate limiting if enabled
@@ -92,5 +125,9 @@ func (t *Translator) Translate(ctx context.Context, text string) (string, error)
 		},
 	}
 
-	return t.provider.Chat(ctx, t.model, messages)
+	translated, err := t.provider.Chat(ctx, t.model, messages)
+	if err != nil {
+		return "", err
+	}
+	return postprocess.Clean(translated, text), nil
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/maximbilan/grammr/internal/glossary"
 	"github.com/maximbilan/grammr/internal/provider"
 	"github.com/maximbilan/grammr/internal/ratelimit"
 	"github.com/maximbilan/grammr/internal/validation"
@@ -82,6 +83,71 @@ func TestBuildPrompt(t *testing.T) {
 			t.Fatalf("expected english fallback prompt, got: %q", prompt)
 		}
 	})
+
+	t.Run("includes glossary", func(t *testing.T) {
+		tr := &Translator{translationLanguage: "german"}
+		tr.SetGlossary(&glossary.Glossary{Terms: []glossary.Term{{Preferred: "Kubernetes"}}})
+		prompt := tr.buildPrompt("Deploy to Kubernetes")
+		if !strings.Contains(prompt, `Write "Kubernetes" exactly like this.`) {
+			t.Fatalf("expected prompt to include the glossary, got: %q", prompt)
+		}
+	})
+	t.Run("names the source language", func(t *testing.T) {
+		tr := &Translator{translationLanguage: "english"}
+		withSource := tr.WithSourceLanguage("french")
+		if prompt := withSource.buildPrompt("Bonjour"); !strings.Contains(prompt, "following french text to english") {
+			t.Fatalf("expected prompt to name the source language, got: %q", prompt)
+		}
+		if prompt := tr.buildPrompt("Bonjour"); strings.Contains(prompt, "french") {
+			t.Fatalf("WithSourceLanguage() should not change the original translator, got: %q", prompt)
+		}
+	})
+
+	t.Run("asks for the chosen formality", func(t *testing.T) {
+		tr := &Translator{translationLanguage: "german"}
+		if prompt := tr.buildPrompt("How are you?"); strings.Contains(prompt, "form of address") {
+			t.Fatalf("expected no formality instruction by default, got: %q", prompt)
+		}
+		if err := tr.SetFormality("Formal"); err != nil {
+			t.Fatalf("SetFormality() error = %v", err)
+		}
+		if prompt := tr.buildPrompt("How are you?"); !strings.Contains(prompt, "formal form of address") {
+			t.Fatalf("expected prompt to ask for the formal form, got: %q", prompt)
+		}
+		if err := tr.SetFormality("informal"); err != nil {
+			t.Fatalf("SetFormality() error = %v", err)
+		}
+		if prompt := tr.buildPrompt("How are you?"); !strings.Contains(prompt, "informal form of address") {
+			t.Fatalf("expected prompt to ask for the informal form, got: %q", prompt)
+		}
+	})
+}
+
+func TestSetFormality(t *testing.T) {
+	tests := []struct {
+		formality string
+		want      string
+		wantErr   bool
+	}{
+		{formality: "", want: "auto"},
+		{formality: "auto", want: "auto"},
+		{formality: " FORMAL ", want: "formal"},
+		{formality: "informal", want: "informal"},
+		{formality: "polite", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formality, func(t *testing.T) {
+			tr := &Translator{}
+			err := tr.SetFormality(tt.formality)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetFormality(%q) error = %v, wantErr %v", tt.formality, err, tt.wantErr)
+			}
+			if !tt.wantErr && tr.formality != tt.want {
+				t.Errorf("SetFormality(%q) formality = %q, want %q", tt.formality, tr.formality, tt.want)
+			}
+		})
+	}
 }
 
 func TestTranslate(t *testing.T) {
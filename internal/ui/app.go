@@ -2,23 +2,39 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/apierror"
 	"github.com/maximbilan/grammr/internal/cache"
 	"github.com/maximbilan/grammr/internal/clipboard"
 	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/consistency"
 	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/maximbilan/grammr/internal/history"
+	"github.com/maximbilan/grammr/internal/merge"
 	"github.com/maximbilan/grammr/internal/provider"
 	"github.com/maximbilan/grammr/internal/ratelimit"
+	"github.com/maximbilan/grammr/internal/redact"
+	"github.com/maximbilan/grammr/internal/sanitize"
+	"github.com/maximbilan/grammr/internal/session"
+	"github.com/maximbilan/grammr/internal/tracing"
 	"github.com/maximbilan/grammr/internal/translator"
 	"github.com/maximbilan/grammr/internal/validation"
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // trimTrailingWhitespace removes trailing whitespace from text
@@ -54,51 +70,57 @@ func wrapText(text string, width int) string {
 	return wrapped.String()
 }
 
-// createRateLimiter creates a rate limiter from config, or returns nil if disabled
-func createRateLimiter(cfg *config.Config) *ratelimit.RateLimiter {
-	if !cfg.RateLimitEnabled {
-		return nil
-	}
-	maxRequests := cfg.RateLimitRequests
-	if maxRequests <= 0 {
-		maxRequests = 60 // Default
-	}
-	windowSeconds := cfg.RateLimitWindow
-	if windowSeconds <= 0 {
-		windowSeconds = 60 // Default: per minute
-	}
-	return ratelimit.New(maxRequests, time.Duration(windowSeconds)*time.Second, 100*time.Millisecond)
-}
-
 // createTimeoutContext creates a context with timeout from config, with default fallback
 func createTimeoutContext(cfg *config.Config) (context.Context, context.CancelFunc) {
-	timeoutSeconds := cfg.RequestTimeoutSeconds
-	if timeoutSeconds <= 0 {
-		timeoutSeconds = 30 // Default fallback
-	}
-	return context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+	return context.WithTimeout(context.Background(), cfg.RequestTimeout())
 }
 
-// createProvider creates an AI provider based on the config
-func createProvider(cfg *config.Config) (provider.Provider, error) {
-	apiKey := cfg.GetAPIKey()
-	if err := validation.ValidateAPIKey(apiKey); err != nil {
+// newCorrector creates a corrector for the configured style, including user-defined styles,
+// the prompt template override and the glossary
+func newCorrector(cfg *config.Config, prov provider.Provider, rateLimits *ratelimit.Registry, gloss *glossary.Glossary) (*corrector.Corrector, error) {
+	rateLimiter := rateLimits.Limiter(cfg.Provider, ratelimit.OperationCorrection)
+	cor, err := corrector.NewWithCustomStyles(prov, cfg.Model, cfg.Style, cfg.Language, cfg.CustomStylePrompts(), rateLimiter)
+	if err != nil {
 		return nil, err
 	}
 
-	providerType := cfg.Provider
-	if providerType == "" {
-		providerType = "openai" // Default to OpenAI for backward compatibility
+	promptTemplate, err := cfg.LoadPromptTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if err := cor.SetPromptTemplate(promptTemplate); err != nil {
+		return nil, err
+	}
+	if err := cor.SetFormat(cfg.Format); err != nil {
+		return nil, err
+	}
+	if err := cor.SetDialect(cfg.Dialect); err != nil {
+		return nil, err
+	}
+	if err := cor.SetCategory(cfg.Category); err != nil {
+		return nil, err
 	}
+	cor.SetGlossary(gloss)
+	cor.SetChunkConcurrency(cfg.ChunkConcurrency)
+	return cor, nil
+}
 
-	switch providerType {
-	case "anthropic":
-		return provider.NewAnthropicProvider(apiKey)
-	case "openai":
-		return provider.NewOpenAIProvider(apiKey)
-	default:
-		return nil, fmt.Errorf("unknown provider: %s (supported: openai, anthropic)", providerType)
+// newTranslator creates the translator for the configured translation language, or returns nil
+// when translation is off
+func newTranslator(cfg *config.Config, prov provider.Provider, rateLimits *ratelimit.Registry, gloss *glossary.Glossary) (*translator.Translator, error) {
+	if cfg.TranslationLanguage == "" {
+		return nil, nil
+	}
+	rateLimiter := rateLimits.Limiter(cfg.Provider, ratelimit.OperationTranslation)
+	trans, err := translator.NewWithRateLimit(prov, cfg.Model, cfg.TranslationLanguage, rateLimiter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create translator: %w", err)
 	}
+	trans.SetGlossary(gloss)
+	if err := trans.SetFormality(cfg.FormalityFor(cfg.TranslationLanguage)); err != nil {
+		return nil, err
+	}
+	return trans, nil
 }
 
 func hasConfiguredAPIKey(cfg *config.Config) bool {
@@ -115,10 +137,15 @@ func missingAPIKeyMessage(cfg *config.Config) string {
 	return "API key not configured. Run: grammr config set api_key YOUR_KEY"
 }
 
+// correctionHash returns the cache key of a correction of text with the current corrector
+func (m Model) correctionHash(text string) string {
+	return m.cache.CorrectionHash(text, m.corrector.Style(), m.config.Model, m.corrector.Language(), m.corrector.PromptVersion())
+}
+
 // saveToCache saves corrected text to cache, handling errors gracefully
 func (m Model) saveToCache(original, corrected string) {
 	if m.cache != nil {
-		hash := m.cache.Hash(original)
+		hash := m.correctionHash(original)
 		if err := m.cache.Set(hash, original, corrected); err != nil {
 			// Cache write failed, but correction succeeded
 			// We'll return the correction normally, but could log this in the future
@@ -126,6 +153,18 @@ func (m Model) saveToCache(original, corrected string) {
 	}
 }
 
+// sweepCache removes expired cache entries in the background, so they don't pile up on disk
+func (m Model) sweepCache() tea.Cmd {
+	if m.cache == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		// Nothing to report, the cache works the same either way
+		_, _ = m.cache.Sweep(false)
+		return nil
+	}
+}
+
 type Mode int
 
 const (
@@ -135,46 +174,146 @@ const (
 	ModeEditTranslation
 	ModeHelp
 	ModeReviewDiff
+	ModeToneMenu
+	ModeConsistency
+	ModeConfirmSend
+	ModeHistory
+	ModeExportPatch
+	ModeModelPicker
+	ModeTranslationLanguage
+	ModeSearch
+	ModeCopyFormat
 )
 
 // DiffChange represents a single change in the diff
 type DiffChange struct {
-	Type    diffmatchpatch.Operation
-	Text    string
-	Applied bool // true if user applied this change
-	Skipped bool // true if user skipped this change
+	Type    diffmatchpatch.Operation // DiffDelete for removals and replacements, DiffInsert for additions
+	Removed string                   // Text of the original the change takes out
+	Added   string                   // Text the correction puts in
+	Applied bool                     // true if user applied this change
+	Skipped bool                     // true if user skipped this change
+	// Replacement is an alternative phrasing picked by the user; when set it is
+	// used instead of the suggested text if the change is applied
+	Replacement string
+	Category    string // Kind of change, see corrector.ChangeCategories
+}
+
+// appliedText returns the text to write when the change is applied
+func (c DiffChange) appliedText(suggested string) string {
+	if c.Replacement != "" {
+		return c.Replacement
+	}
+	return suggested
 }
 
 type Model struct {
 	// State
-	mode           Mode
-	originalText   string
-	correctedText  string
-	translatedText string
+	mode            Mode
+	originalText    string
+	correctedText   string
+	translatedText  string
+	rewriteSource   string // Text that was rewritten in a new tone; the corrected pane is diffed against it
+	rewriteLabel    string // Describes the last rewrite, empty for a regular correction
+	romanized       string // Romanization of romanizedSource
+	romanizedSource string // The translation that was romanized
+	// correctionResult is the corrected text as the last correction returned it; edits made to it
+	// are merged into the next correction of the same text
+	correctionResult string
+	correctionSource string           // The original text correctionResult is the correction of
+	mergeEdits       bool             // Merge the correction in progress with the edits
+	conflicts        []merge.Conflict // Where the last merge kept an edit over the correction
+	conflictText     string           // The merged text the conflicts refer to
 
 	// UI Components
 	originalEditor    textarea.Model
 	correctedEditor   textarea.Model
 	translationEditor textarea.Model
-	viewport          viewport.Model
+	panes             [paneCount]viewport.Model // Scroll positions of the panes
+	focusedPane       pane                      // The pane PgUp/PgDn and the mouse wheel scroll
+	zoomed            bool                      // Only the focused pane is shown, at full height
+	languageInput     textinput.Model           // Asks for the language to translate into
+	searchInput       textinput.Model           // Asks for the text to find with the vim keymap
+	searchQuery       string                    // The last text searched for
+	searchLine        int                       // Line of the focused pane with the current match
+	selecting         bool                      // A selection in the editor starts at selectionMark
+	selectionMark     int                       // Rune offset of the other end of the selection from the cursor
+	spinner           spinner.Model             // Turns while a request is in progress
+	spinning          bool                      // Whether a spinner tick is pending
+
+	// Loading state
+	loadingSince time.Time // When the correction in progress started
+	chunksDone   int       // Chunks of a chunked correction already corrected
+	chunkCount   int       // Chunks of a chunked correction, 0 for a single request
+	waitingUntil time.Time // When the request held up by the rate limit will be sent
+	waitingRetry string    // Why it is sent again, when it waits to be retried
 
 	// State flags
-	isLoading     bool
-	isTranslating bool
-	showDiff      bool
-	error         string
-	status        string
+	isLoading              bool
+	isTranslating          bool
+	translateOriginal      bool // Translate the original text instead of the corrected one
+	showDiff               bool
+	composing              bool // The original editor holds a new text typed in compose mode
+	isFetchingAlternatives bool
+	isExplaining           bool // Asking why the change under review was made
+	error                  string
+	failed                 failedRequest // The request behind error, when R can send it again
+	status                 string
+	estimate               string // Estimated tokens and cost of the last correction request
+	sourceLanguage         string // Language of the original text, detected or from the config
+	languageDetected       bool   // Whether sourceLanguage was detected rather than configured
+
+	// A question to answer before anything else, nil when there is none
+	confirmation *confirmation
+
+	// Notification state
+	toasts      []toast // Notifications shown next to the status until they expire
+	nextToastID int
+
+	// Session state
+	sessionFile  string          // Where the open texts are saved for the next launch, empty when turned off
+	savedSession session.Session // What was last written to sessionFile
+
+	// Clipboard state
+	clipboard clipboard.Clipboard // Where texts are copied to and pasted from
 
 	// Diff review state
 	diffChanges   []DiffChange // All changes from the diff
 	currentChange int          // Index of current change being reviewed
 	reviewedText  string       // Final text built from applied changes
+	alternatives  []string     // Alternative phrasings offered for the current change
+	bulkDecision  string       // "apply" or "skip" while asking to decide every remaining change
+
+	// Why changes were made, see explanationKey; kept for the whole session
+	explanations map[string]string
+
+	// Undo state
+	undoStack []snapshot // States to go back to, oldest first
+	redoStack []snapshot // Undone states, most recently undone last
+
+	// Consistency report state
+	consistencyIssues []consistency.Issue
+
+	// Model picker state
+	modelOptions []modelOption // Models of the providers with an API key
+	modelIndex   int           // Index of the selected model
+
+	// History browser state
+	historyEntries []history.Entry // Past corrections, newest first
+	historyIndex   int             // Index of the selected entry
 
 	// Services
 	corrector  *corrector.Corrector
 	translator *translator.Translator
+	providers  *providerFactory    // Creates the providers of correctors and translators
+	rateLimits *ratelimit.Registry // Budgets shared by every corrector and translator of the session
 	cache      *cache.Cache
 	config     *config.Config
+	glossary   *glossary.Glossary
+	history    *history.History
+	theme      Theme
+
+	configChanges  <-chan struct{}       // Changes to the config file, nil when it isn't watched
+	rateLimitWaits chan rateLimitWaitMsg // Requests held up by the rate limit or a retry, for the status line
 
 	// Dimensions
 	width  int
@@ -182,6 +321,17 @@ type Model struct {
 }
 
 // Messages
+
+// chunkProgressMsg reports the progress of a chunked correction of a text too long for a single
+// request
+type chunkProgressMsg struct {
+	original string
+	done     int                // Chunks corrected so far
+	total    int                // Chunks of the text
+	updates  <-chan tea.Msg     // Further progress, then the correctionDoneMsg or errMsg
+	cancel   context.CancelFunc // Stops the correction
+}
+
 type textPastedMsg struct {
 	text string
 }
@@ -189,22 +339,27 @@ type textPastedMsg struct {
 type correctionDoneMsg struct {
 	original  string
 	corrected string
+	cached    bool // Served from the cache instead of the API
+	different bool // Asked for a different phrasing than the previous correction
+	// paragraphs and kept count the paragraphs of an edited text and those whose correction was
+	// kept from the previous one, when only the changed paragraphs were corrected
+	paragraphs int
+	kept       int
 }
 
 type translationDoneMsg struct {
+	source     string // The text that was translated
 	translated string
 }
 
-type translationChunkMsg struct {
-	chunk string
-}
-
-type streamChunkMsg struct {
-	chunk string
+type romanizationDoneMsg struct {
+	translated string // The translation that was romanized
+	romanized  string
 }
 
 type errMsg struct {
-	err error
+	err    error
+	failed failedRequest // The correction or translation that failed, which R sends again
 }
 
 func (e errMsg) Error() string {
@@ -214,170 +369,130 @@ func (e errMsg) Error() string {
 	return "unknown error"
 }
 
-type statusMsg string
+func (e errMsg) Unwrap() error {
+	return e.err
+}
 
-// parseDiffIntoChanges parses the diff and returns a list of changes to review
-// It pairs delete+insert sequences as single changes for better UX
-func parseDiffIntoChanges(original, corrected string) []DiffChange {
-	dmp := diffmatchpatch.New()
-	diffs := dmp.DiffMain(original, corrected, false)
-	diffs = dmp.DiffCleanupSemantic(diffs)
+type statusMsg string
 
-	changes := make([]DiffChange, 0)
-	i := 0
-	for i < len(diffs) {
-		diff := diffs[i]
+type alternativesMsg struct {
+	changeIndex  int
+	alternatives []string
+}
 
-		if diff.Type == diffmatchpatch.DiffEqual {
-			i++
-			continue
+// wordContext returns the parts of the words the changed diffs[first:last+1] start and end in,
+// so a change inside a word can be looked at as a whole word
+func wordContext(diffs []diffmatchpatch.Diff, first, last int) (prefix, suffix string) {
+	if first > 0 && diffs[first-1].Type == diffmatchpatch.DiffEqual {
+		text := diffs[first-1].Text
+		prefix = text
+		if i := strings.LastIndexFunc(text, unicode.IsSpace); i >= 0 {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			prefix = text[i+size:]
 		}
-
-		// Check if this is a delete followed by an insert (common pattern)
-		if diff.Type == diffmatchpatch.DiffDelete && i+1 < len(diffs) && diffs[i+1].Type == diffmatchpatch.DiffInsert {
-			// Pair them as a single change
-			changes = append(changes, DiffChange{
-				Type:    diffmatchpatch.DiffDelete,           // Use delete as primary type
-				Text:    diff.Text + " → " + diffs[i+1].Text, // Show both
-				Applied: false,
-				Skipped: false,
-			})
-			i += 2
-		} else {
-			// Single change
-			changes = append(changes, DiffChange{
-				Type:    diff.Type,
-				Text:    diff.Text,
-				Applied: false,
-				Skipped: false,
-			})
-			i++
+	}
+	if last+1 < len(diffs) && diffs[last+1].Type == diffmatchpatch.DiffEqual {
+		text := diffs[last+1].Text
+		suffix = text
+		if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+			suffix = text[:i]
 		}
 	}
-	return changes
+	return prefix, suffix
 }
 
-// buildReviewedTextFromDiffs builds text from original and corrected using change decisions
-func buildReviewedTextFromDiffs(original, corrected string, changes []DiffChange) string {
-	dmp := diffmatchpatch.New()
-	diffs := dmp.DiffMain(original, corrected, false)
-	diffs = dmp.DiffCleanupSemantic(diffs)
-
-	var result strings.Builder
-	changeIdx := 0
-
-	for i := 0; i < len(diffs); i++ {
-		diff := diffs[i]
-
-		if diff.Type == diffmatchpatch.DiffEqual {
-			result.WriteString(diff.Text)
-		} else if diff.Type == diffmatchpatch.DiffDelete {
-			// Check if there's a following insert (paired change)
-			if i+1 < len(diffs) && diffs[i+1].Type == diffmatchpatch.DiffInsert {
-				// This is a paired delete+insert
-				if changeIdx < len(changes) {
-					change := changes[changeIdx]
-					// Verify this is a paired change (should contain " → ")
-					if strings.Contains(change.Text, " → ") {
-						if change.Applied {
-							// Apply the change: skip delete, add insert
-							result.WriteString(diffs[i+1].Text)
-						} else if change.Skipped {
-							// Skip the change: keep original (delete text)
-							result.WriteString(diff.Text)
-						} else {
-							// No decision yet - keep original
-							result.WriteString(diff.Text)
-						}
-						changeIdx++
-						i++ // Skip the insert as we've handled it
-					} else {
-						// Change doesn't match - this shouldn't happen
-						// Keep original and don't increment changeIdx
-						result.WriteString(diff.Text)
-						i++ // Skip the insert
-					}
-				} else {
-					// No more changes - keep original
-					result.WriteString(diff.Text)
-					i++ // Skip the insert
-				}
-			} else {
-				// Single delete (not paired with insert)
-				if changeIdx < len(changes) {
-					change := changes[changeIdx]
-					// This should be a single delete change (no " → " in text)
-					if change.Type == diffmatchpatch.DiffDelete && !strings.Contains(change.Text, " → ") {
-						if change.Skipped {
-							result.WriteString(diff.Text)
-						}
-						// If applied, we don't write it (it's deleted)
-						changeIdx++
-					} else {
-						// Mismatch - keep original
-						result.WriteString(diff.Text)
-					}
-				} else {
-					result.WriteString(diff.Text)
-				}
-			}
-		} else if diff.Type == diffmatchpatch.DiffInsert {
-			// Single insert (not paired with delete)
-			// This should only happen if we didn't pair it with a delete above
-			if changeIdx < len(changes) {
-				change := changes[changeIdx]
-				// This should be a single insert change
-				if change.Type == diffmatchpatch.DiffInsert && !strings.Contains(change.Text, " → ") {
-					if change.Applied {
-						result.WriteString(diff.Text)
-					}
-					// If skipped, we don't write it
-					changeIdx++
-				} else {
-					// Mismatch - don't write the insert
-				}
-			}
+// changeSummary counts the changes of each category, like "12 changes: 5 spelling, 4 grammar"
+func changeSummary(changes []DiffChange) string {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Category]++
+	}
+	var parts []string
+	for _, category := range corrector.ChangeCategories {
+		if counts[category] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[category], category))
 		}
 	}
+	noun := "changes"
+	if len(changes) == 1 {
+		noun = "change"
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d %s", len(changes), noun)
+	}
+	return fmt.Sprintf("%d %s: %s", len(changes), noun, strings.Join(parts, ", "))
+}
 
-	return result.String()
+// sentenceAround returns the sentence of text containing the byte range [start, end)
+func sentenceAround(text string, start, end int) string {
+	begin := strings.LastIndexAny(text[:start], ".!?\n") + 1
+	finish := len(text)
+	if idx := strings.IndexAny(text[end:], ".!?\n"); idx >= 0 {
+		finish = end + idx + 1
+	}
+	return strings.TrimSpace(text[begin:finish])
+}
+
+// readyStatus is the status of a model with nothing open. Without a clipboard to paste from, it
+// says how to get a text in instead.
+func (m Model) readyStatus() string {
+	if hint := clipboard.PasteHint(m.clipboard); hint != "" {
+		return fmt.Sprintf("Ready. Paste with your terminal or press N to type, ? for help (%s)", hint)
+	}
+	return "Ready. Press V to paste, C to copy, ? for help"
 }
 
 func NewModel(cfg *config.Config) (*Model, error) {
 	var c *cache.Cache
 	if cfg.CacheEnabled {
-		var err error
-		c, err = cache.New(cfg.CacheTTLDays)
+		cacheDir, err := cfg.CachePath()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cache: %w", err)
+		}
+		c, err = cache.NewInDir(cacheDir, cfg.CacheTTLDays, cfg.CacheBackend)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create cache: %w", err)
 		}
 	}
 
-	// Create provider
-	prov, err := createProvider(cfg)
+	// Create the rate limit budgets if enabled
+	rateLimits := cfg.OpenRateLimits()
+
+	gloss, err := cfg.LoadGlossary()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create provider: %w", err)
+		return nil, fmt.Errorf("failed to load glossary: %w", err)
 	}
 
-	// Create rate limiter if enabled
-	rateLimiter := createRateLimiter(cfg)
+	// A corrector that can't be created, for example with a mistyped API key, is created again
+	// when it is needed, so the config can be fixed without leaving
+	providers := newProviderFactory()
+	cor, trans, servicesErr := newServices(cfg, providers, rateLimits, gloss)
 
-	cor, err := corrector.NewWithRateLimit(prov, cfg.Model, cfg.Style, cfg.Language, rateLimiter)
+	theme, err := themeFor(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create corrector: %w", err)
+		return nil, err
 	}
 
-	var trans *translator.Translator
-	if cfg.TranslationLanguage != "" {
-		trans, err = translator.NewWithRateLimit(prov, cfg.Model, cfg.TranslationLanguage, rateLimiter)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create translator: %w", err)
-		}
+	hist, err := cfg.OpenHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	sessionFile, err := cfg.SessionFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+	var saved *session.Session
+	if sessionFile != "" {
+		// A session that can't be read is replaced by the next one
+		saved, _ = session.Load(sessionFile)
 	}
 
 	originalEditor := textarea.New()
 	originalEditor.Placeholder = "Original text will appear here..."
 	originalEditor.CharLimit = 0
+	// Pasted texts can be longer than the default limit of 99 lines
+	originalEditor.MaxHeight = 0
 	originalEditor.SetWidth(80)
 	originalEditor.SetHeight(10)
 
@@ -393,28 +508,58 @@ func NewModel(cfg *config.Config) (*Model, error) {
 	translationEditor.SetWidth(80)
 	translationEditor.SetHeight(10)
 
-	vp := viewport.New(80, 20)
+	languageInput := textinput.New()
+	languageInput.Prompt = "Translate into: "
+	languageInput.Placeholder = "e.g. french"
+	languageInput.SetValue(cfg.TranslationLanguage)
 
-	return &Model{
+	searchInput := textinput.New()
+	searchInput.Prompt = "/"
+
+	m := &Model{
 		mode:              ModeGlobal,
 		originalEditor:    originalEditor,
 		correctedEditor:   correctedEditor,
 		translationEditor: translationEditor,
-		viewport:          vp,
+		languageInput:     languageInput,
+		searchInput:       searchInput,
+		spinner:           newSpinner(),
 		showDiff:          cfg.ShowDiff,
+		translateOriginal: cfg.TranslateOriginal,
 		corrector:         cor,
 		translator:        trans,
+		providers:         providers,
+		rateLimits:        rateLimits,
+		rateLimitWaits:    make(chan rateLimitWaitMsg, 1),
 		cache:             c,
 		config:            cfg,
-		status:            "Ready. Press V to paste, C to copy, ? for help",
-	}, nil
+		glossary:          gloss,
+		history:           hist,
+		sessionFile:       sessionFile,
+		theme:             theme,
+		clipboard:         clipboard.New(cfg.Clipboard),
+	}
+	m.status = m.readyStatus()
+	if warning := cfg.ShadowedStylesWarning(); warning != "" {
+		m.status = "⚠ Warning: " + warning
+	}
+	if warning := cfg.UnsavedUpgradeWarning(); warning != "" {
+		m.status = "⚠ Warning: " + warning
+	}
+	if servicesErr != nil {
+		m.status = servicesStatus(servicesErr)
+	}
+	if saved != nil && !saved.Empty() {
+		*m = m.offerSession(*saved)
+	}
+	return m, nil
 }
 
 func (m Model) Init() tea.Cmd {
-	return textarea.Blink
+	return tea.Batch(textarea.Blink, m.sweepCache(), waitForConfigChange(m.configChanges), waitForRateLimit(m.rateLimitWaits))
 }
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -424,7 +569,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m = m.updateEditorDimensions()
 		return m, nil
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case tea.KeyMsg:
+		if m.confirmation != nil {
+			if msg.Paste {
+				return m, nil
+			}
+			return m.handleConfirmation(msg)
+		}
+
+		if msg.Paste {
+			return m.handlePaste(msg)
+		}
+
 		if m.mode == ModeHelp {
 			if msg.Type == tea.KeyEsc || msg.String() == "?" || msg.String() == "q" {
 				m.mode = ModeGlobal
@@ -437,13 +596,54 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleReviewMode(msg)
 		}
 
+		if m.mode == ModeToneMenu {
+			return m.handleToneMenu(msg)
+		}
+
+		if m.mode == ModeModelPicker {
+			return m.handleModelPicker(msg)
+		}
+
+		if m.mode == ModeTranslationLanguage {
+			return m.handleLanguagePrompt(msg)
+		}
+
+		if m.mode == ModeSearch {
+			return m.handleSearchPrompt(msg)
+		}
+
+		if m.mode == ModeConsistency {
+			return m.handleConsistencyMode(msg)
+		}
+
+		if m.mode == ModeConfirmSend {
+			return m.handleConfirmSend(msg)
+		}
+
+		if m.mode == ModeHistory {
+			return m.handleHistoryMode(msg)
+		}
+
+		if m.mode == ModeExportPatch {
+			return m.handleExportPatch(msg)
+		}
+
+		if m.mode == ModeCopyFormat {
+			return m.handleCopyFormat(msg)
+		}
+
 		if m.mode == ModeEditOriginal || m.mode == ModeEditCorrected || m.mode == ModeEditTranslation {
 			return m.handleEditMode(msg)
 		}
 
 		return m.handleGlobalMode(msg)
 
+	case sanitizedMsg:
+		return m.correctSanitized(msg)
+
 	case textPastedMsg:
+		m = m.saveUndo()
+		m = m.resetScroll()
 		// Show pasted text immediately (trim trailing whitespace)
 		trimmedText := trimTrailingWhitespace(msg.text)
 		m.originalText = trimmedText
@@ -452,63 +652,191 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.correctedEditor.SetValue("")
 		m.translatedText = ""
 		m.translationEditor.SetValue("")
-		m.isLoading = true
+		m.rewriteSource = ""
+		m.rewriteLabel = ""
 		m.isTranslating = false
-		m.status = "[●] Correcting..."
-		// Start async correction
-		return m, m.streamCorrection(trimmedText)
+		m = m.applySourceLanguage(trimmedText)
+		// Start async correction, asking first if the text is very large
+		return m.confirmOrCorrect(trimmedText)
 
 	case correctionDoneMsg:
 		// Trim trailing whitespace from both original and corrected
 		trimmedOriginal := trimTrailingWhitespace(msg.original)
 		trimmedCorrected := trimTrailingWhitespace(msg.corrected)
+		if trimmedOriginal != m.originalText {
+			// A new text served from the cache cost nothing
+			m = m.saveUndo()
+			m = m.resetScroll()
+			m.estimate = ""
+			m = m.applySourceLanguage(trimmedOriginal)
+		}
+		result, merged := trimmedCorrected, ""
+		if m.mergeEdits && trimmedOriginal == m.originalText {
+			// Keep the edits made to the previous correction
+			m, trimmedCorrected = m.mergeCorrection(result)
+			merged = mergeStatus(len(m.conflicts))
+		}
+		m.correctionResult = result
+		m.correctionSource = trimmedOriginal
+		m.mergeEdits = false
 		m.originalText = trimmedOriginal
 		m.correctedText = trimmedCorrected
 		m.originalEditor.SetValue(trimmedOriginal)
 		m.correctedEditor.SetValue(trimmedCorrected)
+		m.rewriteSource = ""
+		m.rewriteLabel = ""
 		m.isLoading = false
-		m.status = "✓ Done"
+		// Nothing is left to retry
+		m.error, m.failed = "", failedRequest{}
+		m.recordHistory()
+		m.recordJournal()
+		done := "✓ Done"
+		if msg.cached {
+			done = "✓ Done (cache hit)"
+		}
+		if msg.different {
+			done = "✓ Corrected differently (Ctrl+Z for the previous correction)"
+		}
+		if msg.kept > 0 {
+			done += fmt.Sprintf(" (%d of %d paragraphs changed)", msg.paragraphs-msg.kept, msg.paragraphs)
+		}
+		done += merged
+		m.status = done
 		if m.config.AutoCopy {
-			clipboard.Copy(trimmedCorrected)
-			m.status = "✓ Done (copied)"
+			m.clipboard.Copy(trimmedCorrected)
+			m.status = done + " (copied)"
 		}
 		// Trigger translation if translator is configured
-		if m.translator != nil && trimmedCorrected != "" {
+		if m.shouldTranslate(m.translationSource()) {
+			m.isTranslating = true
+			m.status = done + " [●] Translating..."
+			return m, m.streamTranslation(m.translationSource())
+		}
+		return m, nil
+
+	case rewriteDoneMsg:
+		m = m.saveUndo()
+		m.correctedText = msg.rewritten
+		m.correctedEditor.SetValue(msg.rewritten)
+		m.rewriteSource = msg.source
+		m.rewriteLabel = msg.label
+		m.isLoading = false
+		// Show the diff so the rewrite can be compared with the text it came from
+		m.showDiff = true
+		m.status = fmt.Sprintf("✓ Rewritten (%s)", msg.label)
+		if m.config.AutoCopy {
+			m.clipboard.Copy(msg.rewritten)
+			m.status += " (copied)"
+		}
+		// A translation of the original is still up to date
+		if !m.translateOriginal && m.shouldTranslate(msg.rewritten) {
 			m.isTranslating = true
 			m.status = "✓ Done [●] Translating..."
-			return m, m.streamTranslation(trimmedCorrected)
+			return m, m.streamTranslation(msg.rewritten)
 		}
 		return m, nil
 
 	case translationDoneMsg:
+		// Drop a translation of a text that is no longer the translation source
+		if msg.source != m.translationSource() {
+			return m, nil
+		}
 		trimmedTranslated := trimTrailingWhitespace(msg.translated)
 		m.translatedText = trimmedTranslated
 		m.translationEditor.SetValue(trimmedTranslated)
 		m.isTranslating = false
-		if m.status == "✓ Done [●] Translating..." {
-			m.status = "✓ Done ✓ Translated"
+		if m.failed.op == opTranslation {
+			m.error, m.failed = "", failedRequest{}
+		}
+		m.recordTranslation()
+		if done, ok := strings.CutSuffix(m.status, " [●] Translating..."); ok && strings.HasPrefix(done, "✓ Done") {
+			m.status = done + " ✓ Translated"
+		} else if m.status == "[●] Translating..." {
+			m.status = "✓ Translated"
+		}
+		if m.shouldRomanize(trimmedTranslated) {
+			return m, m.romanizeTranslation(trimmedTranslated)
 		}
 		return m, nil
 
-	case translationChunkMsg:
-		m.translatedText += msg.chunk
-		m.translationEditor.SetValue(m.translatedText)
+	case romanizationDoneMsg:
+		m.romanized = msg.romanized
+		m.romanizedSource = msg.translated
 		return m, nil
 
-	case streamChunkMsg:
-		m.correctedText += msg.chunk
-		m.correctedEditor.SetValue(m.correctedText)
-		return m, nil
+	case streamTickMsg:
+		return m.showStream(msg)
+
+	case configChangedMsg:
+		return m.reloadConfig()
+
+	case rateLimitWaitMsg:
+		return m.showRateLimitWait(msg)
+
+	case rateLimitWaitDoneMsg:
+		return m.endRateLimitWait(msg)
 
 	case errMsg:
-		m.error = msg.Error()
+		// Explain failed requests of a known kind rather than showing the SDK's error
+		m.error = apierror.Message(msg)
 		m.isLoading = false
-		m.status = fmt.Sprintf("✗ Error: %s", msg.Error())
+		m.mergeEdits = false
+		m.isFetchingAlternatives = false
+		m.isExplaining = false
+		m.status = fmt.Sprintf("✗ Error: %s", m.error)
+		m.failed = msg.failed
+		if msg.failed.op == opTranslation {
+			m.isTranslating = false
+		}
+		if msg.failed.op != opNone {
+			m.status += retryHint
+		}
 		return m, nil
 
 	case statusMsg:
 		m.status = string(msg)
 		return m, nil
+
+	case spinner.TickMsg:
+		return m.tickSpinner(msg)
+
+	case diffReadyMsg:
+		// The view draws the diff now that it is cached
+		return m, nil
+
+	case toastExpiredMsg:
+		return m.dismissToast(msg.id), nil
+
+	case configEditedMsg:
+		return m.applyConfigEdit(msg)
+
+	case externalEditDoneMsg:
+		return m.applyExternalEdit(msg), nil
+
+	case chunkProgressMsg:
+		// Stop a correction that was superseded by a new paste
+		if !m.isLoading || msg.original != m.originalText {
+			msg.cancel()
+			return m, nil
+		}
+		m.status = fmt.Sprintf("[●] Correcting (%d/%d)...", msg.done, msg.total)
+		m.chunksDone, m.chunkCount = msg.done, msg.total
+		return m, waitForChunks(msg.updates)
+
+	case explanationMsg:
+		return m.addExplanation(msg), nil
+
+	case selectionDoneMsg:
+		return m.applySelection(msg), nil
+
+	case alternativesMsg:
+		m.isFetchingAlternatives = false
+		// Ignore results that arrive after the user moved on to another change
+		if m.mode == ModeReviewDiff && msg.changeIndex == m.currentChange {
+			m.alternatives = msg.alternatives
+			m.status = fmt.Sprintf("Pick an alternative (1-%d) or keep reviewing", len(msg.alternatives))
+		}
+		return m, nil
 	}
 
 	return m, tea.Batch(cmds...)
@@ -517,36 +845,126 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // switchStyle changes the correction style and saves it to config
 func (m Model) switchStyle(styleName, displayName string) (tea.Model, tea.Cmd) {
 	m.config.Style = styleName
-	rateLimiter := createRateLimiter(m.config)
+	return m.reloadCorrector(fmt.Sprintf("Style: %s", displayName))
+}
 
-	// Create provider
-	prov, err := createProvider(m.config)
-	if err != nil {
-		return m, func() tea.Msg { return errMsg{err: err} }
+// switchDialect cycles through the English dialects, ending with no dialect
+func (m Model) switchDialect() (tea.Model, tea.Cmd) {
+	next := ""
+	if m.config.Dialect == "" {
+		next = corrector.Dialects[0]
+	} else {
+		for i, d := range corrector.Dialects {
+			if d == m.config.Dialect && i+1 < len(corrector.Dialects) {
+				next = corrector.Dialects[i+1]
+			}
+		}
 	}
+	m.config.Dialect = next
 
-	m.corrector, err = corrector.NewWithRateLimit(prov, m.config.Model, styleName, m.config.Language, rateLimiter)
+	label := "Dialect: Any"
+	if next != "" {
+		label = fmt.Sprintf("Dialect: %s", strings.ToUpper(next))
+	}
+	return m.reloadCorrector(label)
+}
+
+// switchCategory cycles through the correction categories
+func (m Model) switchCategory() (tea.Model, tea.Cmd) {
+	current := m.config.Category
+	if current == "" {
+		current = corrector.CategoryAll
+	}
+	next := corrector.Categories[0]
+	for i, c := range corrector.Categories {
+		if c == current && i+1 < len(corrector.Categories) {
+			next = corrector.Categories[i+1]
+		}
+	}
+	m.config.Category = next
+	return m.reloadCorrector(fmt.Sprintf("Fix: %s", styleLabel(next)))
+}
+
+// glossarySummary describes glossary violations for the corrected text label
+func glossarySummary(violations []glossary.Violation) string {
+	if len(violations) == 1 {
+		return fmt.Sprintf("Glossary: use %q instead of %q", violations[0].Preferred, violations[0].Found)
+	}
+	return fmt.Sprintf("%d glossary issues", len(violations))
+}
+
+// charCountLabel shows the length of text in characters next to a pane label. Characters are
+// counted as the user sees them, so an emoji is one character.
+func (m Model) charCountLabel(text string) string {
+	if text == "" {
+		return ""
+	}
+	count := validation.CharCount(text)
+	style := lipgloss.NewStyle().Foreground(m.theme.Muted)
+	if count > validation.MaxInputLength {
+		// Too long for a single request; the text is corrected in chunks
+		return style.Render(fmt.Sprintf(" %d chars (over %d, split into chunks)", count, validation.MaxInputLength))
+	}
+	return style.Render(fmt.Sprintf(" %d chars", count))
+}
+
+// redactionLabel shows how many items of personal data are replaced with placeholders before
+// the text is sent, when redact_pii is on
+func (m Model) redactionLabel(text string) string {
+	if !m.config.RedactPII {
+		return ""
+	}
+	n := redact.Count(text)
+	if n == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(m.theme.Muted).Render(fmt.Sprintf(", %d redacted", n))
+}
+
+// reloadCorrector recreates the corrector and translator after a config change and saves the
+// config
+func (m Model) reloadCorrector(status string) (tea.Model, tea.Cmd) {
+	cor, trans, err := newServices(m.config, m.providers, m.rateLimits, m.glossary)
 	if err != nil {
 		return m, func() tea.Msg { return errMsg{err: err} }
 	}
-	// Save style to config file
-	if err := config.Save(m.config); err != nil {
-		// Log error but don't fail - style is still changed in memory
-		m.status = fmt.Sprintf("Style: %s (config save failed)", displayName)
-	} else {
-		m.status = fmt.Sprintf("Style: %s", displayName)
+	m.corrector, m.translator = cor, trans
+	if m.originalText != "" {
+		m = m.applySourceLanguage(m.originalText)
+	}
+	// Save the change to the config file
+	m.status = status
+	return m.saveConfig()
+}
+
+// saveConfig saves the config, warning when that fails, which leaves the change applied in
+// memory, or when it puts an API key in the config file
+func (m Model) saveConfig() (Model, tea.Cmd) {
+	err := config.Save(m.config)
+	if errors.Is(err, config.ErrNoKeychain) {
+		return m.notify(levelWarn, "Warning: "+err.Error())
+	}
+	if err != nil {
+		return m.notify(levelWarn, fmt.Sprintf("Config save failed: %v", err))
 	}
 	return m, nil
 }
 
 func (m Model) handleGlobalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
+	key := m.globalKey(msg)
+	if model, cmd, ok := m.handleVimAction(key); ok {
+		return model, cmd
+	}
+
+	switch key {
 	case "v", "V":
-		return m, m.pasteAndCorrect()
+		return m.confirmDiscardEdits(func(m Model) (tea.Model, tea.Cmd) {
+			return m, m.pasteAndCorrect()
+		})
 	case "c", "C":
 		if m.correctedText != "" {
-			if err := clipboard.Copy(m.correctedText); err != nil {
-				return m, tea.Printf("Failed to copy: %v", err)
+			if err := m.clipboard.Copy(m.correctedText); err != nil {
+				return m.notify(levelError, fmt.Sprintf("Failed to copy: %v", err))
 			}
 			m.status = "✓ Copied to clipboard"
 		}
@@ -569,63 +987,128 @@ func (m Model) handleGlobalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "t", "T":
 		if m.translatedText != "" {
-			if err := clipboard.Copy(m.translatedText); err != nil {
-				return m, tea.Printf("Failed to copy: %v", err)
+			if err := m.clipboard.Copy(m.translatedText); err != nil {
+				return m.notify(levelError, fmt.Sprintf("Failed to copy: %v", err))
 			}
 			m.status = "✓ Translation copied to clipboard"
 		}
 		return m, nil
 	case "r", "R":
+		if m.error != "" && m.failed.op != opNone {
+			return m.retryFailed()
+		}
 		if m.originalText != "" {
-			m.isLoading = true
-			m.isTranslating = false
-			m.translatedText = ""
-			m.translationEditor.SetValue("")
-			m.status = "[●] Correcting..."
-			return m, m.correctText(m.originalText)
+			var ok bool
+			if m, ok = m.ensureServices(); !ok {
+				return m, nil
+			}
+			return m.recorrect(m.originalText)
 		}
 		return m, nil
+	case "ctrl+r":
+		return m.correctDifferently()
 	case "d", "D":
 		m.showDiff = !m.showDiff
 		return m, nil
+	case "k", "K":
+		return m.switchDiffMode()
+	case "n", "N":
+		return m.compose()
+	case "j", "J":
+		return m.promoteCorrection()
 	case "a", "A":
 		// Enter review mode to apply/skip changes word by word
-		if m.originalText != "" && m.correctedText != "" {
-			m.diffChanges = parseDiffIntoChanges(m.originalText, m.correctedText)
+		if m.diffBase() != "" && m.correctedText != "" {
+			m.diffChanges = m.diff().changes()
 			m.currentChange = 0
+			m.alternatives = nil
 			if len(m.diffChanges) > 0 {
 				m.mode = ModeReviewDiff
-				m.reviewedText = buildReviewedTextFromDiffs(m.originalText, m.correctedText, m.diffChanges)
-				m.status = fmt.Sprintf("Reviewing changes (%d/%d) - Tab: Apply, Space: Skip, Esc: Exit", m.currentChange+1, len(m.diffChanges))
+				m.reviewedText = m.diff().reviewedText(m.diffChanges)
+				m.status = reviewStatus(m.currentChange, len(m.diffChanges))
 			} else {
 				m.status = "No changes to review"
 			}
 		}
 		return m, nil
+	case "w", "W":
+		// Open the tone menu to rewrite the text
+		if m.rewriteSourceText() != "" && !m.isLoading {
+			m.mode = ModeToneMenu
+		}
+		return m, nil
+	case "m", "M":
+		if !m.isLoading {
+			return m.openModelPicker()
+		}
+		return m, nil
+	case "s", "S":
+		return m.startTransform(corrector.ActionShorten)
+	case "x", "X":
+		return m.startTransform(corrector.ActionExpand)
+	case "p", "P":
+		return m.startTransform(corrector.ActionSimplify)
+	case "l", "L":
+		return m.switchDialect()
+	case "f", "F":
+		return m.switchCategory()
+	case "i", "I":
+		return m.checkConsistency()
+	case "g", "G":
+		return m.switchLanguage()
+	case "b", "B":
+		return m.toggleTranslationSource()
+	case "ctrl+t":
+		return m.toggleTranslation()
+	case "h", "H":
+		return m.openHistory()
+	case "u", "U":
+		return m.exportPatch()
+	case "y", "Y":
+		return m.openCopyMenu()
+	case "ctrl+z":
+		return m.undo()
+	case "ctrl+y":
+		return m.redo()
+	case "ctrl+e":
+		return m.openExternalEditor()
+	case ",":
+		return m.openConfigEditor()
+	case "z", "Z":
+		return m.toggleZoom()
+	case "tab":
+		return m.focusNextPane(1)
+	case "shift+tab":
+		return m.focusNextPane(-1)
+	case "pgdown":
+		return m.scrollPane(func(vp *viewport.Model) { vp.ViewDown() })
+	case "pgup":
+		return m.scrollPane(func(vp *viewport.Model) { vp.ViewUp() })
 	case "?", "f1":
 		m.mode = ModeHelp
 		return m, nil
 	case "q", "Q":
 		return m, tea.Quit
 	case "ctrl+v":
-		return m, tea.Sequence(m.pasteAndCorrect(), func() tea.Msg {
-			time.Sleep(100 * time.Millisecond)
-			return nil
+		return m.confirmDiscardEdits(func(m Model) (tea.Model, tea.Cmd) {
+			return m, tea.Sequence(m.pasteAndCorrect(), func() tea.Msg {
+				time.Sleep(100 * time.Millisecond)
+				return nil
+			})
 		})
 	case "ctrl+c":
 		if m.correctedText != "" {
-			clipboard.Copy(m.correctedText)
+			m.clipboard.Copy(m.correctedText)
 			return m, tea.Quit
 		}
 		return m, tea.Quit
-	case "1":
-		return m.switchStyle("casual", "Casual")
-	case "2":
-		return m.switchStyle("formal", "Formal")
-	case "3":
-		return m.switchStyle("academic", "Academic")
-	case "4":
-		return m.switchStyle("technical", "Technical")
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		for _, option := range styleOptions(m.config) {
+			if option.key == msg.String() {
+				return m.switchStyle(option.name, option.label)
+			}
+		}
+		return m, nil
 	}
 
 	return m, nil
@@ -647,15 +1130,28 @@ func (m Model) updateEditorDimensions() Model {
 	m.correctedEditor.SetHeight(editorHeight)
 	m.translationEditor.SetWidth(editorWidth)
 	m.translationEditor.SetHeight(editorHeight)
-	m.viewport.Width = editorWidth
-	m.viewport.Height = m.height - 10
 	return m
 }
 
 func (m Model) handleEditMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.composing {
+		switch msg.String() {
+		case "esc":
+			return m.cancelCompose()
+		case "ctrl+j", "ctrl+s":
+			return m.submitCompose()
+		}
+	}
+
 	switch msg.String() {
+	case "ctrl+@":
+		return m.toggleSelection()
+	case "ctrl+g":
+		return m.correctSelection()
 	case "esc":
 		// Sync editor values with text fields before exiting
+		m.selecting = false
+		before := m.snapshot()
 		if m.mode == ModeEditOriginal {
 			m.originalText = trimTrailingWhitespace(m.originalEditor.Value())
 		} else if m.mode == ModeEditCorrected {
@@ -668,16 +1164,36 @@ func (m Model) handleEditMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.correctedEditor.Blur()
 		m.translationEditor.Blur()
 		m.mode = ModeGlobal
+		if m.originalText != before.originalText || m.correctedText != before.correctedText || m.translatedText != before.translatedText {
+			m = m.pushUndo(before)
+		}
 		return m, nil
 	case "ctrl+s":
 		if m.mode == ModeEditOriginal {
+			m.selecting = false
+			m = m.saveUndo()
 			m.originalText = trimTrailingWhitespace(m.originalEditor.Value())
 			m.originalEditor.Blur()
 			m.correctedEditor.Blur()
 			m.translationEditor.Blur()
 			m.mode = ModeGlobal
+			m = m.applySourceLanguage(m.originalText)
+			var ok bool
+			if m, ok = m.ensureServices(); !ok {
+				return m, nil
+			}
+			m.mergeEdits = m.hasEdits()
 			m.isLoading = true
 			m.status = "[●] Correcting..."
+			// Only the paragraphs that were edited need correcting again
+			if edit, ok := m.editedParagraphs(m.originalText); ok {
+				m.estimate = ""
+				if len(edit.changed) > 0 {
+					m.estimate = m.correctionEstimate(edit.changedText()).String()
+				}
+				return m, m.correctParagraphs(m.originalText, edit)
+			}
+			m.estimate = m.correctionEstimate(m.originalText).String()
 			return m, m.correctText(m.originalText)
 		}
 		return m, nil
@@ -701,72 +1217,88 @@ func (m Model) handleEditMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleReviewMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.bulkDecision != "" {
+		return m.handleBulkDecision(msg)
+	}
+
 	switch msg.String() {
+	case "q", "Q", "ctrl+c":
+		return m.quitReview()
 	case "tab":
 		// Apply current change
 		if m.currentChange < len(m.diffChanges) {
+			m = m.saveUndo()
 			m.diffChanges[m.currentChange].Applied = true
 			m.diffChanges[m.currentChange].Skipped = false
-			m.reviewedText = buildReviewedTextFromDiffs(m.originalText, m.correctedText, m.diffChanges)
-			m.currentChange++
-
-			if m.currentChange >= len(m.diffChanges) {
-				// All changes reviewed - rebuild to ensure final state is correct
-				m.reviewedText = buildReviewedTextFromDiffs(m.originalText, m.correctedText, m.diffChanges)
-				m.correctedText = m.reviewedText
-				m.correctedEditor.SetValue(m.reviewedText)
-				// Disable diff view to show the actual corrected text, not a diff
-				m.showDiff = false
-				// Copy to clipboard
-				if err := clipboard.Copy(m.reviewedText); err != nil {
-					m.status = fmt.Sprintf("✓ All changes reviewed (copy failed: %v)", err)
-				} else {
-					m.status = "✓ All changes reviewed (copied)"
-				}
-				m.mode = ModeGlobal
-			} else {
-				m.status = fmt.Sprintf("Reviewing changes (%d/%d) - Tab: Apply, Space: Skip, Esc: Exit", m.currentChange+1, len(m.diffChanges))
-			}
+			m = m.advanceReview()
 		}
 		return m, nil
 	case " ":
 		// Skip current change
 		if m.currentChange < len(m.diffChanges) {
+			m = m.saveUndo()
 			m.diffChanges[m.currentChange].Applied = false
 			m.diffChanges[m.currentChange].Skipped = true
-			m.reviewedText = buildReviewedTextFromDiffs(m.originalText, m.correctedText, m.diffChanges)
-			m.currentChange++
-
-			if m.currentChange >= len(m.diffChanges) {
-				// All changes reviewed - rebuild to ensure final state is correct
-				m.reviewedText = buildReviewedTextFromDiffs(m.originalText, m.correctedText, m.diffChanges)
-				m.correctedText = m.reviewedText
-				m.correctedEditor.SetValue(m.reviewedText)
-				// Disable diff view to show the actual corrected text, not a diff
-				m.showDiff = false
-				// Copy to clipboard
-				if err := clipboard.Copy(m.reviewedText); err != nil {
-					m.status = fmt.Sprintf("✓ All changes reviewed (copy failed: %v)", err)
-				} else {
-					m.status = "✓ All changes reviewed (copied)"
-				}
-				m.mode = ModeGlobal
-			} else {
-				m.status = fmt.Sprintf("Reviewing changes (%d/%d) - Tab: Apply, Space: Skip, Esc: Exit", m.currentChange+1, len(m.diffChanges))
+			m.diffChanges[m.currentChange].Replacement = ""
+			m = m.advanceReview()
+		}
+		return m, nil
+	case "o", "O":
+		// Ask the provider for alternative phrasings of the current change
+		if m.currentChange < len(m.diffChanges) && !m.isFetchingAlternatives {
+			var ok bool
+			if m, ok = m.ensureServices(); !ok {
+				return m, nil
 			}
+			m.isFetchingAlternatives = true
+			m.alternatives = nil
+			m.status = "[●] Fetching alternatives..."
+			return m, m.fetchAlternatives(m.currentChange)
+		}
+		return m, nil
+	case "w", "W":
+		return m.explainChange()
+	case "1", "2", "3":
+		// Apply the current change using the chosen alternative
+		choice := int(msg.String()[0] - '1')
+		if m.currentChange < len(m.diffChanges) && choice < len(m.alternatives) {
+			m = m.saveUndo()
+			m.diffChanges[m.currentChange].Applied = true
+			m.diffChanges[m.currentChange].Skipped = false
+			m.diffChanges[m.currentChange].Replacement = m.alternatives[choice]
+			m = m.advanceReview()
 		}
 		return m, nil
+	case "shift+tab", "left":
+		// Go back to revise the previous decision
+		return m.goToChange(m.currentChange - 1), nil
+	case "right":
+		return m.goToChange(m.currentChange + 1), nil
+	case "a", "A":
+		return m.confirmBulkDecision("apply"), nil
+	case "s", "S":
+		return m.confirmBulkDecision("skip"), nil
+	case "home":
+		return m.goToChange(0), nil
+	case "end":
+		return m.goToChange(len(m.diffChanges) - 1), nil
+	case "ctrl+z":
+		return m.undo()
+	case "ctrl+y":
+		return m.redo()
 	case "esc":
 		// Exit review mode and apply reviewed changes
+		m = m.saveUndo()
 		// Rebuild reviewedText to ensure it's up-to-date with all decisions
-		m.reviewedText = buildReviewedTextFromDiffs(m.originalText, m.correctedText, m.diffChanges)
+		m.reviewedText = m.diff().reviewedText(m.diffChanges)
 		// Update correctedText with the reviewed text (which includes all applied changes)
 		m.correctedText = m.reviewedText
 		m.correctedEditor.SetValue(m.reviewedText)
 		// Disable diff view to show the actual corrected text, not a diff
 		m.showDiff = false
+		m.alternatives = nil
 		// Copy to clipboard
-		if err := clipboard.Copy(m.reviewedText); err != nil {
+		if err := m.clipboard.Copy(m.reviewedText); err != nil {
 			m.status = fmt.Sprintf("Review mode exited (copy failed: %v)", err)
 		} else {
 			m.status = "Review mode exited (copied)"
@@ -777,57 +1309,200 @@ func (m Model) handleReviewMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// advanceReview moves to the next change after a decision, finishing the review when none are left
+func (m Model) advanceReview() Model {
+	m.reviewedText = m.diff().reviewedText(m.diffChanges)
+	m.currentChange++
+	m.alternatives = nil
+
+	if m.currentChange >= len(m.diffChanges) {
+		return m.finishReview()
+	}
+	m.status = reviewStatus(m.currentChange, len(m.diffChanges))
+	return m
+}
+
+// finishReview writes the reviewed text to the corrected pane once every change is decided
+func (m Model) finishReview() Model {
+	// All changes reviewed - rebuild to ensure final state is correct
+	m.reviewedText = m.diff().reviewedText(m.diffChanges)
+	m.correctedText = m.reviewedText
+	m.correctedEditor.SetValue(m.reviewedText)
+	// Disable diff view to show the actual corrected text, not a diff
+	m.showDiff = false
+	m.alternatives = nil
+	// Copy to clipboard
+	if err := m.clipboard.Copy(m.reviewedText); err != nil {
+		m.status = fmt.Sprintf("✓ All changes reviewed (copy failed: %v)", err)
+	} else {
+		m.status = "✓ All changes reviewed (copied)"
+	}
+	m.mode = ModeGlobal
+	return m
+}
+
+// undecidedChanges counts the changes that are neither applied nor skipped
+func (m Model) undecidedChanges() int {
+	count := 0
+	for _, change := range m.diffChanges {
+		if !change.Applied && !change.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
+// confirmBulkDecision asks before applying or skipping every change that isn't decided yet
+func (m Model) confirmBulkDecision(decision string) Model {
+	remaining := m.undecidedChanges()
+	if remaining == 0 {
+		m.status = "Every change is decided, press Esc to finish"
+		return m
+	}
+	verb := "Apply"
+	if decision == "skip" {
+		verb = "Skip"
+	}
+	m.bulkDecision = decision
+	m.status = fmt.Sprintf("%s all %d remaining changes? (y/n)", verb, remaining)
+	return m
+}
+
+func (m Model) handleBulkDecision(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m = m.saveUndo()
+		apply := m.bulkDecision == "apply"
+		m.bulkDecision = ""
+		for i := range m.diffChanges {
+			change := &m.diffChanges[i]
+			if !change.Applied && !change.Skipped {
+				change.Applied = apply
+				change.Skipped = !apply
+			}
+		}
+		return m.finishReview(), nil
+	case "n", "N", "esc":
+		m.bulkDecision = ""
+		m.status = reviewStatus(m.currentChange, len(m.diffChanges))
+	}
+	return m, nil
+}
+
+// goToChange moves to another change without deciding the current one. Its decision, if it has
+// one, can then be changed with Tab or Space.
+func (m Model) goToChange(index int) Model {
+	if index < 0 || index >= len(m.diffChanges) || index == m.currentChange {
+		return m
+	}
+	m.currentChange = index
+	m.alternatives = nil
+	m.status = reviewStatus(m.currentChange, len(m.diffChanges))
+	return m
+}
+
+// reviewStatus returns the status line shown while reviewing changes
+func reviewStatus(current, total int) string {
+	return fmt.Sprintf("Reviewing changes (%d/%d) - Tab: Apply, Space: Skip, ←/→: Previous/Next, O: Alternatives, Esc: Exit", current+1, total)
+}
+
+// decisionLabel describes what was decided for a change, if anything
+func decisionLabel(change DiffChange) string {
+	switch {
+	case change.Applied:
+		return "applied"
+	case change.Skipped:
+		return "skipped"
+	}
+	return ""
+}
+
+// fetchAlternatives requests alternative phrasings for the change at changeIdx
+func (m Model) fetchAlternatives(changeIdx int) tea.Cmd {
+	sentence, span := m.diff().context(changeIdx)
+	return func() tea.Msg {
+		ctx, cancel := m.requestContext(m.correctionTimeout(sentence))
+		defer cancel()
+
+		alternatives, err := m.corrector.SuggestAlternatives(ctx, sentence, span)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to fetch alternatives: %w", err)}
+		}
+		return alternativesMsg{changeIndex: changeIdx, alternatives: alternatives}
+	}
+}
+
 func (m Model) pasteAndCorrect() tea.Cmd {
 	return func() tea.Msg {
-		text, err := clipboard.Paste()
+		_, span := tracing.Start(context.Background(), "clipboard.paste")
+		text, err := m.clipboard.Paste()
+		tracing.End(span, err)
 		if err != nil {
 			return errMsg{err: fmt.Errorf("failed to read clipboard: %w", err)}
 		}
 
+		// Clipboards hold whatever was copied last, from anywhere
+		text, report := sanitize.Clean(text)
 		// Trim trailing whitespace before processing
 		text = trimTrailingWhitespace(text)
 		if text == "" {
 			return errMsg{err: fmt.Errorf("clipboard is empty or contains only whitespace")}
 		}
+		if report.String() != "" {
+			return sanitizedMsg{text: text, report: report}
+		}
+		return m.newText(text)
+	}
+}
 
-		// Check cache first
-		if m.cache != nil {
-			hash := m.cache.Hash(text)
-			if cached := m.cache.Get(hash); cached != "" {
-				// Cache hit - return immediately with both original and corrected
-				trimmedCached := trimTrailingWhitespace(cached)
-				return correctionDoneMsg{
-					original:  text,
-					corrected: trimmedCached,
-				}
+// newText returns the message that starts correcting a new text: its correction when it is in
+// the cache, or the text to show while it is corrected
+func (m Model) newText(text string) tea.Msg {
+	// Check cache first; its key depends on the corrector
+	if m.cache != nil && m.corrector != nil {
+		// Look up the correction in the language the text will be corrected in
+		hash := m.applySourceLanguage(text).correctionHash(text)
+		_, span := tracing.Start(context.Background(), "cache.lookup")
+		cached := m.cache.Get(hash)
+		span.SetAttributes(attribute.Bool("cache.hit", cached != ""))
+		span.End()
+		if cached != "" {
+			// Cache hit - return immediately with both original and corrected
+			trimmedCached := trimTrailingWhitespace(cached)
+			return correctionDoneMsg{
+				original:  text,
+				corrected: trimmedCached,
+				cached:    true,
 			}
 		}
-
-		// No cache - show original immediately, then start correction
-		return textPastedMsg{text: text}
 	}
+
+	// No cache - show original immediately, then start correction
+	return textPastedMsg{text: text}
 }
 
 func (m Model) streamCorrection(text string) tea.Cmd {
+	if corrector.NeedsChunking(text) {
+		return m.correctInChunks(text)
+	}
+
+	stream := &streamBuffer{}
 	return tea.Batch(
 		func() tea.Msg {
 			return statusMsg("[●] Correcting...")
 		},
+		tickStream(streamTickMsg{buffer: stream, source: text}),
 		func() tea.Msg {
-			ctx, cancel := createTimeoutContext(m.config)
+			ctx, cancel := m.requestContext(m.correctionTimeout(text))
 			defer cancel()
 
-			corrected := ""
-			err := m.corrector.StreamCorrect(ctx, text, func(chunk string) {
-				corrected += chunk
-			})
-
+			err := m.corrector.StreamCorrect(ctx, text, stream.write)
 			if err != nil {
-				return errMsg{err: err}
+				return errMsg{err: err, failed: failedRequest{op: opCorrection, text: text}}
 			}
 
 			// Trim trailing whitespace from corrected text
-			trimmedCorrected := trimTrailingWhitespace(corrected)
+			trimmedCorrected := trimTrailingWhitespace(stream.String())
 
 			// Save to cache (handle errors gracefully - don't fail correction if cache fails)
 			m.saveToCache(text, trimmedCorrected)
@@ -841,13 +1516,17 @@ func (m Model) streamCorrection(text string) tea.Cmd {
 }
 
 func (m Model) correctText(text string) tea.Cmd {
+	if corrector.NeedsChunking(text) {
+		return m.correctInChunks(text)
+	}
+
 	return func() tea.Msg {
-		ctx, cancel := createTimeoutContext(m.config)
+		ctx, cancel := m.requestContext(m.correctionTimeout(text))
 		defer cancel()
 
 		corrected, err := m.corrector.Correct(ctx, text)
 		if err != nil {
-			return errMsg{err: err}
+			return errMsg{err: err, failed: failedRequest{op: opCorrection, text: text}}
 		}
 
 		// Trim trailing whitespace from corrected text
@@ -863,35 +1542,93 @@ func (m Model) correctText(text string) tea.Cmd {
 	}
 }
 
+// correctInChunks corrects a text that is too long for a single request by splitting it on
+// paragraph boundaries and correcting chunk_concurrency chunks at a time
+func (m Model) correctInChunks(text string) tea.Cmd {
+	return func() tea.Msg {
+		chunks := corrector.SplitChunks(text, corrector.MaxChunkLength)
+		// Room for every message, so the correction never waits for the UI
+		updates := make(chan tea.Msg, len(chunks)+1)
+		ctx, cancel := context.WithCancel(m.rateLimitContext(context.Background()))
+		progress := chunkProgressMsg{original: text, total: len(chunks), updates: updates, cancel: cancel}
+
+		go func() {
+			defer cancel()
+			// Each chunk gets the time of the longest one
+			timeout := m.config.CorrectionTimeout()
+			for _, chunk := range chunks {
+				timeout = max(timeout, m.correctionTimeout(chunk.Text))
+			}
+			corrected, err := m.corrector.CorrectChunks(ctx, chunks, m.config.ChunkConcurrency, timeout, func(done int) {
+				progress := progress
+				progress.done = done
+				updates <- progress
+			})
+			if err != nil {
+				updates <- errMsg{err: err, failed: failedRequest{op: opCorrection, text: text}}
+				return
+			}
+			trimmedCorrected := trimTrailingWhitespace(corrector.JoinChunks(chunks, corrected))
+			m.saveToCache(text, trimmedCorrected)
+			updates <- correctionDoneMsg{
+				original:  text,
+				corrected: trimmedCorrected,
+			}
+		}()
+		// The first progress message shows the progress bar
+		return progress
+	}
+}
+
+// waitForChunks waits for the next message of a chunked correction
+func waitForChunks(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
 func (m Model) streamTranslation(text string) tea.Cmd {
+	stream := &streamBuffer{}
 	return tea.Batch(
 		func() tea.Msg {
 			return statusMsg("[●] Translating...")
 		},
 		func() tea.Msg {
-			ctx, cancel := createTimeoutContext(m.config)
-			defer cancel()
+			// Check cache first
+			if cached := m.cachedTranslation(text); cached != "" {
+				return translationDoneMsg{
+					source:     text,
+					translated: trimTrailingWhitespace(cached),
+				}
+			}
 
-			translated := ""
-			err := m.translator.StreamTranslate(ctx, text, func(chunk string) {
-				translated += chunk
-			})
+			ctx, cancel := m.requestContext(m.translationTimeout(text))
+			defer cancel()
 
+			err := m.translator.StreamTranslate(ctx, text, stream.write)
 			if err != nil {
-				return errMsg{err: err}
+				return errMsg{err: err, failed: failedRequest{op: opTranslation, text: text}}
 			}
 
 			// Trim trailing whitespace from translated text
-			trimmedTranslated := trimTrailingWhitespace(translated)
+			trimmedTranslated := trimTrailingWhitespace(stream.String())
+			m.saveTranslationToCache(text, trimmedTranslated)
 
 			return translationDoneMsg{
+				source:     text,
 				translated: trimmedTranslated,
 			}
 		},
+		tickStream(streamTickMsg{buffer: stream, source: text, translation: true}),
 	)
 }
 
+// View renders the model in the theme
 func (m Model) View() string {
+	return m.theme.output(m.view())
+}
+
+func (m Model) view() string {
 	if m.mode == ModeHelp {
 		return m.renderHelp()
 	}
@@ -900,6 +1637,22 @@ func (m Model) View() string {
 		return m.renderReviewMode()
 	}
 
+	if m.mode == ModeToneMenu {
+		return m.renderToneMenu()
+	}
+
+	if m.mode == ModeModelPicker {
+		return m.renderModelPicker()
+	}
+
+	if m.mode == ModeConsistency {
+		return m.renderConsistencyReport()
+	}
+
+	if m.mode == ModeHistory {
+		return m.renderHistory()
+	}
+
 	if m.mode == ModeEditOriginal || m.mode == ModeEditCorrected || m.mode == ModeEditTranslation {
 		return m.renderEditMode()
 	}
@@ -917,11 +1670,11 @@ func (m Model) View() string {
 	// Header
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("6")).
+		Foreground(m.theme.Header).
 		Padding(0, 1)
 
 	statusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(m.theme.Status).
 		Padding(0, 1)
 
 	// Render style indicator with visual styling
@@ -929,7 +1682,7 @@ func (m Model) View() string {
 
 	headerLoadingIndicator := ""
 	if m.isLoading {
-		headerLoadingIndicator = "[●] Correcting..."
+		headerLoadingIndicator = m.correctionProgress()
 	}
 
 	// Build header components
@@ -938,14 +1691,29 @@ func (m Model) View() string {
 		headerLeft += " " + headerLoadingIndicator
 	}
 
-	status := statusStyle.Render(m.status)
+	statusText := m.loadingStatus(m.status)
+	if m.estimate != "" && m.mode != ModeConfirmSend {
+		statusText += " · " + m.estimate
+	}
+	if m.mode == ModeTranslationLanguage {
+		statusText = m.languageInput.View() + "  (Enter: Translate, Esc: Cancel)"
+	}
+	if m.mode == ModeSearch {
+		statusText = m.searchInput.View()
+	}
+	status := statusStyle.Render(statusText)
 	if m.error != "" {
 		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("9")).
+			Foreground(m.theme.Error).
 			Bold(true).
 			Padding(0, 1)
-		status = errorStyle.Render("✗ " + m.error)
+		text := "✗ " + m.error
+		if m.failed.op != opNone {
+			text += retryHint
+		}
+		status = errorStyle.Render(text)
 	}
+	status += m.renderToasts()
 
 	// Check if header fits on one line
 	headerWidth := lipgloss.Width(headerLeft)
@@ -965,201 +1733,96 @@ func (m Model) View() string {
 	s.WriteString("\n\n")
 
 	// Original text
-	originalLabel := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("4")).
-		Render("Original Text")
+	if m.showsPane(paneOriginal) {
+		originalLabel := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(m.theme.Original).
+			Render("Original Text")
 
-	s.WriteString(originalLabel)
-	s.WriteString("\n")
-	// Render box (edit mode is handled by renderEditMode())
-	boxWidth := m.width - 4
-	if boxWidth < 20 {
-		boxWidth = 20
-	}
-	// Account for: header (1-2 lines), separator (1), spacing (1), labels (3 if translation enabled, else 2), spacing between boxes (2 if translation, else 1), separator (1), footer (1-2)
-	// Total: ~12-14 lines for fixed content with translation, ~9-11 without
-	hasTranslation := m.translator != nil
-	fixedLines := 11
-	if hasTranslation {
-		fixedLines = 14
-	}
-	availableHeight := m.height - fixedLines
-	if availableHeight < 10 {
-		availableHeight = m.height - (fixedLines - 2) // Minimum space for very small terminals
-	}
-	numBoxes := 2
-	if hasTranslation {
-		numBoxes = 3
-	}
-	boxHeight := availableHeight / numBoxes
-	if boxHeight < 3 {
-		boxHeight = 3 // Minimum box height
-	}
-	// Wrap text to fit within box width (accounting for padding)
-	contentWidth := boxWidth - 4 // Account for padding (2 on each side)
-	if contentWidth < 1 {
-		contentWidth = 1
+		// Render box (edit mode is handled by renderEditMode())
+		originalBox, originalScroll := m.renderPane(paneOriginal)
+		s.WriteString(originalLabel + m.charCountLabel(m.originalText) + m.redactionLabel(m.originalText) + originalScroll)
+		s.WriteString("\n")
+		s.WriteString(originalBox)
+		s.WriteString("\n\n")
 	}
-	wrappedText := wrapText(m.originalText, contentWidth)
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(1, 2).
-		Width(boxWidth).
-		Height(boxHeight)
-	s.WriteString(boxStyle.Render(wrappedText))
-	s.WriteString("\n\n")
 
 	// Corrected text
-	correctedLabelStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("2"))
-
-	loadingIndicator := ""
-	if m.isLoading {
-		loadingIndicator = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11")).
-			Render(" [●] Correcting...")
-	}
-
-	correctedLabel := correctedLabelStyle.Render("Corrected Text") + loadingIndicator
-
-	s.WriteString(correctedLabel)
-	s.WriteString("\n")
-	// Render box (edit mode is handled by renderEditMode())
-	boxWidth = m.width - 4
-	if boxWidth < 20 {
-		boxWidth = 20
-	}
-	hasTranslation = m.translator != nil
-	fixedLines = 11
-	if hasTranslation {
-		fixedLines = 14
-	}
-	availableHeight = m.height - fixedLines
-	if availableHeight < 10 {
-		availableHeight = m.height - (fixedLines - 2) // Minimum space for very small terminals
-	}
-	numBoxes = 2
-	if hasTranslation {
-		numBoxes = 3
-	}
-	boxHeight = availableHeight / numBoxes
-	if boxHeight < 3 {
-		boxHeight = 3 // Minimum box height
-	}
-	content := m.correctedText
+	if m.showsPane(paneCorrected) {
+		correctedLabelStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(m.theme.Corrected)
 
-	// Show loading indicator in the box if loading
-	if m.isLoading && content == "" {
-		loadingText := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11")).
-			Italic(true).
-			Render("Correcting...")
-		content = loadingText
-	} else if m.showDiff && m.originalText != "" && m.correctedText != "" && m.mode != ModeReviewDiff {
-		// Only show diff view when not in review mode (review mode has its own display)
-		content = renderDiff(m.originalText, m.correctedText)
-	} else {
-		// Wrap text to fit within box width (accounting for padding)
-		contentWidth := boxWidth - 4 // Account for padding (2 on each side)
-		if contentWidth < 1 {
-			contentWidth = 1
+		loadingIndicator := ""
+		if m.isLoading {
+			loadingIndicator = lipgloss.NewStyle().
+				Foreground(m.theme.Highlight).
+				Render(" " + m.spinner.View() + " Correcting...")
 		}
-		content = wrapText(content, contentWidth)
-	}
 
-	boxStyle = lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(1, 2).
-		Width(boxWidth).
-		Height(boxHeight)
+		correctedLabelText := "Corrected Text"
+		if m.rewriteLabel != "" {
+			correctedLabelText = fmt.Sprintf("Rewritten Text (%s)", m.rewriteLabel)
+		}
+		correctedLabel := correctedLabelStyle.Render(correctedLabelText) + loadingIndicator
+		if !m.isLoading {
+			correctedLabel += m.charCountLabel(m.correctedText) + m.changeBadge()
+		}
+		violations := m.glossary.Check(m.correctedText)
+		if len(violations) > 0 && !m.isLoading {
+			correctedLabel += lipgloss.NewStyle().
+				Foreground(m.theme.Highlight).
+				Render(" ⚠ " + glossarySummary(violations))
+		}
+		if conflicts := m.activeConflicts(); len(conflicts) > 0 && !m.isLoading {
+			correctedLabel += lipgloss.NewStyle().
+				Foreground(m.theme.Highlight).
+				Render(" ⚠ " + conflictSummary(conflicts))
+		}
 
-	s.WriteString(boxStyle.Render(content))
-	s.WriteString("\n\n")
+		// Render box (edit mode is handled by renderEditMode())
+		correctedBox, correctedScroll := m.renderPane(paneCorrected)
+		s.WriteString(correctedLabel + correctedScroll)
+		s.WriteString("\n")
+		s.WriteString(correctedBox)
+		s.WriteString("\n\n")
+	}
 
 	// Translation text (only show if translator is configured)
-	if m.translator != nil {
+	if m.showsPane(paneTranslation) {
 		translationLabelStyle := lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("5"))
+			Foreground(m.theme.Translation)
 
 		translationLoadingIndicator := ""
 		if m.isTranslating {
 			translationLoadingIndicator = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("11")).
-				Render(" [●] Translating...")
+				Foreground(m.theme.Highlight).
+				Render(" " + m.spinner.View() + " Translating...")
 		}
 
-		translationLabel := translationLabelStyle.Render("Translation") + translationLoadingIndicator
+		translationLabel := translationLabelStyle.Render(m.translationLabel()) + translationLoadingIndicator
 
-		s.WriteString(translationLabel)
-		s.WriteString("\n")
 		// Render box (edit mode is handled by renderEditMode())
-		boxWidth = m.width - 4
-		if boxWidth < 20 {
-			boxWidth = 20
-		}
-		hasTranslation = m.translator != nil
-		fixedLines = 11
-		if hasTranslation {
-			fixedLines = 14
-		}
-		availableHeight = m.height - fixedLines
-		if availableHeight < 10 {
-			availableHeight = m.height - (fixedLines - 2) // Minimum space for very small terminals
-		}
-		numBoxes = 2
-		if hasTranslation {
-			numBoxes = 3
-		}
-		boxHeight = availableHeight / numBoxes
-		if boxHeight < 3 {
-			boxHeight = 3 // Minimum box height
-		}
-		boxStyle := lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("8")).
-			Padding(1, 2).
-			Width(boxWidth).
-			Height(boxHeight)
-
-		translationContent := m.translatedText
-
-		// Show loading indicator in the box if translating
-		if m.isTranslating && translationContent == "" {
-			loadingText := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("11")).
-				Italic(true).
-				Render("Translating...")
-			translationContent = loadingText
-		} else {
-			// Wrap text to fit within box width (accounting for padding)
-			contentWidth := boxWidth - 4 // Account for padding (2 on each side)
-			if contentWidth < 1 {
-				contentWidth = 1
-			}
-			translationContent = wrapText(translationContent, contentWidth)
-		}
-
-		s.WriteString(boxStyle.Render(translationContent))
+		translationBox, translationScroll := m.renderPane(paneTranslation)
+		s.WriteString(translationLabel + translationScroll)
+		s.WriteString("\n")
+		s.WriteString(translationBox)
 		s.WriteString("\n\n")
 	}
 
 	// Footer
 	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(m.theme.Muted).
 		Padding(0, 1)
 
 	// Create style shortcuts with visual indication (compact version)
 	styleShortcuts := m.renderStyleShortcuts()
 
 	// Build footer with style shortcuts - always compact
-	mainFooterText := "V: Paste  C: Copy  E: Edit  R: Retry  D: Diff  A: Review  Q: Quit  ?: Help"
+	mainFooterText := "V: Paste  C: Copy  E: Edit  R: Retry  D: Diff  A: Review  W: Rewrite  Q: Quit  ?: Help"
 	if m.translator != nil {
-		mainFooterText = "V: Paste  C: Copy  T: Copy Translation  E: Edit  R: Retry  D: Diff  A: Review  Q: Quit  ?: Help"
+		mainFooterText = "V: Paste  C: Copy  T: Copy Translation  E: Edit  R: Retry  D: Diff  A: Review  W: Rewrite  Q: Quit  ?: Help"
 	}
 	mainFooter := footerStyle.Render(mainFooterText)
 	styleShortcutsWidth := lipgloss.Width(styleShortcuts)
@@ -1179,6 +1842,13 @@ func (m Model) View() string {
 		footer = mainFooter + separator + styleShortcuts
 	}
 
+	// Counts of the focused pane
+	focused := m.focusedPane
+	if focused == paneTranslation && m.translator == nil {
+		focused = paneOriginal
+	}
+	s.WriteString(m.statsLine(focused, m.paneText(focused)))
+	s.WriteString("\n")
 	s.WriteString(strings.Repeat("─", m.width))
 	s.WriteString("\n")
 	s.WriteString(footer)
@@ -1200,18 +1870,18 @@ func (m Model) renderReviewMode() string {
 	// Header
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("6")).
+		Foreground(m.theme.Header).
 		Padding(0, 1)
 
 	statusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(m.theme.Status).
 		Padding(0, 1)
 
 	// Render style indicator with visual styling
 	styleIndicator := m.renderStyleIndicator()
 
 	headerLeft := headerStyle.Render("grammr - Review Changes") + " " + styleIndicator
-	status := statusStyle.Render(m.status)
+	status := statusStyle.Render(m.loadingStatus(m.status)) + m.renderToasts()
 
 	// Check if header fits on one line
 	headerWidth := lipgloss.Width(headerLeft)
@@ -1230,14 +1900,29 @@ func (m Model) renderReviewMode() string {
 	s.WriteString(strings.Repeat("─", m.width))
 	s.WriteString("\n\n")
 
+	s.WriteString(lipgloss.NewStyle().
+		Foreground(m.theme.Muted).
+		Render(changeSummary(m.diffChanges)))
+	s.WriteString("\n\n")
+
 	// Show current change being reviewed
 	if m.currentChange < len(m.diffChanges) {
 		change := m.diffChanges[m.currentChange]
 
 		changeLabel := lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("11")).
+			Foreground(m.theme.Highlight).
 			Render(fmt.Sprintf("Change %d of %d", m.currentChange+1, len(m.diffChanges)))
+		if change.Category != "" {
+			changeLabel += lipgloss.NewStyle().
+				Foreground(m.theme.Highlight).
+				Render(" · " + styleLabel(change.Category))
+		}
+		if decision := decisionLabel(change); decision != "" {
+			changeLabel += lipgloss.NewStyle().
+				Foreground(m.theme.Muted).
+				Render(fmt.Sprintf(" (%s)", decision))
+		}
 
 		s.WriteString(changeLabel)
 		s.WriteString("\n\n")
@@ -1246,12 +1931,12 @@ func (m Model) renderReviewMode() string {
 		if boxWidth < 20 {
 			boxWidth = 20
 		}
-		// Account for: header (1-2 lines), separator (1), spacing (1), change label (1), spacing (1),
-		// preview label (1), spacing (1), separator (1), footer (1-2)
-		// Total: ~9-11 lines for fixed content
-		availableHeight := m.height - 11
+		// Account for: header (1-2 lines), separator (1), spacing (1), summary (2), change label (1),
+		// spacing (1), preview label (1), spacing (1), separator (1), footer (1-2)
+		// Total: ~11-13 lines for fixed content
+		availableHeight := m.height - 13
 		if availableHeight < 10 {
-			availableHeight = m.height - 9 // Minimum space for very small terminals
+			availableHeight = m.height - 11 // Minimum space for very small terminals
 		}
 		boxHeight := availableHeight / 2
 		if boxHeight < 3 {
@@ -1259,54 +1944,56 @@ func (m Model) renderReviewMode() string {
 		}
 
 		// Show what's being changed
-		if strings.Contains(change.Text, " → ") {
+		if change.Removed != "" && change.Added != "" {
 			// Paired change (delete → insert)
-			parts := strings.SplitN(change.Text, " → ", 2)
-			deletePart := parts[0]
-			insertPart := parts[1]
-
 			deleteStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("9")).
+				Foreground(m.theme.Removed).
 				Strikethrough(true).
 				Bold(true)
 			insertStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("10")).
+				Foreground(m.theme.Added).
 				Bold(true)
 
 			changeText := fmt.Sprintf("Change: %s → %s",
-				deleteStyle.Render(deletePart),
-				insertStyle.Render(insertPart))
+				deleteStyle.Render(change.Removed),
+				insertStyle.Render(change.Added))
+			changeText += m.renderAlternatives()
+			changeText += m.renderExplanation()
 
 			boxStyle := lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("11")).
+				Border(m.theme.border()).
+				BorderForeground(m.theme.Highlight).
 				Padding(1, 2).
 				Width(boxWidth).
 				Height(boxHeight)
 			s.WriteString(boxStyle.Render(changeText))
 		} else if change.Type == diffmatchpatch.DiffDelete {
 			deleteStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("9")).
+				Foreground(m.theme.Removed).
 				Strikethrough(true).
 				Bold(true)
-			changeText := deleteStyle.Render(fmt.Sprintf("Remove: %q", change.Text))
+			changeText := deleteStyle.Render(fmt.Sprintf("Remove: %q", change.Removed))
+			changeText += m.renderAlternatives()
+			changeText += m.renderExplanation()
 
 			boxStyle := lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("9")).
+				Border(m.theme.border()).
+				BorderForeground(m.theme.Removed).
 				Padding(1, 2).
 				Width(boxWidth).
 				Height(boxHeight)
 			s.WriteString(boxStyle.Render(changeText))
 		} else if change.Type == diffmatchpatch.DiffInsert {
 			insertStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("10")).
+				Foreground(m.theme.Added).
 				Bold(true)
-			changeText := insertStyle.Render(fmt.Sprintf("Add: %q", change.Text))
+			changeText := insertStyle.Render(fmt.Sprintf("Add: %q", change.Added))
+			changeText += m.renderAlternatives()
+			changeText += m.renderExplanation()
 
 			boxStyle := lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("10")).
+				Border(m.theme.border()).
+				BorderForeground(m.theme.Added).
 				Padding(1, 2).
 				Width(boxWidth).
 				Height(boxHeight)
@@ -1318,26 +2005,26 @@ func (m Model) renderReviewMode() string {
 		// Show preview of reviewed text so far
 		previewLabel := lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("8")).
+			Foreground(m.theme.Muted).
 			Render("Preview:")
 
 		s.WriteString(previewLabel)
 		s.WriteString("\n")
 
 		previewBoxStyle := lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("8")).
+			Border(m.theme.border()).
+			BorderForeground(m.theme.Border).
 			Padding(1, 2).
 			Width(boxWidth).
 			Height(boxHeight)
 
 		// Show the reviewed text with highlighting for current change
-		previewText := m.renderReviewPreview()
+		previewText := wrapStyled(m.renderReviewPreview(), boxWidth-previewBoxStyle.GetHorizontalPadding())
 		s.WriteString(previewBoxStyle.Render(previewText))
 	} else {
 		// All changes reviewed
 		doneStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("2")).
+			Foreground(m.theme.Corrected).
 			Bold(true)
 		s.WriteString(doneStyle.Render("✓ All changes reviewed!"))
 	}
@@ -1346,10 +2033,10 @@ func (m Model) renderReviewMode() string {
 
 	// Footer
 	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(m.theme.Muted).
 		Padding(0, 1)
 
-	footer := footerStyle.Render("Tab: Apply  Space: Skip  Esc: Exit")
+	footer := footerStyle.Render("Tab: Apply  Space: Skip  ←/→: Previous/Next  A/S: Apply/Skip All  O: Alternatives  W: Why  Esc: Exit")
 	s.WriteString(strings.Repeat("─", m.width))
 	s.WriteString("\n")
 	s.WriteString(footer)
@@ -1371,11 +2058,11 @@ func (m Model) renderEditMode() string {
 	// Header
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("6")).
+		Foreground(m.theme.Header).
 		Padding(0, 1)
 
 	statusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(m.theme.Status).
 		Padding(0, 1)
 
 	// Render style indicator with visual styling
@@ -1384,27 +2071,35 @@ func (m Model) renderEditMode() string {
 	var headerTitle string
 	var editor *textarea.Model
 	var labelText string
-	var labelColor string
+	var labelColor lipgloss.Color
 
-	if m.mode == ModeEditOriginal {
+	editedPane := paneOriginal
+	if m.composing {
+		headerTitle = "grammr - Compose"
+		editor = &m.originalEditor
+		labelText = "Original Text"
+		labelColor = m.theme.Original
+	} else if m.mode == ModeEditOriginal {
 		headerTitle = "grammr - Edit Original Text"
 		editor = &m.originalEditor
 		labelText = "Original Text"
-		labelColor = "4"
+		labelColor = m.theme.Original
 	} else if m.mode == ModeEditCorrected {
 		headerTitle = "grammr - Edit Corrected Text"
 		editor = &m.correctedEditor
+		editedPane = paneCorrected
 		labelText = "Corrected Text"
-		labelColor = "2"
+		labelColor = m.theme.Corrected
 	} else if m.mode == ModeEditTranslation {
 		headerTitle = "grammr - Edit Translation"
 		editor = &m.translationEditor
+		editedPane = paneTranslation
 		labelText = "Translation"
-		labelColor = "5"
+		labelColor = m.theme.Translation
 	}
 
 	headerLeft := headerStyle.Render(headerTitle) + " " + styleIndicator
-	status := statusStyle.Render(m.status)
+	status := statusStyle.Render(m.loadingStatus(m.status)) + m.renderToasts()
 
 	// Check if header fits on one line
 	headerWidth := lipgloss.Width(headerLeft)
@@ -1426,9 +2121,9 @@ func (m Model) renderEditMode() string {
 	// Label
 	labelStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color(labelColor))
+		Foreground(labelColor)
 
-	s.WriteString(labelStyle.Render(labelText))
+	s.WriteString(labelStyle.Render(labelText) + m.charCountLabel(editor.Value()))
 	s.WriteString("\n\n")
 
 	// Editor - fill most of the screen
@@ -1436,11 +2131,11 @@ func (m Model) renderEditMode() string {
 	if editorWidth < 20 {
 		editorWidth = 20
 	}
-	// Account for: header (1-2 lines), separator (1), spacing (1), label (1), spacing (1), separator (1), footer (1)
-	// Total: ~6-7 lines for fixed content
-	availableHeight := m.height - 7
+	// Account for: header (1-2 lines), separator (1), spacing (1), label (1), spacing (1), stats (1), separator (1), footer (1)
+	// Total: ~7-8 lines for fixed content
+	availableHeight := m.height - 8
 	if availableHeight < 10 {
-		availableHeight = m.height - 5 // Minimum space for very small terminals
+		availableHeight = m.height - 6 // Minimum space for very small terminals
 	}
 	editorHeight := availableHeight
 	if editorHeight < 5 {
@@ -1453,13 +2148,23 @@ func (m Model) renderEditMode() string {
 	s.WriteString(editor.View())
 
 	s.WriteString("\n\n")
+	// Live counts of the text being edited
+	s.WriteString(m.statsLine(editedPane, editor.Value()))
+	s.WriteString("\n")
 
 	// Footer
 	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(m.theme.Muted).
 		Padding(0, 1)
 
-	footer := footerStyle.Render("Esc: Exit  Ctrl+S: Save and re-correct (original only)")
+	footerText := "Esc: Exit  Ctrl+S: Save and re-correct (original only)"
+	if m.mode != ModeEditTranslation {
+		footerText += "  Ctrl+Space, Ctrl+G: Correct a selection"
+	}
+	if m.composing {
+		footerText = "Ctrl+Enter or Ctrl+S: Correct  Esc: Cancel"
+	}
+	footer := footerStyle.Render(footerText)
 	s.WriteString(strings.Repeat("─", m.width))
 	s.WriteString("\n")
 	s.WriteString(footer)
@@ -1468,140 +2173,99 @@ func (m Model) renderEditMode() string {
 }
 
 func (m Model) renderReviewPreview() string {
-	// Build a preview showing the current state with the current change highlighted
-	// Use the reviewed text which already has decisions applied
-	previewText := m.reviewedText
+	// Without a current change, the reviewed text already has every decision applied
+	if m.currentChange >= len(m.diffChanges) {
+		return m.reviewedText
+	}
 
-	// If we have a current change, highlight it in the preview
-	if m.currentChange < len(m.diffChanges) {
-		// Find and highlight the current change in the text
-		dmp := diffmatchpatch.New()
-		diffs := dmp.DiffMain(m.originalText, m.correctedText, false)
-		diffs = dmp.DiffCleanupSemantic(diffs)
-
-		var result strings.Builder
-		changeIdx := 0
-
-		for i := 0; i < len(diffs); i++ {
-			diff := diffs[i]
-
-			if diff.Type == diffmatchpatch.DiffEqual {
-				result.WriteString(
-					lipgloss.NewStyle().
-						Foreground(lipgloss.Color("8")).
-						Render(diff.Text),
-				)
-			} else if diff.Type == diffmatchpatch.DiffDelete {
-				// Check if paired with insert
-				if i+1 < len(diffs) && diffs[i+1].Type == diffmatchpatch.DiffInsert {
-					// Paired change
-					if changeIdx == m.currentChange {
-						// Highlight current change
-						result.WriteString(
-							lipgloss.NewStyle().
-								Foreground(lipgloss.Color("11")).
-								Background(lipgloss.Color("9")).
-								Bold(true).
-								Strikethrough(true).
-								Render(diff.Text),
-						)
-						result.WriteString(
-							lipgloss.NewStyle().
-								Foreground(lipgloss.Color("11")).
-								Background(lipgloss.Color("10")).
-								Bold(true).
-								Render(diffs[i+1].Text),
-						)
-					} else if changeIdx < len(m.diffChanges) {
-						change := m.diffChanges[changeIdx]
-						if change.Applied {
-							result.WriteString(
-								lipgloss.NewStyle().
-									Foreground(lipgloss.Color("10")).
-									Render(diffs[i+1].Text),
-							)
-						} else if change.Skipped {
-							result.WriteString(
-								lipgloss.NewStyle().
-									Foreground(lipgloss.Color("8")).
-									Render(diff.Text),
-							)
-						} else {
-							// Not reviewed yet
-							result.WriteString(
-								lipgloss.NewStyle().
-									Foreground(lipgloss.Color("9")).
-									Strikethrough(true).
-									Render(diff.Text),
-							)
-							result.WriteString(
-								lipgloss.NewStyle().
-									Foreground(lipgloss.Color("10")).
-									Render(diffs[i+1].Text),
-							)
-						}
-					}
-					changeIdx++
-					i++ // Skip insert
-				} else {
-					// Single delete
-					if changeIdx == m.currentChange {
-						result.WriteString(
-							lipgloss.NewStyle().
-								Foreground(lipgloss.Color("11")).
-								Background(lipgloss.Color("9")).
-								Bold(true).
-								Strikethrough(true).
-								Render(diff.Text),
-						)
-					} else if changeIdx < len(m.diffChanges) {
-						change := m.diffChanges[changeIdx]
-						if change.Skipped {
-							result.WriteString(
-								lipgloss.NewStyle().
-									Foreground(lipgloss.Color("8")).
-									Render(diff.Text),
-							)
-						} else if !change.Applied {
-							result.WriteString(
-								lipgloss.NewStyle().
-									Foreground(lipgloss.Color("9")).
-									Strikethrough(true).
-									Render(diff.Text),
-							)
-						}
-					}
-					changeIdx++
-				}
-			} else if diff.Type == diffmatchpatch.DiffInsert {
-				// Single insert (shouldn't happen if paired correctly, but handle it)
-				if changeIdx == m.currentChange {
-					result.WriteString(
-						lipgloss.NewStyle().
-							Foreground(lipgloss.Color("11")).
-							Background(lipgloss.Color("10")).
-							Bold(true).
-							Render(diff.Text),
-					)
-				} else if changeIdx < len(m.diffChanges) {
-					change := m.diffChanges[changeIdx]
-					if change.Applied {
-						result.WriteString(
-							lipgloss.NewStyle().
-								Foreground(lipgloss.Color("10")).
-								Render(diff.Text),
-						)
-					}
-				}
-				changeIdx++
+	removedOpen, removedClose := m.theme.removedMarkers()
+	addedOpen, addedClose := m.theme.addedMarkers()
+	removedStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Removed).
+		Strikethrough(true)
+	addedStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Added)
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Muted)
+
+	var result strings.Builder
+	write := func(style lipgloss.Style, text string) {
+		if text != "" {
+			result.WriteString(style.Render(text))
+		}
+	}
+
+	d := m.diff()
+	next := 0 // The first segment not written yet
+	for i, h := range d.hunks {
+		for _, segment := range d.segments[next:h.first] {
+			write(mutedStyle, segment.Text)
+		}
+		next = h.last + 1
+
+		if i == m.currentChange {
+			// Highlight the change being reviewed
+			if h.removed != "" {
+				write(removedStyle.
+					Foreground(m.theme.Highlight).
+					Background(m.theme.Removed).
+					Bold(true), removedOpen+h.removed+removedClose)
 			}
+			if h.added != "" {
+				write(addedStyle.
+					Foreground(m.theme.Highlight).
+					Background(m.theme.Added).
+					Bold(true), addedOpen+h.added+addedClose)
+			}
+			continue
 		}
+		if i >= len(m.diffChanges) {
+			write(mutedStyle, h.removed)
+			continue
+		}
+		switch change := m.diffChanges[i]; {
+		case change.Applied:
+			write(addedStyle, change.appliedText(h.added))
+		case change.Skipped:
+			write(mutedStyle, h.removed)
+		default:
+			// Not reviewed yet
+			if h.removed != "" {
+				write(removedStyle, removedOpen+h.removed+removedClose)
+			}
+			if h.added != "" {
+				write(addedStyle, addedOpen+h.added+addedClose)
+			}
+		}
+	}
+	for _, segment := range d.segments[next:] {
+		write(mutedStyle, segment.Text)
+	}
+	return result.String()
+}
 
-		return result.String()
+// renderAlternatives lists the alternative phrasings offered for the current change
+func (m Model) renderAlternatives() string {
+	if m.isFetchingAlternatives {
+		return "\n\n" + lipgloss.NewStyle().
+			Foreground(m.theme.Highlight).
+			Italic(true).
+			Render("Fetching alternatives...")
+	}
+	if len(m.alternatives) == 0 {
+		return ""
 	}
 
-	// Fallback: just show the reviewed text
-	return previewText
+	var s strings.Builder
+	s.WriteString("\n\n")
+	s.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.Header).
+		Render("Alternatives:"))
+	for i, alternative := range m.alternatives {
+		s.WriteString(fmt.Sprintf("\n  %d. %s", i+1, alternative))
+	}
+	return s.String()
 }
 
 // renderStyleIndicator creates a visually styled badge for the current style
@@ -1622,13 +2286,11 @@ func (m Model) renderStyleIndicator() string {
 		label = "Technical"
 		color = "11" // Bright yellow
 	default:
-		// Capitalize first letter
-		if len(m.config.Style) > 0 {
-			label = strings.ToUpper(string(m.config.Style[0])) + strings.ToLower(m.config.Style[1:])
-		} else {
-			label = m.config.Style
-		}
+		label = styleLabel(m.config.Style)
 		color = "8" // Gray
+		if _, ok := m.config.CustomStylePrompts()[m.config.Style]; ok {
+			color = "14" // Bright cyan for user-defined styles
+		}
 	}
 
 	// Use a simple colored style with brackets
@@ -1636,60 +2298,109 @@ func (m Model) renderStyleIndicator() string {
 		Bold(true).
 		Foreground(lipgloss.Color(color))
 
-	return styleBadge.Render("[" + label + "]")
+	parts := []string{label}
+	if m.config.Dialect != "" {
+		parts = append(parts, strings.ToUpper(m.config.Dialect))
+	}
+	if m.config.Category != "" && m.config.Category != corrector.CategoryAll {
+		parts = append(parts, styleLabel(m.config.Category)+" only")
+	}
+	if language := m.languageLabel(); language != "" {
+		parts = append(parts, language)
+	}
+	if m.config.Offline {
+		parts = append(parts, "Offline")
+	}
+	return styleBadge.Render("[" + strings.Join(parts, " · ") + "]")
+}
+
+// styleOption describes a correction style selectable with a number key
+type styleOption struct {
+	key   string
+	name  string // Style name stored in config
+	label string // Display name
+	color string
 }
 
-// renderStyleShortcuts creates a visual indicator showing all styles with the active one highlighted
-func (m Model) renderStyleShortcuts() string {
-	styles := []struct {
-		key   string
-		name  string
-		color string
-	}{
-		{"1", "Casual", "10"},
-		{"2", "Formal", "12"},
-		{"3", "Academic", "13"},
-		{"4", "Technical", "11"},
+// maxStyleOptions is the number of styles reachable with the number keys 1-9
+const maxStyleOptions = 9
+
+// styleOptions returns the built-in styles followed by the user-defined ones, numbered from 1
+func styleOptions(cfg *config.Config) []styleOption {
+	options := []styleOption{
+		{"1", "casual", "Casual", "10"},
+		{"2", "formal", "Formal", "12"},
+		{"3", "academic", "Academic", "13"},
+		{"4", "technical", "Technical", "11"},
+	}
+	seen := map[string]bool{"casual": true, "formal": true, "academic": true, "technical": true}
+	for _, custom := range cfg.CustomStyles {
+		name := config.NormalizeStyleName(custom.Name)
+		if name == "" || strings.TrimSpace(custom.Prompt) == "" || seen[name] {
+			continue
+		}
+		if len(options) == maxStyleOptions {
+			break
+		}
+		seen[name] = true
+		options = append(options, styleOption{
+			key:   strconv.Itoa(len(options) + 1),
+			name:  name,
+			label: styleLabel(name),
+			color: "14", // Bright cyan
+		})
+	}
+	return options
+}
+
+// styleLabel turns a style name into a display label by capitalizing its first letter
+func styleLabel(name string) string {
+	if len(name) == 0 {
+		return name
 	}
+	return strings.ToUpper(string(name[0])) + strings.ToLower(name[1:])
+}
 
+// renderStyleShortcuts creates a visual indicator showing all styles with the active one highlighted
+func (m Model) renderStyleShortcuts() string {
 	var shortcuts []string
-	for _, s := range styles {
+	for _, s := range styleOptions(m.config) {
 		var shortcutStyle lipgloss.Style
-		if m.config.Style == strings.ToLower(s.name) {
+		if m.config.Style == s.name {
 			// Active style - highlighted with color and bold
 			shortcutStyle = lipgloss.NewStyle().
 				Bold(true).
 				Foreground(lipgloss.Color(s.color))
-			shortcuts = append(shortcuts, shortcutStyle.Render("["+s.key+": "+s.name+"]"))
+			shortcuts = append(shortcuts, shortcutStyle.Render("["+s.key+": "+s.label+"]"))
 		} else {
 			// Inactive style - subtle gray
 			shortcutStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("8"))
-			shortcuts = append(shortcuts, shortcutStyle.Render(s.key+": "+s.name))
+				Foreground(m.theme.Muted)
+			shortcuts = append(shortcuts, shortcutStyle.Render(s.key+": "+s.label))
 		}
 	}
 
 	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8"))
+		Foreground(m.theme.Muted)
 
 	return footerStyle.Render("Styles: " + strings.Join(shortcuts, " "))
 }
 
 func (m Model) renderHelp() string {
 	helpStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("6")).
+		Border(m.theme.border()).
+		BorderForeground(m.theme.Header).
 		Padding(1, 2).
 		Width(m.width - 4).
 		Height(m.height - 4)
 
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("6"))
+		Foreground(m.theme.Header)
 
 	sectionStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("6"))
+		Foreground(m.theme.Header)
 
 	// Build content line by line with proper alignment
 	var content strings.Builder
@@ -1700,19 +2411,61 @@ func (m Model) renderHelp() string {
 	content.WriteString(sectionStyle.Render("Global Mode:"))
 	content.WriteString("\n")
 	content.WriteString("  V, v      Paste from clipboard\n")
+	content.WriteString("  N, n      Type a new text (Ctrl+Enter to correct)\n")
 	content.WriteString("  C, c      Copy corrected text\n")
 	if m.translator != nil {
 		content.WriteString("  T, t      Copy translation\n")
+		content.WriteString("  B, b      Translate the original or the corrected text\n")
 	}
+	content.WriteString("  Ctrl+T    Turn translation on or off\n")
 	content.WriteString("  E, e      Edit corrected text\n")
 	content.WriteString("  O, o      Edit original text\n")
-	content.WriteString("  R, r      Retry correction\n")
+	content.WriteString("  R, r      Retry failed request, or correct again\n")
+	content.WriteString("  Ctrl+R    Correct again with a different phrasing\n")
+	content.WriteString("  J, j      Correct the corrected text again as the new original\n")
 	content.WriteString("  D, d      Toggle diff view\n")
+	content.WriteString("  K, k      Cycle diff mode (words, characters, whitespace)\n")
 	content.WriteString("  A, a      Review changes word-by-word\n")
+	content.WriteString("  W, w      Rewrite in another tone\n")
+	content.WriteString("  S, s      Shorten the text\n")
+	content.WriteString("  X, x      Expand with more detail\n")
+	content.WriteString("  P, p      Simplify to plain language\n")
+	content.WriteString("  L, l      Cycle English dialect (US, UK, AU, any)\n")
+	content.WriteString("  F, f      Cycle what to fix (all, spelling, punctuation, grammar)\n")
+	content.WriteString("  I, i      Check for inconsistently written terms\n")
+	content.WriteString("  G, g      Cycle source language (auto-detect or a fixed language)\n")
+	content.WriteString("  M, m      Switch the provider and model\n")
+	if m.history != nil {
+		content.WriteString("  H, h      Browse past corrections\n")
+	}
+	content.WriteString("  U, u      Export the changes as a patch\n")
+	content.WriteString("  Y, y      Copy as Markdown, HTML or next to the original\n")
+	content.WriteString("  Tab       Choose the pane to scroll\n")
+	content.WriteString("  Z, z      Zoom the chosen pane to full height\n")
+	content.WriteString("  PgUp/PgDn Scroll the pane (or use the mouse wheel)\n")
+	content.WriteString("  Ctrl+E    Open the focused pane in $EDITOR\n")
+	content.WriteString("  ,         Open the config file in $EDITOR\n")
+	content.WriteString("  Ctrl+Z    Undo the last paste, correction, rewrite or edit\n")
+	content.WriteString("  Ctrl+Y    Redo\n")
 	content.WriteString("  Q, q      Quit\n")
 	content.WriteString("  Ctrl+C    Force quit\n")
 	content.WriteString("  ?, F1     Show this help\n\n")
 
+	if m.config.Keybindings == config.KeybindingsVim {
+		content.WriteString(sectionStyle.Render("Vim Keys:"))
+		content.WriteString("\n")
+		content.WriteString("  j, k      Scroll the pane a line down or up\n")
+		content.WriteString("  Ctrl+D/U  Scroll half a page down or up\n")
+		content.WriteString("  g, G      Go to the top or bottom of the pane\n")
+		content.WriteString("  i         Edit the text of the pane\n")
+		content.WriteString("  /         Find text in the pane\n")
+		content.WriteString("  n, N      Next or previous match\n")
+		content.WriteString("  y, Y      Yank the corrected text or the translation\n")
+		content.WriteString("  p         Put the clipboard in as the original text\n")
+		content.WriteString("  Ctrl+G    Cycle source language\n")
+		content.WriteString("  Ctrl+N    Type a new text\n\n")
+	}
+
 	content.WriteString(sectionStyle.Render("Quick Actions:"))
 	content.WriteString("\n")
 	content.WriteString("  Ctrl+V    Paste & auto-correct\n")
@@ -1720,44 +2473,106 @@ func (m Model) renderHelp() string {
 
 	content.WriteString(sectionStyle.Render("Styles:"))
 	content.WriteString("\n")
-	content.WriteString("  1         Casual (default)\n")
-	content.WriteString("  2         Formal\n")
-	content.WriteString("  3         Academic\n")
-	content.WriteString("  4         Technical\n\n")
+	for _, option := range styleOptions(m.config) {
+		label := option.label
+		if option.name == "casual" {
+			label += " (default)"
+		}
+		content.WriteString(fmt.Sprintf("  %-9s %s\n", option.key, label))
+	}
+	content.WriteString("\n")
 
 	content.WriteString(sectionStyle.Render("Edit Mode:"))
 	content.WriteString("\n")
 	content.WriteString("  Esc       Exit edit mode\n")
-	content.WriteString("  Ctrl+S    Save and re-correct (original only)\n\n")
+	content.WriteString("  Ctrl+S    Save and re-correct (original only)\n")
+	content.WriteString("  Ctrl+Spc  Start or cancel a selection at the cursor\n")
+	content.WriteString("  Ctrl+G    Correct only the selected text\n\n")
 
 	content.WriteString(sectionStyle.Render("Review Mode:"))
 	content.WriteString("\n")
 	content.WriteString("  Tab       Apply current change\n")
 	content.WriteString("  Space     Skip current change\n")
+	content.WriteString("  ←, S-Tab  Go back to the previous change to revise it\n")
+	content.WriteString("  →         Go to the next change\n")
+	content.WriteString("  Home/End  Jump to the first or last change\n")
+	content.WriteString("  A         Apply all remaining changes\n")
+	content.WriteString("  S         Skip all remaining changes\n")
+	content.WriteString("  O         Suggest alternative phrasings\n")
+	content.WriteString("  1-3       Apply the chosen alternative\n")
+	content.WriteString("  W         Explain why the change was made\n")
+	content.WriteString("  Ctrl+Z    Undo the last decision\n")
 	content.WriteString("  Esc       Exit review mode\n")
+	content.WriteString("  Q, q      Quit (asks first while changes are left)\n")
+
+	if m.cache != nil {
+		content.WriteString("\n")
+		content.WriteString(sectionStyle.Render("Cache:"))
+		content.WriteString("\n")
+		content.WriteString(fmt.Sprintf("  This session: %s\n", m.cache.Session()))
+	}
+
+	if project := m.config.ProjectFile(); project != "" {
+		content.WriteString("\n")
+		content.WriteString(sectionStyle.Render("Project Config:"))
+		content.WriteString("\n")
+		content.WriteString(fmt.Sprintf("  %s\n", project))
+	}
 
 	return helpStyle.Render(content.String())
 }
 
-func Run() error {
+// Options are the command line flags of the TUI
+type Options struct {
+	Accessible bool // Render plainly, whatever the accessible setting
+}
+
+func Run(opts Options) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if opts.Accessible {
+		cfg.Accessible = true
+	}
 
-	if !hasConfiguredAPIKey(cfg) {
-		return fmt.Errorf("%s", missingAPIKeyMessage(cfg))
+	var warning string
+	if !hasConfiguredAPIKey(cfg) && !cfg.Offline {
+		// Ask for a key on the first run; quitting leaves the usual setup hint
+		var done bool
+		done, warning, err = runOnboarding(cfg)
+		if err != nil {
+			return err
+		}
+		if !done {
+			return fmt.Errorf("%s", missingAPIKeyMessage(cfg))
+		}
 	}
 
 	model, err := NewModel(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create model: %w", err)
 	}
+	if warning != "" {
+		model.status = "⚠ " + warning
+	}
 
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	// Pick up edits to the config file while running; without a watcher, changes apply on restart
+	if file, err := config.File(); err == nil {
+		if changes, err := watchConfig(file); err == nil {
+			model.configChanges = changes
+		}
+	}
+
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("program error: %w", err)
 	}
 
+	if model.cache != nil {
+		// Statistics are informational, so failing to save them isn't an error
+		_ = model.cache.SaveStats()
+	}
+
 	return nil
 }
@@ -3,15 +3,30 @@ package ui
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/clipboard"
 	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/translator"
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/zalando/go-keyring"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the API keys that tests save out of the real keychain
+	keyring.MockInit()
+	os.Exit(m.Run())
+}
+
 func TestTrimTrailingWhitespace(t *testing.T) {
 	tests := []struct {
 		name string
@@ -148,28 +163,28 @@ func TestParseDiffIntoChanges(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			changes := parseDiffIntoChanges(tt.original, tt.corrected)
+			changes := computeDiff(tt.original, tt.corrected, diffOptions{}).changes()
 
 			if len(changes) != tt.wantCount {
-				t.Errorf("parseDiffIntoChanges() count = %v, want %v", len(changes), tt.wantCount)
+				t.Errorf("changes() count = %v, want %v", len(changes), tt.wantCount)
 			}
 
 			// Verify change types match expected
 			if len(changes) > 0 && len(tt.wantTypes) > 0 {
 				for i, change := range changes {
 					if i < len(tt.wantTypes) && change.Type != tt.wantTypes[i] {
-						t.Errorf("parseDiffIntoChanges() change[%d].Type = %v, want %v", i, change.Type, tt.wantTypes[i])
+						t.Errorf("changes() change[%d].Type = %v, want %v", i, change.Type, tt.wantTypes[i])
 					}
 					// Verify all changes start as not applied/skipped
 					if change.Applied {
-						t.Errorf("parseDiffIntoChanges() change[%d].Applied = true, want false", i)
+						t.Errorf("changes() change[%d].Applied = true, want false", i)
 					}
 					if change.Skipped {
-						t.Errorf("parseDiffIntoChanges() change[%d].Skipped = true, want false", i)
+						t.Errorf("changes() change[%d].Skipped = true, want false", i)
 					}
-					// Verify text is not empty (unless it's a deletion)
-					if change.Text == "" && change.Type != diffmatchpatch.DiffDelete {
-						t.Errorf("parseDiffIntoChanges() change[%d].Text is empty", i)
+					// Verify a change removes or adds some text
+					if change.Removed == "" && change.Added == "" {
+						t.Errorf("changes() change[%d] is empty", i)
 					}
 				}
 			}
@@ -182,21 +197,21 @@ func TestParseDiffIntoChangesPairsDeleteInsert(t *testing.T) {
 	original := "Hello world"
 	corrected := "Hello there"
 
-	changes := parseDiffIntoChanges(original, corrected)
+	changes := computeDiff(original, corrected, diffOptions{}).changes()
 
 	// Should have one change that pairs the delete and insert
 	if len(changes) != 1 {
-		t.Fatalf("parseDiffIntoChanges() expected 1 change, got %d", len(changes))
+		t.Fatalf("changes() expected 1 change, got %d", len(changes))
 	}
 
 	change := changes[0]
 	if change.Type != diffmatchpatch.DiffDelete {
-		t.Errorf("parseDiffIntoChanges() paired change Type = %v, want DiffDelete", change.Type)
+		t.Errorf("changes() paired change Type = %v, want DiffDelete", change.Type)
 	}
 
-	// Paired changes should contain " → " separator
-	if !strings.Contains(change.Text, " → ") {
-		t.Errorf("parseDiffIntoChanges() paired change Text = %q, should contain ' → '", change.Text)
+	// Paired changes keep both sides
+	if change.Removed != "world" || change.Added != "there" {
+		t.Errorf("changes() paired change = %q → %q, want %q → %q", change.Removed, change.Added, "world", "there")
 	}
 }
 
@@ -213,7 +228,7 @@ func TestBuildReviewedTextFromDiffs(t *testing.T) {
 			original:  "Hello world",
 			corrected: "Hello, world",
 			setupFunc: func(orig, corr string) []DiffChange {
-				changes := parseDiffIntoChanges(orig, corr)
+				changes := computeDiff(orig, corr, diffOptions{}).changes()
 				for i := range changes {
 					changes[i].Applied = true
 					changes[i].Skipped = false
@@ -227,7 +242,7 @@ func TestBuildReviewedTextFromDiffs(t *testing.T) {
 			original:  "Hello world",
 			corrected: "Hello, world",
 			setupFunc: func(orig, corr string) []DiffChange {
-				changes := parseDiffIntoChanges(orig, corr)
+				changes := computeDiff(orig, corr, diffOptions{}).changes()
 				for i := range changes {
 					changes[i].Applied = false
 					changes[i].Skipped = true
@@ -250,7 +265,7 @@ func TestBuildReviewedTextFromDiffs(t *testing.T) {
 			original:  "Hello",
 			corrected: "Hello world",
 			setupFunc: func(orig, corr string) []DiffChange {
-				changes := parseDiffIntoChanges(orig, corr)
+				changes := computeDiff(orig, corr, diffOptions{}).changes()
 				for i := range changes {
 					changes[i].Applied = true
 					changes[i].Skipped = false
@@ -264,7 +279,7 @@ func TestBuildReviewedTextFromDiffs(t *testing.T) {
 			original:  "Hello",
 			corrected: "Hello world",
 			setupFunc: func(orig, corr string) []DiffChange {
-				changes := parseDiffIntoChanges(orig, corr)
+				changes := computeDiff(orig, corr, diffOptions{}).changes()
 				for i := range changes {
 					changes[i].Applied = false
 					changes[i].Skipped = true
@@ -278,7 +293,7 @@ func TestBuildReviewedTextFromDiffs(t *testing.T) {
 			original:  "Hello world",
 			corrected: "Hello",
 			setupFunc: func(orig, corr string) []DiffChange {
-				changes := parseDiffIntoChanges(orig, corr)
+				changes := computeDiff(orig, corr, diffOptions{}).changes()
 				for i := range changes {
 					changes[i].Applied = true
 					changes[i].Skipped = false
@@ -292,7 +307,7 @@ func TestBuildReviewedTextFromDiffs(t *testing.T) {
 			original:  "Hello world",
 			corrected: "Hello",
 			setupFunc: func(orig, corr string) []DiffChange {
-				changes := parseDiffIntoChanges(orig, corr)
+				changes := computeDiff(orig, corr, diffOptions{}).changes()
 				for i := range changes {
 					changes[i].Applied = false
 					changes[i].Skipped = true
@@ -309,9 +324,9 @@ func TestBuildReviewedTextFromDiffs(t *testing.T) {
 			if tt.setupFunc != nil {
 				changes = tt.setupFunc(tt.original, tt.corrected)
 			}
-			got := buildReviewedTextFromDiffs(tt.original, tt.corrected, changes)
+			got := computeDiff(tt.original, tt.corrected, diffOptions{}).reviewedText(changes)
 			if got != tt.want {
-				t.Errorf("buildReviewedTextFromDiffs() = %q, want %q", got, tt.want)
+				t.Errorf("reviewedText() = %q, want %q", got, tt.want)
 			}
 		})
 	}
@@ -323,7 +338,7 @@ func TestBuildReviewedTextFromDiffsMultipleChanges(t *testing.T) {
 	corrected := "I am very happy"
 
 	// Create changes that represent the diff
-	changes := parseDiffIntoChanges(original, corrected)
+	changes := computeDiff(original, corrected, diffOptions{}).changes()
 
 	// Apply first change, skip second
 	if len(changes) >= 1 {
@@ -335,46 +350,46 @@ func TestBuildReviewedTextFromDiffsMultipleChanges(t *testing.T) {
 		changes[1].Skipped = true
 	}
 
-	result := buildReviewedTextFromDiffs(original, corrected, changes)
+	result := computeDiff(original, corrected, diffOptions{}).reviewedText(changes)
 
 	// Result should reflect applied/skipped changes
 	// This is a complex case, so we just verify it doesn't crash and produces something reasonable
 	if result == "" && original != "" {
-		t.Error("buildReviewedTextFromDiffs() returned empty string for non-empty input")
+		t.Error("reviewedText() returned empty string for non-empty input")
 	}
 }
 
 func TestBuildReviewedTextFromDiffsEdgeCases(t *testing.T) {
 	t.Run("empty strings", func(t *testing.T) {
-		result := buildReviewedTextFromDiffs("", "", []DiffChange{})
+		result := computeDiff("", "", diffOptions{}).reviewedText([]DiffChange{})
 		if result != "" {
-			t.Errorf("buildReviewedTextFromDiffs() empty strings = %q, want empty", result)
+			t.Errorf("reviewedText() empty strings = %q, want empty", result)
 		}
 	})
 
 	t.Run("changes longer than diffs", func(t *testing.T) {
 		// More changes than actual diffs - should handle gracefully
 		changes := []DiffChange{
-			{Type: diffmatchpatch.DiffDelete, Text: "extra", Applied: true},
-			{Type: diffmatchpatch.DiffInsert, Text: "extra", Applied: true},
+			{Type: diffmatchpatch.DiffDelete, Removed: "extra", Applied: true},
+			{Type: diffmatchpatch.DiffInsert, Added: "extra", Applied: true},
 		}
-		result := buildReviewedTextFromDiffs("Hello", "Hello", changes)
+		result := computeDiff("Hello", "Hello", diffOptions{}).reviewedText(changes)
 		// Should not crash and should return something reasonable
 		if result == "" {
-			t.Error("buildReviewedTextFromDiffs() should handle extra changes gracefully")
+			t.Error("reviewedText() should handle extra changes gracefully")
 		}
 	})
 
 	t.Run("unicode text", func(t *testing.T) {
 		original := "Hello 世界"
 		corrected := "Hello, 世界"
-		changes := parseDiffIntoChanges(original, corrected)
+		changes := computeDiff(original, corrected, diffOptions{}).changes()
 		if len(changes) > 0 {
 			changes[0].Applied = true
 		}
-		result := buildReviewedTextFromDiffs(original, corrected, changes)
+		result := computeDiff(original, corrected, diffOptions{}).reviewedText(changes)
 		if !strings.Contains(result, "世界") {
-			t.Error("buildReviewedTextFromDiffs() should preserve unicode characters")
+			t.Error("reviewedText() should preserve unicode characters")
 		}
 	})
 }
@@ -402,12 +417,12 @@ func TestHasConfiguredAPIKey(t *testing.T) {
 			want: true,
 		},
 		{
-			name: "anthropic fallback to api_key",
+			name: "anthropic ignores api_key",
 			cfg: &config.Config{
 				Provider: "anthropic",
-				APIKey:   "sk-legacy-1234567890",
+				APIKey:   "sk-openai-1234567890",
 			},
-			want: true,
+			want: false,
 		},
 		{
 			name: "missing keys",
@@ -464,6 +479,8 @@ func newTestModel(t *testing.T, cfg *config.Config) Model {
 	if err != nil {
 		t.Fatalf("NewModel() error = %v", err)
 	}
+	// Keep tests away from the system clipboard
+	m.clipboard = &clipboard.Memory{}
 	return *m
 }
 
@@ -504,32 +521,6 @@ func TestWrapText(t *testing.T) {
 	}
 }
 
-func TestCreateRateLimiter(t *testing.T) {
-	t.Run("disabled returns nil", func(t *testing.T) {
-		cfg := newTestConfig()
-		cfg.RateLimitEnabled = false
-		if rl := createRateLimiter(cfg); rl != nil {
-			t.Fatalf("createRateLimiter() = %#v, want nil", rl)
-		}
-	})
-
-	t.Run("enabled returns limiter and defaults", func(t *testing.T) {
-		cfg := newTestConfig()
-		cfg.RateLimitEnabled = true
-		cfg.RateLimitRequests = 0
-		cfg.RateLimitWindow = 0
-
-		rl := createRateLimiter(cfg)
-		if rl == nil {
-			t.Fatal("createRateLimiter() returned nil")
-		}
-		// First call should pass immediately with initial token bucket.
-		if err := rl.Wait(context.Background()); err != nil {
-			t.Fatalf("Wait() error = %v", err)
-		}
-	})
-}
-
 func TestCreateTimeoutContext(t *testing.T) {
 	t.Run("uses configured timeout", func(t *testing.T) {
 		cfg := newTestConfig()
@@ -710,7 +701,23 @@ func TestUpdateMessageHandling(t *testing.T) {
 		}
 	})
 
-	t.Run("status and stream chunk messages", func(t *testing.T) {
+	t.Run("errMsg explains failed requests", func(t *testing.T) {
+		m := newTestModel(t, newTestConfig())
+		m.isLoading = true
+
+		err := &apierror.Error{Kind: apierror.KindModelNotFound, Provider: "openai", Model: "gpt-5", Err: errors.New("404 Not Found")}
+		nextModelAny, _ := m.Update(errMsg{err: fmt.Errorf("failed to correct: %w", err)})
+		next := nextModelAny.(Model)
+
+		if want := "Model gpt-5 isn't available for your key"; !strings.HasPrefix(next.error, want) {
+			t.Fatalf("error = %q, want %q", next.error, want)
+		}
+		if strings.Contains(next.status, "404") {
+			t.Fatalf("status = %q, want the guidance instead of the raw error", next.status)
+		}
+	})
+
+	t.Run("status and stream ticks", func(t *testing.T) {
 		m := newTestModel(t, newTestConfig())
 
 		nextModelAny, _ := m.Update(statusMsg("running"))
@@ -719,13 +726,34 @@ func TestUpdateMessageHandling(t *testing.T) {
 			t.Fatalf("status = %q, want %q", next.status, "running")
 		}
 
-		nextModelAny, _ = next.Update(streamChunkMsg{chunk: "abc"})
+		next.originalText = "abc"
+		next.isLoading = true
+		stream := &streamBuffer{}
+		stream.write("a")
+		stream.write("bc")
+		nextModelAny, cmd := next.Update(streamTickMsg{buffer: stream, source: "abc"})
 		next = nextModelAny.(Model)
-		if next.correctedText != "abc" {
+		if next.correctedText != "abc" || next.correctedEditor.Value() != "abc" {
 			t.Fatalf("correctedText = %q, want %q", next.correctedText, "abc")
 		}
+		if cmd == nil {
+			t.Fatal("a stream in progress should keep ticking")
+		}
 
-		nextModelAny, _ = next.Update(translationChunkMsg{chunk: "xyz"})
+		// Ticks of a stream that is done change nothing and stop
+		next.isLoading = false
+		stream.write(" more")
+		nextModelAny, cmd = next.Update(streamTickMsg{buffer: stream, source: "abc"})
+		next = nextModelAny.(Model)
+		if next.correctedText != "abc" || cmd != nil {
+			t.Fatalf("correctedText = %q after the stream was done, want it unchanged", next.correctedText)
+		}
+
+		next.correctedText = "abc"
+		next.isTranslating = true
+		stream = &streamBuffer{}
+		stream.write("xyz")
+		nextModelAny, _ = next.Update(streamTickMsg{buffer: stream, source: next.translationSource(), translation: true})
 		next = nextModelAny.(Model)
 		if next.translatedText != "xyz" {
 			t.Fatalf("translatedText = %q, want %q", next.translatedText, "xyz")
@@ -761,3 +789,792 @@ func TestUpdateWindowSize(t *testing.T) {
 		t.Fatalf("window size not updated: got %dx%d", next.width, next.height)
 	}
 }
+
+func TestChangeContext(t *testing.T) {
+	original := "This is fine. The dog is big. Bye."
+	corrected := "This is fine. The dog is huge. Bye."
+
+	sentence, span := computeDiff(original, corrected, diffOptions{}).context(0)
+	if sentence != "The dog is huge." {
+		t.Fatalf("sentence = %q, want %q", sentence, "The dog is huge.")
+	}
+	if span != "huge" {
+		t.Fatalf("span = %q, want %q", span, "huge")
+	}
+
+	sentence, span = computeDiff("Stop. It is really big. Ok.", "Stop. It is big. Ok.", diffOptions{}).context(0)
+	if sentence != "It is really big." || span != "really " {
+		t.Fatalf("deletion context = %q/%q, want original sentence and removed text", sentence, span)
+	}
+
+	if sentence, span := computeDiff(original, corrected, diffOptions{}).context(5); sentence != "" || span != "" {
+		t.Fatalf("out of range change should return empty context, got %q/%q", sentence, span)
+	}
+}
+
+func TestReviewModeAlternatives(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "Hello world"
+	m.correctedText = "Hello there"
+	m.diffChanges = computeDiff(m.originalText, m.correctedText, diffOptions{}).changes()
+	m.mode = ModeReviewDiff
+
+	nextAny, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	next := nextAny.(Model)
+	if !next.isFetchingAlternatives || cmd == nil {
+		t.Fatal("pressing O should start fetching alternatives")
+	}
+
+	nextAny, _ = next.Update(alternativesMsg{changeIndex: 0, alternatives: []string{"everyone", "friends"}})
+	next = nextAny.(Model)
+	if len(next.alternatives) != 2 {
+		t.Fatalf("alternatives = %q, want 2 entries", next.alternatives)
+	}
+
+	nextAny, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	next = nextAny.(Model)
+	if next.correctedText != "Hello friends" {
+		t.Fatalf("correctedText = %q, want %q", next.correctedText, "Hello friends")
+	}
+	if next.mode != ModeGlobal {
+		t.Fatalf("mode = %v, want ModeGlobal after the last change", next.mode)
+	}
+}
+
+func TestReviewModeIgnoresStaleAlternatives(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "Hello world"
+	m.correctedText = "Hello there"
+	m.diffChanges = computeDiff(m.originalText, m.correctedText, diffOptions{}).changes()
+	m.mode = ModeReviewDiff
+
+	nextAny, _ := m.Update(alternativesMsg{changeIndex: 3, alternatives: []string{"x"}})
+	if next := nextAny.(Model); len(next.alternatives) != 0 {
+		t.Fatalf("stale alternatives should be ignored, got %q", next.alternatives)
+	}
+}
+
+func TestStyleOptionsIncludesCustomStyles(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.CustomStyles = []config.CustomStyle{
+		{Name: "Slack", Prompt: "Keep it short."},
+		{Name: "formal", Prompt: "Duplicate of a built-in."},
+		{Name: "empty"},
+	}
+	for i := 0; i < 10; i++ {
+		cfg.CustomStyles = append(cfg.CustomStyles, config.CustomStyle{Name: fmt.Sprintf("extra%d", i), Prompt: "Extra."})
+	}
+
+	options := styleOptions(cfg)
+	if len(options) != maxStyleOptions {
+		t.Fatalf("styleOptions() length = %d, want %d", len(options), maxStyleOptions)
+	}
+	if options[4].key != "5" || options[4].name != "slack" || options[4].label != "Slack" {
+		t.Fatalf("styleOptions()[4] = %+v, want slack on key 5", options[4])
+	}
+	if options[5].name != "extra0" {
+		t.Fatalf("styleOptions()[5] = %+v, duplicates and empty prompts should be skipped", options[5])
+	}
+}
+
+func TestSwitchToCustomStyle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := newTestConfig()
+	cfg.CustomStyles = []config.CustomStyle{{Name: "slack", Prompt: "Keep it short."}}
+	m := newTestModel(t, cfg)
+
+	nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")})
+	next := nextAny.(Model)
+	if next.config.Style != "slack" {
+		t.Fatalf("Style = %q, want slack", next.config.Style)
+	}
+	if !strings.Contains(next.status, "Slack") {
+		t.Fatalf("status = %q, want it to mention Slack", next.status)
+	}
+
+	// Keys without a configured style are ignored
+	nextAny, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("9")})
+	if nextAny.(Model).config.Style != "slack" {
+		t.Fatal("pressing an unassigned number key should not change the style")
+	}
+}
+
+func TestToneMenuRewrite(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+
+	// Nothing to rewrite yet
+	nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if next := nextAny.(Model); next.mode != ModeGlobal {
+		t.Fatalf("mode = %v, want ModeGlobal without text", next.mode)
+	}
+
+	m.originalText = "i think we could maybe ship it friday"
+	m.correctedText = "I think we could maybe ship it Friday."
+
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	next := nextAny.(Model)
+	if next.mode != ModeToneMenu {
+		t.Fatalf("mode = %v, want ModeToneMenu", next.mode)
+	}
+	if view := next.View(); !strings.Contains(view, "Concise") {
+		t.Fatalf("tone menu should list the tones, got %q", view)
+	}
+
+	nextAny, cmd := next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+	next = nextAny.(Model)
+	if next.mode != ModeGlobal || !next.isLoading || cmd == nil {
+		t.Fatal("choosing a tone should start a rewrite")
+	}
+
+	nextAny, _ = next.Update(rewriteDoneMsg{source: m.correctedText, label: "Concise", rewritten: "We ship Friday."})
+	next = nextAny.(Model)
+	if next.correctedText != "We ship Friday." || next.isLoading {
+		t.Fatalf("correctedText = %q, isLoading = %v", next.correctedText, next.isLoading)
+	}
+	if next.diffBase() != m.correctedText {
+		t.Fatalf("diffBase() = %q, want the rewritten source", next.diffBase())
+	}
+	if !strings.Contains(next.View(), "Rewritten Text (Concise)") {
+		t.Fatal("corrected pane should be labelled with the tone")
+	}
+
+	nextAny, _ = next.Update(correctionDoneMsg{original: "Hello", corrected: "Hello."})
+	if next = nextAny.(Model); next.rewriteLabel != "" || next.diffBase() != "Hello" {
+		t.Fatalf("a new correction should clear the rewrite, got label %q", next.rewriteLabel)
+	}
+}
+
+func TestQuickRewriteActions(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.ShortenPercent = 30
+	m := newTestModel(t, cfg)
+	m.originalText = "hello"
+	m.correctedText = "Hello."
+
+	tests := []struct {
+		key   string
+		label string
+	}{
+		{key: "s", label: "Shortened to 30%"},
+		{key: "x", label: "Expanded"},
+		{key: "p", label: "Simplified"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			nextAny, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+			next := nextAny.(Model)
+			if !next.isLoading || cmd == nil {
+				t.Fatalf("pressing %s should start a rewrite", tt.key)
+			}
+			if !strings.Contains(next.status, tt.label) {
+				t.Fatalf("status = %q, want it to mention %q", next.status, tt.label)
+			}
+		})
+	}
+
+	m.isLoading = true
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}); cmd != nil {
+		t.Fatal("quick actions should be ignored while a request is running")
+	}
+}
+
+// blockingProvider answers no request until it is cancelled
+type blockingProvider struct{}
+
+func (blockingProvider) StreamChat(ctx context.Context, model string, messages []provider.Message, onChunk func(string)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (blockingProvider) Chat(ctx context.Context, model string, messages []provider.Message) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestChunkedCorrection(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	corr, err := corrector.New(provider.NewMockProvider(), "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("corrector.New() error = %v", err)
+	}
+	// The mock provider echoes the prompt, so each chunk comes back prefixed
+	if err := corr.SetPromptTemplate("{{.Text}}"); err != nil {
+		t.Fatalf("SetPromptTemplate() error = %v", err)
+	}
+	m.corrector = corr
+
+	paragraph := strings.Repeat("lorem ipsum ", 800)
+	var paragraphs []string
+	for len(strings.Join(paragraphs, "\n\n")) <= 100000 {
+		paragraphs = append(paragraphs, paragraph)
+	}
+	text := strings.Join(paragraphs, "\n\n")
+	if !corrector.NeedsChunking(text) {
+		t.Fatal("test text should need chunking")
+	}
+
+	m.originalText = text
+	m.isLoading = true
+	m.config.ChunkConcurrency = 3
+	total := len(corrector.SplitChunks(text, corrector.MaxChunkLength))
+
+	msg := m.correctInChunks(text)()
+	for i := 0; ; i++ {
+		next, ok := msg.(chunkProgressMsg)
+		if !ok {
+			break
+		}
+		nextAny, cmd := m.Update(next)
+		m = nextAny.(Model)
+		if want := fmt.Sprintf("(%d/%d)", i, total); !strings.Contains(m.status, want) {
+			t.Fatalf("status = %q, want progress %s", m.status, want)
+		}
+		msg = cmd()
+	}
+
+	done, ok := msg.(correctionDoneMsg)
+	if !ok {
+		t.Fatalf("final message = %T, want correctionDoneMsg", msg)
+	}
+	if got := strings.Count(done.corrected, "Mock response for: "); got != total {
+		t.Fatalf("corrected text has %d corrected chunks, want %d", got, total)
+	}
+	if got, want := strings.Count(done.corrected, "\n\n"), strings.Count(text, "\n\n"); got != want {
+		t.Fatalf("corrected text has %d blank lines, want %d", got, want)
+	}
+
+	// A correction of a text that is no longer shown is stopped
+	if m.corrector, err = corrector.New(blockingProvider{}, "gpt-4o", "casual", "english"); err != nil {
+		t.Fatalf("corrector.New() error = %v", err)
+	}
+	m.originalText = "something else"
+	m.isLoading = true
+	progress, ok := m.correctInChunks(text)().(chunkProgressMsg)
+	if !ok {
+		t.Fatal("chunked correction should report progress")
+	}
+	if _, cmd := m.Update(progress); cmd != nil {
+		t.Fatal("stale chunk progress should be ignored")
+	}
+	for msg := range progress.updates {
+		if _, ok := msg.(correctionDoneMsg); ok {
+			t.Fatal("stale chunked correction should be stopped")
+		}
+		if _, ok := msg.(errMsg); ok {
+			break
+		}
+	}
+}
+
+func TestSwitchDialect(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := newTestModel(t, newTestConfig())
+
+	want := []string{"us", "uk", "au", ""}
+	for _, dialect := range want {
+		nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+		m = nextAny.(Model)
+		if m.config.Dialect != dialect {
+			t.Fatalf("dialect = %q, want %q", m.config.Dialect, dialect)
+		}
+		if dialect != "" && !strings.Contains(m.renderStyleIndicator(), strings.ToUpper(dialect)) {
+			t.Fatalf("style indicator %q should show the dialect", m.renderStyleIndicator())
+		}
+	}
+	if strings.Contains(m.renderStyleIndicator(), "·") {
+		t.Fatalf("style indicator %q should not show a dialect", m.renderStyleIndicator())
+	}
+}
+
+func TestSwitchCategory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := newTestModel(t, newTestConfig())
+
+	for _, category := range []string{"spelling", "punctuation", "grammar", "all"} {
+		nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+		m = nextAny.(Model)
+		if m.config.Category != category {
+			t.Fatalf("category = %q, want %q", m.config.Category, category)
+		}
+	}
+
+	m.config.Category = "spelling"
+	if indicator := m.renderStyleIndicator(); !strings.Contains(indicator, "Spelling only") {
+		t.Fatalf("style indicator %q should show the category", indicator)
+	}
+}
+
+func TestSwitchDiffMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	cfg := newTestConfig()
+	cfg.DiffGranularity = config.DiffWords
+	m := newTestModel(t, cfg)
+	m.originalText = "The fox jumps  over the dog"
+	m.correctedText = "The fox jumped over the dog"
+
+	want := []struct {
+		opts   diffOptions
+		status string
+	}{
+		{diffOptions{words: true, ignoreWhitespace: true}, "Diff: words, ignoring whitespace"},
+		{diffOptions{}, "Diff: characters"},
+		{diffOptions{ignoreWhitespace: true}, "Diff: characters, ignoring whitespace"},
+		{diffOptions{words: true}, "Diff: words"},
+	}
+	for _, w := range want {
+		nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+		m = nextAny.(Model)
+		if got := diffOptionsFor(m.config); got != w.opts {
+			t.Fatalf("diff options = %+v, want %+v", got, w.opts)
+		}
+		if !strings.HasPrefix(m.status, w.status) {
+			t.Fatalf("status = %q, want %q", m.status, w.status)
+		}
+	}
+
+	// Review mode compares the texts the same way
+	nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = nextAny.(Model)
+	if len(m.diffChanges) != 1 || !strings.HasPrefix(m.diffChanges[0].Removed, "jumps") {
+		t.Fatalf("changes = %+v, want jumps replaced by jumped", m.diffChanges)
+	}
+}
+
+func TestConsistencyReport(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "Send an email."
+	m.correctedText = "Send an email."
+
+	nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	next := nextAny.(Model)
+	if next.mode != ModeGlobal || !strings.Contains(next.status, "No inconsistent terms") {
+		t.Fatalf("mode = %v, status = %q, want no issues", next.mode, next.status)
+	}
+
+	m.correctedText = "Send an email, then another email and an e-mail."
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	next = nextAny.(Model)
+	if next.mode != ModeConsistency || len(next.consistencyIssues) != 1 {
+		t.Fatalf("mode = %v, issues = %d, want the consistency report", next.mode, len(next.consistencyIssues))
+	}
+	if view := next.View(); !strings.Contains(view, "e-mail (1)") {
+		t.Fatalf("report should list the variants, got %q", view)
+	}
+
+	nextAny, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	next = nextAny.(Model)
+	if next.mode != ModeGlobal {
+		t.Fatalf("mode = %v, want ModeGlobal after normalizing", next.mode)
+	}
+	if want := "Send an email, then another email and an email."; next.correctedText != want {
+		t.Fatalf("correctedText = %q, want %q", next.correctedText, want)
+	}
+	if next.diffBase() != m.correctedText {
+		t.Fatalf("diffBase() = %q, want the text before normalizing", next.diffBase())
+	}
+}
+
+func TestGlossaryViolationsShown(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.width, m.height = 100, 40
+	m.glossary = &glossary.Glossary{Terms: []glossary.Term{{Preferred: "sign in", Avoid: []string{"login"}}}}
+	m.originalText = "Please login."
+	m.correctedText = "Please login."
+
+	if view := m.View(); !strings.Contains(view, `Glossary: use "sign in" instead of "login"`) {
+		t.Fatalf("view should warn about the glossary violation, got %q", view)
+	}
+
+	m.correctedText = "Please sign in."
+	if view := m.View(); strings.Contains(view, "Glossary") {
+		t.Fatal("view should not warn when the text follows the glossary")
+	}
+}
+
+func TestConfirmLargePaste(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.ConfirmTokens = 300
+	m := newTestModel(t, cfg)
+
+	// Small texts are sent right away, with the estimate in the status line
+	nextModelAny, cmd := m.Update(textPastedMsg{text: "Hello world"})
+	next := nextModelAny.(Model)
+	if next.mode != ModeGlobal || !next.isLoading || cmd == nil {
+		t.Fatalf("small paste should start correcting, mode = %v, isLoading = %v", next.mode, next.isLoading)
+	}
+	if !strings.Contains(next.estimate, "tokens, est. ") {
+		t.Fatalf("estimate = %q, want tokens and cost", next.estimate)
+	}
+	if !strings.Contains(next.View(), next.estimate) {
+		t.Fatal("View() should show the estimate")
+	}
+
+	large := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 50)
+	nextModelAny, cmd = m.Update(textPastedMsg{text: large})
+	next = nextModelAny.(Model)
+	if next.mode != ModeConfirmSend || next.isLoading || cmd != nil {
+		t.Fatalf("large paste should ask first, mode = %v, isLoading = %v", next.mode, next.isLoading)
+	}
+	if !strings.HasPrefix(next.status, "Send ≈") {
+		t.Fatalf("status = %q, want a confirmation prompt", next.status)
+	}
+
+	cancelledAny, _ := next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	cancelled := cancelledAny.(Model)
+	if cancelled.mode != ModeGlobal || cancelled.isLoading || cancelled.status != "Cancelled" {
+		t.Fatalf("n should cancel, mode = %v, status = %q", cancelled.mode, cancelled.status)
+	}
+
+	confirmedAny, cmd := next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	confirmed := confirmedAny.(Model)
+	if confirmed.mode != ModeGlobal || !confirmed.isLoading || cmd == nil {
+		t.Fatalf("y should start correcting, mode = %v, isLoading = %v", confirmed.mode, confirmed.isLoading)
+	}
+}
+
+func TestCharCountLabel(t *testing.T) {
+	if got := (Model{}).charCountLabel(""); got != "" {
+		t.Fatalf("charCountLabel(\"\") = %q, want empty", got)
+	}
+	if got := removeANSICodes((Model{}).charCountLabel("日本語 👍🏽")); got != " 5 chars" {
+		t.Fatalf("charCountLabel() = %q, want %q", got, " 5 chars")
+	}
+}
+
+func TestRedactionLabel(t *testing.T) {
+	cfg := newTestConfig()
+	m := Model{config: cfg}
+	text := "Ask Anna Lee at anna@example.com or +1 415-555-0132."
+	if got := m.redactionLabel(text); got != "" {
+		t.Fatalf("redactionLabel() = %q, want empty with redact_pii off", got)
+	}
+	cfg.RedactPII = true
+	if got := removeANSICodes(m.redactionLabel(text)); got != ", 3 redacted" {
+		t.Fatalf("redactionLabel() = %q, want %q", got, ", 3 redacted")
+	}
+	if got := m.redactionLabel("Nothing personal here."); got != "" {
+		t.Fatalf("redactionLabel() = %q, want empty without personal data", got)
+	}
+}
+
+func TestSourceLanguageDetection(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := newTestConfig()
+	cfg.DetectLanguage = true
+	cfg.TranslationLanguage = "spanish"
+	m := newTestModel(t, cfg)
+
+	nextAny, _ := m.Update(textPastedMsg{text: "Yo tengo una manzana y es muy roja."})
+	m = nextAny.(Model)
+	if m.sourceLanguage != "spanish" || m.corrector.Language() != "spanish" {
+		t.Fatalf("source language = %q, corrector language = %q, want spanish", m.sourceLanguage, m.corrector.Language())
+	}
+	if !strings.Contains(m.renderStyleIndicator(), "Spanish (auto)") {
+		t.Fatalf("style indicator %q should show the detected language", m.renderStyleIndicator())
+	}
+	// Text already in the translation language isn't translated
+	if m.shouldTranslate("Yo tengo una manzana.") {
+		t.Fatal("shouldTranslate() should be false for text in the translation language")
+	}
+
+	// G pins a language instead of detecting it
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	m = nextAny.(Model)
+	if m.config.DetectLanguage || m.config.Language != "english" {
+		t.Fatalf("detect = %v, language = %q, want english without detection", m.config.DetectLanguage, m.config.Language)
+	}
+	if m.sourceLanguage != "english" || m.corrector.Language() != "english" {
+		t.Fatalf("source language = %q, want the pinned language", m.sourceLanguage)
+	}
+	if !m.shouldTranslate("I have an apple.") {
+		t.Fatal("shouldTranslate() should be true for text in another language")
+	}
+}
+
+func TestToggleTranslationSource(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.TranslationLanguage = "french"
+	m := newTestModel(t, cfg)
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m.translatedText = "J'ai une pomme."
+
+	if !strings.Contains(m.View(), "Translation of Corrected") {
+		t.Fatal("translation pane should say it translates the corrected text")
+	}
+
+	nextAny, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m = nextAny.(Model)
+	if !m.translateOriginal || m.translationSource() != m.originalText {
+		t.Fatal("B should switch the translation source to the original text")
+	}
+	if !m.isTranslating || cmd == nil || m.translatedText != "" {
+		t.Fatalf("B should start a new translation, isTranslating = %v, translatedText = %q", m.isTranslating, m.translatedText)
+	}
+	if !strings.Contains(m.View(), "Translation of Original") {
+		t.Fatal("translation pane should say it translates the original text")
+	}
+
+	// A translation of the corrected text that arrives late is dropped
+	nextAny, _ = m.Update(translationDoneMsg{source: m.correctedText, translated: "J'ai une pomme."})
+	m = nextAny.(Model)
+	if m.translatedText != "" {
+		t.Fatalf("translatedText = %q, want the stale translation dropped", m.translatedText)
+	}
+	nextAny, _ = m.Update(translationDoneMsg{source: m.originalText, translated: "J'a une pomme."})
+	m = nextAny.(Model)
+	if m.translatedText != "J'a une pomme." {
+		t.Fatalf("translatedText = %q, want the translation of the original", m.translatedText)
+	}
+}
+
+func TestTranslationCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := newTestConfig()
+	cfg.CacheEnabled = true
+	cfg.CacheTTLDays = 1
+	cfg.TranslationLanguage = "french"
+	m := newTestModel(t, cfg)
+	trans, err := translator.NewWithRateLimit(provider.NewMockProvider(), cfg.Model, cfg.TranslationLanguage, nil)
+	if err != nil {
+		t.Fatalf("NewWithRateLimit() error = %v", err)
+	}
+	m.translator = trans
+
+	if got := m.cachedTranslation("I have an apple."); got != "" {
+		t.Fatalf("cachedTranslation() = %q before anything was cached", got)
+	}
+	m.saveTranslationToCache("I have an apple.", "J'ai une pomme.")
+
+	// The cached translation is used instead of calling the provider
+	batch, ok := m.streamTranslation("I have an apple.")().(tea.BatchMsg)
+	if !ok || len(batch) != 3 {
+		t.Fatalf("streamTranslation() should return a batch of three commands")
+	}
+	msg, ok := batch[1]().(translationDoneMsg)
+	if !ok || msg.translated != "J'ai une pomme." {
+		t.Fatalf("streamTranslation() = %#v, want the cached translation", msg)
+	}
+
+	// A different model or translation language doesn't reuse it
+	m.config.Model = "gpt-4o-mini"
+	if got := m.cachedTranslation("I have an apple."); got != "" {
+		t.Fatalf("cachedTranslation() = %q, want no translation for another model", got)
+	}
+}
+
+func TestRomanizeTranslation(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.TranslationLanguage = "japanese"
+	m := newTestModel(t, cfg)
+	m.width, m.height = 100, 40
+	m.correctedText = "Good morning."
+
+	nextAny, cmd := m.Update(translationDoneMsg{source: m.correctedText, translated: "おはようございます。"})
+	m = nextAny.(Model)
+	if cmd != nil {
+		t.Fatal("translations should not be romanized unless romanize is on")
+	}
+
+	m.config.Romanize = true
+	nextAny, cmd = m.Update(translationDoneMsg{source: m.correctedText, translated: "おはようございます。"})
+	m = nextAny.(Model)
+	if cmd == nil {
+		t.Fatal("a Japanese translation should be romanized when romanize is on")
+	}
+	nextAny, _ = m.Update(romanizationDoneMsg{translated: "おはようございます。", romanized: "Ohayō gozaimasu."})
+	m = nextAny.(Model)
+	if !strings.Contains(m.View(), "Ohayō gozaimasu.") {
+		t.Fatal("the romanization should be shown beneath the translation")
+	}
+
+	// A romanization of an older translation isn't shown
+	m.translatedText = "こんにちは。"
+	if strings.Contains(m.View(), "Ohayō gozaimasu.") {
+		t.Fatal("a stale romanization should not be shown")
+	}
+}
+
+func TestCorrectionCacheKeyFollowsSettings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := newTestConfig()
+	cfg.CacheEnabled = true
+	cfg.CacheTTLDays = 1
+	m := newTestModel(t, cfg)
+	m.saveToCache("I has a apple.", "I have an apple.")
+	if got := m.cache.Get(m.correctionHash("I has a apple.")); got != "I have an apple." {
+		t.Fatalf("cached correction = %q, want %q", got, "I have an apple.")
+	}
+
+	formal := newTestConfig()
+	formal.CacheEnabled = true
+	formal.CacheTTLDays = 1
+	formal.Style = "formal"
+	mf := newTestModel(t, formal)
+	if got := mf.cache.Get(mf.correctionHash("I has a apple.")); got != "" {
+		t.Fatalf("a correction in another style should not be reused, got %q", got)
+	}
+
+	other := newTestConfig()
+	other.CacheEnabled = true
+	other.CacheTTLDays = 1
+	other.Model = "gpt-4o-mini"
+	mo := newTestModel(t, other)
+	if got := mo.cache.Get(mo.correctionHash("I has a apple.")); got != "" {
+		t.Fatalf("a correction by another model should not be reused, got %q", got)
+	}
+}
+
+func TestCacheHitStatus(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+
+	nextAny, _ := m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have an apple.", cached: true})
+	m = nextAny.(Model)
+	if m.status != "✓ Done (cache hit)" {
+		t.Fatalf("status = %q, want the cache hit to be shown", m.status)
+	}
+
+	nextAny, _ = m.Update(correctionDoneMsg{original: "I has a pear.", corrected: "I have a pear."})
+	m = nextAny.(Model)
+	if m.status != "✓ Done" {
+		t.Fatalf("status = %q, want %q", m.status, "✓ Done")
+	}
+}
+
+func TestReviewModeNavigation(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple and a orange."
+	m.correctedText = "I have an apple and an orange."
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if m.mode != ModeReviewDiff || len(m.diffChanges) < 3 {
+		t.Fatalf("mode = %v with %d changes, want a review of several changes", m.mode, len(m.diffChanges))
+	}
+	last := len(m.diffChanges) - 1
+
+	// Apply the first change, then go back and skip it instead
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.currentChange != 0 {
+		t.Fatalf("currentChange = %d, want 0 after going back", m.currentChange)
+	}
+	if !strings.Contains(m.View(), "(applied)") {
+		t.Fatal("a revisited change should show its decision")
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if !m.diffChanges[0].Skipped || m.diffChanges[0].Applied {
+		t.Fatalf("first change = %+v, want it skipped after revising", m.diffChanges[0])
+	}
+	if m.currentChange != 1 {
+		t.Fatalf("currentChange = %d, want 1", m.currentChange)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEnd})
+	if m.currentChange != last {
+		t.Fatalf("currentChange = %d, want the last change %d", m.currentChange, last)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	if m.currentChange != last || m.mode != ModeReviewDiff {
+		t.Fatal("→ on the last change should stay there")
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyHome})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyLeft})
+	if m.currentChange != 0 {
+		t.Fatalf("currentChange = %d, want ← on the first change to stay there", m.currentChange)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	if m.currentChange != 1 || m.diffChanges[1].Applied || m.diffChanges[1].Skipped {
+		t.Fatal("→ should move on without deciding")
+	}
+}
+
+func TestReviewModeBulkDecisions(t *testing.T) {
+	start := func(t *testing.T) Model {
+		m := newTestModel(t, newTestConfig())
+		m.originalText = "I has a apple and a orange."
+		m.correctedText = "I have an apple and an orange."
+		return pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	}
+
+	t.Run("apply all", func(t *testing.T) {
+		m := start(t)
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+		if m.mode != ModeReviewDiff || !strings.Contains(m.status, "(y/n)") {
+			t.Fatalf("mode = %v, status = %q, want a confirmation", m.mode, m.status)
+		}
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+		if m.mode != ModeGlobal {
+			t.Fatalf("mode = %v, want the review finished", m.mode)
+		}
+		// The skipped first change stays skipped
+		if m.correctedText != "I has an apple and an orange." {
+			t.Fatalf("correctedText = %q", m.correctedText)
+		}
+	})
+
+	t.Run("skip all", func(t *testing.T) {
+		m := start(t)
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+		if m.mode != ModeGlobal || m.correctedText != "I has a apple and a orange." {
+			t.Fatalf("mode = %v, correctedText = %q, want every change skipped", m.mode, m.correctedText)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		m := start(t)
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+		if m.mode != ModeReviewDiff || m.undecidedChanges() != len(m.diffChanges) {
+			t.Fatal("cancelling should leave the review as it was")
+		}
+		// Tab works normally again
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyTab})
+		if !m.diffChanges[0].Applied {
+			t.Fatal("Tab should apply the change after cancelling")
+		}
+	})
+}
+
+func TestReviewChangeCategories(t *testing.T) {
+	original, corrected := "Hi world I has a apple, and recieve it", "Hi, world I have an apple and receive it"
+	changes := computeDiff(original, corrected, diffOptions{}).changes()
+	var categories []string
+	for _, change := range changes {
+		categories = append(categories, change.Category)
+	}
+	want := []string{"punctuation", "grammar", "grammar", "punctuation", "spelling", "spelling"}
+	if strings.Join(categories, ",") != strings.Join(want, ",") {
+		t.Fatalf("categories = %v, want %v", categories, want)
+	}
+
+	if got := changeSummary(changes); got != "6 changes: 2 spelling, 2 punctuation, 2 grammar" {
+		t.Errorf("changeSummary() = %q", got)
+	}
+	if got := changeSummary(changes[1:2]); got != "1 change: 1 grammar" {
+		t.Errorf("changeSummary() = %q", got)
+	}
+
+	m := newTestModel(t, newTestConfig())
+	m.originalText = original
+	m.correctedText = corrected
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	view := m.View()
+	if !strings.Contains(view, "6 changes: 2 spelling, 2 punctuation, 2 grammar") || !strings.Contains(view, "· Punctuation") {
+		t.Fatalf("review should show the summary and the category of the change:\n%s", view)
+	}
+}
+
+func TestReadyStatus(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.clipboard = &clipboard.Memory{}
+	if got := m.readyStatus(); got != "Ready. Press V to paste, C to copy, ? for help" {
+		t.Errorf("readyStatus() = %q", got)
+	}
+
+	m.clipboard = clipboard.OSC52{Hint: "install wl-clipboard to paste from the clipboard"}
+	if got := m.readyStatus(); !strings.Contains(got, "press N to type") || !strings.Contains(got, "install wl-clipboard") {
+		t.Errorf("readyStatus() = %q, want the way to type a text and the hint", got)
+	}
+}
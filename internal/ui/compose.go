@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// compose opens an empty original editor to type a new text into, instead of pasting it from the
+// clipboard
+func (m Model) compose() (tea.Model, tea.Cmd) {
+	m.composing = true
+	m.mode = ModeEditOriginal
+	m.originalEditor.SetValue("")
+	m.originalEditor.Focus()
+	m.status = "Type your text, then Ctrl+Enter to correct it"
+	return m, textarea.Blink
+}
+
+// submitCompose corrects the typed text like a pasted one
+func (m Model) submitCompose() (tea.Model, tea.Cmd) {
+	text := trimTrailingWhitespace(m.originalEditor.Value())
+	if strings.TrimSpace(text) == "" {
+		m.status = "Nothing to correct"
+		return m, nil
+	}
+	m = m.closeCompose()
+	return m, func() tea.Msg { return m.newText(text) }
+}
+
+// cancelCompose leaves compose mode and puts the previous text back
+func (m Model) cancelCompose() (tea.Model, tea.Cmd) {
+	m = m.closeCompose()
+	m.originalEditor.SetValue(m.originalText)
+	m.status = "Cancelled"
+	return m, nil
+}
+
+func (m Model) closeCompose() Model {
+	m.composing = false
+	m.selecting = false
+	m.mode = ModeGlobal
+	m.originalEditor.Blur()
+	return m
+}
+
+// promoteCorrection makes the corrected text the new original and corrects it again, so a text
+// can be refined in passes (correct, then make it formal, then shorten it)
+func (m Model) promoteCorrection() (tea.Model, tea.Cmd) {
+	if m.isLoading || m.correctedText == "" {
+		return m, nil
+	}
+	text := m.correctedText
+	return m, func() tea.Msg { return m.newText(text) }
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCompose(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "Old text."
+	m.correctedText = "Old text."
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if !m.composing || m.mode != ModeEditOriginal || m.originalEditor.Value() != "" {
+		t.Fatalf("N should open an empty original editor, mode = %v, value = %q", m.mode, m.originalEditor.Value())
+	}
+	if !strings.Contains(m.View(), "grammr - Compose") {
+		t.Fatal("view should show compose mode")
+	}
+
+	// Nothing typed yet
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlJ})
+	if !m.composing || m.status != "Nothing to correct" {
+		t.Fatalf("composing = %v, status = %q", m.composing, m.status)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("I has a apple.")})
+	nextAny, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlJ})
+	m = nextAny.(Model)
+	if m.composing || m.mode != ModeGlobal || cmd == nil {
+		t.Fatalf("Ctrl+Enter should correct the text, composing = %v, mode = %v", m.composing, m.mode)
+	}
+	msg, ok := cmd().(textPastedMsg)
+	if !ok || msg.text != "I has a apple." {
+		t.Fatalf("cmd() = %#v, want the typed text", msg)
+	}
+}
+
+func TestComposeCancel(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "Old text."
+	m.originalEditor.SetValue("Old text.")
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("New")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.composing || m.mode != ModeGlobal {
+		t.Fatalf("Esc should leave compose mode, mode = %v", m.mode)
+	}
+	if m.originalText != "Old text." || m.originalEditor.Value() != "Old text." {
+		t.Fatalf("original = %q, editor = %q, want the previous text", m.originalText, m.originalEditor.Value())
+	}
+}
+
+func TestPromoteCorrection(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+
+	// Nothing corrected yet
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}); cmd != nil {
+		t.Fatal("J without a corrected text should do nothing")
+	}
+
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")})
+	if cmd == nil {
+		t.Fatal("J should correct the corrected text")
+	}
+	msg, ok := cmd().(textPastedMsg)
+	if !ok || msg.text != "I have an apple." {
+		t.Fatalf("cmd() = %#v, want the corrected text as the new original", msg)
+	}
+
+	m.isLoading = true
+	if _, cmd := m.promoteCorrection(); cmd != nil {
+		t.Fatal("J should wait for the running correction")
+	}
+}
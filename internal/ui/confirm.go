@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmation is a yes/no question asked before an action that would lose work
+type confirmation struct {
+	prompt string
+	onYes  func(m Model) (tea.Model, tea.Cmd)
+	onNo   func(m Model) Model // Runs when the user declines, nil for nothing
+}
+
+// confirm asks prompt in the status line and runs onYes once the user agrees. Until they
+// answer, every other key is ignored.
+func (m Model) confirm(prompt string, onYes func(m Model) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	m.confirmation = &confirmation{prompt: prompt, onYes: onYes}
+	m.status = prompt + " (y/n)"
+	return m, nil
+}
+
+func (m Model) handleConfirmation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		onYes := m.confirmation.onYes
+		m.confirmation = nil
+		return onYes(m)
+	case "n", "N", "esc":
+		onNo := m.confirmation.onNo
+		m.confirmation = nil
+		m.status = "Cancelled"
+		if m.mode == ModeReviewDiff {
+			m.status = reviewStatus(m.currentChange, len(m.diffChanges))
+		}
+		if onNo != nil {
+			m = onNo(m)
+		}
+	}
+	return m, nil
+}
+
+// confirmDiscardEdits runs action right away, or asks first when it would replace edits made to
+// the corrected text
+func (m Model) confirmDiscardEdits(action func(m Model) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	if !m.hasEdits() {
+		return action(m)
+	}
+	return m.confirm("Discard your edits to the corrected text?", action)
+}
+
+// pendingChanges counts the changes of the review that are neither applied nor skipped
+func (m Model) pendingChanges() int {
+	pending := 0
+	for _, change := range m.diffChanges {
+		if !change.Applied && !change.Skipped {
+			pending++
+		}
+	}
+	return pending
+}
+
+// quitReview quits from review mode, asking first while changes are left to review
+func (m Model) quitReview() (tea.Model, tea.Cmd) {
+	quit := func(m Model) (tea.Model, tea.Cmd) { return m, tea.Quit }
+	pending := m.pendingChanges()
+	switch pending {
+	case 0:
+		return quit(m)
+	case 1:
+		return m.confirm("Quit with 1 change not reviewed?", quit)
+	}
+	return m.confirm(fmt.Sprintf("Quit with %d changes not reviewed?", pending), quit)
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConfirmDiscardEdits(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple."
+	m.correctionResult = "I have an apple."
+	m.correctedText = "I have a red apple."
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if m.confirmation == nil || m.status != "Discard your edits to the corrected text? (y/n)" {
+		t.Fatalf("status = %q, want a question before the edits are replaced", m.status)
+	}
+
+	// Other keys and pastes wait for the answer
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = pressKey(t, m, pasteMsg("New text."))
+	if m.confirmation == nil || m.correctedText != "I have a red apple." {
+		t.Fatal("keys other than y and n should be ignored while asking")
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m.confirmation != nil || m.status != "Cancelled" {
+		t.Fatalf("status = %q, want the paste cancelled", m.status)
+	}
+
+	next, cmd := m.Update(pasteMsg("New text."))
+	m = next.(Model)
+	if m.confirmation == nil || cmd != nil {
+		t.Fatal("a terminal paste should ask too")
+	}
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = next.(Model)
+	if m.confirmation != nil || cmd == nil {
+		t.Fatal("y should go ahead with the paste")
+	}
+	if msg, ok := cmd().(textPastedMsg); !ok || msg.text != "New text." {
+		t.Errorf("message = %#v, want the pasted text", msg)
+	}
+
+	// Without edits nothing is asked
+	m.correctedText = m.correctionResult
+	if next, cmd := m.Update(pasteMsg("Other text.")); next.(Model).confirmation != nil || cmd == nil {
+		t.Error("a paste without edits should go ahead right away")
+	}
+}
+
+func TestQuitReview(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if m.mode != ModeReviewDiff || len(m.diffChanges) == 0 {
+		t.Fatalf("mode = %v, want review mode with changes", m.mode)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	m = next.(Model)
+	if cmd != nil || m.confirmation == nil {
+		t.Fatalf("status = %q, want a question before quitting mid-review", m.status)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeReviewDiff || m.status != reviewStatus(m.currentChange, len(m.diffChanges)) {
+		t.Fatalf("mode = %v, status = %q, want to keep reviewing", m.mode, m.status)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd == nil {
+		t.Fatal("y should quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("y should quit")
+	}
+
+	// Once every change is decided, q quits right away
+	for i := range m.diffChanges {
+		m.diffChanges[i].Applied = true
+	}
+	m.confirmation = nil
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil {
+		t.Error("q should quit without asking when nothing is left to review")
+	}
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/consistency"
+)
+
+// checkConsistency looks for terms written in more than one way and opens the report
+func (m Model) checkConsistency() (tea.Model, tea.Cmd) {
+	text := m.rewriteSourceText()
+	if text == "" || m.isLoading {
+		return m, nil
+	}
+
+	m.consistencyIssues = consistency.Check(text)
+	if len(m.consistencyIssues) == 0 {
+		m.status = "✓ No inconsistent terms found"
+		return m, nil
+	}
+	m.mode = ModeConsistency
+	return m, nil
+}
+
+func (m Model) handleConsistencyMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "i", "I":
+		m.mode = ModeGlobal
+		m.consistencyIssues = nil
+		return m, nil
+	case "enter", "n", "N":
+		// Normalize every term to its preferred form and show the result like a rewrite
+		source := m.rewriteSourceText()
+		normalized := consistency.Normalize(source, m.consistencyIssues)
+		m.mode = ModeGlobal
+		m.consistencyIssues = nil
+		return m.Update(rewriteDoneMsg{source: source, label: "Normalized", rewritten: normalized})
+	}
+	return m, nil
+}
+
+func (m Model) renderConsistencyReport() string {
+	reportStyle := lipgloss.NewStyle().
+		Border(m.theme.border()).
+		BorderForeground(m.theme.Header).
+		Padding(1, 2).
+		Width(m.width - 4)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.Header)
+
+	preferredStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.Added)
+
+	variantStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Removed)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("grammr - Inconsistent Terms"))
+	content.WriteString("\n\n")
+	content.WriteString(fmt.Sprintf("%d term(s) are written in more than one way:\n\n", len(m.consistencyIssues)))
+	for _, issue := range m.consistencyIssues {
+		var variants []string
+		for _, v := range issue.Variants {
+			label := fmt.Sprintf("%s (%d)", v.Text, len(v.Occurrences))
+			if v.Text == issue.Preferred {
+				variants = append(variants, preferredStyle.Render(label))
+			} else {
+				variants = append(variants, variantStyle.Render(label))
+			}
+		}
+		content.WriteString("  " + strings.Join(variants, " · ") + " → " + preferredStyle.Render(issue.Preferred) + "\n")
+	}
+	content.WriteString("\n  Enter, N  Normalize all\n")
+	content.WriteString("  Esc       Close\n")
+
+	return reportStyle.Render(content.String())
+}
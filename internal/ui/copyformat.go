@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// markdownQuote quotes text as a Markdown blockquote
+func markdownQuote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlParagraphs turns text into HTML paragraphs, one per block of lines separated by a blank
+// line, keeping the line breaks within them
+func htmlParagraphs(text string) string {
+	var paragraphs []string
+	for _, block := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		block = strings.Trim(block, "\n")
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		lines := strings.Split(block, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		paragraphs = append(paragraphs, "<p>"+strings.Join(lines, "<br>\n")+"</p>")
+	}
+	return strings.Join(paragraphs, "\n")
+}
+
+// comparisonBlock puts the original text above the corrected one, for review feedback
+func comparisonBlock(original, corrected string) string {
+	return "Original:\n" + original + "\n\nCorrected:\n" + corrected
+}
+
+// openCopyMenu asks which format to copy the corrected text in
+func (m Model) openCopyMenu() (tea.Model, tea.Cmd) {
+	if m.isLoading || m.correctedText == "" {
+		return m, nil
+	}
+	m.mode = ModeCopyFormat
+	m.status = "Copy as: P: Plain text, M: Markdown quote, H: HTML, O: Original + corrected, Esc: Cancel"
+	return m, nil
+}
+
+func (m Model) handleCopyFormat(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var text, format string
+	switch msg.String() {
+	case "p", "P":
+		text, format = m.correctedText, "plain text"
+	case "m", "M":
+		text, format = markdownQuote(m.correctedText), "Markdown quote"
+	case "h", "H":
+		text, format = htmlParagraphs(m.correctedText), "HTML"
+	case "o", "O":
+		text, format = comparisonBlock(m.originalText, m.correctedText), "original + corrected"
+	case "esc", "q", "y", "Y":
+		m.mode = ModeGlobal
+		m.status = "Cancelled"
+		return m, nil
+	default:
+		return m, nil
+	}
+
+	m.mode = ModeGlobal
+	if err := m.clipboard.Copy(text); err != nil {
+		return m.notify(levelError, fmt.Sprintf("Failed to copy: %v", err))
+	}
+	m.status = fmt.Sprintf("✓ Copied as %s", format)
+	return m, nil
+}
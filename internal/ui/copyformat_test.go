@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/clipboard"
+)
+
+func TestMarkdownQuote(t *testing.T) {
+	got := markdownQuote("First line.\nSecond line.\n\nNew paragraph.")
+	want := "> First line.\n> Second line.\n>\n> New paragraph."
+	if got != want {
+		t.Errorf("markdownQuote() = %q, want %q", got, want)
+	}
+}
+
+func TestHTMLParagraphs(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "one paragraph",
+			text: "Fish & chips <3",
+			want: "<p>Fish &amp; chips &lt;3</p>",
+		},
+		{
+			name: "line breaks and paragraphs",
+			text: "Dear Ann,\nthanks.\n\n\nBest,\nBo",
+			want: "<p>Dear Ann,<br>\nthanks.</p>\n<p>Best,<br>\nBo</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlParagraphs(tt.text); got != tt.want {
+				t.Errorf("htmlParagraphs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyFormats(t *testing.T) {
+	tests := []struct {
+		key        string
+		want       string
+		wantStatus string
+	}{
+		{key: "p", want: "I have an apple.", wantStatus: "✓ Copied as plain text"},
+		{key: "m", want: "> I have an apple.", wantStatus: "✓ Copied as Markdown quote"},
+		{key: "h", want: "<p>I have an apple.</p>", wantStatus: "✓ Copied as HTML"},
+		{
+			key:        "o",
+			want:       "Original:\nI has a apple.\n\nCorrected:\nI have an apple.",
+			wantStatus: "✓ Copied as original + corrected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			m := newTestModel(t, newTestConfig())
+			m.originalText = "I has a apple."
+			m.correctedText = "I have an apple."
+			board := &clipboard.Memory{}
+			m.clipboard = board
+
+			m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+			if m.mode != ModeCopyFormat {
+				t.Fatalf("mode = %v, want the copy menu", m.mode)
+			}
+			m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+			if m.mode != ModeGlobal || m.status != tt.wantStatus {
+				t.Errorf("mode = %v, status = %q, want %q", m.mode, m.status, tt.wantStatus)
+			}
+			if board.Text != tt.want {
+				t.Errorf("copied %q, want %q", board.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyMenuCancel(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.correctedText = "I have an apple."
+	board := &clipboard.Memory{}
+	m.clipboard = board
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeGlobal || m.status != "Cancelled" || board.Text != "" {
+		t.Errorf("mode = %v, status = %q, copied %q", m.mode, m.status, board.Text)
+	}
+}
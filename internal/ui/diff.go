@@ -1,41 +1,446 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/maximbilan/grammr/internal/textdiff"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
+// ansiReset ends all styling of an ANSI escape sequence
+const ansiReset = "\x1b[0m"
+
+// diffOptions control how the original and corrected texts are compared. The zero value compares
+// characters and shows every change.
+type diffOptions struct {
+	words            bool // Compare whole words instead of characters
+	ignoreWhitespace bool // Leave out changes that only touch whitespace
+}
+
+// diffOptionsFor returns the diff options set in cfg
+func diffOptionsFor(cfg *config.Config) diffOptions {
+	return diffOptions{
+		words:            cfg.DiffGranularity == config.DiffWords,
+		ignoreWhitespace: cfg.DiffIgnoreWhitespace,
+	}
+}
+
+// label describes the options for the status line
+func (o diffOptions) label() string {
+	label := "Diff: characters"
+	if o.words {
+		label = "Diff: words"
+	}
+	if o.ignoreWhitespace {
+		label += ", ignoring whitespace"
+	}
+	return label
+}
+
+// asyncDiffLength is the combined length of two texts over which their diff is computed in the
+// background, see prepareDiff, instead of while the view is drawn
+const asyncDiffLength = 20000
+
+// diffCacheSize is how many diffs are kept, enough for the diff shown and the ones of the modes
+// the diff mode key cycles through
+const diffCacheSize = 4
+
+type diffKey struct {
+	original  string
+	corrected string
+	opts      diffOptions
+}
+
+// diffCache keeps the diffs computed last, since every redraw asks for the same one
+var diffCache = struct {
+	sync.Mutex
+	diffs   map[diffKey]*Diff
+	order   []diffKey        // Oldest first
+	pending map[diffKey]bool // Diffs being computed in the background
+}{
+	diffs:   make(map[diffKey]*Diff),
+	pending: make(map[diffKey]bool),
+}
+
+// diffReadyMsg reports that a diff computed in the background can be shown
+type diffReadyMsg struct{}
+
+// Diff is a correction compared with the text it corrects. It holds the segments the diff view
+// draws and the changes review mode goes through, paired from them once, so the diff, review mode,
+// its preview and the reviewed text always agree on what the changes are. Diffs are shared, so
+// they must not be changed.
+type Diff struct {
+	original  string
+	corrected string
+	segments  []diffmatchpatch.Diff
+	hunks     []hunk
+}
+
+// hunk is one change of a Diff: a removal, an addition, or a removal directly followed by the
+// addition that replaces it
+type hunk struct {
+	first, last  int // The segments the change covers
+	removed      string
+	added        string
+	originalPos  int // Where the change starts in the original text
+	correctedPos int // Where the change starts in the corrected text
+	category     string
+}
+
+// newDiff pairs the changes of segments, the diff of original and corrected, with the length of
+// the original text each segment stands for
+func newDiff(original, corrected string, segments []diffmatchpatch.Diff, originalLengths []int) *Diff {
+	d := &Diff{original: original, corrected: corrected, segments: segments}
+	originalPos, correctedPos := 0, 0
+	for i := 0; i < len(segments); i++ {
+		segment := segments[i]
+		if segment.Type == diffmatchpatch.DiffEqual {
+			// Unchanged text may have taken the whitespace of the corrected text
+			originalPos += originalLengths[i]
+			correctedPos += len(segment.Text)
+			continue
+		}
+
+		h := hunk{first: i, last: i, originalPos: originalPos, correctedPos: correctedPos}
+		if segment.Type == diffmatchpatch.DiffDelete {
+			h.removed = segment.Text
+			if i+1 < len(segments) && segments[i+1].Type == diffmatchpatch.DiffInsert {
+				i++
+				h.last, h.added = i, segments[i].Text
+			}
+		} else {
+			h.added = segment.Text
+		}
+		// A change inside a word is classified by the whole word
+		prefix, suffix := wordContext(segments, h.first, h.last)
+		h.category = corrector.ClassifyChange(prefix+h.removed+suffix, prefix+h.added+suffix)
+
+		originalPos += len(h.removed)
+		correctedPos += len(h.added)
+		d.hunks = append(d.hunks, h)
+	}
+	return d
+}
+
+// changes returns the changes to review, with no decisions made yet
+func (d *Diff) changes() []DiffChange {
+	changes := make([]DiffChange, 0, len(d.hunks))
+	for _, h := range d.hunks {
+		op := diffmatchpatch.DiffDelete
+		if h.removed == "" {
+			op = diffmatchpatch.DiffInsert
+		}
+		changes = append(changes, DiffChange{
+			Type:     op,
+			Removed:  h.removed,
+			Added:    h.added,
+			Category: h.category,
+		})
+	}
+	return changes
+}
+
+// reviewedText returns the original text with the changes that were applied, taking the decision
+// for each change from changes at the same index. The original is kept where a change was skipped
+// or not decided yet.
+func (d *Diff) reviewedText(changes []DiffChange) string {
+	var result strings.Builder
+	next := 0 // The first segment not written yet
+	for i, h := range d.hunks {
+		for _, segment := range d.segments[next:h.first] {
+			result.WriteString(segment.Text)
+		}
+		next = h.last + 1
+		if i < len(changes) && changes[i].Applied {
+			result.WriteString(changes[i].appliedText(h.added))
+		} else {
+			result.WriteString(h.removed)
+		}
+	}
+	for _, segment := range d.segments[next:] {
+		result.WriteString(segment.Text)
+	}
+	return result.String()
+}
+
+// context returns the sentence surrounding the change at changeIdx and the span the change
+// introduces. For pure deletions the removed text and its sentence in the original are returned
+// instead.
+func (d *Diff) context(changeIdx int) (sentence, span string) {
+	if changeIdx < 0 || changeIdx >= len(d.hunks) {
+		return "", ""
+	}
+	h := d.hunks[changeIdx]
+	if h.added == "" {
+		return sentenceAround(d.original, h.originalPos, h.originalPos+len(h.removed)), h.removed
+	}
+	return sentenceAround(d.corrected, h.correctedPos, h.correctedPos+len(h.added)), h.added
+}
+
+// diff returns the diff of the corrected text against the text it was made from
+func (m Model) diff() *Diff {
+	return computeDiff(m.diffBase(), m.correctedText, diffOptionsFor(m.config))
+}
+
+// computeDiff compares original with corrected. Every view of the changes goes through it, and the
+// diff is kept, so a correction is only compared once.
+func computeDiff(original, corrected string, opts diffOptions) *Diff {
+	key := diffKey{original: original, corrected: corrected, opts: opts}
+	diffCache.Lock()
+	d, ok := diffCache.diffs[key]
+	diffCache.Unlock()
+	if ok {
+		return d
+	}
+
+	segments, originalLengths := textdiff.ComputeLengths(original, corrected, textdiff.Options{
+		Words:            opts.words,
+		IgnoreWhitespace: opts.ignoreWhitespace,
+	})
+	d = newDiff(original, corrected, segments, originalLengths)
+
+	diffCache.Lock()
+	defer diffCache.Unlock()
+	delete(diffCache.pending, key)
+	if _, ok := diffCache.diffs[key]; !ok {
+		if len(diffCache.order) == diffCacheSize {
+			delete(diffCache.diffs, diffCache.order[0])
+			diffCache.order = diffCache.order[1:]
+		}
+		diffCache.diffs[key] = d
+		diffCache.order = append(diffCache.order, key)
+	}
+	return d
+}
+
+// diffReady reports whether the diff of original and corrected can be drawn without holding up
+// the view: it was computed already, or the texts are short
+func diffReady(original, corrected string, opts diffOptions) bool {
+	if len(original)+len(corrected) <= asyncDiffLength {
+		return true
+	}
+	diffCache.Lock()
+	defer diffCache.Unlock()
+	_, ok := diffCache.diffs[diffKey{original: original, corrected: corrected, opts: opts}]
+	return ok
+}
+
+// prepareDiff computes the diff of a long correction in the background, so the view can show it
+// once it is ready
+func (m Model) prepareDiff() tea.Cmd {
+	original, corrected, opts := m.diffBase(), m.correctedText, diffOptionsFor(m.config)
+	// A streaming correction changes too often for its diff to be worth computing
+	if m.isLoading || original == "" || corrected == "" || diffReady(original, corrected, opts) {
+		return nil
+	}
+	key := diffKey{original: original, corrected: corrected, opts: opts}
+	diffCache.Lock()
+	defer diffCache.Unlock()
+	if diffCache.pending[key] {
+		return nil
+	}
+	diffCache.pending[key] = true
+	return func() tea.Msg {
+		computeDiff(original, corrected, opts)
+		return diffReadyMsg{}
+	}
+}
+
+// diffModes are the options the diff mode key cycles through
+var diffModes = []diffOptions{
+	{words: true},
+	{words: true, ignoreWhitespace: true},
+	{},
+	{ignoreWhitespace: true},
+}
+
+// switchDiffMode cycles between word and character diffs, with and without whitespace changes,
+// and saves the choice
+func (m Model) switchDiffMode() (tea.Model, tea.Cmd) {
+	current := diffOptionsFor(m.config)
+	next := diffModes[0]
+	for i, mode := range diffModes {
+		if mode == current && i+1 < len(diffModes) {
+			next = diffModes[i+1]
+		}
+	}
+
+	m.config.DiffGranularity = config.DiffChars
+	if next.words {
+		m.config.DiffGranularity = config.DiffWords
+	}
+	m.config.DiffIgnoreWhitespace = next.ignoreWhitespace
+	m.status = next.label()
+	return m.saveConfig()
+}
+
+// wrapStyled wraps styled text to width, breaking between words where it can, without splitting
+// escape sequences. Every line closes its own styling and the next one reopens it, so colors don't
+// leak into the box border or get lost when the pane scrolls.
+func wrapStyled(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	lines := strings.Split(wrap.String(wordwrap.String(text, width), width), "\n")
+
+	active := "" // Escape sequences in effect at the end of the previous line
+	for i, line := range lines {
+		open := active
+		for rest := line; ; {
+			start := strings.Index(rest, "\x1b[")
+			if start < 0 {
+				break
+			}
+			end := strings.IndexFunc(rest[start+2:], func(r rune) bool { return r >= 0x40 && r <= 0x7e })
+			if end < 0 {
+				break
+			}
+			seq := rest[start : start+2+end+1]
+			if seq == ansiReset || seq == "\x1b[m" {
+				active = ""
+			} else if seq[len(seq)-1] == 'm' {
+				active += seq
+			}
+			rest = rest[start+len(seq):]
+		}
+		if active != "" {
+			line += ansiReset
+		}
+		lines[i] = open + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffSummary counts the words diffs add and remove, and the changes they make. A change that only
+// touches punctuation counts as one word.
+func diffSummary(diffs []diffmatchpatch.Diff) (added, removed, changes int) {
+	words := func(text string) int {
+		if strings.TrimSpace(text) == "" {
+			return 0
+		}
+		return max(countText(text).words, 1)
+	}
+	inChange := false
+	for _, diff := range diffs {
+		switch diff.Type {
+		case diffmatchpatch.DiffEqual:
+			inChange = false
+			continue
+		case diffmatchpatch.DiffInsert:
+			added += words(diff.Text)
+		case diffmatchpatch.DiffDelete:
+			removed += words(diff.Text)
+		}
+		if !inChange {
+			changes++
+			inChange = true
+		}
+	}
+	return added, removed, changes
+}
+
+// changeBadge sums up the changes next to the corrected text label, such as "+12 −7 in 9
+// changes", and says so when the text needed none
+func (m Model) changeBadge() string {
+	if m.isLoading || m.diffBase() == "" || m.correctedText == "" {
+		return ""
+	}
+	if !diffReady(m.diffBase(), m.correctedText, diffOptionsFor(m.config)) {
+		return ""
+	}
+	added, removed, changes := diffSummary(m.diff().segments)
+	if changes == 0 {
+		return lipgloss.NewStyle().
+			Foreground(m.theme.Corrected).
+			Bold(true).
+			Render(" ✓ No changes needed")
+	}
+	noun := "changes"
+	if changes == 1 {
+		noun = "change"
+	}
+	return " " + lipgloss.NewStyle().Foreground(m.theme.Added).Render(fmt.Sprintf("+%d", added)) +
+		" " + lipgloss.NewStyle().Foreground(m.theme.Removed).Render(fmt.Sprintf("−%d", removed)) +
+		lipgloss.NewStyle().Foreground(m.theme.Muted).Render(fmt.Sprintf(" in %d %s", changes, noun))
+}
+
 func renderDiff(original, corrected string) string {
-	dmp := diffmatchpatch.New()
-	diffs := dmp.DiffMain(original, corrected, false)
-	// Clean up the diff to make it more semantic (word-level rather than character-level)
-	diffs = dmp.DiffCleanupSemantic(diffs)
+	return renderDiffWithViolations(original, corrected, nil, themes[ThemeDark], diffOptions{})
+}
+
+// renderDiffWithViolations renders the diff in the colors of theme and highlights glossary
+// violations, given as byte offsets into the corrected text
+func renderDiffWithViolations(original, corrected string, violations []glossary.Violation, theme Theme, opts diffOptions) string {
+	diffs := computeDiff(original, corrected, opts).segments
 
 	var styled strings.Builder
+	pos := 0 // Position in the corrected text
 	for _, diff := range diffs {
 		switch diff.Type {
 		case diffmatchpatch.DiffDelete:
+			open, close := theme.removedMarkers()
 			styled.WriteString(
 				lipgloss.NewStyle().
-					Foreground(lipgloss.Color("9")).
+					Foreground(theme.Removed).
 					Strikethrough(true).
-					Render(diff.Text),
+					Render(open + diff.Text + close),
 			)
 		case diffmatchpatch.DiffInsert:
-			styled.WriteString(
-				lipgloss.NewStyle().
-					Foreground(lipgloss.Color("10")).
-					Render(diff.Text),
-			)
+			open, close := theme.addedMarkers()
+			style := lipgloss.NewStyle().
+				Foreground(theme.Added)
+			if open != "" {
+				styled.WriteString(style.Render(open))
+			}
+			styled.WriteString(renderWithViolations(diff.Text, pos, violations, theme, style))
+			if close != "" {
+				styled.WriteString(style.Render(close))
+			}
+			pos += len(diff.Text)
 		case diffmatchpatch.DiffEqual:
-			styled.WriteString(
+			styled.WriteString(renderWithViolations(diff.Text, pos, violations, theme,
 				lipgloss.NewStyle().
-					Foreground(lipgloss.Color("8")).
-					Render(diff.Text),
-			)
+					Foreground(theme.Muted),
+			))
+			pos += len(diff.Text)
+		}
+	}
+	return styled.String()
+}
+
+// renderWithViolations renders text that starts at offset in the corrected text, highlighting the
+// parts covered by violations
+func renderWithViolations(text string, offset int, violations []glossary.Violation, theme Theme, style lipgloss.Style) string {
+	violationStyle := lipgloss.NewStyle().
+		Foreground(theme.Highlight).
+		Underline(true)
+
+	var styled strings.Builder
+	start := 0
+	for _, v := range violations {
+		vStart, vEnd := v.Start-offset, v.End-offset
+		if vEnd <= start || vStart >= len(text) {
+			continue
+		}
+		if vStart < start {
+			vStart = start
+		}
+		if vEnd > len(text) {
+			vEnd = len(text)
 		}
+		styled.WriteString(style.Render(text[start:vStart]))
+		styled.WriteString(violationStyle.Render(text[vStart:vEnd]))
+		start = vEnd
 	}
+	styled.WriteString(style.Render(text[start:]))
 	return styled.String()
 }
@@ -3,6 +3,11 @@ package ui
 import (
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 func TestRenderDiff(t *testing.T) {
@@ -135,3 +140,310 @@ func TestRenderDiffEdgeCases(t *testing.T) {
 		t.Error("renderDiff() should handle long strings")
 	}
 }
+
+func TestRenderDiffWithViolations(t *testing.T) {
+	corrected := "Please login to Kubernetes."
+	violations := []glossary.Violation{{Start: 7, End: 12, Found: "login", Preferred: "sign in"}}
+
+	result := renderDiffWithViolations("Please login to kubernetes.", corrected, violations, themes[ThemeDark], diffOptions{})
+	if clean := removeANSICodes(result); !strings.Contains(clean, "Please login to ") || !strings.Contains(clean, "Kubernetes.") {
+		t.Fatalf("renderDiffWithViolations() lost text: %q", clean)
+	}
+
+	highlighted := renderWithViolations(corrected, 0, violations, themes[ThemeDark], lipgloss.NewStyle())
+	if removeANSICodes(highlighted) != corrected {
+		t.Fatalf("renderWithViolations() = %q, want the text unchanged", removeANSICodes(highlighted))
+	}
+
+	// Violations outside the segment are ignored
+	segment := renderWithViolations("to Kubernetes.", 13, violations, themes[ThemeDark], lipgloss.NewStyle())
+	if removeANSICodes(segment) != "to Kubernetes." {
+		t.Fatalf("renderWithViolations() = %q", removeANSICodes(segment))
+	}
+}
+
+func TestRenderDiffMarkers(t *testing.T) {
+	original, corrected := "The fox jumps over the dog", "The fox jumped over a dog"
+	opts := diffOptions{words: true}
+
+	got := removeANSICodes(renderDiffWithViolations(original, corrected, nil, themes[ThemeColorBlind], opts))
+	if want := "The fox [-jumps-]{+jumped+} over [-the-]{+a+} dog"; got != want {
+		t.Errorf("renderDiffWithViolations() = %q, want %q", got, want)
+	}
+
+	got = removeANSICodes(renderDiffWithViolations(original, corrected, nil, themes[ThemeDark], opts))
+	if want := "The fox jumpsjumped over thea dog"; got != want {
+		t.Errorf("renderDiffWithViolations() = %q, want %q without markers", got, want)
+	}
+}
+
+func TestComputeDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		original  string
+		corrected string
+		opts      diffOptions
+		want      string
+	}{
+		{
+			name:      "characters",
+			original:  "The fox jumps over the dog",
+			corrected: "The fox jumped over the dog",
+			want:      "The fox jump[-s-]{+ed+} over the dog",
+		},
+		{
+			name:      "words",
+			original:  "The fox jumps over the dog",
+			corrected: "The fox jumped over the dog",
+			opts:      diffOptions{words: true},
+			want:      "The fox [-jumps-]{+jumped+} over the dog",
+		},
+		{
+			name:      "words with punctuation",
+			original:  "Hello world I has a apple",
+			corrected: "Hello, world I have an apple.",
+			opts:      diffOptions{words: true},
+			want:      "Hello{+,+} world I [-has-]{+have+} [-a-]{+an+} apple{+.+}",
+		},
+		{
+			name:      "whitespace shown",
+			original:  "Hello  world.Bye",
+			corrected: "Hello world. Bye",
+			opts:      diffOptions{words: true},
+			want:      "Hello[-  -]{+ +}world.{+ +}Bye",
+		},
+		{
+			name:      "whitespace ignored",
+			original:  "Hello  world.Bye",
+			corrected: "Hello world. Bye",
+			opts:      diffOptions{words: true, ignoreWhitespace: true},
+			want:      "Hello world. Bye",
+		},
+		{
+			name:      "whitespace that is part of a change",
+			original:  "I has  a apple",
+			corrected: "I have a apple",
+			opts:      diffOptions{words: true, ignoreWhitespace: true},
+			want:      "I [-has  -]{+have +}a apple",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got strings.Builder
+			for _, diff := range computeDiff(tt.original, tt.corrected, tt.opts).segments {
+				switch diff.Type {
+				case diffmatchpatch.DiffDelete:
+					got.WriteString("[-" + diff.Text + "-]")
+				case diffmatchpatch.DiffInsert:
+					got.WriteString("{+" + diff.Text + "+}")
+				default:
+					got.WriteString(diff.Text)
+				}
+			}
+			if got.String() != tt.want {
+				t.Errorf("computeDiff() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapStyled(t *testing.T) {
+	const (
+		red   = "\x1b[31m"
+		green = "\x1b[32m"
+		reset = "\x1b[0m"
+	)
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{
+			name:  "short text",
+			text:  red + "hello" + reset,
+			width: 20,
+			want:  red + "hello" + reset,
+		},
+		{
+			name:  "style continues on the next line",
+			text:  red + "one two three" + reset,
+			width: 8,
+			want:  red + "one two" + reset + "\n" + red + "three" + reset,
+		},
+		{
+			name:  "breaks between styled words",
+			text:  red + "one" + reset + " " + green + "two" + reset,
+			width: 4,
+			want:  red + "one" + reset + "\n" + green + "two" + reset,
+		},
+		{
+			name:  "long word",
+			text:  green + "abcdefgh" + reset,
+			width: 4,
+			want:  green + "abcd" + reset + "\n" + green + "efgh" + reset,
+		},
+		{
+			name:  "no width",
+			text:  red + "one two" + reset,
+			width: 0,
+			want:  red + "one two" + reset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapStyled(tt.text, tt.width); got != tt.want {
+				t.Errorf("wrapStyled() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		original    string
+		corrected   string
+		wantAdded   int
+		wantRemoved int
+		wantChanges int
+	}{
+		{name: "identical", original: "I have an apple.", corrected: "I have an apple."},
+		{name: "replaced words", original: "I has a apple", corrected: "I have an apple", wantAdded: 2, wantRemoved: 2, wantChanges: 2},
+		{name: "added punctuation", original: "Hello world", corrected: "Hello, world.", wantAdded: 2, wantChanges: 2},
+		{name: "removed words", original: "It is very very good", corrected: "It is very good", wantRemoved: 1, wantChanges: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, changes := diffSummary(computeDiff(tt.original, tt.corrected, diffOptions{words: true}).segments)
+			if added != tt.wantAdded || removed != tt.wantRemoved || changes != tt.wantChanges {
+				t.Errorf("diffSummary() = +%d −%d in %d, want +%d −%d in %d",
+					added, removed, changes, tt.wantAdded, tt.wantRemoved, tt.wantChanges)
+			}
+		})
+	}
+}
+
+func TestChangeBadge(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.DiffGranularity = config.DiffWords
+	m := newTestModel(t, cfg)
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	if got := removeANSICodes(m.changeBadge()); got != " +2 −2 in 2 changes" {
+		t.Errorf("changeBadge() = %q", got)
+	}
+
+	m.correctedText = m.originalText
+	if got := removeANSICodes(m.changeBadge()); got != " ✓ No changes needed" {
+		t.Errorf("changeBadge() = %q", got)
+	}
+	if !strings.Contains(m.View(), "No changes needed") {
+		t.Error("view should say that no changes were needed")
+	}
+
+	m.isLoading = true
+	if got := m.changeBadge(); got != "" {
+		t.Errorf("changeBadge() = %q while correcting, want empty", got)
+	}
+}
+
+func TestPrepareDiff(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.showDiff = true
+	m.originalText = strings.Repeat("Their going too the store. ", 1000)
+	m.correctedText = strings.Repeat("They're going to the store. ", 1000)
+	m.width, m.height = 100, 40
+	opts := diffOptionsFor(m.config)
+
+	if diffReady(m.originalText, m.correctedText, opts) {
+		t.Fatal("diffReady() = true for a long diff that was never computed")
+	}
+	if got := m.paneContent(paneCorrected, 80); !strings.Contains(got, "Comparing with the original") {
+		t.Errorf("paneContent() = %q, want the plain text while the diff is computed", got[:80])
+	}
+
+	cmd := m.prepareDiff()
+	if cmd == nil {
+		t.Fatal("prepareDiff() should compute a long diff in the background")
+	}
+	if m.prepareDiff() != nil {
+		t.Error("prepareDiff() should not compute a diff that is already being computed")
+	}
+	if _, ok := cmd().(diffReadyMsg); !ok {
+		t.Fatal("the diff command should report that the diff is ready")
+	}
+	if !diffReady(m.originalText, m.correctedText, opts) {
+		t.Fatal("diffReady() = false after the diff was computed")
+	}
+	if m.prepareDiff() != nil {
+		t.Error("prepareDiff() should not compute a cached diff again")
+	}
+	if got := m.paneContent(paneCorrected, 80); strings.Contains(got, "Comparing with the original") {
+		t.Error("paneContent() should show the diff once it is ready")
+	}
+
+	// Short diffs are drawn right away
+	if !diffReady("I has", "I have", opts) {
+		t.Error("diffReady() = false for a short diff")
+	}
+}
+
+func TestDiffChanges(t *testing.T) {
+	original := "She have a apple. It are red."
+	corrected := "She has an apple. It is red."
+	d := computeDiff(original, corrected, diffOptions{words: true})
+
+	changes := d.changes()
+	if len(changes) != 3 {
+		t.Fatalf("changes() = %+v, want 3 changes", changes)
+	}
+	if changes[1].Removed != "a" || changes[1].Added != "an" {
+		t.Errorf("changes()[1] = %q → %q, want %q → %q", changes[1].Removed, changes[1].Added, "a", "an")
+	}
+	if got := d.reviewedText(changes); got != original {
+		t.Errorf("reviewedText() = %q without decisions, want the original", got)
+	}
+	for i := range changes {
+		changes[i].Applied = true
+	}
+	if got := d.reviewedText(changes); got != corrected {
+		t.Errorf("reviewedText() = %q with every change applied, want the correction", got)
+	}
+
+	if sentence, span := d.context(2); sentence != "It is red." || span != "is" {
+		t.Errorf("context(2) = %q, %q, want %q, %q", sentence, span, "It is red.", "is")
+	}
+
+	// The preview marks the same changes review mode goes through
+	m := newTestModel(t, newTestConfig())
+	m.originalText, m.correctedText = original, corrected
+	m.config.DiffGranularity = config.DiffWords
+	m.diffChanges = changes
+	m.diffChanges[2].Applied = false
+	m.currentChange = 2
+	if got := removeANSICodes(m.renderReviewPreview()); got != "She has an apple. It areis red." {
+		t.Errorf("renderReviewPreview() = %q", got)
+	}
+}
+
+func TestDiffContextIgnoringWhitespace(t *testing.T) {
+	// The ignored whitespace change makes the original longer than the unchanged text that
+	// stands for it
+	original := "a b. Remove this."
+	d := computeDiff(original, "a          b.", diffOptions{words: true, ignoreWhitespace: true})
+
+	changes := d.changes()
+	if len(changes) != 1 || changes[0].Removed != " Remove this." || changes[0].Added != "" {
+		t.Fatalf("changes() = %+v, want the removed sentence", changes)
+	}
+	if sentence, span := d.context(0); sentence != "Remove this." || span != " Remove this." {
+		t.Errorf("context(0) = %q, %q, want %q, %q", sentence, span, "Remove this.", " Remove this.")
+	}
+	if got := d.reviewedText(changes); got != "a          b. Remove this." {
+		t.Errorf("reviewedText() = %q without decisions, want the original with the corrected whitespace", got)
+	}
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/corrector"
+)
+
+// correctDifferently corrects the original text again, asking for a different phrasing than the
+// last correction. Unlike R it never gives the same result twice; the previous result stays a
+// Ctrl+Z away to compare with.
+func (m Model) correctDifferently() (tea.Model, tea.Cmd) {
+	if m.isLoading || m.correctionResult == "" {
+		return m, nil
+	}
+	m, ok := m.ensureServices()
+	if !ok {
+		return m, nil
+	}
+	if corrector.NeedsChunking(m.originalText) {
+		m.status = "The text is too long to correct differently, press R to correct it again"
+		return m, nil
+	}
+
+	return m.confirmDiscardEdits(func(m Model) (tea.Model, tea.Cmd) {
+		m = m.saveUndo()
+		m.isLoading = true
+		m.isTranslating = false
+		m.translatedText = ""
+		m.translationEditor.SetValue("")
+		m.status = "[●] Correcting differently..."
+		m.estimate = m.correctionEstimate(m.originalText).String()
+
+		text, previous := m.originalText, m.correctionResult
+		return m, func() tea.Msg {
+			ctx, cancel := m.requestContext(m.correctionTimeout(text))
+			defer cancel()
+
+			var corrected strings.Builder
+			err := m.corrector.StreamCorrectDifferently(ctx, text, previous, func(chunk string) {
+				corrected.WriteString(chunk)
+			})
+			if err != nil {
+				return errMsg{err: err}
+			}
+			// Not cached: the next paste of the text should get the usual correction
+			return correctionDoneMsg{
+				original:  text,
+				corrected: trimTrailingWhitespace(corrected.String()),
+				different: true,
+			}
+		}
+	})
+}
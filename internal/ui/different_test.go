@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCorrectDifferently(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	ctrlR := tea.KeyMsg{Type: tea.KeyCtrlR}
+
+	// Nothing corrected yet
+	m = pressKey(t, m, ctrlR)
+	if m.isLoading {
+		t.Fatal("Ctrl+R without a correction should do nothing")
+	}
+
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m.correctionResult = "I have an apple."
+	next, cmd := m.Update(ctrlR)
+	m = next.(Model)
+	if !m.isLoading || cmd == nil || !strings.Contains(m.status, "Correcting differently") {
+		t.Fatalf("isLoading = %v, status = %q, want a new correction", m.isLoading, m.status)
+	}
+
+	next, _ = m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I own an apple.", different: true})
+	m = next.(Model)
+	if m.correctedText != "I own an apple." || !strings.HasPrefix(m.status, "✓ Corrected differently") {
+		t.Fatalf("correctedText = %q, status = %q", m.correctedText, m.status)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if m.correctedText != "I have an apple." {
+		t.Fatalf("Ctrl+Z should bring back the previous correction, got %q", m.correctedText)
+	}
+}
+
+func TestCorrectDifferentlyAsksBeforeDiscardingEdits(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple."
+	m.correctionResult = "I have an apple."
+	m.correctedText = "I have a red apple."
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.confirmation == nil || m.isLoading {
+		t.Fatal("Ctrl+R should ask before replacing edits")
+	}
+}
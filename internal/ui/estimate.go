@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/estimate"
+)
+
+// correctionEstimate estimates the tokens and cost of correcting text with the configured model
+func (m Model) correctionEstimate(text string) estimate.Estimate {
+	return estimate.ForCorrection(m.config.Model, text)
+}
+
+// confirmOrCorrect starts correcting text, or asks first when the request is larger than the
+// confirm_tokens threshold so a huge paste doesn't burn through the budget by accident
+func (m Model) confirmOrCorrect(text string) (tea.Model, tea.Cmd) {
+	m, ok := m.ensureServices()
+	if !ok {
+		return m, nil
+	}
+	est := m.correctionEstimate(text)
+	m.estimate = est.String()
+	if m.config.ConfirmTokens > 0 && est.InputTokens > m.config.ConfirmTokens {
+		m.mode = ModeConfirmSend
+		m.isLoading = false
+		m.status = fmt.Sprintf("Send %s? (y/n)", m.estimate)
+		return m, nil
+	}
+
+	m.isLoading = true
+	m.status = "[●] Correcting..."
+	return m, m.streamCorrection(text)
+}
+
+func (m Model) handleConfirmSend(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.mode = ModeGlobal
+		m.isLoading = true
+		m.status = "[●] Correcting..."
+		return m, m.streamCorrection(m.originalText)
+	case "n", "N", "esc", "q":
+		m.mode = ModeGlobal
+		m.status = "Cancelled"
+		return m, nil
+	}
+	return m, nil
+}
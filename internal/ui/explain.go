@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// explanationMsg carries why a change of the review was made
+type explanationMsg struct {
+	key  string
+	text string
+}
+
+// explanationKey identifies a change by its sentence, so an explanation is asked for once even
+// when the same change is reviewed again
+func (m Model) explanationKey(changeIdx int) (key, sentence, before, after string) {
+	sentence, _ = m.diff().context(changeIdx)
+	before, after = m.diffChanges[changeIdx].Removed, m.diffChanges[changeIdx].Added
+	return sentence + "\x00" + before + "\x00" + after, sentence, before, after
+}
+
+// explainChange asks the provider why the current change was made
+func (m Model) explainChange() (tea.Model, tea.Cmd) {
+	if m.currentChange >= len(m.diffChanges) || m.isExplaining {
+		return m, nil
+	}
+	key, sentence, before, after := m.explanationKey(m.currentChange)
+	if _, ok := m.explanations[key]; ok {
+		return m, nil
+	}
+
+	m, ok := m.ensureServices()
+	if !ok {
+		return m, nil
+	}
+	m.isExplaining = true
+	m.status = "[●] Explaining the change..."
+	return m, func() tea.Msg {
+		ctx, cancel := m.requestContext(m.correctionTimeout(sentence))
+		defer cancel()
+
+		text, err := m.corrector.ExplainChange(ctx, sentence, before, after)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to explain the change: %w", err)}
+		}
+		return explanationMsg{key: key, text: text}
+	}
+}
+
+// addExplanation keeps an explanation for when its change is shown
+func (m Model) addExplanation(msg explanationMsg) Model {
+	m.isExplaining = false
+	if m.explanations == nil {
+		m.explanations = make(map[string]string)
+	}
+	m.explanations[msg.key] = msg.text
+	if m.mode == ModeReviewDiff {
+		m.status = reviewStatus(m.currentChange, len(m.diffChanges))
+	}
+	return m
+}
+
+// renderExplanation shows why the current change was made, once it was asked for
+func (m Model) renderExplanation() string {
+	if m.currentChange >= len(m.diffChanges) {
+		return ""
+	}
+	key, _, _, _ := m.explanationKey(m.currentChange)
+	text, ok := m.explanations[key]
+	switch {
+	case ok:
+		return "\n\n" + lipgloss.NewStyle().
+			Bold(true).
+			Foreground(m.theme.Header).
+			Render("Why: ") + text
+	case m.isExplaining:
+		return "\n\n" + lipgloss.NewStyle().
+			Foreground(m.theme.Highlight).
+			Italic(true).
+			Render("Explaining...")
+	}
+	return ""
+}
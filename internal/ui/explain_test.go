@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/config"
+)
+
+func TestReviewModeExplanation(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.DiffGranularity = config.DiffWords
+	m := newTestModel(t, cfg)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = next.(Model)
+	m.originalText = "She have two apples."
+	m.correctedText = "She has two apples."
+	m.diffChanges = computeDiff(m.originalText, m.correctedText, diffOptionsFor(m.config)).changes()
+	m.mode = ModeReviewDiff
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m = next.(Model)
+	if !m.isExplaining || cmd == nil {
+		t.Fatal("pressing W should ask why the change was made")
+	}
+	if !strings.Contains(m.View(), "Explaining...") {
+		t.Error("view should show that the explanation is on its way")
+	}
+
+	key, sentence, before, after := m.explanationKey(0)
+	if sentence != "She has two apples." || before != "have" || after != "has" {
+		t.Fatalf("explanationKey() = %q, %q, %q", sentence, before, after)
+	}
+	next, _ = m.Update(explanationMsg{key: key, text: "A singular subject takes has."})
+	m = next.(Model)
+	if m.isExplaining || m.status != reviewStatus(0, len(m.diffChanges)) {
+		t.Fatalf("isExplaining = %v, status = %q", m.isExplaining, m.status)
+	}
+	if !strings.Contains(m.View(), "Why: A singular subject takes has.") {
+		t.Errorf("view should show the explanation:\n%s", m.View())
+	}
+
+	// The explanation is kept, so asking again costs nothing
+	if _, cmd := m.explainChange(); cmd != nil {
+		t.Fatal("W should not ask again for a change that was explained")
+	}
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultEditor is run when neither $VISUAL nor $EDITOR is set
+const defaultEditor = "vi"
+
+// externalEditDoneMsg carries the text of a pane after it was edited in an external editor
+type externalEditDoneMsg struct {
+	pane pane
+	text string
+	err  error
+}
+
+// editorCommand returns the command that opens path in the user's editor. The editor setting may
+// include arguments, such as "code --wait".
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if strings.TrimSpace(editor) == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{defaultEditor}
+	}
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// paneText returns the text shown in a pane
+func (m Model) paneText(p pane) string {
+	switch p {
+	case paneCorrected:
+		return m.correctedText
+	case paneTranslation:
+		return m.translatedText
+	}
+	return m.originalText
+}
+
+// openExternalEditor suspends the TUI and opens the text of the focused pane in $EDITOR
+func (m Model) openExternalEditor() (tea.Model, tea.Cmd) {
+	p := m.focusedPane
+	if p == paneTranslation && m.translator == nil {
+		p = paneOriginal
+	}
+	if m.isLoading || m.paneText(p) == "" {
+		return m, nil
+	}
+
+	file, err := os.CreateTemp("", "grammr-*.txt")
+	if err != nil {
+		return m, func() tea.Msg { return errMsg{err: fmt.Errorf("failed to create temporary file: %w", err)} }
+	}
+	path := file.Name()
+	_, err = file.WriteString(m.paneText(p))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return m, func() tea.Msg { return errMsg{err: fmt.Errorf("failed to write temporary file: %w", err)} }
+	}
+
+	return m, tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return externalEditDoneMsg{pane: p, err: err}
+		}
+		data, err := os.ReadFile(path)
+		return externalEditDoneMsg{pane: p, text: string(data), err: err}
+	})
+}
+
+// applyExternalEdit puts the text edited in the external editor back into its pane
+func (m Model) applyExternalEdit(msg externalEditDoneMsg) Model {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("✗ Editor failed: %v", msg.err)
+		return m
+	}
+	text := trimTrailingWhitespace(msg.text)
+	if text == m.paneText(msg.pane) {
+		m.status = "No changes"
+		return m
+	}
+
+	m = m.saveUndo()
+	m.status = fmt.Sprintf("✓ %s updated", paneNames[msg.pane])
+	switch msg.pane {
+	case paneCorrected:
+		m.correctedText = text
+		m.correctedEditor.SetValue(text)
+	case paneTranslation:
+		m.translatedText = text
+		m.translationEditor.SetValue(text)
+	default:
+		m.originalText = text
+		m.originalEditor.SetValue(text)
+		m = m.applySourceLanguage(text)
+		m.status += " (R to correct it again)"
+	}
+	return m
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		visual string
+		editor string
+		want   []string
+	}{
+		{name: "default", want: []string{"vi", "/tmp/text.txt"}},
+		{name: "editor", editor: "nano", want: []string{"nano", "/tmp/text.txt"}},
+		{name: "visual wins", visual: "hx", editor: "nano", want: []string{"hx", "/tmp/text.txt"}},
+		{name: "arguments", editor: "code --wait", want: []string{"code", "--wait", "/tmp/text.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+			if got := editorCommand("/tmp/text.txt").Args; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("editorCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExternalEdit(t *testing.T) {
+	// The command isn't run, so keep its temporary file out of the way
+	t.Setenv("TMPDIR", t.TempDir())
+	m := newTestModel(t, newTestConfig())
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlE}); cmd != nil {
+		t.Fatal("Ctrl+E should do nothing without a text")
+	}
+
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlE}); cmd == nil {
+		t.Fatal("Ctrl+E should open the editor")
+	}
+
+	nextAny, _ := m.Update(externalEditDoneMsg{pane: paneCorrected, text: "I have a red apple.\n"})
+	m = nextAny.(Model)
+	if m.correctedText != "I have a red apple." || m.correctedEditor.Value() != "I have a red apple." {
+		t.Fatalf("correctedText = %q, want the edited text", m.correctedText)
+	}
+	m = pressKey(t, m, ctrlZ)
+	if m.correctedText != "I have an apple." {
+		t.Fatalf("undo should bring back the text before the edit, got %q", m.correctedText)
+	}
+
+	nextAny, _ = m.Update(externalEditDoneMsg{pane: paneOriginal, err: errors.New("exit status 1")})
+	m = nextAny.(Model)
+	if m.originalText != "I has a apple." || !strings.Contains(m.status, "Editor failed") {
+		t.Fatalf("original = %q, status = %q", m.originalText, m.status)
+	}
+}
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/history"
+)
+
+// recordHistory adds the current correction to the history. Like the cache, a failed write is
+// not worth interrupting the user for.
+func (m Model) recordHistory() {
+	_ = m.history.Add(history.Entry{
+		Original:    m.originalText,
+		Corrected:   m.correctedText,
+		Translation: m.translatedText,
+		Style:       m.config.Style,
+	})
+}
+
+// recordTranslation adds the translation to the history entry of the current correction
+func (m Model) recordTranslation() {
+	_ = m.history.SetTranslation(m.originalText, m.correctedText, m.translatedText)
+}
+
+// openHistory shows the list of past corrections
+func (m Model) openHistory() (tea.Model, tea.Cmd) {
+	if m.history == nil {
+		m.status = "History is turned off (history_enabled)"
+		return m, nil
+	}
+	m.historyEntries = m.history.Entries()
+	if len(m.historyEntries) == 0 {
+		m.status = "No corrections in the history yet"
+		return m, nil
+	}
+	m.historyIndex = 0
+	m.mode = ModeHistory
+	return m, nil
+}
+
+func (m Model) handleHistoryMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "h", "H":
+		m.mode = ModeGlobal
+		m.historyEntries = nil
+		return m, nil
+	case "up", "k":
+		if m.historyIndex > 0 {
+			m.historyIndex--
+		}
+		return m, nil
+	case "down", "j":
+		if m.historyIndex < len(m.historyEntries)-1 {
+			m.historyIndex++
+		}
+		return m, nil
+	case "enter":
+		return m.restoreHistoryEntry(m.historyEntries[m.historyIndex])
+	}
+	return m, nil
+}
+
+// restoreHistoryEntry puts a past correction back into the panes, ready to copy or review again
+func (m Model) restoreHistoryEntry(entry history.Entry) (tea.Model, tea.Cmd) {
+	m.mode = ModeGlobal
+	m = m.saveUndo()
+	m = m.resetScroll()
+	m.historyEntries = nil
+	m.originalText = entry.Original
+	m.correctedText = entry.Corrected
+	m.correctionResult = entry.Corrected
+	m.correctionSource = entry.Original
+	m.translatedText = entry.Translation
+	m.originalEditor.SetValue(entry.Original)
+	m.correctedEditor.SetValue(entry.Corrected)
+	m.translationEditor.SetValue(entry.Translation)
+	m.rewriteSource = ""
+	m.rewriteLabel = ""
+	m.romanized = ""
+	m.romanizedSource = ""
+	m.isLoading = false
+	m.isTranslating = false
+	m.estimate = ""
+	m.error = ""
+	m = m.applySourceLanguage(entry.Original)
+	m.status = "✓ Restored from history"
+
+	// Translate entries from before translation was turned on
+	if entry.Translation == "" && m.shouldTranslate(m.translationSource()) {
+		m.isTranslating = true
+		m.status += " [●] Translating..."
+		return m, m.streamTranslation(m.translationSource())
+	}
+	return m, nil
+}
+
+// historyLine shortens text to its first line, fitting width
+func historyLine(text string, width int) string {
+	line, _, multiline := strings.Cut(strings.TrimSpace(text), "\n")
+	runes := []rune(line)
+	if width < 2 {
+		width = 2
+	}
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	if multiline {
+		return line + " …"
+	}
+	return line
+}
+
+func (m Model) renderHistory() string {
+	width := m.width
+	if width == 0 {
+		width = 80
+	}
+	height := m.height
+	if height == 0 {
+		height = 24
+	}
+
+	historyStyle := lipgloss.NewStyle().
+		Border(m.theme.border()).
+		BorderForeground(m.theme.Header).
+		Padding(1, 2).
+		Width(width - 4)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.Header)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.Highlight)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Muted)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("grammr - History"))
+	content.WriteString("\n\n")
+
+	// Show a window of entries around the selected one
+	visible := max(height-16, 3)
+	start := max(m.historyIndex-visible/2, 0)
+	end := min(start+visible, len(m.historyEntries))
+	start = max(end-visible, 0)
+
+	lineWidth := width - 30
+	for i := start; i < end; i++ {
+		entry := m.historyEntries[i]
+		line := fmt.Sprintf("%s  %s", entry.Time.Local().Format("Jan 2 15:04"), historyLine(entry.Original, lineWidth))
+		if i == m.historyIndex {
+			content.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			content.WriteString("  " + line)
+		}
+		content.WriteString("\n")
+	}
+
+	selected := m.historyEntries[m.historyIndex]
+	content.WriteString("\n")
+	content.WriteString(mutedStyle.Render(historyLine(selected.Corrected, width-12)))
+	if selected.Translation != "" {
+		content.WriteString("\n")
+		content.WriteString(mutedStyle.Render(historyLine(selected.Translation, width-12)))
+	}
+	content.WriteString("\n\n  ↑/↓, K/J  Select\n")
+	content.WriteString("  Enter     Restore\n")
+	content.WriteString("  Esc       Close\n")
+
+	return historyStyle.Render(content.String())
+}
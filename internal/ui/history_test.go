@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/history"
+)
+
+func newTestHistoryModel(t *testing.T) Model {
+	t.Helper()
+	m := newTestModel(t, newTestConfig())
+	hist, err := history.Open(filepath.Join(t.TempDir(), history.DefaultFile), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.history = hist
+	return m
+}
+
+func TestHistoryRecordsCorrections(t *testing.T) {
+	m := newTestHistoryModel(t)
+
+	nextAny, _ := m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have an apple."})
+	m = nextAny.(Model)
+	nextAny, _ = m.Update(translationDoneMsg{source: "I have an apple.", translated: "Tengo una manzana."})
+	m = nextAny.(Model)
+	nextAny, _ = m.Update(correctionDoneMsg{original: "I has a pear.", corrected: "I have a pear."})
+	m = nextAny.(Model)
+
+	entries := m.history.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("history has %d entries, want 2", len(entries))
+	}
+	if entries[0].Original != "I has a pear." {
+		t.Errorf("newest entry = %q, want the last correction", entries[0].Original)
+	}
+	if entries[1].Translation != "Tengo una manzana." {
+		t.Errorf("translation = %q, want it recorded with its correction", entries[1].Translation)
+	}
+}
+
+func TestHistoryBrowserRestoresEntry(t *testing.T) {
+	m := newTestHistoryModel(t)
+	m.history.Add(history.Entry{Original: "I has a apple.", Corrected: "I have an apple.", Translation: "Tengo una manzana."})
+	m.history.Add(history.Entry{Original: "I has a pear.", Corrected: "I have a pear."})
+
+	nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")})
+	m = nextAny.(Model)
+	if m.mode != ModeHistory {
+		t.Fatalf("mode = %v, want the history browser", m.mode)
+	}
+	if view := m.View(); !strings.Contains(view, "I has a pear.") || !strings.Contains(view, "I has a apple.") {
+		t.Fatalf("history view doesn't list the corrections:\n%s", view)
+	}
+
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = nextAny.(Model)
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = nextAny.(Model)
+
+	if m.mode != ModeGlobal {
+		t.Fatalf("mode = %v, want the browser closed", m.mode)
+	}
+	if m.originalText != "I has a apple." || m.correctedText != "I have an apple." || m.translatedText != "Tengo una manzana." {
+		t.Fatalf("restored %q, %q, %q", m.originalText, m.correctedText, m.translatedText)
+	}
+	if m.correctedEditor.Value() != "I have an apple." {
+		t.Errorf("corrected editor = %q, want the restored text", m.correctedEditor.Value())
+	}
+}
+
+func TestHistoryBrowserWithoutEntries(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	nextAny, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	m = nextAny.(Model)
+	if m.mode != ModeGlobal {
+		t.Fatalf("mode = %v, want the browser to stay closed when the history is off", m.mode)
+	}
+
+	m = newTestHistoryModel(t)
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	m = nextAny.(Model)
+	if m.mode != ModeGlobal || m.status != "No corrections in the history yet" {
+		t.Fatalf("mode = %v, status = %q, want the empty history reported", m.mode, m.status)
+	}
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/journal"
+)
+
+// recordJournal appends the current correction, with its changes word by word, to the journal
+// set with journal_file. Corrections that changed nothing have no mistakes to learn from. Like
+// the history, a failed write is not worth interrupting the user for.
+func (m Model) recordJournal() {
+	path, err := m.config.JournalPath()
+	if err != nil || path == "" || m.correctedText == m.originalText {
+		return
+	}
+	var changes []journal.Change
+	for _, change := range computeDiff(m.originalText, m.correctedText, diffOptions{words: true, ignoreWhitespace: true}).changes() {
+		changes = append(changes, journal.Change{
+			Category: change.Category,
+			Before:   strings.TrimSpace(change.Removed),
+			After:    strings.TrimSpace(change.Added),
+		})
+	}
+	_ = journal.Append(path, journal.Entry{
+		Time:      time.Now(),
+		Original:  m.originalText,
+		Corrected: m.correctedText,
+		Changes:   changes,
+	})
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/maximbilan/grammr/internal/journal"
+)
+
+func TestRecordJournal(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.JournalFile = filepath.Join(t.TempDir(), "journal.md")
+	m := newTestModel(t, cfg)
+
+	next, _ := m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have an apple."})
+	next.(Model).Update(correctionDoneMsg{original: "Fine.", corrected: "Fine."})
+
+	entries, err := journal.Read(cfg.JournalFile)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("journal has %d entries, want only the correction that changed something", len(entries))
+	}
+	want := []journal.Change{
+		{Category: "grammar", Before: "has", After: "have"},
+		{Category: "grammar", Before: "a", After: "an"},
+	}
+	if got := entries[0].Changes; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("changes = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/config"
+)
+
+// Actions of the vim keymap that have no key in the default keymap
+const (
+	actionLineDown     = "vim:line-down"
+	actionLineUp       = "vim:line-up"
+	actionHalfPageDown = "vim:half-page-down"
+	actionHalfPageUp   = "vim:half-page-up"
+	actionTop          = "vim:top"
+	actionBottom       = "vim:bottom"
+	actionInsert       = "vim:insert"
+	actionSearch       = "vim:search"
+	actionNextMatch    = "vim:next-match"
+	actionPrevMatch    = "vim:prev-match"
+)
+
+// vimKeys maps the keys of the vim keymap to the default key of the same action, or to one of
+// the vim actions. Keys not listed here keep their default meaning, so the uppercase keys still
+// reach the actions vim took the lowercase ones from: P simplifies, K cycles the diff mode and I checks
+// consistency.
+var vimKeys = map[string]string{
+	"j":      actionLineDown,
+	"k":      actionLineUp,
+	"ctrl+d": actionHalfPageDown,
+	"ctrl+u": actionHalfPageUp,
+	"g":      actionTop,
+	"G":      actionBottom,
+	"i":      actionInsert,
+	"/":      actionSearch,
+	"n":      actionNextMatch,
+	"N":      actionPrevMatch,
+	"y":      "c", // Yank the corrected text
+	"Y":      "t", // Yank the translation
+	"p":      "v", // Put the clipboard in as the original text
+	"ctrl+g": "g", // Cycle the source language, since g and G move
+	"ctrl+n": "n", // Type a new text, since n and N find matches
+	"ctrl+x": "y", // Copy in another format, since y and Y yank
+}
+
+// globalKey returns the action of a key in global mode: the key itself, or what the vim keymap
+// maps it to
+func (m Model) globalKey(msg tea.KeyMsg) string {
+	key := msg.String()
+	if m.config.Keybindings != config.KeybindingsVim {
+		return key
+	}
+	if action, ok := vimKeys[key]; ok {
+		return action
+	}
+	return key
+}
+
+// handleVimAction runs an action of the vim keymap, reporting whether key was one
+func (m Model) handleVimAction(key string) (tea.Model, tea.Cmd, bool) {
+	var model tea.Model
+	var cmd tea.Cmd
+	switch key {
+	case actionLineDown:
+		model, cmd = m.scrollPane(func(vp *viewport.Model) { vp.LineDown(1) })
+	case actionLineUp:
+		model, cmd = m.scrollPane(func(vp *viewport.Model) { vp.LineUp(1) })
+	case actionHalfPageDown:
+		model, cmd = m.scrollPane(func(vp *viewport.Model) { vp.HalfViewDown() })
+	case actionHalfPageUp:
+		model, cmd = m.scrollPane(func(vp *viewport.Model) { vp.HalfViewUp() })
+	case actionTop:
+		model, cmd = m.scrollPane(func(vp *viewport.Model) { vp.GotoTop() })
+	case actionBottom:
+		model, cmd = m.scrollPane(func(vp *viewport.Model) { vp.GotoBottom() })
+	case actionInsert:
+		model, cmd = m.insert()
+	case actionSearch:
+		model, cmd = m.openSearch()
+	case actionNextMatch:
+		model, cmd = m.findMatch(1)
+	case actionPrevMatch:
+		model, cmd = m.findMatch(-1)
+	default:
+		return m, nil, false
+	}
+	return model, cmd, true
+}
+
+// insert edits the text of the focused pane, like i enters insert mode in vim
+func (m Model) insert() (tea.Model, tea.Cmd) {
+	switch m.focusedPane {
+	case paneCorrected:
+		if m.correctedText == "" {
+			return m, nil
+		}
+		m.correctedEditor.SetValue(m.correctedText)
+		m.mode = ModeEditCorrected
+		m.correctedEditor.Focus()
+	case paneTranslation:
+		if m.translator == nil || m.translatedText == "" {
+			return m, nil
+		}
+		m.translationEditor.SetValue(m.translatedText)
+		m.mode = ModeEditTranslation
+		m.translationEditor.Focus()
+	default:
+		if m.originalText == "" {
+			return m, nil
+		}
+		m.originalEditor.SetValue(m.originalText)
+		m.mode = ModeEditOriginal
+		m.originalEditor.Focus()
+	}
+	return m, textarea.Blink
+}
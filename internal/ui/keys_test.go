@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/config"
+)
+
+func TestGlobalKey(t *testing.T) {
+	tests := []struct {
+		keybindings string
+		key         tea.KeyMsg
+		want        string
+	}{
+		{config.KeybindingsDefault, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, "j"},
+		{config.KeybindingsDefault, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}, "y"},
+		{"", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")}, "p"},
+		{config.KeybindingsVim, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, actionLineDown},
+		{config.KeybindingsVim, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}, "c"},
+		{config.KeybindingsVim, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")}, "v"},
+		{config.KeybindingsVim, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")}, "P"},
+		{config.KeybindingsVim, tea.KeyMsg{Type: tea.KeyCtrlG}, "g"},
+		{config.KeybindingsVim, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}, "r"},
+	}
+
+	for _, tt := range tests {
+		cfg := newTestConfig()
+		cfg.Keybindings = tt.keybindings
+		m := Model{config: cfg}
+		if got := m.globalKey(tt.key); got != tt.want {
+			t.Errorf("globalKey(%q) with %q keybindings = %q, want %q", tt.key.String(), tt.keybindings, got, tt.want)
+		}
+	}
+}
+
+func TestVimKeys(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Keybindings = config.KeybindingsVim
+	m := newTestModel(t, cfg)
+	nextAny, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = nextAny.(Model)
+
+	var lines []string
+	for i := 1; i <= 40; i++ {
+		lines = append(lines, fmt.Sprintf("Line number %d.", i))
+	}
+	m.originalText = strings.Join(lines, "\n")
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if got := m.panes[paneOriginal].YOffset; got != 1 {
+		t.Fatalf("YOffset = %d after j, want 1", got)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	bottom := m.panes[paneOriginal].YOffset
+	if bottom <= 1 {
+		t.Fatalf("YOffset = %d after G, want the bottom", bottom)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	if got := m.panes[paneOriginal].YOffset; got != bottom-1 {
+		t.Fatalf("YOffset = %d after k, want %d", got, bottom-1)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if got := m.panes[paneOriginal].YOffset; got != 0 {
+		t.Fatalf("YOffset = %d after g, want the top", got)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if m.mode != ModeEditOriginal {
+		t.Fatalf("mode = %v after i, want the original editor", m.mode)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeGlobal {
+		t.Fatalf("mode = %v after Esc, want global mode", m.mode)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Keybindings = config.KeybindingsVim
+	m := newTestModel(t, cfg)
+	nextAny, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = nextAny.(Model)
+
+	var lines []string
+	for i := 1; i <= 40; i++ {
+		lines = append(lines, fmt.Sprintf("Line number %d.", i))
+	}
+	lines[9] = "The needle is here."
+	lines[29] = "Another NEEDLE."
+	m.originalText = strings.Join(lines, "\n")
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if m.mode != ModeSearch {
+		t.Fatalf("mode = %v after /, want the search prompt", m.mode)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("needle")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeGlobal || m.panes[paneOriginal].YOffset != 9 {
+		t.Fatalf("mode = %v, YOffset = %d, want the pane scrolled to the first match", m.mode, m.panes[paneOriginal].YOffset)
+	}
+	if m.status != "/needle: match 1 of 2 in Original Text" {
+		t.Errorf("status = %q, want the match count", m.status)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m.searchLine != 29 || !strings.Contains(m.status, "match 2 of 2") {
+		t.Fatalf("searchLine = %d, status = %q, want the second match", m.searchLine, m.status)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m.searchLine != 9 {
+		t.Fatalf("searchLine = %d, want the search to wrap around to the first match", m.searchLine)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	if m.searchLine != 29 {
+		t.Fatalf("searchLine = %d, want N to wrap around to the last match", m.searchLine)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("haystack")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.status != "Not found: haystack" {
+		t.Errorf("status = %q, want the search to fail", m.status)
+	}
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/langdetect"
+)
+
+// languageAuto is the language option that detects the language of each text
+const languageAuto = "auto"
+
+// applySourceLanguage works out the language of text, detecting it unless the user picked one,
+// and points the corrector and translator at it
+func (m Model) applySourceLanguage(text string) Model {
+	language := m.config.Language
+	if language == "" {
+		language = "english"
+	}
+	m.languageDetected = false
+	if m.config.DetectLanguage {
+		if detected, ok := langdetect.Detect(text); ok {
+			language = detected
+			m.languageDetected = true
+		}
+	}
+
+	m.sourceLanguage = language
+	if m.corrector != nil {
+		m.corrector = m.corrector.WithLanguage(language)
+	}
+	if m.translator != nil {
+		m.translator = m.translator.WithSourceLanguage(language)
+	}
+	return m
+}
+
+// switchLanguage cycles between detecting the language and each supported language
+func (m Model) switchLanguage() (tea.Model, tea.Cmd) {
+	options := append([]string{languageAuto}, langdetect.Languages...)
+	current := languageAuto
+	if !m.config.DetectLanguage {
+		current = m.config.Language
+	}
+	next := options[0]
+	for i, option := range options {
+		if option == current && i+1 < len(options) {
+			next = options[i+1]
+		}
+	}
+
+	if next == languageAuto {
+		m.config.DetectLanguage = true
+		return m.reloadCorrector("Language: Auto-detect")
+	}
+	m.config.DetectLanguage = false
+	m.config.Language = next
+	return m.reloadCorrector(fmt.Sprintf("Language: %s", styleLabel(next)))
+}
+
+// languageLabel describes the language of the current text for the header
+func (m Model) languageLabel() string {
+	if m.languageDetected {
+		return styleLabel(m.sourceLanguage) + " (auto)"
+	}
+	if !m.config.DetectLanguage && m.config.Language != "" && m.config.Language != "english" {
+		return styleLabel(m.config.Language)
+	}
+	return ""
+}
+
+// shouldTranslate reports whether text needs translating, which it doesn't when it is already in
+// the translation language
+func (m Model) shouldTranslate(text string) bool {
+	if m.translator == nil || text == "" {
+		return false
+	}
+	return !strings.EqualFold(m.sourceLanguage, m.translator.Language())
+}
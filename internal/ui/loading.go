@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// loadingMarker marks the status of a request in progress; it is replaced by the spinner when
+// the status is rendered
+const loadingMarker = "[●]"
+
+// progressBarWidth is the width of the progress bar of a chunked correction, in cells
+const progressBarWidth = 10
+
+func newSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.Dot))
+}
+
+// busy reports whether a request is in progress and the spinner should turn
+func (m Model) busy() bool {
+	return m.isLoading || m.isTranslating || m.isFetchingAlternatives || m.isExplaining
+}
+
+// Update handles a message, starts the spinner when a request starts, computes long diffs in the
+// background and autosaves the session
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	wasLoading, wasBusy := m.isLoading, m.busy()
+	next, cmd := m.update(msg)
+	updated, ok := next.(Model)
+	if !ok {
+		return next, cmd
+	}
+
+	if updated.isLoading && !wasLoading {
+		updated.loadingSince = time.Now()
+		updated.chunksDone, updated.chunkCount = 0, 0
+		updated.waitingUntil, updated.waitingRetry = time.Time{}, ""
+	}
+	if updated.busy() && !wasBusy && !updated.spinning && !updated.theme.Plain {
+		updated.spinning = true
+		cmd = tea.Batch(cmd, updated.spinner.Tick)
+	}
+	if diff := updated.prepareDiff(); diff != nil {
+		cmd = tea.Batch(cmd, diff)
+	}
+	return updated.autosave(), cmd
+}
+
+// tickSpinner advances the spinner, letting it stop once no request is in progress
+func (m Model) tickSpinner(msg spinner.TickMsg) (tea.Model, tea.Cmd) {
+	if !m.busy() {
+		m.spinning = false
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+// loadingStatus replaces the loading marker in a status with the spinner, which a plain theme
+// leaves out so screen readers aren't interrupted by it
+func (m Model) loadingStatus(status string) string {
+	if !m.busy() || m.theme.Plain {
+		return status
+	}
+	return strings.Replace(status, loadingMarker, m.spinner.View(), 1)
+}
+
+// correctionProgress describes the correction in progress: how long it has run and, for a text
+// corrected in chunks, how many of them are done
+func (m Model) correctionProgress() string {
+	if wait := time.Until(m.waitingUntil); wait > 0 {
+		return m.loadingStatus(loadingMarker) + " " + waitText(wait, m.waitingRetry)
+	}
+	text := m.loadingStatus(loadingMarker) + " Correcting..."
+	if !m.loadingSince.IsZero() {
+		if elapsed := time.Since(m.loadingSince); elapsed >= time.Second {
+			text += " " + formatElapsed(elapsed)
+		}
+	}
+	if m.chunkCount > 0 {
+		text += fmt.Sprintf(" %s %d/%d", m.progressBar(m.chunksDone, m.chunkCount), m.chunksDone, m.chunkCount)
+	}
+	return text
+}
+
+// formatElapsed formats a duration in whole seconds, such as 42s or 1m05s
+func formatElapsed(d time.Duration) string {
+	seconds := int(d / time.Second)
+	if seconds < 60 {
+		return fmt.Sprintf("%ds", seconds)
+	}
+	return fmt.Sprintf("%dm%02ds", seconds/60, seconds%60)
+}
+
+// progressBar draws done out of total as a bar in the theme's colors
+func (m Model) progressBar(done, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(done*progressBarWidth/total, progressBarWidth)
+	}
+	return lipgloss.NewStyle().Foreground(m.theme.Added).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(m.theme.Muted).Render(strings.Repeat("░", progressBarWidth-filled))
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSpinnerRunsWhileLoading(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple."
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(Model)
+	if !m.isLoading {
+		t.Fatal("R should start a correction")
+	}
+	if !m.spinning {
+		t.Fatal("spinner should start with the correction")
+	}
+	if m.loadingSince.IsZero() {
+		t.Fatal("start of the correction should be recorded")
+	}
+	if view := removeANSICodes(m.View()); strings.Contains(view, loadingMarker) {
+		t.Errorf("view shows the static marker instead of the spinner:\n%s", view)
+	}
+
+	if _, cmd := m.Update(spinner.TickMsg{}); cmd == nil {
+		t.Error("spinner should keep turning while loading")
+	}
+
+	m.isLoading = false
+	next, cmd := m.Update(spinner.TickMsg{})
+	m = next.(Model)
+	if cmd != nil || m.spinning {
+		t.Error("spinner should stop once nothing is loading")
+	}
+}
+
+func TestCorrectionProgress(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.isLoading = true
+	m.loadingSince = time.Now().Add(-65 * time.Second)
+
+	got := removeANSICodes(m.correctionProgress())
+	if !strings.Contains(got, "Correcting... 1m05s") {
+		t.Errorf("correctionProgress() = %q, want the elapsed time", got)
+	}
+	if strings.Contains(got, "█") || strings.Contains(got, "░") {
+		t.Errorf("correctionProgress() = %q, want no progress bar for a single request", got)
+	}
+
+	m.chunksDone, m.chunkCount = 2, 4
+	got = removeANSICodes(m.correctionProgress())
+	if want := "█████░░░░░ 2/4"; !strings.Contains(got, want) {
+		t.Errorf("correctionProgress() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0s"},
+		{d: 12*time.Second + 300*time.Millisecond, want: "12s"},
+		{d: 59 * time.Second, want: "59s"},
+		{d: 65 * time.Second, want: "1m05s"},
+		{d: 12*time.Minute + 30*time.Second, want: "12m30s"},
+	}
+
+	for _, tt := range tests {
+		if got := formatElapsed(tt.d); got != tt.want {
+			t.Errorf("formatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/merge"
+)
+
+// hasEdits reports whether the corrected text was edited since the correction returned it
+func (m Model) hasEdits() bool {
+	return m.correctionResult != "" && m.rewriteLabel == "" && m.correctedText != m.correctionResult
+}
+
+// mergeCorrection merges a new correction with the edits made to the previous one. Where both
+// changed the same words, the edit is kept and the spot is flagged as a conflict.
+func (m Model) mergeCorrection(corrected string) (Model, string) {
+	merged, conflicts := merge.Merge(m.correctionResult, m.correctedText, corrected)
+	m.conflicts = conflicts
+	m.conflictText = merged
+	return m, merged
+}
+
+// activeConflicts returns the conflicts of the last merge while the corrected text is unchanged
+func (m Model) activeConflicts() []merge.Conflict {
+	if m.correctedText != m.conflictText {
+		return nil
+	}
+	return m.conflicts
+}
+
+// mergeStatus describes a merge for the status line
+func mergeStatus(conflicts int) string {
+	switch conflicts {
+	case 0:
+		return " (merged with your edits)"
+	case 1:
+		return " (merged with your edits, 1 conflict kept yours)"
+	}
+	return fmt.Sprintf(" (merged with your edits, %d conflicts kept yours)", conflicts)
+}
+
+// conflictSummary describes merge conflicts for the corrected text label
+func conflictSummary(conflicts []merge.Conflict) string {
+	if len(conflicts) == 1 {
+		return "1 conflict with your edits"
+	}
+	return fmt.Sprintf("%d conflicts with your edits", len(conflicts))
+}
+
+// renderConflicts highlights the conflicts in text and shows what the correction suggested after
+// each of them
+func renderConflicts(text string, conflicts []merge.Conflict, theme Theme) string {
+	highlight := lipgloss.NewStyle().
+		Foreground(theme.Highlight).
+		Underline(true)
+	suggestion := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	var b strings.Builder
+	pos := 0
+	for _, c := range conflicts {
+		b.WriteString(text[pos:c.Start])
+		b.WriteString(highlight.Render(c.Ours))
+		theirs := strings.TrimSpace(c.Theirs)
+		if theirs == "" {
+			theirs = "removed"
+		}
+		b.WriteString(suggestion.Render(fmt.Sprintf(" [correction: %s]", theirs)))
+		pos = c.End
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRetryMergesEdits(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	nextAny, _ := m.Update(correctionDoneMsg{original: "The results was good. We meet on monday.", corrected: "The results were good. We meet on Monday."})
+	m = nextAny.(Model)
+
+	// Edit the corrected text, then correct the original again
+	m.correctedText = "The results were great. We meet on Monday."
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = nextAny.(Model)
+	if !m.mergeEdits {
+		t.Fatal("R should merge the new correction with the edits")
+	}
+
+	nextAny, _ = m.Update(correctionDoneMsg{original: m.originalText, corrected: "The results were excellent. We will meet on Monday."})
+	m = nextAny.(Model)
+	if want := "The results were great. We will meet on Monday."; m.correctedText != want {
+		t.Fatalf("correctedText = %q, want %q", m.correctedText, want)
+	}
+	if !strings.Contains(m.status, "1 conflict kept yours") {
+		t.Fatalf("status = %q, want it to report the conflict", m.status)
+	}
+	// The diff view shows the changes instead of the conflicts
+	m.showDiff = false
+	view := m.View()
+	if !strings.Contains(view, "1 conflict with your edits") || !strings.Contains(view, "[correction: excellent]") {
+		t.Fatalf("view should flag the conflict:\n%s", view)
+	}
+
+	// Without edits, the next correction replaces the text
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = nextAny.(Model)
+	m.correctedText = m.correctionResult
+	m.mergeEdits = m.hasEdits()
+	nextAny, _ = m.Update(correctionDoneMsg{original: m.originalText, corrected: "The results were excellent."})
+	m = nextAny.(Model)
+	if m.correctedText != "The results were excellent." || len(m.activeConflicts()) != 0 {
+		t.Fatalf("correctedText = %q, conflicts = %v", m.correctedText, m.activeConflicts())
+	}
+}
+
+func TestRetryWithoutEditsReplaces(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	nextAny, _ := m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have an apple."})
+	m = nextAny.(Model)
+
+	nextAny, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = nextAny.(Model)
+	if m.mergeEdits {
+		t.Fatal("R should not merge when the corrected text wasn't edited")
+	}
+	nextAny, _ = m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have one apple."})
+	m = nextAny.(Model)
+	if m.correctedText != "I have one apple." || strings.Contains(m.status, "merged") {
+		t.Fatalf("correctedText = %q, status = %q", m.correctedText, m.status)
+	}
+}
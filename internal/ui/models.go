@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/provider"
+)
+
+// providerLabels are the names of the providers as shown to the user
+var providerLabels = map[string]string{
+	"openai":    "OpenAI",
+	"anthropic": "Anthropic",
+}
+
+// modelOption is a model of a provider offered in the model picker
+type modelOption struct {
+	provider string
+	model    string
+}
+
+// currentProvider returns the provider in use; an empty provider means OpenAI
+func currentProvider(cfg *config.Config) string {
+	if cfg.Provider == "" {
+		return "openai"
+	}
+	return cfg.Provider
+}
+
+// modelOptions lists the models of every provider with an API key. The model in use is listed
+// too, even when it isn't one of the suggested ones.
+func modelOptions(cfg *config.Config) []modelOption {
+	var options []modelOption
+	for _, name := range provider.Names {
+		if strings.TrimSpace(cfg.APIKeyFor(name)) == "" {
+			continue
+		}
+		models := provider.Models[name]
+		if name == currentProvider(cfg) && cfg.Model != "" && !slices.Contains(models, cfg.Model) {
+			models = append([]string{cfg.Model}, models...)
+		}
+		for _, model := range models {
+			options = append(options, modelOption{provider: name, model: model})
+		}
+	}
+	return options
+}
+
+// openModelPicker shows the models to switch to, with the one in use selected
+func (m Model) openModelPicker() (tea.Model, tea.Cmd) {
+	m.modelOptions = modelOptions(m.config)
+	if len(m.modelOptions) == 0 {
+		m.status = missingAPIKeyMessage(m.config)
+		return m, nil
+	}
+	m.modelIndex = 0
+	current := modelOption{provider: currentProvider(m.config), model: m.config.Model}
+	if i := slices.Index(m.modelOptions, current); i >= 0 {
+		m.modelIndex = i
+	}
+	m.mode = ModeModelPicker
+	return m, nil
+}
+
+func (m Model) handleModelPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = ModeGlobal
+		m.modelOptions = nil
+		return m, nil
+	case "up", "k":
+		if m.modelIndex > 0 {
+			m.modelIndex--
+		}
+		return m, nil
+	case "down", "j":
+		if m.modelIndex < len(m.modelOptions)-1 {
+			m.modelIndex++
+		}
+		return m, nil
+	case "enter":
+		return m.switchModel(m.modelOptions[m.modelIndex])
+	}
+
+	for i, option := range m.modelOptions {
+		if i < maxStyleOptions && msg.String() == fmt.Sprintf("%d", i+1) {
+			return m.switchModel(option)
+		}
+	}
+	return m, nil
+}
+
+// switchModel changes the provider and model, recreates the corrector and translator for them
+// and saves the choice to config
+func (m Model) switchModel(option modelOption) (tea.Model, tea.Cmd) {
+	m.mode = ModeGlobal
+	m.modelOptions = nil
+	if option.provider == currentProvider(m.config) && option.model == m.config.Model {
+		return m, nil
+	}
+
+	previousProvider, previousModel := m.config.Provider, m.config.Model
+	m.config.Provider, m.config.Model = option.provider, option.model
+	if _, err := m.providers.get(m.config); err != nil {
+		// Keep using the previous model
+		m.config.Provider, m.config.Model = previousProvider, previousModel
+		return m, func() tea.Msg { return errMsg{err: err} }
+	}
+	return m.reloadCorrector(fmt.Sprintf("Model: %s (%s)", option.model, providerLabels[option.provider]))
+}
+
+func (m Model) renderModelPicker() string {
+	width := m.width
+	if width == 0 {
+		width = 80
+	}
+
+	menuStyle := lipgloss.NewStyle().
+		Border(m.theme.border()).
+		BorderForeground(m.theme.Header).
+		Padding(1, 2).
+		Width(width - 4)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.Header)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.Highlight)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Muted)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("grammr - Model"))
+	content.WriteString("\n")
+
+	current := modelOption{provider: currentProvider(m.config), model: m.config.Model}
+	for i, option := range m.modelOptions {
+		if i == 0 || m.modelOptions[i-1].provider != option.provider {
+			content.WriteString("\n" + mutedStyle.Render(providerLabels[option.provider]) + "\n")
+		}
+		key := " "
+		if i < maxStyleOptions {
+			key = fmt.Sprintf("%d", i+1)
+		}
+		line := fmt.Sprintf("%s  %s", key, option.model)
+		if option == current {
+			line += " (in use)"
+		}
+		if i == m.modelIndex {
+			content.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			content.WriteString("  " + line)
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n  ↑/↓, K/J  Select\n")
+	content.WriteString("  Enter     Switch to the model\n")
+	content.WriteString("  Esc       Cancel\n")
+
+	return menuStyle.Render(content.String())
+}
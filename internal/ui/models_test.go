@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/config"
+)
+
+func TestModelOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want []modelOption
+	}{
+		{
+			name: "only providers with an API key",
+			cfg:  &config.Config{Provider: "openai", APIKey: "sk-12345678901234567890", Model: "gpt-4o"},
+			want: []modelOption{{"openai", "gpt-4o"}, {"openai", "gpt-4o-mini"}},
+		},
+		{
+			name: "model in use listed first when it isn't suggested",
+			cfg:  &config.Config{APIKey: "sk-12345678901234567890", Model: "gpt-4.1"},
+			want: []modelOption{{"openai", "gpt-4.1"}, {"openai", "gpt-4o"}, {"openai", "gpt-4o-mini"}},
+		},
+		{
+			name: "every configured provider",
+			cfg:  &config.Config{Provider: "anthropic", AnthropicAPIKey: "sk-ant-REDACTED", APIKey: "sk-12345678901234567890", Model: "claude-3-haiku-20240307"},
+			want: []modelOption{
+				{"openai", "gpt-4o"},
+				{"openai", "gpt-4o-mini"},
+				{"anthropic", "claude-3-5-sonnet-20241022"},
+				{"anthropic", "claude-3-opus-20240229"},
+				{"anthropic", "claude-3-haiku-20240307"},
+			},
+		},
+		{
+			name: "no API keys",
+			cfg:  &config.Config{Model: "gpt-4o"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modelOptions(tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("modelOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelPicker(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	cfg := newTestConfig()
+	cfg.AnthropicAPIKey = "sk-ant-REDACTED"
+	cfg.TranslationLanguage = "french"
+	m := newTestModel(t, cfg)
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("M")})
+	m = next.(Model)
+	if m.mode != ModeModelPicker {
+		t.Fatalf("mode = %v, want the model picker", m.mode)
+	}
+	if got := m.modelOptions[m.modelIndex]; got != (modelOption{"openai", "gpt-4o"}) {
+		t.Fatalf("selected %v, want the model in use", got)
+	}
+	view := removeANSICodes(m.View())
+	for _, want := range []string{"OpenAI", "Anthropic", "gpt-4o (in use)", "claude-3-opus-20240229"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("picker should list %q:\n%s", want, view)
+		}
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = next.(Model)
+	translator := m.translator
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if cmd != nil {
+		t.Fatalf("switching models failed: %v", cmd())
+	}
+	if m.mode != ModeGlobal || m.config.Provider != "anthropic" || m.config.Model != "claude-3-5-sonnet-20241022" {
+		t.Fatalf("mode = %v, provider = %q, model = %q, want the picked model", m.mode, m.config.Provider, m.config.Model)
+	}
+	if m.status != "Model: claude-3-5-sonnet-20241022 (Anthropic)" {
+		t.Errorf("status = %q, want the new model", m.status)
+	}
+	if m.translator == nil || m.translator == translator {
+		t.Error("translator should be recreated for the new model")
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.Provider != "anthropic" || saved.Model != "claude-3-5-sonnet-20241022" {
+		t.Errorf("saved provider = %q, model = %q, want the picked model", saved.Provider, saved.Model)
+	}
+
+	// Number keys pick a model directly
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	m = next.(Model)
+	if m.config.Model != "gpt-4o" {
+		t.Fatalf("model = %q, want the first one picked with its number", m.config.Model)
+	}
+
+	// Esc leaves the picker without switching
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if m.mode != ModeGlobal || m.config.Model != "gpt-4o" {
+		t.Errorf("mode = %v, model = %q, want the picker closed without a change", m.mode, m.config.Model)
+	}
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// level is how important a notification is
+type level int
+
+const (
+	levelInfo level = iota
+	levelWarn
+	levelError
+)
+
+// toastDurations is how long a toast of each level stays on screen
+var toastDurations = map[level]time.Duration{
+	levelInfo:  4 * time.Second,
+	levelWarn:  8 * time.Second,
+	levelError: 12 * time.Second,
+}
+
+// maxToasts is how many toasts are shown at once; the oldest one is dismissed early to make room
+const maxToasts = 3
+
+// toast is a notification shown next to the status until it expires. Unlike the status, which
+// the next action overwrites, a toast stays long enough to be read.
+type toast struct {
+	id    int
+	level level
+	text  string
+}
+
+// toastExpiredMsg dismisses a toast once its time is up
+type toastExpiredMsg struct {
+	id int
+}
+
+// notify queues a toast and returns the command that dismisses it
+func (m Model) notify(lvl level, text string) (Model, tea.Cmd) {
+	m.nextToastID++
+	id := m.nextToastID
+	m.toasts = append(m.toasts, toast{id: id, level: lvl, text: text})
+	if len(m.toasts) > maxToasts {
+		m.toasts = m.toasts[len(m.toasts)-maxToasts:]
+	}
+	return m, tea.Tick(toastDurations[lvl], func(time.Time) tea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+// dismissToast removes a toast from the queue
+func (m Model) dismissToast(id int) Model {
+	toasts := make([]toast, 0, len(m.toasts))
+	for _, t := range m.toasts {
+		if t.id != id {
+			toasts = append(toasts, t)
+		}
+	}
+	m.toasts = toasts
+	return m
+}
+
+// renderToasts renders the queued toasts, oldest first
+func (m Model) renderToasts() string {
+	if len(m.toasts) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(m.toasts))
+	for _, t := range m.toasts {
+		icon, color := "ℹ", m.theme.Muted
+		switch t.level {
+		case levelWarn:
+			icon, color = "⚠", m.theme.Highlight
+		case levelError:
+			icon, color = "✗", m.theme.Error
+		}
+		parts = append(parts, lipgloss.NewStyle().
+			Foreground(color).
+			Render(icon+" "+t.text))
+	}
+	return " " + strings.Join(parts, "  ")
+}
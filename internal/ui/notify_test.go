@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNotify(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+
+	var cmd tea.Cmd
+	for _, text := range []string{"first", "second", "third", "fourth"} {
+		m, cmd = m.notify(levelInfo, text)
+		if cmd == nil {
+			t.Fatal("notify() should return the command that dismisses the toast")
+		}
+	}
+	if len(m.toasts) != maxToasts || m.toasts[0].text != "second" {
+		t.Fatalf("toasts = %+v, want the last %d", m.toasts, maxToasts)
+	}
+
+	m, _ = m.notify(levelError, "Failed to copy")
+	got := removeANSICodes(m.renderToasts())
+	if !strings.Contains(got, "ℹ fourth") || !strings.Contains(got, "✗ Failed to copy") {
+		t.Errorf("renderToasts() = %q, want the queued toasts with their icons", got)
+	}
+
+	// A status set afterwards doesn't hide the toast
+	m.status = "✓ Copied to clipboard"
+	if view := removeANSICodes(m.View()); !strings.Contains(view, "Failed to copy") {
+		t.Errorf("view should show the toast next to the status:\n%s", view)
+	}
+
+	next, _ := m.Update(toastExpiredMsg{id: m.toasts[len(m.toasts)-1].id})
+	m = next.(Model)
+	if strings.Contains(m.renderToasts(), "Failed to copy") {
+		t.Error("expired toast should be dismissed")
+	}
+	if len(m.toasts) != maxToasts-1 {
+		t.Errorf("got %d toasts, want the other %d kept", len(m.toasts), maxToasts-1)
+	}
+}
+
+func TestConfigSaveFailureToast(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	m := newTestModel(t, newTestConfig())
+
+	// A file where the config directory should be makes saving fail
+	blocker := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", blocker)
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatal("a failed save should return the command that dismisses its toast")
+	}
+	if len(m.toasts) != 1 || m.toasts[0].level != levelWarn || !strings.HasPrefix(m.toasts[0].text, "Config save failed") {
+		t.Fatalf("toasts = %+v, want a config save warning", m.toasts)
+	}
+	if !strings.HasPrefix(m.status, "Diff: ") {
+		t.Errorf("status = %q, want the new diff mode", m.status)
+	}
+}
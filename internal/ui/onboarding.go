@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// keyCheckedMsg carries the result of the test call made with a new API key
+type keyCheckedMsg struct {
+	err error
+}
+
+// onboarding asks for an API key on the first run, checks it with a test call and saves it
+type onboarding struct {
+	cfg           *config.Config
+	theme         Theme
+	providerIndex int // Index into provider.Names
+	keyInput      textinput.Model
+	checking      bool
+	err           string
+	done          bool   // The key works and was saved
+	warning       string // Shown once grammr starts, such as when the key went into the config file
+
+	// checkKey makes the test call; tests replace it to stay offline
+	checkKey func(cfg *config.Config) error
+}
+
+func newOnboarding(cfg *config.Config) onboarding {
+	theme, err := themeFor(cfg)
+	if err != nil {
+		theme = themes[ThemeDark]
+	}
+
+	keyInput := textinput.New()
+	keyInput.Prompt = "API key: "
+	keyInput.Placeholder = "sk-..."
+	keyInput.EchoMode = textinput.EchoPassword
+	keyInput.EchoCharacter = '•'
+	keyInput.Focus()
+
+	o := onboarding{cfg: cfg, theme: theme, keyInput: keyInput, checkKey: checkAPIKey}
+	for i, name := range provider.Names {
+		if name == currentProvider(cfg) {
+			o.providerIndex = i
+		}
+	}
+	return o
+}
+
+// modelFor returns the model to use with a provider: the configured one if it belongs to the
+// provider, otherwise the provider's first suggested model
+func modelFor(name, model string) string {
+	isClaude := strings.HasPrefix(model, "claude")
+	if model == "" || isClaude != (name == "anthropic") {
+		return provider.Models[name][0]
+	}
+	return model
+}
+
+// checkAPIKey makes a small request with the configured key to see that it works
+func checkAPIKey(cfg *config.Config) error {
+	prov, err := createProvider(cfg)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := createTimeoutContext(cfg)
+	defer cancel()
+	_, err = prov.Chat(ctx, cfg.Model, []provider.Message{
+		{Role: provider.RoleUser, Content: "Reply with OK."},
+	})
+	return err
+}
+
+func (o onboarding) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (o onboarding) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case keyCheckedMsg:
+		o.checking = false
+		if msg.err != nil {
+			o.err = fmt.Sprintf("The key didn't work: %v", msg.err)
+			return o, nil
+		}
+		if err := config.Save(o.cfg); errors.Is(err, config.ErrNoKeychain) {
+			o.warning = "Warning: " + err.Error()
+		} else if err != nil {
+			o.err = fmt.Sprintf("The key works, but saving it failed: %v", err)
+			return o, nil
+		}
+		o.done = true
+		return o, tea.Quit
+
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc {
+			return o, tea.Quit
+		}
+		if o.checking {
+			return o, nil
+		}
+		switch msg.Type {
+		case tea.KeyTab, tea.KeyShiftTab:
+			o.providerIndex = (o.providerIndex + 1) % len(provider.Names)
+			o.err = ""
+			return o, nil
+		case tea.KeyEnter:
+			return o.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	o.keyInput, cmd = o.keyInput.Update(msg)
+	return o, cmd
+}
+
+// submit checks the format of the key, then tries it with a test call
+func (o onboarding) submit() (tea.Model, tea.Cmd) {
+	key := strings.TrimSpace(o.keyInput.Value())
+	if err := validation.ValidateAPIKey(key); err != nil {
+		o.err = err.Error()
+		return o, nil
+	}
+
+	name := provider.Names[o.providerIndex]
+	o.cfg.Provider = name
+	o.cfg.Model = modelFor(name, o.cfg.Model)
+	if name == "anthropic" {
+		o.cfg.AnthropicAPIKey = key
+	} else {
+		o.cfg.APIKey = key
+	}
+
+	o.checking = true
+	o.err = ""
+	cfg, check := o.cfg, o.checkKey
+	return o, func() tea.Msg { return keyCheckedMsg{err: check(cfg)} }
+}
+
+func (o onboarding) View() string {
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(o.theme.Header)
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(o.theme.Highlight)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(o.theme.Muted)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("Welcome to grammr"))
+	content.WriteString("\n\n")
+	content.WriteString("grammr needs an API key to correct your texts.\n\n")
+
+	content.WriteString("Provider: ")
+	for i, name := range provider.Names {
+		label := providerLabels[name]
+		if i == o.providerIndex {
+			content.WriteString(selectedStyle.Render("[" + label + "]"))
+		} else {
+			content.WriteString(mutedStyle.Render(" " + label + " "))
+		}
+		content.WriteString(" ")
+	}
+	content.WriteString("\n\n")
+	content.WriteString(o.keyInput.View())
+	content.WriteString("\n\n")
+
+	switch {
+	case o.checking:
+		content.WriteString(mutedStyle.Render("Checking the key..."))
+	case o.err != "":
+		content.WriteString(lipgloss.NewStyle().
+			Foreground(o.theme.Error).
+			Render("✗ " + o.err))
+	default:
+		content.WriteString(mutedStyle.Render("The key is saved to your config, like grammr config set."))
+	}
+	content.WriteString("\n\n")
+	content.WriteString(mutedStyle.Render("Tab: Switch provider  Enter: Check and save  Esc: Quit"))
+
+	return o.theme.output(lipgloss.NewStyle().
+		Border(o.theme.border()).
+		BorderForeground(o.theme.Header).
+		Padding(1, 2).
+		Render(content.String()))
+}
+
+// runOnboarding shows the onboarding screen, reporting whether a working key was saved and what
+// to warn about once grammr starts
+func runOnboarding(cfg *config.Config) (bool, string, error) {
+	p := tea.NewProgram(newOnboarding(cfg), tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return false, "", fmt.Errorf("program error: %w", err)
+	}
+	return final.(onboarding).done, final.(onboarding).warning, nil
+}
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+// typeKey sends the text and Enter to the onboarding screen and runs the test call
+func typeKey(t *testing.T, o onboarding, key string) onboarding {
+	t.Helper()
+	next, _ := o.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	next, cmd := next.(onboarding).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	o = next.(onboarding)
+	if cmd == nil {
+		return o
+	}
+	if !o.checking {
+		t.Fatal("Enter should start the test call")
+	}
+	next, _ = o.Update(cmd())
+	return next.(onboarding)
+}
+
+func TestOnboarding(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	cfg := &config.Config{Provider: "openai", Model: "gpt-4o", KeyStorage: config.KeyStorageFile}
+	o := newOnboarding(cfg)
+
+	o = typeKey(t, o, "not-a-key")
+	if o.err == "" || o.done {
+		t.Fatalf("err = %q, want a malformed key rejected before the test call", o.err)
+	}
+
+	o.keyInput.SetValue("")
+	o.checkKey = func(*config.Config) error { return errors.New("401 Unauthorized") }
+	o = typeKey(t, o, "sk-12345678901234567890")
+	if !strings.Contains(o.err, "401 Unauthorized") || o.done {
+		t.Fatalf("err = %q, want the failed test call reported", o.err)
+	}
+	if view := o.View(); strings.Contains(view, "sk-12345678901234567890") {
+		t.Error("the typed key should be masked")
+	}
+
+	// Switch to Anthropic, which needs one of its own models
+	next, _ := o.Update(tea.KeyMsg{Type: tea.KeyTab})
+	o = next.(onboarding)
+	o.keyInput.SetValue("")
+	var checked config.Config
+	o.checkKey = func(cfg *config.Config) error {
+		checked = *cfg
+		return nil
+	}
+	o = typeKey(t, o, "sk-ant-REDACTED")
+	if !o.done {
+		t.Fatalf("err = %q, want a working key accepted", o.err)
+	}
+	if checked.Provider != "anthropic" || checked.AnthropicAPIKey != "sk-ant-REDACTED" || checked.Model != "claude-3-5-sonnet-20241022" {
+		t.Errorf("checked provider = %q, key = %q, model = %q, want the Anthropic key and model", checked.Provider, checked.AnthropicAPIKey, checked.Model)
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !hasConfiguredAPIKey(saved) || saved.Provider != "anthropic" {
+		t.Errorf("saved provider = %q, want the Anthropic key saved", saved.Provider)
+	}
+}
+
+func TestOnboardingWithoutKeychain(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keychain"))
+	defer keyring.MockInit()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	o := newOnboarding(&config.Config{Provider: "openai", Model: "gpt-4o"})
+	o.checkKey = func(*config.Config) error { return nil }
+
+	o = typeKey(t, o, "sk-12345678901234567890")
+	if !o.done || o.err != "" {
+		t.Fatalf("done = %v, err = %q, want the key saved to the config file", o.done, o.err)
+	}
+	if !strings.Contains(o.warning, "stored in the config file") {
+		t.Errorf("warning = %q, want the key in the config file pointed out", o.warning)
+	}
+}
+
+func TestModelFor(t *testing.T) {
+	tests := []struct {
+		provider string
+		model    string
+		want     string
+	}{
+		{"openai", "gpt-4o-mini", "gpt-4o-mini"},
+		{"openai", "claude-3-haiku-20240307", "gpt-4o"},
+		{"anthropic", "gpt-4o", "claude-3-5-sonnet-20241022"},
+		{"anthropic", "claude-3-haiku-20240307", "claude-3-haiku-20240307"},
+		{"openai", "", "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		if got := modelFor(tt.provider, tt.model); got != tt.want {
+			t.Errorf("modelFor(%q, %q) = %q, want %q", tt.provider, tt.model, got, tt.want)
+		}
+	}
+}
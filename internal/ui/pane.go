@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pane is one of the text boxes of the main screen
+type pane int
+
+const (
+	paneOriginal pane = iota
+	paneCorrected
+	paneTranslation
+	paneCount
+)
+
+// paneNames label the panes in the status line
+var paneNames = [paneCount]string{"Original Text", "Corrected Text", "Translation"}
+
+// mouseWheelLines is how far one turn of the mouse wheel scrolls
+const mouseWheelLines = 3
+
+// paneSize returns the outer width and height of each pane, sharing the space left by the
+// header, labels and footer
+func (m Model) paneSize() (width, height int) {
+	width = max(m.width-4, 20)
+	// Account for: header (1-2 lines), separator (1), spacing (1), labels (3 if translation enabled, else 2), spacing between boxes (2 if translation, else 1), stats (1), separator (1), footer (1-2)
+	// Total: ~13-15 lines for fixed content with translation, ~10-12 without
+	fixedLines, numBoxes := 12, 2
+	if m.translator != nil {
+		fixedLines, numBoxes = 15, 3
+	}
+	if m.zoomed {
+		// A single label and box
+		fixedLines, numBoxes = 9, 1
+	}
+	availableHeight := m.height - fixedLines
+	if availableHeight < 10 {
+		availableHeight = m.height - (fixedLines - 2) // Minimum space for very small terminals
+	}
+	return width, max(availableHeight/numBoxes, 3)
+}
+
+// paneContent renders what a pane shows, wrapped to width
+func (m Model) paneContent(p pane, width int) string {
+	loadingStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Highlight).
+		Italic(true)
+
+	switch p {
+	case paneCorrected:
+		if m.isLoading && m.correctedText == "" {
+			return loadingStyle.Render("Correcting...")
+		}
+		if m.showDiff && m.diffBase() != "" && m.correctedText != "" && m.mode != ModeReviewDiff {
+			// Only show diff view when not in review mode (review mode has its own display)
+			if !diffReady(m.diffBase(), m.correctedText, diffOptionsFor(m.config)) {
+				status := "Comparing with the original..."
+				if m.isLoading {
+					status = "Correcting..."
+				}
+				return loadingStyle.Render(status) + "\n\n" + wrapText(m.correctedText, width)
+			}
+			diff := renderDiffWithViolations(m.diffBase(), m.correctedText, m.glossary.Check(m.correctedText), m.theme, diffOptionsFor(m.config))
+			return wrapStyled(diff, width)
+		}
+		if conflicts := m.activeConflicts(); len(conflicts) > 0 {
+			return wrapStyled(renderConflicts(m.correctedText, conflicts, m.theme), width)
+		}
+		return wrapText(m.correctedText, width)
+	case paneTranslation:
+		if m.isTranslating && m.translatedText == "" {
+			return loadingStyle.Render("Translating...")
+		}
+		content := wrapText(m.translatedText, width)
+		if romanized := m.currentRomanization(); romanized != "" {
+			content += "\n\n" + lipgloss.NewStyle().
+				Foreground(m.theme.Muted).
+				Italic(true).
+				Render(wrapText(romanized, width))
+		}
+		return content
+	default:
+		return wrapText(m.originalText, width)
+	}
+}
+
+// paneViewport returns the viewport of a pane, sized and filled with its current content
+func (m Model) paneViewport(p pane) viewport.Model {
+	width, height := m.paneSize()
+	vp := m.panes[p]
+	vp.Width = max(width-4, 1)   // Padding of 2 on each side
+	vp.Height = max(height-2, 1) // Padding of 1 above and below
+	vp.SetContent(m.paneContent(p, vp.Width))
+	return vp
+}
+
+// renderPane renders a pane in its box, along with a scroll position for its label when the
+// text doesn't fit
+func (m Model) renderPane(p pane) (box, scroll string) {
+	vp := m.paneViewport(p)
+	overflows := vp.TotalLineCount() > vp.Height
+
+	border := m.theme.Border
+	if overflows {
+		scroll = lipgloss.NewStyle().
+			Foreground(m.theme.Muted).
+			Render(fmt.Sprintf(" ↕ %d%%", int(vp.ScrollPercent()*100)))
+		if p == m.focusedPane {
+			border = m.theme.Highlight
+		}
+	}
+	box = lipgloss.NewStyle().
+		Border(m.theme.border()).
+		BorderForeground(border).
+		Padding(1, 2).
+		Render(vp.View())
+	return box, scroll
+}
+
+// showsPane reports whether the main screen shows pane p: all panes, or only the focused one
+// while zoomed
+func (m Model) showsPane(p pane) bool {
+	if p == paneTranslation && m.translator == nil {
+		return false
+	}
+	if !m.zoomed {
+		return true
+	}
+	focused := m.focusedPane
+	if focused == paneTranslation && m.translator == nil {
+		focused = paneOriginal
+	}
+	return p == focused
+}
+
+// toggleZoom shows the focused pane at full height, hiding the others, or shows all of them again.
+// Tab moves the zoom to the next pane.
+func (m Model) toggleZoom() (tea.Model, tea.Cmd) {
+	m.zoomed = !m.zoomed
+	if m.focusedPane == paneTranslation && m.translator == nil {
+		m.focusedPane = paneOriginal
+	}
+	m.status = "Showing all panes"
+	if m.zoomed {
+		m.status = fmt.Sprintf("Zoomed: %s (Z to show all panes)", paneNames[m.focusedPane])
+	}
+	return m, nil
+}
+
+// focusNextPane moves scrolling to the next (or previous) visible pane
+func (m Model) focusNextPane(step int) (tea.Model, tea.Cmd) {
+	count := int(paneTranslation)
+	if m.translator != nil {
+		count = int(paneCount)
+	}
+	m.focusedPane = pane(((int(m.focusedPane)+step)%count + count) % count)
+	m.status = fmt.Sprintf("Scrolling: %s", paneNames[m.focusedPane])
+	return m, nil
+}
+
+// scrollPane scrolls the focused pane
+func (m Model) scrollPane(scroll func(vp *viewport.Model)) (tea.Model, tea.Cmd) {
+	if m.focusedPane == paneTranslation && m.translator == nil {
+		m.focusedPane = paneOriginal
+	}
+	vp := m.paneViewport(m.focusedPane)
+	scroll(&vp)
+	m.panes[m.focusedPane] = vp
+	return m, nil
+}
+
+// handleMouse scrolls the focused pane with the mouse wheel
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.mode != ModeGlobal {
+		return m, nil
+	}
+	switch msg.Type {
+	case tea.MouseWheelUp:
+		return m.scrollPane(func(vp *viewport.Model) { vp.LineUp(mouseWheelLines) })
+	case tea.MouseWheelDown:
+		return m.scrollPane(func(vp *viewport.Model) { vp.LineDown(mouseWheelLines) })
+	}
+	return m, nil
+}
+
+// resetScroll shows the top of every pane, for a new text
+func (m Model) resetScroll() Model {
+	m.panes = [paneCount]viewport.Model{}
+	return m
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestScrollLongText(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	nextAny, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = nextAny.(Model)
+
+	var lines []string
+	for i := 1; i <= 40; i++ {
+		lines = append(lines, fmt.Sprintf("Line number %d.", i))
+	}
+	m.originalText = "Short."
+	shortHeight := strings.Count(m.View(), "\n")
+	m.originalText = strings.Join(lines, "\n")
+
+	view := m.View()
+	if !strings.Contains(view, "Line number 1.") || strings.Contains(view, "Line number 40.") {
+		t.Fatal("a long text should show its start and be cut off at the pane height")
+	}
+	if !strings.Contains(view, "↕ 0%") {
+		t.Fatal("an overflowing pane should show its scroll position")
+	}
+	if height := strings.Count(view, "\n"); height != shortHeight {
+		t.Fatalf("view is %d lines high, want the %d of a short text", height, shortHeight)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyPgDown})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.panes[paneOriginal].YOffset == 0 {
+		t.Fatal("PgDn should scroll the original pane")
+	}
+	if view := m.View(); strings.Contains(view, "Line number 1.\n") || strings.Contains(view, "Line number 1. ") {
+		t.Fatal("the start of the text should be scrolled out of view")
+	}
+
+	nextAny, _ = m.Update(tea.MouseMsg{Type: tea.MouseWheelUp})
+	m = nextAny.(Model)
+	nextAny, _ = m.Update(tea.MouseMsg{Type: tea.MouseWheelUp})
+	m = nextAny.(Model)
+	if m.panes[paneOriginal].YOffset == 0 {
+		t.Fatal("the mouse wheel should scroll less than two pages")
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyPgUp})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyPgUp})
+	if m.panes[paneOriginal].YOffset != 0 {
+		t.Fatalf("YOffset = %d, want the pane back at the top", m.panes[paneOriginal].YOffset)
+	}
+
+	// A new text starts at the top again
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyPgDown})
+	nextAny, _ = m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have an apple."})
+	m = nextAny.(Model)
+	if m.panes[paneOriginal].YOffset != 0 {
+		t.Fatal("a new text should reset the scroll position")
+	}
+}
+
+func TestFocusNextPane(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.focusedPane != paneCorrected {
+		t.Fatalf("focusedPane = %v, want the corrected pane", m.focusedPane)
+	}
+	// Without a translator there's no translation pane to scroll
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.focusedPane != paneOriginal {
+		t.Fatalf("focusedPane = %v, want the original pane", m.focusedPane)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.focusedPane != paneCorrected {
+		t.Fatalf("focusedPane = %v, want Shift+Tab to go back", m.focusedPane)
+	}
+}
+
+func TestToggleZoom(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.TranslationLanguage = "french"
+	m := newTestModel(t, cfg)
+	nextAny, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = nextAny.(Model)
+
+	var lines []string
+	for i := 1; i <= 40; i++ {
+		lines = append(lines, fmt.Sprintf("Line number %d.", i))
+	}
+	m.originalText = strings.Join(lines, "\n")
+	m.correctedText = "Corrected."
+	m.translatedText = "Corrigé."
+	m.showDiff = false
+	height := strings.Count(m.View(), "\n")
+	_, paneHeight := m.paneSize()
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	if !m.zoomed || !strings.HasPrefix(m.status, "Zoomed: Original Text") {
+		t.Fatalf("zoomed = %v, status = %q, want the original pane zoomed", m.zoomed, m.status)
+	}
+	view := m.View()
+	if strings.Contains(view, "Corrected.") || strings.Contains(view, "Corrigé.") {
+		t.Error("a zoomed pane should hide the others")
+	}
+	if _, zoomedHeight := m.paneSize(); zoomedHeight <= 2*paneHeight {
+		t.Errorf("zoomed pane is %d lines high, want most of the screen instead of %d", zoomedHeight, paneHeight)
+	}
+	if got := strings.Count(view, "\n"); got > height {
+		t.Errorf("zoomed view is %d lines high, want no more than the %d of all panes", got, height)
+	}
+
+	// Tab moves the zoom to the next pane
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	view = m.View()
+	if !strings.Contains(view, "Corrected.") || strings.Contains(view, "Line number 1.") {
+		t.Error("Tab should zoom the corrected pane instead")
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	view = m.View()
+	if m.zoomed || !strings.Contains(view, "Line number 1.") || !strings.Contains(view, "Corrigé.") {
+		t.Error("Z again should show all panes")
+	}
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/corrector"
+)
+
+// paragraphEdit is an edited text split into paragraphs, with the corrections of the paragraphs
+// that are the same as in the text corrected last
+type paragraphEdit struct {
+	paragraphs []corrector.Chunk
+	corrected  []string // Corrections of the paragraphs, empty for the ones that changed
+	changed    []int    // The paragraphs to correct
+}
+
+// editedParagraphs matches the paragraphs of text with those of the text corrected last, so only
+// the ones that changed have to be corrected again. It reports false when nothing can be reused,
+// or when the last correction doesn't have a paragraph for each paragraph of its original.
+func (m Model) editedParagraphs(text string) (paragraphEdit, bool) {
+	if m.correctionSource == "" || m.correctionResult == "" {
+		return paragraphEdit{}, false
+	}
+	before := corrector.SplitParagraphs(m.correctionSource)
+	after := corrector.SplitParagraphs(m.correctionResult)
+	if len(before) < 2 || len(before) != len(after) {
+		return paragraphEdit{}, false
+	}
+	corrections := make(map[string]string, len(before))
+	for i, paragraph := range before {
+		corrections[paragraph.Text] = after[i].Text
+	}
+
+	edit := paragraphEdit{paragraphs: corrector.SplitParagraphs(text)}
+	edit.corrected = make([]string, len(edit.paragraphs))
+	for i, paragraph := range edit.paragraphs {
+		if corrected, ok := corrections[paragraph.Text]; ok {
+			edit.corrected[i] = corrected
+			continue
+		}
+		// A paragraph too long for one request is better left to a full correction
+		if corrector.NeedsChunking(paragraph.Text) {
+			return paragraphEdit{}, false
+		}
+		edit.changed = append(edit.changed, i)
+	}
+	if len(edit.changed) == len(edit.paragraphs) {
+		return paragraphEdit{}, false
+	}
+	return edit, true
+}
+
+// changedText returns the paragraphs to correct, for the estimate of the request
+func (e paragraphEdit) changedText() string {
+	texts := make([]string, 0, len(e.changed))
+	for _, i := range e.changed {
+		texts = append(texts, e.paragraphs[i].Text)
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// correctParagraphs corrects the paragraphs of text that changed since the last correction,
+// chunk_concurrency at a time, and puts their corrections between the ones kept
+func (m Model) correctParagraphs(text string, edit paragraphEdit) tea.Cmd {
+	return func() tea.Msg {
+		chunks := make([]corrector.Chunk, 0, len(edit.changed))
+		for _, i := range edit.changed {
+			chunks = append(chunks, edit.paragraphs[i])
+		}
+		ctx, cancel := m.requestContext(m.correctionTimeout(edit.changedText()))
+		defer cancel()
+
+		corrected, err := m.corrector.CorrectChunks(ctx, chunks, m.config.ChunkConcurrency, 0, nil)
+		if err != nil {
+			return errMsg{err: err, failed: failedRequest{op: opCorrection, text: text}}
+		}
+		texts := slices.Clone(edit.corrected)
+		for j, i := range edit.changed {
+			texts[i] = corrected[j]
+		}
+		trimmedCorrected := trimTrailingWhitespace(corrector.JoinChunks(edit.paragraphs, texts))
+		m.saveToCache(text, trimmedCorrected)
+
+		return correctionDoneMsg{
+			original:   text,
+			corrected:  trimmedCorrected,
+			paragraphs: len(edit.paragraphs),
+			kept:       len(edit.paragraphs) - len(edit.changed),
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/provider"
+)
+
+func TestEditedParagraphs(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		result      string
+		text        string
+		wantOK      bool
+		wantChanged []int
+	}{
+		{
+			name:        "one paragraph edited",
+			source:      "She have a cat.\n\nIt are black.\n\nThe end.",
+			result:      "She has a cat.\n\nIt is black.\n\nThe end.",
+			text:        "She have a cat.\n\nThey is black.\n\nThe end.",
+			wantOK:      true,
+			wantChanged: []int{1},
+		},
+		{
+			name:        "paragraph added",
+			source:      "She have a cat.\n\nIt are black.",
+			result:      "She has a cat.\n\nIt is black.",
+			text:        "She have a cat.\n\nIt are black.\n\nIt like milk.",
+			wantOK:      true,
+			wantChanged: []int{2},
+		},
+		{
+			name:   "nothing edited",
+			source: "She have a cat.\n\nIt are black.",
+			result: "She has a cat.\n\nIt is black.",
+			text:   "She have a cat.\n\nIt are black.",
+			wantOK: true,
+		},
+		{
+			name:   "every paragraph edited",
+			source: "She have a cat.\n\nIt are black.",
+			result: "She has a cat.\n\nIt is black.",
+			text:   "He have a dog.\n\nIt are white.",
+		},
+		{
+			name:   "correction merged paragraphs",
+			source: "She have a cat.\n\nIt are black.",
+			result: "She has a cat. It is black.",
+			text:   "She have a cat.\n\nThey is black.",
+		},
+		{
+			name: "no previous correction",
+			text: "She have a cat.\n\nIt are black.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, newTestConfig())
+			m.correctionSource, m.correctionResult = tt.source, tt.result
+			edit, ok := m.editedParagraphs(tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("editedParagraphs() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !slices.Equal(edit.changed, tt.wantChanged) {
+				t.Errorf("editedParagraphs() changed = %v, want %v", edit.changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestCorrectParagraphs(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	corr, err := corrector.New(provider.NewMockProvider(), "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("corrector.New() error = %v", err)
+	}
+	// The mock provider echoes the prompt, so a corrected paragraph comes back prefixed
+	if err := corr.SetPromptTemplate("{{.Text}}"); err != nil {
+		t.Fatalf("SetPromptTemplate() error = %v", err)
+	}
+	m.corrector = corr
+	m.originalText = "She have a cat.\n\nIt are black.\n\nThe end."
+	m.correctionSource = m.originalText
+	m.correctionResult = "She has a cat.\n\nIt is black.\n\nThe end."
+	m.correctedText = m.correctionResult
+
+	text := "She have a cat.\n\nThey is black.\n\nThe end."
+	edit, ok := m.editedParagraphs(text)
+	if !ok {
+		t.Fatal("editedParagraphs() should reuse the paragraphs that were not edited")
+	}
+	done, ok := m.correctParagraphs(text, edit)().(correctionDoneMsg)
+	if !ok {
+		t.Fatal("correctParagraphs() should finish the correction")
+	}
+	if want := "She has a cat.\n\nMock response for: They is black.\n\nThe end."; done.corrected != want {
+		t.Errorf("corrected = %q, want %q", done.corrected, want)
+	}
+
+	m.originalText = text
+	next, _ := m.Update(done)
+	m = next.(Model)
+	if !strings.Contains(m.status, "1 of 3 paragraphs changed") {
+		t.Errorf("status = %q, want the paragraphs corrected again", m.status)
+	}
+	if m.correctionSource != text || m.correctionResult != done.corrected {
+		t.Error("the correction should be reused for the next edit")
+	}
+}
+
+// failingProvider fails every request with err
+type failingProvider struct{ err error }
+
+func (p failingProvider) StreamChat(ctx context.Context, model string, messages []provider.Message, onChunk func(string)) error {
+	return p.err
+}
+
+func (p failingProvider) Chat(ctx context.Context, model string, messages []provider.Message) (string, error) {
+	return "", p.err
+}
+
+func TestRetryFailedParagraphCorrection(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	corr, err := corrector.New(failingProvider{err: errors.New("boom")}, "gpt-4o", "casual", "english")
+	if err != nil {
+		t.Fatalf("corrector.New() error = %v", err)
+	}
+	m.corrector = corr
+	m.originalText = "She have a cat.\n\nIt are black.\n\nThe end."
+	m.correctionSource = m.originalText
+	m.correctionResult = "She has a cat.\n\nIt is black.\n\nThe end."
+	m.correctedText = m.correctionResult
+
+	text := "She have a cat.\n\nThey is black.\n\nThe end."
+	edit, ok := m.editedParagraphs(text)
+	if !ok {
+		t.Fatal("editedParagraphs() should reuse the paragraphs that were not edited")
+	}
+	failed, ok := m.correctParagraphs(text, edit)().(errMsg)
+	if !ok {
+		t.Fatal("correctParagraphs() should fail")
+	}
+	if failed.failed != (failedRequest{op: opCorrection, text: text}) {
+		t.Errorf("failed = %+v, want the correction of the edited text", failed.failed)
+	}
+
+	m.originalText = text
+	m.isLoading = true
+	next, _ := m.Update(failed)
+	next, cmd := next.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(Model)
+	if !m.isLoading || m.status != "[●] Correcting..." || cmd == nil {
+		t.Errorf("isLoading = %v, status = %q, want the correction sent again", m.isLoading, m.status)
+	}
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/sanitize"
+)
+
+// handlePaste handles text pasted into the terminal. Terminals with bracketed paste send it as a
+// single message, so it works without clipboard access (over SSH, for example) and never triggers
+// shortcuts. In the main view the pasted text is corrected like one pasted with V; in the editors
+// it is inserted at the cursor.
+func (m Model) handlePaste(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Carriage returns, which terminals send for line breaks, become newlines
+	text, report := sanitize.Clean(string(msg.Runes))
+	switch m.mode {
+	case ModeEditOriginal, ModeEditCorrected, ModeEditTranslation:
+		msg.Runes = []rune(text)
+		next, cmd := m.handleEditMode(msg)
+		if report.String() == "" {
+			return next, cmd
+		}
+		// The text is only sent once the user asks, so instructions to the model are just reported
+		m, toast := next.(Model).notify(levelWarn, "Pasted text: "+report.String())
+		return m, tea.Batch(cmd, toast)
+	case ModeGlobal:
+		text = trimTrailingWhitespace(text)
+		if strings.TrimSpace(text) == "" {
+			return m, nil
+		}
+		return m.confirmDiscardEdits(func(m Model) (tea.Model, tea.Cmd) {
+			if report.String() != "" {
+				return m.correctSanitized(sanitizedMsg{text: text, report: report})
+			}
+			return m, func() tea.Msg { return m.newText(text) }
+		})
+	}
+	// Dialogs and review mode take no text
+	return m, nil
+}
+
+// sanitizedMsg carries a pasted text that sanitize.Clean changed or found instructions to the
+// model in
+type sanitizedMsg struct {
+	text   string
+	report sanitize.Report
+}
+
+// correctSanitized reports what was removed from a pasted text, or found in it, and corrects it.
+// A text that may instruct the model is only sent once the user agrees.
+func (m Model) correctSanitized(msg sanitizedMsg) (tea.Model, tea.Cmd) {
+	correct := func(m Model) (tea.Model, tea.Cmd) {
+		return m, func() tea.Msg { return m.newText(msg.text) }
+	}
+	if len(msg.report.Injections) == 0 {
+		m, toast := m.notify(levelInfo, "Pasted text: "+msg.report.String())
+		next, cmd := correct(m)
+		return next, tea.Batch(toast, cmd)
+	}
+	m, toast := m.notify(levelWarn, "Pasted text: "+msg.report.String())
+	next, cmd := m.confirm("The pasted text may instruct the model. Send it anyway?", correct)
+	return next, tea.Batch(toast, cmd)
+}
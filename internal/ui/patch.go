@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/patch"
+)
+
+// patchFilePerm keeps written patches private, like the rest of grammr's files
+const patchFilePerm os.FileMode = 0600
+
+// correctionPatch returns a unified diff from the original text to the corrected one, as
+// reviewed or edited
+func (m Model) correctionPatch() string {
+	return patch.Unified("a/original.txt", "b/corrected.txt", m.originalText, m.correctedText)
+}
+
+// patchFileName names a patch written at t
+func patchFileName(t time.Time) string {
+	return t.Format("grammr-20060102-150405.patch")
+}
+
+// exportPatch asks whether to copy the patch or write it to a file
+func (m Model) exportPatch() (tea.Model, tea.Cmd) {
+	if m.isLoading || m.correctedText == "" {
+		return m, nil
+	}
+	if m.correctionPatch() == "" {
+		m.status = "No changes to export"
+		return m, nil
+	}
+	m.mode = ModeExportPatch
+	m.status = "Export patch: C: Copy to clipboard, W: Write to a file, Esc: Cancel"
+	return m, nil
+}
+
+func (m Model) handleExportPatch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "c", "C":
+		m.mode = ModeGlobal
+		if err := m.clipboard.Copy(m.correctionPatch()); err != nil {
+			m.status = fmt.Sprintf("Failed to copy patch: %v", err)
+		} else {
+			m.status = "✓ Patch copied to clipboard"
+		}
+	case "w", "W":
+		m.mode = ModeGlobal
+		name := patchFileName(time.Now())
+		if err := os.WriteFile(name, []byte(m.correctionPatch()), patchFilePerm); err != nil {
+			m.status = fmt.Sprintf("Failed to write patch: %v", err)
+		} else {
+			m.status = fmt.Sprintf("✓ Patch written to %s", name)
+		}
+	case "esc", "q", "u", "U":
+		m.mode = ModeGlobal
+		m.status = "Cancelled"
+	}
+	return m, nil
+}
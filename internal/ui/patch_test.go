@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExportPatchToFile(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if m.mode != ModeExportPatch {
+		t.Fatalf("mode = %v, want the export prompt", m.mode)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if m.mode != ModeGlobal || !strings.HasPrefix(m.status, "✓ Patch written to grammr-") {
+		t.Fatalf("mode = %v, status = %q, want the patch written", m.mode, m.status)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "grammr-*.patch"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("patch files = %v, %v", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "-I has a apple.\n+I have an apple.\n") {
+		t.Fatalf("patch =\n%s", data)
+	}
+}
+
+func TestExportPatchWithoutChanges(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I have an apple."
+	m.correctedText = "I have an apple."
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if m.mode != ModeGlobal || m.status != "No changes to export" {
+		t.Fatalf("mode = %v, status = %q", m.mode, m.status)
+	}
+}
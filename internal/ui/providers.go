@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// providerFactory creates the providers of a session. They send their requests through one HTTP
+// client, so keep-alive connections and TLS sessions outlive the correctors and translators that
+// are rebuilt when the model, style or config changes. The provider itself is reused while the
+// settings it is made from stay the same.
+type providerFactory struct {
+	client   *http.Client
+	current  provider.Provider
+	settings providerSettings
+}
+
+// providerSettings are the settings a provider is made from
+type providerSettings struct {
+	name          string
+	apiKey        string
+	deterministic bool
+	audit         bool
+	redact        bool
+	retries       int
+	offline       bool
+	cacheDir      string // Where the key of the audit log is found
+}
+
+func newProviderFactory() *providerFactory {
+	return &providerFactory{client: provider.NewHTTPClient()}
+}
+
+// get returns the provider for cfg
+func (f *providerFactory) get(cfg *config.Config) (provider.Provider, error) {
+	if f == nil {
+		return createProvider(cfg)
+	}
+	settings := providerSettings{
+		name:          cfg.Provider,
+		apiKey:        cfg.GetAPIKey(),
+		deterministic: cfg.Deterministic,
+		audit:         cfg.AuditEnabled,
+		redact:        cfg.RedactPII,
+		retries:       cfg.RequestRetries,
+		offline:       cfg.Offline,
+		cacheDir:      cfg.CacheDir,
+	}
+	if f.current != nil && settings == f.settings {
+		return f.current, nil
+	}
+	prov, err := newProvider(cfg, f.client)
+	if err != nil {
+		return nil, err
+	}
+	f.current, f.settings = prov, settings
+	return prov, nil
+}
+
+// createProvider creates an AI provider based on the config, with connections of its own. The
+// session gets its providers from its providerFactory instead.
+func createProvider(cfg *config.Config) (provider.Provider, error) {
+	return newProvider(cfg, nil)
+}
+
+// newProvider creates the provider of cfg, sending requests through client unless it is nil
+func newProvider(cfg *config.Config, client *http.Client) (provider.Provider, error) {
+	apiKey := cfg.GetAPIKey()
+	// Offline, nothing is sent, so no key is needed
+	if err := validation.ValidateAPIKey(apiKey); err != nil && !cfg.Offline {
+		return nil, err
+	}
+	auditLog, err := cfg.OpenAudit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return provider.New(cfg.Provider, apiKey, provider.Options{
+		Deterministic: cfg.Deterministic,
+		Audit:         auditLog,
+		HTTPClient:    client,
+		Redact:        cfg.RedactPII,
+		Retries:       cfg.RequestRetries,
+		Offline:       cfg.Offline,
+	})
+}
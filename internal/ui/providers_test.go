@@ -0,0 +1,46 @@
+package ui
+
+import "testing"
+
+func TestProviderFactory(t *testing.T) {
+	f := newProviderFactory()
+	cfg := newTestConfig()
+	first, err := f.get(cfg)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+
+	// Settings the provider isn't made from keep it
+	cfg.Style, cfg.Model = "formal", "gpt-4o-mini"
+	if again, err := f.get(cfg); err != nil || again != first {
+		t.Errorf("get() = %v, %v, want the same provider", again, err)
+	}
+
+	cfg.Deterministic = true
+	second, err := f.get(cfg)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	if second == first {
+		t.Error("get() should create a new provider when deterministic changes")
+	}
+
+	cfg.APIKey = ""
+	if _, err := f.get(cfg); err == nil {
+		t.Error("get() should fail without an API key")
+	}
+	// A failure keeps the previous provider for the settings it was made from
+	cfg.APIKey = "sk-12345678901234567890"
+	if again, err := f.get(cfg); err != nil || again != second {
+		t.Errorf("get() = %v, %v, want the previous provider", again, err)
+	}
+
+	// A model reloading its corrector keeps the provider
+	m := newTestModel(t, newTestConfig())
+	prov, _ := m.providers.get(m.config)
+	m.config.Style = "formal"
+	next, _ := m.reloadCorrector("Style: formal")
+	if again, _ := next.(Model).providers.get(m.config); again != prov {
+		t.Error("reloadCorrector() should reuse the provider")
+	}
+}
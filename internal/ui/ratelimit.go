@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/apierror"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/ratelimit"
+)
+
+// rateLimitWaitMsg reports that a request is waiting for the rate limit before it is sent, or
+// waiting to be sent again after it failed
+type rateLimitWaitMsg struct {
+	delay time.Duration
+	retry string // Why the request is sent again, such as "Rate limited (1/2)"; empty for the rate limit
+}
+
+// rateLimitWaitDoneMsg reports that the request shown waiting for the rate limit has been sent
+type rateLimitWaitDoneMsg struct {
+	waiting  string // The status shown while waiting
+	previous string // The status to go back to
+}
+
+// requestContext creates the context of a request, which times out after timeout and tells the
+// UI when the request has to wait for the rate limit
+func (m Model) requestContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return m.rateLimitContext(ctx), cancel
+}
+
+// correctionTimeout returns the time allowed for correcting text, or for a request made like a
+// correction, such as a rewrite
+func (m Model) correctionTimeout(text string) time.Duration {
+	return m.config.ScaledTimeout(m.config.CorrectionTimeout(), text)
+}
+
+// translationTimeout returns the time allowed for translating text
+func (m Model) translationTimeout(text string) time.Duration {
+	return m.config.ScaledTimeout(m.config.TranslationTimeout(), text)
+}
+
+// rateLimitContext returns ctx telling the UI when its requests have to wait for the rate limit,
+// or before they are sent again after a rate limit or network error
+func (m Model) rateLimitContext(ctx context.Context) context.Context {
+	if m.rateLimitWaits == nil {
+		return ctx
+	}
+	waits := m.rateLimitWaits
+	send := func(msg rateLimitWaitMsg) {
+		// Drop the wait rather than hold up the request when the UI is behind
+		select {
+		case waits <- msg:
+		default:
+		}
+	}
+	retries := m.config.RequestRetries
+	ctx = provider.WithRetryNotify(ctx, func(attempt int, delay time.Duration, err error) {
+		send(rateLimitWaitMsg{delay: delay, retry: retryReason(err, attempt, retries)})
+	})
+	return ratelimit.WithWaitNotify(ctx, func(delay time.Duration) {
+		send(rateLimitWaitMsg{delay: delay})
+	})
+}
+
+// retryReason describes why a request is sent again, such as "Rate limited (1/2)"
+func retryReason(err error, attempt, retries int) string {
+	reason := "Network error"
+	if apierror.KindOf(err) == apierror.KindRateLimited {
+		reason = "Rate limited"
+	}
+	return fmt.Sprintf("%s (%d/%d)", reason, attempt, retries)
+}
+
+// waitForRateLimit waits for the next request to be held up by the rate limit or a retry
+func waitForRateLimit(waits <-chan rateLimitWaitMsg) tea.Cmd {
+	if waits == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return <-waits
+	}
+}
+
+// showRateLimitWait tells the user that the request in progress is waiting for the rate limit
+func (m Model) showRateLimitWait(msg rateLimitWaitMsg) (tea.Model, tea.Cmd) {
+	wait := waitForRateLimit(m.rateLimitWaits)
+	if !m.busy() {
+		return m, wait
+	}
+	m.waitingUntil, m.waitingRetry = time.Now().Add(msg.delay), msg.retry
+	done := rateLimitWaitDoneMsg{waiting: loadingMarker + " " + waitText(msg.delay, msg.retry), previous: m.status}
+	m.status = done.waiting
+	return m, tea.Batch(wait, tea.Tick(msg.delay, func(time.Time) tea.Msg { return done }))
+}
+
+// endRateLimitWait puts the status back once the request is sent, unless it has moved on
+func (m Model) endRateLimitWait(msg rateLimitWaitDoneMsg) (tea.Model, tea.Cmd) {
+	if m.status == msg.waiting {
+		m.status = msg.previous
+	}
+	return m, nil
+}
+
+// waitText describes a wait for the rate limit, such as "Waiting for rate limit, ~5s...", or
+// before a retry, such as "Rate limited (1/2), retrying in ~1s..."
+func waitText(delay time.Duration, retry string) string {
+	// Round up, so a wait under a second doesn't read as ~0s
+	seconds := (delay + time.Second - 1) / time.Second
+	if retry != "" {
+		return fmt.Sprintf("%s, retrying in ~%s...", retry, formatElapsed(seconds*time.Second))
+	}
+	return fmt.Sprintf("Waiting for rate limit, ~%s...", formatElapsed(seconds*time.Second))
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/ratelimit"
+)
+
+func TestRequestContextReportsRateLimitWaits(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	limiter := ratelimit.NewWithBurst(20, 1, time.Millisecond)
+
+	ctx, cancel := m.requestContext(time.Minute)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	msg, ok := waitForRateLimit(m.rateLimitWaits)().(rateLimitWaitMsg)
+	if !ok || msg.delay <= 0 {
+		t.Fatalf("waitForRateLimit() = %#v, want the wait of the second request", msg)
+	}
+}
+
+func TestRequestContextDoesNotBlockOnWaits(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	limiter := ratelimit.NewWithBurst(100, 1, time.Millisecond)
+
+	// Nobody reads the waits, so all but the first are dropped instead of holding up requests
+	ctx, cancel := m.requestContext(time.Minute)
+	defer cancel()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if len(m.rateLimitWaits) != 1 {
+		t.Errorf("%d waits queued, want 1", len(m.rateLimitWaits))
+	}
+}
+
+func TestShowRateLimitWait(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.isLoading = true
+	m.status = "[●] Correcting..."
+
+	next, cmd := m.Update(rateLimitWaitMsg{delay: 4200 * time.Millisecond})
+	m = next.(Model)
+	if want := "Waiting for rate limit, ~5s..."; !strings.Contains(m.status, want) {
+		t.Errorf("status = %q, want %q", m.status, want)
+	}
+	if got := removeANSICodes(m.correctionProgress()); !strings.Contains(got, "Waiting for rate limit") {
+		t.Errorf("correctionProgress() = %q, want the wait", got)
+	}
+	if cmd == nil {
+		t.Fatal("showRateLimitWait() should keep waiting and put the status back later")
+	}
+
+	next, _ = m.Update(rateLimitWaitDoneMsg{waiting: m.status, previous: "[●] Correcting..."})
+	if got := next.(Model).status; got != "[●] Correcting..." {
+		t.Errorf("status after the wait = %q, want it put back", got)
+	}
+}
+
+func TestShowRateLimitWaitWhenIdle(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.status = "Ready"
+
+	next, _ := m.Update(rateLimitWaitMsg{delay: time.Second})
+	if got := next.(Model).status; got != "Ready" {
+		t.Errorf("status = %q, want it left alone without a request in progress", got)
+	}
+}
+
+func TestOperationTimeouts(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.RequestTimeoutSeconds = 30
+	cfg.TranslationTimeoutSeconds = 120
+	cfg.ScaleTimeouts = true
+	m := newTestModel(t, cfg)
+
+	if got := m.correctionTimeout("I has a apple."); got != 30*time.Second {
+		t.Errorf("correctionTimeout() = %v, want request_timeout_seconds", got)
+	}
+	if got := m.translationTimeout("I have an apple."); got != 2*time.Minute {
+		t.Errorf("translationTimeout() = %v, want translation_timeout_seconds", got)
+	}
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 500)
+	if got := m.correctionTimeout(long); got <= 30*time.Second {
+		t.Errorf("correctionTimeout() = %v for a long text, want more than 30s", got)
+	}
+}
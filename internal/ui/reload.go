@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+	"github.com/maximbilan/grammr/internal/clipboard"
+	"github.com/maximbilan/grammr/internal/config"
+)
+
+// configReloadDelay gives an editor time to finish writing the config file before it is read
+const configReloadDelay = 200 * time.Millisecond
+
+// configChangedMsg reports that the config file changed on disk
+type configChangedMsg struct{}
+
+// watchConfig reports changes to the config file at path on the returned channel, coalescing
+// bursts of writes into one. The directory is watched, since editors often replace the file
+// instead of writing to it.
+func watchConfig(path string) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch config: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch config: %w", err)
+	}
+
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default: // A change is already pending
+		}
+	}
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(path) || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(configReloadDelay, notify)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// waitForConfigChange waits for the next change to the config file
+func waitForConfigChange(changes <-chan struct{}) tea.Cmd {
+	if changes == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		<-changes
+		return configChangedMsg{}
+	}
+}
+
+// reloadConfig applies the config file to the running session. The cache keeps its settings
+// until grammr restarts.
+func (m Model) reloadConfig() (tea.Model, tea.Cmd) {
+	wait := waitForConfigChange(m.configChanges)
+	cfg, err := config.Load()
+	if err != nil {
+		m, dismiss := m.notify(levelError, fmt.Sprintf("Config reload failed: %v", err))
+		return m, tea.Batch(wait, dismiss)
+	}
+	// grammr saving a setting changes the file too, but not the config
+	if reflect.DeepEqual(cfg, m.config) {
+		return m, wait
+	}
+	m, err = m.applyConfig(cfg)
+	if err != nil {
+		m, dismiss := m.notify(levelError, fmt.Sprintf("Config reload failed: %v", err))
+		return m, tea.Batch(wait, dismiss)
+	}
+	m.status = "Config reloaded"
+	if warning := cfg.ShadowedStylesWarning(); warning != "" {
+		m, dismiss := m.notify(levelWarn, "Warning: "+warning)
+		return m, tea.Batch(wait, dismiss)
+	}
+	return m, wait
+}
+
+// rateLimitsChanged reports whether the rate limits differ between two configs. While they
+// don't, the budgets already used up are kept.
+func rateLimitsChanged(old, cfg *config.Config) bool {
+	return old.RateLimitEnabled != cfg.RateLimitEnabled ||
+		old.RateLimitRequests != cfg.RateLimitRequests ||
+		old.RateLimitWindow != cfg.RateLimitWindow ||
+		old.RateLimitBurst != cfg.RateLimitBurst ||
+		!reflect.DeepEqual(old.RateLimits, cfg.RateLimits)
+}
+
+// applyConfig switches the session over to cfg, keeping the current text
+func (m Model) applyConfig(cfg *config.Config) (Model, error) {
+	rateLimits := m.rateLimits
+	if rateLimitsChanged(m.config, cfg) {
+		rateLimits = cfg.OpenRateLimits()
+	}
+	gloss, err := cfg.LoadGlossary()
+	if err != nil {
+		return m, err
+	}
+	cor, trans, err := newServices(cfg, m.providers, rateLimits, gloss)
+	if err != nil {
+		return m, err
+	}
+	theme, err := themeFor(cfg)
+	if err != nil {
+		return m, err
+	}
+	hist, err := cfg.OpenHistory()
+	if err != nil {
+		return m, err
+	}
+
+	m.config = cfg
+	m.corrector = cor
+	m.translator = trans
+	m.rateLimits = rateLimits
+	m.glossary = gloss
+	m.history = hist
+	m.theme = theme
+	m.showDiff = cfg.ShowDiff
+	m.translateOriginal = cfg.TranslateOriginal
+	m.clipboard = clipboard.New(cfg.Clipboard)
+	if m.originalText != "" {
+		m = m.applySourceLanguage(m.originalText)
+	}
+	return m, nil
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maximbilan/grammr/internal/config"
+)
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("model: gpt-4o\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := watchConfig(path)
+	if err != nil {
+		t.Fatalf("watchConfig() error = %v", err)
+	}
+
+	// Other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "glossary.yaml"), []byte("terms: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+		t.Fatal("change reported for another file")
+	case <-time.After(3 * configReloadDelay):
+	}
+
+	if err := os.WriteFile(path, []byte("model: gpt-4o-mini\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported after writing the config file")
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	file, err := config.File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte("api_key: sk-12345678901234567890\ncache_enabled: false\n"+content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("model: gpt-4o\n")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	m := newTestModel(t, cfg)
+	m.status = "Ready"
+
+	// Nothing changed, e.g. after grammr saved a setting itself
+	updated, _ := m.reloadConfig()
+	if got := updated.(Model).status; got != "Ready" {
+		t.Errorf("status = %q after an unchanged reload, want it untouched", got)
+	}
+
+	write("model: gpt-4o-mini\nauto_copy: true\ntranslation_language: french\nshow_diff: false\n")
+	updated, cmd := m.reloadConfig()
+	m = updated.(Model)
+	if m.status != "Config reloaded" {
+		t.Errorf("status = %q, want %q", m.status, "Config reloaded")
+	}
+	if m.config.Model != "gpt-4o-mini" || !m.config.AutoCopy || m.showDiff {
+		t.Errorf("config not applied: model = %q, auto_copy = %v, show diff = %v", m.config.Model, m.config.AutoCopy, m.showDiff)
+	}
+	if m.translator == nil || m.translator.Language() != "french" {
+		t.Error("translator not created for the new translation language")
+	}
+	if cmd != nil {
+		t.Error("reloadConfig() should not wait for changes without a watcher")
+	}
+
+	write("provider: [broken\n")
+	updated, _ = m.reloadConfig()
+	m = updated.(Model)
+	if m.config.Model != "gpt-4o-mini" {
+		t.Error("a broken config file should keep the current config")
+	}
+	if len(m.toasts) != 1 || m.toasts[0].level != levelError || !strings.HasPrefix(m.toasts[0].text, "Config reload failed") {
+		t.Errorf("toasts = %+v, want the reload error", m.toasts)
+	}
+}
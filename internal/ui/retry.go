@@ -0,0 +1,58 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// operation is a request that R sends again, with the same text, after it failed
+type operation int
+
+const (
+	opNone operation = iota
+	opCorrection
+	opTranslation
+)
+
+// failedRequest is the correction or translation behind the error shown
+type failedRequest struct {
+	op   operation
+	text string // The text that was being corrected or translated
+}
+
+// retryHint is added to the error of a request R can send again
+const retryHint = " (R: Retry)"
+
+// retryFailed sends the request behind the error shown again, with the text it failed on, so a
+// failed translation is translated again rather than the text corrected again
+func (m Model) retryFailed() (tea.Model, tea.Cmd) {
+	failed := m.failed
+	var ok bool
+	if m, ok = m.ensureServices(); !ok {
+		return m, nil
+	}
+	m.error, m.failed = "", failedRequest{}
+	if failed.op == opTranslation {
+		if m.translator == nil {
+			// Translation was turned off since
+			m.status = "Translation is off"
+			return m, nil
+		}
+		m.isTranslating = true
+		m.status = "[●] Translating..."
+		return m, m.streamTranslation(failed.text)
+	}
+	return m.recorrect(failed.text)
+}
+
+// recorrect corrects text again, keeping the edits made to its previous correction
+func (m Model) recorrect(text string) (tea.Model, tea.Cmd) {
+	m = m.saveUndo()
+	m.mergeEdits = m.hasEdits()
+	m.isLoading = true
+	m.isTranslating = false
+	m.translatedText = ""
+	m.translationEditor.SetValue("")
+	m.status = "[●] Correcting..."
+	m.estimate = m.correctionEstimate(text).String()
+	return m, m.correctText(text)
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/apierror"
+)
+
+func TestRetryFailedRequest(t *testing.T) {
+	retryKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}
+
+	t.Run("translation", func(t *testing.T) {
+		cfg := newTestConfig()
+		cfg.TranslationLanguage = "french"
+		m := newTestModel(t, cfg)
+		m.originalText, m.correctedText = "I has a apple.", "I have an apple."
+		m.isTranslating = true
+
+		next, _ := m.Update(errMsg{err: errors.New("boom"), failed: failedRequest{op: opTranslation, text: "I have an apple."}})
+		m = next.(Model)
+		if m.isTranslating || !strings.HasSuffix(m.status, retryHint) {
+			t.Fatalf("isTranslating = %v, status = %q, want the translation stopped and the retry offered", m.isTranslating, m.status)
+		}
+
+		next, cmd := m.Update(retryKey)
+		m = next.(Model)
+		if !m.isTranslating || m.isLoading || m.status != "[●] Translating..." || cmd == nil {
+			t.Errorf("isTranslating = %v, isLoading = %v, status = %q, want the translation sent again and the text not corrected", m.isTranslating, m.isLoading, m.status)
+		}
+		if m.error != "" || m.failed.op != opNone {
+			t.Errorf("error = %q, failed = %+v, want them cleared", m.error, m.failed)
+		}
+		if m.correctedText != "I have an apple." {
+			t.Errorf("correctedText = %q, want it kept", m.correctedText)
+		}
+	})
+
+	t.Run("correction", func(t *testing.T) {
+		m := newTestModel(t, newTestConfig())
+		m.originalText = "I has a apple."
+		m.isLoading = true
+
+		next, _ := m.Update(errMsg{err: errors.New("boom"), failed: failedRequest{op: opCorrection, text: "I has a apple."}})
+		next, cmd := next.(Model).Update(retryKey)
+		m = next.(Model)
+		if !m.isLoading || m.status != "[●] Correcting..." || cmd == nil {
+			t.Errorf("isLoading = %v, status = %q, want the correction sent again", m.isLoading, m.status)
+		}
+		if m.error != "" {
+			t.Errorf("error = %q, want it cleared", m.error)
+		}
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		m := newTestModel(t, newTestConfig())
+		next, _ := m.Update(errMsg{err: errors.New("failed to fetch alternatives")})
+		m = next.(Model)
+		if strings.Contains(m.status, retryHint) {
+			t.Errorf("status = %q, want no retry offered", m.status)
+		}
+		// Without a text, there is nothing to correct again either
+		if _, cmd := m.Update(retryKey); cmd != nil {
+			t.Error("R should do nothing without a text")
+		}
+	})
+
+	t.Run("success clears the failure", func(t *testing.T) {
+		m := newTestModel(t, newTestConfig())
+		next, _ := m.Update(errMsg{err: errors.New("boom"), failed: failedRequest{op: opCorrection, text: "I has a apple."}})
+		next, _ = next.(Model).Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have an apple."})
+		m = next.(Model)
+		if m.error != "" || m.failed.op != opNone {
+			t.Errorf("error = %q, failed = %+v, want nothing left to retry", m.error, m.failed)
+		}
+	})
+}
+
+func TestShowRetryWait(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.isLoading = true
+	m.status = "[●] Correcting..."
+
+	next, _ := m.Update(rateLimitWaitMsg{delay: 2 * time.Second, retry: "Rate limited (1/2)"})
+	m = next.(Model)
+	if want := "Rate limited (1/2), retrying in ~2s..."; !strings.Contains(m.status, want) {
+		t.Errorf("status = %q, want %q", m.status, want)
+	}
+	if got := removeANSICodes(m.correctionProgress()); !strings.Contains(got, "retrying in") {
+		t.Errorf("correctionProgress() = %q, want the retry", got)
+	}
+}
+
+func TestRetryReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: &apierror.Error{Kind: apierror.KindRateLimited, Err: errors.New("429")}, want: "Rate limited (1/2)"},
+		{err: &apierror.Error{Kind: apierror.KindNetwork, Err: errors.New("connection refused")}, want: "Network error (1/2)"},
+	}
+	for _, tt := range tests {
+		if got := retryReason(tt.err, 1, 2); got != tt.want {
+			t.Errorf("retryReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
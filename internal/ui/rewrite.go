@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/corrector"
+)
+
+type rewriteDoneMsg struct {
+	source    string
+	label     string // Describes the rewrite, e.g. "Concise" or "Simplified"
+	rewritten string
+}
+
+// rewriteSourceText returns the text a rewrite operates on: the corrected text if available,
+// otherwise the original
+func (m Model) rewriteSourceText() string {
+	if m.correctedText != "" {
+		return m.correctedText
+	}
+	return m.originalText
+}
+
+// diffBase returns the text the corrected pane is compared against. After a rewrite this is
+// the text that was rewritten, otherwise the original text.
+func (m Model) diffBase() string {
+	if m.rewriteSource != "" {
+		return m.rewriteSource
+	}
+	return m.originalText
+}
+
+func (m Model) handleToneMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "w", "W":
+		m.mode = ModeGlobal
+		return m, nil
+	}
+
+	for i, tone := range corrector.Tones {
+		if msg.String() == fmt.Sprintf("%d", i+1) {
+			m.mode = ModeGlobal
+			return m.startRewrite(styleLabel(tone), func(ctx context.Context, text string, onChunk func(string)) error {
+				return m.corrector.StreamRewrite(ctx, text, tone, onChunk)
+			})
+		}
+	}
+	return m, nil
+}
+
+// startTransform applies a quick rewrite action (shorten, expand, simplify) to the text
+func (m Model) startTransform(action string) (tea.Model, tea.Cmd) {
+	if m.rewriteSourceText() == "" || m.isLoading {
+		return m, nil
+	}
+
+	percent := m.config.ShortenTargetPercent()
+	return m.startRewrite(actionLabel(action, percent), func(ctx context.Context, text string, onChunk func(string)) error {
+		return m.corrector.StreamTransform(ctx, text, action, percent, onChunk)
+	})
+}
+
+// actionLabel describes the result of a rewrite action
+func actionLabel(action string, percent int) string {
+	switch action {
+	case corrector.ActionShorten:
+		return fmt.Sprintf("Shortened to %d%%", percent)
+	case corrector.ActionExpand:
+		return "Expanded"
+	case corrector.ActionSimplify:
+		return "Simplified"
+	default:
+		return styleLabel(action)
+	}
+}
+
+// startRewrite runs a rewrite of the current text in the background, replacing any translation
+func (m Model) startRewrite(label string, rewrite func(ctx context.Context, text string, onChunk func(string)) error) (tea.Model, tea.Cmd) {
+	m, ok := m.ensureServices()
+	if !ok {
+		return m, nil
+	}
+	source := m.rewriteSourceText()
+	m.isLoading = true
+	m.isTranslating = false
+	m.translatedText = ""
+	m.translationEditor.SetValue("")
+	m.status = fmt.Sprintf("[●] Rewriting (%s)...", label)
+	return m, m.rewriteText(source, label, rewrite)
+}
+
+func (m Model) rewriteText(text, label string, rewrite func(ctx context.Context, text string, onChunk func(string)) error) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := m.requestContext(m.correctionTimeout(text))
+		defer cancel()
+
+		var rewritten strings.Builder
+		err := rewrite(ctx, text, func(chunk string) {
+			rewritten.WriteString(chunk)
+		})
+		if err != nil {
+			return errMsg{err: err}
+		}
+
+		return rewriteDoneMsg{
+			source:    text,
+			label:     label,
+			rewritten: trimTrailingWhitespace(rewritten.String()),
+		}
+	}
+}
+
+func (m Model) renderToneMenu() string {
+	menuStyle := lipgloss.NewStyle().
+		Border(m.theme.border()).
+		BorderForeground(m.theme.Header).
+		Padding(1, 2).
+		Width(m.width - 4)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(m.theme.Header)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("grammr - Rewrite Tone"))
+	content.WriteString("\n\n")
+	if m.correctedText != "" {
+		content.WriteString("Rewrite the corrected text as:\n\n")
+	} else {
+		content.WriteString("Rewrite the original text as:\n\n")
+	}
+	for i, tone := range corrector.Tones {
+		content.WriteString(fmt.Sprintf("  %-9d %s\n", i+1, styleLabel(tone)))
+	}
+	content.WriteString("\nQuick actions (from the main screen):\n\n")
+	content.WriteString("  S         Shorten\n")
+	content.WriteString("  X         Expand\n")
+	content.WriteString("  P         Simplify to plain language\n")
+	content.WriteString("\n  Esc       Cancel\n")
+
+	return menuStyle.Render(content.String())
+}
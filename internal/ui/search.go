@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// openSearch asks for the text to find in the focused pane
+func (m Model) openSearch() (tea.Model, tea.Cmd) {
+	m.mode = ModeSearch
+	m.searchInput.SetValue("")
+	return m, m.searchInput.Focus()
+}
+
+func (m Model) handleSearchPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeGlobal
+		m.searchInput.Blur()
+		return m, nil
+	case "enter":
+		m.mode = ModeGlobal
+		m.searchInput.Blur()
+		query := strings.TrimSpace(m.searchInput.Value())
+		if query == "" {
+			return m, nil
+		}
+		m.searchQuery = query
+		m.searchLine = -1
+		return m.findMatch(1)
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return m, cmd
+}
+
+// matchLines returns the lines of the focused pane, as shown on screen, that contain query,
+// ignoring case
+func (m Model) matchLines(query string) []int {
+	vp := m.paneViewport(m.focusedPane)
+	lines := strings.Split(ansi.Strip(m.paneContent(m.focusedPane, vp.Width)), "\n")
+	query = strings.ToLower(query)
+
+	var matches []int
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(line), query) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// findMatch scrolls the focused pane to the next (or previous) line with the last search,
+// wrapping around at either end
+func (m Model) findMatch(step int) (tea.Model, tea.Cmd) {
+	if m.searchQuery == "" {
+		return m, nil
+	}
+	if m.focusedPane == paneTranslation && m.translator == nil {
+		m.focusedPane = paneOriginal
+	}
+
+	matches := m.matchLines(m.searchQuery)
+	if len(matches) == 0 {
+		m.status = fmt.Sprintf("Not found: %s", m.searchQuery)
+		return m, nil
+	}
+
+	index := 0
+	if step > 0 {
+		for index < len(matches) && matches[index] <= m.searchLine {
+			index++
+		}
+		index %= len(matches)
+	} else {
+		index = len(matches) - 1
+		for index >= 0 && matches[index] >= m.searchLine {
+			index--
+		}
+		if index < 0 {
+			index = len(matches) - 1
+		}
+	}
+
+	m.searchLine = matches[index]
+	vp := m.paneViewport(m.focusedPane)
+	vp.SetYOffset(m.searchLine)
+	m.panes[m.focusedPane] = vp
+	m.status = fmt.Sprintf("/%s: match %d of %d in %s", m.searchQuery, index+1, len(matches), paneNames[m.focusedPane])
+	return m, nil
+}
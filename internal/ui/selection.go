@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// selectionDoneMsg carries the correction of the text selected in an editor
+type selectionDoneMsg struct {
+	mode      Mode   // The edit mode the text was selected in
+	start     int    // Rune offset of the selection in the editor's text
+	end       int    // Rune offset just past the selection
+	text      string // The selected text
+	corrected string
+}
+
+// selectableEditor returns the editor of the current edit mode if its text can be corrected in
+// parts. Translations are in another language than the corrector's, so they can't.
+func (m *Model) selectableEditor() *textarea.Model {
+	switch m.mode {
+	case ModeEditOriginal:
+		return &m.originalEditor
+	case ModeEditCorrected:
+		return &m.correctedEditor
+	}
+	return nil
+}
+
+// cursorOffset returns the position of an editor's cursor in runes from the start of its text
+func cursorOffset(editor textarea.Model) int {
+	offset := 0
+	for _, line := range strings.Split(editor.Value(), "\n")[:editor.Line()] {
+		offset += utf8.RuneCountInString(line) + 1
+	}
+	info := editor.LineInfo()
+	return offset + info.StartColumn + info.ColumnOffset
+}
+
+// splice replaces the runes from start to end of an editor's text, leaving the cursor after the
+// replacement
+func splice(editor *textarea.Model, start, end int, replacement string) {
+	runes := []rune(editor.Value())
+	// Insert what comes before the cursor at the start of what comes after it
+	editor.SetValue(string(runes[end:]))
+	for editor.Line() > 0 {
+		editor.CursorUp()
+	}
+	editor.CursorStart()
+	editor.InsertString(string(runes[:start]) + replacement)
+}
+
+// toggleSelection starts selecting at the cursor, or cancels the selection
+func (m Model) toggleSelection() (tea.Model, tea.Cmd) {
+	editor := m.selectableEditor()
+	if editor == nil {
+		return m, nil
+	}
+	if m.selecting {
+		m.selecting = false
+		m.status = "Selection cleared"
+		return m, nil
+	}
+	m.selecting = true
+	m.selectionMark = cursorOffset(*editor)
+	m.status = "Selecting: move to the other end, then Ctrl+G to correct it (Ctrl+Space to cancel)"
+	return m, nil
+}
+
+// correctSelection corrects the text between the selection mark and the cursor, instead of the
+// whole text
+func (m Model) correctSelection() (tea.Model, tea.Cmd) {
+	editor := m.selectableEditor()
+	if editor == nil || m.isLoading {
+		return m, nil
+	}
+	if !m.selecting {
+		m.status = "Press Ctrl+Space at one end of the text to correct, then Ctrl+G at the other"
+		return m, nil
+	}
+
+	runes := []rune(editor.Value())
+	cursor := cursorOffset(*editor)
+	start := min(max(min(m.selectionMark, cursor), 0), len(runes))
+	end := min(max(m.selectionMark, cursor), len(runes))
+	text := string(runes[start:end])
+	if strings.TrimSpace(text) == "" {
+		m.status = "Nothing selected"
+		return m, nil
+	}
+	if err := validation.ValidateText(text); err != nil {
+		m.status = fmt.Sprintf("✗ %v", err)
+		return m, nil
+	}
+
+	m, ok := m.ensureServices()
+	if !ok {
+		return m, nil
+	}
+	m.selecting = false
+	m.isLoading = true
+	m.status = fmt.Sprintf("[●] Correcting the selection (%s)...", plural(validation.CharCount(text), "char"))
+	m.estimate = m.correctionEstimate(text).String()
+	mode := m.mode
+	return m, func() tea.Msg {
+		ctx, cancel := m.requestContext(m.correctionTimeout(text))
+		defer cancel()
+
+		corrected, err := m.corrector.Correct(ctx, text)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return selectionDoneMsg{mode: mode, start: start, end: end, text: text, corrected: corrected}
+	}
+}
+
+// applySelection splices a corrected selection back into its editor, unless the text changed
+// in the meantime
+func (m Model) applySelection(msg selectionDoneMsg) Model {
+	m.isLoading = false
+	editor := m.selectableEditor()
+	if m.mode != msg.mode || editor == nil {
+		m.status = "The editor was closed before the selection was corrected"
+		return m
+	}
+	runes := []rune(editor.Value())
+	if msg.end > len(runes) || string(runes[msg.start:msg.end]) != msg.text {
+		m.status = "The selection changed while it was corrected, select it again"
+		return m
+	}
+
+	// Keep the spacing around the selection, which the correction trims
+	leading := msg.text[:len(msg.text)-len(strings.TrimLeft(msg.text, " \t\n"))]
+	trailing := msg.text[len(strings.TrimRight(msg.text, " \t\n")):]
+	splice(editor, msg.start, msg.end, leading+strings.TrimSpace(msg.corrected)+trailing)
+	m.status = "✓ Selection corrected"
+	return m
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSplice(t *testing.T) {
+	editor := textarea.New()
+	editor.SetWidth(40)
+	editor.SetValue("Intro.\nHello wrld, bye")
+	if got := cursorOffset(editor); got != 22 {
+		t.Fatalf("cursorOffset() = %d, want the end of the text", got)
+	}
+
+	splice(&editor, 13, 17, "world")
+	if got := editor.Value(); got != "Intro.\nHello world, bye" {
+		t.Fatalf("Value() = %q", got)
+	}
+	if got := cursorOffset(editor); got != 18 {
+		t.Errorf("cursorOffset() = %d, want it after the replacement", got)
+	}
+}
+
+func TestCorrectSelection(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "Intro. I has a apple."
+	m.correctedText = "Intro.\nI has a apple."
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+
+	// Nothing selected yet
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlG})
+	if m.isLoading {
+		t.Fatal("Ctrl+G without a selection should not correct anything")
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyCtrlAt})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyHome})
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = next.(Model)
+	if !m.isLoading || cmd == nil || m.selecting {
+		t.Fatalf("Ctrl+G should correct the selection, status = %q", m.status)
+	}
+	if m.status != "[●] Correcting the selection (14 chars)..." {
+		t.Errorf("status = %q, want the selected line counted", m.status)
+	}
+
+	next, _ = m.Update(selectionDoneMsg{mode: ModeEditCorrected, start: 7, end: 21, text: "I has a apple.", corrected: "I have an apple.\n"})
+	m = next.(Model)
+	if got := m.correctedEditor.Value(); got != "Intro.\nI have an apple." {
+		t.Fatalf("editor = %q, want the corrected selection spliced in", got)
+	}
+	if m.isLoading || m.status != "✓ Selection corrected" {
+		t.Errorf("isLoading = %v, status = %q", m.isLoading, m.status)
+	}
+
+	// A correction for text that changed since is dropped
+	next, _ = m.Update(selectionDoneMsg{mode: ModeEditCorrected, start: 0, end: 6, text: "Hello.", corrected: "Hi."})
+	m = next.(Model)
+	if m.correctedEditor.Value() != "Intro.\nI have an apple." {
+		t.Fatal("a stale correction should not change the editor")
+	}
+}
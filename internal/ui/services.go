@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/glossary"
+	"github.com/maximbilan/grammr/internal/ratelimit"
+	"github.com/maximbilan/grammr/internal/translator"
+)
+
+// configEditedMsg reports that the config file was closed in the external editor
+type configEditedMsg struct {
+	err error
+}
+
+// newServices creates the corrector and translator of cfg, with a provider from providers
+func newServices(cfg *config.Config, providers *providerFactory, rateLimits *ratelimit.Registry, gloss *glossary.Glossary) (*corrector.Corrector, *translator.Translator, error) {
+	prov, err := providers.get(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create provider: %w", err)
+	}
+	cor, err := newCorrector(cfg, prov, rateLimits, gloss)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create corrector: %w", err)
+	}
+	trans, err := newTranslator(cfg, prov, rateLimits, gloss)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cor, trans, nil
+}
+
+// ensureServices makes sure there is a corrector to send a request with. One that couldn't be
+// created, for example because of a bad API key, is created again from the config; if that still
+// fails, the status says why and how to fix it, and false is returned.
+func (m Model) ensureServices() (Model, bool) {
+	if m.corrector != nil {
+		return m, true
+	}
+	cor, trans, err := newServices(m.config, m.providers, m.rateLimits, m.glossary)
+	if err != nil {
+		m.isLoading = false
+		m.error = err.Error()
+		m.status = servicesStatus(err)
+		return m, false
+	}
+	m.corrector, m.translator = cor, trans
+	if m.originalText != "" {
+		m = m.applySourceLanguage(m.originalText)
+	}
+	return m.updateEditorDimensions(), true
+}
+
+// servicesStatus is the status shown when the corrector can't be created
+func servicesStatus(err error) string {
+	return fmt.Sprintf("✗ Error: %v (press , to edit the config)", err)
+}
+
+// openConfigEditor suspends the TUI and opens the config file in $EDITOR. The config is applied
+// when the editor is closed, like an edit made while grammr runs.
+func (m Model) openConfigEditor() (tea.Model, tea.Cmd) {
+	path, err := config.File()
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), config.ConfigDirPerm)
+	}
+	if err != nil {
+		return m, func() tea.Msg { return errMsg{err: fmt.Errorf("failed to open config: %w", err)} }
+	}
+	return m, tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return configEditedMsg{err: err}
+	})
+}
+
+// applyConfigEdit applies the config file after it was edited in the external editor
+func (m Model) applyConfigEdit(msg configEditedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("✗ Editor failed: %v", msg.err)
+		return m, nil
+	}
+	if m.configChanges != nil {
+		// The config watcher reloads the config
+		return m, nil
+	}
+	return m.reloadConfig()
+}
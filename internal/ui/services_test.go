@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/provider"
+)
+
+func TestStartWithBrokenProvider(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.APIKey = "bad"
+	m := newTestModel(t, cfg)
+	if m.corrector != nil {
+		t.Fatal("NewModel() should not create a corrector with an invalid key")
+	}
+	if !strings.Contains(m.status, "press , to edit the config") {
+		t.Errorf("status = %q, want the error and how to fix it", m.status)
+	}
+
+	m.originalText = "I has a apple."
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(Model)
+	if m.isLoading || m.corrector != nil {
+		t.Fatal("a correction should not start without a corrector")
+	}
+	if !strings.HasPrefix(m.status, "✗ Error:") {
+		t.Errorf("status = %q, want the error", m.status)
+	}
+
+	// Once the config is fixed, the corrector is created on first use
+	m.config.APIKey = newTestConfig().APIKey
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(Model)
+	if m.corrector == nil || !m.isLoading {
+		t.Fatalf("status = %q, want the correction started with a new corrector", m.status)
+	}
+}
+
+func TestApplyConfigEditFailure(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	next, _ := m.applyConfigEdit(configEditedMsg{err: errors.New("exit status 1")})
+	if got := next.(Model).status; !strings.Contains(got, "Editor failed") {
+		t.Errorf("status = %q, want the editor error", got)
+	}
+}
+
+func TestOfflineWithoutAPIKey(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.APIKey = ""
+	cfg.Offline = true
+	m := newTestModel(t, cfg)
+	if m.corrector == nil {
+		t.Fatalf("status = %q, want a corrector without an API key offline", m.status)
+	}
+	if !strings.Contains(m.renderStyleIndicator(), "Offline") {
+		t.Errorf("style indicator = %q, want offline shown", m.renderStyleIndicator())
+	}
+	if _, err := m.corrector.Correct(context.Background(), "I has a apple."); !errors.Is(err, provider.ErrOffline) {
+		t.Errorf("Correct() error = %v, want ErrOffline", err)
+	}
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"reflect"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/session"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// currentSession captures the texts of the panes, including unsaved edits, and the review in
+// progress
+func (m Model) currentSession() session.Session {
+	if m.composing {
+		// The typed text replaces everything else once it's corrected
+		return session.Session{Original: m.originalEditor.Value()}
+	}
+
+	s := session.Session{
+		Original:      m.originalText,
+		Corrected:     m.correctedText,
+		Result:        m.correctionResult,
+		Translation:   m.translatedText,
+		RewriteSource: m.rewriteSource,
+		RewriteLabel:  m.rewriteLabel,
+	}
+	switch m.mode {
+	case ModeEditOriginal:
+		s.Original = m.originalEditor.Value()
+	case ModeEditCorrected:
+		s.Corrected = m.correctedEditor.Value()
+	case ModeEditTranslation:
+		s.Translation = m.translationEditor.Value()
+	case ModeReviewDiff:
+		review := &session.Review{Current: m.currentChange}
+		for _, c := range m.diffChanges {
+			review.Changes = append(review.Changes, session.Change{
+				Op:          int(c.Type),
+				Removed:     c.Removed,
+				Added:       c.Added,
+				Applied:     c.Applied,
+				Skipped:     c.Skipped,
+				Replacement: c.Replacement,
+				Category:    c.Category,
+			})
+		}
+		s.Review = review
+	}
+	return s
+}
+
+// autosave writes the session when it changed since the last write, so quitting or crashing
+// keeps the texts for the next launch. Nothing is written while a question is open, so the
+// session offered at launch survives until it's answered. Like the history, a failed write is
+// not worth interrupting the user for.
+func (m Model) autosave() Model {
+	if m.sessionFile == "" || m.confirmation != nil {
+		return m
+	}
+	s := m.currentSession()
+	if reflect.DeepEqual(s, m.savedSession) {
+		return m
+	}
+	m.savedSession = s
+	if s.Empty() {
+		_ = session.Clear(m.sessionFile)
+	} else {
+		_ = session.Save(m.sessionFile, s)
+	}
+	return m
+}
+
+// offerSession asks whether to restore the session saved by the last run
+func (m Model) offerSession(s session.Session) Model {
+	next, _ := m.confirm("Restore the text from your last session?", func(m Model) (tea.Model, tea.Cmd) {
+		return m.restoreSession(s), nil
+	})
+	m = next.(Model)
+	m.confirmation.onNo = func(m Model) Model {
+		_ = session.Clear(m.sessionFile)
+		m.status = m.readyStatus()
+		return m
+	}
+	return m
+}
+
+// restoreSession puts the texts and the review of a saved session back
+func (m Model) restoreSession(s session.Session) Model {
+	m.originalText = s.Original
+	m.correctedText = s.Corrected
+	m.correctionResult = s.Result
+	m.translatedText = s.Translation
+	m.originalEditor.SetValue(s.Original)
+	m.correctedEditor.SetValue(s.Corrected)
+	m.translationEditor.SetValue(s.Translation)
+	m.rewriteSource = s.RewriteSource
+	m.rewriteLabel = s.RewriteLabel
+	if s.Original != "" {
+		m = m.applySourceLanguage(s.Original)
+	}
+	m.status = "✓ Restored your last session"
+
+	if s.Review != nil && len(s.Review.Changes) > 0 {
+		d := m.diff()
+		if !reviewMatches(s.Review.Changes, d) {
+			// Decisions made on other changes, such as with other diff settings, would be applied
+			// to the wrong ones
+			m.status = "✓ Restored your last session, but not its review: the changes are no longer the same"
+			return m
+		}
+		m.diffChanges = nil
+		for _, c := range s.Review.Changes {
+			m.diffChanges = append(m.diffChanges, DiffChange{
+				Type:        diffmatchpatch.Operation(c.Op),
+				Removed:     c.Removed,
+				Added:       c.Added,
+				Applied:     c.Applied,
+				Skipped:     c.Skipped,
+				Replacement: c.Replacement,
+				Category:    c.Category,
+			})
+		}
+		m.currentChange = min(max(s.Review.Current, 0), len(m.diffChanges))
+		m.reviewedText = d.reviewedText(m.diffChanges)
+		m.mode = ModeReviewDiff
+		m.status = reviewStatus(m.currentChange, len(m.diffChanges))
+	}
+	return m
+}
+
+// reviewMatches reports whether the changes of a saved review are those of d, in the same order
+func reviewMatches(changes []session.Change, d *Diff) bool {
+	if len(changes) != len(d.hunks) {
+		return false
+	}
+	for i, c := range changes {
+		if c.Removed != d.hunks[i].removed || c.Added != d.hunks[i].added {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/config"
+	"github.com/maximbilan/grammr/internal/session"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+func TestAutosaveAndRestore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	cfg := newTestConfig()
+	cfg.SessionEnabled = true
+
+	m := newTestModel(t, cfg)
+	if m.confirmation != nil {
+		t.Fatal("the first launch has no session to restore")
+	}
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m.correctionResult = "I have an apple."
+	m.translatedText = "Tengo una manzana."
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+
+	saved, err := session.Load(m.sessionFile)
+	if err != nil || saved == nil {
+		t.Fatalf("Load() = %v, %v, want the autosaved session", saved, err)
+	}
+	if saved.Original != "I has a apple." || saved.Translation != "Tengo una manzana." {
+		t.Fatalf("saved session = %+v, want the texts of the panes", saved)
+	}
+
+	restored := newTestModel(t, cfg)
+	if restored.confirmation == nil || restored.status != "Restore the text from your last session? (y/n)" {
+		t.Fatalf("status = %q, want the last session offered", restored.status)
+	}
+	restored = pressKey(t, restored, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if restored.originalText != "I has a apple." || restored.correctedText != "I have an apple." ||
+		restored.translatedText != "Tengo una manzana." || restored.correctedEditor.Value() != "I have an apple." {
+		t.Fatalf("restored texts = %q, %q, %q", restored.originalText, restored.correctedText, restored.translatedText)
+	}
+	if restored.status != "✓ Restored your last session" {
+		t.Errorf("status = %q", restored.status)
+	}
+
+	declined := newTestModel(t, cfg)
+	declined = pressKey(t, declined, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if declined.originalText != "" {
+		t.Fatal("declining should start with empty panes")
+	}
+	if saved, err := session.Load(declined.sessionFile); err != nil || saved != nil {
+		t.Fatalf("Load() after declining = %v, %v, want no session", saved, err)
+	}
+}
+
+func TestAutosaveUnsavedEdits(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "Old text."
+	m.correctedText = "Old text."
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A long new text")})
+	if s := m.currentSession(); s.Original != "A long new text" || s.Corrected != "" {
+		t.Fatalf("session while composing = %+v, want only the typed text", s)
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	if s := m.currentSession(); s.Original != "Old text." || s.Corrected == "Old text." {
+		t.Fatalf("session while editing = %+v, want the edited corrected text", s)
+	}
+}
+
+// wordDiffConfig returns a test config that diffs words
+func wordDiffConfig() *config.Config {
+	cfg := newTestConfig()
+	cfg.DiffGranularity = config.DiffWords
+	return cfg
+}
+
+func TestRestoreReview(t *testing.T) {
+	m := newTestModel(t, wordDiffConfig())
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m.mode = ModeReviewDiff
+	m.diffChanges = []DiffChange{
+		{Type: diffmatchpatch.DiffDelete, Removed: "has", Added: "have", Skipped: true},
+		{Type: diffmatchpatch.DiffDelete, Removed: "a", Added: "an"},
+	}
+	m.currentChange = 1
+
+	restored := newTestModel(t, wordDiffConfig()).restoreSession(m.currentSession())
+	if restored.mode != ModeReviewDiff || restored.currentChange != 1 || len(restored.diffChanges) != len(m.diffChanges) {
+		t.Fatalf("mode = %v, current = %d, want the review where it was left", restored.mode, restored.currentChange)
+	}
+	if !restored.diffChanges[0].Skipped || restored.diffChanges[0].Type != diffmatchpatch.DiffDelete ||
+		restored.diffChanges[0].Removed != "has" || restored.diffChanges[0].Added != "have" {
+		t.Errorf("change = %+v, want the decision kept", restored.diffChanges[0])
+	}
+	if restored.reviewedText != "I has a apple." {
+		t.Errorf("reviewedText = %q, want the skipped change left out", restored.reviewedText)
+	}
+}
+
+func TestRestoreReviewOfOtherChanges(t *testing.T) {
+	m := newTestModel(t, wordDiffConfig())
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m.mode = ModeReviewDiff
+	m.diffChanges = []DiffChange{
+		{Type: diffmatchpatch.DiffDelete, Removed: "has", Added: "have", Applied: true},
+		{Type: diffmatchpatch.DiffDelete, Removed: "a", Added: "an", Skipped: true},
+	}
+	saved := m.currentSession()
+	fewer := saved
+	fewer.Review = &session.Review{Changes: saved.Review.Changes[:1]}
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		session session.Session
+	}{
+		// Characters split the text into other changes than words
+		{name: "other diff granularity", cfg: newTestConfig(), session: saved},
+		{name: "fewer changes", cfg: wordDiffConfig(), session: fewer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := newTestModel(t, tt.cfg).restoreSession(tt.session)
+			if restored.mode == ModeReviewDiff || len(restored.diffChanges) != 0 {
+				t.Fatalf("mode = %v, changes = %+v, want the review dropped", restored.mode, restored.diffChanges)
+			}
+			if restored.correctedText != "I have an apple." || !strings.Contains(restored.status, "not its review") {
+				t.Errorf("correctedText = %q, status = %q, want the texts restored and the review dropped", restored.correctedText, restored.status)
+			}
+		})
+	}
+}
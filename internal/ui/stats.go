@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// textStats are the counts writers check against length limits
+type textStats struct {
+	words     int
+	chars     int
+	sentences int
+}
+
+// countText counts the words, characters and sentences of text. Characters are counted as the
+// user sees them, like charCountLabel; a sentence ends with ., ! or ? (or their CJK forms)
+// followed by a space or the end of the text.
+func countText(text string) textStats {
+	stats := textStats{chars: validation.CharCount(text)}
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			stats.words++
+		}
+	}
+
+	runes := []rune(text)
+	open := false // Inside a sentence that hasn't ended yet
+	for i, r := range runes {
+		switch {
+		case strings.ContainsRune(".!?…。！？", r):
+			last := i+1 == len(runes) || unicode.IsSpace(runes[i+1]) || strings.ContainsRune("\"'”’)", runes[i+1])
+			if open && (last || strings.ContainsRune("。！？", r)) {
+				stats.sentences++
+				open = false
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			open = true
+		}
+	}
+	if open {
+		stats.sentences++
+	}
+	return stats
+}
+
+func (s textStats) String() string {
+	return fmt.Sprintf("%s · %s · %s",
+		plural(s.words, "word"), plural(s.chars, "char"), plural(s.sentences, "sentence"))
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// signed formats a difference with its sign, using a real minus sign
+func signed(n int) string {
+	if n < 0 {
+		return fmt.Sprintf("−%d", -n)
+	}
+	return fmt.Sprintf("+%d", n)
+}
+
+// statsLine shows the counts of text, shown in pane p, and how the correction changed them
+func (m Model) statsLine(p pane, text string) string {
+	if text == "" {
+		return ""
+	}
+
+	stats := countText(text)
+	line := fmt.Sprintf("%s: %s", paneNames[p], stats)
+	if p == paneCorrected && m.originalText != "" {
+		original := countText(m.originalText)
+		line += fmt.Sprintf(" (%s words, %s chars vs original)",
+			signed(stats.words-original.words), signed(stats.chars-original.chars))
+	}
+	return lipgloss.NewStyle().
+		Foreground(m.theme.Muted).
+		Padding(0, 1).
+		Render(line)
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want textStats
+	}{
+		{name: "empty", text: "", want: textStats{}},
+		{name: "one sentence", text: "I have an apple.", want: textStats{words: 4, chars: 16, sentences: 1}},
+		{name: "no final period", text: "Hello there. How are you", want: textStats{words: 5, chars: 24, sentences: 2}},
+		{name: "several endings", text: "Really?! Yes... \"Fine.\" Done", want: textStats{words: 4, chars: 28, sentences: 4}},
+		{name: "punctuation is not a word", text: "One - two", want: textStats{words: 2, chars: 9, sentences: 1}},
+		{name: "decimal numbers", text: "It costs 3.50 now.", want: textStats{words: 4, chars: 18, sentences: 1}},
+		{name: "chinese", text: "你好。谢谢！", want: textStats{words: 1, chars: 6, sentences: 2}},
+		{name: "emoji", text: "Nice 👍🏽", want: textStats{words: 1, chars: 6, sentences: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countText(tt.text); got != tt.want {
+				t.Errorf("countText(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsLine(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	if got := m.statsLine(paneOriginal, ""); got != "" {
+		t.Fatalf("statsLine() = %q, want empty without a text", got)
+	}
+
+	m.originalText = "I has a apple. It are red."
+	m.correctedText = "I have an apple. It is red."
+	if got := removeANSICodes(m.statsLine(paneOriginal, m.originalText)); !strings.Contains(got, "Original Text: 7 words · 26 chars · 2 sentences") {
+		t.Fatalf("statsLine() = %q", got)
+	}
+	if got := removeANSICodes(m.statsLine(paneCorrected, m.correctedText)); !strings.Contains(got, "(+0 words, +1 chars vs original)") {
+		t.Fatalf("statsLine() = %q, want the difference to the original", got)
+	}
+
+	// The main view shows the counts of the focused pane
+	m.focusedPane = paneCorrected
+	if !strings.Contains(m.View(), "Corrected Text: 7 words") {
+		t.Fatal("view should show the counts of the corrected text")
+	}
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamInterval is how often a streamed response is redrawn. Redrawing on every token copies
+// and renders the whole text each time, which makes long responses crawl.
+const streamInterval = 50 * time.Millisecond
+
+// streamBuffer collects a streamed response as it arrives, for the UI to show on a ticker
+type streamBuffer struct {
+	mu    sync.Mutex
+	text  strings.Builder
+	shown int // Length of the text when it was last taken
+}
+
+func (b *streamBuffer) write(chunk string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.text.WriteString(chunk)
+}
+
+// String returns the text received so far
+func (b *streamBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.text.String()
+}
+
+// take returns the text received so far, and whether it grew since the last call
+func (b *streamBuffer) take() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	grew := b.text.Len() > b.shown
+	b.shown = b.text.Len()
+	return b.text.String(), grew
+}
+
+// streamTickMsg shows what a stream received since the last tick
+type streamTickMsg struct {
+	buffer      *streamBuffer
+	source      string // Text being corrected or translated, to stop ticking for a stale stream
+	translation bool   // A translation rather than a correction
+}
+
+// tickStream schedules the next tick of a stream
+func tickStream(msg streamTickMsg) tea.Cmd {
+	return tea.Tick(streamInterval, func(time.Time) tea.Msg { return msg })
+}
+
+// showStream shows the text of a stream while it is still the one in progress. The done message
+// of the stream replaces it with the complete, cleaned up response.
+func (m Model) showStream(msg streamTickMsg) (tea.Model, tea.Cmd) {
+	if msg.translation {
+		if !m.isTranslating || msg.source != m.translationSource() {
+			return m, nil
+		}
+		if text, grew := msg.buffer.take(); grew {
+			m.translatedText = text
+			m.translationEditor.SetValue(text)
+		}
+		return m, tickStream(msg)
+	}
+
+	// Edits merged into the correction stay on screen until it is done
+	if !m.isLoading || m.mergeEdits || msg.source != m.originalText {
+		return m, nil
+	}
+	if text, grew := msg.buffer.take(); grew {
+		m.correctedText = text
+		m.correctedEditor.SetValue(text)
+	}
+	return m, tickStream(msg)
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maximbilan/grammr/internal/config"
+)
+
+// Built-in themes
+const (
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeColorBlind   = "colorblind"
+)
+
+// Theme maps what text means to the color it is shown in
+type Theme struct {
+	Header      lipgloss.Color // Titles, section headings and dialog borders
+	Border      lipgloss.Color // Borders around the text panes
+	Status      lipgloss.Color // The status line
+	Muted       lipgloss.Color // Hints, footers and unchanged text in diffs
+	Added       lipgloss.Color // Text a correction adds
+	Removed     lipgloss.Color // Text a correction removes
+	Highlight   lipgloss.Color // The change under review, glossary issues and work in progress
+	Error       lipgloss.Color
+	Original    lipgloss.Color // The original text label
+	Corrected   lipgloss.Color // The corrected text label and finished reviews
+	Translation lipgloss.Color // The translation label
+	// Markers puts [-…-] around removed and {+…+} around added text, so changes can be told apart
+	// without relying on color
+	Markers bool
+	// Plain renders without colors, text attributes, box drawing or symbols, for screen readers
+	// and basic terminals
+	Plain bool
+}
+
+// themes are the built-in palettes. Dark uses the terminal's own colors, so it also suits most
+// light terminals; light uses darker shades that stay readable on a white background. Colorblind
+// shows changes in blue and orange, which stay distinct with red-green color blindness, and marks
+// them with symbols as well.
+var themes = map[string]Theme{
+	ThemeDark: {
+		Header: "6", Border: "8", Status: "8", Muted: "8",
+		Added: "10", Removed: "9", Highlight: "11", Error: "9",
+		Original: "4", Corrected: "2", Translation: "5",
+	},
+	ThemeLight: {
+		Header: "#005f87", Border: "#8a8a8a", Status: "#585858", Muted: "#6c6c6c",
+		Added: "#007a00", Removed: "#c00000", Highlight: "#a65e00", Error: "#d70000",
+		Original: "#0000af", Corrected: "#005f00", Translation: "#870087",
+	},
+	ThemeHighContrast: {
+		Header: "#00ffff", Border: "#ffffff", Status: "#ffffff", Muted: "#d0d0d0",
+		Added: "#00ff00", Removed: "#ff5f5f", Highlight: "#ffff00", Error: "#ff0000",
+		Original: "#5fafff", Corrected: "#00ff00", Translation: "#ff87ff",
+	},
+	ThemeColorBlind: {
+		Header: "#56b4e9", Border: "8", Status: "8", Muted: "8",
+		Added: "#56b4e9", Removed: "#e69f00", Highlight: "#f0e442", Error: "#d55e00",
+		Original: "#0072b2", Corrected: "#009e73", Translation: "#cc79a7",
+		Markers: true,
+	},
+}
+
+// Themes lists the built-in theme names
+var Themes = []string{ThemeDark, ThemeLight, ThemeHighContrast, ThemeColorBlind}
+
+// colorPattern matches the colors a palette accepts: an ANSI color number or a hex color
+var colorPattern = regexp.MustCompile(`^(?:[0-9]{1,3}|#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3})$`)
+
+// newTheme returns the built-in theme called name, dark when empty, with the roles in colors
+// replaced
+func newTheme(name string, colors map[string]string) (Theme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = ThemeDark
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme: %s (supported: %s)", name, strings.Join(Themes, ", "))
+	}
+
+	roles := theme.roles()
+	for role, color := range colors {
+		target, ok := roles[strings.ToLower(strings.TrimSpace(role))]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown theme color: %s (supported: %s)", role, strings.Join(themeRoles(), ", "))
+		}
+		color = strings.TrimSpace(color)
+		if !colorPattern.MatchString(color) {
+			return Theme{}, fmt.Errorf("invalid color for %s: %q (use an ANSI color number or #rrggbb)", role, color)
+		}
+		*target = lipgloss.Color(color)
+	}
+	return theme, nil
+}
+
+// themeFor returns the theme set in cfg, made plain in accessible mode
+func themeFor(cfg *config.Config) (Theme, error) {
+	theme, err := newTheme(cfg.Theme, cfg.ThemeColors)
+	if err != nil {
+		return Theme{}, err
+	}
+	if cfg.Accessible {
+		theme.Plain = true
+		theme.Markers = true
+	}
+	return theme, nil
+}
+
+// border returns the border drawn around panes and dialogs. The plain border is blank but takes
+// the same space, so the layout doesn't change.
+func (t Theme) border() lipgloss.Border {
+	if t.Plain {
+		return lipgloss.HiddenBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
+// plainSymbols spells out the symbols of the TUI in ASCII
+var plainSymbols = strings.NewReplacer(
+	"✓", "[ok]",
+	"✗", "[error]",
+	"⚠", "[warning]",
+	"ℹ", "[info]",
+	"●", "*",
+	"→", "->",
+	"←", "<-",
+	"↑", "Up",
+	"↓", "Down",
+	"↕", "scrolled",
+	"─", "-",
+	"·", "|",
+	"−", "-",
+	"…", "...",
+	"•", "*",
+	"█", "#",
+	"░", ".",
+)
+
+// output finishes a rendered view: a plain theme strips the colors and text attributes and spells
+// out the symbols
+func (t Theme) output(view string) string {
+	if !t.Plain {
+		return view
+	}
+	return plainSymbols.Replace(ansi.Strip(view))
+}
+
+// removedMarkers returns the text to put around removed text, if the theme uses markers
+func (t Theme) removedMarkers() (open, close string) {
+	if t.Markers {
+		return "[-", "-]"
+	}
+	return "", ""
+}
+
+// addedMarkers returns the text to put around added text, if the theme uses markers
+func (t Theme) addedMarkers() (open, close string) {
+	if t.Markers {
+		return "{+", "+}"
+	}
+	return "", ""
+}
+
+// roles maps the names of the roles in theme_colors to the colors of t
+func (t *Theme) roles() map[string]*lipgloss.Color {
+	return map[string]*lipgloss.Color{
+		"header":      &t.Header,
+		"border":      &t.Border,
+		"status":      &t.Status,
+		"muted":       &t.Muted,
+		"added":       &t.Added,
+		"removed":     &t.Removed,
+		"highlight":   &t.Highlight,
+		"error":       &t.Error,
+		"original":    &t.Original,
+		"corrected":   &t.Corrected,
+		"translation": &t.Translation,
+	}
+}
+
+func themeRoles() []string {
+	var names []string
+	for name := range (&Theme{}).roles() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
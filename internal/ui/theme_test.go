@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestNewTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		theme   string
+		colors  map[string]string
+		check   func(Theme) bool
+		wantErr string
+	}{
+		{
+			name:  "dark by default",
+			theme: "",
+			check: func(th Theme) bool { return th == themes[ThemeDark] },
+		},
+		{
+			name:  "built-in theme",
+			theme: "High-Contrast",
+			check: func(th Theme) bool { return th == themes[ThemeHighContrast] },
+		},
+		{
+			name:  "color-blind theme marks changes",
+			theme: ThemeColorBlind,
+			check: func(th Theme) bool { return th.Markers && !themes[ThemeDark].Markers },
+		},
+		{
+			name:   "custom colors replace roles",
+			theme:  ThemeLight,
+			colors: map[string]string{"Added": "#00aa00", "removed": "1"},
+			check: func(th Theme) bool {
+				return th.Added == lipgloss.Color("#00aa00") && th.Removed == lipgloss.Color("1") && th.Header == themes[ThemeLight].Header
+			},
+		},
+		{
+			name:    "unknown theme",
+			theme:   "solarized",
+			wantErr: "unknown theme: solarized (supported: dark, light, high-contrast, colorblind)",
+		},
+		{
+			name:    "unknown role",
+			colors:  map[string]string{"background": "0"},
+			wantErr: "unknown theme color: background",
+		},
+		{
+			name:    "invalid color",
+			colors:  map[string]string{"header": "teal"},
+			wantErr: `invalid color for header: "teal"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme, err := newTheme(tt.theme, tt.colors)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newTheme() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newTheme() error = %v", err)
+			}
+			if !tt.check(theme) {
+				t.Errorf("newTheme() = %+v", theme)
+			}
+		})
+	}
+}
+
+func TestThemesDefineEveryRole(t *testing.T) {
+	for _, name := range Themes {
+		theme := themes[name]
+		for role, color := range theme.roles() {
+			if *color == "" {
+				t.Errorf("theme %s has no %s color", name, role)
+			}
+		}
+	}
+}
+
+func TestNewModelRejectsUnknownTheme(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Theme = "neon"
+	if _, err := NewModel(cfg); err == nil {
+		t.Fatal("NewModel() should reject an unknown theme")
+	}
+}
+
+func TestAccessibleView(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Accessible = true
+	m := newTestModel(t, cfg)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = next.(Model)
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m.status = "✓ Done"
+
+	view := m.View()
+	if strings.Contains(view, "\x1b[") {
+		t.Error("an accessible view should have no escape codes")
+	}
+	for _, symbol := range []string{"╭", "│", "─", "✓"} {
+		if strings.Contains(view, symbol) {
+			t.Errorf("an accessible view should not contain %q", symbol)
+		}
+	}
+	for _, want := range []string{"[ok] Done", "[-s-]", "{+n+}"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q:\n%s", want, view)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// translationSource returns the text the translation pane translates: the corrected text by
+// default, or the original to understand what it says
+func (m Model) translationSource() string {
+	if m.translateOriginal {
+		return m.originalText
+	}
+	return m.correctedText
+}
+
+// translationLabel names the translation pane after the text it translates
+func (m Model) translationLabel() string {
+	if m.translateOriginal {
+		return "Translation of Original"
+	}
+	return "Translation of Corrected"
+}
+
+// toggleTranslationSource switches between translating the original and the corrected text
+func (m Model) toggleTranslationSource() (tea.Model, tea.Cmd) {
+	if m.translator == nil {
+		return m, nil
+	}
+	m.translateOriginal = !m.translateOriginal
+	m.translatedText = ""
+	m.translationEditor.SetValue("")
+	m.status = "Translating the corrected text"
+	if m.translateOriginal {
+		m.status = "Translating the original text"
+	}
+
+	text := m.translationSource()
+	if m.isLoading || !m.shouldTranslate(text) {
+		// A correction in progress translates its result when it finishes
+		m.isTranslating = false
+		return m, nil
+	}
+	m.isTranslating = true
+	return m, m.streamTranslation(text)
+}
+
+// translationHash returns the cache key of a translation of text into the translation language
+func (m Model) translationHash(text string) string {
+	language := m.translator.Language()
+	return m.cache.TranslationHash(text, language, m.config.Model, m.config.FormalityFor(language))
+}
+
+// cachedTranslation returns a cached translation of text, or "" when there is none
+func (m Model) cachedTranslation(text string) string {
+	if m.cache == nil || m.translator == nil {
+		return ""
+	}
+	return m.cache.Get(m.translationHash(text))
+}
+
+// saveTranslationToCache saves a translation so the same text isn't translated, and paid for,
+// twice
+func (m Model) saveTranslationToCache(text, translated string) {
+	if m.cache == nil || m.translator == nil || translated == "" {
+		return
+	}
+	// A failed cache write doesn't affect the translation
+	_ = m.cache.Set(m.translationHash(text), text, translated)
+}
+
+// shouldRomanize reports whether a romanized line should be shown beneath translated
+func (m Model) shouldRomanize(translated string) bool {
+	return m.config.Romanize && m.translator != nil && m.translator.CanRomanize() && translated != ""
+}
+
+// romanizeTranslation spells out a translation in Latin letters with a second, small request
+func (m Model) romanizeTranslation(translated string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := m.requestContext(m.translationTimeout(translated))
+		defer cancel()
+
+		romanized, err := m.translator.Romanize(ctx, translated)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to romanize translation: %w", err)}
+		}
+		return romanizationDoneMsg{
+			translated: translated,
+			romanized:  trimTrailingWhitespace(romanized),
+		}
+	}
+}
+
+// currentRomanization returns the romanization of the translation on screen, or "" when there is
+// none or the translation changed since
+func (m Model) currentRomanization() string {
+	if m.romanizedSource == "" || m.romanizedSource != m.translatedText {
+		return ""
+	}
+	return m.romanized
+}
+
+// toggleTranslation turns translation off, or asks for the language to translate into to turn it
+// on. The choice is saved to config like a style switch.
+func (m Model) toggleTranslation() (tea.Model, tea.Cmd) {
+	if m.translator == nil {
+		m.mode = ModeTranslationLanguage
+		m.languageInput.CursorEnd()
+		return m, m.languageInput.Focus()
+	}
+
+	// Offer the same language the next time translation is turned on
+	m.languageInput.SetValue(m.config.TranslationLanguage)
+	m.config.TranslationLanguage = ""
+	m.translatedText = ""
+	m.translationEditor.SetValue("")
+	m.isTranslating = false
+	if m.focusedPane == paneTranslation {
+		m.focusedPane = paneOriginal
+	}
+	next, cmd := m.reloadCorrector("Translation off")
+	return next.(Model).updateEditorDimensions(), cmd
+}
+
+func (m Model) handleLanguagePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = ModeGlobal
+		m.languageInput.Blur()
+		m.status = "Cancelled"
+		return m, nil
+	case "enter":
+		language := strings.ToLower(strings.TrimSpace(m.languageInput.Value()))
+		if language == "" {
+			return m, nil
+		}
+		m.mode = ModeGlobal
+		m.languageInput.Blur()
+		return m.enableTranslation(language)
+	}
+
+	var cmd tea.Cmd
+	m.languageInput, cmd = m.languageInput.Update(msg)
+	return m, cmd
+}
+
+// enableTranslation turns translation into language on and translates the text on screen
+func (m Model) enableTranslation(language string) (tea.Model, tea.Cmd) {
+	m.config.TranslationLanguage = language
+	next, cmd := m.reloadCorrector(fmt.Sprintf("Translating into %s", styleLabel(language)))
+	m = next.(Model).updateEditorDimensions()
+	if m.translator == nil {
+		// Creating the translator failed; cmd reports why
+		m.config.TranslationLanguage = ""
+		return m, cmd
+	}
+
+	text := m.translationSource()
+	if m.isLoading || !m.shouldTranslate(text) {
+		// A correction in progress translates its result when it finishes
+		return m, cmd
+	}
+	m.isTranslating = true
+	return m, tea.Batch(cmd, m.streamTranslation(text))
+}
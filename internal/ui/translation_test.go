@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maximbilan/grammr/internal/config"
+)
+
+func TestToggleTranslation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m.sourceLanguage = "english"
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = next.(Model)
+	if m.mode != ModeTranslationLanguage {
+		t.Fatalf("mode = %v, want the language prompt", m.mode)
+	}
+	if view := removeANSICodes(m.View()); !strings.Contains(view, "Translate into:") {
+		t.Errorf("view should show the language prompt:\n%s", view)
+	}
+
+	// Enter without a language keeps asking
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.mode != ModeTranslationLanguage {
+		t.Fatalf("mode = %v, want the prompt kept open", m.mode)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("French")})
+	m = next.(Model)
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.mode != ModeGlobal || m.translator == nil || m.translator.Language() != "french" {
+		t.Fatalf("mode = %v, translator = %v, want translation into french", m.mode, m.translator)
+	}
+	if !m.isTranslating || cmd == nil {
+		t.Error("the corrected text should be translated right away")
+	}
+	if view := removeANSICodes(m.View()); !strings.Contains(view, "Translation of Corrected") {
+		t.Errorf("view should show the translation pane:\n%s", view)
+	}
+
+	m.isTranslating = false
+	m.translatedText = "J'ai une pomme."
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = next.(Model)
+	if m.translator != nil || m.translatedText != "" || m.status != "Translation off" {
+		t.Fatalf("translator = %v, translation = %q, status = %q, want translation off", m.translator, m.translatedText, m.status)
+	}
+	if view := removeANSICodes(m.View()); strings.Contains(view, "Translation of Corrected") {
+		t.Errorf("view should hide the translation pane:\n%s", view)
+	}
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.TranslationLanguage != "" {
+		t.Errorf("saved translation language = %q, want none", saved.TranslationLanguage)
+	}
+
+	// The prompt offers the last language again, and Esc leaves translation off
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = next.(Model)
+	if got := m.languageInput.Value(); got != "french" {
+		t.Errorf("prompt = %q, want the last language", got)
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if m.mode != ModeGlobal || m.translator != nil {
+		t.Errorf("mode = %v, translator = %v, want the prompt closed without a change", m.mode, m.translator)
+	}
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxUndo is how many steps can be undone
+const maxUndo = 50
+
+// snapshot is the state of the panes and of a review, restored by undo and redo
+type snapshot struct {
+	mode             Mode // ModeGlobal or ModeReviewDiff
+	originalText     string
+	correctedText    string
+	translatedText   string
+	correctionResult string
+	correctionSource string
+	rewriteSource    string
+	rewriteLabel     string
+	romanized        string
+	romanizedSource  string
+	showDiff         bool
+	diffChanges      []DiffChange
+	currentChange    int
+	reviewedText     string
+}
+
+func (m Model) snapshot() snapshot {
+	mode := ModeGlobal
+	if m.mode == ModeReviewDiff {
+		mode = ModeReviewDiff
+	}
+	return snapshot{
+		mode:             mode,
+		originalText:     m.originalText,
+		correctedText:    m.correctedText,
+		translatedText:   m.translatedText,
+		correctionResult: m.correctionResult,
+		correctionSource: m.correctionSource,
+		rewriteSource:    m.rewriteSource,
+		rewriteLabel:     m.rewriteLabel,
+		romanized:        m.romanized,
+		romanizedSource:  m.romanizedSource,
+		showDiff:         m.showDiff,
+		// Review decisions are changed in place, so keep a copy
+		diffChanges:   slices.Clone(m.diffChanges),
+		currentChange: m.currentChange,
+		reviewedText:  m.reviewedText,
+	}
+}
+
+func (m Model) restore(s snapshot) Model {
+	languageChanged := s.originalText != m.originalText
+	m.mode = s.mode
+	m.originalText = s.originalText
+	m.correctedText = s.correctedText
+	m.translatedText = s.translatedText
+	m.originalEditor.SetValue(s.originalText)
+	m.correctedEditor.SetValue(s.correctedText)
+	m.translationEditor.SetValue(s.translatedText)
+	m.correctionResult = s.correctionResult
+	m.correctionSource = s.correctionSource
+	m.rewriteSource = s.rewriteSource
+	m.rewriteLabel = s.rewriteLabel
+	m.romanized = s.romanized
+	m.romanizedSource = s.romanizedSource
+	m.showDiff = s.showDiff
+	m.diffChanges = slices.Clone(s.diffChanges)
+	m.currentChange = s.currentChange
+	m.reviewedText = s.reviewedText
+	m.alternatives = nil
+	m.isTranslating = false
+	m.estimate = ""
+	m.error = ""
+	if languageChanged && s.originalText != "" {
+		m = m.applySourceLanguage(s.originalText)
+	}
+	return m
+}
+
+// pushUndo saves a state to return to with undo. A new change can't be redone past.
+func (m Model) pushUndo(s snapshot) Model {
+	// Clip, so an older copy of the model never shares the backing array
+	m.undoStack = append(slices.Clip(m.undoStack), s)
+	if len(m.undoStack) > maxUndo {
+		m.undoStack = m.undoStack[len(m.undoStack)-maxUndo:]
+	}
+	m.redoStack = nil
+	return m
+}
+
+// saveUndo saves the current state before a change
+func (m Model) saveUndo() Model {
+	return m.pushUndo(m.snapshot())
+}
+
+// undo goes back to the state before the last paste, correction, rewrite, edit or review decision
+func (m Model) undo() (tea.Model, tea.Cmd) {
+	if m.isLoading {
+		m.status = "Wait for the correction to finish before undoing"
+		return m, nil
+	}
+	if len(m.undoStack) == 0 {
+		m.status = "Nothing to undo"
+		return m, nil
+	}
+	previous := m.undoStack[len(m.undoStack)-1]
+	m.redoStack = append(slices.Clip(m.redoStack), m.snapshot())
+	m.undoStack = slices.Clip(m.undoStack[:len(m.undoStack)-1])
+	m = m.restore(previous)
+	m.status = m.undoStatus("Undone")
+	return m, nil
+}
+
+// redo reapplies the last undone change
+func (m Model) redo() (tea.Model, tea.Cmd) {
+	if m.isLoading {
+		m.status = "Wait for the correction to finish before redoing"
+		return m, nil
+	}
+	if len(m.redoStack) == 0 {
+		m.status = "Nothing to redo"
+		return m, nil
+	}
+	next := m.redoStack[len(m.redoStack)-1]
+	m.undoStack = append(slices.Clip(m.undoStack), m.snapshot())
+	m.redoStack = slices.Clip(m.redoStack[:len(m.redoStack)-1])
+	m = m.restore(next)
+	m.status = m.undoStatus("Redone")
+	return m, nil
+}
+
+// undoStatus describes the state undo or redo returned to
+func (m Model) undoStatus(done string) string {
+	if m.mode == ModeReviewDiff {
+		return done + " - " + reviewStatus(m.currentChange, len(m.diffChanges))
+	}
+	return done
+}
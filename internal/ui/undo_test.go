@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pressKey(t *testing.T, m Model, msg tea.KeyMsg) Model {
+	t.Helper()
+	nextAny, _ := m.Update(msg)
+	return nextAny.(Model)
+}
+
+var (
+	ctrlZ = tea.KeyMsg{Type: tea.KeyCtrlZ}
+	ctrlY = tea.KeyMsg{Type: tea.KeyCtrlY}
+)
+
+func TestUndoRedoCorrections(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+
+	nextAny, _ := m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have an apple."})
+	m = nextAny.(Model)
+	nextAny, _ = m.Update(correctionDoneMsg{original: "I has a pear.", corrected: "I have a pear.", cached: true})
+	m = nextAny.(Model)
+	nextAny, _ = m.Update(rewriteDoneMsg{source: "I have a pear.", label: "Formal", rewritten: "I possess a pear."})
+	m = nextAny.(Model)
+
+	m = pressKey(t, m, ctrlZ)
+	if m.correctedText != "I have a pear." || m.rewriteLabel != "" {
+		t.Fatalf("after undoing the rewrite correctedText = %q, label = %q", m.correctedText, m.rewriteLabel)
+	}
+	m = pressKey(t, m, ctrlZ)
+	if m.originalText != "I has a apple." || m.correctedEditor.Value() != "I have an apple." {
+		t.Fatalf("after undoing the paste original = %q, corrected editor = %q", m.originalText, m.correctedEditor.Value())
+	}
+
+	m = pressKey(t, m, ctrlY)
+	m = pressKey(t, m, ctrlY)
+	if m.correctedText != "I possess a pear." || m.rewriteLabel != "Formal" {
+		t.Fatalf("after redoing correctedText = %q, label = %q", m.correctedText, m.rewriteLabel)
+	}
+	if m = pressKey(t, m, ctrlY); m.status != "Nothing to redo" {
+		t.Fatalf("status = %q, want nothing left to redo", m.status)
+	}
+}
+
+func TestUndoReviewDecisions(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if m.mode != ModeReviewDiff || len(m.diffChanges) < 2 {
+		t.Fatalf("mode = %v with %d changes, want a review", m.mode, len(m.diffChanges))
+	}
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m = pressKey(t, m, ctrlZ)
+	if m.currentChange != 0 || m.diffChanges[0].Applied {
+		t.Fatalf("after undo currentChange = %d, applied = %v, want the first change undecided", m.currentChange, m.diffChanges[0].Applied)
+	}
+
+	// Finishing the review can be undone too
+	for m.mode == ModeReviewDiff {
+		m = pressKey(t, m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	}
+	if m.correctedText != "I has a apple." {
+		t.Fatalf("correctedText = %q, want every change skipped", m.correctedText)
+	}
+	m = pressKey(t, m, ctrlZ)
+	if m.mode != ModeReviewDiff || m.currentChange != len(m.diffChanges)-1 {
+		t.Fatalf("mode = %v at change %d, want the review back at its last change", m.mode, m.currentChange)
+	}
+	if m.correctedText != "I have an apple." {
+		t.Fatalf("correctedText = %q, want the correction back", m.correctedText)
+	}
+}
+
+func TestUndoEdit(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	m.originalText = "I has a apple."
+	m.correctedText = "I have an apple."
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	m.correctedEditor.SetValue("I have one apple.")
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.correctedText != "I have one apple." {
+		t.Fatalf("correctedText = %q, want the edit", m.correctedText)
+	}
+
+	m = pressKey(t, m, ctrlZ)
+	if m.correctedText != "I have an apple." {
+		t.Fatalf("correctedText = %q, want the edit undone", m.correctedText)
+	}
+
+	// Leaving the editor without changes isn't a step to undo
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m = pressKey(t, m, ctrlZ); m.status != "Nothing to undo" {
+		t.Fatalf("status = %q, want nothing to undo", m.status)
+	}
+}
+
+func TestUndoWaitsForCorrection(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	nextAny, _ := m.Update(correctionDoneMsg{original: "I has a apple.", corrected: "I have an apple."})
+	m = nextAny.(Model)
+	m.isLoading = true
+
+	m = pressKey(t, m, ctrlZ)
+	if m.originalText != "I has a apple." {
+		t.Fatalf("originalText = %q, want no undo while correcting", m.originalText)
+	}
+}
+
+func TestUndoStackIsBounded(t *testing.T) {
+	m := newTestModel(t, newTestConfig())
+	for i := 0; i < maxUndo+10; i++ {
+		m = m.saveUndo()
+	}
+	if len(m.undoStack) != maxUndo {
+		t.Fatalf("undo stack has %d entries, want %d", len(m.undoStack), maxUndo)
+	}
+}
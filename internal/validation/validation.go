@@ -4,12 +4,18 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+
+	"github.com/rivo/uniseg"
 )
 
 const (
 	// MaxInputLength is the maximum allowed length for input text (100K characters)
-	// This prevents excessive API costs and potential memory issues
+	// This prevents excessive API costs and potential memory issues.
+	// Characters are counted as grapheme clusters, so CJK text and emoji aren't penalized for their size in bytes.
 	MaxInputLength = 100000
+	// MaxDocumentLength is the maximum length of a text sent to grammr's servers. Texts over
+	// MaxInputLength characters are corrected in chunks.
+	MaxDocumentLength = 10 * MaxInputLength
 )
 
 // ValidateAPIKey validates the format of an OpenAI API key
@@ -28,13 +34,41 @@ func ValidateAPIKey(apiKey string) error {
 	return nil
 }
 
-// ValidateTextInput validates text input for API calls
-func ValidateTextInput(text string, onChunk interface{}) error {
+// CharCount returns the number of user-perceived characters (grapheme clusters) in text, so an
+// emoji or a letter with combining accents counts as one character
+func CharCount(text string) int {
+	return uniseg.GraphemeClusterCount(text)
+}
+
+// ExceedsMaxLength reports whether text is longer than MaxInputLength characters
+func ExceedsMaxLength(text string) bool {
+	// A text never has more characters than bytes, so short texts don't need counting
+	return len(text) > MaxInputLength && CharCount(text) > MaxInputLength
+}
+
+// ValidateNotEmpty checks that there is a text, of any length
+func ValidateNotEmpty(text string) error {
 	if text == "" {
 		return fmt.Errorf("text cannot be empty")
 	}
-	if len(text) > MaxInputLength {
-		return fmt.Errorf("text exceeds maximum length of %d characters (got %d)", MaxInputLength, len(text))
+	return nil
+}
+
+// ValidateText checks that text is neither empty nor longer than MaxInputLength characters
+func ValidateText(text string) error {
+	if err := ValidateNotEmpty(text); err != nil {
+		return err
+	}
+	if ExceedsMaxLength(text) {
+		return fmt.Errorf("text exceeds maximum length of %d characters (got %d)", MaxInputLength, CharCount(text))
+	}
+	return nil
+}
+
+// ValidateTextInput validates text input for API calls
+func ValidateTextInput(text string, onChunk interface{}) error {
+	if err := ValidateText(text); err != nil {
+		return err
 	}
 	if isNilValue(onChunk) {
 		return fmt.Errorf("onChunk callback cannot be nil")
@@ -87,6 +87,24 @@ func TestValidateTextInput(t *testing.T) {
 			onChunk: validChunk,
 			wantErr: true,
 		},
+		{
+			name:    "CJK text within the limit",
+			text:    strings.Repeat("漢", MaxInputLength),
+			onChunk: validChunk,
+			wantErr: false,
+		},
+		{
+			name:    "emoji within the limit",
+			text:    strings.Repeat("👍🏽", MaxInputLength),
+			onChunk: validChunk,
+			wantErr: false,
+		},
+		{
+			name:    "CJK text too long",
+			text:    strings.Repeat("漢", MaxInputLength+1),
+			onChunk: validChunk,
+			wantErr: true,
+		},
 		{
 			name:    "nil callback",
 			text:    "Hello world",
@@ -110,3 +128,26 @@ func TestValidateTextInput(t *testing.T) {
 		})
 	}
 }
+
+func TestCharCount(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: "empty", text: "", want: 0},
+		{name: "ascii", text: "Hello", want: 5},
+		{name: "CJK", text: "日本語", want: 3},
+		{name: "emoji with skin tone", text: "👍🏽", want: 1},
+		{name: "family emoji", text: "👨‍👩‍👧", want: 1},
+		{name: "combining accent", text: "e\u0301", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CharCount(tt.text); got != tt.want {
+				t.Errorf("CharCount(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package grammr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/provider"
+	"github.com/maximbilan/grammr/internal/translator"
+	"github.com/maximbilan/grammr/internal/validation"
+)
+
+// Options configures a Client. Only APIKey is required.
+type Options struct {
+	Provider      string // "openai" or "anthropic", defaults to "openai"
+	APIKey        string // API key of the provider
+	Model         string // Defaults to the first model offered for the provider
+	Style         string // "casual", "formal", "academic" or "technical", defaults to "casual"
+	Language      string // Language of the texts to correct, defaults to "english"
+	Deterministic bool   // Use temperature 0 and a fixed seed for reproducible output
+}
+
+// Client corrects and translates texts with an AI provider, the way the grammr CLI does. It is
+// safe for concurrent use.
+type Client struct {
+	provider  provider.Provider
+	model     string
+	corrector *corrector.Corrector
+}
+
+// Providers lists the supported providers
+func Providers() []string {
+	return append([]string(nil), provider.Names...)
+}
+
+// New creates a client for the provider of opts
+func New(opts Options) (*Client, error) {
+	if err := validation.ValidateAPIKey(opts.APIKey); err != nil {
+		return nil, err
+	}
+	prov, err := provider.New(opts.Provider, opts.APIKey, provider.Options{Deterministic: opts.Deterministic})
+	if err != nil {
+		return nil, err
+	}
+	return newClient(prov, opts)
+}
+
+// newClient creates a client sending its requests to prov
+func newClient(prov provider.Provider, opts Options) (*Client, error) {
+	model := opts.Model
+	if model == "" {
+		name := opts.Provider
+		if name == "" {
+			name = "openai"
+		}
+		if models := provider.Models[name]; len(models) > 0 {
+			model = models[0]
+		}
+	}
+	style := opts.Style
+	if style == "" {
+		style = "casual"
+	}
+	cor, err := corrector.New(prov, model, style, opts.Language)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{provider: prov, model: model, corrector: cor}, nil
+}
+
+// Model returns the model the client sends requests to
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Correct returns text with its spelling, punctuation and grammar corrected
+func (c *Client) Correct(ctx context.Context, text string) (string, error) {
+	corrected, err := c.corrector.Correct(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(corrected, " \t\r\n"), nil
+}
+
+// StreamCorrect corrects text, calling onChunk with each part of the correction as it arrives
+func (c *Client) StreamCorrect(ctx context.Context, text string, onChunk func(string)) error {
+	return c.corrector.StreamCorrect(ctx, text, onChunk)
+}
+
+// Translate returns text translated to language, such as "spanish"
+func (c *Client) Translate(ctx context.Context, text, language string) (string, error) {
+	trans, err := c.translator(language)
+	if err != nil {
+		return "", err
+	}
+	translated, err := trans.Translate(ctx, text)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(translated, " \t\r\n"), nil
+}
+
+// StreamTranslate translates text to language, calling onChunk with each part of the
+// translation as it arrives
+func (c *Client) StreamTranslate(ctx context.Context, text, language string, onChunk func(string)) error {
+	trans, err := c.translator(language)
+	if err != nil {
+		return err
+	}
+	return trans.StreamTranslate(ctx, text, onChunk)
+}
+
+func (c *Client) translator(language string) (*translator.Translator, error) {
+	if strings.TrimSpace(language) == "" {
+		return nil, fmt.Errorf("translation language is required")
+	}
+	return translator.NewWithRateLimit(c.provider, c.model, language, nil)
+}
@@ -0,0 +1,113 @@
+package grammr
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/maximbilan/grammr/internal/provider"
+)
+
+// fakeProvider answers every request with the same response, remembering the last prompt
+type fakeProvider struct {
+	response string
+	model    string
+	prompt   string
+}
+
+func (p *fakeProvider) StreamChat(ctx context.Context, model string, messages []provider.Message, onChunk func(string)) error {
+	response, err := p.Chat(ctx, model, messages)
+	if err != nil {
+		return err
+	}
+	for _, word := range strings.SplitAfter(response, " ") {
+		onChunk(word)
+	}
+	return nil
+}
+
+func (p *fakeProvider) Chat(ctx context.Context, model string, messages []provider.Message) (string, error) {
+	p.model = model
+	p.prompt = messages[len(messages)-1].Content
+	return p.response, nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "openai", opts: Options{APIKey: "sk-test1234567890abcdefghijklmnop"}},
+		{name: "anthropic", opts: Options{Provider: "anthropic", APIKey: "sk-ant-REDACTED"}},
+		{name: "missing key", opts: Options{}, wantErr: true},
+		{name: "unknown provider", opts: Options{Provider: "other", APIKey: "sk-test1234567890abcdefghijklmnop"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && client.Model() != provider.Models[tt.name][0] {
+				t.Errorf("Model() = %q, want the first model of %s", client.Model(), tt.name)
+			}
+		})
+	}
+}
+
+func TestClientCorrect(t *testing.T) {
+	prov := &fakeProvider{response: "I have an apple.\n"}
+	client, err := newClient(prov, Options{Model: "gpt-4o-mini", Style: "formal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrected, err := client.Correct(context.Background(), "I has a apple.")
+	if err != nil {
+		t.Fatalf("Correct() error = %v", err)
+	}
+	if corrected != "I have an apple." {
+		t.Errorf("Correct() = %q, want the correction without the trailing newline", corrected)
+	}
+	if prov.model != "gpt-4o-mini" || !strings.Contains(prov.prompt, "I has a apple.") {
+		t.Errorf("sent %q to %s, want the text sent to gpt-4o-mini", prov.prompt, prov.model)
+	}
+
+	var streamed strings.Builder
+	if err := client.StreamCorrect(context.Background(), "I has a apple.", func(chunk string) { streamed.WriteString(chunk) }); err != nil {
+		t.Fatalf("StreamCorrect() error = %v", err)
+	}
+	if !strings.HasPrefix(streamed.String(), "I have an apple.") {
+		t.Errorf("StreamCorrect() streamed %q", streamed.String())
+	}
+}
+
+func TestClientTranslate(t *testing.T) {
+	prov := &fakeProvider{response: "Tengo una manzana."}
+	client, err := newClient(prov, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translated, err := client.Translate(context.Background(), "I have an apple.", "spanish")
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if translated != "Tengo una manzana." || !strings.Contains(prov.prompt, "spanish") {
+		t.Errorf("Translate() = %q for prompt %q", translated, prov.prompt)
+	}
+
+	var streamed strings.Builder
+	if err := client.StreamTranslate(context.Background(), "I have an apple.", "spanish", func(chunk string) { streamed.WriteString(chunk) }); err != nil {
+		t.Fatalf("StreamTranslate() error = %v", err)
+	}
+	if streamed.String() != "Tengo una manzana." {
+		t.Errorf("StreamTranslate() streamed %q", streamed.String())
+	}
+
+	if _, err := client.Translate(context.Background(), "I have an apple.", ""); err == nil {
+		t.Error("Translate() without a language should fail")
+	}
+}
@@ -0,0 +1,68 @@
+package grammr
+
+import (
+	"strings"
+
+	"github.com/maximbilan/grammr/internal/corrector"
+	"github.com/maximbilan/grammr/internal/textdiff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Change is one difference between a text and its correction, compared a word at a time
+type Change struct {
+	Original  string `json:"original"`  // Text the change removes, empty when it only adds
+	Corrected string `json:"corrected"` // Text the change adds, empty when it only removes
+	Category  string `json:"category"`  // "spelling", "punctuation", "grammar" or "rewording"
+}
+
+// Changes lists the changes between original and corrected, in the order they appear, so they
+// can be reviewed one by one like in grammr's review mode
+func Changes(original, corrected string) []Change {
+	var changes []Change
+	walk(original, corrected, func(text string) {}, func(change Change) {
+		changes = append(changes, change)
+	})
+	return changes
+}
+
+// Apply returns original with the changes between original and corrected that accept keeps,
+// numbered as listed by Changes. Accepting all of them yields corrected, none of them original.
+func Apply(original, corrected string, accept func(i int, change Change) bool) string {
+	var result strings.Builder
+	i := 0
+	walk(original, corrected, func(text string) {
+		result.WriteString(text)
+	}, func(change Change) {
+		if accept(i, change) {
+			result.WriteString(change.Corrected)
+		} else {
+			result.WriteString(change.Original)
+		}
+		i++
+	})
+	return result.String()
+}
+
+// walk calls onEqual with each unchanged part of the texts and onChange with each change between
+// them
+func walk(original, corrected string, onEqual func(string), onChange func(Change)) {
+	diffs := textdiff.Compute(original, corrected, textdiff.Options{Words: true})
+	for i := 0; i < len(diffs); {
+		if diffs[i].Type == diffmatchpatch.DiffEqual {
+			onEqual(diffs[i].Text)
+			i++
+			continue
+		}
+		// A change runs until the next unchanged part
+		var change Change
+		for ; i < len(diffs) && diffs[i].Type != diffmatchpatch.DiffEqual; i++ {
+			if diffs[i].Type == diffmatchpatch.DiffDelete {
+				change.Original += diffs[i].Text
+			} else {
+				change.Corrected += diffs[i].Text
+			}
+		}
+		change.Category = corrector.ClassifyChange(change.Original, change.Corrected)
+		onChange(change)
+	}
+}
@@ -0,0 +1,51 @@
+package grammr
+
+import (
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	changes := Changes("I has recieved a apple .", "I have received an apple.")
+	want := []Change{
+		{Original: "has", Corrected: "have", Category: "grammar"},
+		{Original: "recieved", Corrected: "received", Category: "spelling"},
+		{Original: "a", Corrected: "an", Category: "grammar"},
+		{Original: " ", Corrected: "", Category: "punctuation"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Changes() = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	if got := Changes("Same text.", "Same text."); len(got) != 0 {
+		t.Errorf("Changes() of equal texts = %+v, want none", got)
+	}
+}
+
+func TestApply(t *testing.T) {
+	original := "I has recieved a apple."
+	corrected := "I have received an apple."
+
+	tests := []struct {
+		name   string
+		accept func(i int, change Change) bool
+		want   string
+	}{
+		{name: "all", accept: func(int, Change) bool { return true }, want: corrected},
+		{name: "none", accept: func(int, Change) bool { return false }, want: original},
+		{name: "spelling only", accept: func(_ int, change Change) bool { return change.Category == "spelling" }, want: "I has received a apple."},
+		{name: "by index", accept: func(i int, _ Change) bool { return i == 0 }, want: "I have recieved a apple."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Apply(original, corrected, tt.accept); got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}